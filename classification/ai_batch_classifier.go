@@ -0,0 +1,121 @@
+package classification
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// aiBatchPromptFooter формат ответа для пакетной классификации
+const aiBatchPromptFooter = `JSON массив, по элементу на позицию: [{"index": 0, "category_path": ["Категория"], "confidence": 0.9}]`
+
+// AIClassificationResult результат классификации одной позиции в ClassifyBatch
+type AIClassificationResult struct {
+	Index    int                       `json:"index"`              // Индекс запроса во входном срезе
+	Response *AIClassificationResponse `json:"response,omitempty"` // Классификация (nil при ошибке)
+	Error    string                    `json:"error,omitempty"`    // Ошибка классификации позиции
+	Batched  bool                      `json:"batched"`            // Позиция классифицирована пакетным запросом
+	// Confidence уверенность модели в основной категории Response.CategoryPath, в диапазоне [0, 1]
+	Confidence float64 `json:"confidence,omitempty"`
+	// Alternatives другие категории по убыванию уверенности (Response.Suggestions)
+	Alternatives []CategorySuggestion `json:"alternatives,omitempty"`
+}
+
+// aiBatchAnswer элемент ответа модели на пакетный запрос
+type aiBatchAnswer struct {
+	Index int `json:"index"`
+	aiRawClassificationResponse
+}
+
+// ClassifyBatch классифицирует позиции пачками по MaxBatchSize: список категорий отправляется
+// один раз на пачку, а не на каждую позицию, что многократно сокращает расход токенов.
+// Позиции, которые не удалось разобрать из ответа (или вся пачка, если ответ не разобран),
+// классифицируются по одной через ClassifyWithAI. Ошибки отдельных позиций возвращаются
+// в AIClassificationResult.Error; ошибка функции означает сбой запроса к модели.
+func (ai *AIClassifier) ClassifyBatch(requests []AIClassificationRequest) ([]AIClassificationResult, error) {
+	results := make([]AIClassificationResult, len(requests))
+	for i := range results {
+		results[i].Index = i
+	}
+
+	batchSize := ai.config.MaxBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultAIMaxBatchSize
+	}
+
+	for start := 0; start < len(requests); start += batchSize {
+		end := min(start+batchSize, len(requests))
+		if err := ai.classifyBatchChunk(requests[start:end], results[start:end]); err != nil {
+			return results, err
+		}
+	}
+
+	return results, nil
+}
+
+// classifyBatchChunk классифицирует одну пачку и заполняет results (того же размера, что и requests)
+func (ai *AIClassifier) classifyBatchChunk(requests []AIClassificationRequest, results []AIClassificationResult) error {
+	startTime := time.Now()
+
+	// Системный промпт не зависит от позиции, поэтому строится без данных товара
+	systemPrompt := ai.buildSystemPrompt(AIClassificationRequest{})
+	prompt := ai.buildBatchPrompt(requests)
+	if ai.config.EnableLogging {
+		log.Printf("[AIClassifier] Batch of %d items, prompt size: %d bytes, estimated tokens: ~%d",
+			len(requests), len(prompt), ai.estimateTokens(prompt))
+	}
+
+	response, err := ai.callAI(systemPrompt, prompt)
+	ai.updatePerformanceMetrics(time.Since(startTime))
+	if err != nil {
+		return fmt.Errorf("AI batch request failed: %w", err)
+	}
+
+	var answers []aiBatchAnswer
+	if err := unmarshalTolerantJSONArray(response, &answers); err != nil {
+		if ai.config.EnableLogging {
+			log.Printf("[AIClassifier] Failed to parse batch response, classifying %d items one by one: %v", len(requests), err)
+		}
+		answers = nil
+	}
+
+	for _, answer := range answers {
+		if answer.Index < 0 || answer.Index >= len(requests) || len(answer.CategoryPath) == 0 {
+			continue
+		}
+		results[answer.Index].Response = answer.toResponse(ai.config.MaxAlternatives)
+		results[answer.Index].Batched = true
+	}
+
+	// Позиции без ответа классифицируем по одной
+	for i := range results {
+		if results[i].Response == nil {
+			response, err := ai.ClassifyWithAI(requests[i])
+			if err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+			results[i].Response = response
+		}
+		results[i].Confidence = results[i].Response.Confidence
+		results[i].Alternatives = results[i].Response.Suggestions
+	}
+
+	return nil
+}
+
+// buildBatchPrompt строит пользовательский промпт для пачки позиций с общим списком категорий
+func (ai *AIClassifier) buildBatchPrompt(requests []AIClassificationRequest) string {
+	var prompt strings.Builder
+	prompt.WriteString("Классифицируй позиции:\n")
+	for i, request := range requests {
+		fmt.Fprintf(&prompt, "%d. %s", i, request.ItemName)
+		if request.Description != "" {
+			fmt.Fprintf(&prompt, " %s", request.Description)
+		}
+		prompt.WriteString("\n")
+	}
+	fmt.Fprintf(&prompt, "\nКатегории: %s\n\n%s", ai.summarizeClassifierTree(), aiBatchPromptFooter)
+	return prompt.String()
+}
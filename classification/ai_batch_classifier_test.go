@@ -0,0 +1,154 @@
+package classification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// newBatchTestClassifier создает классификатор с заданным размером пачки и сценарным ответом модели
+func newBatchTestClassifier(t *testing.T, batchSize int, complete AICompletionFunc) *AIClassifier {
+	t.Helper()
+	classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
+	config := classifier.GetConfig()
+	config.MaxBatchSize = batchSize
+	config.EnableLogging = false
+	if err := classifier.SetConfig(config); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+	classifier.SetCompletionFunc(complete)
+	return classifier
+}
+
+// batchItemCount возвращает число позиций в пакетном промпте (0 - промпт одной позиции)
+func batchItemCount(prompt string) int {
+	if !strings.HasPrefix(prompt, "Классифицируй позиции:") {
+		return 0
+	}
+	count := 0
+	for _, line := range strings.Split(prompt, "\n") {
+		if line != "" && line[0] >= '0' && line[0] <= '9' {
+			count++
+		}
+	}
+	return count
+}
+
+func TestAIClassifierClassifyBatch(t *testing.T) {
+	batchCalls, singleCalls := 0, 0
+	classifier := newBatchTestClassifier(t, 2, func(ctx context.Context, prompt string) (string, error) {
+		count := batchItemCount(prompt)
+		if count == 0 {
+			singleCalls++
+			return `{"category_path": ["Товары"], "confidence": 0.6}`, nil
+		}
+		batchCalls++
+		answers := make([]string, count)
+		for i := range answers {
+			answers[i] = fmt.Sprintf(`{"index": %d, "category_path": ["Товары", "Позиция %d"], "confidence": 0.9,
+				"alternatives": [{"category_path": ["Услуги"], "confidence": 0.1}]}`, i, i)
+		}
+		return "```json\n[" + strings.Join(answers, ", ") + "]\n```", nil
+	})
+
+	requests := []AIClassificationRequest{
+		{ItemName: "Болт М8"}, {ItemName: "Гайка М8"}, {ItemName: "Шайба 8"},
+		{ItemName: "Шуруп 4x40"}, {ItemName: "Дюбель 6x30", Description: "нейлон"},
+	}
+	results, err := classifier.ClassifyBatch(requests)
+	if err != nil {
+		t.Fatalf("ClassifyBatch() error = %v", err)
+	}
+
+	if batchCalls != 3 || singleCalls != 0 {
+		t.Errorf("batch calls = %d, single calls = %d, want 3 and 0", batchCalls, singleCalls)
+	}
+	if len(results) != len(requests) {
+		t.Fatalf("len(results) = %d, want %d", len(results), len(requests))
+	}
+	for i, result := range results {
+		if result.Index != i || !result.Batched || result.Response == nil {
+			t.Fatalf("results[%d] = %+v", i, result)
+		}
+		// Индексы в ответе относятся к позициям внутри пачки
+		want := fmt.Sprintf("Позиция %d", i%2)
+		if result.Response.CategoryPath[1] != want {
+			t.Errorf("results[%d].CategoryPath = %v, want %s", i, result.Response.CategoryPath, want)
+		}
+		if result.Confidence != 0.9 || len(result.Alternatives) != 1 || result.Alternatives[0].Confidence != 0.1 {
+			t.Errorf("results[%d] confidence = %v, alternatives = %+v", i, result.Confidence, result.Alternatives)
+		}
+	}
+}
+
+func TestAIClassifierClassifyBatchFallback(t *testing.T) {
+	t.Run("missing items are classified one by one", func(t *testing.T) {
+		singleCalls := 0
+		classifier := newBatchTestClassifier(t, 10, func(ctx context.Context, prompt string) (string, error) {
+			if batchItemCount(prompt) == 0 {
+				singleCalls++
+				return `{"category_path": ["Услуги"], "confidence": 0.8}`, nil
+			}
+			return `[{"index": 0, "category_path": ["Товары"], "confidence": 0.9}, {"index": 2, "category_path": []}]`, nil
+		})
+
+		results, err := classifier.ClassifyBatch([]AIClassificationRequest{
+			{ItemName: "Болт М8"}, {ItemName: "Монтаж"}, {ItemName: "Доставка"},
+		})
+		if err != nil {
+			t.Fatalf("ClassifyBatch() error = %v", err)
+		}
+		if singleCalls != 2 {
+			t.Errorf("single calls = %d, want 2", singleCalls)
+		}
+		if !results[0].Batched || results[0].Response.CategoryPath[0] != "Товары" {
+			t.Errorf("results[0] = %+v", results[0])
+		}
+		for _, i := range []int{1, 2} {
+			if results[i].Batched || results[i].Response == nil || results[i].Response.CategoryPath[0] != "Услуги" {
+				t.Errorf("results[%d] = %+v", i, results[i])
+			}
+		}
+	})
+
+	t.Run("unparsable batch response", func(t *testing.T) {
+		singleCalls := 0
+		classifier := newBatchTestClassifier(t, 10, func(ctx context.Context, prompt string) (string, error) {
+			if batchItemCount(prompt) == 0 {
+				singleCalls++
+				if strings.Contains(prompt, "???") {
+					return "Не знаю", nil
+				}
+				return `{"category_path": ["Товары"]}`, nil
+			}
+			return "Извините, не могу классифицировать эти позиции", nil
+		})
+
+		results, err := classifier.ClassifyBatch([]AIClassificationRequest{{ItemName: "Болт М8"}, {ItemName: "???"}})
+		if err != nil {
+			t.Fatalf("ClassifyBatch() error = %v", err)
+		}
+		if singleCalls != 2 {
+			t.Errorf("single calls = %d, want 2", singleCalls)
+		}
+		if results[0].Response == nil || results[0].Batched {
+			t.Errorf("results[0] = %+v", results[0])
+		}
+		if results[1].Response != nil || results[1].Error == "" {
+			t.Errorf("results[1] = %+v, want per-item error", results[1])
+		}
+	})
+}
+
+func TestAIClassifierClassifyBatchEmpty(t *testing.T) {
+	classifier := newBatchTestClassifier(t, 5, func(ctx context.Context, prompt string) (string, error) {
+		t.Fatal("completion must not be called for empty batch")
+		return "", nil
+	})
+
+	results, err := classifier.ClassifyBatch(nil)
+	if err != nil || len(results) != 0 {
+		t.Errorf("ClassifyBatch(nil) = %v, %v", results, err)
+	}
+}
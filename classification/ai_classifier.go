@@ -1,13 +1,16 @@
 package classification
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"text/template"
 	"time"
 
 	"httpserver/nomenclature"
@@ -15,9 +18,23 @@ import (
 
 // AIClassifierConfig конфигурация для AI классификатора
 type AIClassifierConfig struct {
-	MaxCategories      int // Максимальное количество категорий в списке (по умолчанию 15)
+	MaxCategories      int // Максимальное количество категорий в списке (по умолчанию 15), первые по названию категории
 	MaxCategoryNameLen int // Максимальная длина названия категории (по умолчанию 50)
 	EnableLogging      bool // Включить детальное логирование (по умолчанию true)
+	// Шаблоны промптов в формате text/template (переменные описаны в AIPromptData).
+	// Пустое значение означает встроенный оптимизированный шаблон.
+	SystemPromptTemplate string
+	UserPromptTemplate   string
+	// MaxRetries число повторов запроса при ответе 5xx или пустом ответе модели (0 - без повторов)
+	MaxRetries int
+	// RetryBackoff задержка перед первым повтором, далее удваивается (по умолчанию defaultAIRetryBackoff)
+	RetryBackoff time.Duration
+	// StripMarkdown извлекать JSON из ответа с markdown-обрамлением и пояснениями вокруг объекта
+	StripMarkdown bool
+	// MaxBatchSize максимальное число позиций в одном запросе ClassifyBatch (по умолчанию defaultAIMaxBatchSize)
+	MaxBatchSize int
+	// MaxAlternatives максимальное число альтернативных категорий в ответе (по умолчанию defaultAIMaxAlternatives)
+	MaxAlternatives int
 }
 
 // AIClassifier классификатор категорий с использованием AI
@@ -29,11 +46,17 @@ type AIClassifier struct {
 	cacheHits      int64 // Счетчик попаданий в кэш
 	cacheMisses    int64 // Счетчик промахов кэша
 	config         AIClassifierConfig // Конфигурация
+	prompts        *aiPromptTemplates // Скомпилированные шаблоны промптов
 	totalRequests  int64 // Общее количество запросов
 	totalLatency   time.Duration // Общее время выполнения запросов
 	perfMutex      sync.RWMutex // Мьютекс для метрик производительности
+	// complete выполняет запрос к модели; по умолчанию aiClient.GetCompletionWithContext (см. SetCompletionFunc)
+	complete func(ctx context.Context, systemPrompt, prompt string) (string, error)
 }
 
+// AICompletionFunc выполняет запрос к модели по пользовательскому промпту и возвращает ее ответ
+type AICompletionFunc func(ctx context.Context, prompt string) (string, error)
+
 // Reuse CategoryNode from classifier.go
 // AIClassificationRequest запрос на классификацию
 type AIClassificationRequest struct {
@@ -49,6 +72,15 @@ type AIClassificationResponse struct {
 	Confidence   float64    `json:"confidence"`
 	Reasoning    string     `json:"reasoning"`
 	Alternatives [][]string `json:"alternatives,omitempty"`
+	// Suggestions альтернативные категории с уверенностью модели по убыванию (не более MaxAlternatives).
+	// Alternatives содержит пути тех же категорий в том же порядке.
+	Suggestions []CategorySuggestion `json:"suggestions,omitempty"`
+}
+
+// CategorySuggestion категория-кандидат с уверенностью модели
+type CategorySuggestion struct {
+	CategoryPath []string `json:"category_path"`
+	Confidence   float64  `json:"confidence"`
 }
 
 // NewAIClassifier создает новый AI классификатор
@@ -64,9 +96,33 @@ func NewAIClassifier(apiKey string, model string) *AIClassifier {
 	// Загружаем конфигурацию из переменных окружения
 	config := loadConfigFromEnv()
 	
+	prompts, err := compileAIPromptTemplates(config)
+	if err != nil {
+		log.Printf("[AIClassifier] %v, using built-in prompt templates", err)
+		config.SystemPromptTemplate = ""
+		config.UserPromptTemplate = ""
+		prompts, _ = compileAIPromptTemplates(config)
+	}
+
+	aiClient := nomenclature.NewAIClient(apiKey, model)
 	return &AIClassifier{
-		aiClient: nomenclature.NewAIClient(apiKey, model),
+		aiClient: aiClient,
 		config:   config,
+		prompts:  prompts,
+		complete: aiClient.GetCompletionWithContext,
+	}
+}
+
+// SetCompletionFunc подменяет запрос к модели: классификация (промпты, повторы, разбор ответа,
+// метрики) выполняется полностью, но ответ берется из fn без обращения к сети.
+// Используется в тестах и CI со сценарными ответами. nil возвращает запросы к ARLIAI API.
+func (ai *AIClassifier) SetCompletionFunc(fn AICompletionFunc) {
+	if fn == nil {
+		ai.complete = ai.aiClient.GetCompletionWithContext
+		return
+	}
+	ai.complete = func(ctx context.Context, _, prompt string) (string, error) {
+		return fn(ctx, prompt)
 	}
 }
 
@@ -76,6 +132,10 @@ func loadConfigFromEnv() AIClassifierConfig {
 		MaxCategories:      15,
 		MaxCategoryNameLen: 50,
 		EnableLogging:      true,
+		MaxRetries:         defaultAIMaxRetries,
+		StripMarkdown:      true,
+		MaxBatchSize:       defaultAIMaxBatchSize,
+		MaxAlternatives:    defaultAIMaxAlternatives,
 	}
 	
 	// Загружаем максимальное количество категорий
@@ -96,6 +156,36 @@ func loadConfigFromEnv() AIClassifierConfig {
 	if loggingStr := os.Getenv("AI_CLASSIFIER_ENABLE_LOGGING"); loggingStr != "" {
 		config.EnableLogging = strings.ToLower(loggingStr) == "true"
 	}
+
+	// Загружаем число повторов при ошибках сервера
+	if retriesStr := os.Getenv("AI_CLASSIFIER_MAX_RETRIES"); retriesStr != "" {
+		if retries, err := strconv.Atoi(retriesStr); err == nil && retries >= 0 {
+			config.MaxRetries = retries
+		}
+	}
+
+	// Загружаем размер пачки для пакетной классификации
+	if batchStr := os.Getenv("AI_CLASSIFIER_MAX_BATCH_SIZE"); batchStr != "" {
+		if batch, err := strconv.Atoi(batchStr); err == nil && batch > 0 {
+			config.MaxBatchSize = batch
+		}
+	}
+
+	// Загружаем число альтернативных категорий
+	if altStr := os.Getenv("AI_CLASSIFIER_MAX_ALTERNATIVES"); altStr != "" {
+		if alternatives, err := strconv.Atoi(altStr); err == nil && alternatives > 0 {
+			config.MaxAlternatives = alternatives
+		}
+	}
+
+	// Загружаем настройку извлечения JSON из markdown
+	if stripStr := os.Getenv("AI_CLASSIFIER_STRIP_MARKDOWN"); stripStr != "" {
+		config.StripMarkdown = strings.ToLower(stripStr) == "true"
+	}
+
+	// Загружаем шаблоны промптов
+	config.SystemPromptTemplate = os.Getenv("AI_CLASSIFIER_SYSTEM_PROMPT_TEMPLATE")
+	config.UserPromptTemplate = os.Getenv("AI_CLASSIFIER_USER_PROMPT_TEMPLATE")
 	
 	return config
 }
@@ -113,7 +203,8 @@ func (ai *AIClassifier) SetClassifierTree(tree *CategoryNode) {
 func (ai *AIClassifier) ClassifyWithAI(request AIClassificationRequest) (*AIClassificationResponse, error) {
 	startTime := time.Now()
 	
-	// Подготавливаем промпт
+	// Подготавливаем промпты
+	systemPrompt := ai.buildSystemPrompt(request)
 	prompt := ai.buildClassificationPrompt(request)
 	
 	// Логируем размер промпта для мониторинга оптимизаций
@@ -124,7 +215,7 @@ func (ai *AIClassifier) ClassifyWithAI(request AIClassificationRequest) (*AIClas
 	}
 
 	// Вызываем AI
-	response, err := ai.callAI(prompt)
+	response, err := ai.callAI(systemPrompt, prompt)
 	if err != nil {
 		// Обновляем метрики даже при ошибке
 		ai.updatePerformanceMetrics(time.Since(startTime))
@@ -154,26 +245,42 @@ func (ai *AIClassifier) updatePerformanceMetrics(latency time.Duration) {
 	ai.totalLatency += latency
 }
 
-// buildClassificationPrompt строит промпт для классификации
+// buildClassificationPrompt строит промпт для классификации по шаблону UserPromptTemplate
 func (ai *AIClassifier) buildClassificationPrompt(request AIClassificationRequest) string {
-	classifierSummary := ai.summarizeClassifierTree()
+	return ai.renderPrompt(ai.prompts.user, request)
+}
+
+// buildSystemPrompt строит системный промпт по шаблону SystemPromptTemplate
+func (ai *AIClassifier) buildSystemPrompt(request AIClassificationRequest) string {
+	return ai.renderPrompt(ai.prompts.system, request)
+}
 
-	// Максимально упрощенный промпт для экономии токенов
-	// Используем компактный формат без лишних слов
-	desc := ""
-	if request.Description != "" {
-		desc = " " + request.Description
+// renderPrompt подставляет данные запроса в шаблон промпта.
+// При ошибке исполнения используется встроенный шаблон того же вида.
+func (ai *AIClassifier) renderPrompt(tmpl *template.Template, request AIClassificationRequest) string {
+	data := AIPromptData{
+		ItemName:    request.ItemName,
+		Description: request.Description,
+	}
+	// Список категорий строим только если шаблон его использует
+	if strings.Contains(tmpl.Root.String(), ".Categories") {
+		data.Categories = ai.summarizeClassifierTree()
 	}
-	
-	return fmt.Sprintf(`Классифицируй: %s%s
 
-Категории: %s
+	prompt, err := renderAIPrompt(tmpl, data)
+	if err == nil {
+		return prompt
+	}
 
-JSON: {"category_path": ["Категория"], "confidence": 0.9, "reasoning": "кратко"}`,
-		request.ItemName,
-		desc,
-		classifierSummary,
-	)
+	log.Printf("[AIClassifier] %v, falling back to built-in template", err)
+	defaults, _ := compileAIPromptTemplates(AIClassifierConfig{})
+	fallback := defaults.user
+	if tmpl.Name() == "system" {
+		fallback = defaults.system
+	}
+	data.Categories = ai.summarizeClassifierTree()
+	prompt, _ = renderAIPrompt(fallback, data)
+	return prompt
 }
 
 // summarizeClassifierTree создает текстовое представление классификатора для AI
@@ -232,11 +339,13 @@ func (ai *AIClassifier) buildCompactCategoryList(maxCategories int) string {
 		max = len(ai.classifierTree.Children)
 	}
 
-	// Берем только первые maxCategories категорий
+	// Берем первые maxCategories категорий в порядке sortedCategoryChildren,
+	// а не в порядке построения дерева: одинаковые деревья дают одинаковый промпт
 	// Обрезаем длинные названия для экономии токенов
+	children := sortedCategoryChildren(ai.classifierTree.Children)
 	maxLen := ai.config.MaxCategoryNameLen
 	for i := 0; i < max; i++ {
-		name := ai.classifierTree.Children[i].Name
+		name := children[i].Name
 		// Обрезаем слишком длинные названия
 		if len(name) > maxLen {
 			name = name[:maxLen-3] + "..."
@@ -254,11 +363,26 @@ func (ai *AIClassifier) buildCompactCategoryList(maxCategories int) string {
 	return result
 }
 
+// sortedCategoryChildren возвращает копию категорий, упорядоченную по названию, затем по пути и ID.
+// Порядок детей в дереве зависит от источника (JSON из БД, обход map при построении),
+// поэтому для выбора категорий в промпт он не используется.
+func sortedCategoryChildren(children []CategoryNode) []CategoryNode {
+	sorted := make([]CategoryNode, len(children))
+	copy(sorted, children)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Name != sorted[j].Name {
+			return sorted[i].Name < sorted[j].Name
+		}
+		if sorted[i].Path != sorted[j].Path {
+			return sorted[i].Path < sorted[j].Path
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+	return sorted
+}
+
 // callAI вызывает AI API
-func (ai *AIClassifier) callAI(prompt string) (string, error) {
-	// Упрощенный системный промпт для экономии токенов
-	systemPrompt := `Классифицируй товары/услуги. ТОВАРЫ=объекты, УСЛУГИ=работы. JSON формат.`
-	
+func (ai *AIClassifier) callAI(systemPrompt, prompt string) (string, error) {
 	// Логируем размер системного промпта
 	if ai.config.EnableLogging {
 		systemPromptSize := len(systemPrompt)
@@ -266,16 +390,33 @@ func (ai *AIClassifier) callAI(prompt string) (string, error) {
 		log.Printf("[AIClassifier] System prompt size: %d bytes, estimated tokens: ~%d", systemPromptSize, systemPromptTokens)
 	}
 
-	// Вызываем AI через стандартный метод
-	result, err := ai.aiClient.GetCompletion(systemPrompt, prompt)
-	if err != nil {
-		return "", fmt.Errorf("AI API call failed: %w", err)
+	// Вызываем AI через стандартный метод; ошибки сервера и пустые ответы повторяем
+	delay := ai.config.RetryBackoff
+	if delay <= 0 {
+		delay = defaultAIRetryBackoff
 	}
+	for attempt := 0; ; attempt++ {
+		result, err := ai.complete(context.Background(), systemPrompt, prompt)
+		if err == nil && strings.TrimSpace(result) == "" {
+			err = errEmptyAIResponse
+		}
+		if err == nil {
+			return result, nil
+		}
+		if attempt >= ai.config.MaxRetries || !isRetryableAIError(err) {
+			return "", fmt.Errorf("AI API call failed: %w", err)
+		}
 
-	return result, nil
+		if ai.config.EnableLogging {
+			log.Printf("[AIClassifier] Attempt %d failed: %v, retrying in %v", attempt+1, err, delay)
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
 }
 
-// parseAIResponse парсит ответ от AI
+// parseAIResponse парсит ответ от AI. При StripMarkdown ответ разбирается терпимо
+// (см. unmarshalTolerantJSON): markdown-обрамление, текст вокруг объекта и обрыв JSON не мешают разбору.
 func (ai *AIClassifier) parseAIResponse(response string) (*AIClassificationResponse, error) {
 	// Очищаем ответ от возможных markdown блоков
 	response = strings.TrimSpace(response)
@@ -289,21 +430,23 @@ func (ai *AIClassifier) parseAIResponse(response string) (*AIClassificationRespo
 		response = strings.TrimSpace(response)
 	}
 
-	var aiResponse AIClassificationResponse
-	if err := json.Unmarshal([]byte(response), &aiResponse); err != nil {
+	var raw aiRawClassificationResponse
+	var err error
+	if ai.config.StripMarkdown {
+		err = unmarshalTolerantJSON(response, &raw)
+	} else {
+		err = json.Unmarshal([]byte(response), &raw)
+	}
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse AI response: %w, response: %s", err, response)
 	}
 
 	// Валидация
-	if len(aiResponse.CategoryPath) == 0 {
+	if len(raw.CategoryPath) == 0 {
 		return nil, fmt.Errorf("empty category path in AI response")
 	}
 
-	if aiResponse.Confidence <= 0 || aiResponse.Confidence > 1 {
-		aiResponse.Confidence = 0.7 // Дефолтная уверенность
-	}
-
-	return &aiResponse, nil
+	return raw.toResponse(ai.config.MaxAlternatives), nil
 }
 
 // estimateTokens приблизительно оценивает количество токенов в тексте
@@ -339,13 +482,21 @@ func (ai *AIClassifier) GetConfig() AIClassifierConfig {
 	return ai.config
 }
 
-// SetConfig устанавливает новую конфигурацию (сбрасывает кэш)
-func (ai *AIClassifier) SetConfig(config AIClassifierConfig) {
+// SetConfig устанавливает новую конфигурацию (сбрасывает кэш).
+// Шаблоны промптов проверяются заранее: при ошибке конфигурация не меняется.
+func (ai *AIClassifier) SetConfig(config AIClassifierConfig) error {
+	prompts, err := compileAIPromptTemplates(config)
+	if err != nil {
+		return err
+	}
+
 	ai.config = config
+	ai.prompts = prompts
 	// Сбрасываем кэш при изменении конфигурации
 	ai.cacheMutex.Lock()
 	ai.categoryListCache = ""
 	ai.cacheMutex.Unlock()
+	return nil
 }
 
 // CodeExists проверяет существование пути в классификаторе
@@ -1,6 +1,8 @@
 package classification
 
 import (
+	"context"
+	"strings"
 	"testing"
 )
 
@@ -94,7 +96,9 @@ func TestAIClassifierOptimizationIntegration(t *testing.T) {
 		MaxCategoryNameLen: 30,
 		EnableLogging:      false,
 	}
-	classifier.SetConfig(newConfig)
+	if err := classifier.SetConfig(newConfig); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
 	
 	// Проверяем, что кэш сброшен
 	summary3 := classifier.summarizeClassifierTree()
@@ -142,7 +146,9 @@ func TestAIClassifierConfigImpact(t *testing.T) {
 		MaxCategoryNameLen: 50,
 		EnableLogging:      false,
 	}
-	classifier.SetConfig(smallConfig)
+	if err := classifier.SetConfig(smallConfig); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
 	smallSummary := classifier.summarizeClassifierTree()
 	smallSize := len(smallSummary)
 	
@@ -152,7 +158,9 @@ func TestAIClassifierConfigImpact(t *testing.T) {
 		MaxCategoryNameLen: 50,
 		EnableLogging:      false,
 	}
-	classifier.SetConfig(largeConfig)
+	if err := classifier.SetConfig(largeConfig); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
 	largeSummary := classifier.summarizeClassifierTree()
 	largeSize := len(largeSummary)
 	
@@ -221,3 +229,54 @@ func TestAIClassifierCacheEffectiveness(t *testing.T) {
 	t.Logf("Cache effectiveness: %d hits, %d misses, hit rate: %.2f%%", hits2, misses2, hitRate)
 }
 
+
+// TestAIClassifierOfflineClassification прогоняет классификацию целиком со сценарным ответом модели
+func TestAIClassifierOfflineClassification(t *testing.T) {
+	classifier := NewAIClassifier("", "GLM-4.5-Air")
+
+	root := NewCategoryNode("root", "Root", "/root", 0)
+	root.AddChild(NewCategoryNode("tools", "Инструменты", "/root/tools", 1))
+	root.AddChild(NewCategoryNode("food", "Продукты питания", "/root/food", 1))
+	classifier.SetClassifierTree(root)
+
+	var prompts []string
+	classifier.SetCompletionFunc(func(ctx context.Context, prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		if strings.Contains(prompt, "Молоток") && strings.Contains(prompt, "Инструменты") {
+			return "```json\n{\"category_path\": [\"Инструменты\"], \"confidence\": 0.92, \"reasoning\": \"ручной инструмент\"}\n```", nil
+		}
+		return `{"category_path": ["Продукты питания"], "confidence": 0.8}`, nil
+	})
+
+	result, err := classifier.ClassifyWithAI(AIClassificationRequest{
+		ItemName:    "Молоток строительный",
+		Description: "С деревянной ручкой",
+	})
+	if err != nil {
+		t.Fatalf("ClassifyWithAI() error = %v", err)
+	}
+	if len(result.CategoryPath) != 1 || result.CategoryPath[0] != "Инструменты" {
+		t.Errorf("CategoryPath = %v, want [Инструменты]", result.CategoryPath)
+	}
+	if result.Confidence != 0.92 {
+		t.Errorf("Confidence = %v, want 0.92", result.Confidence)
+	}
+	if !classifier.CodeExists(result.CategoryPath) {
+		t.Errorf("Category %v is missing in classifier tree", result.CategoryPath)
+	}
+
+	result, err = classifier.ClassifyWithAI(AIClassificationRequest{ItemName: "Молоко 3,2%"})
+	if err != nil {
+		t.Fatalf("ClassifyWithAI() error = %v", err)
+	}
+	if result.CategoryPath[0] != "Продукты питания" {
+		t.Errorf("CategoryPath = %v, want [Продукты питания]", result.CategoryPath)
+	}
+
+	if len(prompts) != 2 {
+		t.Errorf("completion calls = %d, want 2", len(prompts))
+	}
+	if totalRequests, _ := classifier.GetPerformanceStats(); totalRequests != 2 {
+		t.Errorf("totalRequests = %d, want 2", totalRequests)
+	}
+}
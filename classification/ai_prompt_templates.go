@@ -0,0 +1,86 @@
+package classification
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// AIPromptData данные, доступные в шаблонах промптов AIClassifier.
+//
+// Переменные шаблона:
+//
+//	{{.ItemName}}    - название классифицируемого товара/услуги
+//	{{.Description}} - описание (может быть пустым, используйте {{if .Description}}...{{end}})
+//	{{.Categories}}  - компактный список категорий классификатора через запятую
+type AIPromptData struct {
+	ItemName    string
+	Description string
+	Categories  string
+}
+
+// DefaultAISystemPromptTemplate встроенный оптимизированный системный промпт
+const DefaultAISystemPromptTemplate = `Классифицируй товары/услуги. ТОВАРЫ=объекты, УСЛУГИ=работы. JSON формат.`
+
+// DefaultAIUserPromptTemplate встроенный оптимизированный пользовательский промпт
+// (максимально компактный для экономии токенов)
+const DefaultAIUserPromptTemplate = `Классифицируй: {{.ItemName}}{{if .Description}} {{.Description}}{{end}}
+
+Категории: {{.Categories}}
+
+JSON: {"category_path": ["Категория"], "confidence": 0.9, "reasoning": "кратко", "alternatives": [{"category_path": ["Другая категория"], "confidence": 0.3}]}`
+
+// aiPromptTemplates скомпилированные шаблоны промптов
+type aiPromptTemplates struct {
+	system *template.Template
+	user   *template.Template
+}
+
+// compileAIPromptTemplates компилирует шаблоны из конфигурации, подставляя встроенные
+// шаблоны для пустых полей. Каждый шаблон пробно исполняется на тестовых данных,
+// чтобы ссылки на несуществующие переменные обнаруживались сразу, а не при запросе к AI.
+func compileAIPromptTemplates(config AIClassifierConfig) (*aiPromptTemplates, error) {
+	systemText := config.SystemPromptTemplate
+	if strings.TrimSpace(systemText) == "" {
+		systemText = DefaultAISystemPromptTemplate
+	}
+	userText := config.UserPromptTemplate
+	if strings.TrimSpace(userText) == "" {
+		userText = DefaultAIUserPromptTemplate
+	}
+
+	system, err := parseAIPromptTemplate("system", systemText)
+	if err != nil {
+		return nil, err
+	}
+	user, err := parseAIPromptTemplate("user", userText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &aiPromptTemplates{system: system, user: user}, nil
+}
+
+// parseAIPromptTemplate разбирает и проверяет один шаблон промпта
+func parseAIPromptTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s prompt template: %w", name, err)
+	}
+
+	sample := AIPromptData{ItemName: "Болт М10", Description: "оцинкованный", Categories: "Крепеж, Инструмент"}
+	if err := tmpl.Execute(&strings.Builder{}, sample); err != nil {
+		return nil, fmt.Errorf("invalid %s prompt template: %w", name, err)
+	}
+
+	return tmpl, nil
+}
+
+// renderAIPrompt исполняет шаблон промпта с переданными данными
+func renderAIPrompt(tmpl *template.Template, data AIPromptData) (string, error) {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render %s prompt: %w", tmpl.Name(), err)
+	}
+	return sb.String(), nil
+}
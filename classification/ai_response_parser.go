@@ -0,0 +1,250 @@
+package classification
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultAIMaxRetries число повторов запроса к модели по умолчанию
+	defaultAIMaxRetries = 2
+	// defaultAIRetryBackoff задержка перед первым повтором по умолчанию
+	defaultAIRetryBackoff = 500 * time.Millisecond
+	// defaultAIMaxBatchSize число позиций в одном запросе ClassifyBatch по умолчанию
+	defaultAIMaxBatchSize = 20
+	// defaultAIMaxAlternatives число альтернативных категорий в ответе по умолчанию
+	defaultAIMaxAlternatives = 3
+	// defaultAIConfidence уверенность, если модель ее не указала
+	defaultAIConfidence = 0.7
+)
+
+// aiRawClassificationResponse ответ модели до нормализации. Альтернативы приходят либо
+// ранжированным списком с оценками ([{"category_path": [...], "confidence": 0.3}]),
+// либо, в старом формате, списком путей без оценок ([["Категория"]]).
+type aiRawClassificationResponse struct {
+	CategoryPath []string        `json:"category_path"`
+	Confidence   float64         `json:"confidence"`
+	Reasoning    string          `json:"reasoning"`
+	Alternatives json.RawMessage `json:"alternatives,omitempty"`
+}
+
+// toResponse нормализует ответ: уверенность ограничивается диапазоном [0, 1] (отсутствующая
+// заменяется defaultAIConfidence), альтернативы без основной категории сортируются по убыванию
+// уверенности и обрезаются до maxAlternatives (0 - defaultAIMaxAlternatives)
+func (raw *aiRawClassificationResponse) toResponse(maxAlternatives int) *AIClassificationResponse {
+	response := &AIClassificationResponse{
+		CategoryPath: raw.CategoryPath,
+		Confidence:   raw.Confidence,
+		Reasoning:    raw.Reasoning,
+	}
+	if response.Confidence <= 0 {
+		response.Confidence = defaultAIConfidence
+	}
+	response.Confidence = clampConfidence(response.Confidence)
+
+	if maxAlternatives <= 0 {
+		maxAlternatives = defaultAIMaxAlternatives
+	}
+	primary := strings.Join(raw.CategoryPath, "\x1f")
+	for _, suggestion := range parseCategorySuggestions(raw.Alternatives) {
+		if len(response.Suggestions) == maxAlternatives {
+			break
+		}
+		if strings.Join(suggestion.CategoryPath, "\x1f") == primary {
+			continue
+		}
+		response.Suggestions = append(response.Suggestions, suggestion)
+		response.Alternatives = append(response.Alternatives, suggestion.CategoryPath)
+	}
+	return response
+}
+
+// parseCategorySuggestions разбирает альтернативы из ответа модели в обоих форматах и сортирует
+// их по убыванию уверенности (при равенстве сохраняется порядок модели). Элементы без пути пропускаются.
+func parseCategorySuggestions(data json.RawMessage) []CategorySuggestion {
+	var items []json.RawMessage
+	if len(data) == 0 || json.Unmarshal(data, &items) != nil {
+		return nil
+	}
+
+	suggestions := make([]CategorySuggestion, 0, len(items))
+	for _, item := range items {
+		var path []string
+		if json.Unmarshal(item, &path) == nil {
+			if len(path) > 0 {
+				suggestions = append(suggestions, CategorySuggestion{CategoryPath: path})
+			}
+			continue
+		}
+
+		var scored struct {
+			CategoryPath []string `json:"category_path"`
+			Confidence   float64  `json:"confidence"`
+			Score        float64  `json:"score"`
+		}
+		if json.Unmarshal(item, &scored) != nil || len(scored.CategoryPath) == 0 {
+			continue
+		}
+		confidence := scored.Confidence
+		if confidence == 0 {
+			confidence = scored.Score
+		}
+		suggestions = append(suggestions, CategorySuggestion{
+			CategoryPath: scored.CategoryPath,
+			Confidence:   clampConfidence(confidence),
+		})
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Confidence > suggestions[j].Confidence
+	})
+	return suggestions
+}
+
+// clampConfidence ограничивает уверенность диапазоном [0, 1]
+func clampConfidence(confidence float64) float64 {
+	return max(0, min(confidence, 1))
+}
+
+// errEmptyAIResponse модель вернула пустой ответ
+var errEmptyAIResponse = errors.New("empty AI response")
+
+// aiStatusPattern извлекает HTTP статус из ошибки nomenclature.AIClient ("API returned status 502: ...")
+var aiStatusPattern = regexp.MustCompile(`API returned status (\d{3})`)
+
+// isRetryableAIError определяет, имеет ли смысл повторить запрос: ответ 5xx, пустой ответ
+// или ответ без choices. Ошибки 4xx, квоты и открытого circuit breaker не повторяются.
+func isRetryableAIError(err error) bool {
+	if errors.Is(err, errEmptyAIResponse) {
+		return true
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "no choices in response") {
+		return true
+	}
+	if match := aiStatusPattern.FindStringSubmatch(msg); match != nil {
+		status, _ := strconv.Atoi(match[1])
+		return status >= 500
+	}
+	return false
+}
+
+// markdownFencePattern открывающие и закрывающие строки markdown-блоков кода (```json, ```)
+var markdownFencePattern = regexp.MustCompile("(?m)^\\s*```[a-zA-Z]*\\s*$")
+
+// unmarshalTolerantJSON разбирает JSON-объект из ответа модели. Последовательно пробует:
+// ответ как есть; ответ без markdown-блоков, от первой "{" до последней "}" (пояснения вокруг
+// объекта отбрасываются); наконец, оборванный ответ с дописанными закрывающими кавычками и скобками.
+func unmarshalTolerantJSON(response string, v interface{}) error {
+	return unmarshalTolerantJSONValue(response, '{', '}', v)
+}
+
+// unmarshalTolerantJSONArray разбирает JSON-массив из ответа модели так же, как unmarshalTolerantJSON
+func unmarshalTolerantJSONArray(response string, v interface{}) error {
+	return unmarshalTolerantJSONValue(response, '[', ']', v)
+}
+
+// unmarshalTolerantJSONValue разбирает JSON-значение, ограниченное скобками open и closing
+func unmarshalTolerantJSONValue(response string, open, closing byte, v interface{}) error {
+	err := json.Unmarshal([]byte(response), v)
+	if err == nil {
+		return nil
+	}
+
+	cleaned := strings.TrimSpace(markdownFencePattern.ReplaceAllString(response, ""))
+	start := strings.IndexByte(cleaned, open)
+	if start < 0 {
+		return err
+	}
+	cleaned = cleaned[start:]
+
+	if end := strings.LastIndexByte(cleaned, closing); end >= 0 {
+		if json.Unmarshal([]byte(cleaned[:end+1]), v) == nil {
+			return nil
+		}
+	}
+
+	if completed, ok := completeTruncatedJSON(cleaned); ok && json.Unmarshal([]byte(completed), v) == nil {
+		return nil
+	}
+	return err
+}
+
+// completeTruncatedJSON дописывает оборванный JSON: закрывает строку и открытые массивы и объекты.
+// Незавершенная последняя пара "ключ: значение" отбрасывается до предыдущей запятой.
+func completeTruncatedJSON(s string) (string, bool) {
+	var stack []byte
+	inString, escaped := false, false
+	lastComma := -1
+	var stackAtComma []byte
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != c {
+				return "", false
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return s[:i+1], true
+			}
+		case ',':
+			lastComma = i
+			stackAtComma = append(stackAtComma[:0], stack...)
+		}
+	}
+	if len(stack) == 0 {
+		return "", false
+	}
+
+	closeStack := func(prefix string, open []byte) string {
+		var sb strings.Builder
+		sb.WriteString(strings.TrimRight(prefix, " \t\r\n,:"))
+		for i := len(open) - 1; i >= 0; i-- {
+			sb.WriteByte(open[i])
+		}
+		return sb.String()
+	}
+
+	candidate := s
+	if escaped {
+		candidate = candidate[:len(candidate)-1]
+	}
+	if inString {
+		candidate += `"`
+	}
+	completed := closeStack(candidate, stack)
+	if json.Valid([]byte(completed)) {
+		return completed, true
+	}
+	if lastComma >= 0 {
+		completed = closeStack(s[:lastComma], stackAtComma)
+		if json.Valid([]byte(completed)) {
+			return completed, true
+		}
+	}
+	return "", false
+}
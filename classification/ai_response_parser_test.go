@@ -0,0 +1,181 @@
+package classification
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAIClassifierParseTolerantResponse(t *testing.T) {
+	classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
+
+	tests := []struct {
+		name     string
+		response string
+		path     []string
+	}{
+		{"plain", `{"category_path": ["Товары", "Крепеж"], "confidence": 0.9}`, []string{"Товары", "Крепеж"}},
+		{"fenced with prose", "Вот результат:\n```json\n{\"category_path\": [\"Товары\"], \"confidence\": 0.8}\n```\nНадеюсь, это поможет.", []string{"Товары"}},
+		{"trailing text", `{"category_path": ["Услуги"], "confidence": 0.8} Пояснение: {см. выше}`, []string{"Услуги"}},
+		{"truncated string", `{"category_path": ["Товары", "Кре`, []string{"Товары", "Кре"}},
+		{"truncated after field", `{"category_path": ["Товары"], "confidence": 0.8, "reasoning": "болт М8 отно`, []string{"Товары"}},
+		{"truncated number", "```json\n{\"category_path\": [\"Товары\"], \"confidence\": 0.", []string{"Товары"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := classifier.parseAIResponse(tt.response)
+			if err != nil {
+				t.Fatalf("parseAIResponse() error = %v", err)
+			}
+			if strings.Join(resp.CategoryPath, "/") != strings.Join(tt.path, "/") {
+				t.Errorf("CategoryPath = %v, want %v", resp.CategoryPath, tt.path)
+			}
+		})
+	}
+
+	if _, err := classifier.parseAIResponse("Не удалось классифицировать"); err == nil {
+		t.Error("Expected error for response without JSON")
+	}
+}
+
+func TestAIClassifierParseStrictWithoutStripMarkdown(t *testing.T) {
+	classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
+	config := classifier.GetConfig()
+	config.StripMarkdown = false
+	if err := classifier.SetConfig(config); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+
+	if _, err := classifier.parseAIResponse(`{"category_path": ["Товары"]} Пояснение`); err == nil {
+		t.Error("Expected error for trailing text when StripMarkdown is disabled")
+	}
+}
+
+func TestAIClassifierCallAIRetries(t *testing.T) {
+	classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
+	config := classifier.GetConfig()
+	config.MaxRetries = 2
+	config.RetryBackoff = time.Millisecond
+	config.EnableLogging = false
+	if err := classifier.SetConfig(config); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+
+	t.Run("5xx and empty body are retried", func(t *testing.T) {
+		responses := []struct {
+			text string
+			err  error
+		}{
+			{"", errors.New("API returned status 502: Bad Gateway")},
+			{"  ", nil},
+			{`{"category_path": ["Товары"]}`, nil},
+		}
+		calls := 0
+		classifier.SetCompletionFunc(func(ctx context.Context, prompt string) (string, error) {
+			r := responses[calls]
+			calls++
+			return r.text, r.err
+		})
+
+		result, err := classifier.callAI("system", "prompt")
+		if err != nil {
+			t.Fatalf("callAI() error = %v", err)
+		}
+		if calls != 3 || !strings.Contains(result, "Товары") {
+			t.Errorf("calls = %d, result = %q", calls, result)
+		}
+	})
+
+	t.Run("retries are limited", func(t *testing.T) {
+		calls := 0
+		classifier.SetCompletionFunc(func(ctx context.Context, prompt string) (string, error) {
+			calls++
+			return "", errors.New("API returned status 503: Service Unavailable")
+		})
+
+		if _, err := classifier.callAI("system", "prompt"); err == nil {
+			t.Fatal("Expected error after retries are exhausted")
+		}
+		if calls != 3 {
+			t.Errorf("calls = %d, want 3", calls)
+		}
+	})
+
+	t.Run("4xx is not retried", func(t *testing.T) {
+		calls := 0
+		classifier.SetCompletionFunc(func(ctx context.Context, prompt string) (string, error) {
+			calls++
+			return "", errors.New("API returned status 401: Unauthorized")
+		})
+
+		if _, err := classifier.callAI("system", "prompt"); err == nil {
+			t.Fatal("Expected error for 401")
+		}
+		if calls != 1 {
+			t.Errorf("calls = %d, want 1", calls)
+		}
+	})
+}
+
+func TestAIClassifierParseAlternatives(t *testing.T) {
+	classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
+	config := classifier.GetConfig()
+	config.MaxAlternatives = 2
+	if err := classifier.SetConfig(config); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+
+	resp, err := classifier.parseAIResponse(`{"category_path": ["Крепеж", "Болты"], "confidence": 1.4,
+		"alternatives": [
+			{"category_path": ["Крепеж", "Винты"], "confidence": 0.2},
+			{"category_path": ["Крепеж", "Болты"], "confidence": 0.9},
+			{"category_path": ["Метизы"], "score": 0.6},
+			{"category_path": [], "confidence": 0.5},
+			{"category_path": ["Инструмент"], "confidence": -1}
+		]}`)
+	if err != nil {
+		t.Fatalf("parseAIResponse() error = %v", err)
+	}
+	if resp.Confidence != 1 {
+		t.Errorf("Confidence = %v, want clamped 1", resp.Confidence)
+	}
+
+	// Основная категория и пустой путь отбрасываются, остальные ранжируются и обрезаются до MaxAlternatives
+	want := []CategorySuggestion{
+		{CategoryPath: []string{"Метизы"}, Confidence: 0.6},
+		{CategoryPath: []string{"Крепеж", "Винты"}, Confidence: 0.2},
+	}
+	if len(resp.Suggestions) != len(want) {
+		t.Fatalf("Suggestions = %+v, want %+v", resp.Suggestions, want)
+	}
+	for i := range want {
+		if strings.Join(resp.Suggestions[i].CategoryPath, "/") != strings.Join(want[i].CategoryPath, "/") ||
+			resp.Suggestions[i].Confidence != want[i].Confidence {
+			t.Errorf("Suggestions[%d] = %+v, want %+v", i, resp.Suggestions[i], want[i])
+		}
+		if strings.Join(resp.Alternatives[i], "/") != strings.Join(want[i].CategoryPath, "/") {
+			t.Errorf("Alternatives[%d] = %v, want %v", i, resp.Alternatives[i], want[i].CategoryPath)
+		}
+	}
+}
+
+func TestAIClassifierParseLegacyAlternatives(t *testing.T) {
+	classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
+
+	resp, err := classifier.parseAIResponse(`{"category_path": ["Кабель"], "alternatives": [["Провод"], ["Кабель"], []]}`)
+	if err != nil {
+		t.Fatalf("parseAIResponse() error = %v", err)
+	}
+	if resp.Confidence != defaultAIConfidence {
+		t.Errorf("Confidence = %v, want default %v", resp.Confidence, defaultAIConfidence)
+	}
+	if len(resp.Alternatives) != 1 || resp.Alternatives[0][0] != "Провод" {
+		t.Errorf("Alternatives = %v, want [[Провод]]", resp.Alternatives)
+	}
+	if len(resp.Suggestions) != 1 || resp.Suggestions[0].Confidence != 0 {
+		t.Errorf("Suggestions = %+v", resp.Suggestions)
+	}
+}
@@ -3,6 +3,8 @@ package classification
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 )
 
 // CategoryNode представляет узел в дереве классификатора
@@ -118,6 +120,98 @@ func (n *CategoryNode) Clone() *CategoryNode {
 	return clone
 }
 
+// Walk обходит узел и всех его потомков в глубину, начиная с самого узла.
+// fn получает указатели на узлы дерева и может их изменять.
+func (n *CategoryNode) Walk(fn func(*CategoryNode)) {
+	fn(n)
+	for i := range n.Children {
+		n.Children[i].Walk(fn)
+	}
+}
+
+// FindByPath находит узел с указанным Path среди узла и его потомков (nil - не найден)
+func (n *CategoryNode) FindByPath(path string) *CategoryNode {
+	path = strings.TrimSpace(path)
+	if n.Path == path {
+		return n
+	}
+	for i := range n.Children {
+		if found := n.Children[i].FindByPath(path); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// categoryNodeJSON представление CategoryNode без собственных методов сериализации
+type categoryNodeJSON CategoryNode
+
+// MarshalJSON сериализует узел вместе с поддеревом. ParentID и Level потомков записываются
+// по фактической структуре дерева, даже если поддерево было собрано до AddChild.
+func (n CategoryNode) MarshalJSON() ([]byte, error) {
+	node := categoryNodeJSON(n)
+	node.Children = make([]CategoryNode, len(n.Children))
+	for i, child := range n.Children {
+		child.ParentID = n.ID
+		child.Level = n.Level + 1
+		node.Children[i] = child
+	}
+	return json.Marshal(node)
+}
+
+// UnmarshalJSON восстанавливает узел с поддеревом: связи потомков с родителями (ParentID)
+// и уровни пересчитываются по вложенности, пустые Children и Metadata инициализируются,
+// как в NewCategoryNode
+func (n *CategoryNode) UnmarshalJSON(data []byte) error {
+	var node categoryNodeJSON
+	if err := json.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	*n = CategoryNode(node)
+	n.relink()
+	return nil
+}
+
+// relink восстанавливает ParentID и Level потомков и инициализирует пустые коллекции
+func (n *CategoryNode) relink() {
+	if n.Children == nil {
+		n.Children = make([]CategoryNode, 0)
+	}
+	if n.Metadata == nil {
+		n.Metadata = make(map[string]interface{})
+	}
+	for i := range n.Children {
+		n.Children[i].ParentID = n.ID
+		n.Children[i].Level = n.Level + 1
+		n.Children[i].relink()
+	}
+}
+
+// LoadCategoryTree загружает дерево классификатора из JSON файла, сохраненного SaveCategoryTree
+func LoadCategoryTree(filename string) (*CategoryNode, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read category tree: %w", err)
+	}
+	root := &CategoryNode{}
+	if err := json.Unmarshal(data, root); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal category tree %s: %w", filename, err)
+	}
+	return root, nil
+}
+
+// SaveCategoryTree сохраняет дерево классификатора в JSON файл
+func SaveCategoryTree(filename string, root *CategoryNode) error {
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal category tree: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write category tree: %w", err)
+	}
+	return nil
+}
+
 // BaseFoldingStrategy базовая реализация стратегии свертки
 type BaseFoldingStrategy struct {
 	ID          string        `json:"id"`
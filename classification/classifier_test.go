@@ -2,6 +2,8 @@ package classification
 
 import (
 	"encoding/json"
+	"fmt"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -602,7 +604,9 @@ func TestAIClassifierConfig(t *testing.T) {
 		MaxCategoryNameLen: 30,
 		EnableLogging:      false,
 	}
-	classifier.SetConfig(newConfig)
+	if err := classifier.SetConfig(newConfig); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
 	
 	updatedConfig := classifier.GetConfig()
 	if updatedConfig.MaxCategories != 10 {
@@ -616,6 +620,109 @@ func TestAIClassifierConfig(t *testing.T) {
 	}
 }
 
+func TestAIClassifierCustomPromptTemplates(t *testing.T) {
+	classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
+
+	root := NewCategoryNode("root", "Root", "/root", 0)
+	root.AddChild(NewCategoryNode("tools", "Инструмент", "/root/tools", 1))
+	root.AddChild(NewCategoryNode("fasteners", "Крепеж", "/root/fasteners", 1))
+	classifier.SetClassifierTree(root)
+
+	config := classifier.GetConfig()
+	config.EnableLogging = false
+	config.SystemPromptTemplate = "Дерево: {{.Categories}}"
+	config.UserPromptTemplate = "Товар={{.ItemName}}; Описание={{if .Description}}{{.Description}}{{else}}нет{{end}}; Выбор из [{{.Categories}}]"
+	if err := classifier.SetConfig(config); err != nil {
+		t.Fatalf("SetConfig() with valid templates failed: %v", err)
+	}
+
+	request := AIClassificationRequest{ItemName: "Молоток слесарный", Description: "500 г"}
+
+	userPrompt := classifier.buildClassificationPrompt(request)
+	expectedUser := "Товар=Молоток слесарный; Описание=500 г; Выбор из [Инструмент, Крепеж]"
+	if userPrompt != expectedUser {
+		t.Errorf("Expected user prompt %q, got %q", expectedUser, userPrompt)
+	}
+
+	systemPrompt := classifier.buildSystemPrompt(request)
+	if systemPrompt != "Дерево: Инструмент, Крепеж" {
+		t.Errorf("Unexpected system prompt %q", systemPrompt)
+	}
+
+	noDescription := classifier.buildClassificationPrompt(AIClassificationRequest{ItemName: "Болт"})
+	if !strings.Contains(noDescription, "Описание=нет") {
+		t.Errorf("Expected empty description branch, got %q", noDescription)
+	}
+}
+
+func TestAIClassifierDefaultPromptTemplates(t *testing.T) {
+	classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
+	if err := classifier.SetConfig(AIClassifierConfig{MaxCategories: 15, MaxCategoryNameLen: 50}); err != nil {
+		t.Fatalf("SetConfig() error = %v", err)
+	}
+
+	prompt := classifier.buildClassificationPrompt(AIClassificationRequest{ItemName: "Кабель", Description: "медный"})
+	expected := `Классифицируй: Кабель медный
+
+Категории: Классификатор не загружен
+
+JSON: {"category_path": ["Категория"], "confidence": 0.9, "reasoning": "кратко", "alternatives": [{"category_path": ["Другая категория"], "confidence": 0.3}]}`
+	if prompt != expected {
+		t.Errorf("Default user prompt changed:\n%s", prompt)
+	}
+
+	if system := classifier.buildSystemPrompt(AIClassificationRequest{}); system != DefaultAISystemPromptTemplate {
+		t.Errorf("Expected default system prompt, got %q", system)
+	}
+}
+
+func TestAIClassifierCategoryListIsDeterministic(t *testing.T) {
+	names := []string{"Электроника", "Крепеж", "Инструмент", "Кабель", "Сантехника"}
+
+	buildList := func(order []int) string {
+		root := NewCategoryNode("root", "Классификатор", "/", 0)
+		for _, i := range order {
+			root.AddChild(NewCategoryNode(fmt.Sprintf("cat%d", i), names[i], fmt.Sprintf("/cat%d", i), 1))
+		}
+		classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
+		if err := classifier.SetConfig(AIClassifierConfig{MaxCategories: 3, MaxCategoryNameLen: 50}); err != nil {
+			t.Fatalf("SetConfig() error = %v", err)
+		}
+		classifier.SetClassifierTree(root)
+		return classifier.buildCompactCategoryList(3)
+	}
+
+	first := buildList([]int{0, 1, 2, 3, 4})
+	second := buildList([]int{4, 2, 0, 3, 1})
+	if first != second {
+		t.Fatalf("Category lists differ for the same tree:\n%s\n%s", first, second)
+	}
+	if expected := "Инструмент, Кабель, Крепеж ... (+2)"; first != expected {
+		t.Errorf("Expected categories in name order %q, got %q", expected, first)
+	}
+}
+
+func TestAIClassifierSetConfigRejectsInvalidTemplates(t *testing.T) {
+	classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
+	original := classifier.GetConfig()
+
+	invalid := []AIClassifierConfig{
+		{MaxCategories: 5, MaxCategoryNameLen: 30, UserPromptTemplate: "{{.ItemName"},
+		{MaxCategories: 5, MaxCategoryNameLen: 30, UserPromptTemplate: "{{.UnknownField}}"},
+		{MaxCategories: 5, MaxCategoryNameLen: 30, SystemPromptTemplate: "{{range .ItemName}}{{end}}"},
+	}
+
+	for i, config := range invalid {
+		if err := classifier.SetConfig(config); err == nil {
+			t.Errorf("Case %d: expected SetConfig() to reject invalid template", i)
+		}
+	}
+
+	if classifier.GetConfig().MaxCategories != original.MaxCategories {
+		t.Error("Config must not change when template validation fails")
+	}
+}
+
 func TestAIClassifierPerformanceStats(t *testing.T) {
 	classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
 	
@@ -772,3 +879,113 @@ func TestClassificationResultJSON(t *testing.T) {
 		t.Errorf("Expected confidence 0.95, got %f", result2.Confidence)
 	}
 }
+
+// buildTestCategoryTree строит трехуровневое дерево: Корень -> Товары -> Крепеж -> Болты
+func buildTestCategoryTree() *CategoryNode {
+	bolts := NewCategoryNode("bolts", "Болты", "/goods/fasteners/bolts", 0)
+	bolts.Metadata["code"] = "25.94.11"
+	fasteners := NewCategoryNode("fasteners", "Крепеж", "/goods/fasteners", 0)
+	fasteners.AddChild(bolts)
+	goods := NewCategoryNode("goods", "Товары", "/goods", 0)
+	goods.AddChild(fasteners)
+	goods.AddChild(NewCategoryNode("cables", "Кабель", "/goods/cables", 0))
+
+	root := NewCategoryNode("root", "Корень", "/", 0)
+	root.AddChild(goods)
+	root.AddChild(NewCategoryNode("services", "Услуги", "/services", 0))
+	return root
+}
+
+func TestCategoryNodeWalkAndFindByPath(t *testing.T) {
+	root := buildTestCategoryTree()
+
+	var ids []string
+	root.Walk(func(node *CategoryNode) {
+		ids = append(ids, node.ID)
+	})
+	if got := strings.Join(ids, ","); got != "root,goods,fasteners,bolts,cables,services" {
+		t.Errorf("Walk order = %s", got)
+	}
+
+	bolts := root.FindByPath("/goods/fasteners/bolts")
+	if bolts == nil || bolts.ID != "bolts" {
+		t.Fatalf("FindByPath(bolts) = %v", bolts)
+	}
+	if root.FindByPath("/goods/unknown") != nil {
+		t.Error("Expected nil for unknown path")
+	}
+
+	// Walk и FindByPath возвращают узлы самого дерева, а не копии
+	root.FindByPath("/goods/cables").Metadata["checked"] = true
+	if root.Children[0].Children[1].Metadata["checked"] != true {
+		t.Error("Expected FindByPath to return node from the tree")
+	}
+}
+
+func TestCategoryNodeJSONRoundTrip(t *testing.T) {
+	root := buildTestCategoryTree()
+
+	data, err := json.Marshal(root)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var restored CategoryNode
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	bolts := restored.FindByPath("/goods/fasteners/bolts")
+	if bolts == nil {
+		t.Fatal("Expected deep node after round trip")
+	}
+	// Уровни и родители восстанавливаются по вложенности, хотя Болты добавлены до Крепежа в Товары
+	if bolts.ParentID != "fasteners" || bolts.Level != 3 {
+		t.Errorf("bolts ParentID = %q, Level = %d, want fasteners, 3", bolts.ParentID, bolts.Level)
+	}
+	if bolts.Metadata["code"] != "25.94.11" {
+		t.Errorf("bolts Metadata = %v", bolts.Metadata)
+	}
+
+	count := 0
+	restored.Walk(func(node *CategoryNode) {
+		count++
+		if node.Children == nil || node.Metadata == nil {
+			t.Errorf("node %s has nil Children or Metadata", node.ID)
+		}
+	})
+	if count != 6 {
+		t.Errorf("restored tree has %d nodes, want 6", count)
+	}
+
+	// Повторная сериализация дает тот же JSON
+	again, err := json.Marshal(&restored)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("Second marshal differs:\n%s\n%s", data, again)
+	}
+}
+
+func TestCategoryTreeSaveLoad(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "tree.json")
+	if err := SaveCategoryTree(filename, buildTestCategoryTree()); err != nil {
+		t.Fatalf("SaveCategoryTree() error = %v", err)
+	}
+
+	root, err := LoadCategoryTree(filename)
+	if err != nil {
+		t.Fatalf("LoadCategoryTree() error = %v", err)
+	}
+
+	classifier := NewAIClassifier("test_api_key", "GLM-4.5-Air")
+	classifier.SetClassifierTree(root)
+	if !classifier.CodeExists([]string{"Товары", "Крепеж", "Болты"}) {
+		t.Error("Expected loaded tree to contain Товары/Крепеж/Болты")
+	}
+
+	if _, err := LoadCategoryTree(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
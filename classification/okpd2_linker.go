@@ -0,0 +1,290 @@
+package classification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"httpserver/database"
+	"httpserver/nomenclature"
+)
+
+// Okpd2LinkItem наименование номенклатуры с коротким списком кандидатов ОКПД2 для AI
+type Okpd2LinkItem struct {
+	Key        string                    `json:"-"`
+	Name       string                    `json:"name"`
+	Candidates []database.Okpd2Candidate `json:"candidates"`
+}
+
+// Okpd2Suggestion выбранный AI код для наименования (Key из Okpd2LinkItem).
+// Пустой Code означает, что ни один кандидат не подошел.
+type Okpd2Suggestion struct {
+	Key        string
+	Code       string
+	Confidence float64
+}
+
+// Okpd2Backend подбирает коды ОКПД2 для пачки наименований за один запрос.
+// Вторым значением возвращается оценка потраченных токенов.
+type Okpd2Backend interface {
+	SuggestOkpd2(ctx context.Context, items []Okpd2LinkItem) ([]Okpd2Suggestion, int, error)
+}
+
+// Okpd2LinkerConfig настройки связывания номенклатуры с ОКПД2
+type Okpd2LinkerConfig struct {
+	Workers        int     // Параллельных запросов к AI (по умолчанию 4)
+	BatchSize      int     // Наименований в одном запросе (по умолчанию 10)
+	CandidateLimit int     // Кандидатов ОКПД2 на наименование (по умолчанию 10)
+	MinConfidence  float64 // Минимальная уверенность для связывания
+	DryRun         bool    // Только подобрать коды, не изменяя client_benchmarks
+
+	// Срок жизни ответа "код не найден" в кэше (по умолчанию database.DefaultOkpd2LinkNegativeCacheTTL)
+	NegativeCacheTTL time.Duration
+}
+
+// Okpd2LinkStats итоги связывания
+type Okpd2LinkStats struct {
+	Processed       int `json:"processed"`
+	Linked          int `json:"linked"`
+	Skipped         int `json:"skipped"`
+	Failed          int `json:"failed"`
+	UniqueNames     int `json:"unique_names"`
+	CacheHits       int `json:"cache_hits"`
+	AIRequests      int `json:"ai_requests"`
+	EstimatedTokens int `json:"estimated_tokens"`
+}
+
+// Okpd2Linker связывает номенклатуры эталонов без ОКПД2 с кодами, предложенными AI.
+// Одинаковые наименования отправляются один раз, ответы сохраняются в okpd2_link_cache
+// и переиспользуются при следующих запусках.
+type Okpd2Linker struct {
+	db      *database.ServiceDB
+	backend Okpd2Backend
+	config  Okpd2LinkerConfig
+}
+
+// NewOkpd2Linker создает связыватель ОКПД2
+func NewOkpd2Linker(db *database.ServiceDB, backend Okpd2Backend, config Okpd2LinkerConfig) *Okpd2Linker {
+	if config.Workers <= 0 {
+		config.Workers = 4
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 10
+	}
+	if config.CandidateLimit <= 0 {
+		config.CandidateLimit = 10
+	}
+	if config.NegativeCacheTTL <= 0 {
+		config.NegativeCacheTTL = database.DefaultOkpd2LinkNegativeCacheTTL
+	}
+	return &Okpd2Linker{db: db, backend: backend, config: config}
+}
+
+// Run обрабатывает до limit несвязанных номенклатур (limit <= 0 - все)
+func (l *Okpd2Linker) Run(ctx context.Context, limit int) (*Okpd2LinkStats, error) {
+	items, err := l.db.GetUnlinkedNomenclatures(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &Okpd2LinkStats{Processed: len(items)}
+
+	// Уникальные наименования: сначала ищем в кэше, остальные отправляем в AI
+	results := make(map[string]database.Okpd2LinkCacheEntry)
+	var pending []Okpd2LinkItem
+	seen := make(map[string]bool)
+	for _, item := range items {
+		key := database.Okpd2LinkNameKey(item.Name)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		cached, err := l.db.GetOkpd2LinkCache(key)
+		if err != nil {
+			return nil, err
+		}
+		// Устаревший отрицательный ответ не используется: наименование подбирается заново
+		if cached != nil && (cached.Code != "" || time.Since(cached.CreatedAt) < l.config.NegativeCacheTTL) {
+			stats.CacheHits++
+			results[key] = *cached
+			continue
+		}
+
+		candidates, err := l.db.SearchOkpd2Candidates(item.Name, l.config.CandidateLimit)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) == 0 {
+			// Без кандидатов AI выбирать не из чего, запрос не нужен
+			entry := database.Okpd2LinkCacheEntry{NameKey: key}
+			if err := l.db.SaveOkpd2LinkCache(&entry); err != nil {
+				return nil, err
+			}
+			results[key] = entry
+			continue
+		}
+		pending = append(pending, Okpd2LinkItem{Key: key, Name: item.Name, Candidates: candidates})
+	}
+	stats.UniqueNames = len(seen)
+
+	if err := l.suggest(ctx, pending, results, stats); err != nil {
+		return stats, err
+	}
+
+	for _, item := range items {
+		entry, ok := results[database.Okpd2LinkNameKey(item.Name)]
+		if !ok {
+			stats.Failed++
+			continue
+		}
+		if entry.Code == "" || entry.Confidence < l.config.MinConfidence {
+			stats.Skipped++
+			continue
+		}
+		if !l.config.DryRun {
+			if err := l.db.LinkNomenclatureOkpd2(item.ID, entry.Code); err != nil {
+				log.Printf("[Okpd2Linker] nomenclature %d: %v", item.ID, err)
+				stats.Failed++
+				continue
+			}
+		}
+		stats.Linked++
+	}
+
+	return stats, nil
+}
+
+// suggest отправляет пачки наименований в AI ограниченным пулом воркеров.
+// Ответы записываются в кэш в вызывающей горутине, чтобы не писать в SQLite параллельно.
+func (l *Okpd2Linker) suggest(ctx context.Context, pending []Okpd2LinkItem, results map[string]database.Okpd2LinkCacheEntry, stats *Okpd2LinkStats) error {
+	type batchResult struct {
+		suggestions []Okpd2Suggestion
+		tokens      int
+		err         error
+	}
+
+	batches := make(chan []Okpd2LinkItem)
+	out := make(chan batchResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < l.config.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for batch := range batches {
+				suggestions, tokens, err := l.backend.SuggestOkpd2(ctx, batch)
+				out <- batchResult{suggestions: suggestions, tokens: tokens, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(batches)
+		for start := 0; start < len(pending); start += l.config.BatchSize {
+			end := start + l.config.BatchSize
+			if end > len(pending) {
+				end = len(pending)
+			}
+			select {
+			case batches <- pending[start:end]:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	var saveErr error
+	for result := range out {
+		stats.AIRequests++
+		stats.EstimatedTokens += result.tokens
+		if result.err != nil {
+			log.Printf("[Okpd2Linker] AI request failed: %v", result.err)
+			continue
+		}
+		for _, suggestion := range result.suggestions {
+			entry := database.Okpd2LinkCacheEntry{
+				NameKey:    suggestion.Key,
+				Code:       suggestion.Code,
+				Confidence: suggestion.Confidence,
+			}
+			if saveErr == nil {
+				saveErr = l.db.SaveOkpd2LinkCache(&entry)
+			}
+			results[suggestion.Key] = entry
+		}
+	}
+
+	if saveErr != nil {
+		return saveErr
+	}
+	return ctx.Err()
+}
+
+// AIOkpd2Backend выбирает коды ОКПД2 из кандидатов через AIClient
+type AIOkpd2Backend struct {
+	client *nomenclature.AIClient
+}
+
+// NewAIOkpd2Backend создает AI бэкенд для связывания с ОКПД2
+func NewAIOkpd2Backend(apiKey, model string) *AIOkpd2Backend {
+	return &AIOkpd2Backend{client: nomenclature.NewAIClient(apiKey, model)}
+}
+
+const okpd2SystemPrompt = `Ты эксперт по классификатору ОКПД2. Для каждого товара выбери один код из его списка кандидатов.
+Если ни один кандидат не подходит, верни пустой код. Ответь только JSON массивом:
+[{"index": 0, "code": "25.94.11.120", "confidence": 0.9}]`
+
+// SuggestOkpd2 отправляет пачку наименований одним запросом
+func (b *AIOkpd2Backend) SuggestOkpd2(ctx context.Context, items []Okpd2LinkItem) ([]Okpd2Suggestion, int, error) {
+	var prompt strings.Builder
+	for i, item := range items {
+		fmt.Fprintf(&prompt, "%d. %s\n", i, item.Name)
+		for _, candidate := range item.Candidates {
+			fmt.Fprintf(&prompt, "   - %s %s\n", candidate.Code, candidate.Name)
+		}
+	}
+
+	response, err := b.client.GetCompletionWithContext(ctx, okpd2SystemPrompt, prompt.String())
+	// Приблизительная оценка, как в AIClassifier.estimateTokens: ~3 символа на токен
+	tokens := len([]rune(okpd2SystemPrompt+prompt.String()+response)) / 3
+	if err != nil {
+		return nil, tokens, fmt.Errorf("AI API call failed: %w", err)
+	}
+
+	var answers []struct {
+		Index      int     `json:"index"`
+		Code       string  `json:"code"`
+		Confidence float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(response), &answers); err != nil {
+		return nil, tokens, fmt.Errorf("failed to parse AI response: %w, response: %s", err, response)
+	}
+
+	suggestions := make([]Okpd2Suggestion, 0, len(answers))
+	for _, answer := range answers {
+		if answer.Index < 0 || answer.Index >= len(items) {
+			continue
+		}
+		item := items[answer.Index]
+		// Код вне списка кандидатов не принимается: AI мог его выдумать
+		code := ""
+		for _, candidate := range item.Candidates {
+			if candidate.Code == strings.TrimSpace(answer.Code) {
+				code = candidate.Code
+				break
+			}
+		}
+		suggestions = append(suggestions, Okpd2Suggestion{Key: item.Key, Code: code, Confidence: answer.Confidence})
+	}
+	return suggestions, tokens, nil
+}
@@ -0,0 +1,180 @@
+package classification
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"httpserver/database"
+)
+
+// fakeOkpd2Backend выбирает первого кандидата и считает запросы по каждому наименованию
+type fakeOkpd2Backend struct {
+	mu         sync.Mutex
+	calls      map[string]int
+	requests   int
+	confidence map[string]float64
+}
+
+func (f *fakeOkpd2Backend) SuggestOkpd2(ctx context.Context, items []Okpd2LinkItem) ([]Okpd2Suggestion, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.requests++
+	suggestions := make([]Okpd2Suggestion, 0, len(items))
+	for _, item := range items {
+		f.calls[item.Key]++
+		confidence := 0.9
+		if c, ok := f.confidence[item.Key]; ok {
+			confidence = c
+		}
+		suggestions = append(suggestions, Okpd2Suggestion{Key: item.Key, Code: item.Candidates[0].Code, Confidence: confidence})
+	}
+	return suggestions, 100, nil
+}
+
+func TestOkpd2Linker_CacheAvoidsDuplicateCalls(t *testing.T) {
+	db, err := database.NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create service DB: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.GetConnection().Exec(`
+		INSERT INTO okpd2_classifier (code, name, level) VALUES
+			('25.94.11.120', 'Болты из черных металлов', 4),
+			('25.94.11.130', 'Гайки из черных металлов', 4),
+			('22.29.29.190', 'Изделия пластмассовые прочие', 4)
+	`)
+	if err != nil {
+		t.Fatalf("Failed to seed okpd2_classifier: %v", err)
+	}
+
+	client, err := db.CreateClient("Клиент", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	names := []string{"Болты М8", "болты  м8", "Болты М8", "Гайки М10", "Изделия пластмассовые", "Шайба"}
+	for _, name := range names {
+		if _, err := db.CreateClientBenchmark(project.ID, name, name, "nomenclature", "", "", "test", 0.9); err != nil {
+			t.Fatalf("CreateClientBenchmark failed: %v", err)
+		}
+	}
+
+	backend := &fakeOkpd2Backend{
+		calls:      make(map[string]int),
+		confidence: map[string]float64{"изделия пластмассовые": 0.4},
+	}
+	config := Okpd2LinkerConfig{Workers: 3, BatchSize: 1, MinConfidence: 0.7}
+
+	stats, err := NewOkpd2Linker(db, backend, config).Run(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// "Шайба" не имеет кандидатов и в AI не отправляется
+	if len(backend.calls) != 3 {
+		t.Errorf("Expected 3 distinct names sent to AI, got %v", backend.calls)
+	}
+	for key, count := range backend.calls {
+		if count != 1 {
+			t.Errorf("Name %q sent to AI %d times, want 1", key, count)
+		}
+	}
+	if stats.Processed != 6 || stats.Linked != 4 || stats.Skipped != 2 || stats.AIRequests != 3 || stats.EstimatedTokens != 300 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// Повторный запуск: несвязанные наименования берутся из кэша, AI не вызывается
+	config.MinConfidence = 0.3
+	stats, err = NewOkpd2Linker(db, backend, config).Run(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	if backend.requests != 3 {
+		t.Errorf("Expected no new AI requests on second run, got %d total", backend.requests)
+	}
+	if stats.Processed != 2 || stats.CacheHits != 2 || stats.Linked != 1 || stats.Skipped != 1 {
+		t.Errorf("Unexpected stats on second run: %+v", stats)
+	}
+
+	var linkedCode string
+	err = db.GetConnection().QueryRow(`
+		SELECT o.code FROM client_benchmarks cb
+		JOIN okpd2_classifier o ON o.id = cb.okpd2_reference_id
+		WHERE cb.original_name = 'Гайки М10'
+	`).Scan(&linkedCode)
+	if err != nil || !strings.HasPrefix(linkedCode, "25.94.11.130") {
+		t.Errorf("Expected 'Гайки М10' linked to 25.94.11.130, got %q (%v)", linkedCode, err)
+	}
+}
+
+func TestOkpd2Linker_StaleNegativeCacheIsRetried(t *testing.T) {
+	db, err := database.NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create service DB: %v", err)
+	}
+	defer db.Close()
+
+	client, err := db.CreateClient("Клиент", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	if _, err := db.CreateClientBenchmark(project.ID, "Шайба М8", "Шайба М8", "nomenclature", "", "", "test", 0.9); err != nil {
+		t.Fatalf("CreateClientBenchmark failed: %v", err)
+	}
+
+	backend := &fakeOkpd2Backend{calls: make(map[string]int)}
+	config := Okpd2LinkerConfig{MinConfidence: 0.7, NegativeCacheTTL: time.Hour}
+
+	// Справочник пуст: кандидатов нет, в кэш попадает отрицательный ответ
+	stats, err := NewOkpd2Linker(db, backend, config).Run(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if stats.Skipped != 1 || backend.requests != 0 {
+		t.Fatalf("Expected the name skipped without AI requests, got %+v (requests %d)", stats, backend.requests)
+	}
+
+	_, err = db.GetConnection().Exec(`INSERT INTO okpd2_classifier (code, name, level) VALUES ('25.94.12.110', 'Шайбы из черных металлов', 4)`)
+	if err != nil {
+		t.Fatalf("Failed to seed okpd2_classifier: %v", err)
+	}
+
+	// Свежий отрицательный ответ берется из кэша
+	stats, err = NewOkpd2Linker(db, backend, config).Run(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Second run failed: %v", err)
+	}
+	if stats.CacheHits != 1 || stats.Linked != 0 || backend.requests != 0 {
+		t.Fatalf("Expected fresh negative entry served from cache, got %+v (requests %d)", stats, backend.requests)
+	}
+
+	// Устаревший отрицательный ответ подбирается заново
+	_, err = db.GetConnection().Exec(`UPDATE okpd2_link_cache SET created_at = datetime('now', '-2 hours')`)
+	if err != nil {
+		t.Fatalf("Failed to age cache entry: %v", err)
+	}
+	stats, err = NewOkpd2Linker(db, backend, config).Run(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Third run failed: %v", err)
+	}
+	if stats.CacheHits != 0 || stats.Linked != 1 || backend.requests != 1 {
+		t.Errorf("Expected stale negative entry retried and linked, got %+v (requests %d)", stats, backend.requests)
+	}
+
+	entry, err := db.GetOkpd2LinkCache(database.Okpd2LinkNameKey("Шайба М8"))
+	if err != nil || entry == nil || entry.Code != "25.94.12.110" {
+		t.Errorf("Expected cache entry replaced with the new code, got %+v (%v)", entry, err)
+	}
+}
@@ -141,6 +141,30 @@ func main() {
 		fmt.Printf("🔗 Номенклатур с производителем: %d\n", withManufacturer)
 	}
 
+	// Разбивка по ключу связи с производителем (заполняется при импорте)
+	matchRows, err := conn.Query(`
+		SELECT COALESCE(manufacturer_match_type, ''), COUNT(*)
+		FROM client_benchmarks
+		WHERE client_project_id = ?
+		AND category = 'nomenclature'
+		AND manufacturer_benchmark_id IS NOT NULL
+		GROUP BY COALESCE(manufacturer_match_type, '')
+	`, systemProject.ID)
+	if err == nil {
+		for matchRows.Next() {
+			var matchType string
+			var count int
+			if err := matchRows.Scan(&matchType, &count); err != nil {
+				continue
+			}
+			if matchType == "" {
+				matchType = "не указан"
+			}
+			fmt.Printf("   - по ключу %s: %d\n", matchType, count)
+		}
+		matchRows.Close()
+	}
+
 	// Статистика по утвержденным
 	var approvedCount int
 	err = conn.QueryRow(`
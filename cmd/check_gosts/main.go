@@ -1,14 +1,29 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 
 	"httpserver/database"
+	"httpserver/importer"
+	"httpserver/internal/config"
+	"httpserver/normalization"
 )
 
 func main() {
+	audit := flag.Bool("audit", false, "Проверить согласованность статусов и дат ГОСТов (код выхода 1 при нарушениях)")
+	regenKeywords := flag.Bool("regen-keywords", false, "Сгенерировать ключевые слова для ГОСТов с пустым полем keywords")
+	force := flag.Bool("force", false, "С -regen-keywords: перегенерировать ключевые слова у всех ГОСТов")
+	findSimilar := flag.Bool("find-similar", false, "Найти ГОСТы с похожими названиями (кандидаты в дубликаты для ручной проверки)")
+	threshold := flag.Float64("threshold", 0.85, "С -find-similar: минимальное сходство названий (0..1]")
+	limit := flag.Int("limit", 100, "С -find-similar: максимум выводимых пар (0 - все)")
+	verifyProject := flag.Int("verify-references", 0, "Проверить ссылки номенклатур проекта с указанным ID на ГОСТы (код выхода 1 при отсутствующих или отмененных)")
+	serviceDBPath := flag.String("service-db", "service.db", "С -verify-references: путь к сервисной БД")
+	flag.Parse()
+
 	// Инициализируем базу данных
 	gostsDB, err := database.NewGostsDB("./gosts.db")
 	if err != nil {
@@ -16,6 +31,41 @@ func main() {
 	}
 	defer gostsDB.Close()
 
+	// Стоп-слова для ключевых слов - как на сервере (GOSTs БД не хранит конфигурацию, берем из окружения)
+	normalization.SetStopwords(normalization.DefaultStopwordSet(config.LoadStopwords()...))
+
+	if *audit {
+		found := runAudit(gostsDB)
+		gostsDB.Close()
+		if found > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *verifyProject > 0 {
+		problems := runVerifyReferences(gostsDB, *serviceDBPath, *verifyProject)
+		gostsDB.Close()
+		if problems > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *findSimilar {
+		runFindSimilar(gostsDB, *threshold, *limit)
+		return
+	}
+
+	if *regenKeywords {
+		updated, err := gostsDB.RegenerateGostKeywords(importer.GenerateKeywords, *force)
+		if err != nil {
+			log.Fatalf("Failed to regenerate keywords: %v", err)
+		}
+		fmt.Printf("Keywords regenerated for %d GOST(s)\n", updated)
+		return
+	}
+
 	// Получаем несколько записей для проверки
 	gosts, total, err := gostsDB.ListGosts(10, 0, "", "", "", "", "", "")
 	if err != nil {
@@ -56,3 +106,73 @@ func main() {
 		}
 	}
 }
+
+// runAudit выводит ГОСТы, статус которых противоречит датам, и возвращает их количество
+func runAudit(gostsDB *database.GostsDB) int {
+	inconsistencies, err := gostsDB.FindInconsistentGosts()
+	if err != nil {
+		log.Fatalf("Failed to audit gosts: %v", err)
+	}
+
+	if len(inconsistencies) == 0 {
+		fmt.Println("Audit: no inconsistent GOSTs found")
+		return 0
+	}
+
+	fmt.Printf("Audit: %d inconsistent GOST record(s)\n", len(inconsistencies))
+	fmt.Println(strings.Repeat("=", 80))
+	for _, item := range inconsistencies {
+		fmt.Printf("%s [%s] (id=%d): %s\n", item.GostNumber, item.Rule, item.GostID, item.Message)
+	}
+
+	return len(inconsistencies)
+}
+
+// runVerifyReferences выводит ссылки номенклатур проекта на отсутствующие и отмененные ГОСТы
+// и возвращает их количество
+func runVerifyReferences(gostsDB *database.GostsDB, serviceDBPath string, projectID int) int {
+	serviceDB, err := database.NewServiceDB(serviceDBPath)
+	if err != nil {
+		log.Fatalf("Failed to open service database: %v", err)
+	}
+	defer serviceDB.Close()
+
+	report, err := serviceDB.VerifyTuGostReferences(projectID, gostsDB)
+	if err != nil {
+		log.Fatalf("Failed to verify TU/GOST references: %v", err)
+	}
+
+	fmt.Printf("Project %d: %d reference(s) checked, %d valid, %d missing, %d withdrawn, %d skipped (TU)\n",
+		projectID, report.Total, report.Valid, report.Missing, report.Withdrawn, report.Skipped)
+	for _, check := range report.References {
+		if check.Result != database.TuGostReferenceMissing && check.Result != database.TuGostReferenceWithdrawn {
+			continue
+		}
+		fmt.Printf("%s [%s] benchmarks=%v: %s\n", check.Code, check.Result, check.BenchmarkIDs, check.Message)
+	}
+
+	return report.Missing + report.Withdrawn
+}
+
+// runFindSimilar выводит пары ГОСТов с похожими названиями. Ничего не объединяет:
+// решение о дубликате принимает человек.
+func runFindSimilar(gostsDB *database.GostsDB, threshold float64, limit int) {
+	pairs, err := gostsDB.FindSimilarTitles(threshold, limit)
+	if err != nil {
+		log.Fatalf("Failed to find similar gosts: %v", err)
+	}
+
+	if len(pairs) == 0 {
+		fmt.Printf("No GOSTs with title similarity >= %.2f found\n", threshold)
+		return
+	}
+
+	fmt.Printf("%d pair(s) of GOSTs with title similarity >= %.2f\n", len(pairs), threshold)
+	fmt.Println(strings.Repeat("=", 80))
+	for _, pair := range pairs {
+		fmt.Printf("\n%.2f  %s (id=%d, %s)\n", pair.Similarity, pair.First.GostNumber, pair.First.ID, pair.First.SourceType)
+		fmt.Printf("      %s\n", pair.First.Title)
+		fmt.Printf("      %s (id=%d, %s)\n", pair.Second.GostNumber, pair.Second.ID, pair.Second.SourceType)
+		fmt.Printf("      %s\n", pair.Second.Title)
+	}
+}
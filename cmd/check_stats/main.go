@@ -5,6 +5,8 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"sort"
+	"time"
 
 	"httpserver/database"
 	"httpserver/normalization"
@@ -45,6 +47,12 @@ func main() {
 		}
 	}
 
+	sessionID, phaseTimings, err := serviceDB.GetLatestSessionPhaseTimings(*projectID)
+	if err != nil {
+		log.Printf("failed to get phase timings: %v", err)
+	}
+	printPhaseTimings(sessionID, phaseTimings)
+
 	if *printJSON {
 		payload, err := json.MarshalIndent(stats, "", "  ")
 		if err != nil {
@@ -56,3 +64,34 @@ func main() {
 }
 
 const timeLayout = "2006-01-02 15:04:05"
+
+// printPhaseTimings выводит время по фазам последней сессии нормализации, от самой долгой фазы
+func printPhaseTimings(sessionID int, timings map[string]time.Duration) {
+	fmt.Println("\nPhase Timings:")
+	if sessionID == 0 || len(timings) == 0 {
+		fmt.Println("  No phase timings recorded.")
+		return
+	}
+
+	phases := make([]string, 0, len(timings))
+	var total time.Duration
+	for phase, duration := range timings {
+		phases = append(phases, phase)
+		total += duration
+	}
+	sort.Slice(phases, func(i, j int) bool {
+		if timings[phases[i]] != timings[phases[j]] {
+			return timings[phases[i]] > timings[phases[j]]
+		}
+		return phases[i] < phases[j]
+	})
+
+	fmt.Printf("  Session ID: %d\n", sessionID)
+	for _, phase := range phases {
+		share := 0.0
+		if total > 0 {
+			share = float64(timings[phase]) / float64(total) * 100
+		}
+		fmt.Printf("  - %-16s %12s (%.1f%%)\n", phase, timings[phase].Round(time.Millisecond), share)
+	}
+}
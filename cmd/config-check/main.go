@@ -14,7 +14,8 @@ func main() {
 	// Загружаем конфигурацию
 	cfg, err := config.LoadConfig()
 	if err != nil {
-		fmt.Printf("❌ Ошибка загрузки конфигурации: %v\n", err)
+		fmt.Println("❌ Ошибка загрузки конфигурации:")
+		printValidationErrors(err)
 		os.Exit(1)
 	}
 
@@ -69,7 +70,8 @@ func main() {
 
 	// Проверяем валидацию
 	if err := cfg.Validate(); err != nil {
-		fmt.Printf("⚠️  Предупреждения валидации: %v\n", err)
+		fmt.Println("⚠️  Предупреждения валидации:")
+		printValidationErrors(err)
 		fmt.Println("")
 	} else {
 		fmt.Println("✅ Валидация пройдена успешно")
@@ -79,3 +81,10 @@ func main() {
 	fmt.Println("=== Проверка завершена ===")
 }
 
+// printValidationErrors выводит каждое нарушение конфигурации отдельным пунктом
+func printValidationErrors(err error) {
+	for _, e := range config.ValidationErrors(err) {
+		fmt.Printf("  • %v\n", e)
+	}
+}
+
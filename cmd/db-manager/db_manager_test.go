@@ -1,12 +1,19 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
+
+	"httpserver/database"
 )
 
 // createTestDBFile создает валидный SQLite файл для тестирования
@@ -335,3 +342,355 @@ func TestCleanupCommand_NoServiceDB(t *testing.T) {
 	}
 }
 
+
+// setupBackupTree создает дерево баз данных для проверки отбора файлов backup
+func setupBackupTree(t *testing.T) string {
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("Failed to chdir: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(oldWd) })
+
+	uploadsDir := filepath.Join(tempDir, "data", "uploads")
+	os.MkdirAll(uploadsDir, 0755)
+	createTestDBFile(t, tempDir, "service.db")
+	createTestDBFile(t, tempDir, "main.db")
+	createTestDBFile(t, filepath.Join(tempDir, "data"), "normalized.db")
+	createTestDBFile(t, uploadsDir, "upload_1.db")
+	createTestDBFile(t, uploadsDir, "upload_test.db")
+	os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte("not a database"), 0644)
+	return tempDir
+}
+
+func backupArchivePaths(entries []database.BackupEntry) map[string]bool {
+	paths := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		paths[filepath.ToSlash(entry.ArchivePath)] = true
+	}
+	return paths
+}
+
+// TestCollectBackupEntries_Filters проверяет флаги --include, --exclude и --with-service
+func TestCollectBackupEntries_Filters(t *testing.T) {
+	setupBackupTree(t)
+	scanPaths := []string{".", "data", "data/uploads"}
+
+	tests := []struct {
+		name   string
+		filter func() backupFilter
+		want   []string
+	}{
+		{
+			name:   "default",
+			filter: func() backupFilter { return backupFilter{} },
+			want:   []string{"main/main.db", "main/normalized.db", "uploads/upload_1.db", "uploads/upload_test.db"},
+		},
+		{
+			name:   "with service",
+			filter: func() backupFilter { return backupFilter{withService: true} },
+			want:   []string{"service/service.db", "main/main.db", "main/normalized.db", "uploads/upload_1.db", "uploads/upload_test.db"},
+		},
+		{
+			name: "include path glob",
+			filter: func() backupFilter {
+				var f backupFilter
+				f.include.Set("data/uploads/*")
+				return f
+			},
+			want: []string{"uploads/upload_1.db", "uploads/upload_test.db"},
+		},
+		{
+			name: "exclude wins over include",
+			filter: func() backupFilter {
+				var f backupFilter
+				f.include.Set("upload_*.db,main.db")
+				f.exclude.Set("*_test.db")
+				return f
+			},
+			want: []string{"main/main.db", "uploads/upload_1.db"},
+		},
+		{
+			name: "include does not bypass with-service",
+			filter: func() backupFilter {
+				var f backupFilter
+				f.include.Set("service.db")
+				return f
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := collectBackupEntries(scanPaths, tt.filter())
+			got := backupArchivePaths(entries)
+			if len(entries) != len(tt.want) {
+				t.Fatalf("Expected %d entries %v, got %v", len(tt.want), tt.want, got)
+			}
+			for _, want := range tt.want {
+				if !got[want] {
+					t.Errorf("Expected %s in backup, got %v", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestGlobList_RejectsInvalidPattern(t *testing.T) {
+	var g globList
+	if err := g.Set("[data"); err == nil {
+		t.Error("Expected error for invalid glob")
+	}
+}
+
+// TestBackupManifest проверяет manifest.json в архиве backup
+func TestBackupManifest(t *testing.T) {
+	tempDir := setupBackupTree(t)
+	entries := collectBackupEntries([]string{"."}, backupFilter{})
+	zipPath := filepath.Join(tempDir, "backup.zip")
+
+	result, err := database.WriteBackupArchiveWithManifest(context.Background(), zipPath, entries, nil)
+	if err != nil {
+		t.Fatalf("WriteBackupArchiveWithManifest failed: %v", err)
+	}
+
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open backup: %v", err)
+	}
+	defer reader.Close()
+
+	var manifest database.BackupManifest
+	found := false
+	for _, file := range reader.File {
+		if file.Name != database.BackupManifestName {
+			continue
+		}
+		found = true
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open manifest: %v", err)
+		}
+		err = json.NewDecoder(rc).Decode(&manifest)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("Failed to decode manifest: %v", err)
+		}
+	}
+	if !found {
+		t.Fatalf("%s not found in backup", database.BackupManifestName)
+	}
+	if len(manifest.Files) != result.FilesCount || len(reader.File) != result.FilesCount+1 {
+		t.Fatalf("Manifest lists %d files, archive has %d entries, backed up %d",
+			len(manifest.Files), len(reader.File), result.FilesCount)
+	}
+
+	for _, entry := range manifest.Files {
+		if !filepath.IsAbs(entry.SourcePath) {
+			t.Errorf("Manifest path is not absolute: %s", entry.SourcePath)
+		}
+		info, err := os.Stat(entry.SourcePath)
+		if err != nil {
+			t.Errorf("Manifest path %s does not exist: %v", entry.SourcePath, err)
+			continue
+		}
+		if entry.Size != info.Size() || !entry.ModTime.Equal(info.ModTime()) {
+			t.Errorf("Manifest entry %+v does not match file (size %d, modtime %s)", entry, info.Size(), info.ModTime())
+		}
+		if entry.ArchivePath == "uploads/upload_1.db" && entry.SourcePath != filepath.Join(tempDir, "data", "uploads", "upload_1.db") {
+			t.Errorf("Unexpected source path for %s: %s", entry.ArchivePath, entry.SourcePath)
+		}
+	}
+}
+
+// TestRestore_RoundTripRelinksUploads проверяет цикл backup -> удаление -> restore
+// и возврат восстановленной загруженной базы в project_databases
+func TestRestore_RoundTripRelinksUploads(t *testing.T) {
+	tempDir := setupBackupTree(t)
+	uploadPath := filepath.Join(tempDir, "data", "uploads", "upload_1.db")
+	original, err := os.ReadFile(uploadPath)
+	if err != nil {
+		t.Fatalf("Failed to read upload: %v", err)
+	}
+
+	serviceDB, err := database.NewServiceDB(filepath.Join(tempDir, "data", "service.db"))
+	if err != nil {
+		t.Fatalf("Failed to open service database: %v", err)
+	}
+	defer serviceDB.Close()
+	client, err := serviceDB.CreateClient("Client", "Client LLC", "", "", "", "", "tests")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Project", "nomenclature", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	projectDB, err := serviceDB.CreateProjectDatabase(project.ID, "Upload", uploadPath, "", int64(len(original)))
+	if err != nil {
+		t.Fatalf("Failed to register upload: %v", err)
+	}
+
+	zipPath := filepath.Join(tempDir, "backup.zip")
+	var filter backupFilter
+	filter.include.Set("upload_1.db")
+	entries := collectBackupEntries([]string{"data/uploads"}, filter)
+	if _, err := database.WriteBackupArchiveWithManifest(context.Background(), zipPath, entries, nil); err != nil {
+		t.Fatalf("WriteBackupArchiveWithManifest failed: %v", err)
+	}
+
+	// Файл удален, запись отключена
+	if err := os.Remove(uploadPath); err != nil {
+		t.Fatalf("Failed to delete upload: %v", err)
+	}
+	if err := serviceDB.UpdateProjectDatabase(projectDB.ID, projectDB.Name, projectDB.FilePath, "", false); err != nil {
+		t.Fatalf("Failed to deactivate project database: %v", err)
+	}
+
+	result, err := database.RestoreBackupArchive(context.Background(), zipPath, database.BackupRestoreOptions{})
+	if err != nil {
+		t.Fatalf("RestoreBackupArchive failed: %v", err)
+	}
+	if len(result.Restored) != 1 {
+		t.Fatalf("Expected 1 restored upload, got %+v", result.Restored)
+	}
+	restored, err := os.ReadFile(uploadPath)
+	if err != nil || string(restored) != string(original) {
+		t.Fatalf("Upload was not restored to its original path: %v", err)
+	}
+
+	relinked := relinkRestoredDatabases(serviceDB, result)
+	if len(relinked) != 1 || relinked[0].ID != projectDB.ID {
+		t.Fatalf("Expected project database %d to be re-linked, got %+v", projectDB.ID, relinked)
+	}
+	if !relinked[0].IsActive || relinked[0].FileSize != int64(len(original)) {
+		t.Errorf("Re-linked database is not active or has wrong size: %+v", relinked[0])
+	}
+}
+
+// TestFindCleanupCandidates проверяет, что cleanup выбирает только несвязанные базы нужного возраста
+func TestFindCleanupCandidates(t *testing.T) {
+	tempDir := t.TempDir()
+	oldWd, _ := os.Getwd()
+	os.Chdir(tempDir)
+	defer os.Chdir(oldWd)
+
+	uploadsDir := filepath.Join(tempDir, "data", "uploads")
+	os.MkdirAll(uploadsDir, 0755)
+	linkedFile := createTestDBFile(t, uploadsDir, "linked.db")
+	staleOrphan := createTestDBFile(t, uploadsDir, "stale_orphan.db")
+	freshOrphan := createTestDBFile(t, uploadsDir, "fresh_orphan.db")
+
+	now := time.Now()
+	old := now.Add(-10 * 24 * time.Hour)
+	for _, path := range []string{linkedFile, staleOrphan} {
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("Failed to set modtime: %v", err)
+		}
+	}
+
+	serviceDB, err := database.NewServiceDB(filepath.Join(tempDir, "data", "service.db"))
+	if err != nil {
+		t.Fatalf("Failed to open service database: %v", err)
+	}
+	defer serviceDB.Close()
+	client, err := serviceDB.CreateClient("Client", "Client LLC", "", "", "", "", "tests")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Project", "nomenclature", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := serviceDB.CreateProjectDatabase(project.ID, "Linked", linkedFile, "", 16); err != nil {
+		t.Fatalf("Failed to register linked database: %v", err)
+	}
+
+	candidatePaths := func(candidates []cleanupCandidate) map[string]bool {
+		paths := make(map[string]bool, len(candidates))
+		for _, candidate := range candidates {
+			paths[filepath.Base(candidate.path)] = true
+		}
+		return paths
+	}
+
+	all := candidatePaths(findCleanupCandidates(serviceDB, []string{"data/uploads"}, 0, now))
+	if len(all) != 2 || !all["stale_orphan.db"] || !all["fresh_orphan.db"] {
+		t.Errorf("Expected both orphans without age filter, got %v", all)
+	}
+
+	stale := findCleanupCandidates(serviceDB, []string{"data/uploads"}, 168*time.Hour, now)
+	if len(stale) != 1 || filepath.Base(stale[0].path) != "stale_orphan.db" || stale[0].size != 16 {
+		t.Errorf("Expected only stale orphan with --older-than=168h, got %+v", stale)
+	}
+
+	// Поиск кандидатов ничего не удаляет
+	for _, path := range []string{linkedFile, staleOrphan, freshOrphan} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("File %s was removed: %v", path, err)
+		}
+	}
+}
+
+// TestListCommand_JSON проверяет вывод list --json: валидный JSON, сортировка по пути и поля связи с проектом
+func TestListCommand_JSON(t *testing.T) {
+	tempDir := setupBackupTree(t)
+	linkedFile := filepath.Join(tempDir, "data", "uploads", "upload_1.db")
+
+	serviceDB, err := database.NewServiceDB(filepath.Join(tempDir, "data", "service.db"))
+	if err != nil {
+		t.Fatalf("Failed to open service database: %v", err)
+	}
+	defer serviceDB.Close()
+	client, err := serviceDB.CreateClient("Client", "Client LLC", "", "", "", "", "tests")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Project", "nomenclature", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	projectDB, err := serviceDB.CreateProjectDatabase(project.ID, "Upload", linkedFile, "", 16)
+	if err != nil {
+		t.Fatalf("Failed to register upload: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeDatabaseFilesJSON(&buf, collectDatabaseFiles(serviceDB, []string{".", "data", "data/uploads"})); err != nil {
+		t.Fatalf("writeDatabaseFilesJSON failed: %v", err)
+	}
+
+	var files []struct {
+		Path            string `json:"path"`
+		Type            string `json:"type"`
+		Protected       bool   `json:"protected"`
+		LinkedToProject *bool  `json:"linked_to_project"`
+		ProjectID       *int   `json:"project_id"`
+		DatabaseID      *int   `json:"database_id"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &files); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	// service.db, data/service.db, main.db, data/normalized.db, два файла в uploads
+	if len(files) != 6 {
+		t.Fatalf("Expected 6 files, got %d:\n%s", len(files), buf.String())
+	}
+
+	for i, file := range files {
+		if i > 0 && files[i-1].Path >= file.Path {
+			t.Errorf("Files are not sorted by path: %s before %s", files[i-1].Path, file.Path)
+		}
+		if file.LinkedToProject == nil || file.ProjectID == nil || file.DatabaseID == nil {
+			t.Errorf("Missing link fields for %s", file.Path)
+			continue
+		}
+		if file.Path == linkedFile {
+			if !*file.LinkedToProject || *file.ProjectID != project.ID || *file.DatabaseID != projectDB.ID || file.Type != "uploaded" {
+				t.Errorf("Unexpected entry for linked file: %+v", file)
+			}
+		} else if *file.LinkedToProject || *file.ProjectID != 0 || *file.DatabaseID != 0 {
+			t.Errorf("Unlinked file %s reported as linked", file.Path)
+		}
+	}
+}
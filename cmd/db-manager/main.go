@@ -1,18 +1,24 @@
 package main
 
 import (
-	"archive/zip"
+	"context"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"os/signal"
+	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"syscall"
 	"time"
 
 	"httpserver/database"
+	"httpserver/importer"
 )
 
 func main() {
@@ -30,8 +36,18 @@ func main() {
 		handleDelete()
 	case "backup":
 		handleBackup()
+	case "restore":
+		handleRestore()
 	case "cleanup":
 		handleCleanup()
+	case "orphans":
+		handleOrphans()
+	case "reindex":
+		handleReindex()
+	case "dedupe-benchmarks":
+		handleDedupeBenchmarks()
+	case "clean-temp":
+		handleCleanTemp()
 	default:
 		fmt.Printf("Unknown command: %s\n\n", command)
 		printUsage()
@@ -45,19 +61,42 @@ func printUsage() {
 	fmt.Println("Usage: db-manager <command> [options]")
 	fmt.Println()
 	fmt.Println("Commands:")
-	fmt.Println("  list                    List all database files")
+	fmt.Println("  list [--json]           List all database files")
 	fmt.Println("  delete <path>           Delete a database file")
-	fmt.Println("  backup [--output=path]  Create a backup of all databases")
-	fmt.Println("  cleanup                 Delete unused databases")
+	fmt.Println("  backup [--output=path] [--include=glob] [--exclude=glob] [--with-service]")
+	fmt.Println("                          Create a backup of databases with manifest.json (service.db only with --with-service)")
+	fmt.Println("  restore <backup.zip> [--target=dir] [--overwrite]")
+	fmt.Println("                          Restore databases from a backup and re-link uploads to their projects")
+	fmt.Println("  cleanup [--dry-run] [--older-than=168h] [--yes]")
+	fmt.Println("                          Delete uploaded databases not linked to a project (requires --yes)")
+	fmt.Println("  orphans [--db=path] [--repair] [--reassign]")
+	fmt.Println("                          Find uploads referencing deleted databases, clients or projects")
+	fmt.Println("  reindex [--steps=list] [--gosts-db=path]")
+	fmt.Println("                          Rebuild GOST FTS index, client stats, database sizes and reference links")
+	fmt.Println("  dedupe-benchmarks --project=id [--dry-run]")
+	fmt.Println("                          Merge duplicate benchmarks of a project (same category, name and INN)")
+	fmt.Println("  clean-temp [--older-than=24h] [--dir=data/temp] [--force]")
+	fmt.Println("                          Delete old downloaded import files (failed imports are kept without --force)")
 	fmt.Println()
 	fmt.Println("Examples:")
 	fmt.Println("  db-manager list")
 	fmt.Println("  db-manager delete data/uploads/test.db")
 	fmt.Println("  db-manager backup --output=backup.zip")
-	fmt.Println("  db-manager cleanup")
+	fmt.Println("  db-manager backup --include='data/*' --exclude='*_test.db' --with-service")
+	fmt.Println("  db-manager restore data/backups/backup_20240101_120000.zip")
+	fmt.Println("  db-manager cleanup --older-than=168h --dry-run")
+	fmt.Println("  db-manager cleanup --older-than=168h --yes")
+	fmt.Println("  db-manager orphans --db=data/data.db --repair --reassign")
+	fmt.Println("  db-manager reindex --steps=client_stats,database_sizes")
+	fmt.Println("  db-manager dedupe-benchmarks --project=1 --dry-run")
+	fmt.Println("  db-manager clean-temp --older-than=72h")
 }
 
 func handleList() {
+	listFlag := flag.NewFlagSet("list", flag.ExitOnError)
+	asJSON := listFlag.Bool("json", false, "Print the list as a JSON array sorted by path")
+	listFlag.Parse(os.Args[2:])
+
 	serviceDBPath := "data/service.db"
 	if _, err := os.Stat(serviceDBPath); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -73,14 +112,6 @@ func handleList() {
 		defer serviceDB.Close()
 	}
 
-	// Защищенные файлы
-	protectedFiles := map[string]bool{
-		"service.db":         true,
-		"1c_data.db":         true,
-		"data.db":            true,
-		"normalized_data.db": true,
-	}
-
 	scanPaths := []string{
 		".",
 		"data",
@@ -90,6 +121,46 @@ func handleList() {
 		"/app/data/uploads",
 	}
 
+	allFiles := collectDatabaseFiles(serviceDB, scanPaths)
+	if *asJSON {
+		if err := writeDatabaseFilesJSON(os.Stdout, allFiles); err != nil {
+			log.Fatalf("Failed to write JSON: %v", err)
+		}
+		return
+	}
+
+	// Выводим результаты
+	fmt.Printf("Found %d database files:\n\n", len(allFiles))
+	for _, file := range allFiles {
+		protected := ""
+		if file["protected"].(bool) {
+			protected = " [PROTECTED]"
+		}
+		linked := ""
+		if linkedToProject, ok := file["linked_to_project"].(bool); ok && linkedToProject {
+			linked = " [LINKED]"
+		}
+		fmt.Printf("%s%s%s\n", file["path"], protected, linked)
+		fmt.Printf("  Type: %s, Size: %d bytes, Modified: %s\n",
+			file["type"], file["size"], file["modified_at"])
+		if projectID, ok := file["project_id"]; ok {
+			fmt.Printf("  Project ID: %d\n", projectID)
+		}
+		fmt.Println()
+	}
+}
+
+// collectDatabaseFiles находит .db файлы в scanPaths и определяет их тип и связь с проектами.
+// serviceDB может быть nil - тогда связь с проектами не проверяется.
+func collectDatabaseFiles(serviceDB *database.ServiceDB, scanPaths []string) []map[string]interface{} {
+	// Защищенные файлы
+	protectedFiles := map[string]bool{
+		"service.db":         true,
+		"1c_data.db":         true,
+		"data.db":            true,
+		"normalized_data.db": true,
+	}
+
 	fileMap := make(map[string]bool)
 	var allFiles []map[string]interface{}
 
@@ -175,25 +246,31 @@ func handleList() {
 		}
 	}
 
-	// Выводим результаты
-	fmt.Printf("Found %d database files:\n\n", len(allFiles))
-	for _, file := range allFiles {
-		protected := ""
-		if file["protected"].(bool) {
-			protected = " [PROTECTED]"
-		}
-		linked := ""
-		if linkedToProject, ok := file["linked_to_project"].(bool); ok && linkedToProject {
-			linked = " [LINKED]"
+	return allFiles
+}
+
+// writeDatabaseFilesJSON выводит список файлов JSON массивом, отсортированным по пути.
+// Поля linked_to_project, project_id и database_id присутствуют всегда (false и 0 для несвязанных файлов).
+func writeDatabaseFilesJSON(w io.Writer, files []map[string]interface{}) error {
+	output := make([]map[string]interface{}, 0, len(files))
+	for _, file := range files {
+		entry := map[string]interface{}{
+			"linked_to_project": false,
+			"project_id":        0,
+			"database_id":       0,
 		}
-		fmt.Printf("%s%s%s\n", file["path"], protected, linked)
-		fmt.Printf("  Type: %s, Size: %d bytes, Modified: %s\n",
-			file["type"], file["size"], file["modified_at"])
-		if projectID, ok := file["project_id"]; ok {
-			fmt.Printf("  Project ID: %d\n", projectID)
+		for key, value := range file {
+			entry[key] = value
 		}
-		fmt.Println()
+		output = append(output, entry)
 	}
+	sort.Slice(output, func(i, j int) bool {
+		return output[i]["path"].(string) < output[j]["path"].(string)
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
 }
 
 func handleDelete() {
@@ -266,6 +343,10 @@ func handleDelete() {
 func handleBackup() {
 	outputFlag := flag.NewFlagSet("backup", flag.ExitOnError)
 	outputPath := outputFlag.String("output", "", "Output path for backup file")
+	var filter backupFilter
+	outputFlag.Var(&filter.include, "include", "Back up only files matching the glob (file name or path, repeatable)")
+	outputFlag.Var(&filter.exclude, "exclude", "Skip files matching the glob (file name or path, repeatable)")
+	outputFlag.BoolVar(&filter.withService, "with-service", false, "Also back up service.db")
 	outputFlag.Parse(os.Args[2:])
 
 	// Определяем путь к бэкапу
@@ -285,30 +366,98 @@ func handleBackup() {
 
 	backupPath := filepath.Join(backupDir, backupFileName)
 
-	// Создаем ZIP архив
-	zipFile, err := os.Create(backupPath)
+	// Ctrl+C прерывает бэкап; недописанный архив при этом удаляется
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Собираем файлы для бэкапа
+	entries := collectBackupEntries([]string{".", "data", "data/uploads"}, filter)
+	if len(entries) == 0 {
+		log.Fatalf("No database files matched the backup filters")
+	}
+
+	lastPrinted := time.Time{}
+	result, err := database.WriteBackupArchiveWithManifest(ctx, backupPath, entries, func(p database.BackupProgress) {
+		if time.Since(lastPrinted) < 500*time.Millisecond && p.FilesDone < p.FilesTotal {
+			return
+		}
+		lastPrinted = time.Now()
+		fmt.Printf("\rFiles: %d/%d, %d/%d bytes", p.FilesDone, p.FilesTotal, p.BytesDone, p.BytesTotal)
+	})
+	fmt.Println()
+	if errors.Is(err, context.Canceled) {
+		log.Fatalf("Backup cancelled, no archive was written")
+	}
 	if err != nil {
-		log.Fatalf("Failed to create backup file: %v", err)
+		log.Fatalf("Failed to create backup: %v", err)
 	}
-	defer zipFile.Close()
 
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
+	for _, skipped := range result.Skipped {
+		log.Printf("Failed to open file %s, skipped", skipped)
+	}
 
-	// Собираем файлы для бэкапа
-	scanPaths := []string{
-		".",
-		"data",
-		"data/uploads",
+	fmt.Printf("Backup created successfully: %s\n", backupPath)
+	fmt.Printf("Files: %d, Total size: %d bytes\n", result.FilesCount, result.TotalSize)
+}
+
+// globList значения повторяемого флага с glob-шаблонами (--include=*.db --include=data/*)
+type globList []string
+
+func (g *globList) String() string {
+	return strings.Join(*g, ",")
+}
+
+func (g *globList) Set(value string) error {
+	for _, pattern := range strings.Split(value, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+		*g = append(*g, filepath.ToSlash(pattern))
 	}
+	return nil
+}
 
-	fileMap := make(map[string]bool)
-	addedFiles := 0
-	totalSize := int64(0)
+// backupFilter отбор файлов для backup. Шаблон сравнивается с именем файла и с путем
+// относительно рабочего каталога (data/uploads/upload.db); exclude приоритетнее include.
+type backupFilter struct {
+	include     globList
+	exclude     globList
+	withService bool // service.db по умолчанию не копируется
+}
 
-	protectedFiles := map[string]bool{
-		"service.db": true,
+func (f backupFilter) matches(filePath string) bool {
+	if filepath.Base(filePath) == "service.db" && !f.withService {
+		return false
 	}
+	if matchesAnyGlob(f.exclude, filePath) {
+		return false
+	}
+	return len(f.include) == 0 || matchesAnyGlob(f.include, filePath)
+}
+
+func matchesAnyGlob(patterns []string, filePath string) bool {
+	slashPath := filepath.ToSlash(filepath.Clean(filePath))
+	name := filepath.Base(filePath)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, slashPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// collectBackupEntries находит .db файлы в scanPaths, прошедшие filter, и определяет их пути в архиве:
+// uploads/ для загруженных баз, service/ для service.db, main/ для остальных
+func collectBackupEntries(scanPaths []string, filter backupFilter) []database.BackupEntry {
+	fileMap := make(map[string]bool)
+	var entries []database.BackupEntry
 
 	for _, scanPath := range scanPaths {
 		if _, err := os.Stat(scanPath); err != nil {
@@ -342,43 +491,22 @@ func handleBackup() {
 			}
 			fileMap[absPath] = true
 
-			fileName := filepath.Base(absPath)
-			if protectedFiles[fileName] {
-				// Пропускаем service.db по умолчанию
+			if !filter.matches(filePath) {
 				return nil
 			}
 
 			// Определяем путь в архиве
+			fileName := filepath.Base(absPath)
 			var archivePath string
 			if strings.Contains(filePath, "uploads") {
 				archivePath = filepath.Join("uploads", fileName)
+			} else if fileName == "service.db" {
+				archivePath = filepath.Join("service", fileName)
 			} else {
 				archivePath = filepath.Join("main", fileName)
 			}
 
-			// Открываем файл
-			sourceFile, err := os.Open(filePath)
-			if err != nil {
-				log.Printf("Failed to open file %s: %v", filePath, err)
-				return nil
-			}
-			defer sourceFile.Close()
-
-			// Создаем запись в архиве
-			archiveFile, err := zipWriter.Create(archivePath)
-			if err != nil {
-				log.Printf("Failed to create archive entry for %s: %v", filePath, err)
-				return nil
-			}
-
-			// Копируем содержимое
-			if _, err := io.Copy(archiveFile, sourceFile); err != nil {
-				log.Printf("Failed to copy file %s to archive: %v", filePath, err)
-				return nil
-			}
-
-			addedFiles++
-			totalSize += info.Size()
+			entries = append(entries, database.BackupEntry{SourcePath: filePath, ArchivePath: archivePath})
 			return nil
 		})
 
@@ -387,11 +515,91 @@ func handleBackup() {
 		}
 	}
 
-	fmt.Printf("Backup created successfully: %s\n", backupPath)
-	fmt.Printf("Files: %d, Total size: %d bytes\n", addedFiles, totalSize)
+	return entries
+}
+
+func handleRestore() {
+	if len(os.Args) < 3 || strings.HasPrefix(os.Args[2], "-") {
+		fmt.Println("Error: backup path is required")
+		fmt.Println("Usage: db-manager restore <backup.zip> [--target=dir] [--overwrite]")
+		os.Exit(1)
+	}
+	zipPath := os.Args[2]
+
+	restoreFlag := flag.NewFlagSet("restore", flag.ExitOnError)
+	var options database.BackupRestoreOptions
+	restoreFlag.StringVar(&options.TargetDir, "target", "", "Restore into this data directory instead of the original paths")
+	restoreFlag.BoolVar(&options.Overwrite, "overwrite", false, "Replace existing files")
+	restoreFlag.Parse(os.Args[3:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	result, err := database.RestoreBackupArchive(ctx, zipPath, options)
+	if errors.Is(err, database.ErrRestoreTargetExists) {
+		log.Fatalf("%v\nRun with --overwrite to replace them", err)
+	}
+	if err != nil {
+		log.Fatalf("Failed to restore backup: %v", err)
+	}
+
+	for _, file := range result.Restored {
+		fmt.Printf("Restored: %s (%d bytes)\n", file.Path, file.Size)
+	}
+	for _, skipped := range result.Skipped {
+		log.Printf("Unknown archive entry %s, skipped", skipped)
+	}
+	if !result.UsedManifest {
+		fmt.Println("Backup has no manifest.json, files were restored by the main/ and uploads/ layout")
+	}
+
+	serviceDBPath := "data/service.db"
+	if _, err := os.Stat(serviceDBPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			serviceDBPath = "service.db"
+		}
+	}
+	if _, err := os.Stat(serviceDBPath); err == nil {
+		serviceDB, err := database.NewServiceDB(serviceDBPath)
+		if err != nil {
+			log.Fatalf("Failed to open service database: %v", err)
+		}
+		defer serviceDB.Close()
+
+		for _, projectDB := range relinkRestoredDatabases(serviceDB, result) {
+			fmt.Printf("Re-linked %s to project %d (database ID: %d)\n", projectDB.FilePath, projectDB.ClientProjectID, projectDB.ID)
+		}
+	}
+
+	fmt.Printf("\nRestore completed. Restored %d files.\n", len(result.Restored))
+}
+
+// relinkRestoredDatabases возвращает в работу записи project_databases восстановленных загруженных баз
+func relinkRestoredDatabases(serviceDB *database.ServiceDB, result *database.BackupRestoreResult) []*database.ProjectDatabase {
+	var relinked []*database.ProjectDatabase
+	for _, file := range result.Restored {
+		if !strings.HasPrefix(file.ArchivePath, "uploads/") {
+			continue
+		}
+		projectDB, err := serviceDB.RelinkRestoredProjectDatabase(file.Path)
+		if err != nil {
+			log.Printf("Warning: Failed to re-link %s: %v", file.Path, err)
+			continue
+		}
+		if projectDB != nil {
+			relinked = append(relinked, projectDB)
+		}
+	}
+	return relinked
 }
 
 func handleCleanup() {
+	cleanupFlag := flag.NewFlagSet("cleanup", flag.ExitOnError)
+	dryRun := cleanupFlag.Bool("dry-run", false, "Only list unused databases without deleting them")
+	olderThan := cleanupFlag.Duration("older-than", 0, "Only delete unused databases not modified for this long (e.g. 168h)")
+	confirmed := cleanupFlag.Bool("yes", false, "Confirm deletion of the listed databases")
+	cleanupFlag.Parse(os.Args[2:])
+
 	serviceDBPath := "data/service.db"
 	if _, err := os.Stat(serviceDBPath); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
@@ -405,16 +613,52 @@ func handleCleanup() {
 	}
 	defer serviceDB.Close()
 
-	// Получаем все базы данных из project_databases
-	// (здесь нужен метод для получения всех баз данных, но для упрощения
-	// мы просто сканируем файлы и проверяем, есть ли они в БД)
+	candidates := findCleanupCandidates(serviceDB, []string{"data/uploads"}, *olderThan, time.Now())
+	var totalSize int64
+	for _, candidate := range candidates {
+		totalSize += candidate.size
+	}
 
-	scanPaths := []string{
-		"data/uploads",
+	if *dryRun || !*confirmed {
+		for _, candidate := range candidates {
+			fmt.Printf("Would delete: %s (%d bytes, modified %s)\n",
+				candidate.path, candidate.size, candidate.modTime.Format("2006-01-02 15:04"))
+		}
+		fmt.Printf("\n%d unused database files, %d bytes would be reclaimed.\n", len(candidates), totalSize)
+		if !*dryRun && len(candidates) > 0 {
+			fmt.Println("Nothing was deleted. Run with --yes to delete these files.")
+			os.Exit(1)
+		}
+		return
 	}
 
-	fileMap := make(map[string]bool)
 	deletedCount := 0
+	var reclaimed int64
+	for _, candidate := range candidates {
+		if err := os.Remove(candidate.path); err != nil {
+			log.Printf("Failed to delete %s: %v", candidate.path, err)
+			continue
+		}
+		fmt.Printf("Deleted unused database: %s\n", candidate.path)
+		deletedCount++
+		reclaimed += candidate.size
+	}
+
+	fmt.Printf("\nCleanup completed. Deleted %d unused database files, reclaimed %d bytes.\n", deletedCount, reclaimed)
+}
+
+// cleanupCandidate неиспользуемая база данных, которую удаляет cleanup
+type cleanupCandidate struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// findCleanupCandidates находит в scanPaths .db файлы, не связанные ни с одним проектом
+// и не изменявшиеся дольше olderThan (0 - без ограничения по возрасту)
+func findCleanupCandidates(serviceDB *database.ServiceDB, scanPaths []string, olderThan time.Duration, now time.Time) []cleanupCandidate {
+	fileMap := make(map[string]bool)
+	var candidates []cleanupCandidate
 
 	for _, scanPath := range scanPaths {
 		if _, err := os.Stat(scanPath); err != nil {
@@ -448,16 +692,15 @@ func handleCleanup() {
 			}
 			fileMap[absPath] = true
 
+			if olderThan > 0 && now.Sub(info.ModTime()) < olderThan {
+				return nil
+			}
+
 			// Проверяем, есть ли файл в project_databases
 			_, projectID, err := serviceDB.FindClientAndProjectByDatabasePath(absPath)
 			if err != nil || projectID == 0 {
 				// Файл не связан с проектом - можно удалить
-				fmt.Printf("Deleting unused database: %s\n", absPath)
-				if err := os.Remove(absPath); err != nil {
-					log.Printf("Failed to delete %s: %v", absPath, err)
-				} else {
-					deletedCount++
-				}
+				candidates = append(candidates, cleanupCandidate{path: absPath, size: info.Size(), modTime: info.ModTime()})
 			}
 
 			return nil
@@ -468,6 +711,216 @@ func handleCleanup() {
 		}
 	}
 
-	fmt.Printf("\nCleanup completed. Deleted %d unused database files.\n", deletedCount)
+	return candidates
+}
+
+func handleOrphans() {
+	orphansFlag := flag.NewFlagSet("orphans", flag.ExitOnError)
+	dbPath := orphansFlag.String("db", "data/data.db", "Path to the database with uploads")
+	repair := orphansFlag.Bool("repair", false, "Null dangling database_id/client_id/project_id")
+	reassign := orphansFlag.Bool("reassign", false, "With --repair, restore client_id/project_id from the upload database")
+	orphansFlag.Parse(os.Args[2:])
+
+	serviceDBPath := "data/service.db"
+	if _, err := os.Stat(serviceDBPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			serviceDBPath = "service.db"
+		}
+	}
+
+	serviceDB, err := database.NewServiceDB(serviceDBPath)
+	if err != nil {
+		log.Fatalf("Failed to open service database: %v", err)
+	}
+	defer serviceDB.Close()
+
+	if _, err := os.Stat(*dbPath); err != nil {
+		log.Fatalf("Database not found: %s", *dbPath)
+	}
+	uploadsDB, err := database.NewDB(*dbPath)
+	if err != nil {
+		log.Fatalf("Failed to open database %s: %v", *dbPath, err)
+	}
+	defer uploadsDB.Close()
+
+	orphans, err := serviceDB.FindOrphanedUploads(uploadsDB)
+	if err != nil {
+		log.Fatalf("Failed to find orphaned uploads: %v", err)
+	}
+
+	if len(orphans) == 0 {
+		fmt.Println("No orphaned uploads found.")
+		return
+	}
+
+	fmt.Printf("Found %d orphaned uploads:\n", len(orphans))
+	for _, orphan := range orphans {
+		var missing []string
+		if orphan.MissingDatabase {
+			missing = append(missing, fmt.Sprintf("database_id=%d", *orphan.DatabaseID))
+		}
+		if orphan.MissingClient {
+			missing = append(missing, fmt.Sprintf("client_id=%d", *orphan.ClientID))
+		}
+		if orphan.MissingProject {
+			missing = append(missing, fmt.Sprintf("project_id=%d", *orphan.ProjectID))
+		}
+		fmt.Printf("  Upload %d (%s): missing %s\n", orphan.UploadID, orphan.UploadUUID, strings.Join(missing, ", "))
+	}
+
+	if !*repair {
+		fmt.Println("\nRun with --repair to fix dangling references.")
+		return
+	}
+
+	result, err := serviceDB.RepairOrphanedUploads(uploadsDB, *reassign)
+	if err != nil {
+		log.Fatalf("Failed to repair orphaned uploads: %v", err)
+	}
+
+	fmt.Printf("\nRepaired %d uploads (%d reassigned to the database owner).\n", result.Repaired, result.Reassigned)
+}
+
+func handleReindex() {
+	reindexFlag := flag.NewFlagSet("reindex", flag.ExitOnError)
+	stepsValue := reindexFlag.String("steps", "", "Comma-separated steps: "+strings.Join(database.ReindexSteps, ", ")+" (default: all)")
+	gostsDBPath := reindexFlag.String("gosts-db", "gosts.db", "Path to the GOSTs database")
+	reindexFlag.Parse(os.Args[2:])
+
+	steps, err := database.ParseReindexSteps(*stepsValue)
+	if err != nil {
+		log.Fatalf("Invalid --steps: %v", err)
+	}
+
+	serviceDBPath := "data/service.db"
+	if _, err := os.Stat(serviceDBPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			serviceDBPath = "service.db"
+		}
+	}
+
+	serviceDB, err := database.NewServiceDB(serviceDBPath)
+	if err != nil {
+		log.Fatalf("Failed to open service database: %v", err)
+	}
+	defer serviceDB.Close()
+
+	var gostsDB *database.GostsDB
+	for _, step := range steps {
+		if step != database.ReindexStepGostsFTS {
+			continue
+		}
+		if _, err := os.Stat(*gostsDBPath); err != nil {
+			log.Printf("Warning: GOSTs database not found: %s", *gostsDBPath)
+			break
+		}
+		if gostsDB, err = database.NewGostsDB(*gostsDBPath); err != nil {
+			log.Printf("Warning: Could not open GOSTs database: %v", err)
+			gostsDB = nil
+		} else {
+			defer gostsDB.Close()
+		}
+	}
+
+	report := database.RunReindex(serviceDB, gostsDB, steps)
+
+	if r := report.GostsFTS; r != nil {
+		fmt.Printf("gosts_fts:      %d GOSTs, %d missing from index, %d stale index rows\n", r.Gosts, r.Missing, r.Stale)
+	}
+	if r := report.ClientStats; r != nil {
+		fmt.Printf("client_stats:   %d clients, %d corrected\n", r.Clients, len(r.Changed))
+	}
+	if r := report.DatabaseSizes; r != nil {
+		fmt.Printf("database_sizes: %d checked, %d updated, %d files missing\n", r.Checked, len(r.Updated), len(r.Missing))
+		for _, change := range r.Updated {
+			fmt.Printf("  %s: %d -> %d bytes\n", change.FilePath, change.OldSize, change.NewSize)
+		}
+	}
+	if r := report.References; r != nil {
+		fmt.Printf("references:     %d benchmarks with missing codes\n", r.Checked)
+		for book, count := range r.Relinked {
+			fmt.Printf("  %s: %d relinked\n", book, count)
+		}
+		for book, count := range r.StillMissing {
+			fmt.Printf("  %s: %d still missing\n", book, count)
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		for step, message := range report.Errors {
+			fmt.Printf("%s: FAILED: %s\n", step, message)
+		}
+		os.Exit(1)
+	}
+}
+
+func handleCleanTemp() {
+	cleanFlag := flag.NewFlagSet("clean-temp", flag.ExitOnError)
+	olderThan := cleanFlag.Duration("older-than", 24*time.Hour, "Delete files not modified for this long")
+	dir := cleanFlag.String("dir", importer.DefaultTempDir, "Directory with downloaded import files")
+	force := cleanFlag.Bool("force", false, "Also delete files kept after failed imports")
+	cleanFlag.Parse(os.Args[2:])
+
+	result, err := importer.NewTempFileManager(*dir).Clean(*olderThan, *force)
+	if err != nil {
+		log.Fatalf("Failed to clean temp directory: %v", err)
+	}
+
+	for _, path := range result.Removed {
+		fmt.Printf("Deleted: %s\n", path)
+	}
+	if len(result.KeptFailed) > 0 {
+		fmt.Printf("Kept %d file(s) from failed imports, run with --force to delete them.\n", len(result.KeptFailed))
+	}
+	fmt.Printf("\nTemp cleanup completed. Deleted %d files (%d bytes).\n", len(result.Removed), result.FreedBytes)
 }
 
+func handleDedupeBenchmarks() {
+	dedupeFlag := flag.NewFlagSet("dedupe-benchmarks", flag.ExitOnError)
+	projectID := dedupeFlag.Int("project", 0, "ID of the client project to deduplicate")
+	dryRun := dedupeFlag.Bool("dry-run", false, "Only report duplicate groups without merging them")
+	dedupeFlag.Parse(os.Args[2:])
+
+	if *projectID <= 0 {
+		log.Fatalf("--project is required")
+	}
+
+	serviceDBPath := "data/service.db"
+	if _, err := os.Stat(serviceDBPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			serviceDBPath = "service.db"
+		}
+	}
+
+	serviceDB, err := database.NewServiceDB(serviceDBPath)
+	if err != nil {
+		log.Fatalf("Failed to open service database: %v", err)
+	}
+	defer serviceDB.Close()
+
+	result, err := serviceDB.MergeDuplicateBenchmarks(*projectID, *dryRun)
+	if err != nil {
+		log.Fatalf("Failed to merge duplicate benchmarks: %v", err)
+	}
+
+	if len(result.Groups) == 0 {
+		fmt.Printf("No duplicate benchmarks found in project %d.\n", *projectID)
+		return
+	}
+
+	for _, group := range result.Groups {
+		fmt.Printf("[%s] %q", group.Category, group.NormalizedName)
+		if group.TaxID != "" {
+			fmt.Printf(" (INN %s)", group.TaxID)
+		}
+		fmt.Printf(": keep %d, remove %v\n", group.KeepID, group.DuplicateIDs)
+	}
+
+	if result.DryRun {
+		fmt.Printf("\n%d duplicate groups, %d benchmarks would be removed. Run without --dry-run to merge.\n",
+			len(result.Groups), result.Deleted)
+		return
+	}
+	fmt.Printf("\nMerged %d duplicate groups: %d benchmarks removed, %d references moved.\n",
+		len(result.Groups), result.Deleted, result.Repointed)
+}
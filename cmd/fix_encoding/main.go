@@ -4,11 +4,8 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
-	"strings"
-	"unicode/utf8"
 
-	"golang.org/x/text/encoding/charmap"
-	"golang.org/x/text/transform"
+	"httpserver/database"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -48,14 +45,11 @@ func main() {
 
 		// Проверяем, нужно ли исправлять эту запись
 		needsFix := false
-		if gostNumber.Valid && (strings.Contains(gostNumber.String, "╨У") || strings.Contains(gostNumber.String, "╨Ю")) {
-			needsFix = true
-		}
-		if title.Valid && (strings.Contains(title.String, "╨У") || strings.Contains(title.String, "╨Ю")) {
-			needsFix = true
-		}
-		if status.Valid && (strings.Contains(status.String, "╨У") || strings.Contains(status.String, "╨Ю")) {
-			needsFix = true
+		for _, field := range []sql.NullString{gostNumber, title, status, description, keywords} {
+			if field.Valid && database.HasEncodingIssue(field.String) {
+				needsFix = true
+				break
+			}
 		}
 
 		if !needsFix {
@@ -103,42 +97,8 @@ func main() {
 	fmt.Printf("\nFixed %d out of %d records with encoding issues\n", fixedCount, totalCount)
 }
 
+// fixEncoding восстанавливает текст тем же декодером, который применяется при импорте и чтении ГОСТов
 func fixEncoding(text string) string {
-	if text == "" {
-		return text
-	}
-
-	// Проверяем, есть ли некорректные символы
-	if !strings.Contains(text, "╨У") && !strings.Contains(text, "╨Ю") {
-		return text
-	}
-
-	// Проблема: текст уже в UTF-8, но содержит символы "╨У" (0xD0 0xA3 в UTF-8)
-	// Эти байты должны быть интерпретированы как Windows-1251 символ "Г" (0xC3)
-	// Решение: берем UTF-8 байты и декодируем их как Windows-1251
-	decoder := charmap.Windows1251.NewDecoder()
-	
-	// Конвертируем UTF-8 строку в байты
-	utf8Bytes := []byte(text)
-	
-	// Декодируем эти байты как Windows-1251
-	decoded, _, err := transform.Bytes(decoder, utf8Bytes)
-	if err != nil {
-		return text // Если не удалось, возвращаем как есть
-	}
-
-	// Проверяем результат
-	if utf8.Valid(decoded) {
-		result := string(decoded)
-		// Проверяем, что после декодирования нет некорректных символов и есть правильные
-		if !strings.Contains(result, "╨У") && !strings.Contains(result, "╨Ю") {
-			// Проверяем, что есть правильные кириллические символы
-			if strings.Contains(result, "ГОСТ") || strings.Contains(result, "Стандарт") {
-				return result
-			}
-		}
-	}
-
-	return text
+	fixed, _ := database.RepairMojibake(text)
+	return fixed
 }
-
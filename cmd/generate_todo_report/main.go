@@ -1,12 +1,16 @@
 package main
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"html/template"
+	"io"
 	"log"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -33,19 +37,99 @@ type TodoDB struct {
 	Version  string     `json:"version"`
 }
 
+// TaskFilter фильтр задач по статусу, приоритету и исполнителю.
+// Внутри поля значения объединяются по ИЛИ, между полями - по И; пустое поле не ограничивает выборку.
+type TaskFilter struct {
+	Statuses   []string
+	Priorities []string
+	Assignees  []string // "unassigned" соответствует задачам без исполнителя
+}
+
+// parseFilterValues разбирает список значений фильтра, перечисленных через запятую
+func parseFilterValues(value string) []string {
+	var values []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return values
+}
+
+// matchesAny проверяет, совпадает ли значение (без учета регистра) хотя бы с одним из допустимых
+func matchesAny(value string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, candidate := range allowed {
+		if strings.EqualFold(value, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match проверяет, проходит ли задача фильтр
+func (f TaskFilter) Match(task TodoTask) bool {
+	assignee := task.AssignedTo
+	if assignee == "" {
+		assignee = "unassigned"
+	}
+	return matchesAny(task.Status, f.Statuses) &&
+		matchesAny(task.Priority, f.Priorities) &&
+		matchesAny(assignee, f.Assignees)
+}
+
+// filterTasks возвращает задачи, прошедшие фильтр
+func filterTasks(tasks []TodoTask, filter TaskFilter) []TodoTask {
+	filtered := make([]TodoTask, 0, len(tasks))
+	for _, task := range tasks {
+		if filter.Match(task) {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered
+}
+
+// loadTodoDB загружает базу задач из JSON-файла
+func loadTodoDB(path string) (TodoDB, error) {
+	var db TodoDB
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return db, fmt.Errorf("ошибка чтения БД: %w", err)
+	}
+	if err := json.Unmarshal(data, &db); err != nil {
+		return db, fmt.Errorf("ошибка парсинга БД: %w", err)
+	}
+	return db, nil
+}
+
 func main() {
-	dbPath := ".todos/tasks.json"
-	
+	var (
+		dbPath   = flag.String("db", ".todos/tasks.json", "Путь к базе задач")
+		status   = flag.String("status", "", "Фильтр по статусу (через запятую, например OPEN,IN_PROGRESS)")
+		priority = flag.String("priority", "", "Фильтр по приоритету (через запятую, например CRITICAL,HIGH)")
+		assignee = flag.String("assignee", "", "Фильтр по исполнителю (через запятую; unassigned - без исполнителя)")
+		mdPath   = flag.String("md", "TODO_REPORT.md", "Путь к Markdown отчету")
+		htmlPath = flag.String("html", ".todos/dashboard.html", "Путь к HTML отчету")
+		csvPath  = flag.String("csv", ".todos/tasks.csv", "Путь к CSV выгрузке задач")
+	)
+	flag.Parse()
+
 	// Загружаем БД
-	data, err := os.ReadFile(dbPath)
+	db, err := loadTodoDB(*dbPath)
 	if err != nil {
-		log.Fatalf("Ошибка чтения БД: %v", err)
+		log.Fatal(err)
 	}
 
-	var db TodoDB
-	if err := json.Unmarshal(data, &db); err != nil {
-		log.Fatalf("Ошибка парсинга БД: %v", err)
+	// Фильтры применяются ко всем форматам отчетов
+	filter := TaskFilter{
+		Statuses:   parseFilterValues(*status),
+		Priorities: parseFilterValues(*priority),
+		Assignees:  parseFilterValues(*assignee),
 	}
+	db.Tasks = filterTasks(db.Tasks, filter)
 
 	// Статистика
 	total := len(db.Tasks)
@@ -100,7 +184,7 @@ func main() {
 	reportMD := generateMarkdownReport(db, total, open, inProgress, resolved, critical, high, medium, low, criticalTasks, highTasks)
 	
 	// Сохраняем Markdown
-	if err := os.WriteFile("TODO_REPORT.md", []byte(reportMD), 0644); err != nil {
+	if err := os.WriteFile(*mdPath, []byte(reportMD), 0644); err != nil {
 		log.Fatalf("Ошибка записи Markdown: %v", err)
 	}
 
@@ -108,13 +192,68 @@ func main() {
 	reportHTML := generateHTMLReport(db, total, open, inProgress, resolved, critical, high, medium, low, criticalTasks, highTasks)
 	
 	// Сохраняем HTML
-	if err := os.WriteFile(".todos/dashboard.html", []byte(reportHTML), 0644); err != nil {
+	if err := os.WriteFile(*htmlPath, []byte(reportHTML), 0644); err != nil {
 		log.Fatalf("Ошибка записи HTML: %v", err)
 	}
 
-	fmt.Println("✅ Отчеты сгенерированы:")
-	fmt.Println("   - TODO_REPORT.md")
-	fmt.Println("   - .todos/dashboard.html")
+	// Сохраняем CSV со всеми полями задач
+	csvFile, err := os.Create(*csvPath)
+	if err != nil {
+		log.Fatalf("Ошибка создания CSV: %v", err)
+	}
+	if err := writeCSVReport(csvFile, db.Tasks); err != nil {
+		csvFile.Close()
+		log.Fatalf("Ошибка записи CSV: %v", err)
+	}
+	if err := csvFile.Close(); err != nil {
+		log.Fatalf("Ошибка записи CSV: %v", err)
+	}
+
+	fmt.Printf("✅ Отчеты сгенерированы (задач: %d):\n", total)
+	fmt.Println("   - " + *mdPath)
+	fmt.Println("   - " + *htmlPath)
+	fmt.Println("   - " + *csvPath)
+}
+
+// writeCSVReport выгружает задачи в CSV со всеми полями (заголовки совпадают с полями tasks.json)
+func writeCSVReport(w io.Writer, tasks []TodoTask) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"id", "file", "line", "type", "priority", "description", "status",
+		"assignedTo", "createdAt", "updatedAt", "estimatedHours"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		record := []string{
+			task.ID,
+			task.File,
+			strconv.Itoa(task.Line),
+			task.Type,
+			task.Priority,
+			task.Description,
+			task.Status,
+			task.AssignedTo,
+			task.CreatedAt.Format(time.RFC3339),
+			task.UpdatedAt.Format(time.RFC3339),
+			strconv.Itoa(task.EstimatedHours),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// completionRate возвращает процент завершенных задач (0 для пустой выборки)
+func completionRate(resolved, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(resolved) * 100 / float64(total)
 }
 
 func generateMarkdownReport(db TodoDB, total, open, inProgress, resolved, critical, high, medium, low int, criticalTasks, highTasks []TodoTask) string {
@@ -144,7 +283,7 @@ func generateMarkdownReport(db TodoDB, total, open, inProgress, resolved, critic
 		time.Now().Format("2006-01-02 15:04:05"),
 		formatTime(db.LastScan),
 		total, open, inProgress, resolved,
-		float64(resolved)*100/float64(total),
+		completionRate(resolved, total),
 		critical, high, medium, low,
 	)
 
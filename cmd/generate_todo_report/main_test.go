@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/csv"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// loadTestTodoDB загружает тестовую базу задач из testdata
+func loadTestTodoDB(t *testing.T) TodoDB {
+	t.Helper()
+
+	db, err := loadTodoDB(filepath.Join("testdata", "tasks.json"))
+	if err != nil {
+		t.Fatalf("Failed to load test tasks: %v", err)
+	}
+	if len(db.Tasks) != 6 {
+		t.Fatalf("Expected 6 seeded tasks, got %d", len(db.Tasks))
+	}
+	return db
+}
+
+// TestFilterTasks проверяет фильтрацию задач и комбинирование фильтров
+func TestFilterTasks(t *testing.T) {
+	db := loadTestTodoDB(t)
+
+	tests := []struct {
+		name     string
+		status   string
+		priority string
+		assignee string
+		want     int
+	}{
+		{name: "no filters", want: 6},
+		{name: "status", status: "OPEN", want: 3},
+		{name: "status case insensitive", status: "open", want: 3},
+		{name: "several statuses", status: "OPEN, IN_PROGRESS", want: 5},
+		{name: "priority", priority: "CRITICAL", want: 2},
+		{name: "assignee", assignee: "ivanov", want: 2},
+		{name: "unassigned", assignee: "unassigned", want: 2},
+		{name: "status and priority", status: "OPEN", priority: "CRITICAL,HIGH", want: 2},
+		{name: "all filters", status: "OPEN,IN_PROGRESS", priority: "HIGH", assignee: "ivanov", want: 1},
+		{name: "no matches", status: "RESOLVED", priority: "CRITICAL", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := TaskFilter{
+				Statuses:   parseFilterValues(tt.status),
+				Priorities: parseFilterValues(tt.priority),
+				Assignees:  parseFilterValues(tt.assignee),
+			}
+			if got := filterTasks(db.Tasks, filter); len(got) != tt.want {
+				t.Errorf("filterTasks() returned %d tasks, want %d", len(got), tt.want)
+			}
+		})
+	}
+}
+
+// TestWriteCSVReport проверяет выгрузку отфильтрованных задач в CSV
+func TestWriteCSVReport(t *testing.T) {
+	db := loadTestTodoDB(t)
+	tasks := filterTasks(db.Tasks, TaskFilter{Statuses: []string{"OPEN"}})
+
+	var buf strings.Builder
+	if err := writeCSVReport(&buf, tasks); err != nil {
+		t.Fatalf("writeCSVReport() failed: %v", err)
+	}
+
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(records) != len(tasks)+1 {
+		t.Fatalf("Expected %d CSV rows (header + tasks), got %d", len(tasks)+1, len(records))
+	}
+
+	header := records[0]
+	if len(header) != 11 || header[0] != "id" || header[10] != "estimatedHours" {
+		t.Errorf("Unexpected CSV header: %v", header)
+	}
+
+	// Описание с запятой и кавычками должно сохраниться без искажений
+	var found bool
+	for _, record := range records[1:] {
+		if record[0] == "t2" {
+			found = true
+			if record[5] != `Проверять ошибку, "rows.Err"` {
+				t.Errorf("Unexpected description %q", record[5])
+			}
+			if record[2] != "42" || record[7] != "petrov" || record[8] != "2025-11-03T10:00:00Z" {
+				t.Errorf("Unexpected CSV record: %v", record)
+			}
+		}
+	}
+	if !found {
+		t.Error("Task t2 is missing from CSV")
+	}
+}
+
+// TestCompletionRate_EmptySelection проверяет, что пустая выборка не дает NaN в отчете
+func TestCompletionRate_EmptySelection(t *testing.T) {
+	if rate := completionRate(0, 0); rate != 0 {
+		t.Errorf("completionRate(0, 0) = %v, want 0", rate)
+	}
+	if rate := completionRate(1, 4); rate != 25 {
+		t.Errorf("completionRate(1, 4) = %v, want 25", rate)
+	}
+}
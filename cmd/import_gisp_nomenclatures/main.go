@@ -11,6 +11,8 @@ import (
 
 	"httpserver/database"
 	"httpserver/importer"
+	"httpserver/internal/config"
+	"httpserver/normalization"
 )
 
 func main() {
@@ -18,22 +20,49 @@ func main() {
 		filePath = flag.String("file", "", "Path to the GISP Excel file (production_res_valid_only.xlsx)")
 		dbPath   = flag.String("db", "./service.db", "Path to service database")
 		verbose  = flag.Bool("verbose", false, "Verbose output")
+		// Строки с ошибками записываются в файл, чтобы повторить импорт только для них
+		failedFile = flag.String("failed-file", "", "Path to write failed rows (default: <file>.failed.jsonl, or the retry file itself in retry mode)")
+		retryFile  = flag.String("retry-file", "", "Import only the rows from a failed rows file of a previous run instead of the Excel file")
+		// Обработка кодов ОКПД2/ТН ВЭД, отсутствующих в справочниках
+		missingReferences = flag.String("missing-references", string(importer.MissingReferenceStub), "How to handle OKPD2/TNVED codes missing from reference books: stub (create gisp_inline entry) or warn (leave unlinked)")
 	)
 	flag.Parse()
 
-	if *filePath == "" {
-		fmt.Println("Usage: import_gisp_nomenclatures -file <path_to_excel_file> [-db <database_path>] [-verbose]")
+	missingReferenceMode := importer.MissingReferenceMode(*missingReferences)
+	if missingReferenceMode != importer.MissingReferenceStub && missingReferenceMode != importer.MissingReferenceWarn {
+		log.Fatalf("Invalid -missing-references value %q: expected %q or %q", *missingReferences, importer.MissingReferenceStub, importer.MissingReferenceWarn)
+	}
+
+	if *filePath == "" && *retryFile == "" {
+		fmt.Println("Usage: import_gisp_nomenclatures -file <path_to_excel_file> [-db <database_path>] [-failed-file <path>] [-verbose]")
+		fmt.Println("       import_gisp_nomenclatures -retry-file <path_to_failed_rows_file> [-db <database_path>] [-verbose]")
 		fmt.Println("\nExample:")
 		fmt.Println("  import_gisp_nomenclatures -file \"C:\\Users\\eugin\\Downloads\\Telegram Desktop\\isp\\реестр российской промышленной продукции\\production_res_valid_only.xlsx\"")
+		fmt.Println("  import_gisp_nomenclatures -retry-file production_res_valid_only.xlsx.failed.jsonl")
 		os.Exit(1)
 	}
 
 	// Проверяем существование файла
-	if _, err := os.Stat(*filePath); err != nil {
+	sourcePath := *filePath
+	if *retryFile != "" {
+		sourcePath = *retryFile
+	}
+	if _, err := os.Stat(sourcePath); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			log.Fatalf("File not found: %s", *filePath)
+			log.Fatalf("File not found: %s", sourcePath)
+		}
+		log.Fatalf("Error checking file %s: %v", sourcePath, err)
+	}
+
+	// По умолчанию ошибки пишутся рядом с исходным файлом, а при повторе - в сам файл повтора,
+	// чтобы каждый следующий повтор содержал только оставшиеся ошибки
+	failedRowsPath := *failedFile
+	if failedRowsPath == "" {
+		if *retryFile != "" {
+			failedRowsPath = *retryFile
+		} else {
+			failedRowsPath = *filePath + ".failed.jsonl"
 		}
-		log.Fatalf("Error checking file %s: %v", *filePath, err)
 	}
 
 	// Проверяем существование БД или создаем директорию
@@ -49,19 +78,36 @@ func main() {
 	}
 	defer db.Close()
 
-	// Выполняем миграции
-	if err := database.MigrateBenchmarkManufacturerLink(db.GetConnection()); err != nil {
-		log.Fatalf("Failed to run manufacturer link migration: %v", err)
+	// Стоп-слова для ключевых слов номенклатуры - как на сервере (конфигурация из сервисной БД или окружения)
+	// Ошибка в остальных настройках не должна останавливать импорт
+	stopwords := config.LoadStopwords()
+	if cfg, err := config.LoadConfig(db); err != nil {
+		log.Printf("Warning: failed to load config, using STOPWORDS from environment: %v", err)
+	} else {
+		stopwords = cfg.Stopwords
 	}
+	normalization.SetStopwords(normalization.DefaultStopwordSet(stopwords...))
 
-	// Создаем таблицы справочников
-	if err := database.CreateReferenceBooksTables(db.GetConnection()); err != nil {
-		log.Fatalf("Failed to create reference books tables: %v", err)
-	}
+	// Выполняем миграции под блокировкой, чтобы параллельно запущенные утилиты не выполняли их одновременно
+	err = db.WithSetupLock(database.SchemaSetupLockName, database.DefaultSetupLockTimeout, func() error {
+		if err := database.MigrateBenchmarkManufacturerLink(db.GetConnection()); err != nil {
+			return fmt.Errorf("failed to run manufacturer link migration: %w", err)
+		}
+
+		// Создаем таблицы справочников
+		if err := database.CreateReferenceBooksTables(db.GetConnection()); err != nil {
+			return fmt.Errorf("failed to create reference books tables: %w", err)
+		}
+
+		// Выполняем миграцию для связи со справочниками
+		if err := database.MigrateBenchmarkReferenceLinks(db.GetConnection()); err != nil {
+			return fmt.Errorf("failed to run reference links migration: %w", err)
+		}
 
-	// Выполняем миграцию для связи со справочниками
-	if err := database.MigrateBenchmarkReferenceLinks(db.GetConnection()); err != nil {
-		log.Fatalf("Failed to run reference links migration: %v", err)
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
 	// Получаем или создаем системный проект
@@ -75,33 +121,57 @@ func main() {
 		log.Printf("System project name: %s", systemProject.Name)
 	}
 
-	// Парсим Excel файл
-	if *verbose {
-		log.Printf("Parsing Excel file: %s", *filePath)
-	}
-	records, err := importer.ParseGISPExcelFile(*filePath)
-	if err != nil {
-		log.Fatalf("Failed to parse Excel file: %v", err)
-	}
+	nomenclatureImporter := importer.NewNomenclatureImporter(db)
+	nomenclatureImporter.SetFailedRowsFile(failedRowsPath)
+	nomenclatureImporter.SetMissingReferenceMode(missingReferenceMode)
 
-	if *verbose {
-		log.Printf("Parsed %d records from Excel file", len(records))
-	}
+	var result *importer.ImportResult
+	if *retryFile != "" {
+		// Повторяем импорт только строк с ошибками из предыдущего запуска
+		failedRows, err := importer.ReadFailedRowsFile(*retryFile)
+		if err != nil {
+			log.Fatalf("Failed to read retry file: %v", err)
+		}
 
-	if len(records) == 0 {
-		log.Fatalf("No records found in Excel file")
-	}
+		if len(failedRows) == 0 {
+			fmt.Printf("Retry file %s has no failed rows, nothing to import\n", *retryFile)
+			return
+		}
 
-	// Импортируем данные
-	nomenclatureImporter := importer.NewNomenclatureImporter(db)
+		if *verbose {
+			log.Printf("Retrying %d failed rows from %s...", len(failedRows), *retryFile)
+		}
 
-	if *verbose {
-		log.Printf("Starting import of %d nomenclature records...", len(records))
-	}
+		result, err = nomenclatureImporter.RetryFailedRows(failedRows, systemProject.ID)
+		if err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
+	} else {
+		// Парсим Excel файл
+		if *verbose {
+			log.Printf("Parsing Excel file: %s", *filePath)
+		}
+		records, err := importer.ParseGISPExcelFile(*filePath)
+		if err != nil {
+			log.Fatalf("Failed to parse Excel file: %v", err)
+		}
 
-	result, err := nomenclatureImporter.ImportNomenclatures(records, systemProject.ID)
-	if err != nil {
-		log.Fatalf("Import failed: %v", err)
+		if *verbose {
+			log.Printf("Parsed %d records from Excel file", len(records))
+		}
+
+		if len(records) == 0 {
+			log.Fatalf("No records found in Excel file")
+		}
+
+		if *verbose {
+			log.Printf("Starting import of %d nomenclature records...", len(records))
+		}
+
+		result, err = nomenclatureImporter.ImportNomenclatures(records, systemProject.ID)
+		if err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
 	}
 
 	// Выводим результаты
@@ -114,6 +184,27 @@ func main() {
 	fmt.Printf("Started: %s\n", result.Started.Format("2006-01-02 15:04:05"))
 	fmt.Printf("Completed: %s\n", result.Completed.Format("2006-01-02 15:04:05"))
 
+	// Коды, отсутствующие в справочниках
+	for _, book := range []string{importer.ReferenceBookOKPD2, importer.ReferenceBookTNVED} {
+		if result.StubReferences[book] > 0 {
+			fmt.Printf("%s stub entries created (%s): %d\n", book, database.ReferenceSourceGISPInline, result.StubReferences[book])
+		}
+		if missing := result.MissingReferenceCodes[book]; len(missing) > 0 {
+			fmt.Printf("%s codes missing from reference book: %d\n", book, len(missing))
+		}
+	}
+
+	// Привязываем к справочникам только эталоны, созданные этим импортом
+	if result.CreatedMaxID > 0 {
+		relink, err := db.RelinkReferenceBooksForRange(systemProject.ID, result.CreatedMinID, result.CreatedMaxID)
+		if err != nil {
+			log.Printf("Warning: failed to relink reference books for new rows: %v", err)
+		} else {
+			fmt.Printf("Relinked new rows (id %d-%d): checked %d, relinked %v, still missing %v\n",
+				result.CreatedMinID, result.CreatedMaxID, relink.Checked, relink.Relinked, relink.StillMissing)
+		}
+	}
+
 	// Проверяем справочники после импорта
 	fmt.Printf("\n=== Reference Books Validation ===\n")
 	conn := db.GetConnection()
@@ -192,6 +283,8 @@ func main() {
 
 	if len(result.Errors) > 0 {
 		fmt.Printf("\nWarning: Import completed with %d errors\n", len(result.Errors))
+		fmt.Printf("Failed rows saved to: %s\n", failedRowsPath)
+		fmt.Printf("Retry them with: import_gisp_nomenclatures -retry-file \"%s\" -db \"%s\"\n", failedRowsPath, *dbPath)
 		os.Exit(1)
 	}
 
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"httpserver/database"
+)
+
+// checkpointFileName файл контрольной точки импорта -all во временном каталоге импорта
+const checkpointFileName = "gost_import_checkpoint.json"
+
+// checkpointEntry источник, полностью импортированный в предыдущем запуске
+type checkpointEntry struct {
+	Records     int       `json:"records"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// importCheckpoint контрольная точка импорта из всех источников. Обновляется после каждого
+// источника, чтобы после сбоя следующий запуск продолжил с первого незавершенного.
+type importCheckpoint struct {
+	path    string
+	Sources map[string]checkpointEntry `json:"sources"`
+}
+
+// loadImportCheckpoint читает контрольную точку; отсутствующий файл означает пустую контрольную точку
+func loadImportCheckpoint(path string) (*importCheckpoint, error) {
+	checkpoint := &importCheckpoint{path: path, Sources: make(map[string]checkpointEntry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %w", path, err)
+	}
+	if err := json.Unmarshal(data, checkpoint); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	if checkpoint.Sources == nil {
+		checkpoint.Sources = make(map[string]checkpointEntry)
+	}
+	return checkpoint, nil
+}
+
+// completed возвращает запись об импорте источника, если он завершен не раньше maxAge назад.
+// maxAge <= 0 означает, что срок записи не ограничен.
+func (c *importCheckpoint) completed(name string, maxAge time.Duration, now time.Time) (checkpointEntry, bool) {
+	entry, ok := c.Sources[name]
+	if !ok {
+		return checkpointEntry{}, false
+	}
+	if maxAge > 0 && now.Sub(entry.CompletedAt) > maxAge {
+		return checkpointEntry{}, false
+	}
+	return entry, true
+}
+
+// markCompleted записывает завершенный источник и сохраняет контрольную точку
+func (c *importCheckpoint) markCompleted(name string, records int, now time.Time) error {
+	c.Sources[name] = checkpointEntry{Records: records, CompletedAt: now}
+	return c.save()
+}
+
+// reset очищает контрольную точку
+func (c *importCheckpoint) reset() error {
+	c.Sources = make(map[string]checkpointEntry)
+	return c.save()
+}
+
+// save атомарно записывает контрольную точку: сначала во временный файл, затем переименовывает
+func (c *importCheckpoint) save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("failed to create checkpoint directory: %w", err)
+	}
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// defaultImportConcurrency число источников, загружаемых одновременно при импорте -all
+const defaultImportConcurrency = 4
+
+// importAllOptions режим импорта из всех источников
+type importAllOptions struct {
+	maxAge      time.Duration // Источники, импортированные не раньше maxAge назад, пропускаются
+	resume      bool          // Пропускать все завершенные источники независимо от maxAge
+	force       bool          // Игнорировать контрольную точку и импортировать все источники заново
+	concurrency int           // Число источников, обрабатываемых одновременно (<= 0 - defaultImportConcurrency)
+}
+
+// importAllSources импортирует источники пулом из opts.concurrency воркеров, пропуская завершенные
+// по контрольной точке. importSource вызывается параллельно и сам отвечает за сериализацию записи в БД.
+// После каждого успешного источника контрольная точка сохраняется. Ошибка источника не прерывает
+// импорт остальных и попадает в результат; результаты возвращаются в порядке sources.
+func importAllSources(
+	sources []*database.GostImportSource,
+	checkpoint *importCheckpoint,
+	opts importAllOptions,
+	importSource func(source *database.GostImportSource) (int, error),
+	verbose bool,
+) []map[string]interface{} {
+	if opts.force {
+		if err := checkpoint.reset(); err != nil {
+			log.Printf("Warning: failed to reset checkpoint: %v", err)
+		}
+	}
+
+	maxAge := opts.maxAge
+	if opts.resume {
+		maxAge = 0
+	}
+
+	concurrency := opts.concurrency
+	if concurrency <= 0 {
+		concurrency = defaultImportConcurrency
+	}
+
+	results := make([]map[string]interface{}, len(sources))
+	semaphore := make(chan struct{}, concurrency)
+	var checkpointMu sync.Mutex
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		sourceResult := map[string]interface{}{"source": source.Name, "url": source.URL}
+		results[i] = sourceResult
+
+		checkpointMu.Lock()
+		entry, ok := checkpoint.completed(source.Name, maxAge, time.Now())
+		checkpointMu.Unlock()
+		if ok && !opts.force {
+			if verbose {
+				log.Printf("Skipping source %s: imported %d records at %s", source.Name, entry.Records, entry.CompletedAt.Format(time.RFC3339))
+			}
+			sourceResult["skipped"] = true
+			sourceResult["records"] = entry.Records
+			sourceResult["completed_at"] = entry.CompletedAt
+			continue
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(source *database.GostImportSource, sourceResult map[string]interface{}) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if verbose {
+				log.Printf("Downloading from source: %s", source.Name)
+			}
+			records, err := importSource(source)
+			if err != nil {
+				log.Printf("Error importing from %s: %v", source.Name, err)
+				sourceResult["error"] = err.Error()
+				return
+			}
+			sourceResult["records"] = records
+
+			checkpointMu.Lock()
+			defer checkpointMu.Unlock()
+			if err := checkpoint.markCompleted(source.Name, records, time.Now()); err != nil {
+				log.Printf("Warning: failed to save checkpoint after %s: %v", source.Name, err)
+			}
+		}(source, sourceResult)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// sourceImportSummary итоги импорта из всех источников
+type sourceImportSummary struct {
+	Imported int `json:"imported"`
+	Skipped  int `json:"skipped"`
+	Failed   int `json:"failed"`
+	Records  int `json:"records"` // Записей в импортированных в этом запуске источниках
+}
+
+// summarizeSourceResults подсчитывает итоги по результатам importAllSources
+func summarizeSourceResults(results []map[string]interface{}) sourceImportSummary {
+	var summary sourceImportSummary
+	for _, result := range results {
+		switch {
+		case result["error"] != nil:
+			summary.Failed++
+		case result["skipped"] == true:
+			summary.Skipped++
+		default:
+			summary.Imported++
+			if records, ok := result["records"].(int); ok {
+				summary.Records += records
+			}
+		}
+	}
+	return summary
+}
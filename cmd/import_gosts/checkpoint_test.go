@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpserver/database"
+)
+
+func TestImportAllSources_ResumesAfterPartialFailure(t *testing.T) {
+	checkpointPath := filepath.Join(t.TempDir(), checkpointFileName)
+	sources := []*database.GostImportSource{
+		{Name: "nationalstandards", URL: "https://example.com/1"},
+		{Name: "interstatestandards", URL: "https://example.com/2"},
+		{Name: "preliminarystandards", URL: "https://example.com/3"},
+	}
+
+	var calls []string
+	var callsMu sync.Mutex
+	failing := map[string]bool{"interstatestandards": true}
+	importSource := func(source *database.GostImportSource) (int, error) {
+		callsMu.Lock()
+		calls = append(calls, source.Name)
+		callsMu.Unlock()
+		if failing[source.Name] {
+			return 0, errors.New("connection reset by peer")
+		}
+		return len(source.Name), nil
+	}
+	run := func(opts importAllOptions) []map[string]interface{} {
+		t.Helper()
+		calls = nil
+		// Контрольная точка каждый раз читается с диска, как при новом запуске утилиты
+		checkpoint, err := loadImportCheckpoint(checkpointPath)
+		if err != nil {
+			t.Fatalf("loadImportCheckpoint failed: %v", err)
+		}
+		return importAllSources(sources, checkpoint, opts, importSource, false)
+	}
+	opts := importAllOptions{maxAge: 24 * time.Hour}
+
+	// Первый запуск: сбой на втором источнике не прерывает остальные
+	results := run(opts)
+	if len(calls) != 3 || results[1]["error"] == nil {
+		t.Fatalf("Expected all sources to be attempted with an error on the second, got calls %v, results %v", calls, results)
+	}
+	checkpoint, _ := loadImportCheckpoint(checkpointPath)
+	if len(checkpoint.Sources) != 2 || checkpoint.Sources["nationalstandards"].Records != len("nationalstandards") {
+		t.Fatalf("Expected completed sources with record counts in checkpoint, got %+v", checkpoint.Sources)
+	}
+	if _, ok := checkpoint.Sources["interstatestandards"]; ok {
+		t.Fatal("Failed source must not be recorded as completed")
+	}
+
+	// Повторный запуск импортирует только незавершенный источник
+	delete(failing, "interstatestandards")
+	results = run(opts)
+	if len(calls) != 1 || calls[0] != "interstatestandards" {
+		t.Fatalf("Expected only the unfinished source to be imported, got %v", calls)
+	}
+	if results[0]["skipped"] != true || results[2]["skipped"] != true {
+		t.Errorf("Expected completed sources to be reported as skipped, got %v", results)
+	}
+
+	// Устаревшие записи контрольной точки не учитываются без -resume
+	checkpoint, _ = loadImportCheckpoint(checkpointPath)
+	for name, entry := range checkpoint.Sources {
+		entry.CompletedAt = entry.CompletedAt.Add(-48 * time.Hour)
+		checkpoint.Sources[name] = entry
+	}
+	if err := checkpoint.save(); err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+	if run(importAllOptions{maxAge: 24 * time.Hour, resume: true}); len(calls) != 0 {
+		t.Errorf("Expected -resume to skip all completed sources, got %v", calls)
+	}
+	if run(opts); len(calls) != 3 {
+		t.Errorf("Expected stale checkpoint to be ignored, got %v", calls)
+	}
+
+	// -force импортирует все источники, даже только что завершенные
+	if run(importAllOptions{maxAge: 24 * time.Hour, force: true}); len(calls) != 3 {
+		t.Errorf("Expected -force to import all sources, got %v", calls)
+	}
+}
+
+func TestImportAllSources_BoundedConcurrency(t *testing.T) {
+	checkpoint, err := loadImportCheckpoint(filepath.Join(t.TempDir(), checkpointFileName))
+	if err != nil {
+		t.Fatalf("loadImportCheckpoint failed: %v", err)
+	}
+
+	sources := make([]*database.GostImportSource, 12)
+	for i := range sources {
+		sources[i] = &database.GostImportSource{Name: fmt.Sprintf("source%02d", i)}
+	}
+
+	const concurrency = 3
+	var running, maxRunning int32
+	var mu sync.Mutex
+	attempted := make(map[string]bool)
+	importSource := func(source *database.GostImportSource) (int, error) {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			seen := atomic.LoadInt32(&maxRunning)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+				break
+			}
+		}
+
+		mu.Lock()
+		attempted[source.Name] = true
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+		if source.Name == "source05" || source.Name == "source09" {
+			return 0, errors.New("download failed")
+		}
+		return 10, nil
+	}
+
+	results := importAllSources(sources, checkpoint, importAllOptions{concurrency: concurrency}, importSource, false)
+
+	if got := atomic.LoadInt32(&maxRunning); got > concurrency || got < 2 {
+		t.Errorf("Expected between 2 and %d concurrent imports, got %d", concurrency, got)
+	}
+	if len(attempted) != len(sources) {
+		t.Errorf("Expected all %d sources to be attempted, got %d", len(sources), len(attempted))
+	}
+	for i, result := range results {
+		if result["source"] != sources[i].Name {
+			t.Fatalf("Result %d belongs to %v, expected %s", i, result["source"], sources[i].Name)
+		}
+		failed := sources[i].Name == "source05" || sources[i].Name == "source09"
+		if (result["error"] != nil) != failed {
+			t.Errorf("Source %s: unexpected error state %v", sources[i].Name, result["error"])
+		}
+	}
+
+	summary := summarizeSourceResults(results)
+	if summary.Imported != 10 || summary.Failed != 2 || summary.Skipped != 0 || summary.Records != 100 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if len(checkpoint.Sources) != 10 {
+		t.Errorf("Expected 10 completed sources in checkpoint, got %d", len(checkpoint.Sources))
+	}
+}
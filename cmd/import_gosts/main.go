@@ -5,86 +5,43 @@ import (
 	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/PuerkitoBio/goquery"
 	"httpserver/database"
 	"httpserver/importer"
+	"httpserver/internal/config"
+	"httpserver/normalization"
 )
 
-// Список всех источников данных Росстандарта (50 источников)
-var gostSources = []struct {
-	name string
-	url  string
-}{
-	{"tulist", "https://www.rst.gov.ru/opendata/7706406291-tulist"},
-	{"nationalstandards", "https://www.rst.gov.ru/opendata/7706406291-nationalstandards"},
-	{"interstatestandards", "https://www.rst.gov.ru/opendata/7706406291-interstatestandards"},
-	{"techcommit", "https://www.rst.gov.ru/opendata/7706406291-techcommit"},
-	{"publiccouncils", "https://www.rst.gov.ru/opendata/7706406291-publiccouncils"},
-	{"gosuslugi", "https://www.rst.gov.ru/opendata/7706406291-gosuslugi"},
-	{"npa", "https://www.rst.gov.ru/opendata/7706406291-npa"},
-	{"zased", "https://www.rst.gov.ru/opendata/7706406291-zased"},
-	{"plan", "https://www.rst.gov.ru/opendata/7706406291-plan"},
-	{"reqsmi", "https://www.rst.gov.ru/opendata/7706406291-reqsmi"},
-	{"listinstrros", "https://www.rst.gov.ru/opendata/7706406291-listinstrros"},
-	{"vniiftricouncilplan", "https://www.rst.gov.ru/opendata/7706406291-vniiftricouncilplan"},
-	{"memoranda", "https://www.rst.gov.ru/opendata/7706406291-memoranda"},
-	{"regulations", "https://www.rst.gov.ru/opendata/7706406291-regulations"},
-	{"publiccouncilplan", "https://www.rst.gov.ru/opendata/7706406291-publiccouncilplan"},
-	{"rosstandartsystems", "https://www.rst.gov.ru/opendata/7706406291-rosstandartsystems"},
-	{"incomeemployees", "https://www.rst.gov.ru/opendata/7706406291-incomeemployees"},
-	{"efficiencyfact", "https://www.rst.gov.ru/opendata/7706406291-efficiencyfact"},
-	{"indicatorsindustry", "https://www.rst.gov.ru/opendata/7706406291-indicatorsindustry"},
-	{"targetsindustry", "https://www.rst.gov.ru/opendata/7706406291-targetsindustry"},
-	{"efficiencyplan", "https://www.rst.gov.ru/opendata/7706406291-efficiencyplan"},
-	{"budgetappropriations", "https://www.rst.gov.ru/opendata/7706406291-budgetappropriations"},
-	{"gostevents", "https://www.rst.gov.ru/opendata/7706406291-gostevents"},
-	{"establishedmedia", "https://www.rst.gov.ru/opendata/7706406291-establishedmedia"},
-	{"standartcouncil", "https://www.rst.gov.ru/opendata/7706406291-standartcouncil"},
-	{"vacanciesinfo", "https://www.rst.gov.ru/opendata/7706406291-vacanciesinfo"},
-	{"anticorruption", "https://www.rst.gov.ru/opendata/7706406291-anticorruption"},
-	{"stateprograms", "https://www.rst.gov.ru/opendata/7706406291-stateprograms"},
-	{"etalonros", "https://www.rst.gov.ru/opendata/7706406291-etalonros"},
-	{"citizensappeals", "https://www.rst.gov.ru/opendata/7706406291-citizensappeals"},
-	{"rosstandartstructure", "https://www.rst.gov.ru/opendata/7706406291-rosstandartstructure"},
-	{"informationsystems", "https://www.rst.gov.ru/opendata/7706406291-informationsystems"},
-	{"podved", "https://www.rst.gov.ru/opendata/7706406291-podved"},
-	{"rules", "https://www.rst.gov.ru/opendata/7706406291-rules"},
-	{"interaction", "https://www.rst.gov.ru/opendata/7706406291-interaction"},
-	{"controlresult", "https://www.rst.gov.ru/opendata/7706406291-controlresult"},
-	{"controlplan", "https://www.rst.gov.ru/opendata/7706406291-controlplan"},
-	{"incomepodved", "https://www.rst.gov.ru/opendata/7706406291-incomepodved"},
-	{"ndtlist", "https://www.rst.gov.ru/opendata/7706406291-ndtlist"},
-	{"verification", "https://www.rst.gov.ru/opendata/7706406291-verification"},
-	{"nssregistry", "https://www.rst.gov.ru/opendata/7706406291-nssregistry"},
-	{"nssblacklist", "https://www.rst.gov.ru/opendata/7706406291--nssblacklist"},
-	{"rstmaingoals", "https://www.rst.gov.ru/opendata/7706406291-rstmaingoals"},
-	{"koomet", "https://www.rst.gov.ru/opendata/7706406291-koomet"},
-	{"eaeutechregs", "https://www.rst.gov.ru/opendata/7706406291-eaeutechregs"},
-	{"orglist", "https://www.rst.gov.ru/opendata/7706406291-orglist"},
-	{"timezones", "https://www.rst.gov.ru/opendata/7706406291-timezones"},
-	{"declaredproducts", "https://www.rst.gov.ru/opendata/7706406291-declaredproducts"},
-	{"productcommoncertification", "https://www.rst.gov.ru/opendata/7706406291-productcommoncertification"},
-	{"listnationalstandarts", "https://www.rst.gov.ru/opendata/7706406291-listnationalstandarts"},
-}
-
 func main() {
 	var (
 		filePath  = flag.String("file", "", "Path to the GOST CSV file")
+		dirPath   = flag.String("dir", "", "Import all CSV files from a directory (source type is inferred from file names)")
 		dbPath    = flag.String("db", "./gosts.db", "Path to GOSTs database")
 		sourceURL = flag.String("source-url", "", "Source URL for the GOST data")
 		sourceType = flag.String("source-type", "", "Source type (nationalstandards, interstatestandards, etc.)")
 		download   = flag.Bool("download", false, "Download CSV files from Rosstandart")
-		allSources = flag.Bool("all", false, "Download and import from all available sources")
+		allSources = flag.Bool("all", false, "Download and import from all enabled sources, reporting changes since the previous sync")
 		verbose    = flag.Bool("verbose", false, "Verbose output")
+		tempDir    = flag.String("temp-dir", importer.DefaultTempDir, "Directory for downloaded CSV files (removed after successful import, kept on failure)")
+		priority   = flag.String("source-priority", "", "Comma-separated source priority for merging overlapping GOSTs (default: GOST_SOURCE_PRIORITY or built-in order)")
+		// Контрольная точка -all: источники, импортированные недавно, не скачиваются повторно
+		maxAge      = flag.Duration("max-age", 24*time.Hour, "With -all, skip sources fully imported within this window according to the checkpoint")
+		resume      = flag.Bool("resume", false, "With -all, continue from the last checkpoint regardless of -max-age")
+		force       = flag.Bool("force", false, "With -all, ignore the checkpoint and import all sources again")
+		concurrency = flag.Int("concurrency", defaultImportConcurrency, "With -all, number of sources downloaded in parallel (database writes stay serialized)")
+
+		listSources   = flag.Bool("list-sources", false, "List configured import sources")
+		addSource     = flag.String("add-source", "", "Add import source with the given name (URL from -source-url)")
+		updateSource  = flag.String("update-source", "", "Change URL of the import source (URL from -source-url)")
+		removeSource  = flag.String("remove-source", "", "Remove import source")
+		enableSource  = flag.String("enable-source", "", "Enable import source")
+		disableSource = flag.String("disable-source", "", "Disable import source")
 	)
 	flag.Parse()
 
@@ -101,50 +58,104 @@ func main() {
 	}
 	defer gostsDB.Close()
 
+	// Стоп-слова для ключевых слов - как на сервере (GOSTs БД не хранит конфигурацию, берем из окружения)
+	normalization.SetStopwords(normalization.DefaultStopwordSet(config.LoadStopwords()...))
+
 	if *verbose {
 		log.Printf("Using database: %s", *dbPath)
 	}
 
+	if *resume && *force {
+		log.Fatal("-resume cannot be combined with -force")
+	}
+
+	sourcePriority := parseSourcePriority(*priority, config.LoadGostSourcePriority())
+
+	// Управление списком источников импорта
+	if *listSources || *addSource != "" || *updateSource != "" || *removeSource != "" || *enableSource != "" || *disableSource != "" {
+		if err := manageSources(gostsDB, *listSources, *addSource, *updateSource, *removeSource, *enableSource, *disableSource, *sourceURL); err != nil {
+			log.Fatalf("Failed to manage import sources: %v", err)
+		}
+		return
+	}
+
 	// Если нужно скачать файлы
 	if *download || *allSources {
+		tempFiles := importer.NewTempFileManager(*tempDir)
 		if *allSources {
-			// Скачиваем и импортируем из всех источников
-			for _, source := range gostSources {
-				if *verbose {
-					log.Printf("Downloading from source: %s", source.name)
-				}
-				if err := downloadAndImport(gostsDB, source.url, source.name, *verbose); err != nil {
-					log.Printf("Error importing from %s: %v", source.name, err)
-					continue
-				}
+			// Скачиваем и импортируем из всех включенных источников
+			sources, err := gostsDB.ListImportSources(true)
+			if err != nil {
+				log.Fatalf("Failed to load import sources: %v", err)
+			}
+			if len(sources) == 0 {
+				log.Fatal("No enabled import sources, see -list-sources and -enable-source")
+			}
+			checkpointPath := filepath.Join(tempFiles.Dir(), checkpointFileName)
+			checkpoint, err := loadImportCheckpoint(checkpointPath)
+			if err != nil {
+				log.Fatalf("Failed to load checkpoint: %v", err)
+			}
+			opts := importAllOptions{maxAge: *maxAge, resume: *resume, force: *force, concurrency: *concurrency}
+			sourceResults := importAllSources(sources, checkpoint, opts, func(source *database.GostImportSource) (int, error) {
+				return downloadAndImport(gostsDB, tempFiles, source.URL, source.Name, sourcePriority, *verbose)
+			}, *verbose)
+			summary := summarizeSourceResults(sourceResults)
+			fmt.Printf("\n=== Sources Summary ===\n")
+			fmt.Printf("Imported: %d (%d records), skipped: %d, failed: %d\n",
+				summary.Imported, summary.Records, summary.Skipped, summary.Failed)
+			if err := reportSyncDiff(gostsDB, *dbPath, sourceResults, *verbose); err != nil {
+				log.Printf("Failed to compare with previous sync: %v", err)
 			}
 		} else {
 			// Скачиваем из указанного источника
 			if *sourceURL == "" || *sourceType == "" {
 				log.Fatal("source-url and source-type are required when using -download")
 			}
-			if err := downloadAndImport(gostsDB, *sourceURL, *sourceType, *verbose); err != nil {
+			if _, err := downloadAndImport(gostsDB, tempFiles, *sourceURL, *sourceType, sourcePriority, *verbose); err != nil {
 				log.Fatalf("Failed to download and import: %v", err)
 			}
 		}
 		return
 	}
 
+	// Импорт каталога ранее скачанных файлов (офлайн)
+	if *dirPath != "" {
+		if err := importDirectory(gostsDB, *dirPath, *dbPath, sourcePriority, *verbose); err != nil {
+			log.Fatalf("Failed to import directory: %v", err)
+		}
+		return
+	}
+
 	// Импорт из локального файла
 	if *filePath == "" {
 		fmt.Println("Usage: import_gosts [options]")
 		fmt.Println("\nOptions:")
 		fmt.Println("  -file <path>          Path to CSV file with GOSTs")
+		fmt.Println("  -dir <path>           Import all CSV files from directory")
 		fmt.Println("  -db <path>            Path to GOSTs database (default: ./gosts.db)")
 		fmt.Println("  -source-type <type>    Source type (nationalstandards, interstatestandards, etc.)")
 		fmt.Println("  -source-url <url>     Source URL")
 		fmt.Println("  -download             Download CSV from source URL")
-		fmt.Println("  -all                  Download and import from all available sources")
+		fmt.Println("  -all                  Download and import from all enabled sources")
+		fmt.Println("  -max-age <duration>   With -all, skip sources imported within this window (default: 24h)")
+		fmt.Println("  -resume               With -all, continue from the last checkpoint")
+		fmt.Println("  -force                With -all, ignore the checkpoint")
+		fmt.Println("  -concurrency <n>      With -all, number of sources downloaded in parallel (default: 4)")
 		fmt.Println("  -verbose              Verbose output")
+		fmt.Println("  -list-sources         List configured import sources")
+		fmt.Println("  -add-source <name>    Add import source (URL from -source-url)")
+		fmt.Println("  -update-source <name> Change import source URL (URL from -source-url)")
+		fmt.Println("  -remove-source <name> Remove import source")
+		fmt.Println("  -enable-source <name> Enable import source")
+		fmt.Println("  -disable-source <name> Disable import source")
 		fmt.Println("\nExamples:")
 		fmt.Println("  import_gosts -file gosts.csv -source-type nationalstandards")
 		fmt.Println("  import_gosts -download -source-url https://www.rst.gov.ru/opendata/7706406291-nationalstandards -source-type nationalstandards")
 		fmt.Println("  import_gosts -all")
+		fmt.Println("  import_gosts -dir ./downloads/gosts")
+		fmt.Println("  import_gosts -disable-source vacanciesinfo")
+		fmt.Println("  import_gosts -add-source mysource -source-url https://example.com/opendata/gosts")
 		os.Exit(1)
 	}
 
@@ -164,122 +175,62 @@ func main() {
 		*sourceURL = ""
 	}
 
-	// Парсим CSV файл используя новый парсер
 	if *verbose {
 		log.Printf("Parsing CSV file: %s", *filePath)
 	}
-	
-	// Открываем файл
-	file, err := os.Open(*filePath)
-	if err != nil {
-		log.Fatalf("Failed to open CSV file: %v", err)
-	}
-	defer file.Close()
-	
-	// Используем ParseGostCSVFromReader
-	records, err := importer.ParseGostCSVFromReader(file)
+	data, err := os.ReadFile(*filePath)
 	if err != nil {
-		log.Fatalf("Failed to parse CSV file: %v", err)
-	}
-
-	if *verbose {
-		log.Printf("Parsed %d records from CSV file", len(records))
+		log.Fatalf("Failed to read CSV file: %v", err)
 	}
 
-	if len(records) == 0 {
-		log.Fatalf("No records found in CSV file")
-	}
-
-	// Создаем или обновляем источник данных
-	source := &database.GostSource{
-		SourceName:   *sourceType,
-		SourceURL:    *sourceURL,
-		LastSyncDate: timePtr(time.Now()),
-		RecordsCount: len(records),
-	}
-
-	sourceRecord, err := gostsDB.CreateOrUpdateSource(source)
+	result, err := importer.ImportGostData(gostsDB, data, *sourceURL, *sourceType, importer.ImportOptions{
+		Priority: sourcePriority,
+		Logger:   &importLogger{verbose: *verbose},
+	})
 	if err != nil {
-		log.Fatalf("Failed to create or update source: %v", err)
-	}
-
-	if *verbose {
-		log.Printf("Using source ID: %d", sourceRecord.ID)
-	}
-
-	// Импортируем данные
-	successCount := 0
-	errorCount := 0
-	errors := []string{}
-
-	if *verbose {
-		log.Printf("Starting import of %d GOST records...", len(records))
+		log.Fatalf("Failed to import CSV file: %v", err)
 	}
-
-	for i, record := range records {
-		if *verbose && (i+1)%100 == 0 {
-			log.Printf("Processed %d/%d records...", i+1, len(records))
-		}
-
-		gost := &database.Gost{
-			GostNumber:    record.GostNumber,
-			Title:         record.Title,
-			AdoptionDate:  record.AdoptionDate,
-			EffectiveDate: record.EffectiveDate,
-			Status:        record.Status,
-			SourceType:    *sourceType,
-			SourceID:      &sourceRecord.ID,
-			SourceURL:     *sourceURL,
-			Description:   record.Description,
-			Keywords:      record.Keywords,
-		}
-
-		_, err := gostsDB.CreateOrUpdateGost(gost)
-		if err != nil {
-			errorCount++
-			errorMsg := fmt.Sprintf("ГОСТ %s: %v", record.GostNumber, err)
-			errors = append(errors, errorMsg)
-			if *verbose {
-				log.Printf("Error importing GOST %s: %v", record.GostNumber, err)
-			}
-			continue
-		}
-
-		successCount++
+	if result.Total == 0 {
+		log.Fatalf("No records found in CSV file")
 	}
+	errorCount := len(result.Errors)
 
 	// Выводим результаты
 	fmt.Printf("\n=== Import Results ===\n")
-	fmt.Printf("Total records: %d\n", len(records))
-	fmt.Printf("Successful: %d\n", successCount)
+	fmt.Printf("Total records: %d\n", result.Total)
+	fmt.Printf("Successful: %d (inserted: %d, updated: %d, unchanged: %d)\n",
+		result.Success, result.Inserted, result.Updated, result.Unchanged)
 	fmt.Printf("Errors: %d\n", errorCount)
-	fmt.Printf("Source ID: %d\n", sourceRecord.ID)
+	fmt.Printf("Source ID: %d\n", result.SourceID)
 
 	if errorCount > 0 && *verbose {
 		fmt.Printf("\n=== Errors (first 20) ===\n")
 		maxErrors := 20
-		if len(errors) < maxErrors {
-			maxErrors = len(errors)
+		if errorCount < maxErrors {
+			maxErrors = errorCount
 		}
 		for i := 0; i < maxErrors; i++ {
-			fmt.Printf(" - %s\n", errors[i])
+			fmt.Printf(" - %s\n", result.Errors[i])
 		}
-		if len(errors) > maxErrors {
-			fmt.Printf("... and %d more errors\n", len(errors)-maxErrors)
+		if errorCount > maxErrors {
+			fmt.Printf("... and %d more errors\n", errorCount-maxErrors)
 		}
 	}
 
 	// Сохраняем результаты в JSON файл
-	result := map[string]interface{}{
-		"total":      len(records),
-		"success":    successCount,
+	report := map[string]interface{}{
+		"total":      result.Total,
+		"success":    result.Success,
+		"inserted":   result.Inserted,
+		"updated":    result.Updated,
+		"unchanged":  result.Unchanged,
 		"errors":     errorCount,
-		"error_list": errors,
-		"source_id":  sourceRecord.ID,
+		"error_list": result.Errors,
+		"source_id":  result.SourceID,
 		"timestamp":  time.Now().Format(time.RFC3339),
 	}
 
-	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	resultJSON, err := json.MarshalIndent(report, "", "  ")
 	if err == nil {
 		reportPath := filepath.Join(filepath.Dir(*dbPath), "gost_import_report.json")
 		if err := os.WriteFile(reportPath, resultJSON, 0644); err == nil {
@@ -318,303 +269,174 @@ func main() {
 	fmt.Printf("\nImport completed successfully!\n")
 }
 
-// downloadAndImport скачивает CSV файл и импортирует его
-func downloadAndImport(gostsDB *database.GostsDB, url, sourceType string, verbose bool) error {
-	if verbose {
-		log.Printf("Downloading CSV from: %s", url)
+// importDirectory импортирует все CSV файлы каталога и сохраняет сводный отчет рядом с БД
+func importDirectory(gostsDB *database.GostsDB, dir, dbPath string, priority []string, verbose bool) error {
+	result, err := importer.ImportGostDirectory(gostsDB, dir, priority, &importLogger{verbose: verbose})
+	if err != nil {
+		return err
 	}
 
-	// Создаем HTTP клиент с таймаутом
-	client := &http.Client{
-		Timeout: 5 * time.Minute, // Таймаут 5 минут для больших файлов
+	fmt.Printf("\n=== Directory Import Results ===\n")
+	for _, file := range result.Files {
+		if file.Error != "" {
+			fmt.Printf("  %s (%s): failed: %s\n", file.File, file.SourceType, file.Error)
+			continue
+		}
+		fmt.Printf("  %s (%s): %d/%d imported, unchanged: %d\n", file.File, file.SourceType, file.Success, file.Total, file.Unchanged)
 	}
+	fmt.Printf("Files imported: %d, skipped (not CSV): %d\n", len(result.Files), len(result.Skipped))
+	fmt.Printf("Total records: %d\n", result.Total)
+	fmt.Printf("Successful: %d (inserted: %d, updated: %d, unchanged: %d)\n",
+		result.Success, result.Inserted, result.Updated, result.Unchanged)
+	fmt.Printf("Errors: %d\n", result.ErrorCount)
 
-	// Скачиваем файл
-	resp, err := client.Get(url)
-	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err == nil {
+		reportPath := filepath.Join(filepath.Dir(dbPath), "gost_import_report.json")
+		if err := os.WriteFile(reportPath, resultJSON, 0644); err == nil && verbose {
+			log.Printf("Import report saved to: %s", reportPath)
+		}
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download file: status code %d", resp.StatusCode)
+	if result.ErrorCount > 0 {
+		fmt.Printf("\nWarning: Import completed with %d errors\n", result.ErrorCount)
+		os.Exit(1)
 	}
 
-	// Проверяем Content-Type (может быть не CSV)
-	contentType := resp.Header.Get("Content-Type")
-	if verbose && contentType != "" {
-		log.Printf("Content-Type: %s", contentType)
-	}
+	fmt.Printf("\nImport completed successfully!\n")
+	return nil
+}
 
-	// Если сервер вернул HTML вместо CSV, пытаемся найти ссылку на CSV в HTML
-	if strings.Contains(contentType, "text/html") {
-		if verbose {
-			log.Printf("Server returned HTML, searching for CSV download links...")
-		}
-		
-		// Читаем HTML для парсинга
-		htmlContent, err := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		if err != nil {
-			return fmt.Errorf("failed to read HTML content: %w", err)
-		}
-		
-		// Парсим HTML и ищем ссылки на CSV
-		csvURL, err := findCSVLinkInHTML(string(htmlContent), url)
-		if err != nil {
-			return fmt.Errorf("failed to find CSV link in HTML: %w", err)
-		}
-		
-		if csvURL == "" {
-			return fmt.Errorf("no CSV download link found in HTML page")
-		}
-		
-		if verbose {
-			log.Printf("Found CSV link: %s", csvURL)
-		}
-		
-		// Скачиваем CSV файл по найденной ссылке
-		resp, err = client.Get(csvURL)
-		if err != nil {
-			return fmt.Errorf("failed to download CSV from found link: %w", err)
-		}
-		defer resp.Body.Close()
-		
-		if resp.StatusCode != http.StatusOK {
-			return fmt.Errorf("failed to download CSV: status code %d", resp.StatusCode)
-		}
-		
-		// Обновляем Content-Type
-		contentType = resp.Header.Get("Content-Type")
-		if verbose && contentType != "" {
-			log.Printf("CSV Content-Type: %s", contentType)
-		}
-	}
+// gostImportDiffSampleSize сколько номеров ГОСТов каждого вида изменений попадает в отчет
+const gostImportDiffSampleSize = 20
 
-	// Читаем данные напрямую из HTTP ответа в байты
-	// Это позволяет правильно определить кодировку до парсинга
-	data, err := io.ReadAll(resp.Body)
+// reportSyncDiff сохраняет снимок ГОСТов после импорта -all, выводит изменения относительно
+// предыдущей синхронизации и записывает их в отчет рядом с БД
+func reportSyncDiff(gostsDB *database.GostsDB, dbPath string, sourceResults []map[string]interface{}, verbose bool) error {
+	diff, err := gostsDB.RecordGostImportSnapshot(gostImportDiffSampleSize)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return err
 	}
-	resp.Body.Close()
 
-	// Проверяем размер данных
-	if len(data) == 0 {
-		return fmt.Errorf("downloaded file is empty")
-	}
-	if verbose {
-		log.Printf("Downloaded file size: %d bytes", len(data))
+	fmt.Printf("\n=== Changes Since Previous Sync ===\n")
+	if diff.PreviousSnapshotID == 0 {
+		fmt.Printf("No previous sync, baseline saved (%d GOSTs)\n", diff.CurrentCount)
+	} else {
+		fmt.Printf("Previous sync: %s (%d GOSTs)\n", diff.PreviousTakenAt.Format(time.RFC3339), diff.PreviousCount)
+		fmt.Printf("Added: %d, status changed: %d, removed: %d\n", diff.Added, diff.Changed, diff.Removed)
+		for _, sample := range []struct {
+			label   string
+			numbers []string
+		}{{"Added", diff.AddedSample}, {"Changed", diff.ChangedSample}, {"Removed", diff.RemovedSample}} {
+			if len(sample.numbers) > 0 {
+				fmt.Printf("  %s: %s\n", sample.label, strings.Join(sample.numbers, ", "))
+			}
+		}
 	}
 
-	// Используем парсер напрямую с данными в байтах
-	// Парсер сам определит и исправит кодировку
-	config := importer.DefaultParserConfig()
-	logger := &importLogger{verbose: verbose}
-	parser := importer.NewGostParser(config, logger)
-	
-	records, err := parser.ParseCSVData(data)
+	result := map[string]interface{}{
+		"sources":   sourceResults,
+		"diff":      diff,
+		"timestamp": time.Now().Format(time.RFC3339),
+	}
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
 	if err != nil {
-		// Если парсинг не удался, возможно это HTML или другой формат
-		if strings.Contains(contentType, "text/html") {
-			return fmt.Errorf("server returned HTML instead of CSV. This source may not provide CSV format or URL format is incorrect: %w", err)
-		}
-		return fmt.Errorf("failed to parse CSV: %w", err)
+		return fmt.Errorf("failed to encode report: %w", err)
 	}
-
-	if verbose {
-		log.Printf("Parsed %d records from %s", len(records), sourceType)
+	reportPath := filepath.Join(filepath.Dir(dbPath), "gost_import_report.json")
+	if err := os.WriteFile(reportPath, resultJSON, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
 	}
-
-	// Создаем или обновляем источник (до импорта, чтобы знать source_id)
-	source := &database.GostSource{
-		SourceName:   sourceType,
-		SourceURL:    url,
-		LastSyncDate: timePtr(time.Now()),
-		RecordsCount: len(records),
+	if verbose {
+		log.Printf("Import report saved to: %s", reportPath)
 	}
+	return nil
+}
 
-	sourceRecord, err := gostsDB.CreateOrUpdateSource(source)
-	if err != nil {
-		// Если не удалось создать источник, пробуем продолжить без него
-		log.Printf("Warning: failed to create source: %v, continuing without source_id", err)
-		sourceRecord = &database.GostSource{
-			ID: 0, // Используем 0 если источник не создан
+// manageSources выполняет операции над списком источников импорта и выводит итоговый список
+func manageSources(gostsDB *database.GostsDB, list bool, add, update, remove, enable, disable, sourceURL string) error {
+	if add != "" {
+		if _, err := gostsDB.CreateImportSource(add, sourceURL); err != nil {
+			return err
 		}
+		fmt.Printf("Added import source %s\n", add)
 	}
-
-	// Импортируем данные
-	successCount := 0
-	errorCount := 0
-	for _, record := range records {
-		// Пропускаем записи без номера ГОСТа
-		if record.GostNumber == "" {
-			errorCount++
-			if verbose {
-				log.Printf("Skipping record without GOST number")
-			}
-			continue
-		}
-		
-		// Если название пустое, используем номер ГОСТа
-		title := record.Title
-		if title == "" {
-			title = record.GostNumber
+	if update != "" {
+		if err := gostsDB.UpdateImportSourceURL(update, sourceURL); err != nil {
+			return err
 		}
-		
-		// Создаем запись ГОСТа
-		var sourceID *int
-		if sourceRecord != nil && sourceRecord.ID > 0 {
-			sourceID = &sourceRecord.ID
+		fmt.Printf("Updated import source %s\n", update)
+	}
+	if enable != "" {
+		if err := gostsDB.SetImportSourceEnabled(enable, true); err != nil {
+			return err
 		}
-		
-		gost := &database.Gost{
-			GostNumber:    record.GostNumber,
-			Title:         title,
-			AdoptionDate:  record.AdoptionDate,
-			EffectiveDate: record.EffectiveDate,
-			Status:        record.Status,
-			SourceType:    sourceType,
-			SourceID:      sourceID,
-			SourceURL:     url,
-			Description:   record.Description,
-			Keywords:      record.Keywords,
+		fmt.Printf("Enabled import source %s\n", enable)
+	}
+	if disable != "" {
+		if err := gostsDB.SetImportSourceEnabled(disable, false); err != nil {
+			return err
 		}
-
-		_, err := gostsDB.CreateOrUpdateGost(gost)
-		if err != nil {
-			errorCount++
-			if verbose {
-				log.Printf("Error importing GOST %s: %v", record.GostNumber, err)
-			}
-			continue
+		fmt.Printf("Disabled import source %s\n", disable)
+	}
+	if remove != "" {
+		if err := gostsDB.DeleteImportSource(remove); err != nil {
+			return err
 		}
-
-		successCount++
+		fmt.Printf("Removed import source %s\n", remove)
 	}
 
-	if verbose {
-		log.Printf("Imported %d/%d GOSTs from %s (errors: %d)", successCount, len(records), sourceType, errorCount)
-	} else {
-		log.Printf("Imported %d GOSTs from %s", successCount, sourceType)
+	if !list {
+		return nil
 	}
 
+	sources, err := gostsDB.ListImportSources(false)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\n=== Import Sources (%d) ===\n", len(sources))
+	for _, source := range sources {
+		state := "enabled"
+		if !source.Enabled {
+			state = "disabled"
+		}
+		fmt.Printf("  %-28s %-8s %s\n", source.Name, state, source.URL)
+	}
 	return nil
 }
 
-// findCSVLinkInHTML парсит HTML и ищет ссылки на CSV файлы
-func findCSVLinkInHTML(htmlContent, baseURL string) (string, error) {
-	// Парсим HTML
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+// gostsWriteMu сериализует запись в GostsDB при параллельной загрузке источников (-concurrency)
+var gostsWriteMu sync.Mutex
+
+// downloadAndImport скачивает CSV источника и импортирует его (см. importer.ImportGostSource).
+// Возвращает число записей в файле.
+func downloadAndImport(gostsDB *database.GostsDB, tempFiles *importer.TempFileManager, url, sourceType string, priority []string, verbose bool) (int, error) {
+	result, err := importer.ImportGostSource(gostsDB, url, sourceType, importer.ImportOptions{
+		Priority:  priority,
+		TempFiles: tempFiles,
+		WriteLock: &gostsWriteMu,
+		Logger:    &importLogger{verbose: verbose},
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to parse HTML: %w", err)
+		return 0, err
 	}
-	
-	// Парсим базовый URL для разрешения относительных ссылок
-	base, err := url.Parse(baseURL)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse base URL: %w", err)
-	}
-	
-	var csvURL string
-	
-	// Стратегия 1: Ищем ссылки с расширением .csv или параметрами формата
-	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
-		if csvURL != "" {
-			return // Уже нашли
-		}
-		
-		href, exists := s.Attr("href")
-		if !exists {
-			return
-		}
-		
-		hrefLower := strings.ToLower(href)
-		// Проверяем, содержит ли ссылка .csv или указывает на CSV
-		if strings.Contains(hrefLower, ".csv") || 
-		   strings.Contains(hrefLower, "format=csv") ||
-		   strings.Contains(hrefLower, "export=csv") ||
-		   strings.Contains(hrefLower, "download") {
-			
-			// Разрешаем относительные ссылки
-			parsedURL, err := url.Parse(href)
-			if err != nil {
-				return
-			}
-			
-			resolvedURL := base.ResolveReference(parsedURL)
-			csvURL = resolvedURL.String()
+	log.Printf("Imported %d GOSTs from %s (unchanged: %d)", result.Success, sourceType, result.Unchanged)
+	return result.Total, nil
+}
+
+// parseSourcePriority разбирает список источников через запятую; пустое значение - fallback
+// (приоритет из конфигурации, см. config.LoadGostSourcePriority)
+func parseSourcePriority(value string, fallback []string) []string {
+	var priority []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			priority = append(priority, name)
 		}
-	})
-	
-	// Стратегия 2: Ищем кнопки или элементы с data-атрибутами
-	if csvURL == "" {
-		doc.Find("[data-format='csv'], [data-export='csv'], [data-download='csv']").Each(func(i int, s *goquery.Selection) {
-			if csvURL != "" {
-				return
-			}
-			
-			href, exists := s.Attr("href")
-			if !exists {
-				href, exists = s.Attr("data-url")
-			}
-			if !exists {
-				href, exists = s.Attr("data-link")
-			}
-			
-			if exists && href != "" {
-				parsedURL, err := url.Parse(href)
-				if err == nil {
-					resolvedURL := base.ResolveReference(parsedURL)
-					csvURL = resolvedURL.String()
-				}
-			}
-		})
-	}
-	
-	// Стратегия 3: Ищем ссылки с текстом "CSV", "Скачать", "Download"
-	if csvURL == "" {
-		doc.Find("a").Each(func(i int, s *goquery.Selection) {
-			if csvURL != "" {
-				return
-			}
-			
-			text := strings.ToLower(strings.TrimSpace(s.Text()))
-			if strings.Contains(text, "csv") || 
-			   strings.Contains(text, "скачать") ||
-			   strings.Contains(text, "download") ||
-			   strings.Contains(text, "экспорт") {
-				
-				href, exists := s.Attr("href")
-				if exists && href != "" {
-					parsedURL, err := url.Parse(href)
-					if err == nil {
-						resolvedURL := base.ResolveReference(parsedURL)
-						// Проверяем, что это действительно CSV
-						resolvedStr := strings.ToLower(resolvedURL.String())
-						if strings.Contains(resolvedStr, ".csv") || 
-						   strings.Contains(resolvedStr, "format=csv") ||
-						   strings.Contains(resolvedStr, "export=csv") {
-							csvURL = resolvedURL.String()
-						}
-					}
-				}
-			}
-		})
 	}
-	
-	// Стратегия 4: Пробуем добавить .csv к базовому URL
-	if csvURL == "" {
-		if !strings.HasSuffix(baseURL, ".csv") {
-			csvURL = baseURL + ".csv"
-		}
+	if len(priority) == 0 {
+		return fallback
 	}
-	
-	return csvURL, nil
-}
-
-func timePtr(t time.Time) *time.Time {
-	return &t
+	return priority
 }
 
 // importLogger простой логгер для импорта
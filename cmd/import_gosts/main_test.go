@@ -0,0 +1,93 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"httpserver/database"
+	"httpserver/importer"
+)
+
+func TestDownloadAndImport_RemovesTempFileOnSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Write([]byte("номер;название;дата принятия;статус\nГОСТ 12345-2020;Тестовый стандарт безопасности;2020-01-01;действующий\n"))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	gostsDB, err := database.NewGostsDB(filepath.Join(dir, "gosts.db"))
+	if err != nil {
+		t.Fatalf("Failed to create GOSTs DB: %v", err)
+	}
+	defer gostsDB.Close()
+
+	tempFiles := importer.NewTempFileManager(filepath.Join(dir, "temp"))
+	records, err := downloadAndImport(gostsDB, tempFiles, server.URL, "test", nil, false)
+	if err != nil {
+		t.Fatalf("downloadAndImport failed: %v", err)
+	}
+	if records != 1 {
+		t.Errorf("Expected 1 imported record, got %d", records)
+	}
+
+	if _, err := gostsDB.GetGostByNumber("ГОСТ 12345-2020"); err != nil {
+		t.Fatalf("Expected GOST to be imported: %v", err)
+	}
+
+	entries, err := os.ReadDir(tempFiles.Dir())
+	if err != nil {
+		t.Fatalf("Failed to read temp directory: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("Expected temp files to be removed after successful import, found %d", len(entries))
+	}
+}
+
+func TestDownloadAndImport_ZipArchiveWithSeveralCSV(t *testing.T) {
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	for name, content := range map[string]string{
+		"part1.csv": "номер;название;дата принятия;статус\nГОСТ 11111-2020;Первый стандарт;2020-01-01;действующий\n",
+		"part2.csv": "номер;название;дата принятия;статус\nГОСТ 22222-2021;Второй стандарт;2021-01-01;действующий\n",
+	} {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create zip entry: %v", err)
+		}
+		entry.Write([]byte(content))
+	}
+	writer.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write(archive.Bytes())
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	gostsDB, err := database.NewGostsDB(filepath.Join(dir, "gosts.db"))
+	if err != nil {
+		t.Fatalf("Failed to create GOSTs DB: %v", err)
+	}
+	defer gostsDB.Close()
+
+	tempFiles := importer.NewTempFileManager(filepath.Join(dir, "temp"))
+	records, err := downloadAndImport(gostsDB, tempFiles, server.URL+"/opendata/gosts.zip", "test", nil, false)
+	if err != nil {
+		t.Fatalf("downloadAndImport failed: %v", err)
+	}
+	if records != 2 {
+		t.Errorf("Expected records from both archive entries, got %d", records)
+	}
+	for _, number := range []string{"ГОСТ 11111-2020", "ГОСТ 22222-2021"} {
+		if _, err := gostsDB.GetGostByNumber(number); err != nil {
+			t.Errorf("Expected %s to be imported from archive: %v", number, err)
+		}
+	}
+}
@@ -46,8 +46,11 @@ func main() {
 	}
 	defer db.Close()
 
-	// Выполняем миграции
-	if err := database.MigrateBenchmarkOGRNRegion(db.GetConnection()); err != nil {
+	// Выполняем миграции под блокировкой, чтобы параллельно запущенные утилиты не выполняли их одновременно
+	err = db.WithSetupLock(database.SchemaSetupLockName, database.DefaultSetupLockTimeout, func() error {
+		return database.MigrateBenchmarkOGRNRegion(db.GetConnection())
+	})
+	if err != nil {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"httpserver/classification"
+	"httpserver/database"
+	"httpserver/internal/config"
+)
+
+func main() {
+	var (
+		dbPath         = flag.String("db", "service.db", "Путь к сервисной базе данных")
+		limit          = flag.Int("limit", 0, "Максимум номенклатур за запуск (0 - все несвязанные)")
+		workers        = flag.Int("workers", 4, "Количество параллельных запросов к AI")
+		batchSize      = flag.Int("batch", 10, "Количество наименований в одном запросе к AI")
+		candidateLimit = flag.Int("candidates", 10, "Количество кандидатов ОКПД2 на наименование")
+		minConfidence  = flag.Float64("min-confidence", -1, "Минимальная уверенность для связывания (по умолчанию из конфигурации)")
+		dryRun         = flag.Bool("dry-run", false, "Только подобрать коды, не изменяя номенклатуры")
+		negativeTTL    = flag.Duration("negative-cache-ttl", database.DefaultOkpd2LinkNegativeCacheTTL, "Через сколько повторно подбирать код для наименований, для которых он не был найден")
+	)
+	flag.Parse()
+
+	serviceDB, err := database.NewServiceDB(*dbPath)
+	if err != nil {
+		log.Fatalf("Ошибка открытия базы данных: %v", err)
+	}
+	defer serviceDB.Close()
+
+	cfg, err := config.LoadConfig(serviceDB)
+	if err != nil {
+		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
+	}
+	if cfg.ArliaiAPIKey == "" {
+		log.Fatal("Не задан ARLIAI_API_KEY")
+	}
+	if *minConfidence < 0 {
+		*minConfidence = cfg.Okpd2LinkMinConfidence
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	linker := classification.NewOkpd2Linker(
+		serviceDB,
+		classification.NewAIOkpd2Backend(cfg.ArliaiAPIKey, cfg.ArliaiModel),
+		classification.Okpd2LinkerConfig{
+			Workers:          *workers,
+			BatchSize:        *batchSize,
+			CandidateLimit:   *candidateLimit,
+			MinConfidence:    *minConfidence,
+			DryRun:           *dryRun,
+			NegativeCacheTTL: *negativeTTL,
+		},
+	)
+
+	log.Printf("Связывание номенклатуры с ОКПД2 (порог уверенности %.2f, воркеров %d, пачка %d)", *minConfidence, *workers, *batchSize)
+	stats, err := linker.Run(ctx, *limit)
+	if stats != nil {
+		fmt.Printf("Обработано номенклатур: %d (уникальных наименований: %d)\n", stats.Processed, stats.UniqueNames)
+		fmt.Printf("Связано: %d\n", stats.Linked)
+		fmt.Printf("Пропущено (нет кода или низкая уверенность): %d\n", stats.Skipped)
+		fmt.Printf("Ошибок: %d\n", stats.Failed)
+		fmt.Printf("Из кэша: %d\n", stats.CacheHits)
+		fmt.Printf("Стоимость: %d запросов к AI, ~%d токенов\n", stats.AIRequests, stats.EstimatedTokens)
+		if *dryRun {
+			fmt.Println("Режим -dry-run: номенклатуры не изменены")
+		}
+	}
+	if err != nil {
+		log.Fatalf("Ошибка связывания с ОКПД2: %v", err)
+	}
+}
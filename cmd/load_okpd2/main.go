@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
 
 	"httpserver/database"
 )
@@ -14,6 +15,10 @@ func main() {
 		filePath = flag.String("file", "", "Путь к файлу с данными ОКПД2")
 		dbPath   = flag.String("db", "service.db", "Путь к сервисной базе данных")
 		_        = flag.Bool("clear", false, "Очистить существующие данные перед загрузкой") // clearData - зарезервировано для будущего использования
+
+		diffOnly    = flag.Bool("diff", false, "Только показать отличия от загруженного справочника, не изменяя БД")
+		diffFormat  = flag.String("diff-format", "text", "Формат вывода отличий: text, csv или json")
+		incremental = flag.Bool("incremental", false, "Применить только отличия (добавленные, удаленные и переименованные коды)")
 	)
 	flag.Parse()
 
@@ -28,6 +33,35 @@ func main() {
 	}
 	defer serviceDB.Close()
 
+	if *diffOnly || *incremental {
+		var entries []database.Okpd2Entry
+		if *textData != "" {
+			entries, err = database.ParseOkpd2FromText(*textData)
+		} else {
+			entries, err = database.ParseOkpd2File(*filePath)
+		}
+		if err != nil {
+			log.Fatalf("Ошибка разбора ОКПД2: %v", err)
+		}
+
+		added, removed, renamed, err := database.DiffReferenceBook(serviceDB, "okpd2", database.Okpd2EntriesToReference(entries))
+		if err != nil {
+			log.Fatalf("Ошибка сравнения ОКПД2: %v", err)
+		}
+		if err := database.WriteReferenceBookDiff(os.Stdout, *diffFormat, added, removed, renamed); err != nil {
+			log.Fatalf("Ошибка вывода отличий: %v", err)
+		}
+		if *diffOnly {
+			return
+		}
+
+		if err := database.ApplyReferenceBookDiff(serviceDB, "okpd2", added, removed, renamed); err != nil {
+			log.Fatalf("Ошибка применения отличий ОКПД2: %v", err)
+		}
+		log.Printf("Отличия ОКПД2 применены: добавлено %d, удалено %d, переименовано %d", len(added), len(removed), len(renamed))
+		return
+	}
+
 	// Загружаем данные
 	if *textData != "" {
 		log.Printf("Загрузка ОКПД2 из текстовых данных...")
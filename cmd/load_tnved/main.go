@@ -0,0 +1,101 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"httpserver/database"
+)
+
+func main() {
+	var (
+		filePath = flag.String("file", "", "Путь к файлу номенклатуры ТН ВЭД (Excel .xlsx или XML)")
+		dbPath   = flag.String("db", "service.db", "Путь к сервисной базе данных")
+
+		diffOnly    = flag.Bool("diff", false, "Только показать отличия от загруженного справочника, не изменяя БД")
+		diffFormat  = flag.String("diff-format", "text", "Формат вывода отличий: text, csv или json")
+		incremental = flag.Bool("incremental", false, "Применить только отличия (добавленные, удаленные и переименованные коды)")
+	)
+	flag.Parse()
+
+	if *filePath == "" {
+		log.Fatal("Необходимо указать -file с путем к файлу номенклатуры ТН ВЭД")
+	}
+
+	// Открываем сервисную базу данных
+	serviceDB, err := database.NewServiceDB(*dbPath)
+	if err != nil {
+		log.Fatalf("Ошибка открытия базы данных: %v", err)
+	}
+	defer serviceDB.Close()
+
+	if *diffOnly || *incremental {
+		entries, err := database.ParseTnvedFile(*filePath)
+		if err != nil {
+			log.Fatalf("Ошибка разбора файла ТН ВЭД: %v", err)
+		}
+
+		added, removed, renamed, err := database.DiffReferenceBook(serviceDB, "tnved", database.TnvedEntriesToReference(entries))
+		if err != nil {
+			log.Fatalf("Ошибка сравнения ТН ВЭД: %v", err)
+		}
+		if err := database.WriteReferenceBookDiff(os.Stdout, *diffFormat, added, removed, renamed); err != nil {
+			log.Fatalf("Ошибка вывода отличий: %v", err)
+		}
+		if *diffOnly {
+			return
+		}
+
+		if err := database.ApplyReferenceBookDiff(serviceDB, "tnved", added, removed, renamed); err != nil {
+			log.Fatalf("Ошибка применения отличий ТН ВЭД: %v", err)
+		}
+		log.Printf("Отличия ТН ВЭД применены: добавлено %d, удалено %d, переименовано %d", len(added), len(removed), len(renamed))
+		return
+	}
+
+	// Загружаем данные (существующие коды обновляются, ссылки эталонов сохраняются)
+	log.Printf("Загрузка ТН ВЭД из файла: %s", *filePath)
+	if err := database.LoadTnvedFromFile(serviceDB, *filePath); err != nil {
+		log.Fatalf("Ошибка загрузки ТН ВЭД из файла: %v", err)
+	}
+	log.Printf("ТН ВЭД успешно загружен из файла")
+
+	// Проверяем количество загруженных записей по уровням
+	rows, err := serviceDB.Query("SELECT level, COUNT(*) FROM tnved_reference GROUP BY level ORDER BY level")
+	if err != nil {
+		log.Printf("Предупреждение: не удалось подсчитать записи: %v", err)
+	} else {
+		total := 0
+		for rows.Next() {
+			var level sql.NullInt64
+			var count int
+			if err := rows.Scan(&level, &count); err != nil {
+				continue
+			}
+			total += count
+			log.Printf("Уровень %d: %d записей", level.Int64, count)
+		}
+		rows.Close()
+		log.Printf("Всего записей ТН ВЭД в базе данных: %d", total)
+	}
+
+	// Выводим несколько примеров
+	examples, err := serviceDB.Query("SELECT code, name, COALESCE(level, 0) FROM tnved_reference ORDER BY code LIMIT 10")
+	if err != nil {
+		log.Printf("Предупреждение: не удалось получить примеры: %v", err)
+	} else {
+		defer examples.Close()
+		fmt.Println("\nПримеры загруженных записей:")
+		for examples.Next() {
+			var code, name string
+			var level int
+			if err := examples.Scan(&code, &name, &level); err != nil {
+				continue
+			}
+			fmt.Printf("  %s (уровень %d): %s\n", code, level, name)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"time"
 
 	"httpserver/database"
@@ -14,8 +16,19 @@ func main() {
 	dbPath := flag.String("db", "service.db", "Path to the service database (service.db by default)")
 	projectID := flag.Int("project", 3, "Project ID to normalize")
 	dryRun := flag.Bool("dry-run", false, "Analyze changes without writing them to the database")
+	changesPath := flag.String("changes-file", "", "With -dry-run, write proposed changes to this CSV file (e.g. proposed_changes.csv)")
+	reportPath := flag.String("out", "", "Write the summary with the list of proposed (or applied) changes to this JSON file (e.g. report.json)")
+	maxChanges := flag.Int("max-changes", normalization.DefaultMaxCounterpartyNameChanges, "Maximum number of changes kept in the report and -changes-file (0 - no limit)")
+	applyFrom := flag.String("apply-from", "", "Apply exactly the changes listed in a reviewed proposed changes CSV file")
 	flag.Parse()
 
+	if *applyFrom != "" && *dryRun {
+		log.Fatal("-apply-from cannot be combined with -dry-run")
+	}
+	if *changesPath != "" && !*dryRun {
+		log.Fatal("-changes-file requires -dry-run")
+	}
+
 	serviceDB, err := database.NewServiceDB(*dbPath)
 	if err != nil {
 		log.Fatalf("failed to open service database: %v", err)
@@ -23,6 +36,13 @@ func main() {
 	defer serviceDB.Close()
 
 	mapper := normalization.NewCounterpartyMapper(serviceDB)
+	mapper.SetMaxNameChanges(*maxChanges)
+
+	if *applyFrom != "" {
+		applyChanges(mapper, *projectID, *applyFrom)
+		return
+	}
+
 	summary, err := mapper.NormalizeNamesForProject(*projectID, *dryRun)
 	if err != nil {
 		log.Fatalf("failed to normalize names: %v", err)
@@ -42,4 +62,76 @@ func main() {
 		fmt.Println("Applied Updates: 0 (dry run)")
 	}
 	fmt.Printf("Duration: %s\n", summary.Duration.Round(time.Millisecond))
+	if summary.ChangesTruncated {
+		fmt.Printf("Warning: change list truncated to %d of %d rows (see -max-changes)\n", len(summary.Changes), summary.UpdatedRecords)
+	}
+
+	if *reportPath != "" {
+		if err := writeReportFile(*reportPath, summary); err != nil {
+			log.Fatalf("failed to write report: %v", err)
+		}
+		fmt.Printf("Report written to %s (%d changes)\n", *reportPath, len(summary.Changes))
+	}
+
+	if *changesPath != "" {
+		if err := writeChangesFile(*changesPath, summary.Changes); err != nil {
+			log.Fatalf("failed to write proposed changes: %v", err)
+		}
+		fmt.Printf("Proposed changes written to %s (%d rows)\n", *changesPath, len(summary.Changes))
+		fmt.Printf("Review the file and apply it with: -apply-from %s\n", *changesPath)
+	}
+}
+
+func writeChangesFile(path string, changes []normalization.CounterpartyNameChange) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := normalization.WriteCounterpartyNameChangesCSV(file, changes); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func writeReportFile(path string, summary *normalization.CounterpartyNameNormalizationSummary) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(summary); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func applyChanges(mapper *normalization.CounterpartyMapper, projectID int, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("failed to open changes file: %v", err)
+	}
+	defer file.Close()
+
+	changes, err := normalization.ReadCounterpartyNameChangesCSV(file)
+	if err != nil {
+		log.Fatalf("failed to read changes file: %v", err)
+	}
+
+	summary, err := mapper.ApplyCounterpartyNameChanges(projectID, changes)
+	if err != nil {
+		log.Fatalf("failed to apply changes: %v", err)
+	}
+
+	fmt.Println("\n--- Counterparty Name Changes Applied ---")
+	fmt.Printf("Project ID: %d\n", summary.ProjectID)
+	fmt.Printf("Changes in file: %d\n", summary.TotalChanges)
+	fmt.Printf("Applied: %d\n", summary.Applied)
+	if len(summary.StaleIDs) > 0 {
+		fmt.Printf("Skipped (changed since dry run or not found): %d %v\n", len(summary.StaleIDs), summary.StaleIDs)
+	}
 }
@@ -1,35 +1,99 @@
 package main
 
 import (
-	"database/sql"
+	"flag"
 	"fmt"
 	"log"
+	"os"
+
+	"httpserver/database"
+	"httpserver/importer"
+	"httpserver/internal/config"
+	"httpserver/normalization"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
 func main() {
+	var (
+		dbPath     = flag.String("db", "./gosts.db", "Path to GOSTs database")
+		sourceType = flag.String("source", "", "Delete and re-import only GOSTs of this source type (nationalstandards, interstatestandards, etc.)")
+		filePath   = flag.String("file", "", "CSV file to re-import the source from (with -source)")
+		all        = flag.Bool("all", false, "Delete all GOST records")
+		verbose    = flag.Bool("verbose", false, "Verbose output")
+	)
+	flag.Parse()
+
+	// Нужен ровно один режим: один источник или полная очистка
+	if (*sourceType == "" && !*all) || (*sourceType != "" && *all) {
+		fmt.Println("Usage: reimport_gosts -source <type> [-file <csv>] | -all [-db <path>]")
+		fmt.Println("\nExamples:")
+		fmt.Println("  reimport_gosts -source nationalstandards -file 7706406291-nationalstandards.csv")
+		fmt.Println("  reimport_gosts -source tulist")
+		fmt.Println("  reimport_gosts -all")
+		os.Exit(1)
+	}
+
 	// Открываем базу данных
-	db, err := sql.Open("sqlite3", "./gosts.db")
+	gostsDB, err := database.NewGostsDB(*dbPath)
 	if err != nil {
 		log.Fatalf("Failed to open database: %v", err)
 	}
-	defer db.Close()
+	defer gostsDB.Close()
 
-	// Удаляем все записи из таблицы gosts
-	fmt.Println("Deleting all GOST records...")
-	result, err := db.Exec("DELETE FROM gosts")
+	// Стоп-слова для ключевых слов - как на сервере (GOSTs БД не хранит конфигурацию, берем из окружения)
+	normalization.SetStopwords(normalization.DefaultStopwordSet(config.LoadStopwords()...))
+
+	if *all {
+		// Удаляем все записи из таблицы gosts
+		fmt.Println("Deleting all GOST records...")
+		result, err := gostsDB.GetDB().Exec("DELETE FROM gosts")
+		if err != nil {
+			log.Fatalf("Failed to delete records: %v", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			log.Fatalf("Failed to get rows affected: %v", err)
+		}
+
+		fmt.Printf("Deleted %d records from gosts table\n", rowsAffected)
+		fmt.Println("\nNow you can reimport GOSTs using:")
+		fmt.Println("  go run cmd/import_gosts/main.go -all")
+		return
+	}
+
+	// Удаляем только ГОСТы указанного источника, остальные источники не затрагиваются
+	deleted, err := gostsDB.DeleteBySource(*sourceType)
 	if err != nil {
 		log.Fatalf("Failed to delete records: %v", err)
 	}
+	fmt.Printf("Deleted %d records of source %s\n", deleted, *sourceType)
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		log.Fatalf("Failed to get rows affected: %v", err)
+	if *filePath == "" {
+		sourceURL := "<url>"
+		if source, err := gostsDB.GetImportSource(*sourceType); err == nil {
+			sourceURL = source.URL
+		}
+		fmt.Println("\nNow you can reimport the source using:")
+		fmt.Printf("  go run cmd/import_gosts/main.go -download -source-url %s -source-type %s\n", sourceURL, *sourceType)
+		return
 	}
 
-	fmt.Printf("Deleted %d records from gosts table\n", rowsAffected)
-	fmt.Println("\nNow you can reimport GOSTs using:")
-	fmt.Println("  go run cmd/import_gosts/main.go -download -source-url <url> -source-type <type>")
+	result := importer.ImportGostFile(gostsDB, *filePath, *sourceType, config.LoadGostSourcePriority(), &reimportLogger{verbose: *verbose})
+	if result.Error != "" {
+		log.Fatalf("Failed to reimport %s: %s", *filePath, result.Error)
+	}
+	fmt.Printf("Reimported %d/%d GOSTs of source %s (errors: %d)\n", result.Success, result.Total, *sourceType, len(result.Errors))
 }
 
+// reimportLogger выводит ход импорта только в режиме -verbose
+type reimportLogger struct {
+	verbose bool
+}
+
+func (l *reimportLogger) Printf(format string, v ...interface{}) {
+	if l.verbose {
+		log.Printf(format, v...)
+	}
+}
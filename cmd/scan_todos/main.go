@@ -3,6 +3,7 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
 	"io/fs"
 	"log"
@@ -15,20 +16,21 @@ import (
 
 // TodoTask представляет задачу TODO
 type TodoTask struct {
-	ID             string    `json:"id"`
-	File           string    `json:"file"`
-	Line           int       `json:"line"`
-	Type           string    `json:"type"`     // TODO, FIXME, HACK, REFACTOR
-	Priority       string    `json:"priority"` // CRITICAL, HIGH, MEDIUM, LOW
-	Description    string    `json:"description"`
-	Status         string    `json:"status"` // OPEN, IN_PROGRESS, RESOLVED, TESTING
-	AssignedTo     string    `json:"assignedTo,omitempty"`
-	CreatedAt      time.Time `json:"createdAt"`
-	UpdatedAt      time.Time `json:"updatedAt"`
-	EstimatedHours int       `json:"estimatedHours"`
-	ActualHours    *int      `json:"actualHours,omitempty"`
-	Dependencies   []string  `json:"dependencies"`
-	RelatedFiles   []string  `json:"relatedFiles"`
+	ID             string     `json:"id"`
+	File           string     `json:"file"`
+	Line           int        `json:"line"`
+	Type           string     `json:"type"`     // TODO, FIXME, HACK, REFACTOR
+	Priority       string     `json:"priority"` // CRITICAL, HIGH, MEDIUM, LOW
+	Description    string     `json:"description"`
+	Status         string     `json:"status"` // OPEN, IN_PROGRESS, RESOLVED, TESTING
+	AssignedTo     string     `json:"assignedTo,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+	EstimatedHours int        `json:"estimatedHours"`
+	ActualHours    *int       `json:"actualHours,omitempty"`
+	Dependencies   []string   `json:"dependencies"`
+	RelatedFiles   []string   `json:"relatedFiles"`
+	ResolvedAt     *time.Time `json:"resolvedAt,omitempty"` // Когда строка TODO перестала находиться при сканировании
 }
 
 // TodoDB представляет базу данных задач
@@ -38,11 +40,24 @@ type TodoDB struct {
 	Version  string     `json:"version"`
 }
 
+// ScanResult результат сканирования директории
+type ScanResult struct {
+	ScannedFiles int // Просканированные файлы
+	SkippedFiles int // Файлы, не изменявшиеся после since
+	Resolved     int // Задачи, строки которых больше не найдены
+}
+
 // SmartTodoScanner сканирует код на наличие TODO
 type SmartTodoScanner struct {
 	patterns map[string]*regexp.Regexp
 	dbPath   string
 	db       *TodoDB
+	// since сканировать только файлы, измененные после этого времени (нулевое - все файлы)
+	since time.Time
+	// seenTasks ID задач, найденных при текущем сканировании
+	seenTasks map[string]bool
+	// scannedFiles файлы, просканированные при текущем сканировании
+	scannedFiles map[string]bool
 }
 
 // NewSmartTodoScanner создает новый сканер
@@ -58,6 +73,12 @@ func NewSmartTodoScanner(dbPath string) *SmartTodoScanner {
 	}
 }
 
+// SetSince ограничивает сканирование файлами, измененными после since.
+// Задачи непросканированных файлов при сверке не закрываются.
+func (s *SmartTodoScanner) SetSince(since time.Time) {
+	s.since = since
+}
+
 // LoadDB загружает базу данных задач
 func (s *SmartTodoScanner) LoadDB() error {
 	data, err := os.ReadFile(s.dbPath)
@@ -115,6 +136,9 @@ func (s *SmartTodoScanner) ScanFile(filePath string) error {
 	}
 
 	lines := strings.Split(string(content), "\n")
+	if s.scannedFiles != nil {
+		s.scannedFiles[filePath] = true
+	}
 
 	for lineNum, line := range lines {
 		lineNum++ // Нумерация с 1
@@ -135,6 +159,9 @@ func (s *SmartTodoScanner) ScanFile(filePath string) error {
 
 		// Создаем ID задачи
 		taskID := fmt.Sprintf("%s:%d", filePath, lineNum)
+		if s.seenTasks != nil {
+			s.seenTasks[taskID] = true
+		}
 
 		// Проверяем, существует ли уже задача
 		exists := false
@@ -145,6 +172,11 @@ func (s *SmartTodoScanner) ScanFile(filePath string) error {
 				s.db.Tasks[i].Description = description
 				s.db.Tasks[i].Priority = priority
 				s.db.Tasks[i].Type = todoType
+				// Строка TODO снова появилась - открываем задачу, закрытую сверкой
+				if s.db.Tasks[i].Status == "RESOLVED" && s.db.Tasks[i].ResolvedAt != nil {
+					s.db.Tasks[i].Status = "OPEN"
+					s.db.Tasks[i].ResolvedAt = nil
+				}
 				exists = true
 				break
 			}
@@ -290,7 +322,7 @@ func (s *SmartTodoScanner) extractDescription(line string) string {
 	line = strings.TrimSpace(line)
 
 	// Убираем технические маркеры, которые не являются описанием задачи
-	line = regexp.MustCompile(`(?i)^\s*(type|struct|interface|func|var|const|json:|`).ReplaceAllString(line, "")
+	line = regexp.MustCompile("(?i)^\\s*(type|struct|interface|func|var|const|json:|`)").ReplaceAllString(line, "")
 	line = strings.TrimSpace(line)
 
 	// Если описание слишком короткое или содержит только технические термины, возвращаем пустую строку
@@ -341,8 +373,14 @@ func (s *SmartTodoScanner) estimateHours(priority string) int {
 	}
 }
 
-// ScanDirectory рекурсивно сканирует директорию
-func (s *SmartTodoScanner) ScanDirectory(rootDir string) error {
+// ScanDirectory рекурсивно сканирует директорию и сверяет задачи с найденными строками:
+// задачи файлов внутри rootDir, чей ID (файл:строка) больше не соответствует TODO,
+// помечаются RESOLVED (см. resolveMissingTasks)
+func (s *SmartTodoScanner) ScanDirectory(rootDir string) (ScanResult, error) {
+	var result ScanResult
+	s.seenTasks = make(map[string]bool)
+	s.scannedFiles = make(map[string]bool)
+
 	extensions := map[string]bool{
 		".go": true, ".ts": true, ".tsx": true, ".js": true, ".jsx": true,
 		".sh": true, ".ps1": true, ".bat": true,
@@ -363,7 +401,7 @@ func (s *SmartTodoScanner) ScanDirectory(rootDir string) error {
 		"scan_todos_simple":    true,
 	}
 
-	return filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
+	err := filepath.WalkDir(rootDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil // Пропускаем ошибки доступа
 		}
@@ -396,28 +434,118 @@ func (s *SmartTodoScanner) ScanDirectory(rootDir string) error {
 			return nil
 		}
 
+		// Пропускаем файлы, не изменявшиеся после since
+		if !s.since.IsZero() {
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			if !info.ModTime().After(s.since) {
+				result.SkippedFiles++
+				return nil
+			}
+		}
+
 		// Сканируем файл
 		if err := s.ScanFile(path); err != nil {
 			log.Printf("Ошибка сканирования %s: %v", path, err)
 		}
+		result.ScannedFiles++
 
 		return nil
 	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Resolved = s.resolveMissingTasks(rootDir, time.Now())
+	return result, nil
+}
+
+// resolveMissingTasks помечает RESOLVED открытые задачи файлов внутри rootDir, которые
+// не найдены при последнем сканировании. Задачи файлов, пропущенных из-за since,
+// сохраняются, если файл существует. Возвращает количество закрытых задач.
+func (s *SmartTodoScanner) resolveMissingTasks(rootDir string, now time.Time) int {
+	resolved := 0
+	for i := range s.db.Tasks {
+		task := &s.db.Tasks[i]
+		if task.Status == "RESOLVED" || s.seenTasks[task.ID] || !isWithinDir(rootDir, task.File) {
+			continue
+		}
+		if !s.scannedFiles[task.File] && !s.since.IsZero() {
+			if _, err := os.Stat(task.File); err == nil {
+				continue
+			}
+		}
+
+		resolvedAt := now
+		task.Status = "RESOLVED"
+		task.ResolvedAt = &resolvedAt
+		task.UpdatedAt = now
+		resolved++
+	}
+	return resolved
+}
+
+// PruneResolved удаляет из базы задачи, закрытые сверкой, и возвращает их количество
+func (s *SmartTodoScanner) PruneResolved() int {
+	kept := s.db.Tasks[:0]
+	for _, task := range s.db.Tasks {
+		if task.Status == "RESOLVED" && task.ResolvedAt != nil {
+			continue
+		}
+		kept = append(kept, task)
+	}
+	pruned := len(s.db.Tasks) - len(kept)
+	s.db.Tasks = kept
+	return pruned
+}
+
+// isWithinDir проверяет, что путь находится внутри dir
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// parseSinceTime разбирает значение флага -since: RFC3339 или дата ГГГГ-ММ-ДД
+func parseSinceTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid -since value %q: expected RFC3339 or YYYY-MM-DD", value)
+	}
+	return t, nil
 }
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Println("Использование: scan_todos <директория>")
+	prune := flag.Bool("prune", false, "Удалить задачи, строки TODO которых больше не найдены")
+	since := flag.String("since", "", "Сканировать только файлы, измененные после времени (RFC3339 или ГГГГ-ММ-ДД)")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		fmt.Println("Использование: scan_todos [-prune] [-since время] <директория>")
 		os.Exit(1)
 	}
 
-	rootDir := os.Args[1]
+	rootDir := flag.Arg(0)
 	if rootDir == "" {
 		rootDir = "."
 	}
 
 	dbPath := ".todos/tasks.json"
 	scanner := NewSmartTodoScanner(dbPath)
+	if *since != "" {
+		sinceTime, err := parseSinceTime(*since)
+		if err != nil {
+			log.Fatalf("Ошибка разбора -since: %v", err)
+		}
+		scanner.SetSince(sinceTime)
+	}
 
 	// Загружаем БД
 	if err := scanner.LoadDB(); err != nil {
@@ -428,9 +556,14 @@ func main() {
 	fmt.Printf("📁 Директория: %s\n", rootDir)
 
 	// Сканируем
-	if err := scanner.ScanDirectory(rootDir); err != nil {
+	result, err := scanner.ScanDirectory(rootDir)
+	if err != nil {
 		log.Fatalf("Ошибка сканирования: %v", err)
 	}
+	pruned := 0
+	if *prune {
+		pruned = scanner.PruneResolved()
+	}
 
 	// Сохраняем БД
 	if err := scanner.SaveDB(); err != nil {
@@ -457,6 +590,11 @@ func main() {
 	fmt.Printf("   Открытых: %d\n", open)
 	fmt.Printf("   Критических: %d\n", critical)
 	fmt.Printf("   Завершенных: %d\n", total-open)
+	fmt.Printf("   Просканировано файлов: %d (пропущено без изменений: %d)\n", result.ScannedFiles, result.SkippedFiles)
+	fmt.Printf("   Закрыто (строки удалены): %d\n", result.Resolved)
+	if *prune {
+		fmt.Printf("   Удалено из базы: %d\n", pruned)
+	}
 
 	if scanner.db.LastScan != nil {
 		fmt.Printf("\n📅 Последнее сканирование: %s\n", scanner.db.LastScan.Format("2006-01-02 15:04:05"))
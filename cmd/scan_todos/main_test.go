@@ -0,0 +1,192 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestScanner создает сканер с базой задач во временной директории
+func newTestScanner(t *testing.T) *SmartTodoScanner {
+	t.Helper()
+
+	scanner := NewSmartTodoScanner(filepath.Join(t.TempDir(), "tasks.json"))
+	if err := scanner.LoadDB(); err != nil {
+		t.Fatalf("LoadDB failed: %v", err)
+	}
+	return scanner
+}
+
+func writeSourceFile(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func scanDir(t *testing.T, scanner *SmartTodoScanner, dir string) ScanResult {
+	t.Helper()
+	result, err := scanner.ScanDirectory(dir)
+	if err != nil {
+		t.Fatalf("ScanDirectory failed: %v", err)
+	}
+	return result
+}
+
+func taskStatuses(scanner *SmartTodoScanner) map[string]string {
+	statuses := make(map[string]string)
+	for _, task := range scanner.db.Tasks {
+		statuses[task.ID] = task.Status
+	}
+	return statuses
+}
+
+// TestScanDirectory_ReconcilesTodoLines проверяет сверку задач при добавлении, перемещении и удалении строк
+func TestScanDirectory_ReconcilesTodoLines(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "worker.go")
+	scanner := newTestScanner(t)
+
+	writeSourceFile(t, file,
+		"package worker",
+		"// TODO: add retry for uploads",
+		"// TODO: handle empty batches",
+	)
+	if result := scanDir(t, scanner, dir); result.Resolved != 0 {
+		t.Fatalf("Expected nothing resolved on first run, got %d", result.Resolved)
+	}
+	if len(scanner.db.Tasks) != 2 {
+		t.Fatalf("Expected 2 tasks after first run, got %+v", scanner.db.Tasks)
+	}
+
+	// Первая строка удалена, вторая сдвинута вниз добавленной строкой
+	writeSourceFile(t, file,
+		"package worker",
+		"",
+		"// TODO: log slow queries",
+		"// TODO: handle empty batches",
+	)
+	result := scanDir(t, scanner, dir)
+	if result.Resolved != 1 {
+		t.Errorf("Expected 1 resolved task, got %d", result.Resolved)
+	}
+
+	statuses := taskStatuses(scanner)
+	want := map[string]string{
+		file + ":2": "RESOLVED", // На строке больше нет TODO
+		file + ":3": "OPEN",     // На строке по-прежнему есть TODO
+		file + ":4": "OPEN",     // Перемещенная строка
+	}
+	for id, status := range want {
+		if statuses[id] != status {
+			t.Errorf("Task %s: expected status %s, got %q", id, status, statuses[id])
+		}
+	}
+	for _, task := range scanner.db.Tasks {
+		if task.Status == "RESOLVED" && task.ResolvedAt == nil {
+			t.Errorf("Resolved task %s has no resolvedAt", task.ID)
+		}
+	}
+
+	// Повторное появление строки открывает задачу, закрытую сверкой
+	writeSourceFile(t, file,
+		"package worker",
+		"// TODO: add retry for uploads",
+	)
+	scanDir(t, scanner, dir)
+	for _, task := range scanner.db.Tasks {
+		if task.ID == file+":2" && (task.Status != "OPEN" || task.ResolvedAt != nil) {
+			t.Errorf("Expected reappeared task to be reopened, got %+v", task)
+		}
+	}
+}
+
+// TestScanDirectory_ResolvesDeletedFiles проверяет закрытие задач удаленного файла и -prune
+func TestScanDirectory_ResolvesDeletedFiles(t *testing.T) {
+	dir := t.TempDir()
+	kept := filepath.Join(dir, "kept.go")
+	deleted := filepath.Join(dir, "deleted.go")
+	scanner := newTestScanner(t)
+
+	writeSourceFile(t, kept, "package main", "// TODO: split config loading")
+	writeSourceFile(t, deleted, "package main", "// FIXME: race on shutdown")
+	scanDir(t, scanner, dir)
+
+	if err := os.Remove(deleted); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	if result := scanDir(t, scanner, dir); result.Resolved != 1 {
+		t.Fatalf("Expected 1 resolved task, got %d", result.Resolved)
+	}
+
+	// Задача, закрытая вручную, не удаляется
+	scanner.db.Tasks = append(scanner.db.Tasks, TodoTask{ID: "manual", File: kept, Status: "RESOLVED"})
+
+	if pruned := scanner.PruneResolved(); pruned != 1 {
+		t.Errorf("Expected 1 pruned task, got %d", pruned)
+	}
+	statuses := taskStatuses(scanner)
+	if len(statuses) != 2 || statuses[kept+":2"] != "OPEN" || statuses["manual"] != "RESOLVED" {
+		t.Errorf("Unexpected tasks after prune: %+v", statuses)
+	}
+}
+
+// TestScanDirectory_Since проверяет, что -since пропускает старые файлы и не закрывает их задачи
+func TestScanDirectory_Since(t *testing.T) {
+	dir := t.TempDir()
+	oldFile := filepath.Join(dir, "old.go")
+	newFile := filepath.Join(dir, "new.go")
+	scanner := newTestScanner(t)
+
+	writeSourceFile(t, oldFile, "package main", "// TODO: remove legacy endpoint")
+	writeSourceFile(t, newFile, "package main", "// TODO: cache project list")
+	scanDir(t, scanner, dir)
+
+	since := time.Now().Add(-time.Hour)
+	oldTime := since.Add(-time.Hour)
+	if err := os.Chtimes(oldFile, oldTime, oldTime); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	writeSourceFile(t, newFile, "package main", "", "// TODO: cache project list")
+
+	scanner.SetSince(since)
+	result := scanDir(t, scanner, dir)
+	if result.ScannedFiles != 1 || result.SkippedFiles != 1 {
+		t.Errorf("Expected 1 scanned and 1 skipped file, got %+v", result)
+	}
+
+	statuses := taskStatuses(scanner)
+	if statuses[oldFile+":2"] != "OPEN" {
+		t.Errorf("Task of skipped file must stay open, got %q", statuses[oldFile+":2"])
+	}
+	if statuses[newFile+":2"] != "RESOLVED" || statuses[newFile+":3"] != "OPEN" {
+		t.Errorf("Moved task of scanned file was not reconciled: %+v", statuses)
+	}
+}
+
+// TestScanDirectory_KeepsTasksOutsideRoot проверяет, что сверка не трогает задачи других директорий
+func TestScanDirectory_KeepsTasksOutsideRoot(t *testing.T) {
+	dir := t.TempDir()
+	scanner := newTestScanner(t)
+	outside := filepath.Join(t.TempDir(), "other.go")
+	scanner.db.Tasks = append(scanner.db.Tasks, TodoTask{ID: outside + ":1", File: outside, Status: "OPEN"})
+
+	scanDir(t, scanner, dir)
+	if scanner.db.Tasks[0].Status != "OPEN" {
+		t.Errorf("Task outside scanned directory must stay open, got %s", scanner.db.Tasks[0].Status)
+	}
+}
+
+func TestParseSinceTime(t *testing.T) {
+	if _, err := parseSinceTime("2024-05-01"); err != nil {
+		t.Errorf("date: %v", err)
+	}
+	if _, err := parseSinceTime("2024-05-01T10:00:00Z"); err != nil {
+		t.Errorf("RFC3339: %v", err)
+	}
+	if _, err := parseSinceTime("yesterday"); err == nil {
+		t.Error("Expected error for invalid value")
+	}
+}
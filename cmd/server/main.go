@@ -29,6 +29,7 @@ import (
 	"httpserver/database"
 	"httpserver/gui"
 	"httpserver/internal/config"
+	"httpserver/internal/infrastructure/logging"
 	"httpserver/server"
 )
 
@@ -93,6 +94,18 @@ func main() {
 		log.Fatalf("Ошибка загрузки конфигурации из БД: %v", err)
 	}
 
+	// Дублируем логи в файл с ротацией, если он задан (по умолчанию только stdout).
+	// Записи для GUI по-прежнему идут через канал логов сервера.
+	if cfg.LogFile != "" {
+		logFile, err := logging.SetupStdLogger(cfg.LogFile, cfg.LogMaxSizeMB, cfg.LogMaxAgeDays, cfg.LogMaxBackups)
+		if err != nil {
+			log.Printf("Предупреждение: не удалось открыть файл логов %s: %v", cfg.LogFile, err)
+		} else {
+			defer logFile.Close()
+			log.Printf("Логи записываются в файл: %s", cfg.LogFile)
+		}
+	}
+
 	// Если конфигурации нет в БД, сохраняем текущую из env
 	configJSON, _ := serviceDB.GetAppConfig()
 	if configJSON == "" {
@@ -154,11 +167,12 @@ func main() {
 				}
 
 			case <-cleanupTicker.C:
-				// Очищаем метрики старше 7 дней
-				if err := db.CleanOldMetrics(7); err != nil {
-					log.Printf("⚠ [Метрики] Ошибка очистки старых данных: %v", err)
+				// Очищаем метрики старше 7 дней пачками, чтобы не блокировать БД
+				deleted, err := db.CleanOldMetricsBatched(7, cfg.MetricsCleanupBatchSize)
+				if err != nil {
+					log.Printf("⚠ [Метрики] Ошибка очистки старых данных (удалено %d записей): %v", deleted, err)
 				} else {
-					log.Printf("✓ [Метрики] Очистка завершена (retention: 7 дней)")
+					log.Printf("✓ [Метрики] Очистка завершена (retention: 7 дней, удалено записей: %d)", deleted)
 				}
 			}
 		}
@@ -180,6 +194,8 @@ func main() {
 						continue
 					}
 					
+					stats["dropped_log_entries"] = srv.DroppedLogEntries()
+
 					serverStats := server.ServerStats{
 						IsRunning:    true,
 						TotalStats:   stats,
@@ -1,9 +1,11 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"httpserver/classification"
@@ -15,7 +17,8 @@ func main() {
 
 	// Создаем классификатор
 	apiKey := os.Getenv("ARLIAI_API_KEY")
-	if apiKey == "" {
+	offline := apiKey == ""
+	if offline {
 		apiKey = "test_key" // Для тестирования без реального API
 	}
 	
@@ -25,6 +28,16 @@ func main() {
 	}
 
 	classifier := classification.NewAIClassifier(apiKey, model)
+	if offline {
+		// Без ключа ответы модели сценарные: классификация проверяется целиком, но без сети
+		fmt.Println("ARLIAI_API_KEY не задан, используется офлайн режим")
+		classifier.SetCompletionFunc(func(ctx context.Context, prompt string) (string, error) {
+			if strings.Contains(prompt, "Молоток") {
+				return `{"category_path": ["Дом и сад"], "confidence": 0.9}`, nil
+			}
+			return `{"category_path": ["Категория 12"], "confidence": 0.5}`, nil
+		})
+	}
 
 	// Создаем большое тестовое дерево категорий
 	fmt.Println("1. Создание тестового дерева категорий...")
@@ -98,11 +111,17 @@ func main() {
 	
 	// Выполняем классификацию (будет использован кэш для summary)
 	startTime := time.Now()
-	_, err := classifier.ClassifyWithAI(request)
+	result, err := classifier.ClassifyWithAI(request)
 	elapsed1 := time.Since(startTime)
 	
 	if err != nil {
-		fmt.Printf("   Ошибка классификации (ожидаемо без реального API): %v\n", err)
+		fmt.Printf("   Ошибка классификации: %v\n", err)
+	} else {
+		fmt.Printf("   Категория: %s (уверенность %.2f)\n", strings.Join(result.CategoryPath, " / "), result.Confidence)
+	}
+	if offline && (err != nil || strings.Join(result.CategoryPath, "/") != "Дом и сад") {
+		fmt.Println("   ОШИБКА: в офлайн режиме ожидалась категория \"Дом и сад\"")
+		os.Exit(1)
 	}
 	
 	// Получаем статистику после запроса
@@ -150,7 +169,10 @@ func main() {
 		MaxCategoryNameLen: 30,
 		EnableLogging:      false,
 	}
-	classifier.SetConfig(smallConfig)
+	if err := classifier.SetConfig(smallConfig); err != nil {
+		fmt.Printf("   ОШИБКА: не удалось применить маленькую конфигурацию: %v\n", err)
+		os.Exit(1)
+	}
 	
 	startTime = time.Now()
 	_, _ = classifier.ClassifyWithAI(request)
@@ -162,7 +184,10 @@ func main() {
 		MaxCategoryNameLen: 100,
 		EnableLogging:      false,
 	}
-	classifier.SetConfig(largeConfig)
+	if err := classifier.SetConfig(largeConfig); err != nil {
+		fmt.Printf("   ОШИБКА: не удалось применить большую конфигурацию: %v\n", err)
+		os.Exit(1)
+	}
 	
 	startTime = time.Now()
 	_, _ = classifier.ClassifyWithAI(request)
@@ -180,7 +205,10 @@ func main() {
 	fmt.Println("6. Тест метрик производительности...")
 	
 	// Восстанавливаем конфигурацию по умолчанию
-	classifier.SetConfig(config)
+	if err := classifier.SetConfig(config); err != nil {
+		fmt.Printf("   ОШИБКА: не удалось применить конфигурацию по умолчанию: %v\n", err)
+		os.Exit(1)
+	}
 	
 	// Симулируем несколько запросов
 	for i := 0; i < 5; i++ {
@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -28,80 +27,12 @@ func main() {
 	// Ищем ГОСТы о сварке в базе
 	fmt.Println("1. Поиск ГОСТов о сварке в базе данных...")
 	query := "свар"
-	
-	// Используем прямой SQL-запрос для избежания проблем с NULL
-	db := gostsDB.GetDB()
-	searchQuery := `
-		SELECT id, gost_number, title, adoption_date, effective_date, status,
-		       source_type, source_id, source_url, description, keywords,
-		       COALESCE(created_at, updated_at) as created_at, updated_at
-		FROM gosts
-		WHERE gost_number LIKE ? OR title LIKE ? OR keywords LIKE ?
-		ORDER BY gost_number
-		LIMIT ? OFFSET ?
-	`
-	
-	searchPattern := "%" + query + "%"
-	rows, err := db.Query(searchQuery, searchPattern, searchPattern, searchPattern, 10, 0)
+
+	// Полнотекстовый поиск по префиксу слова, результаты упорядочены по релевантности
+	gosts, total, err := gostsDB.SearchGosts(query, 10, 0, "", "", "", "", "", "")
 	if err != nil {
 		log.Fatalf("Ошибка поиска ГОСТов: %v", err)
 	}
-	defer rows.Close()
-
-	var gosts []*database.Gost
-	for rows.Next() {
-		gost := &database.Gost{}
-		var adoptionDate, effectiveDate sql.NullTime
-		var sourceID sql.NullInt64
-		var createdAtStr sql.NullString
-		var updatedAtStr sql.NullString
-
-		err := rows.Scan(
-			&gost.ID, &gost.GostNumber, &gost.Title,
-			&adoptionDate, &effectiveDate,
-			&gost.Status, &gost.SourceType, &sourceID,
-			&gost.SourceURL, &gost.Description, &gost.Keywords,
-			&createdAtStr, &updatedAtStr,
-		)
-		if err != nil {
-			log.Printf("Ошибка сканирования: %v", err)
-			continue
-		}
-
-		// Парсим даты из строк
-		if createdAtStr.Valid {
-			if t, err := time.Parse("2006-01-02 15:04:05", createdAtStr.String); err == nil {
-				gost.CreatedAt = t
-			} else if t, err := time.Parse(time.RFC3339, createdAtStr.String); err == nil {
-				gost.CreatedAt = t
-			}
-		}
-		if updatedAtStr.Valid {
-			if t, err := time.Parse("2006-01-02 15:04:05", updatedAtStr.String); err == nil {
-				gost.UpdatedAt = t
-			} else if t, err := time.Parse(time.RFC3339, updatedAtStr.String); err == nil {
-				gost.UpdatedAt = t
-			}
-		}
-		if !createdAtStr.Valid || gost.CreatedAt.IsZero() {
-			gost.CreatedAt = gost.UpdatedAt
-		}
-
-		if adoptionDate.Valid {
-			gost.AdoptionDate = &adoptionDate.Time
-		}
-		if effectiveDate.Valid {
-			gost.EffectiveDate = &effectiveDate.Time
-		}
-		if sourceID.Valid {
-			id := int(sourceID.Int64)
-			gost.SourceID = &id
-		}
-
-		gosts = append(gosts, gost)
-	}
-	
-	total := len(gosts) // Упрощенная версия, можно сделать отдельный COUNT запрос
 
 	if total == 0 {
 		log.Fatal("ГОСТы о сварке не найдены в базе данных.")
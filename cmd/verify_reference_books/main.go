@@ -171,6 +171,38 @@ func main() {
 	fmt.Printf("  Без ТУ/ГОСТ: %d\n", withoutTUGOST)
 	fmt.Println()
 
+	// Причины отсутствия связей: заглушки, созданные импортом, и коды, отсутствующие в справочниках
+	var okpd2Stubs, tnvedStubs, okpd2Missing, tnvedMissing int
+	conn.QueryRow(`SELECT COUNT(*) FROM okpd2_classifier WHERE source = ?`, database.ReferenceSourceGISPInline).Scan(&okpd2Stubs)
+	conn.QueryRow(`SELECT COUNT(*) FROM tnved_reference WHERE source = ?`, database.ReferenceSourceGISPInline).Scan(&tnvedStubs)
+
+	conn.QueryRow(`
+		SELECT COUNT(*) 
+		FROM client_benchmarks 
+		WHERE client_project_id = ? 
+		AND category = 'nomenclature'
+		AND source_database = 'gisp_gov_ru'
+		AND okpd2_reference_id IS NULL
+		AND json_extract(attributes, '$.missing_reference_codes.okpd2') IS NOT NULL
+	`, systemProject.ID).Scan(&okpd2Missing)
+
+	conn.QueryRow(`
+		SELECT COUNT(*) 
+		FROM client_benchmarks 
+		WHERE client_project_id = ? 
+		AND category = 'nomenclature'
+		AND source_database = 'gisp_gov_ru'
+		AND tnved_reference_id IS NULL
+		AND json_extract(attributes, '$.missing_reference_codes.tnved') IS NOT NULL
+	`, systemProject.ID).Scan(&tnvedMissing)
+
+	fmt.Printf("🧩 Коды, отсутствующие в справочниках:\n")
+	fmt.Printf("  Заглушки ОКПД2 (%s): %d\n", database.ReferenceSourceGISPInline, okpd2Stubs)
+	fmt.Printf("  Заглушки ТН ВЭД (%s): %d\n", database.ReferenceSourceGISPInline, tnvedStubs)
+	fmt.Printf("  Без ОКПД2 из-за отсутствующего кода: %d (из %d)\n", okpd2Missing, withoutOKPD2)
+	fmt.Printf("  Без ТН ВЭД из-за отсутствующего кода: %d (из %d)\n", tnvedMissing, withoutTNVED)
+	fmt.Println()
+
 	// Топ-10 наиболее используемых кодов
 	fmt.Printf("📈 Топ-10 наиболее используемых кодов:\n\n")
 
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// backupCopyChunk размер блока копирования; между блоками проверяется отмена контекста
+const backupCopyChunk = 1 << 20
+
+// BackupManifestName имя описания резервной копии в корне архива (WriteBackupArchiveWithManifest)
+const BackupManifestName = "manifest.json"
+
+// BackupEntry файл, добавляемый в резервную копию
+type BackupEntry struct {
+	SourcePath  string // Путь к файлу на диске
+	ArchivePath string // Путь внутри архива (main/..., uploads/..., service/...)
+}
+
+// BackupProgress прогресс создания резервной копии
+type BackupProgress struct {
+	FilesDone   int    `json:"files_done"`
+	FilesTotal  int    `json:"files_total"`
+	BytesDone   int64  `json:"bytes_done"`
+	BytesTotal  int64  `json:"bytes_total"`
+	CurrentFile string `json:"current_file,omitempty"`
+}
+
+// BackupArchiveResult результат создания ZIP архива резервной копии
+type BackupArchiveResult struct {
+	Path       string   `json:"path"`
+	FilesCount int      `json:"files_count"`
+	TotalSize  int64    `json:"total_size"`
+	Skipped    []string `json:"skipped,omitempty"` // Файлы, которые не удалось открыть
+}
+
+// BackupManifestEntry файл резервной копии в manifest.json
+type BackupManifestEntry struct {
+	ArchivePath string    `json:"archive_path"`
+	SourcePath  string    `json:"source_path"` // Абсолютный путь к исходному файлу
+	Size        int64     `json:"size"`
+	ModTime     time.Time `json:"mod_time"`
+}
+
+// BackupManifest содержимое manifest.json: по нему при восстановлении файлы возвращаются на исходные места
+type BackupManifest struct {
+	CreatedAt time.Time             `json:"created_at"`
+	Files     []BackupManifestEntry `json:"files"`
+}
+
+// WriteBackupArchive записывает файлы entries в ZIP архив zipPath. Архив пишется во временный
+// файл рядом с zipPath и переименовывается только после успешного завершения, поэтому при отмене
+// ctx или ошибке записи на диске не остается недописанного архива. Файлы, которые не удалось
+// открыть, пропускаются и возвращаются в Skipped. progress (может быть nil) вызывается после
+// каждого скопированного блока и каждого файла.
+func WriteBackupArchive(ctx context.Context, zipPath string, entries []BackupEntry, progress func(BackupProgress)) (*BackupArchiveResult, error) {
+	return writeBackupArchive(ctx, zipPath, entries, false, progress)
+}
+
+// WriteBackupArchiveWithManifest работает как WriteBackupArchive и дополнительно записывает
+// в архив BackupManifestName с исходным путем, размером и временем изменения каждого файла
+func WriteBackupArchiveWithManifest(ctx context.Context, zipPath string, entries []BackupEntry, progress func(BackupProgress)) (*BackupArchiveResult, error) {
+	return writeBackupArchive(ctx, zipPath, entries, true, progress)
+}
+
+func writeBackupArchive(ctx context.Context, zipPath string, entries []BackupEntry, withManifest bool, progress func(BackupProgress)) (*BackupArchiveResult, error) {
+	state := BackupProgress{FilesTotal: len(entries)}
+	for _, entry := range entries {
+		if info, err := os.Stat(entry.SourcePath); err == nil {
+			state.BytesTotal += info.Size()
+		}
+	}
+	report := func() {
+		if progress != nil {
+			progress(state)
+		}
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(zipPath), filepath.Base(zipPath)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary backup file: %w", err)
+	}
+	tempPath := tempFile.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			tempFile.Close()
+			os.Remove(tempPath)
+		}
+	}()
+
+	result := &BackupArchiveResult{Path: zipPath}
+	zipWriter := zip.NewWriter(tempFile)
+	buffer := make([]byte, backupCopyChunk)
+	manifest := BackupManifest{CreatedAt: time.Now(), Files: []BackupManifestEntry{}}
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		state.CurrentFile = entry.SourcePath
+
+		sourceFile, err := os.Open(entry.SourcePath)
+		if err != nil {
+			result.Skipped = append(result.Skipped, entry.SourcePath)
+			state.FilesDone++
+			report()
+			continue
+		}
+
+		archiveFile, err := zipWriter.Create(filepath.ToSlash(entry.ArchivePath))
+		if err != nil {
+			sourceFile.Close()
+			return nil, fmt.Errorf("failed to create archive entry for %s: %w", entry.SourcePath, err)
+		}
+
+		written, err := copyWithContext(ctx, archiveFile, sourceFile, buffer, func(n int64) {
+			state.BytesDone += n
+			report()
+		})
+		sourceFile.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy %s to archive: %w", entry.SourcePath, err)
+		}
+
+		result.FilesCount++
+		result.TotalSize += written
+		if withManifest {
+			manifestEntry := BackupManifestEntry{ArchivePath: filepath.ToSlash(entry.ArchivePath), SourcePath: entry.SourcePath, Size: written}
+			if absPath, err := filepath.Abs(entry.SourcePath); err == nil {
+				manifestEntry.SourcePath = absPath
+			}
+			if info, err := os.Stat(entry.SourcePath); err == nil {
+				manifestEntry.ModTime = info.ModTime()
+			}
+			manifest.Files = append(manifest.Files, manifestEntry)
+		}
+		state.FilesDone++
+		report()
+	}
+
+	if withManifest {
+		manifestFile, err := zipWriter.Create(BackupManifestName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create backup manifest: %w", err)
+		}
+		encoder := json.NewEncoder(manifestFile)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(manifest); err != nil {
+			return nil, fmt.Errorf("failed to write backup manifest: %w", err)
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finalize backup archive: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close backup archive: %w", err)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tempPath, zipPath); err != nil {
+		return nil, fmt.Errorf("failed to move backup archive to %s: %w", zipPath, err)
+	}
+	committed = true
+
+	return result, nil
+}
+
+// copyWithContext копирует src в dst блоками, проверяя отмену ctx перед каждым блоком.
+// onChunk вызывается с размером каждого записанного блока.
+func copyWithContext(ctx context.Context, dst io.Writer, src io.Reader, buffer []byte, onChunk func(int64)) (int64, error) {
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return written, err
+		}
+		n, readErr := src.Read(buffer)
+		if n > 0 {
+			if _, err := dst.Write(buffer[:n]); err != nil {
+				return written, err
+			}
+			written += int64(n)
+			onChunk(int64(n))
+		}
+		if readErr == io.EOF {
+			return written, nil
+		}
+		if readErr != nil {
+			return written, readErr
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package database
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeBackupSources(t *testing.T, dir string, sizes ...int) []BackupEntry {
+	t.Helper()
+
+	var entries []BackupEntry
+	for i, size := range sizes {
+		name := filepath.Join(dir, "source"+string(rune('a'+i))+".db")
+		if err := os.WriteFile(name, bytes.Repeat([]byte{byte('a' + i)}, size), 0644); err != nil {
+			t.Fatalf("Failed to write source file: %v", err)
+		}
+		entries = append(entries, BackupEntry{SourcePath: name, ArchivePath: filepath.Join("main", filepath.Base(name))})
+	}
+	return entries
+}
+
+func TestWriteBackupArchive_ReportsProgress(t *testing.T) {
+	dir := t.TempDir()
+	entries := writeBackupSources(t, dir, 100, 2*backupCopyChunk+10)
+	entries = append(entries, BackupEntry{SourcePath: filepath.Join(dir, "missing.db"), ArchivePath: "main/missing.db"})
+	zipPath := filepath.Join(dir, "backup.zip")
+
+	var last BackupProgress
+	calls := 0
+	result, err := WriteBackupArchive(context.Background(), zipPath, entries, func(p BackupProgress) {
+		calls++
+		last = p
+	})
+	if err != nil {
+		t.Fatalf("WriteBackupArchive failed: %v", err)
+	}
+
+	wantBytes := int64(100 + 2*backupCopyChunk + 10)
+	if result.FilesCount != 2 || result.TotalSize != wantBytes || len(result.Skipped) != 1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+	if last.FilesDone != 3 || last.FilesTotal != 3 || last.BytesDone != wantBytes || last.BytesTotal != wantBytes {
+		t.Errorf("Unexpected final progress %+v", last)
+	}
+	if calls < 5 {
+		t.Errorf("Expected progress per chunk, got %d calls", calls)
+	}
+
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open backup: %v", err)
+	}
+	defer reader.Close()
+	if len(reader.File) != 2 || reader.File[0].Name != "main/sourcea.db" {
+		t.Errorf("Unexpected archive contents: %d files", len(reader.File))
+	}
+}
+
+func TestWriteBackupArchive_CancelLeavesNoPartialZip(t *testing.T) {
+	dir := t.TempDir()
+	entries := writeBackupSources(t, dir, 3*backupCopyChunk, 3*backupCopyChunk)
+	backupDir := filepath.Join(dir, "backups")
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		t.Fatalf("Failed to create backup dir: %v", err)
+	}
+	zipPath := filepath.Join(backupDir, "backup.zip")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := WriteBackupArchive(ctx, zipPath, entries, func(p BackupProgress) {
+		// Отменяем посреди копирования первого файла
+		if p.BytesDone >= backupCopyChunk {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+
+	leftovers, err := os.ReadDir(backupDir)
+	if err != nil {
+		t.Fatalf("Failed to read backup dir: %v", err)
+	}
+	if len(leftovers) != 0 {
+		t.Errorf("Expected no files after cancelled backup, got %d (first: %s)", len(leftovers), leftovers[0].Name())
+	}
+}
+
+func TestRestoreBackupArchive_RoundTripWithManifest(t *testing.T) {
+	dir := t.TempDir()
+	entries := writeBackupSources(t, dir, 100, 2*backupCopyChunk+10)
+	entries[1].ArchivePath = "uploads/" + filepath.Base(entries[1].SourcePath)
+	zipPath := filepath.Join(dir, "backup.zip")
+	if _, err := WriteBackupArchiveWithManifest(context.Background(), zipPath, entries, nil); err != nil {
+		t.Fatalf("WriteBackupArchiveWithManifest failed: %v", err)
+	}
+
+	originals := make(map[string][]byte)
+	for _, entry := range entries {
+		data, err := os.ReadFile(entry.SourcePath)
+		if err != nil {
+			t.Fatalf("Failed to read source: %v", err)
+		}
+		originals[entry.SourcePath] = data
+		os.Remove(entry.SourcePath)
+	}
+
+	allowed := BackupRestoreOptions{AllowedDirs: []string{dir}}
+	result, err := RestoreBackupArchive(context.Background(), zipPath, allowed)
+	if err != nil {
+		t.Fatalf("RestoreBackupArchive failed: %v", err)
+	}
+	if !result.UsedManifest || len(result.Restored) != 2 || len(result.Skipped) != 0 {
+		t.Fatalf("Unexpected restore result %+v", result)
+	}
+	for sourcePath, want := range originals {
+		got, err := os.ReadFile(sourcePath)
+		if err != nil {
+			t.Fatalf("File %s was not restored to its original path: %v", sourcePath, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Restored %s differs from original", sourcePath)
+		}
+	}
+
+	// Существующие файлы не перезаписываются без Overwrite
+	if err := os.WriteFile(entries[0].SourcePath, []byte("changed"), 0644); err != nil {
+		t.Fatalf("Failed to modify restored file: %v", err)
+	}
+	if _, err := RestoreBackupArchive(context.Background(), zipPath, allowed); !errors.Is(err, ErrRestoreTargetExists) {
+		t.Fatalf("Expected ErrRestoreTargetExists, got %v", err)
+	}
+	if data, _ := os.ReadFile(entries[0].SourcePath); string(data) != "changed" {
+		t.Errorf("File was overwritten without Overwrite")
+	}
+
+	allowed.Overwrite = true
+	if _, err := RestoreBackupArchive(context.Background(), zipPath, allowed); err != nil {
+		t.Fatalf("RestoreBackupArchive with Overwrite failed: %v", err)
+	}
+	if data, _ := os.ReadFile(entries[0].SourcePath); !bytes.Equal(data, originals[entries[0].SourcePath]) {
+		t.Errorf("File was not overwritten with Overwrite")
+	}
+}
+
+func TestRestoreBackupArchive_RemovesStaleWAL(t *testing.T) {
+	dir := t.TempDir()
+	entries := writeBackupSources(t, dir, 100)
+	zipPath := filepath.Join(dir, "backup.zip")
+	if _, err := WriteBackupArchiveWithManifest(context.Background(), zipPath, entries, nil); err != nil {
+		t.Fatalf("WriteBackupArchiveWithManifest failed: %v", err)
+	}
+
+	// Живая база заменена, но рядом остались журнал и индекс WAL
+	dbPath := entries[0].SourcePath
+	if err := os.Remove(dbPath); err != nil {
+		t.Fatalf("Failed to remove source: %v", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.WriteFile(dbPath+suffix, []byte("stale"), 0644); err != nil {
+			t.Fatalf("Failed to write %s file: %v", suffix, err)
+		}
+	}
+
+	// Без Overwrite оставшийся журнал - конфликт, файлы не трогаются
+	options := BackupRestoreOptions{AllowedDirs: []string{dir}}
+	if _, err := RestoreBackupArchive(context.Background(), zipPath, options); !errors.Is(err, ErrRestoreTargetExists) {
+		t.Fatalf("Expected ErrRestoreTargetExists for leftover WAL, got %v", err)
+	}
+	if _, err := os.Stat(dbPath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Database was restored despite the conflict: %v", err)
+	}
+
+	options.Overwrite = true
+	if _, err := RestoreBackupArchive(context.Background(), zipPath, options); err != nil {
+		t.Fatalf("RestoreBackupArchive with Overwrite failed: %v", err)
+	}
+	if _, err := os.Stat(dbPath); err != nil {
+		t.Errorf("Database was not restored: %v", err)
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if _, err := os.Stat(dbPath + suffix); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("Stale %s file was not removed: %v", suffix, err)
+		}
+	}
+}
+
+func TestRestoreBackupArchive_LayoutWithoutManifest(t *testing.T) {
+	dir := t.TempDir()
+	entries := writeBackupSources(t, dir, 10, 20)
+	entries[1].ArchivePath = "uploads/" + filepath.Base(entries[1].SourcePath)
+	zipPath := filepath.Join(dir, "backup.zip")
+	if _, err := WriteBackupArchive(context.Background(), zipPath, entries, nil); err != nil {
+		t.Fatalf("WriteBackupArchive failed: %v", err)
+	}
+
+	targetDir := filepath.Join(dir, "restored")
+	result, err := RestoreBackupArchive(context.Background(), zipPath, BackupRestoreOptions{TargetDir: targetDir})
+	if err != nil {
+		t.Fatalf("RestoreBackupArchive failed: %v", err)
+	}
+	if result.UsedManifest || len(result.Restored) != 2 {
+		t.Fatalf("Unexpected restore result %+v", result)
+	}
+	for _, path := range []string{
+		filepath.Join(targetDir, "sourcea.db"),
+		filepath.Join(targetDir, "uploads", "sourceb.db"),
+	} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected restored file %s: %v", path, err)
+		}
+	}
+}
+
+func TestBackupRestorePath_RejectsUnknownEntries(t *testing.T) {
+	for _, archivePath := range []string{"../evil.db", "main/../../evil.db", "other/file.db", "uploads/nested/file.db", "main/"} {
+		if path, ok := backupRestorePath(archivePath, "", "data", nil); ok {
+			t.Errorf("backupRestorePath(%q) = %q, expected entry to be rejected", archivePath, path)
+		}
+	}
+}
+
+func TestBackupRestorePath_DistrustsManifestSourcePath(t *testing.T) {
+	dataDir := t.TempDir()
+	allowed := []string{dataDir}
+	fallback := filepath.Join("data", "service.db")
+
+	tests := []struct {
+		name       string
+		sourcePath string
+		want       string
+	}{
+		{"inside data dir", filepath.Join(dataDir, "service.db"), filepath.Join(dataDir, "service.db")},
+		{"nested data dir", filepath.Join(dataDir, "old", "service.db"), filepath.Join(dataDir, "old", "service.db")},
+		{"outside data dir", filepath.Join(t.TempDir(), "service.db"), fallback},
+		{"escapes data dir", dataDir + "/../service.db", fallback},
+		{"other file name", filepath.Join(dataDir, "main.db"), fallback},
+		{"relative", "service.db", fallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := backupRestorePath("service/service.db", tt.sourcePath, "", allowed)
+			if !ok || got != tt.want {
+				t.Errorf("backupRestorePath(%q) = %q, %v; want %q", tt.sourcePath, got, ok, tt.want)
+			}
+		})
+	}
+	// Исходный путь принимается только для .db файлов
+	if got, _ := backupRestorePath("main/notes.txt", filepath.Join(dataDir, "notes.txt"), "", allowed); got != filepath.Join("data", "notes.txt") {
+		t.Errorf("Expected non-database file to be restored into data dir, got %q", got)
+	}
+}
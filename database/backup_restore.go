@@ -0,0 +1,262 @@
+package database
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultBackupRestoreDir каталог данных, в который восстанавливаются архивы без manifest.json
+const DefaultBackupRestoreDir = "data"
+
+// ErrRestoreTargetExists возвращается RestoreBackupArchive, если восстанавливаемый файл уже существует
+// и перезапись не разрешена
+var ErrRestoreTargetExists = errors.New("restore target already exists")
+
+// sqliteSidecarSuffixes файлы журнала SQLite рядом с базой. Устаревший WAL, оставшийся от замененной базы,
+// SQLite применил бы к восстановленному файлу при следующем открытии, поэтому такие файлы считаются
+// частью цели восстановления: без Overwrite это конфликт, с Overwrite они удаляются.
+var sqliteSidecarSuffixes = []string{"-wal", "-shm"}
+
+// BackupRestoreOptions параметры восстановления резервной копии
+type BackupRestoreOptions struct {
+	// TargetDir каталог данных для восстановления: main/ и service/ распаковываются в него,
+	// uploads/ - в TargetDir/uploads. Пустое значение - исходные пути из manifest.json,
+	// а для архивов без описания и недопустимых исходных путей DefaultBackupRestoreDir.
+	TargetDir string
+	// AllowedDirs каталоги данных, внутри которых принимаются исходные пути из manifest.json.
+	// Пустое значение - рабочий каталог, из которого db-manager собирает резервные копии.
+	AllowedDirs []string
+	// Overwrite разрешает заменять существующие файлы
+	Overwrite bool
+}
+
+// RestoredBackupFile файл, восстановленный из резервной копии
+type RestoredBackupFile struct {
+	ArchivePath string `json:"archive_path"`
+	Path        string `json:"path"`
+	Size        int64  `json:"size"`
+}
+
+// BackupRestoreResult результат восстановления резервной копии
+type BackupRestoreResult struct {
+	Restored     []RestoredBackupFile `json:"restored"`
+	Skipped      []string             `json:"skipped,omitempty"` // Записи архива вне main/, uploads/ и service/
+	UsedManifest bool                 `json:"used_manifest"`
+}
+
+// RestoreBackupArchive восстанавливает файлы из архива WriteBackupArchive. Пути берутся из manifest.json,
+// для архивов без него - из раскладки main/, uploads/, service/ (см. BackupRestoreOptions.TargetDir).
+// Перед записью проверяются все целевые пути вместе с файлами -wal и -shm рядом с ними: если какой-то
+// файл существует и Overwrite не задан, возвращается ErrRestoreTargetExists и ничего не изменяется.
+// Каждый файл распаковывается во временный файл рядом с целевым и переименовывается после успешной
+// записи; оставшиеся -wal и -shm перед этим удаляются.
+func RestoreBackupArchive(ctx context.Context, zipPath string, options BackupRestoreOptions) (*BackupRestoreResult, error) {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open backup archive: %w", err)
+	}
+	defer reader.Close()
+
+	manifest, err := readBackupManifest(reader.File)
+	if err != nil {
+		return nil, err
+	}
+	result := &BackupRestoreResult{UsedManifest: manifest != nil}
+
+	allowedDirs, err := backupRestoreAllowedDirs(options.AllowedDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcePaths := make(map[string]string)
+	if manifest != nil {
+		for _, entry := range manifest.Files {
+			sourcePaths[entry.ArchivePath] = entry.SourcePath
+		}
+	}
+
+	type restoreTarget struct {
+		file *zip.File
+		path string
+	}
+	var targets []restoreTarget
+	var existing []string
+	for _, file := range reader.File {
+		if file.Name == BackupManifestName || strings.HasSuffix(file.Name, "/") {
+			continue
+		}
+		targetPath, ok := backupRestorePath(file.Name, sourcePaths[file.Name], options.TargetDir, allowedDirs)
+		if !ok {
+			result.Skipped = append(result.Skipped, file.Name)
+			continue
+		}
+		for _, checkPath := range append([]string{targetPath}, sqliteSidecarPaths(targetPath)...) {
+			if _, err := os.Stat(checkPath); err == nil {
+				existing = append(existing, checkPath)
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return nil, fmt.Errorf("failed to check restore target %s: %w", checkPath, err)
+			}
+		}
+		targets = append(targets, restoreTarget{file: file, path: targetPath})
+	}
+	if len(existing) > 0 && !options.Overwrite {
+		return nil, fmt.Errorf("%w: %s", ErrRestoreTargetExists, strings.Join(existing, ", "))
+	}
+
+	buffer := make([]byte, backupCopyChunk)
+	for _, target := range targets {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		written, err := restoreBackupFile(ctx, target.file, target.path, buffer)
+		if err != nil {
+			return result, err
+		}
+		result.Restored = append(result.Restored, RestoredBackupFile{
+			ArchivePath: target.file.Name,
+			Path:        target.path,
+			Size:        written,
+		})
+	}
+
+	return result, nil
+}
+
+// readBackupManifest читает manifest.json архива; nil - архив создан без описания
+func readBackupManifest(files []*zip.File) (*BackupManifest, error) {
+	for _, file := range files {
+		if file.Name != BackupManifestName {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open backup manifest: %w", err)
+		}
+		defer rc.Close()
+
+		var manifest BackupManifest
+		if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+			return nil, fmt.Errorf("failed to decode backup manifest: %w", err)
+		}
+		return &manifest, nil
+	}
+	return nil, nil
+}
+
+// backupRestoreAllowedDirs возвращает абсолютные пути каталогов BackupRestoreOptions.AllowedDirs
+func backupRestoreAllowedDirs(dirs []string) ([]string, error) {
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	allowed := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve restore directory %s: %w", dir, err)
+		}
+		allowed = append(allowed, absDir)
+	}
+	return allowed, nil
+}
+
+// backupRestorePath определяет путь восстановления записи архива. Принимаются только файлы
+// непосредственно в main/, uploads/ и service/. Исходному пути из manifest.json доверяют, только
+// если это .db файл с тем же именем, что и запись архива, внутри одного из allowedDirs;
+// иначе файл восстанавливается в каталог восстановления. Так ни запись архива, ни manifest.json
+// не могут указать путь за пределами каталогов данных.
+func backupRestorePath(archivePath, sourcePath, targetDir string, allowedDirs []string) (string, bool) {
+	dir, name := path.Split(archivePath)
+	if name == "" || name == "." || name == ".." {
+		return "", false
+	}
+
+	var subdir string
+	switch dir {
+	case "main/", "service/":
+	case "uploads/":
+		subdir = "uploads"
+	default:
+		return "", false
+	}
+
+	if targetDir == "" && isAllowedBackupSourcePath(sourcePath, name, allowedDirs) {
+		return filepath.Clean(sourcePath), true
+	}
+	if targetDir == "" {
+		targetDir = DefaultBackupRestoreDir
+	}
+	return filepath.Join(targetDir, subdir, name), true
+}
+
+// isAllowedBackupSourcePath проверяет исходный путь из manifest.json (см. backupRestorePath)
+func isAllowedBackupSourcePath(sourcePath, entryName string, allowedDirs []string) bool {
+	if sourcePath == "" || !filepath.IsAbs(sourcePath) {
+		return false
+	}
+	sourcePath = filepath.Clean(sourcePath)
+	if filepath.Base(sourcePath) != entryName || !strings.EqualFold(filepath.Ext(sourcePath), ".db") {
+		return false
+	}
+	for _, dir := range allowedDirs {
+		rel, err := filepath.Rel(dir, sourcePath)
+		if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// restoreBackupFile распаковывает запись архива в targetPath через временный файл
+func restoreBackupFile(ctx context.Context, file *zip.File, targetPath string, buffer []byte) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create directory for %s: %w", targetPath, err)
+	}
+
+	source, err := file.Open()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open archive entry %s: %w", file.Name, err)
+	}
+	defer source.Close()
+
+	tempFile, err := os.CreateTemp(filepath.Dir(targetPath), filepath.Base(targetPath)+".*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary file for %s: %w", targetPath, err)
+	}
+	tempPath := tempFile.Name()
+
+	written, err := copyWithContext(ctx, tempFile, source, buffer, func(int64) {})
+	if closeErr := tempFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tempPath)
+		return 0, fmt.Errorf("failed to restore %s: %w", file.Name, err)
+	}
+	for _, sidecarPath := range sqliteSidecarPaths(targetPath) {
+		if err := os.Remove(sidecarPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			os.Remove(tempPath)
+			return 0, fmt.Errorf("failed to remove stale %s: %w", sidecarPath, err)
+		}
+	}
+	if err := os.Rename(tempPath, targetPath); err != nil {
+		os.Remove(tempPath)
+		return 0, fmt.Errorf("failed to move restored file to %s: %w", targetPath, err)
+	}
+	return written, nil
+}
+
+// sqliteSidecarPaths пути файлов журнала SQLite для базы targetPath
+func sqliteSidecarPaths(targetPath string) []string {
+	paths := make([]string, 0, len(sqliteSidecarSuffixes))
+	for _, suffix := range sqliteSidecarSuffixes {
+		paths = append(paths, targetPath+suffix)
+	}
+	return paths
+}
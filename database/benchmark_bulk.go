@@ -0,0 +1,96 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// BenchmarkInput данные эталона для пакетной загрузки (BulkCreateBenchmarks)
+type BenchmarkInput struct {
+	OriginalName   string  `json:"original_name"`
+	NormalizedName string  `json:"normalized_name"`
+	Category       string  `json:"category"`
+	Subcategory    string  `json:"subcategory"`
+	Attributes     string  `json:"attributes"`
+	QualityScore   float64 `json:"quality_score"`
+	SourceDatabase string  `json:"source_database"`
+}
+
+// BulkBenchmarkResult результат пакетной загрузки эталонов
+type BulkBenchmarkResult struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+}
+
+// BulkCreateBenchmarks создает эталоны проекта в одной транзакции. Если в проекте уже есть эталон
+// с тем же исходным названием и категорией, он обновляется; изменение нормализованного названия
+// записывается в benchmark_name_history. При ошибке любой записи не сохраняется ни одна.
+func (db *ServiceDB) BulkCreateBenchmarks(projectID int, items []BenchmarkInput) (BulkBenchmarkResult, error) {
+	var result BulkBenchmarkResult
+	if len(items) == 0 {
+		return result, nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return result, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, item := range items {
+		if err := validateBenchmarkName(item.OriginalName); err != nil {
+			return BulkBenchmarkResult{}, err
+		}
+
+		var id int
+		var oldNormalizedName string
+		err := tx.QueryRow(`
+			SELECT id, normalized_name FROM client_benchmarks
+			WHERE client_project_id = ? AND original_name = ? AND category = ?
+			ORDER BY id LIMIT 1
+		`, projectID, item.OriginalName, item.Category).Scan(&id, &oldNormalizedName)
+		if err != nil && err != sql.ErrNoRows {
+			return BulkBenchmarkResult{}, fmt.Errorf("failed to find benchmark %q: %w", item.OriginalName, err)
+		}
+
+		if err == sql.ErrNoRows {
+			_, err := tx.Exec(`
+				INSERT INTO client_benchmarks
+				(client_project_id, original_name, normalized_name, category, subcategory, attributes, quality_score, source_database)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			`, projectID, item.OriginalName, item.NormalizedName, item.Category, item.Subcategory, item.Attributes, item.QualityScore, item.SourceDatabase)
+			if err != nil {
+				return BulkBenchmarkResult{}, fmt.Errorf("failed to create benchmark %q: %w", item.OriginalName, err)
+			}
+			result.Created++
+			continue
+		}
+
+		_, err = tx.Exec(`
+			UPDATE client_benchmarks
+			SET normalized_name = ?,
+			    subcategory = COALESCE(NULLIF(?, ''), subcategory),
+			    attributes = COALESCE(NULLIF(?, ''), attributes),
+			    quality_score = ?,
+			    source_database = COALESCE(NULLIF(?, ''), source_database),
+			    updated_at = CURRENT_TIMESTAMP
+			WHERE id = ?
+		`, item.NormalizedName, item.Subcategory, item.Attributes, item.QualityScore, item.SourceDatabase, id)
+		if err != nil {
+			return BulkBenchmarkResult{}, fmt.Errorf("failed to update benchmark %q: %w", item.OriginalName, err)
+		}
+		if oldNormalizedName != item.NormalizedName {
+			if err := recordBenchmarkNameChange(tx, id, oldNormalizedName, item.NormalizedName, "import"); err != nil {
+				return BulkBenchmarkResult{}, err
+			}
+		}
+		result.Updated++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return BulkBenchmarkResult{}, fmt.Errorf("failed to commit benchmarks: %w", err)
+	}
+	db.markProjectStatsDirty(projectID)
+
+	return result, nil
+}
@@ -0,0 +1,63 @@
+package database
+
+import "testing"
+
+func TestServiceDB_BulkCreateBenchmarks(t *testing.T) {
+	db := newTestServiceDB(t)
+
+	client, err := db.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	existing, err := db.CreateClientBenchmark(project.ID, "Болт М10", "болт м10", "nomenclature", "", "{}", "", 0.8)
+	if err != nil {
+		t.Fatalf("CreateClientBenchmark failed: %v", err)
+	}
+
+	result, err := db.BulkCreateBenchmarks(project.ID, []BenchmarkInput{
+		{OriginalName: "Болт М10", NormalizedName: "болт м10х1", Category: "nomenclature", QualityScore: 0.9},
+		{OriginalName: "Гайка М10", NormalizedName: "гайка м10", Category: "nomenclature", QualityScore: 0.7},
+		{OriginalName: "Болт М10", NormalizedName: "болт", Category: "counterparty", QualityScore: 0.5},
+	})
+	if err != nil {
+		t.Fatalf("BulkCreateBenchmarks failed: %v", err)
+	}
+	if result.Created != 2 || result.Updated != 1 {
+		t.Errorf("Expected 2 created and 1 updated, got %+v", result)
+	}
+
+	updated, err := db.GetClientBenchmark(existing.ID)
+	if err != nil {
+		t.Fatalf("GetClientBenchmark failed: %v", err)
+	}
+	if updated.NormalizedName != "болт м10х1" || updated.Attributes != "{}" {
+		t.Errorf("Expected updated name with kept attributes, got %q %q", updated.NormalizedName, updated.Attributes)
+	}
+
+	history, err := db.GetBenchmarkNameHistory(existing.ID)
+	if err != nil {
+		t.Fatalf("GetBenchmarkNameHistory failed: %v", err)
+	}
+	if len(history) != 1 || history[0].OldNormalizedName != "болт м10" || history[0].ChangedBy != "import" {
+		t.Errorf("Expected one import history entry, got %+v", history)
+	}
+
+	// Ошибка в любой записи откатывает всю пачку
+	if _, err := db.BulkCreateBenchmarks(project.ID, []BenchmarkInput{
+		{OriginalName: "Шайба", NormalizedName: "шайба", Category: "nomenclature"},
+		{OriginalName: " ", NormalizedName: "пусто", Category: "nomenclature"},
+	}); err == nil {
+		t.Fatal("Expected error for empty original name")
+	}
+	all, err := db.GetClientBenchmarks(project.ID, "", false)
+	if err != nil {
+		t.Fatalf("GetClientBenchmarks failed: %v", err)
+	}
+	if len(all) != 3 {
+		t.Errorf("Expected failed batch to be rolled back leaving 3 benchmarks, got %d", len(all))
+	}
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+)
+
+// seedCompactBenchmarks создает проект с n эталонами контрагентов; каждый третий утвержден
+func seedCompactBenchmarks(tb testing.TB, n int) (*ServiceDB, int) {
+	tb.Helper()
+
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		tb.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	tb.Cleanup(func() { db.Close() })
+
+	client, err := db.CreateClient("Compact Client", "Compact Client LLC", "", "", "", "")
+	if err != nil {
+		tb.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Compact Project", "counterparty", "", "1C", 0.8)
+	if err != nil {
+		tb.Fatalf("Failed to create project: %v", err)
+	}
+
+	for i := 0; i < n; i++ {
+		_, err := db.conn.Exec(`
+			INSERT INTO client_benchmarks (client_project_id, original_name, normalized_name, category,
+			                               quality_score, is_approved, legal_address, postal_address, bank_name)
+			VALUES (?, ?, ?, 'counterparty', 0.9, ?, ?, ?, ?)
+		`, project.ID, fmt.Sprintf("ООО Компания %d", i), fmt.Sprintf("Компания %d", i), i%3 == 0,
+			"г. Москва, ул. Длинная, д. 1, корп. 2, стр. 3, офис 456",
+			"123456, г. Москва, а/я 789",
+			"ПАО Очень Длинное Название Банка")
+		if err != nil {
+			tb.Fatalf("Failed to create benchmark: %v", err)
+		}
+	}
+
+	return db, project.ID
+}
+
+func TestGetClientBenchmarksCompact(t *testing.T) {
+	db, projectID := seedCompactBenchmarks(t, 10)
+
+	page, total, err := db.GetClientBenchmarksCompact(projectID, "counterparty", false, 4, 8)
+	if err != nil {
+		t.Fatalf("GetClientBenchmarksCompact failed: %v", err)
+	}
+	if total != 10 {
+		t.Errorf("Expected total 10, got %d", total)
+	}
+	if len(page) != 2 {
+		t.Fatalf("Expected 2 items on the last page, got %d", len(page))
+	}
+	if page[0].ID == 0 || page[0].NormalizedName == "" || page[0].Category != "counterparty" {
+		t.Errorf("Compact benchmark is missing list fields: %+v", page[0])
+	}
+
+	approved, total, err := db.GetClientBenchmarksCompact(projectID, "", true, 0, 0)
+	if err != nil {
+		t.Fatalf("GetClientBenchmarksCompact failed: %v", err)
+	}
+	if total != 4 || len(approved) != 4 {
+		t.Errorf("Expected 4 approved benchmarks, got total %d, items %d", total, len(approved))
+	}
+	for _, b := range approved {
+		if !b.IsApproved {
+			t.Errorf("Benchmark %d is not approved", b.ID)
+		}
+	}
+
+	empty, total, err := db.GetClientBenchmarksCompact(projectID, "nomenclature", false, 10, 0)
+	if err != nil {
+		t.Fatalf("GetClientBenchmarksCompact failed: %v", err)
+	}
+	if total != 0 || empty == nil || len(empty) != 0 {
+		t.Errorf("Expected empty non-nil page, got %v (total %d)", empty, total)
+	}
+}
+
+// BenchmarkGetClientBenchmarks_Full тестирует производительность полной выборки эталонов для списка
+func BenchmarkGetClientBenchmarks_Full(b *testing.B) {
+	db, projectID := seedCompactBenchmarks(b, 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := db.GetClientBenchmarks(projectID, "counterparty", false); err != nil {
+			b.Fatalf("GetClientBenchmarks failed: %v", err)
+		}
+	}
+}
+
+// BenchmarkGetClientBenchmarks_Compact тестирует производительность компактной выборки эталонов для списка
+func BenchmarkGetClientBenchmarks_Compact(b *testing.B) {
+	db, projectID := seedCompactBenchmarks(b, 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := db.GetClientBenchmarksCompact(projectID, "counterparty", false, 0, 0); err != nil {
+			b.Fatalf("GetClientBenchmarksCompact failed: %v", err)
+		}
+	}
+}
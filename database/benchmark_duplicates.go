@@ -0,0 +1,256 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// benchmarkReferences таблицы сервисной БД, ссылающиеся на client_benchmarks.
+// При слиянии дубликатов ссылки переносятся на оставшийся эталон.
+var benchmarkReferences = []struct {
+	table  string
+	column string
+}{
+	{"client_benchmarks", "manufacturer_benchmark_id"},
+	{"normalized_counterparties", "benchmark_id"},
+	{"benchmark_tags", "benchmark_id"},
+	{"benchmark_name_history", "benchmark_id"},
+}
+
+// BenchmarkDuplicateGroup группа эталонов проекта с одинаковыми категорией, нормализованным
+// названием, ИНН и производителем. KeepID - эталон, который остается при слиянии (утвержденный, затем
+// с наибольшим quality_score, затем самый ранний), DuplicateIDs - удаляемые эталоны.
+// Эталоны с разными ссылками на справочники (ОКПД2, ТН ВЭД, ТУ/ГОСТ) в одну группу не попадают.
+type BenchmarkDuplicateGroup struct {
+	ProjectID      int    `json:"project_id"`
+	Category       string `json:"category"`
+	NormalizedName string `json:"normalized_name"`
+	TaxID          string `json:"tax_id,omitempty"`
+	ManufacturerID int    `json:"manufacturer_benchmark_id,omitempty"`
+	KeepID         int    `json:"keep_id"`
+	DuplicateIDs   []int  `json:"duplicate_ids"`
+
+	refs benchmarkClassifierRefs // Ссылки на справочники, собранные по эталонам группы
+}
+
+// BenchmarkMergeResult результат MergeDuplicateBenchmarks
+type BenchmarkMergeResult struct {
+	ProjectID int                       `json:"project_id"`
+	DryRun    bool                      `json:"dry_run"`
+	Groups    []BenchmarkDuplicateGroup `json:"groups"`
+	Deleted   int                       `json:"deleted"`   // Удалено эталонов (в режиме dry-run - будет удалено)
+	Repointed int                       `json:"repointed"` // Перенесено ссылок на оставшиеся эталоны
+}
+
+// benchmarkQuerier общий интерфейс *sql.DB и *sql.Tx для поиска дубликатов
+type benchmarkQuerier interface {
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// FindDuplicateBenchmarks возвращает группы дубликатов эталонов проекта, например созданные
+// повторными импортами без ключа обновления. Названия сравниваются без учета регистра и лишних пробелов.
+func (db *ServiceDB) FindDuplicateBenchmarks(projectID int) ([]BenchmarkDuplicateGroup, error) {
+	return findDuplicateBenchmarks(db.conn, projectID)
+}
+
+// benchmarkClassifierRefs ссылки эталона на справочники (0 - ссылки нет)
+type benchmarkClassifierRefs [3]int
+
+// merge дополняет ссылки группы ссылками эталона. Возвращает false, если ссылки противоречат друг другу.
+func (r *benchmarkClassifierRefs) merge(other benchmarkClassifierRefs) bool {
+	for i := range r {
+		if r[i] != 0 && other[i] != 0 && r[i] != other[i] {
+			return false
+		}
+	}
+	for i := range r {
+		if r[i] == 0 {
+			r[i] = other[i]
+		}
+	}
+	return true
+}
+
+// duplicateBenchmarkRow эталон-кандидат для поиска дубликатов
+type duplicateBenchmarkRow struct {
+	id             int
+	category       string
+	name           string
+	taxID          string
+	manufacturerID int
+	refs           benchmarkClassifierRefs
+}
+
+// findDuplicateBenchmarks группирует эталоны проекта в Go: LOWER в SQLite не приводит кириллицу к нижнему регистру
+func findDuplicateBenchmarks(q benchmarkQuerier, projectID int) ([]BenchmarkDuplicateGroup, error) {
+	rows, err := q.Query(`
+		SELECT id, category, normalized_name, COALESCE(tax_id, ''), COALESCE(manufacturer_benchmark_id, 0),
+			COALESCE(okpd2_reference_id, 0), COALESCE(tnved_reference_id, 0), COALESCE(tu_gost_reference_id, 0)
+		FROM client_benchmarks
+		WHERE client_project_id = ?
+		ORDER BY COALESCE(is_approved, 0) DESC, COALESCE(quality_score, 0) DESC, id
+	`, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate benchmarks: %w", err)
+	}
+	defer rows.Close()
+
+	var benchmarks []duplicateBenchmarkRow
+	for rows.Next() {
+		var row duplicateBenchmarkRow
+		if err := rows.Scan(&row.id, &row.category, &row.name, &row.taxID, &row.manufacturerID,
+			&row.refs[0], &row.refs[1], &row.refs[2]); err != nil {
+			return nil, fmt.Errorf("failed to scan duplicate benchmark: %w", err)
+		}
+		row.name = strings.ToLower(strings.Join(strings.Fields(row.name), " "))
+		row.taxID = strings.TrimSpace(row.taxID)
+		benchmarks = append(benchmarks, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating duplicate benchmarks: %w", err)
+	}
+
+	// Строки отсортированы так, что первая в группе - эталон, который остается.
+	// Сначала группируются эталоны без производителя (в том числе сами производители), затем
+	// номенклатура: ссылки на копии одного производителя сводятся к копии, которая останется.
+	groupsByKey := make(map[string][]int)
+	keptIDs := make(map[int]int)
+	var candidates []BenchmarkDuplicateGroup
+	for _, withManufacturer := range []bool{false, true} {
+		for _, row := range benchmarks {
+			if (row.manufacturerID != 0) != withManufacturer {
+				continue
+			}
+			manufacturerID := row.manufacturerID
+			if keptID, ok := keptIDs[manufacturerID]; ok {
+				manufacturerID = keptID
+			}
+
+			key := fmt.Sprintf("%s\x00%s\x00%s\x00%d", row.category, row.name, row.taxID, manufacturerID)
+			joined := false
+			for _, idx := range groupsByKey[key] {
+				if candidates[idx].refs.merge(row.refs) {
+					candidates[idx].DuplicateIDs = append(candidates[idx].DuplicateIDs, row.id)
+					keptIDs[row.id] = candidates[idx].KeepID
+					joined = true
+					break
+				}
+			}
+			if joined {
+				continue
+			}
+			groupsByKey[key] = append(groupsByKey[key], len(candidates))
+			candidates = append(candidates, BenchmarkDuplicateGroup{
+				ProjectID:      projectID,
+				Category:       row.category,
+				NormalizedName: row.name,
+				TaxID:          row.taxID,
+				ManufacturerID: manufacturerID,
+				KeepID:         row.id,
+				refs:           row.refs,
+			})
+		}
+	}
+
+	var groups []BenchmarkDuplicateGroup
+	for _, group := range candidates {
+		if len(group.DuplicateIDs) > 0 {
+			sort.Ints(group.DuplicateIDs)
+			groups = append(groups, group)
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].KeepID < groups[j].KeepID })
+
+	return groups, nil
+}
+
+// MergeDuplicateBenchmarks сливает дубликаты эталонов проекта: ссылки на удаляемые эталоны
+// (manufacturer_benchmark_id, контрагенты, теги, история названий) переносятся на оставшийся,
+// после чего дубликаты удаляются. Все изменения выполняются в одной транзакции, группы
+// определяются заново внутри нее. При dryRun возвращается только отчет, данные не изменяются.
+func (db *ServiceDB) MergeDuplicateBenchmarks(projectID int, dryRun bool) (*BenchmarkMergeResult, error) {
+	if projectID <= 0 {
+		return nil, fmt.Errorf("invalid project id: %d", projectID)
+	}
+
+	result := &BenchmarkMergeResult{ProjectID: projectID, DryRun: dryRun}
+	if dryRun {
+		groups, err := db.FindDuplicateBenchmarks(projectID)
+		if err != nil {
+			return nil, err
+		}
+		result.Groups = groups
+		for _, group := range groups {
+			result.Deleted += len(group.DuplicateIDs)
+		}
+		return result, nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	groups, err := findDuplicateBenchmarks(tx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	result.Groups = groups
+
+	for _, group := range groups {
+		for _, id := range group.DuplicateIDs {
+			repointed, err := repointBenchmarkReferences(tx, id, group.KeepID)
+			if err != nil {
+				return nil, err
+			}
+			result.Repointed += repointed
+
+			// Ссылки на справочники, которых нет у оставшегося эталона, берутся у дубликата
+			if _, err := tx.Exec(`
+				UPDATE client_benchmarks SET
+					okpd2_reference_id = COALESCE(okpd2_reference_id, (SELECT okpd2_reference_id FROM client_benchmarks WHERE id = ?)),
+					tnved_reference_id = COALESCE(tnved_reference_id, (SELECT tnved_reference_id FROM client_benchmarks WHERE id = ?)),
+					tu_gost_reference_id = COALESCE(tu_gost_reference_id, (SELECT tu_gost_reference_id FROM client_benchmarks WHERE id = ?))
+				WHERE id = ?
+			`, id, id, id, group.KeepID); err != nil {
+				return nil, fmt.Errorf("failed to copy classifier references of benchmark %d: %w", id, err)
+			}
+
+			if _, err := tx.Exec(`DELETE FROM client_benchmarks WHERE id = ?`, id); err != nil {
+				return nil, fmt.Errorf("failed to delete duplicate benchmark %d: %w", id, err)
+			}
+			result.Deleted++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit benchmark merge: %w", err)
+	}
+	db.markProjectStatsDirty(projectID)
+
+	return result, nil
+}
+
+// repointBenchmarkReferences переносит ссылки с эталона id на keepID. Возвращает число перенесенных ссылок.
+func repointBenchmarkReferences(tx *sql.Tx, id, keepID int) (int, error) {
+	repointed := 0
+	for _, ref := range benchmarkReferences {
+		// OR IGNORE: ссылки, уже существующие у оставшегося эталона, удалятся каскадно вместе с дубликатом
+		query := fmt.Sprintf(`UPDATE OR IGNORE %s SET %s = ? WHERE %s = ?`, ref.table, ref.column, ref.column)
+		res, err := tx.Exec(query, keepID, id)
+		if err != nil {
+			errStr := strings.ToLower(err.Error())
+			if strings.Contains(errStr, "no such table") || strings.Contains(errStr, "no such column") {
+				continue
+			}
+			return 0, fmt.Errorf("failed to move %s references from benchmark %d to %d: %w", ref.table, id, keepID, err)
+		}
+		if affected, err := res.RowsAffected(); err == nil {
+			repointed += int(affected)
+		}
+	}
+	return repointed, nil
+}
@@ -0,0 +1,151 @@
+package database
+
+import "testing"
+
+func TestMergeDuplicateBenchmarks_KeepsReferences(t *testing.T) {
+	db := newTestServiceDB(t)
+
+	client, err := db.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+
+	createBenchmark := func(name, category, taxID string, quality float64) int {
+		t.Helper()
+		benchmark, err := db.CreateClientBenchmark(project.ID, name, name, category, "", "", "gisp_gov_ru", quality)
+		if err != nil {
+			t.Fatalf("CreateClientBenchmark failed: %v", err)
+		}
+		if taxID != "" {
+			if _, err := db.conn.Exec(`UPDATE client_benchmarks SET tax_id = ? WHERE id = ?`, taxID, benchmark.ID); err != nil {
+				t.Fatalf("Failed to set tax_id: %v", err)
+			}
+		}
+		return benchmark.ID
+	}
+
+	// Три копии производителя: остается утвержденная, хотя у другой копии выше quality_score
+	firstCopy := createBenchmark("ООО Завод", "manufacturer", "7701234567", 0.9)
+	approvedCopy := createBenchmark("ооо завод ", "manufacturer", "7701234567", 0.5)
+	lastCopy := createBenchmark("ООО Завод", "manufacturer", "7701234567", 0.7)
+	if err := db.ApproveBenchmark(approvedCopy, "tester"); err != nil {
+		t.Fatalf("ApproveBenchmark failed: %v", err)
+	}
+	// Тот же производитель с другим ИНН - не дубликат
+	otherINN := createBenchmark("ООО Завод", "manufacturer", "7707654321", 0.9)
+
+	setColumn := func(id int, column string, value int) {
+		t.Helper()
+		if _, err := db.conn.Exec(`UPDATE client_benchmarks SET `+column+` = ? WHERE id = ?`, value, id); err != nil {
+			t.Fatalf("Failed to set %s: %v", column, err)
+		}
+	}
+
+	// Номенклатуры ссылаются на разные копии одного производителя - после слияния это один производитель
+	products := make([]int, 0, 3)
+	for _, manufacturerID := range []int{firstCopy, approvedCopy, lastCopy} {
+		productID := createBenchmark("Болт М10", "nomenclature", "", 0.8)
+		setColumn(productID, "manufacturer_benchmark_id", manufacturerID)
+		products = append(products, productID)
+	}
+	// Та же номенклатура другого производителя - не дубликат
+	otherManufacturerProduct := createBenchmark("Болт М10", "nomenclature", "", 0.8)
+	setColumn(otherManufacturerProduct, "manufacturer_benchmark_id", otherINN)
+
+	// Разные коды ОКПД2 - не дубликаты
+	nutA := createBenchmark("Гайка М10", "nomenclature", "", 0.8)
+	nutB := createBenchmark("Гайка М10", "nomenclature", "", 0.7)
+	setColumn(nutA, "okpd2_reference_id", 101)
+	setColumn(nutB, "okpd2_reference_id", 102)
+
+	// Ссылка есть только у дубликата - переносится на оставшийся эталон
+	washerKept := createBenchmark("Шайба 10", "nomenclature", "", 0.9)
+	washerDuplicate := createBenchmark("Шайба 10", "nomenclature", "", 0.5)
+	setColumn(washerDuplicate, "tnved_reference_id", 201)
+	if err := db.AddBenchmarkTag(lastCopy, "поставщик"); err != nil {
+		t.Fatalf("AddBenchmarkTag failed: %v", err)
+	}
+
+	report, err := db.MergeDuplicateBenchmarks(project.ID, true)
+	if err != nil {
+		t.Fatalf("Dry run failed: %v", err)
+	}
+	if len(report.Groups) != 3 || report.Deleted != 5 {
+		t.Fatalf("Expected 3 groups with 5 duplicates, got %+v", report)
+	}
+	var count int
+	db.conn.QueryRow(`SELECT COUNT(*) FROM client_benchmarks WHERE client_project_id = ?`, project.ID).Scan(&count)
+	if count != 12 {
+		t.Fatalf("Dry run must not change data, got %d benchmarks", count)
+	}
+
+	result, err := db.MergeDuplicateBenchmarks(project.ID, false)
+	if err != nil {
+		t.Fatalf("MergeDuplicateBenchmarks failed: %v", err)
+	}
+	if result.Deleted != 5 {
+		t.Errorf("Expected 5 deleted benchmarks, got %d", result.Deleted)
+	}
+
+	var manufacturerGroup *BenchmarkDuplicateGroup
+	for i := range result.Groups {
+		if result.Groups[i].Category == "manufacturer" {
+			manufacturerGroup = &result.Groups[i]
+		}
+	}
+	if manufacturerGroup == nil || manufacturerGroup.KeepID != approvedCopy {
+		t.Fatalf("Expected approved manufacturer copy to be kept, got %+v", result.Groups)
+	}
+
+	// Оставшаяся номенклатура ссылается на оставшегося производителя
+	var productID, manufacturerID int
+	if err := db.conn.QueryRow(`
+		SELECT id, manufacturer_benchmark_id FROM client_benchmarks
+		WHERE client_project_id = ? AND category = 'nomenclature' AND manufacturer_benchmark_id != ?
+	`, project.ID, otherINN).Scan(&productID, &manufacturerID); err != nil {
+		t.Fatalf("Expected a single remaining nomenclature: %v", err)
+	}
+	if productID != products[0] || manufacturerID != approvedCopy {
+		t.Errorf("Expected nomenclature %d linked to %d, got %d linked to %d", products[0], approvedCopy, productID, manufacturerID)
+	}
+
+	var dangling int
+	db.conn.QueryRow(`
+		SELECT COUNT(*) FROM client_benchmarks
+		WHERE manufacturer_benchmark_id IS NOT NULL
+		AND manufacturer_benchmark_id NOT IN (SELECT id FROM client_benchmarks)
+	`).Scan(&dangling)
+	if dangling != 0 {
+		t.Errorf("Expected no dangling manufacturer references, got %d", dangling)
+	}
+
+	tags, err := db.GetBenchmarkTags(approvedCopy)
+	if err != nil {
+		t.Fatalf("GetBenchmarkTags failed: %v", err)
+	}
+	if len(tags) != 1 || tags[0] != "поставщик" {
+		t.Errorf("Expected tag moved to kept benchmark, got %v", tags)
+	}
+
+	for _, id := range []int{otherINN, otherManufacturerProduct, nutA, nutB, washerKept} {
+		if _, err := db.GetClientBenchmark(id); err != nil {
+			t.Errorf("Benchmark %d must be kept: %v", id, err)
+		}
+	}
+	var tnvedRef int
+	if err := db.conn.QueryRow(`SELECT COALESCE(tnved_reference_id, 0) FROM client_benchmarks WHERE id = ?`, washerKept).Scan(&tnvedRef); err != nil {
+		t.Fatalf("Failed to read kept benchmark: %v", err)
+	}
+	if tnvedRef != 201 {
+		t.Errorf("Expected TN VED reference copied from duplicate, got %d", tnvedRef)
+	}
+
+	again, err := db.FindDuplicateBenchmarks(project.ID)
+	if err != nil || len(again) != 0 {
+		t.Errorf("Expected no duplicates after merge, got %+v (%v)", again, err)
+	}
+}
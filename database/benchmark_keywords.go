@@ -0,0 +1,80 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// MigrateBenchmarkKeywords добавляет поле keywords для хранения ключевых слов номенклатуры
+func MigrateBenchmarkKeywords(db *sql.DB) error {
+	migrations := []string{
+		`ALTER TABLE client_benchmarks ADD COLUMN keywords TEXT`,
+	}
+
+	for _, migration := range migrations {
+		_, err := db.Exec(migration)
+		if err != nil {
+			errStr := strings.ToLower(err.Error())
+			// Игнорируем ошибки, если поле уже существует
+			if !strings.Contains(errStr, "duplicate column") &&
+				!strings.Contains(errStr, "already exists") {
+				return fmt.Errorf("migration failed: %s, error: %w", migration, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// UpdateBenchmarkKeywords сохраняет ключевые слова эталона.
+// Слова хранятся через пробел, что позволяет искать точное совпадение слова через LIKE.
+func (db *ServiceDB) UpdateBenchmarkKeywords(benchmarkID int, keywords []string) error {
+	_, err := db.conn.Exec(`
+		UPDATE client_benchmarks
+		SET keywords = ?
+		WHERE id = ?
+	`, strings.Join(keywords, " "), benchmarkID)
+	if err != nil {
+		return fmt.Errorf("failed to update benchmark keywords: %w", err)
+	}
+	return nil
+}
+
+// SearchBenchmarksByKeyword ищет эталоны проекта, среди ключевых слов которых есть keyword.
+// Ключевые слова заполняются только для номенклатуры, поэтому контрагенты в выдачу не попадают.
+// Слово должно быть нормализовано так же, как при сохранении (normalization.ExtractKeywords).
+func (db *ServiceDB) SearchBenchmarksByKeyword(projectID int, keyword string, limit int) ([]*ClientBenchmark, error) {
+	keyword = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(keyword)), "ё", "е")
+	if keyword == "" || strings.ContainsAny(keyword, " %_") {
+		return []*ClientBenchmark{}, nil
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+
+	query := `SELECT ` + clientBenchmarkListColumns + `
+		FROM client_benchmarks
+		WHERE client_project_id = ?
+		  AND keywords IS NOT NULL
+		  AND (' ' || keywords || ' ') LIKE ?
+		ORDER BY quality_score DESC, id
+		LIMIT ?
+	`
+
+	rows, err := db.conn.Query(query, projectID, "% "+keyword+" %", limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search benchmarks by keyword: %w", err)
+	}
+	defer rows.Close()
+
+	benchmarks, err := scanClientBenchmarks(rows)
+	if err != nil {
+		return nil, err
+	}
+	if benchmarks == nil {
+		benchmarks = []*ClientBenchmark{}
+	}
+
+	return benchmarks, nil
+}
@@ -0,0 +1,67 @@
+package database
+
+import (
+	"testing"
+)
+
+func TestSearchBenchmarksByKeyword(t *testing.T) {
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	client, err := db.CreateClient("Keyword Client", "Keyword Client LLC", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Keyword Project", "nomenclature", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	benchmarks := map[string][]string{
+		"Болт М10х40 оцинкованный": {"болт", "м10х40", "оцинкованный"},
+		"Болтовое соединение":      {"болтовое", "соединение"},
+		"Гайка М10":                {"гайка", "м10"},
+	}
+	for name, keywords := range benchmarks {
+		benchmark, err := db.CreateNomenclatureBenchmark(project.ID, name, name, "", "", "test", 0.9, nil, nil, nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to create benchmark %q: %v", name, err)
+		}
+		if err := db.UpdateBenchmarkKeywords(benchmark.ID, keywords); err != nil {
+			t.Fatalf("UpdateBenchmarkKeywords failed: %v", err)
+		}
+	}
+
+	// Ищется слово целиком, а не подстрока: "болт" не должен находить "болтовое"
+	results, err := db.SearchBenchmarksByKeyword(project.ID, "Болт", 10)
+	if err != nil {
+		t.Fatalf("SearchBenchmarksByKeyword failed: %v", err)
+	}
+	if len(results) != 1 || results[0].OriginalName != "Болт М10х40 оцинкованный" {
+		t.Fatalf("Expected only the bolt benchmark, got %d results", len(results))
+	}
+	if results[0].Keywords != "болт м10х40 оцинкованный" {
+		t.Errorf("Unexpected keywords %q", results[0].Keywords)
+	}
+
+	results, err = db.SearchBenchmarksByKeyword(project.ID, "м10", 10)
+	if err != nil {
+		t.Fatalf("SearchBenchmarksByKeyword failed: %v", err)
+	}
+	if len(results) != 1 || results[0].OriginalName != "Гайка М10" {
+		t.Errorf("Expected only the nut benchmark for 'м10', got %d results", len(results))
+	}
+
+	for _, keyword := range []string{"", "%", "шайба"} {
+		results, err = db.SearchBenchmarksByKeyword(project.ID, keyword, 10)
+		if err != nil {
+			t.Fatalf("SearchBenchmarksByKeyword(%q) failed: %v", keyword, err)
+		}
+		if len(results) != 0 {
+			t.Errorf("SearchBenchmarksByKeyword(%q) returned %d results, want 0", keyword, len(results))
+		}
+	}
+}
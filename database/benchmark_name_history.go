@@ -0,0 +1,124 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// BenchmarkNameHistoryEntry запись истории изменения нормализованного названия эталона
+type BenchmarkNameHistoryEntry struct {
+	ID                int       `json:"id"`
+	BenchmarkID       int       `json:"benchmark_id"`
+	OldNormalizedName string    `json:"old_normalized_name"`
+	NewNormalizedName string    `json:"new_normalized_name"`
+	ChangedBy         string    `json:"changed_by"`
+	ChangedAt         time.Time `json:"changed_at"`
+}
+
+// recordBenchmarkNameChange добавляет запись в историю названий в рамках транзакции
+func recordBenchmarkNameChange(tx *sql.Tx, benchmarkID int, oldName, newName, changedBy string) error {
+	_, err := tx.Exec(`
+		INSERT INTO benchmark_name_history (benchmark_id, old_normalized_name, new_normalized_name, changed_by, changed_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+	`, benchmarkID, oldName, newName, changedBy)
+	if err != nil {
+		return fmt.Errorf("failed to record benchmark name change: %w", err)
+	}
+	return nil
+}
+
+// GetBenchmarkNameHistory возвращает историю изменений нормализованного названия эталона,
+// начиная с самого свежего изменения
+func (db *ServiceDB) GetBenchmarkNameHistory(benchmarkID int) ([]*BenchmarkNameHistoryEntry, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, benchmark_id, COALESCE(old_normalized_name, ''), new_normalized_name,
+		       COALESCE(changed_by, ''), changed_at
+		FROM benchmark_name_history
+		WHERE benchmark_id = ?
+		ORDER BY id DESC
+	`, benchmarkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get benchmark name history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []*BenchmarkNameHistoryEntry{}
+	for rows.Next() {
+		entry := &BenchmarkNameHistoryEntry{}
+		if err := rows.Scan(
+			&entry.ID, &entry.BenchmarkID, &entry.OldNormalizedName, &entry.NewNormalizedName,
+			&entry.ChangedBy, &entry.ChangedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan benchmark name history: %w", err)
+		}
+		history = append(history, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating benchmark name history: %w", err)
+	}
+
+	return history, nil
+}
+
+// RevertBenchmarkName откатывает последнее изменение нормализованного названия эталона.
+// Откат сам записывается в историю, поэтому повторный вызов возвращает отмененное название.
+func (db *ServiceDB) RevertBenchmarkName(benchmarkID int, changedBy string) (*BenchmarkNameHistoryEntry, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var currentName string
+	err = tx.QueryRow(`SELECT normalized_name FROM client_benchmarks WHERE id = ?`, benchmarkID).Scan(&currentName)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("benchmark %d not found", benchmarkID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current benchmark name: %w", err)
+	}
+
+	var previousName sql.NullString
+	err = tx.QueryRow(`
+		SELECT old_normalized_name FROM benchmark_name_history
+		WHERE benchmark_id = ?
+		ORDER BY id DESC
+		LIMIT 1
+	`, benchmarkID).Scan(&previousName)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("benchmark %d has no name history to revert", benchmarkID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last benchmark name change: %w", err)
+	}
+	if !previousName.Valid || previousName.String == "" {
+		return nil, fmt.Errorf("benchmark %d has no previous name to revert to", benchmarkID)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE client_benchmarks
+		SET normalized_name = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, previousName.String, benchmarkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revert benchmark name: %w", err)
+	}
+
+	if err := recordBenchmarkNameChange(tx, benchmarkID, currentName, previousName.String, changedBy); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit benchmark name revert: %w", err)
+	}
+
+	return &BenchmarkNameHistoryEntry{
+		BenchmarkID:       benchmarkID,
+		OldNormalizedName: currentName,
+		NewNormalizedName: previousName.String,
+		ChangedBy:         changedBy,
+		ChangedAt:         time.Now(),
+	}, nil
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"testing"
+)
+
+// createTestBenchmark создает клиента, проект и эталон для тестов истории названий
+// и возвращает ID эталона
+func createTestBenchmark(t *testing.T, db *ServiceDB) int {
+	t.Helper()
+
+	client, err := db.CreateClient("History Client", "History Client LLC", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	project, err := db.CreateClientProject(client.ID, "History Project", "counterparty", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	result, err := db.conn.Exec(`
+		INSERT INTO client_benchmarks (client_project_id, original_name, normalized_name, category, quality_score)
+		VALUES (?, 'ООО Ромашка', 'Ромашка', 'counterparty', 0.9)
+	`, project.ID)
+	if err != nil {
+		t.Fatalf("Failed to create benchmark: %v", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		t.Fatalf("Failed to get benchmark ID: %v", err)
+	}
+
+	return int(id)
+}
+
+func TestBenchmarkNameHistory_AccumulatesAcrossUpdates(t *testing.T) {
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	benchmarkID := createTestBenchmark(t, db)
+
+	names := []string{"Ромашка ООО", "Ромашка", "РОМАШКА"}
+	for _, name := range names {
+		if err := db.UpdateBenchmark(benchmarkID, "ООО Ромашка", name, "", "", "", 0.95); err != nil {
+			t.Fatalf("UpdateBenchmark(%q) failed: %v", name, err)
+		}
+	}
+
+	// Обновление без смены названия не должно создавать запись
+	if err := db.UpdateBenchmark(benchmarkID, "ООО Ромашка", "РОМАШКА", "", "", "", 0.97); err != nil {
+		t.Fatalf("UpdateBenchmark without rename failed: %v", err)
+	}
+
+	history, err := db.GetBenchmarkNameHistory(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetBenchmarkNameHistory failed: %v", err)
+	}
+	if len(history) != len(names) {
+		t.Fatalf("Expected %d history rows, got %d", len(names), len(history))
+	}
+
+	// История отсортирована от новых к старым
+	expected := []struct{ old, new string }{
+		{"Ромашка", "РОМАШКА"},
+		{"Ромашка ООО", "Ромашка"},
+		{"Ромашка", "Ромашка ООО"},
+	}
+	for i, entry := range history {
+		if entry.OldNormalizedName != expected[i].old || entry.NewNormalizedName != expected[i].new {
+			t.Errorf("Entry %d: expected %q -> %q, got %q -> %q",
+				i, expected[i].old, expected[i].new, entry.OldNormalizedName, entry.NewNormalizedName)
+		}
+		if entry.ChangedBy != "system" {
+			t.Errorf("Entry %d: expected changed_by 'system', got %q", i, entry.ChangedBy)
+		}
+		if entry.ChangedAt.IsZero() {
+			t.Errorf("Entry %d: changed_at is not set", i)
+		}
+	}
+}
+
+func TestRevertBenchmarkName(t *testing.T) {
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	benchmarkID := createTestBenchmark(t, db)
+
+	if _, err := db.RevertBenchmarkName(benchmarkID, "admin"); err == nil {
+		t.Error("Expected error when reverting benchmark without history")
+	}
+
+	if err := db.UpdateBenchmark(benchmarkID, "ООО Ромашка", "Плохое название", "", "", "", 0.95); err != nil {
+		t.Fatalf("UpdateBenchmark failed: %v", err)
+	}
+
+	entry, err := db.RevertBenchmarkName(benchmarkID, "admin")
+	if err != nil {
+		t.Fatalf("RevertBenchmarkName failed: %v", err)
+	}
+	if entry.NewNormalizedName != "Ромашка" {
+		t.Errorf("Expected revert to 'Ромашка', got %q", entry.NewNormalizedName)
+	}
+
+	var normalizedName string
+	if err := db.conn.QueryRow("SELECT normalized_name FROM client_benchmarks WHERE id = ?", benchmarkID).Scan(&normalizedName); err != nil {
+		t.Fatalf("Failed to read benchmark: %v", err)
+	}
+	if normalizedName != "Ромашка" {
+		t.Errorf("Expected normalized name 'Ромашка' after revert, got %q", normalizedName)
+	}
+
+	history, err := db.GetBenchmarkNameHistory(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetBenchmarkNameHistory failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 history rows (change + revert), got %d", len(history))
+	}
+	if history[0].ChangedBy != "admin" {
+		t.Errorf("Expected revert to be recorded by 'admin', got %q", history[0].ChangedBy)
+	}
+}
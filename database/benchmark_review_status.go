@@ -0,0 +1,140 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Статусы согласования эталона (client_benchmarks.review_status)
+const (
+	BenchmarkReviewDraft    = "draft"    // эталон создан, на согласование не отправлялся
+	BenchmarkReviewPending  = "pending"  // ожидает проверки
+	BenchmarkReviewApproved = "approved" // утвержден (is_approved = TRUE)
+	BenchmarkReviewRejected = "rejected" // отклонен проверяющим
+)
+
+// BenchmarkReviewTransitions допустимые переходы между статусами согласования эталона.
+// Повторная установка текущего статуса (например, для обновления заметок) разрешена всегда.
+var BenchmarkReviewTransitions = map[string][]string{
+	BenchmarkReviewDraft:    {BenchmarkReviewPending, BenchmarkReviewApproved},
+	BenchmarkReviewPending:  {BenchmarkReviewApproved, BenchmarkReviewRejected, BenchmarkReviewDraft},
+	BenchmarkReviewApproved: {BenchmarkReviewPending, BenchmarkReviewRejected},
+	BenchmarkReviewRejected: {BenchmarkReviewPending, BenchmarkReviewDraft},
+}
+
+// ErrInvalidReviewStatus возвращается для неизвестного статуса согласования
+var ErrInvalidReviewStatus = errors.New("invalid benchmark review status")
+
+// ErrInvalidReviewTransition возвращается, если переход между статусами не разрешен BenchmarkReviewTransitions
+var ErrInvalidReviewTransition = errors.New("invalid benchmark review status transition")
+
+// IsValidBenchmarkReviewStatus проверяет, что статус входит в рабочий процесс согласования
+func IsValidBenchmarkReviewStatus(status string) bool {
+	_, ok := BenchmarkReviewTransitions[status]
+	return ok
+}
+
+// MigrateBenchmarkReviewStatus добавляет поля согласования эталонов.
+// Уже утвержденные эталоны получают статус approved, остальные - draft.
+func MigrateBenchmarkReviewStatus(db *sql.DB) error {
+	migrations := []string{
+		`ALTER TABLE client_benchmarks ADD COLUMN review_status TEXT NOT NULL DEFAULT 'draft'
+			CHECK (review_status IN ('draft', 'pending', 'approved', 'rejected'))`,
+		`ALTER TABLE client_benchmarks ADD COLUMN review_notes TEXT`,
+		`ALTER TABLE client_benchmarks ADD COLUMN reviewed_by TEXT`,
+		`ALTER TABLE client_benchmarks ADD COLUMN reviewed_at TIMESTAMP`,
+	}
+
+	for _, migration := range migrations {
+		_, err := db.Exec(migration)
+		if err != nil {
+			errStr := strings.ToLower(err.Error())
+			// Игнорируем ошибки, если поле уже существует
+			if !strings.Contains(errStr, "duplicate column") &&
+				!strings.Contains(errStr, "already exists") {
+				return fmt.Errorf("migration failed: %s, error: %w", migration, err)
+			}
+		}
+	}
+
+	// is_approved производное от review_status, поэтому утвержденные записи всегда approved
+	if _, err := db.Exec(`
+		UPDATE client_benchmarks SET review_status = 'approved'
+		WHERE is_approved = TRUE AND review_status <> 'approved'
+	`); err != nil {
+		return fmt.Errorf("failed to backfill benchmark review status: %w", err)
+	}
+
+	if _, err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_client_benchmarks_review_status
+		ON client_benchmarks(client_project_id, review_status)
+	`); err != nil {
+		return fmt.Errorf("failed to create review status index: %w", err)
+	}
+
+	return nil
+}
+
+// SetBenchmarkReviewStatus переводит эталон в новый статус согласования и сохраняет заметки проверяющего.
+// is_approved выставляется по статусу approved; при утверждении reviewer записывается в approved_by,
+// при выходе из approved сведения об утверждении очищаются.
+func (db *ServiceDB) SetBenchmarkReviewStatus(benchmarkID int, status, reviewer, notes string) error {
+	if !IsValidBenchmarkReviewStatus(status) {
+		return fmt.Errorf("%w: %q", ErrInvalidReviewStatus, status)
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var currentStatus string
+	err = tx.QueryRow(`SELECT review_status FROM client_benchmarks WHERE id = ?`, benchmarkID).Scan(&currentStatus)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("benchmark %d not found", benchmarkID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get benchmark review status: %w", err)
+	}
+
+	if currentStatus != status && !isAllowedReviewTransition(currentStatus, status) {
+		return fmt.Errorf("%w: %s -> %s", ErrInvalidReviewTransition, currentStatus, status)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE client_benchmarks
+		SET review_status = ?,
+		    review_notes = ?,
+		    reviewed_by = ?,
+		    reviewed_at = CURRENT_TIMESTAMP,
+		    is_approved = ?,
+		    approved_by = CASE WHEN ? THEN ? ELSE NULL END,
+		    approved_at = CASE WHEN ? THEN COALESCE(CASE WHEN is_approved THEN approved_at END, CURRENT_TIMESTAMP) ELSE NULL END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, status, notes, reviewer, status == BenchmarkReviewApproved,
+		status == BenchmarkReviewApproved, reviewer,
+		status == BenchmarkReviewApproved, benchmarkID)
+	if err != nil {
+		return fmt.Errorf("failed to set benchmark review status: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit benchmark review status: %w", err)
+	}
+
+	return nil
+}
+
+// isAllowedReviewTransition проверяет переход по BenchmarkReviewTransitions
+func isAllowedReviewTransition(from, to string) bool {
+	for _, allowed := range BenchmarkReviewTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
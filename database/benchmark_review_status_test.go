@@ -0,0 +1,204 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func newReviewTestDB(t *testing.T) (*ServiceDB, int) {
+	t.Helper()
+
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db, createTestBenchmark(t, db)
+}
+
+func TestBenchmarkReviewStatus_DefaultsToDraft(t *testing.T) {
+	db, benchmarkID := newReviewTestDB(t)
+
+	benchmark, err := db.GetClientBenchmark(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetClientBenchmark failed: %v", err)
+	}
+	if benchmark.ReviewStatus != BenchmarkReviewDraft {
+		t.Errorf("Expected new benchmark in %q, got %q", BenchmarkReviewDraft, benchmark.ReviewStatus)
+	}
+	if benchmark.IsApproved {
+		t.Error("New benchmark should not be approved")
+	}
+}
+
+func TestSetBenchmarkReviewStatus_Transitions(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         []string // статусы, через которые эталон проходит до проверяемого перехода
+		to           string
+		wantErr      error
+		wantApproved bool
+	}{
+		{name: "draft -> pending", to: BenchmarkReviewPending},
+		{name: "draft -> approved", to: BenchmarkReviewApproved, wantApproved: true},
+		{name: "draft -> rejected", to: BenchmarkReviewRejected, wantErr: ErrInvalidReviewTransition},
+		{name: "pending -> approved", path: []string{BenchmarkReviewPending}, to: BenchmarkReviewApproved, wantApproved: true},
+		{name: "pending -> rejected", path: []string{BenchmarkReviewPending}, to: BenchmarkReviewRejected},
+		{name: "pending -> draft", path: []string{BenchmarkReviewPending}, to: BenchmarkReviewDraft},
+		{name: "approved -> pending", path: []string{BenchmarkReviewApproved}, to: BenchmarkReviewPending},
+		{name: "approved -> rejected", path: []string{BenchmarkReviewApproved}, to: BenchmarkReviewRejected},
+		{name: "approved -> draft", path: []string{BenchmarkReviewApproved}, to: BenchmarkReviewDraft, wantErr: ErrInvalidReviewTransition, wantApproved: true},
+		{name: "rejected -> pending", path: []string{BenchmarkReviewPending, BenchmarkReviewRejected}, to: BenchmarkReviewPending},
+		{name: "rejected -> draft", path: []string{BenchmarkReviewPending, BenchmarkReviewRejected}, to: BenchmarkReviewDraft},
+		{name: "rejected -> approved", path: []string{BenchmarkReviewPending, BenchmarkReviewRejected}, to: BenchmarkReviewApproved, wantErr: ErrInvalidReviewTransition},
+		{name: "unknown status", to: "archived", wantErr: ErrInvalidReviewStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, benchmarkID := newReviewTestDB(t)
+
+			for _, status := range tt.path {
+				if err := db.SetBenchmarkReviewStatus(benchmarkID, status, "setup", ""); err != nil {
+					t.Fatalf("Failed to move benchmark to %q: %v", status, err)
+				}
+			}
+			before, err := db.GetClientBenchmark(benchmarkID)
+			if err != nil {
+				t.Fatalf("GetClientBenchmark failed: %v", err)
+			}
+
+			err = db.SetBenchmarkReviewStatus(benchmarkID, tt.to, "reviewer", "notes for "+tt.to)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+				}
+			} else if err != nil {
+				t.Fatalf("SetBenchmarkReviewStatus failed: %v", err)
+			}
+
+			benchmark, err := db.GetClientBenchmark(benchmarkID)
+			if err != nil {
+				t.Fatalf("GetClientBenchmark failed: %v", err)
+			}
+
+			if tt.wantErr != nil {
+				// Отклоненный переход ничего не меняет
+				if benchmark.ReviewStatus != before.ReviewStatus || benchmark.ReviewNotes != before.ReviewNotes {
+					t.Errorf("Rejected transition changed benchmark: %q (%q) -> %q (%q)",
+						before.ReviewStatus, before.ReviewNotes, benchmark.ReviewStatus, benchmark.ReviewNotes)
+				}
+			} else {
+				if benchmark.ReviewStatus != tt.to {
+					t.Errorf("Expected review_status %q, got %q", tt.to, benchmark.ReviewStatus)
+				}
+				if benchmark.ReviewNotes != "notes for "+tt.to || benchmark.ReviewedBy != "reviewer" || benchmark.ReviewedAt == nil {
+					t.Errorf("Review details not saved: notes=%q by=%q at=%v",
+						benchmark.ReviewNotes, benchmark.ReviewedBy, benchmark.ReviewedAt)
+				}
+			}
+
+			if benchmark.IsApproved != tt.wantApproved {
+				t.Errorf("Expected is_approved=%v, got %v", tt.wantApproved, benchmark.IsApproved)
+			}
+			if benchmark.IsApproved && (benchmark.ApprovedBy == "" || benchmark.ApprovedAt == nil) {
+				t.Errorf("Approved benchmark should have approved_by/approved_at, got %q/%v", benchmark.ApprovedBy, benchmark.ApprovedAt)
+			}
+			if !benchmark.IsApproved && (benchmark.ApprovedBy != "" || benchmark.ApprovedAt != nil) {
+				t.Errorf("Not approved benchmark should not keep approval details, got %q/%v", benchmark.ApprovedBy, benchmark.ApprovedAt)
+			}
+		})
+	}
+}
+
+func TestSetBenchmarkReviewStatus_NotFound(t *testing.T) {
+	db, _ := newReviewTestDB(t)
+
+	if err := db.SetBenchmarkReviewStatus(999999, BenchmarkReviewPending, "reviewer", ""); err == nil {
+		t.Error("Expected error for missing benchmark")
+	}
+}
+
+func TestGetClientBenchmarks_ReviewStatusFilter(t *testing.T) {
+	db, benchmarkID := newReviewTestDB(t)
+
+	benchmark, err := db.GetClientBenchmark(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetClientBenchmark failed: %v", err)
+	}
+	projectID := benchmark.ClientProjectID
+
+	pending, err := db.CreateClientBenchmark(projectID, "ООО Лютик", "Лютик", "counterparty", "", "", "", 0.8)
+	if err != nil {
+		t.Fatalf("CreateClientBenchmark failed: %v", err)
+	}
+	if err := db.SetBenchmarkReviewStatus(pending.ID, BenchmarkReviewPending, "author", "на проверку"); err != nil {
+		t.Fatalf("SetBenchmarkReviewStatus failed: %v", err)
+	}
+
+	all, err := db.GetClientBenchmarks(projectID, "", false)
+	if err != nil {
+		t.Fatalf("GetClientBenchmarks failed: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("Expected 2 benchmarks without filter, got %d", len(all))
+	}
+
+	onlyPending, err := db.GetClientBenchmarks(projectID, "", false, BenchmarkReviewPending)
+	if err != nil {
+		t.Fatalf("GetClientBenchmarks failed: %v", err)
+	}
+	if len(onlyPending) != 1 || onlyPending[0].ID != pending.ID || onlyPending[0].ReviewNotes != "на проверку" {
+		t.Errorf("Expected only pending benchmark %d, got %+v", pending.ID, onlyPending)
+	}
+
+	several, err := db.GetClientBenchmarks(projectID, "", false, BenchmarkReviewDraft, BenchmarkReviewPending)
+	if err != nil {
+		t.Fatalf("GetClientBenchmarks failed: %v", err)
+	}
+	if len(several) != 2 {
+		t.Errorf("Expected 2 benchmarks for draft+pending, got %d", len(several))
+	}
+
+	if _, err := db.GetClientBenchmarks(projectID, "", false, "archived"); !errors.Is(err, ErrInvalidReviewStatus) {
+		t.Errorf("Expected ErrInvalidReviewStatus, got %v", err)
+	}
+}
+
+func TestMigrateBenchmarkReviewStatus_BackfillsApproved(t *testing.T) {
+	db, benchmarkID := newReviewTestDB(t)
+
+	// Эталон, утвержденный до появления review_status
+	if _, err := db.conn.Exec(`UPDATE client_benchmarks SET is_approved = TRUE, review_status = 'draft' WHERE id = ?`, benchmarkID); err != nil {
+		t.Fatalf("Failed to prepare legacy approved benchmark: %v", err)
+	}
+
+	if err := MigrateBenchmarkReviewStatus(db.conn); err != nil {
+		t.Fatalf("MigrateBenchmarkReviewStatus failed: %v", err)
+	}
+
+	benchmark, err := db.GetClientBenchmark(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetClientBenchmark failed: %v", err)
+	}
+	if benchmark.ReviewStatus != BenchmarkReviewApproved {
+		t.Errorf("Expected legacy approved benchmark to become %q, got %q", BenchmarkReviewApproved, benchmark.ReviewStatus)
+	}
+
+	// ApproveBenchmark также выставляет статус approved
+	other, err := db.CreateClientBenchmark(benchmark.ClientProjectID, "ООО Лютик", "Лютик", "counterparty", "", "", "", 0.8)
+	if err != nil {
+		t.Fatalf("CreateClientBenchmark failed: %v", err)
+	}
+	if err := db.ApproveBenchmark(other.ID, "admin"); err != nil {
+		t.Fatalf("ApproveBenchmark failed: %v", err)
+	}
+	approved, err := db.GetClientBenchmark(other.ID)
+	if err != nil {
+		t.Fatalf("GetClientBenchmark failed: %v", err)
+	}
+	if approved.ReviewStatus != BenchmarkReviewApproved || !approved.IsApproved {
+		t.Errorf("ApproveBenchmark should set approved status, got %q (is_approved=%v)", approved.ReviewStatus, approved.IsApproved)
+	}
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidBenchmarkField возвращается для поля, которое нельзя обновить через UpdateBenchmarkFieldsSelective
+var ErrInvalidBenchmarkField = errors.New("invalid benchmark field")
+
+// benchmarkSelectiveTextFields текстовые поля эталона, доступные для частичного обновления
+var benchmarkSelectiveTextFields = map[string]bool{
+	"original_name":         true,
+	"normalized_name":       true,
+	"subcategory":           true,
+	"attributes":            true,
+	"source_database":       true,
+	"tax_id":                true,
+	"kpp":                   true,
+	"ogrn":                  true,
+	"region":                true,
+	"legal_address":         true,
+	"postal_address":        true,
+	"contact_phone":         true,
+	"contact_email":         true,
+	"contact_person":        true,
+	"legal_form":            true,
+	"bank_name":             true,
+	"bank_account":          true,
+	"correspondent_account": true,
+	"bik":                   true,
+}
+
+// UpdateBenchmarkFieldsSelective обновляет только переданные поля эталона (ключи - имена колонок).
+// В отличие от UpdateBenchmark статус утверждения не меняется, если не передан is_approved:
+// true утверждает эталон (review_status = approved), false возвращает утвержденный эталон на проверку.
+// Неизвестные поля и значения неверного типа отклоняются с ErrInvalidBenchmarkField.
+func (db *ServiceDB) UpdateBenchmarkFieldsSelective(id int, updates map[string]any) error {
+	if len(updates) == 0 {
+		return fmt.Errorf("no fields to update")
+	}
+
+	// Порядок колонок фиксирован, чтобы запрос не зависел от обхода map
+	fields := make([]string, 0, len(updates))
+	for field := range updates {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	setParts := []string{}
+	args := []interface{}{}
+	newNormalizedName, renaming := "", false
+
+	for _, field := range fields {
+		value := updates[field]
+		switch {
+		case benchmarkSelectiveTextFields[field]:
+			text, ok := value.(string)
+			if !ok {
+				return fmt.Errorf("%w: %s must be a string", ErrInvalidBenchmarkField, field)
+			}
+			if field == "original_name" && strings.TrimSpace(text) == "" {
+				return ErrInvalidName
+			}
+			if field == "normalized_name" {
+				newNormalizedName, renaming = text, true
+			}
+			setParts = append(setParts, field+" = ?")
+			args = append(args, text)
+
+		case field == "quality_score":
+			var score float64
+			switch v := value.(type) {
+			case float64:
+				score = v
+			case int:
+				score = float64(v)
+			default:
+				return fmt.Errorf("%w: quality_score must be a number", ErrInvalidBenchmarkField)
+			}
+			setParts = append(setParts, "quality_score = ?")
+			args = append(args, score)
+
+		case field == "is_approved":
+			approved, ok := value.(bool)
+			if !ok {
+				return fmt.Errorf("%w: is_approved must be a boolean", ErrInvalidBenchmarkField)
+			}
+			if approved {
+				setParts = append(setParts,
+					"is_approved = TRUE",
+					"review_status = 'approved'",
+					"approved_by = CASE WHEN is_approved THEN approved_by ELSE 'system' END",
+					"approved_at = CASE WHEN is_approved THEN approved_at ELSE CURRENT_TIMESTAMP END",
+				)
+			} else {
+				setParts = append(setParts,
+					"is_approved = FALSE",
+					"review_status = CASE WHEN review_status = 'approved' THEN 'pending' ELSE review_status END",
+					"approved_by = NULL",
+					"approved_at = NULL",
+				)
+			}
+
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidBenchmarkField, field)
+		}
+	}
+
+	setParts = append(setParts, "updated_at = CURRENT_TIMESTAMP")
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldNormalizedName string
+	err = tx.QueryRow(`SELECT normalized_name FROM client_benchmarks WHERE id = ?`, id).Scan(&oldNormalizedName)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("benchmark %d not found", id)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get current benchmark name: %w", err)
+	}
+
+	query := fmt.Sprintf(`UPDATE client_benchmarks SET %s WHERE id = ?`, strings.Join(setParts, ", "))
+	if _, err := tx.Exec(query, append(args, id)...); err != nil {
+		return fmt.Errorf("failed to update benchmark fields: %w", err)
+	}
+
+	if renaming && oldNormalizedName != newNormalizedName {
+		if err := recordBenchmarkNameChange(tx, id, oldNormalizedName, newNormalizedName, "system"); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit benchmark update: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestUpdateBenchmarkFieldsSelective_PreservesOtherFields(t *testing.T) {
+	db, benchmarkID := newReviewTestDB(t)
+
+	if err := db.SetBenchmarkReviewStatus(benchmarkID, BenchmarkReviewApproved, "reviewer", ""); err != nil {
+		t.Fatalf("SetBenchmarkReviewStatus failed: %v", err)
+	}
+	before, err := db.GetClientBenchmark(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetClientBenchmark failed: %v", err)
+	}
+
+	if err := db.UpdateBenchmarkFieldsSelective(benchmarkID, map[string]any{"region": "Москва"}); err != nil {
+		t.Fatalf("UpdateBenchmarkFieldsSelective failed: %v", err)
+	}
+
+	after, err := db.GetClientBenchmark(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetClientBenchmark failed: %v", err)
+	}
+	if after.Region != "Москва" {
+		t.Errorf("Expected region to be updated, got %q", after.Region)
+	}
+	if after.OriginalName != before.OriginalName || after.NormalizedName != before.NormalizedName || after.QualityScore != before.QualityScore {
+		t.Errorf("Untouched fields changed: before %+v, after %+v", before, after)
+	}
+	if !after.IsApproved || after.ReviewStatus != BenchmarkReviewApproved || after.ApprovedBy != "reviewer" {
+		t.Errorf("Approval must be preserved, got is_approved=%v status=%q by=%q", after.IsApproved, after.ReviewStatus, after.ApprovedBy)
+	}
+
+	history, err := db.GetBenchmarkNameHistory(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetBenchmarkNameHistory failed: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("Region update must not record name history, got %d entries", len(history))
+	}
+}
+
+func TestUpdateBenchmarkFieldsSelective_DraftStaysUnapproved(t *testing.T) {
+	db, benchmarkID := newReviewTestDB(t)
+
+	err := db.UpdateBenchmarkFieldsSelective(benchmarkID, map[string]any{
+		"normalized_name": "Ромашка Плюс",
+		"quality_score":   0.75,
+	})
+	if err != nil {
+		t.Fatalf("UpdateBenchmarkFieldsSelective failed: %v", err)
+	}
+
+	benchmark, err := db.GetClientBenchmark(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetClientBenchmark failed: %v", err)
+	}
+	if benchmark.NormalizedName != "Ромашка Плюс" || benchmark.QualityScore != 0.75 || benchmark.OriginalName != "ООО Ромашка" {
+		t.Errorf("Unexpected benchmark after update: %+v", benchmark)
+	}
+	if benchmark.IsApproved || benchmark.ReviewStatus != BenchmarkReviewDraft {
+		t.Errorf("Draft benchmark must stay unapproved, got is_approved=%v status=%q", benchmark.IsApproved, benchmark.ReviewStatus)
+	}
+
+	history, err := db.GetBenchmarkNameHistory(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetBenchmarkNameHistory failed: %v", err)
+	}
+	if len(history) != 1 || history[0].NewNormalizedName != "Ромашка Плюс" {
+		t.Errorf("Expected name change in history, got %+v", history)
+	}
+
+	// Утверждение только по явному запросу
+	if err := db.UpdateBenchmarkFieldsSelective(benchmarkID, map[string]any{"is_approved": true}); err != nil {
+		t.Fatalf("UpdateBenchmarkFieldsSelective failed: %v", err)
+	}
+	if benchmark, _ = db.GetClientBenchmark(benchmarkID); !benchmark.IsApproved || benchmark.ReviewStatus != BenchmarkReviewApproved {
+		t.Errorf("Expected approved benchmark, got is_approved=%v status=%q", benchmark.IsApproved, benchmark.ReviewStatus)
+	}
+}
+
+func TestUpdateBenchmarkFieldsSelective_Validation(t *testing.T) {
+	db, benchmarkID := newReviewTestDB(t)
+
+	tests := []struct {
+		name    string
+		updates map[string]any
+		wantErr error
+	}{
+		{"unknown field", map[string]any{"client_project_id": 5}, ErrInvalidBenchmarkField},
+		{"approval via review_status", map[string]any{"review_status": "approved"}, ErrInvalidBenchmarkField},
+		{"wrong type", map[string]any{"region": 77}, ErrInvalidBenchmarkField},
+		{"wrong score type", map[string]any{"quality_score": "high"}, ErrInvalidBenchmarkField},
+		{"empty original name", map[string]any{"original_name": " "}, ErrInvalidName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := db.UpdateBenchmarkFieldsSelective(benchmarkID, tt.updates); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// Ошибка валидации не применяет и допустимые поля из того же запроса
+	err := db.UpdateBenchmarkFieldsSelective(benchmarkID, map[string]any{"region": "Тверь", "unknown": "x"})
+	if !errors.Is(err, ErrInvalidBenchmarkField) {
+		t.Fatalf("Expected ErrInvalidBenchmarkField, got %v", err)
+	}
+	if benchmark, _ := db.GetClientBenchmark(benchmarkID); benchmark.Region == "Тверь" {
+		t.Error("Region must not change when request is rejected")
+	}
+
+	if err := db.UpdateBenchmarkFieldsSelective(benchmarkID+100, map[string]any{"region": "Тверь"}); err == nil {
+		t.Error("Expected error for missing benchmark")
+	}
+}
@@ -0,0 +1,213 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxBenchmarkTagLength максимальная длина тега эталона в символах
+const maxBenchmarkTagLength = 64
+
+// ErrInvalidBenchmarkTag возвращается для пустого или слишком длинного тега
+var ErrInvalidBenchmarkTag = errors.New("invalid benchmark tag")
+
+// MigrateBenchmarkTags создает таблицу пользовательских тегов эталонов
+func MigrateBenchmarkTags(db *sql.DB) error {
+	migrations := []string{
+		`CREATE TABLE IF NOT EXISTS benchmark_tags (
+			benchmark_id INTEGER NOT NULL,
+			tag TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (benchmark_id, tag),
+			FOREIGN KEY (benchmark_id) REFERENCES client_benchmarks(id) ON DELETE CASCADE
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_benchmark_tags_tag ON benchmark_tags(tag)`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := db.Exec(migration); err != nil {
+			return fmt.Errorf("migration failed: %s, error: %w", migration, err)
+		}
+	}
+
+	return nil
+}
+
+// NormalizeBenchmarkTag приводит тег к виду, в котором он хранится: без пробелов по краям,
+// в нижнем регистре. Пустые теги и теги длиннее maxBenchmarkTagLength символов недопустимы.
+func NormalizeBenchmarkTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return "", fmt.Errorf("%w: empty tag", ErrInvalidBenchmarkTag)
+	}
+	if utf8.RuneCountInString(tag) > maxBenchmarkTagLength {
+		return "", fmt.Errorf("%w: tag is longer than %d characters", ErrInvalidBenchmarkTag, maxBenchmarkTagLength)
+	}
+	return tag, nil
+}
+
+// AddBenchmarkTag добавляет тег эталону. Повторное добавление того же тега не является ошибкой.
+func (db *ServiceDB) AddBenchmarkTag(benchmarkID int, tag string) error {
+	tag, err := NormalizeBenchmarkTag(tag)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(`INSERT OR IGNORE INTO benchmark_tags (benchmark_id, tag) VALUES (?, ?)`, benchmarkID, tag)
+	if err != nil {
+		return fmt.Errorf("failed to add benchmark tag: %w", err)
+	}
+	return nil
+}
+
+// RemoveBenchmarkTag удаляет тег эталона. Удаление отсутствующего тега не является ошибкой.
+func (db *ServiceDB) RemoveBenchmarkTag(benchmarkID int, tag string) error {
+	tag, err := NormalizeBenchmarkTag(tag)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.Exec(`DELETE FROM benchmark_tags WHERE benchmark_id = ? AND tag = ?`, benchmarkID, tag)
+	if err != nil {
+		return fmt.Errorf("failed to remove benchmark tag: %w", err)
+	}
+	return nil
+}
+
+// GetBenchmarkTags возвращает теги эталона в алфавитном порядке
+func (db *ServiceDB) GetBenchmarkTags(benchmarkID int) ([]string, error) {
+	rows, err := db.conn.Query(`SELECT tag FROM benchmark_tags WHERE benchmark_id = ? ORDER BY tag`, benchmarkID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get benchmark tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to scan benchmark tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate benchmark tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// GetBenchmarkTagsBatch возвращает теги нескольких эталонов (ID эталона -> теги по алфавиту).
+// Эталоны без тегов в результат не попадают.
+func (db *ServiceDB) GetBenchmarkTagsBatch(benchmarkIDs []int) (map[int][]string, error) {
+	result := make(map[int][]string)
+	if len(benchmarkIDs) == 0 {
+		return result, nil
+	}
+
+	placeholders := make([]string, len(benchmarkIDs))
+	args := make([]interface{}, len(benchmarkIDs))
+	for i, id := range benchmarkIDs {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT benchmark_id, tag FROM benchmark_tags
+		WHERE benchmark_id IN (`+strings.Join(placeholders, ", ")+`)
+		ORDER BY benchmark_id, tag
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get benchmark tags: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var tag string
+		if err := rows.Scan(&id, &tag); err != nil {
+			return nil, fmt.Errorf("failed to scan benchmark tag: %w", err)
+		}
+		result[id] = append(result[id], tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate benchmark tags: %w", err)
+	}
+
+	return result, nil
+}
+
+// BenchmarkSearchFilter условия выборки эталонов проекта для SearchBenchmarks
+type BenchmarkSearchFilter struct {
+	Category       string
+	ApprovedOnly   bool
+	ReviewStatuses []string // Статусы согласования (BenchmarkReviewDraft и т.д.)
+	Tags           []string // Эталон должен иметь все перечисленные теги
+}
+
+// SearchBenchmarks возвращает эталоны проекта, подходящие под filter, начиная с самых новых
+func (db *ServiceDB) SearchBenchmarks(projectID int, filter BenchmarkSearchFilter) ([]*ClientBenchmark, error) {
+	query := `SELECT ` + clientBenchmarkListColumns + `
+		FROM client_benchmarks
+		WHERE client_project_id = ?
+	`
+	args := []interface{}{projectID}
+
+	if filter.Category != "" {
+		query += " AND category = ?"
+		args = append(args, filter.Category)
+	}
+
+	if filter.ApprovedOnly {
+		query += " AND is_approved = TRUE"
+	}
+
+	if len(filter.ReviewStatuses) > 0 {
+		placeholders := make([]string, len(filter.ReviewStatuses))
+		for i, status := range filter.ReviewStatuses {
+			if !IsValidBenchmarkReviewStatus(status) {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidReviewStatus, status)
+			}
+			placeholders[i] = "?"
+			args = append(args, status)
+		}
+		query += " AND review_status IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+
+	if len(filter.Tags) > 0 {
+		tags := make(map[string]bool, len(filter.Tags))
+		var placeholders []string
+		for _, tag := range filter.Tags {
+			tag, err := NormalizeBenchmarkTag(tag)
+			if err != nil {
+				return nil, err
+			}
+			if tags[tag] {
+				continue
+			}
+			tags[tag] = true
+			placeholders = append(placeholders, "?")
+			args = append(args, tag)
+		}
+		query += ` AND id IN (
+			SELECT benchmark_id FROM benchmark_tags
+			WHERE tag IN (` + strings.Join(placeholders, ", ") + `)
+			GROUP BY benchmark_id
+			HAVING COUNT(*) = ?
+		)`
+		args = append(args, len(tags))
+	}
+
+	query += " ORDER BY created_at DESC"
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get benchmarks: %w", err)
+	}
+	defer rows.Close()
+
+	return scanClientBenchmarks(rows)
+}
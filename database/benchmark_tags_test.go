@@ -0,0 +1,99 @@
+package database
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestServiceDB_BenchmarkTags(t *testing.T) {
+	db := newTestServiceDB(t)
+
+	client, err := db.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Проект", "counterparty", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+
+	var ids []int
+	for _, name := range []string{"ООО Альфа", "ООО Бета", "ООО Гамма"} {
+		benchmark, err := db.CreateClientBenchmark(project.ID, name, name, "counterparty", "", "", "", 0.9)
+		if err != nil {
+			t.Fatalf("CreateClientBenchmark failed: %v", err)
+		}
+		ids = append(ids, benchmark.ID)
+	}
+	alpha, beta, gamma := ids[0], ids[1], ids[2]
+
+	for _, tagging := range []struct {
+		id  int
+		tag string
+	}{
+		{alpha, "key-supplier"},
+		{alpha, " Needs-Review "},
+		{alpha, "needs-review"}, // повтор не создает дубликат
+		{beta, "key-supplier"},
+		{gamma, "needs-review"},
+	} {
+		if err := db.AddBenchmarkTag(tagging.id, tagging.tag); err != nil {
+			t.Fatalf("AddBenchmarkTag(%d, %q) failed: %v", tagging.id, tagging.tag, err)
+		}
+	}
+
+	tags, err := db.GetBenchmarkTags(alpha)
+	if err != nil {
+		t.Fatalf("GetBenchmarkTags failed: %v", err)
+	}
+	if want := []string{"key-supplier", "needs-review"}; !reflect.DeepEqual(tags, want) {
+		t.Errorf("GetBenchmarkTags = %v, want %v", tags, want)
+	}
+
+	if err := db.AddBenchmarkTag(alpha, "   "); !errors.Is(err, ErrInvalidBenchmarkTag) {
+		t.Errorf("Expected ErrInvalidBenchmarkTag for empty tag, got %v", err)
+	}
+
+	search := func(tags ...string) []int {
+		t.Helper()
+		benchmarks, err := db.SearchBenchmarks(project.ID, BenchmarkSearchFilter{Tags: tags})
+		if err != nil {
+			t.Fatalf("SearchBenchmarks(%v) failed: %v", tags, err)
+		}
+		found := []int{}
+		for _, benchmark := range benchmarks {
+			found = append(found, benchmark.ID)
+		}
+		return found
+	}
+
+	if got := search("key-supplier"); len(got) != 2 {
+		t.Errorf("Expected 2 key suppliers, got %v", got)
+	}
+	if got := search("key-supplier", "NEEDS-REVIEW"); !reflect.DeepEqual(got, []int{alpha}) {
+		t.Errorf("Expected only %d to have both tags, got %v", alpha, got)
+	}
+	if got := search("key-supplier", "key-supplier"); len(got) != 2 {
+		t.Errorf("Expected duplicate filter tags to be ignored, got %v", got)
+	}
+
+	if err := db.RemoveBenchmarkTag(alpha, "needs-review"); err != nil {
+		t.Fatalf("RemoveBenchmarkTag failed: %v", err)
+	}
+	if got := search("key-supplier", "needs-review"); len(got) != 0 {
+		t.Errorf("Expected no benchmarks with both tags after untagging, got %v", got)
+	}
+	if got := search("needs-review"); !reflect.DeepEqual(got, []int{gamma}) {
+		t.Errorf("Expected only %d to need review, got %v", gamma, got)
+	}
+
+	batch, err := db.GetBenchmarkTagsBatch([]int{alpha, beta, gamma})
+	if err != nil {
+		t.Fatalf("GetBenchmarkTagsBatch failed: %v", err)
+	}
+	want := map[int][]string{alpha: {"key-supplier"}, beta: {"key-supplier"}, gamma: {"needs-review"}}
+	if !reflect.DeepEqual(batch, want) {
+		t.Errorf("GetBenchmarkTagsBatch = %v, want %v", batch, want)
+	}
+}
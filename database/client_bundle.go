@@ -0,0 +1,420 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ClientBundleVersion версия формата выгрузки клиента
+const ClientBundleVersion = 1
+
+// systemClientName имя системного клиента глобальных эталонов (см. GetOrCreateSystemProject)
+const systemClientName = "Система"
+
+// ErrSystemClient возвращается при попытке выгрузить или загрузить системного клиента
+var ErrSystemClient = errors.New("system client cannot be exported or imported")
+
+// ClientBundle выгрузка клиента со всеми проектами, базами данных, эталонами (с тегами
+// и историей названий), конфигурациями нормализации проектов и нормализованными контрагентами.
+// Строки хранятся как наборы колонок, поэтому выгрузка переносится между окружениями
+// с разным набором миграций: при загрузке отсутствующие в схеме колонки пропускаются.
+type ClientBundle struct {
+	Version    int                      `json:"version"`
+	ExportedAt time.Time                `json:"exported_at"`
+	Client     map[string]interface{}   `json:"client"`
+	Projects   []map[string]interface{} `json:"projects"`
+	Databases  []map[string]interface{} `json:"databases"`
+	Benchmarks []map[string]interface{} `json:"benchmarks"`
+
+	BenchmarkTags               []map[string]interface{} `json:"benchmark_tags"`
+	BenchmarkNameHistory        []map[string]interface{} `json:"benchmark_name_history"`
+	NormalizationConfigs        []map[string]interface{} `json:"normalization_configs"`         // Собственные normalization_config проектов
+	ProjectNormalizationConfigs []map[string]interface{} `json:"project_normalization_configs"` // project_normalization_config
+	NormalizedCounterparties    []map[string]interface{} `json:"normalized_counterparties"`
+}
+
+// ClientImportOptions настройки загрузки выгрузки клиента
+type ClientImportOptions struct {
+	// SkipBenchmarks не загружает эталоны с тегами и историей названий;
+	// ссылки нормализованных контрагентов на эталоны сбрасываются
+	SkipBenchmarks bool
+}
+
+// ExportClient записывает в w JSON выгрузку клиента (см. ClientBundle)
+func (db *ServiceDB) ExportClient(clientID int, w io.Writer) error {
+	clients, err := db.queryRowMaps(`SELECT * FROM clients WHERE id = ?`, clientID)
+	if err != nil {
+		return err
+	}
+	if len(clients) == 0 {
+		return fmt.Errorf("client %d not found: %w", clientID, sql.ErrNoRows)
+	}
+	if clients[0]["name"] == systemClientName {
+		return ErrSystemClient
+	}
+
+	bundle := ClientBundle{
+		Version:    ClientBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Client:     clients[0],
+	}
+
+	bundle.Projects, err = db.queryRowMaps(`SELECT * FROM client_projects WHERE client_id = ? ORDER BY id`, clientID)
+	if err != nil {
+		return err
+	}
+	bundle.Databases, err = db.queryRowMaps(`
+		SELECT pd.* FROM project_databases pd
+		JOIN client_projects cp ON cp.id = pd.client_project_id
+		WHERE cp.client_id = ?
+		ORDER BY pd.id
+	`, clientID)
+	if err != nil {
+		return err
+	}
+	bundle.Benchmarks, err = db.queryRowMaps(`
+		SELECT cb.* FROM client_benchmarks cb
+		JOIN client_projects cp ON cp.id = cb.client_project_id
+		WHERE cp.client_id = ?
+		ORDER BY cb.id
+	`, clientID)
+	if err != nil {
+		return err
+	}
+	bundle.BenchmarkTags, err = db.queryRowMaps(`
+		SELECT bt.* FROM benchmark_tags bt
+		JOIN client_benchmarks cb ON cb.id = bt.benchmark_id
+		JOIN client_projects cp ON cp.id = cb.client_project_id
+		WHERE cp.client_id = ?
+		ORDER BY bt.benchmark_id, bt.tag
+	`, clientID)
+	if err != nil {
+		return err
+	}
+	bundle.BenchmarkNameHistory, err = db.queryRowMaps(`
+		SELECT h.* FROM benchmark_name_history h
+		JOIN client_benchmarks cb ON cb.id = h.benchmark_id
+		JOIN client_projects cp ON cp.id = cb.client_project_id
+		WHERE cp.client_id = ?
+		ORDER BY h.id
+	`, clientID)
+	if err != nil {
+		return err
+	}
+	bundle.NormalizationConfigs, err = db.queryRowMaps(`
+		SELECT nc.* FROM normalization_config nc
+		JOIN client_projects cp ON cp.id = nc.client_project_id
+		WHERE cp.client_id = ?
+		ORDER BY nc.id
+	`, clientID)
+	if err != nil {
+		return err
+	}
+	bundle.ProjectNormalizationConfigs, err = db.queryRowMaps(`
+		SELECT pnc.* FROM project_normalization_config pnc
+		JOIN client_projects cp ON cp.id = pnc.client_project_id
+		WHERE cp.client_id = ?
+		ORDER BY pnc.id
+	`, clientID)
+	if err != nil {
+		return err
+	}
+	bundle.NormalizedCounterparties, err = db.queryRowMaps(`
+		SELECT nc.* FROM normalized_counterparties nc
+		JOIN client_projects cp ON cp.id = nc.client_project_id
+		WHERE cp.client_id = ?
+		ORDER BY nc.id
+	`, clientID)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(bundle); err != nil {
+		return fmt.Errorf("failed to write client bundle: %w", err)
+	}
+	return nil
+}
+
+// ImportClient создает клиента из выгрузки ExportClient вместе с эталонами
+func (db *ServiceDB) ImportClient(r io.Reader) (*Client, error) {
+	return db.ImportClientWithOptions(r, ClientImportOptions{})
+}
+
+// ImportClientWithOptions создает клиента из выгрузки ExportClient в одной транзакции.
+// Все ID назначаются заново, ссылки между строками выгрузки переносятся на новые ID.
+// Ссылки на справочники (ОКПД2, ТН ВЭД, ТУ/ГОСТ) и на эталоны вне выгрузки сбрасываются:
+// их ID в другом окружении не совпадают. Если клиент с таким именем или база данных с тем же
+// файлом уже есть, возвращается ErrDuplicate и ничего не создается.
+func (db *ServiceDB) ImportClientWithOptions(r io.Reader, opts ClientImportOptions) (*Client, error) {
+	var bundle ClientBundle
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("failed to read client bundle: %w", err)
+	}
+	if bundle.Version != ClientBundleVersion {
+		return nil, fmt.Errorf("unsupported client bundle version %d", bundle.Version)
+	}
+	if bundle.Client == nil {
+		return nil, fmt.Errorf("client bundle has no client")
+	}
+	if bundle.Client["name"] == systemClientName {
+		return nil, ErrSystemClient
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Кэшированная статистика пересчитывается после загрузки
+	clientID, err := insertRowMap(tx, "clients", bundle.Client, map[string]interface{}{
+		"project_count":   0,
+		"benchmark_count": 0,
+		"last_activity":   nil,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	projectIDs := make(map[int64]int64, len(bundle.Projects))
+	for _, project := range bundle.Projects {
+		newID, err := insertRowMap(tx, "client_projects", project, map[string]interface{}{"client_id": clientID})
+		if err != nil {
+			return nil, err
+		}
+		projectIDs[rowMapID(project, "id")] = newID
+	}
+
+	for _, configs := range []struct {
+		table string
+		rows  []map[string]interface{}
+	}{
+		{"normalization_config", bundle.NormalizationConfigs},
+		{"project_normalization_config", bundle.ProjectNormalizationConfigs},
+	} {
+		for _, config := range configs.rows {
+			projectID, ok := projectIDs[rowMapID(config, "client_project_id")]
+			if !ok {
+				return nil, fmt.Errorf("%s row references project outside of bundle", configs.table)
+			}
+			if _, err := insertRowMap(tx, configs.table, config, map[string]interface{}{"client_project_id": projectID}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, projectDB := range bundle.Databases {
+		projectID, ok := projectIDs[rowMapID(projectDB, "client_project_id")]
+		if !ok {
+			return nil, fmt.Errorf("database %v references project outside of bundle", projectDB["name"])
+		}
+		if _, err := insertRowMap(tx, "project_databases", projectDB, map[string]interface{}{"client_project_id": projectID}); err != nil {
+			return nil, err
+		}
+	}
+
+	benchmarkIDs := make(map[int64]int64, len(bundle.Benchmarks))
+	if !opts.SkipBenchmarks {
+		var manufacturerLinks [][2]int64
+		for _, benchmark := range bundle.Benchmarks {
+			projectID, ok := projectIDs[rowMapID(benchmark, "client_project_id")]
+			if !ok {
+				return nil, fmt.Errorf("benchmark %v references project outside of bundle", benchmark["original_name"])
+			}
+			newID, err := insertRowMap(tx, "client_benchmarks", benchmark, map[string]interface{}{
+				"client_project_id":         projectID,
+				"manufacturer_benchmark_id": nil,
+				"okpd2_reference_id":        nil,
+				"tnved_reference_id":        nil,
+				"tu_gost_reference_id":      nil,
+			})
+			if err != nil {
+				return nil, err
+			}
+			benchmarkIDs[rowMapID(benchmark, "id")] = newID
+			if manufacturerID := rowMapID(benchmark, "manufacturer_benchmark_id"); manufacturerID != 0 {
+				manufacturerLinks = append(manufacturerLinks, [2]int64{newID, manufacturerID})
+			}
+		}
+
+		// Производитель может идти в выгрузке позже номенклатуры, поэтому ссылки восстанавливаются вторым проходом
+		for _, link := range manufacturerLinks {
+			manufacturerID, ok := benchmarkIDs[link[1]]
+			if !ok {
+				continue
+			}
+			if _, err := tx.Exec(`UPDATE client_benchmarks SET manufacturer_benchmark_id = ? WHERE id = ?`, manufacturerID, link[0]); err != nil {
+				return nil, fmt.Errorf("failed to restore manufacturer link: %w", err)
+			}
+		}
+
+		for _, tag := range bundle.BenchmarkTags {
+			benchmarkID, ok := benchmarkIDs[rowMapID(tag, "benchmark_id")]
+			if !ok {
+				return nil, fmt.Errorf("benchmark tag %v references benchmark outside of bundle", tag["tag"])
+			}
+			if _, err := insertRowMap(tx, "benchmark_tags", tag, map[string]interface{}{"benchmark_id": benchmarkID}); err != nil {
+				return nil, err
+			}
+		}
+		for _, entry := range bundle.BenchmarkNameHistory {
+			benchmarkID, ok := benchmarkIDs[rowMapID(entry, "benchmark_id")]
+			if !ok {
+				return nil, fmt.Errorf("benchmark name history entry references benchmark outside of bundle")
+			}
+			if _, err := insertRowMap(tx, "benchmark_name_history", entry, map[string]interface{}{"benchmark_id": benchmarkID}); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, counterparty := range bundle.NormalizedCounterparties {
+		projectID, ok := projectIDs[rowMapID(counterparty, "client_project_id")]
+		if !ok {
+			return nil, fmt.Errorf("counterparty %v references project outside of bundle", counterparty["normalized_name"])
+		}
+		// Без загруженного эталона ссылка сбрасывается
+		var benchmarkID interface{}
+		if newID, ok := benchmarkIDs[rowMapID(counterparty, "benchmark_id")]; ok {
+			benchmarkID = newID
+		}
+		if _, err := insertRowMap(tx, "normalized_counterparties", counterparty, map[string]interface{}{
+			"client_project_id": projectID,
+			"benchmark_id":      benchmarkID,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.Exec(`UPDATE clients SET`+clientStatsSetClause+` WHERE id = ?`, clientID); err != nil {
+		return nil, fmt.Errorf("failed to recompute client stats: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit client import: %w", err)
+	}
+
+	return db.GetClient(int(clientID))
+}
+
+// queryRowMaps читает строки запроса как наборы колонок для выгрузки
+func (db *ServiceDB) queryRowMaps(query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rows for export: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get columns: %w", err)
+	}
+
+	result := []map[string]interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("failed to scan row for export: %w", err)
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			switch v := values[i].(type) {
+			case time.Time:
+				// Формат CURRENT_TIMESTAMP, чтобы сравнение дат строками (MAX, >) не ломалось
+				row[column] = v.UTC().Format("2006-01-02 15:04:05")
+			case []byte:
+				row[column] = string(v)
+			default:
+				row[column] = v
+			}
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate rows for export: %w", err)
+	}
+	return result, nil
+}
+
+// insertRowMap вставляет строку выгрузки в таблицу и возвращает новый ID.
+// Колонка id не переносится, колонки из overrides заменяются, отсутствующие в схеме пропускаются.
+func insertRowMap(tx *sql.Tx, table string, row map[string]interface{}, overrides map[string]interface{}) (int64, error) {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s columns: %w", table, err)
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan %s column: %w", table, err)
+		}
+		existing[name] = true
+	}
+	rows.Close()
+
+	var columns []string
+	for column := range row {
+		if column != "id" && existing[column] {
+			columns = append(columns, column)
+		}
+	}
+	for column := range overrides {
+		if _, ok := row[column]; !ok && existing[column] {
+			columns = append(columns, column)
+		}
+	}
+	sort.Strings(columns)
+
+	args := make([]interface{}, 0, len(columns))
+	for _, column := range columns {
+		if value, ok := overrides[column]; ok {
+			args = append(args, value)
+		} else {
+			args = append(args, row[column])
+		}
+	}
+
+	query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES (%s)`,
+		table, strings.Join(columns, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", "))
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		// Клиент с таким именем или база данных с тем же файлом уже есть в этом окружении
+		if isUniqueConstraintError(err) {
+			return 0, fmt.Errorf("%w: %s row conflicts with existing data: %v", ErrDuplicate, table, err)
+		}
+		return 0, fmt.Errorf("failed to import %s row: %w", table, err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get imported %s ID: %w", table, err)
+	}
+	return id, nil
+}
+
+// rowMapID возвращает числовое значение колонки строки выгрузки (JSON числа читаются как float64)
+func rowMapID(row map[string]interface{}, column string) int64 {
+	switch v := row[column].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,279 @@
+package database
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestExportImportClient_RoundTrip(t *testing.T) {
+	source := newTestServiceDB(t)
+
+	client, err := source.CreateClient("Клиент", "ООО «Клиент»", "Описание", "info@example.com", "+7 900", "7701234567", "RU", "admin")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	projectA, err := source.CreateClientProject(client.ID, "Номенклатура", "nomenclature", "", "1C", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	projectB, err := source.CreateClientProject(client.ID, "Контрагенты", "counterparty", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	if _, err := source.CreateProjectDatabase(projectA.ID, "Выгрузка", "/data/export.db", "", 1024); err != nil {
+		t.Fatalf("CreateProjectDatabase failed: %v", err)
+	}
+
+	manufacturer, err := source.CreateClientBenchmark(projectB.ID, "ООО Завод", "ООО Завод", "manufacturer", "", "", "test", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientBenchmark failed: %v", err)
+	}
+	if _, err := source.CreateNomenclatureBenchmark(projectA.ID, "Болт М8", "болт м8", "", "", "test", 0.95, &manufacturer.ID, nil, nil, nil); err != nil {
+		t.Fatalf("CreateNomenclatureBenchmark failed: %v", err)
+	}
+
+	var bundle bytes.Buffer
+	if err := source.ExportClient(client.ID, &bundle); err != nil {
+		t.Fatalf("ExportClient failed: %v", err)
+	}
+
+	target := newTestServiceDB(t)
+	// Сдвигаем ID в целевой БД, чтобы проверить переназначение
+	if _, err := target.CreateClient("Другой", "", "", "", "", ""); err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+
+	imported, err := target.ImportClient(bytes.NewReader(bundle.Bytes()))
+	if err != nil {
+		t.Fatalf("ImportClient failed: %v", err)
+	}
+	if imported.ID == client.ID {
+		t.Errorf("Expected new client ID, got the original %d", imported.ID)
+	}
+	if imported.Name != "Клиент" || imported.TaxID != "7701234567" || imported.Country != "RU" || imported.CreatedBy != "admin" {
+		t.Errorf("Unexpected imported client: %+v", imported)
+	}
+
+	projects, err := target.GetClientProjects(imported.ID)
+	if err != nil {
+		t.Fatalf("GetClientProjects failed: %v", err)
+	}
+	if len(projects) != 2 {
+		t.Fatalf("Expected 2 projects, got %d", len(projects))
+	}
+	byName := make(map[string]*ClientProject)
+	for _, project := range projects {
+		byName[project.Name] = project
+	}
+	nomenclatureProject := byName["Номенклатура"]
+	if nomenclatureProject == nil || nomenclatureProject.SourceSystem != "1C" || nomenclatureProject.TargetQualityScore != 0.9 {
+		t.Fatalf("Unexpected nomenclature project: %+v", nomenclatureProject)
+	}
+
+	databases, err := target.GetProjectDatabases(nomenclatureProject.ID, false)
+	if err != nil {
+		t.Fatalf("GetProjectDatabases failed: %v", err)
+	}
+	if len(databases) != 1 || databases[0].FilePath != "/data/export.db" {
+		t.Errorf("Expected imported project database, got %+v", databases)
+	}
+
+	benchmarks, err := target.GetClientBenchmarks(nomenclatureProject.ID, "", false)
+	if err != nil {
+		t.Fatalf("GetClientBenchmarks failed: %v", err)
+	}
+	if len(benchmarks) != 1 || benchmarks[0].NormalizedName != "болт м8" {
+		t.Fatalf("Expected imported nomenclature benchmark, got %+v", benchmarks)
+	}
+	manufacturers, err := target.GetClientBenchmarks(byName["Контрагенты"].ID, "manufacturer", false)
+	if err != nil || len(manufacturers) != 1 {
+		t.Fatalf("Expected imported manufacturer, got %v (%v)", manufacturers, err)
+	}
+	if benchmarks[0].ManufacturerBenchmarkID == nil || *benchmarks[0].ManufacturerBenchmarkID != manufacturers[0].ID {
+		t.Errorf("Expected manufacturer link remapped to %d, got %v", manufacturers[0].ID, benchmarks[0].ManufacturerBenchmarkID)
+	}
+
+	stats, err := target.GetClientStats(imported.ID)
+	if err != nil {
+		t.Fatalf("GetClientStats failed: %v", err)
+	}
+	if stats.ProjectCount != 2 || stats.BenchmarkCount != 2 {
+		t.Errorf("Expected recomputed stats 2/2, got %+v", stats)
+	}
+
+	// Повторная загрузка в то же окружение конфликтует по имени клиента и откатывается целиком
+	if _, err := target.ImportClient(bytes.NewReader(bundle.Bytes())); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate on repeated import, got %v", err)
+	}
+	var clientCount int
+	if err := target.conn.QueryRow(`SELECT COUNT(*) FROM clients`).Scan(&clientCount); err != nil || clientCount != 2 {
+		t.Errorf("Expected failed import to leave 2 clients, got %d (%v)", clientCount, err)
+	}
+
+	// Без эталонов переносятся только клиент, проекты и базы данных
+	empty := newTestServiceDB(t)
+	withoutBenchmarks, err := empty.ImportClientWithOptions(bytes.NewReader(bundle.Bytes()), ClientImportOptions{SkipBenchmarks: true})
+	if err != nil {
+		t.Fatalf("ImportClientWithOptions failed: %v", err)
+	}
+	if stats, _ := empty.GetClientStats(withoutBenchmarks.ID); stats.ProjectCount != 2 || stats.BenchmarkCount != 0 {
+		t.Errorf("Expected 2 projects without benchmarks, got %+v", stats)
+	}
+}
+
+func TestExportClient_RejectsSystemClient(t *testing.T) {
+	db := newTestServiceDB(t)
+
+	project, err := db.GetOrCreateSystemProject()
+	if err != nil {
+		t.Fatalf("GetOrCreateSystemProject failed: %v", err)
+	}
+
+	var bundle bytes.Buffer
+	if err := db.ExportClient(project.ClientID, &bundle); !errors.Is(err, ErrSystemClient) {
+		t.Errorf("Expected ErrSystemClient on export, got %v", err)
+	}
+
+	forged := `{"version": 1, "client": {"id": 1, "name": "Система", "legal_name": "Система"}, "projects": [], "databases": [], "benchmarks": []}`
+	if _, err := db.ImportClient(bytes.NewBufferString(forged)); !errors.Is(err, ErrSystemClient) {
+		t.Errorf("Expected ErrSystemClient on import, got %v", err)
+	}
+}
+
+func TestExportImportClient_RoundTripProjectData(t *testing.T) {
+	source := newTestServiceDB(t)
+
+	client, err := source.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "", "RU", "admin")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := source.CreateClientProject(client.ID, "Контрагенты", "counterparty", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	benchmark, err := source.CreateClientBenchmark(project.ID, "ООО Завод", "ООО Завод", "counterparty", "", "", "test", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientBenchmark failed: %v", err)
+	}
+	for _, tag := range []string{"поставщик", "проверен"} {
+		if err := source.AddBenchmarkTag(benchmark.ID, tag); err != nil {
+			t.Fatalf("AddBenchmarkTag failed: %v", err)
+		}
+	}
+	if _, err := source.conn.Exec(`
+		INSERT INTO benchmark_name_history (benchmark_id, old_normalized_name, new_normalized_name, changed_by)
+		VALUES (?, 'Завод', 'ООО Завод', 'admin')
+	`, benchmark.ID); err != nil {
+		t.Fatalf("Failed to add name history: %v", err)
+	}
+	if err := source.UpdateNormalizationConfigForProject(project.ID, "/data/counterparties.db", "counterparties", "ref", "code", "name"); err != nil {
+		t.Fatalf("UpdateNormalizationConfigForProject failed: %v", err)
+	}
+	if err := source.UpdateProjectNormalizationConfig(project.ID, &ProjectNormalizationConfig{
+		AutoMapCounterparties: true, MasterSelectionStrategy: "max_quality",
+	}); err != nil {
+		t.Fatalf("UpdateProjectNormalizationConfig failed: %v", err)
+	}
+	if err := source.SaveNormalizedCounterparty(project.ID, "ref-1", "Завод ООО", "ООО Завод",
+		"7701234567", "", "", "", "", "", "", "", "ООО", "", "", "", "",
+		benchmark.ID, 0.9, false, "", "/data/counterparties.db", ""); err != nil {
+		t.Fatalf("SaveNormalizedCounterparty failed: %v", err)
+	}
+
+	var bundle bytes.Buffer
+	if err := source.ExportClient(client.ID, &bundle); err != nil {
+		t.Fatalf("ExportClient failed: %v", err)
+	}
+
+	target := newTestServiceDB(t)
+	// Сдвигаем ID в целевой БД, чтобы проверить переназначение
+	other, err := target.CreateClient("Другой", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	otherProject, err := target.CreateClientProject(other.ID, "Другой проект", "counterparty", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	if _, err := target.CreateClientBenchmark(otherProject.ID, "Другой эталон", "Другой эталон", "counterparty", "", "", "test", 0.9); err != nil {
+		t.Fatalf("CreateClientBenchmark failed: %v", err)
+	}
+
+	imported, err := target.ImportClient(bytes.NewReader(bundle.Bytes()))
+	if err != nil {
+		t.Fatalf("ImportClient failed: %v", err)
+	}
+	projects, err := target.GetClientProjects(imported.ID)
+	if err != nil || len(projects) != 1 {
+		t.Fatalf("Expected 1 imported project, got %v (%v)", projects, err)
+	}
+	importedProject := projects[0]
+	benchmarks, err := target.GetClientBenchmarks(importedProject.ID, "", false)
+	if err != nil || len(benchmarks) != 1 {
+		t.Fatalf("Expected 1 imported benchmark, got %v (%v)", benchmarks, err)
+	}
+	importedBenchmark := benchmarks[0]
+
+	tags, err := target.GetBenchmarkTags(importedBenchmark.ID)
+	if err != nil {
+		t.Fatalf("GetBenchmarkTags failed: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "поставщик" || tags[1] != "проверен" {
+		t.Errorf("Expected benchmark tags to be imported, got %v", tags)
+	}
+
+	history, err := target.GetBenchmarkNameHistory(importedBenchmark.ID)
+	if err != nil {
+		t.Fatalf("GetBenchmarkNameHistory failed: %v", err)
+	}
+	if len(history) != 1 || history[0].OldNormalizedName != "Завод" || history[0].ChangedBy != "admin" {
+		t.Errorf("Expected benchmark name history to be imported, got %+v", history)
+	}
+
+	normalizationConfig, err := target.GetNormalizationConfigForProject(importedProject.ID)
+	if err != nil {
+		t.Fatalf("GetNormalizationConfigForProject failed: %v", err)
+	}
+	if normalizationConfig.ClientProjectID == nil || *normalizationConfig.ClientProjectID != importedProject.ID ||
+		normalizationConfig.DatabasePath != "/data/counterparties.db" || normalizationConfig.SourceTable != "counterparties" {
+		t.Errorf("Expected project normalization config to be imported, got %+v", normalizationConfig)
+	}
+
+	projectConfig, err := target.GetProjectNormalizationConfig(importedProject.ID)
+	if err != nil {
+		t.Fatalf("GetProjectNormalizationConfig failed: %v", err)
+	}
+	if projectConfig.ClientProjectID != importedProject.ID || projectConfig.MasterSelectionStrategy != "max_quality" || projectConfig.AutoMergeDuplicates {
+		t.Errorf("Expected project_normalization_config to be imported, got %+v", projectConfig)
+	}
+
+	counterparties, total, err := target.GetNormalizedCounterparties(importedProject.ID, 0, 10, "", "", "")
+	if err != nil {
+		t.Fatalf("GetNormalizedCounterparties failed: %v", err)
+	}
+	if total != 1 || len(counterparties) != 1 {
+		t.Fatalf("Expected 1 imported counterparty, got %d", total)
+	}
+	if counterparties[0].SourceReference != "ref-1" || counterparties[0].TaxID != "7701234567" {
+		t.Errorf("Unexpected imported counterparty: %+v", counterparties[0])
+	}
+	if counterparties[0].BenchmarkID == nil || *counterparties[0].BenchmarkID != importedBenchmark.ID {
+		t.Errorf("Expected counterparty benchmark remapped to %d, got %v", importedBenchmark.ID, counterparties[0].BenchmarkID)
+	}
+
+	// Без эталонов контрагенты загружаются без ссылки на эталон
+	empty := newTestServiceDB(t)
+	withoutBenchmarks, err := empty.ImportClientWithOptions(bytes.NewReader(bundle.Bytes()), ClientImportOptions{SkipBenchmarks: true})
+	if err != nil {
+		t.Fatalf("ImportClientWithOptions failed: %v", err)
+	}
+	emptyProjects, err := empty.GetClientProjects(withoutBenchmarks.ID)
+	if err != nil || len(emptyProjects) != 1 {
+		t.Fatalf("Expected 1 imported project, got %v (%v)", emptyProjects, err)
+	}
+	counterparties, _, err = empty.GetNormalizedCounterparties(emptyProjects[0].ID, 0, 10, "", "", "")
+	if err != nil || len(counterparties) != 1 || counterparties[0].BenchmarkID != nil {
+		t.Errorf("Expected counterparty without benchmark link, got %+v (%v)", counterparties, err)
+	}
+}
@@ -0,0 +1,323 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ClientStats кэшированная статистика клиента (поля project_count, benchmark_count, last_activity в clients)
+type ClientStats struct {
+	ClientID       int    `json:"client_id"`
+	ProjectCount   int    `json:"project_count"`
+	BenchmarkCount int    `json:"benchmark_count"`
+	LastActivity   string `json:"last_activity"`
+}
+
+// clientStatsSetClause пересчитывает статистику для строки clients (используется в UPDATE clients).
+// last_activity - максимальное updated_at среди проектов и эталонов клиента.
+const clientStatsSetClause = `
+	project_count = (SELECT COUNT(*) FROM client_projects WHERE client_id = clients.id),
+	benchmark_count = (
+		SELECT COUNT(*)
+		FROM client_benchmarks cb
+		JOIN client_projects cp ON cp.id = cb.client_project_id
+		WHERE cp.client_id = clients.id
+	),
+	last_activity = (
+		SELECT MAX(updated_at) FROM (
+			SELECT updated_at FROM client_projects WHERE client_id = clients.id
+			UNION ALL
+			SELECT cb.updated_at
+			FROM client_benchmarks cb
+			JOIN client_projects cp ON cp.id = cb.client_project_id
+			WHERE cp.client_id = clients.id
+		)
+	)`
+
+// clientStatsBatchSize - сколько клиентов (проектов, эталонов) пересчитывается одним запросом
+const clientStatsBatchSize = 500
+
+// clientStatsTriggers - триггеры прежней версии миграции, пересчитывавшие статистику на каждую строку
+var clientStatsTriggers = []string{
+	"client_stats_project_ai",
+	"client_stats_project_au",
+	"client_stats_project_moved",
+	"client_stats_project_ad",
+	"client_stats_benchmark_ai",
+	"client_stats_benchmark_au",
+	"client_stats_benchmark_moved",
+	"client_stats_benchmark_ad",
+}
+
+// MigrateClientStats добавляет в clients кэшированную статистику и удаляет построчные триггеры
+// прежней версии. Статистика пересчитывается пачками: методы ServiceDB, меняющие проекты и эталоны,
+// помечают клиентов, а FlushClientStats пересчитывает помеченных перед чтением статистики.
+// При первом добавлении полей статистика заполняется для всех клиентов.
+func MigrateClientStats(db *sql.DB) error {
+	columns := []string{
+		`ALTER TABLE clients ADD COLUMN project_count INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE clients ADD COLUMN benchmark_count INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE clients ADD COLUMN last_activity TIMESTAMP`,
+	}
+
+	added := false
+	for _, migration := range columns {
+		_, err := db.Exec(migration)
+		if err != nil {
+			errStr := strings.ToLower(err.Error())
+			// Игнорируем ошибки, если поле уже существует
+			if !strings.Contains(errStr, "duplicate column") &&
+				!strings.Contains(errStr, "already exists") {
+				return fmt.Errorf("migration failed: %s, error: %w", migration, err)
+			}
+			continue
+		}
+		added = true
+	}
+
+	for _, trigger := range clientStatsTriggers {
+		if _, err := db.Exec(`DROP TRIGGER IF EXISTS ` + trigger); err != nil {
+			return fmt.Errorf("failed to drop trigger %s: %w", trigger, err)
+		}
+	}
+
+	if added {
+		if _, err := db.Exec(`UPDATE clients SET` + clientStatsSetClause); err != nil {
+			return fmt.Errorf("failed to backfill client stats: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// clientStatsPending клиенты, проекты и эталоны, изменившиеся с последнего пересчета статистики.
+// Проекты и эталоны сводятся к клиентам при пересчете, чтобы не делать лишних запросов на каждую запись.
+type clientStatsPending struct {
+	mu         sync.Mutex
+	clients    map[int]struct{}
+	projects   map[int]struct{}
+	benchmarks map[int]struct{}
+}
+
+func addPendingIDs(set *map[int]struct{}, ids []int) {
+	if *set == nil {
+		*set = make(map[int]struct{}, len(ids))
+	}
+	for _, id := range ids {
+		if id > 0 {
+			(*set)[id] = struct{}{}
+		}
+	}
+}
+
+func pendingIDs(set map[int]struct{}) []int {
+	ids := make([]int, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// markClientStatsDirty помечает клиентов для пересчета статистики
+func (db *ServiceDB) markClientStatsDirty(clientIDs ...int) {
+	db.statsPending.mu.Lock()
+	defer db.statsPending.mu.Unlock()
+	addPendingIDs(&db.statsPending.clients, clientIDs)
+}
+
+// markProjectStatsDirty помечает клиентов проектов для пересчета статистики
+func (db *ServiceDB) markProjectStatsDirty(projectIDs ...int) {
+	db.statsPending.mu.Lock()
+	defer db.statsPending.mu.Unlock()
+	addPendingIDs(&db.statsPending.projects, projectIDs)
+}
+
+// markBenchmarkStatsDirty помечает клиентов эталонов для пересчета статистики (last_activity)
+func (db *ServiceDB) markBenchmarkStatsDirty(benchmarkIDs ...int) {
+	db.statsPending.mu.Lock()
+	defer db.statsPending.mu.Unlock()
+	addPendingIDs(&db.statsPending.benchmarks, benchmarkIDs)
+}
+
+// takeClientStatsPending забирает накопленные отметки
+func (db *ServiceDB) takeClientStatsPending() (clients, projects, benchmarks []int) {
+	db.statsPending.mu.Lock()
+	defer db.statsPending.mu.Unlock()
+	clients = pendingIDs(db.statsPending.clients)
+	projects = pendingIDs(db.statsPending.projects)
+	benchmarks = pendingIDs(db.statsPending.benchmarks)
+	db.statsPending.clients, db.statsPending.projects, db.statsPending.benchmarks = nil, nil, nil
+	return clients, projects, benchmarks
+}
+
+// idPlaceholders возвращает список "?, ?, ..." и аргументы для условия IN
+func idPlaceholders(ids []int) (string, []interface{}) {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "), args
+}
+
+// resolvePendingClientIDs сводит проекты и эталоны к их клиентам пачками по clientStatsBatchSize
+func (db *ServiceDB) resolvePendingClientIDs(query string, ids []int, clients map[int]struct{}) error {
+	for start := 0; start < len(ids); start += clientStatsBatchSize {
+		end := min(start+clientStatsBatchSize, len(ids))
+		placeholders, args := idPlaceholders(ids[start:end])
+
+		rows, err := db.conn.Query(fmt.Sprintf(query, placeholders), args...)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var clientID int
+			if err := rows.Scan(&clientID); err != nil {
+				rows.Close()
+				return err
+			}
+			clients[clientID] = struct{}{}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recomputeClientStatsBatch пересчитывает статистику клиентов пачками по clientStatsBatchSize.
+// Возвращает число обновленных строк clients.
+func (db *ServiceDB) recomputeClientStatsBatch(clientIDs []int) (int64, error) {
+	var affected int64
+	for start := 0; start < len(clientIDs); start += clientStatsBatchSize {
+		end := min(start+clientStatsBatchSize, len(clientIDs))
+		placeholders, args := idPlaceholders(clientIDs[start:end])
+
+		result, err := db.conn.Exec(`UPDATE clients SET`+clientStatsSetClause+` WHERE id IN (`+placeholders+`)`, args...)
+		if err != nil {
+			return affected, fmt.Errorf("failed to recompute client stats: %w", err)
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return affected, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		affected += n
+	}
+	return affected, nil
+}
+
+// FlushClientStats пересчитывает статистику клиентов, помеченных с последнего пересчета.
+// При ошибке отметки сохраняются до следующего вызова.
+func (db *ServiceDB) FlushClientStats() error {
+	clientIDs, projectIDs, benchmarkIDs := db.takeClientStatsPending()
+	if len(clientIDs)+len(projectIDs)+len(benchmarkIDs) == 0 {
+		return nil
+	}
+
+	clients := make(map[int]struct{}, len(clientIDs))
+	for _, id := range clientIDs {
+		clients[id] = struct{}{}
+	}
+
+	restore := func() {
+		db.markClientStatsDirty(pendingIDs(clients)...)
+		db.markProjectStatsDirty(projectIDs...)
+		db.markBenchmarkStatsDirty(benchmarkIDs...)
+	}
+
+	if err := db.resolvePendingClientIDs(`SELECT DISTINCT client_id FROM client_projects WHERE id IN (%s)`, projectIDs, clients); err != nil {
+		restore()
+		return fmt.Errorf("failed to resolve project clients: %w", err)
+	}
+	if err := db.resolvePendingClientIDs(`
+		SELECT DISTINCT cp.client_id
+		FROM client_benchmarks cb
+		JOIN client_projects cp ON cp.id = cb.client_project_id
+		WHERE cb.id IN (%s)`, benchmarkIDs, clients); err != nil {
+		restore()
+		return fmt.Errorf("failed to resolve benchmark clients: %w", err)
+	}
+
+	if _, err := db.recomputeClientStatsBatch(pendingIDs(clients)); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// RecomputeClientStats пересчитывает кэшированную статистику клиента по проектам и эталонам.
+// Возвращает ошибку, оборачивающую sql.ErrNoRows, если клиент не найден.
+func (db *ServiceDB) RecomputeClientStats(clientID int) error {
+	affected, err := db.recomputeClientStatsBatch([]int{clientID})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("client %d not found: %w", clientID, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
+// RecomputeAllClientStats пересчитывает кэшированную статистику всех клиентов пачками по clientStatsBatchSize.
+// Обновляет и last_activity, сдвинутый изменениями, которые не помечают клиентов (обогащение, ревью эталонов).
+func (db *ServiceDB) RecomputeAllClientStats() error {
+	// Отметки, накопленные до полного пересчета, больше не нужны
+	db.takeClientStatsPending()
+
+	lastID := 0
+	for {
+		rows, err := db.conn.Query(`SELECT id FROM clients WHERE id > ? ORDER BY id LIMIT ?`, lastID, clientStatsBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to list clients: %w", err)
+		}
+		var ids []int
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan client id: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("failed to list clients: %w", err)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		if _, err := db.recomputeClientStatsBatch(ids); err != nil {
+			return err
+		}
+		lastID = ids[len(ids)-1]
+	}
+}
+
+// GetClientStats возвращает кэшированную статистику клиента, предварительно пересчитав помеченных клиентов
+func (db *ServiceDB) GetClientStats(clientID int) (*ClientStats, error) {
+	if err := db.FlushClientStats(); err != nil {
+		return nil, err
+	}
+
+	stats := &ClientStats{ClientID: clientID}
+	var lastActivity interface{}
+
+	err := db.conn.QueryRow(`
+		SELECT project_count, benchmark_count, COALESCE(last_activity, updated_at, created_at)
+		FROM clients WHERE id = ?
+	`, clientID).Scan(&stats.ProjectCount, &stats.BenchmarkCount, &lastActivity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client stats: %w", err)
+	}
+	stats.LastActivity = normalizeTimestampValue(lastActivity)
+
+	return stats, nil
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+)
+
+// assertClientStatsFresh проверяет, что кэшированная статистика совпадает с полным пересчетом
+func assertClientStatsFresh(t *testing.T, db *ServiceDB, clientID, wantProjects, wantBenchmarks int) {
+	t.Helper()
+
+	cached, err := db.GetClientStats(clientID)
+	if err != nil {
+		t.Fatalf("GetClientStats failed: %v", err)
+	}
+
+	if err := db.RecomputeClientStats(clientID); err != nil {
+		t.Fatalf("RecomputeClientStats failed: %v", err)
+	}
+	fresh, err := db.GetClientStats(clientID)
+	if err != nil {
+		t.Fatalf("GetClientStats failed: %v", err)
+	}
+
+	if *cached != *fresh {
+		t.Errorf("Cached stats %+v differ from recomputed %+v", cached, fresh)
+	}
+	if fresh.ProjectCount != wantProjects || fresh.BenchmarkCount != wantBenchmarks {
+		t.Errorf("Expected %d projects and %d benchmarks, got %+v", wantProjects, wantBenchmarks, fresh)
+	}
+}
+
+func TestServiceDB_ClientStatsCachedAfterMutations(t *testing.T) {
+	db := newTestServiceDB(t)
+
+	client, err := db.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	other, err := db.CreateClient("Другой клиент", "ООО «Другой»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	assertClientStatsFresh(t, db, client.ID, 0, 0)
+
+	first, err := db.CreateClientProject(client.ID, "Проект 1", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	second, err := db.CreateClientProject(client.ID, "Проект 2", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+
+	var benchmarkID int
+	for i, projectID := range []int{first.ID, first.ID, second.ID} {
+		benchmark, err := db.CreateClientBenchmark(projectID, "Болт", "болт", "nomenclature", "", "{}", "", 0.8)
+		if err != nil {
+			t.Fatalf("CreateClientBenchmark %d failed: %v", i, err)
+		}
+		benchmarkID = benchmark.ID
+	}
+	assertClientStatsFresh(t, db, client.ID, 2, 3)
+	assertClientStatsFresh(t, db, other.ID, 0, 0)
+
+	// Изменение эталона сдвигает last_activity при следующем чтении
+	if _, err := db.conn.Exec(`UPDATE client_benchmarks SET updated_at = '2099-01-01 00:00:00' WHERE id = ?`, benchmarkID); err != nil {
+		t.Fatalf("Failed to update benchmark: %v", err)
+	}
+	db.markBenchmarkStatsDirty(benchmarkID)
+	stats, err := db.GetClientStats(client.ID)
+	if err != nil {
+		t.Fatalf("GetClientStats failed: %v", err)
+	}
+	if stats.LastActivity != "2099-01-01T00:00:00Z" {
+		t.Errorf("Expected last_activity to follow benchmark update, got %q", stats.LastActivity)
+	}
+	assertClientStatsFresh(t, db, client.ID, 2, 3)
+
+	// Перенос проекта к другому клиенту
+	if _, err := db.conn.Exec(`UPDATE client_projects SET client_id = ? WHERE id = ?`, other.ID, second.ID); err != nil {
+		t.Fatalf("Failed to move project: %v", err)
+	}
+	db.markClientStatsDirty(client.ID, other.ID)
+	assertClientStatsFresh(t, db, client.ID, 1, 2)
+	assertClientStatsFresh(t, db, other.ID, 1, 1)
+
+	// Удаление проекта каскадно удаляет его эталоны
+	if err := db.DeleteClientProject(first.ID); err != nil {
+		t.Fatalf("DeleteClientProject failed: %v", err)
+	}
+	assertClientStatsFresh(t, db, client.ID, 0, 0)
+
+	clients, err := db.GetClientsWithStats()
+	if err != nil {
+		t.Fatalf("GetClientsWithStats failed: %v", err)
+	}
+	for _, c := range clients {
+		if c["id"] == other.ID && (c["project_count"] != 1 || c["benchmark_count"] != 1) {
+			t.Errorf("Unexpected stats in GetClientsWithStats: %+v", c)
+		}
+	}
+}
+
+func TestServiceDB_RecomputeClientStatsNotFound(t *testing.T) {
+	db := newTestServiceDB(t)
+
+	if err := db.RecomputeClientStats(999); err == nil {
+		t.Error("Expected error for missing client")
+	}
+}
+
+// TestServiceDB_ClientStatsBatchedFlush проверяет пересчет пачками без построчных триггеров
+func TestServiceDB_ClientStatsBatchedFlush(t *testing.T) {
+	db := newTestServiceDB(t)
+
+	var triggers int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'client_stats_%'`).Scan(&triggers); err != nil {
+		t.Fatalf("Failed to count triggers: %v", err)
+	}
+	if triggers != 0 {
+		t.Errorf("Expected no client stats triggers, got %d", triggers)
+	}
+
+	client, err := db.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+
+	items := make([]BenchmarkInput, clientStatsBatchSize+1)
+	for i := range items {
+		items[i] = BenchmarkInput{OriginalName: fmt.Sprintf("Болт М%d", i), NormalizedName: "болт", Category: "nomenclature"}
+	}
+	if _, err := db.BulkCreateBenchmarks(project.ID, items); err != nil {
+		t.Fatalf("BulkCreateBenchmarks failed: %v", err)
+	}
+
+	// До чтения статистика в clients не обновляется
+	var cached int
+	if err := db.conn.QueryRow(`SELECT benchmark_count FROM clients WHERE id = ?`, client.ID).Scan(&cached); err != nil {
+		t.Fatalf("Failed to read cached stats: %v", err)
+	}
+	if cached != 0 {
+		t.Errorf("Expected stats to be recomputed lazily, got benchmark_count %d", cached)
+	}
+
+	assertClientStatsFresh(t, db, client.ID, 1, clientStatsBatchSize+1)
+
+	if err := db.RecomputeAllClientStats(); err != nil {
+		t.Fatalf("RecomputeAllClientStats failed: %v", err)
+	}
+	assertClientStatsFresh(t, db, client.ID, 1, clientStatsBatchSize+1)
+}
@@ -156,6 +156,7 @@ func CreateNormalizedCounterpartiesTable(db *sql.DB) error {
 			source_enrichment TEXT DEFAULT '',
 			source_database TEXT,
 			subcategory TEXT,
+			ogrn TEXT,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			FOREIGN KEY(client_project_id) REFERENCES client_projects(id) ON DELETE CASCADE,
@@ -197,6 +198,7 @@ func CreateNormalizedCounterpartiesTable(db *sql.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_normalized_counterparties_tax_id ON normalized_counterparties(tax_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_normalized_counterparties_benchmark_id ON normalized_counterparties(benchmark_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_normalized_counterparties_subcategory ON normalized_counterparties(subcategory)`,
+		`CREATE INDEX IF NOT EXISTS idx_normalized_counterparties_ogrn ON normalized_counterparties(ogrn)`,
 		`CREATE INDEX IF NOT EXISTS idx_normalized_counterparties_source_enrichment ON normalized_counterparties(source_enrichment)`,
 	}
 
@@ -239,6 +241,21 @@ func MigrateBenchmarkManufacturerLink(db *sql.DB) error {
 	return nil
 }
 
+// MigrateBenchmarkManufacturerMatchType добавляет поле manufacturer_match_type (inn, ogrn, none) -
+// ключ, по которому номенклатура связана с производителем
+func MigrateBenchmarkManufacturerMatchType(db *sql.DB) error {
+	_, err := db.Exec(`ALTER TABLE client_benchmarks ADD COLUMN manufacturer_match_type TEXT`)
+	if err != nil {
+		errStr := strings.ToLower(err.Error())
+		// Игнорируем ошибку, если поле уже существует
+		if !strings.Contains(errStr, "duplicate column") && !strings.Contains(errStr, "already exists") {
+			return fmt.Errorf("failed to add manufacturer_match_type column: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // MigrateNormalizedCounterpartiesSubcategory добавляет поле subcategory в таблицу normalized_counterparties
 func MigrateNormalizedCounterpartiesSubcategory(db *sql.DB) error {
 	// Проверяем существование таблицы
@@ -280,6 +297,30 @@ func MigrateNormalizedCounterpartiesSubcategory(db *sql.DB) error {
 	return nil
 }
 
+// MigrateNormalizedCounterpartiesOGRN добавляет поле ogrn в таблицу normalized_counterparties.
+// ОГРН используется как ключ группировки дубликатов, у которых нет ИНН и БИН.
+func MigrateNormalizedCounterpartiesOGRN(db *sql.DB) error {
+	migrations := []string{
+		`ALTER TABLE normalized_counterparties ADD COLUMN ogrn TEXT`,
+		`CREATE INDEX IF NOT EXISTS idx_normalized_counterparties_ogrn ON normalized_counterparties(ogrn)`,
+	}
+
+	for _, migration := range migrations {
+		_, err := db.Exec(migration)
+		if err != nil {
+			errStr := strings.ToLower(err.Error())
+			// Игнорируем ошибки, если поле уже существует
+			if !strings.Contains(errStr, "duplicate column") &&
+				!strings.Contains(errStr, "already exists") &&
+				!strings.Contains(errStr, "duplicate index") {
+				return fmt.Errorf("migration failed: %s, error: %w", migration, err)
+			}
+		}
+	}
+
+	return nil
+}
+
 // CreateCounterpartyDatabasesTable создает таблицу counterparty_databases для связи many-to-many
 // между нормализованными контрагентами и базами данных проекта
 func CreateCounterpartyDatabasesTable(db *sql.DB) error {
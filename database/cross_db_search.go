@@ -0,0 +1,191 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Ограничения поиска по базам данных проекта
+const (
+	// DefaultCrossDBSearchLimit максимальное количество результатов по умолчанию
+	DefaultCrossDBSearchLimit = 200
+	// crossDBSearchMaxConcurrency количество одновременно открытых баз данных
+	crossDBSearchMaxConcurrency = 4
+)
+
+// Источники результатов поиска по базам данных проекта
+const (
+	CrossDBSourceCatalogItems      = "catalog_items"
+	CrossDBSourceNomenclatureItems = "nomenclature_items"
+)
+
+// CrossDBHit запись, найденная в одной из баз данных проекта
+type CrossDBHit struct {
+	DatabaseID   int    `json:"database_id"`
+	DatabaseName string `json:"database_name"`
+	Source       string `json:"source"` // catalog_items или nomenclature_items
+	ItemID       int    `json:"item_id"`
+	Reference    string `json:"reference"`
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+}
+
+// SearchAcrossProjectDatabases ищет запись по коду или названию во всех активных базах данных проекта
+func (db *ServiceDB) SearchAcrossProjectDatabases(projectID int, query string) ([]CrossDBHit, error) {
+	return db.SearchAcrossProjectDatabasesContext(context.Background(), projectID, query, DefaultCrossDBSearchLimit)
+}
+
+// SearchAcrossProjectDatabasesContext ищет запись по коду или названию в catalog_items и nomenclature_items
+// всех активных баз данных проекта. Базы открываются параллельно (не более crossDBSearchMaxConcurrency).
+// Поиск прекращается при достижении limit (limit <= 0 - DefaultCrossDBSearchLimit) или отмене ctx.
+// Недоступные базы пропускаются с записью в лог.
+func (db *ServiceDB) SearchAcrossProjectDatabasesContext(ctx context.Context, projectID int, query string, limit int) ([]CrossDBHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query is required")
+	}
+	if limit <= 0 {
+		limit = DefaultCrossDBSearchLimit
+	}
+
+	databases, err := db.GetProjectDatabases(projectID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project databases: %w", err)
+	}
+
+	searchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	semaphore := make(chan struct{}, crossDBSearchMaxConcurrency)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	hits := make([]CrossDBHit, 0)
+
+	for _, projectDB := range databases {
+		if searchCtx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(projectDB *ProjectDatabase) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if searchCtx.Err() != nil {
+				return
+			}
+
+			dbHits, err := searchProjectDatabase(searchCtx, projectDB, query, limit)
+			if err != nil {
+				if searchCtx.Err() == nil {
+					log.Printf("[CrossDBSearch] Пропущена БД %d (%s): %v", projectDB.ID, projectDB.FilePath, err)
+				}
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			hits = append(hits, dbHits...)
+			if len(hits) >= limit {
+				// Результатов достаточно - останавливаем поиск в остальных базах
+				cancel()
+			}
+		}(projectDB)
+	}
+	wg.Wait()
+
+	// Отмена вызывающим - ошибка; собственная отмена по лимиту - нет
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].DatabaseID != hits[j].DatabaseID {
+			return hits[i].DatabaseID < hits[j].DatabaseID
+		}
+		if hits[i].Source != hits[j].Source {
+			return hits[i].Source < hits[j].Source
+		}
+		return hits[i].ItemID < hits[j].ItemID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+
+	return hits, nil
+}
+
+// searchProjectDatabase ищет запись в одной базе данных проекта
+func searchProjectDatabase(ctx context.Context, projectDB *ProjectDatabase, query string, limit int) ([]CrossDBHit, error) {
+	// Проверяем файл заранее, чтобы sql.Open не создал пустую БД
+	if _, err := os.Stat(projectDB.FilePath); err != nil {
+		return nil, fmt.Errorf("database file is not accessible: %w", err)
+	}
+
+	conn, err := sql.Open("sqlite3", projectDB.FilePath+"?_timeout=5000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer conn.Close()
+	conn.SetMaxOpenConns(1)
+
+	pattern := "%" + query + "%"
+	sources := []struct {
+		table string
+		query string
+	}{
+		{CrossDBSourceCatalogItems, `
+			SELECT id, reference, COALESCE(code, ''), COALESCE(name, '')
+			FROM catalog_items
+			WHERE code = ? OR code LIKE ? OR name LIKE ?
+			ORDER BY id
+			LIMIT ?`},
+		{CrossDBSourceNomenclatureItems, `
+			SELECT id, nomenclature_reference, COALESCE(nomenclature_code, ''), COALESCE(nomenclature_name, '')
+			FROM nomenclature_items
+			WHERE nomenclature_code = ? OR nomenclature_code LIKE ? OR nomenclature_name LIKE ?
+			ORDER BY id
+			LIMIT ?`},
+	}
+
+	var hits []CrossDBHit
+	for _, source := range sources {
+		var exists bool
+		err := conn.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = ?)
+		`, source.table).Scan(&exists)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check table %s: %w", source.table, err)
+		}
+		if !exists {
+			continue
+		}
+
+		rows, err := conn.QueryContext(ctx, source.query, query, pattern, pattern, limit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s: %w", source.table, err)
+		}
+		for rows.Next() {
+			hit := CrossDBHit{DatabaseID: projectDB.ID, DatabaseName: projectDB.Name, Source: source.table}
+			if err := rows.Scan(&hit.ItemID, &hit.Reference, &hit.Code, &hit.Name); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s: %w", source.table, err)
+			}
+			hits = append(hits, hit)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate %s: %w", source.table, err)
+		}
+	}
+
+	return hits, nil
+}
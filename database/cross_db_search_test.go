@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+// seedUploadDatabase создает БД выгрузки с элементом справочника и номенклатурой
+func seedUploadDatabase(t *testing.T, path string, catalogCode, nomenclatureCode string) {
+	t.Helper()
+
+	db, err := NewDB(path)
+	if err != nil {
+		t.Fatalf("Failed to create upload DB: %v", err)
+	}
+	defer db.Close()
+
+	upload, err := db.CreateUpload(fmt.Sprintf("uuid-%s", filepath.Base(path)), "8.3", "test-config")
+	if err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+	catalog, err := db.AddCatalog(upload.ID, "Номенклатура", "nomenclature")
+	if err != nil {
+		t.Fatalf("Failed to create catalog: %v", err)
+	}
+	if err := db.AddCatalogItem(catalog.ID, "ref-"+catalogCode, catalogCode, "Болт "+catalogCode, "", ""); err != nil {
+		t.Fatalf("Failed to add catalog item: %v", err)
+	}
+	if err := db.AddNomenclatureItem(upload.ID, "nref-"+nomenclatureCode, nomenclatureCode, "Гайка "+nomenclatureCode, "", "", "", ""); err != nil {
+		t.Fatalf("Failed to add nomenclature item: %v", err)
+	}
+}
+
+func setupCrossDBSearch(t *testing.T) (*ServiceDB, int) {
+	t.Helper()
+	dir := t.TempDir()
+
+	serviceDB, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	t.Cleanup(func() { serviceDB.Close() })
+
+	client, err := serviceDB.CreateClient("Search Client", "Search Client LLC", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Search Project", "nomenclature", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	first := filepath.Join(dir, "first.db")
+	second := filepath.Join(dir, "second.db")
+	seedUploadDatabase(t, first, "A-100", "N-200")
+	seedUploadDatabase(t, second, "B-300", "A-100")
+
+	for name, path := range map[string]string{
+		"first":   first,
+		"second":  second,
+		"missing": filepath.Join(dir, "missing.db"), // недоступная БД пропускается
+	} {
+		if _, err := serviceDB.CreateProjectDatabase(project.ID, name, path, "", 0); err != nil {
+			t.Fatalf("Failed to register database %s: %v", name, err)
+		}
+	}
+
+	return serviceDB, project.ID
+}
+
+func TestSearchAcrossProjectDatabases(t *testing.T) {
+	serviceDB, projectID := setupCrossDBSearch(t)
+
+	hits, err := serviceDB.SearchAcrossProjectDatabases(projectID, "A-100")
+	if err != nil {
+		t.Fatalf("SearchAcrossProjectDatabases failed: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Fatalf("Expected 2 hits, got %d: %+v", len(hits), hits)
+	}
+
+	found := make(map[string]string)
+	for _, hit := range hits {
+		if hit.DatabaseID == 0 || hit.Code != "A-100" {
+			t.Errorf("Unexpected hit: %+v", hit)
+		}
+		found[hit.DatabaseName] = hit.Source
+	}
+	if found["first"] != CrossDBSourceCatalogItems {
+		t.Errorf("Expected catalog item hit in first DB, got %q", found["first"])
+	}
+	if found["second"] != CrossDBSourceNomenclatureItems {
+		t.Errorf("Expected nomenclature item hit in second DB, got %q", found["second"])
+	}
+
+	// Поиск по части названия
+	hits, err = serviceDB.SearchAcrossProjectDatabases(projectID, "Гайка")
+	if err != nil {
+		t.Fatalf("SearchAcrossProjectDatabases failed: %v", err)
+	}
+	if len(hits) != 2 {
+		t.Errorf("Expected 2 nomenclature hits by name, got %d", len(hits))
+	}
+}
+
+func TestSearchAcrossProjectDatabases_LimitAndCancel(t *testing.T) {
+	serviceDB, projectID := setupCrossDBSearch(t)
+
+	hits, err := serviceDB.SearchAcrossProjectDatabasesContext(context.Background(), projectID, "-", 3)
+	if err != nil {
+		t.Fatalf("SearchAcrossProjectDatabasesContext failed: %v", err)
+	}
+	if len(hits) != 3 {
+		t.Errorf("Expected results capped at 3, got %d", len(hits))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := serviceDB.SearchAcrossProjectDatabasesContext(ctx, projectID, "A-100", 10); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	if _, err := serviceDB.SearchAcrossProjectDatabases(projectID, "  "); err == nil {
+		t.Error("Expected error for empty query")
+	}
+}
@@ -16,6 +16,8 @@ import (
 // DB обертка для работы с базой данных
 type DB struct {
 	conn *sql.DB
+	// metricDetailsCompressThreshold порог сжатия details метрик качества (0 - без сжатия)
+	metricDetailsCompressThreshold int
 }
 
 // Upload представляет выгрузку из 1С
@@ -114,7 +116,7 @@ func NewDBWithConfig(dbPath string, config DBConfig) (*DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	db := &DB{conn: conn}
+	db := &DB{conn: conn, metricDetailsCompressThreshold: DefaultMetricDetailsCompressThreshold}
 
 	// Инициализируем схему
 	if err := InitSchema(conn); err != nil {
@@ -2498,6 +2500,22 @@ type DataQualityMetric struct {
 	Details        map[string]interface{} `json:"details,omitempty"`
 }
 
+// IsBreached возвращает true, если метрика нарушила пороговое значение (статус WARNING или FAIL)
+func (m DataQualityMetric) IsBreached() bool {
+	return m.Status == "WARNING" || m.Status == "FAIL"
+}
+
+// FilterBreachedMetrics возвращает только метрики, нарушившие пороговые значения
+func FilterBreachedMetrics(metrics []DataQualityMetric) []DataQualityMetric {
+	breached := make([]DataQualityMetric, 0, len(metrics))
+	for _, metric := range metrics {
+		if metric.IsBreached() {
+			breached = append(breached, metric)
+		}
+	}
+	return breached
+}
+
 // DataQualityIssue представляет проблему качества данных
 type DataQualityIssue struct {
 	ID              int        `json:"id"`
@@ -2531,22 +2549,24 @@ type QualityTrend struct {
 	CreatedAt         time.Time `json:"created_at"`
 }
 
-// SaveQualityMetric сохраняет метрику качества
+// SaveQualityMetric сохраняет метрику качества.
+// Details больше порога сжатия (см. SetMetricDetailsCompression) сохраняются сжатыми gzip.
 func (db *DB) SaveQualityMetric(metric *DataQualityMetric) error {
-	detailsJSON := ""
-	if metric.Details != nil {
-		detailsBytes, err := json.Marshal(metric.Details)
-		if err != nil {
-			return fmt.Errorf("failed to marshal details: %w", err)
-		}
-		detailsJSON = string(detailsBytes)
+	detailsData, compressed, err := encodeMetricDetails(metric.Details, db.metricDetailsCompressThreshold)
+	if err != nil {
+		return err
+	}
+	// Несжатый JSON хранится текстом, сжатый - BLOB
+	var details interface{} = string(detailsData)
+	if compressed {
+		details = detailsData
 	}
 
 	query := `
 		INSERT INTO data_quality_metrics (
 			upload_id, database_id, metric_category, metric_name,
-			metric_value, threshold_value, status, measured_at, details
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			metric_value, threshold_value, status, measured_at, details, details_compressed
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	result, err := db.conn.Exec(query,
@@ -2558,7 +2578,8 @@ func (db *DB) SaveQualityMetric(metric *DataQualityMetric) error {
 		metric.ThresholdValue,
 		metric.Status,
 		metric.MeasuredAt,
-		detailsJSON,
+		details,
+		compressed,
 	)
 
 	if err != nil {
@@ -2578,7 +2599,7 @@ func (db *DB) SaveQualityMetric(metric *DataQualityMetric) error {
 func (db *DB) GetQualityMetrics(uploadID int) ([]DataQualityMetric, error) {
 	query := `
 		SELECT id, upload_id, database_id, metric_category, metric_name,
-			metric_value, threshold_value, status, measured_at, details
+			metric_value, threshold_value, status, measured_at, details, details_compressed
 		FROM data_quality_metrics
 		WHERE upload_id = ?
 		ORDER BY measured_at DESC
@@ -2594,7 +2615,8 @@ func (db *DB) GetQualityMetrics(uploadID int) ([]DataQualityMetric, error) {
 	for rows.Next() {
 		var metric DataQualityMetric
 		var thresholdValue sql.NullFloat64
-		var detailsJSON sql.NullString
+		var details []byte
+		var compressed bool
 
 		err := rows.Scan(
 			&metric.ID,
@@ -2606,7 +2628,8 @@ func (db *DB) GetQualityMetrics(uploadID int) ([]DataQualityMetric, error) {
 			&thresholdValue,
 			&metric.Status,
 			&metric.MeasuredAt,
-			&detailsJSON,
+			&details,
+			&compressed,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan quality metric: %w", err)
@@ -2617,8 +2640,8 @@ func (db *DB) GetQualityMetrics(uploadID int) ([]DataQualityMetric, error) {
 			metric.ThresholdValue = &val
 		}
 
-		if detailsJSON.Valid && detailsJSON.String != "" {
-			if err := json.Unmarshal([]byte(detailsJSON.String), &metric.Details); err != nil {
+		if len(details) > 0 {
+			if metric.Details, err = decodeMetricDetails(details, compressed); err != nil {
 				metric.Details = make(map[string]interface{})
 			}
 		}
@@ -3089,7 +3112,7 @@ func (db *DB) GetQualityTrends(databaseID int, days int) ([]QualityTrend, error)
 func (db *DB) GetCurrentQualityMetrics(databaseID int) ([]DataQualityMetric, error) {
 	query := `
 		SELECT id, upload_id, database_id, metric_category, metric_name,
-			metric_value, threshold_value, status, measured_at, details
+			metric_value, threshold_value, status, measured_at, details, details_compressed
 		FROM data_quality_metrics
 		WHERE database_id = ?
 			AND measured_at = (
@@ -3110,7 +3133,8 @@ func (db *DB) GetCurrentQualityMetrics(databaseID int) ([]DataQualityMetric, err
 	for rows.Next() {
 		var metric DataQualityMetric
 		var thresholdValue sql.NullFloat64
-		var detailsJSON sql.NullString
+		var details []byte
+		var compressed bool
 
 		err := rows.Scan(
 			&metric.ID,
@@ -3122,7 +3146,8 @@ func (db *DB) GetCurrentQualityMetrics(databaseID int) ([]DataQualityMetric, err
 			&thresholdValue,
 			&metric.Status,
 			&metric.MeasuredAt,
-			&detailsJSON,
+			&details,
+			&compressed,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan quality metric: %w", err)
@@ -3133,8 +3158,8 @@ func (db *DB) GetCurrentQualityMetrics(databaseID int) ([]DataQualityMetric, err
 			metric.ThresholdValue = &val
 		}
 
-		if detailsJSON.Valid && detailsJSON.String != "" {
-			if err := json.Unmarshal([]byte(detailsJSON.String), &metric.Details); err != nil {
+		if len(details) > 0 {
+			if metric.Details, err = decodeMetricDetails(details, compressed); err != nil {
 				metric.Details = make(map[string]interface{})
 			}
 		}
@@ -3145,6 +3170,85 @@ func (db *DB) GetCurrentQualityMetrics(databaseID int) ([]DataQualityMetric, err
 	return metrics, nil
 }
 
+// QualityMetricsPeriodStart возвращает начало периода выборки метрик качества:
+// day, week, month, по умолчанию - год
+func QualityMetricsPeriodStart(period string, now time.Time) time.Time {
+	switch period {
+	case "day":
+		return now.AddDate(0, 0, -1)
+	case "week":
+		return now.AddDate(0, 0, -7)
+	case "month":
+		return now.AddDate(0, -1, 0)
+	default:
+		return now.AddDate(-1, 0, 0)
+	}
+}
+
+// GetQualityMetricsForDatabases получает метрики качества указанных баз данных (project_databases.id),
+// измеренные начиная с since, от новых к старым
+func (db *DB) GetQualityMetricsForDatabases(databaseIDs []int, since time.Time) ([]DataQualityMetric, error) {
+	if len(databaseIDs) == 0 {
+		return nil, nil
+	}
+
+	placeholders, args := idPlaceholders(databaseIDs)
+	query := `
+		SELECT id, upload_id, database_id, metric_category, metric_name,
+			metric_value, threshold_value, status, measured_at, details, details_compressed
+		FROM data_quality_metrics
+		WHERE database_id IN (` + placeholders + `)
+			AND measured_at >= ?
+		ORDER BY measured_at DESC
+	`
+
+	rows, err := db.conn.Query(query, append(args, since)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quality metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var metrics []DataQualityMetric
+	for rows.Next() {
+		var metric DataQualityMetric
+		var thresholdValue sql.NullFloat64
+		var details []byte
+		var compressed bool
+
+		err := rows.Scan(
+			&metric.ID,
+			&metric.UploadID,
+			&metric.DatabaseID,
+			&metric.MetricCategory,
+			&metric.MetricName,
+			&metric.MetricValue,
+			&thresholdValue,
+			&metric.Status,
+			&metric.MeasuredAt,
+			&details,
+			&compressed,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan quality metric: %w", err)
+		}
+
+		if thresholdValue.Valid {
+			val := thresholdValue.Float64
+			metric.ThresholdValue = &val
+		}
+
+		if len(details) > 0 {
+			if metric.Details, err = decodeMetricDetails(details, compressed); err != nil {
+				metric.Details = make(map[string]interface{})
+			}
+		}
+
+		metrics = append(metrics, metric)
+	}
+
+	return metrics, rows.Err()
+}
+
 // GetTopQualityIssues получает топ проблем качества для базы данных
 func (db *DB) GetTopQualityIssues(databaseID int, limit int) ([]DataQualityIssue, error) {
 	query := `
@@ -4019,3 +4123,46 @@ func (db *DB) CleanOldMetrics(retentionDays int) error {
 
 	return nil
 }
+
+// DefaultMetricsCleanupBatchSize размер пачки CleanOldMetricsBatched по умолчанию
+const DefaultMetricsCleanupBatchSize = 1000
+
+// metricsCleanupBatchPause пауза между пачками, чтобы не блокировать запись в БД надолго
+var metricsCleanupBatchPause = 50 * time.Millisecond
+
+// CleanOldMetricsBatched удаляет метрики старше retentionDays дней пачками по batchSize строк
+// с короткими паузами между ними, чтобы очистка большой истории не блокировала БД одной
+// длинной транзакцией. Возвращает общее количество удаленных строк.
+func (db *DB) CleanOldMetricsBatched(retentionDays, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultMetricsCleanupBatchSize
+	}
+
+	query := `
+		DELETE FROM performance_metrics_history
+		WHERE id IN (
+			SELECT id FROM performance_metrics_history
+			WHERE timestamp < datetime('now', '-' || ? || ' days')
+			LIMIT ?
+		)
+	`
+
+	var total int64
+	for {
+		result, err := db.conn.Exec(query, retentionDays, batchSize)
+		if err != nil {
+			return total, fmt.Errorf("failed to clean old metrics: %w", err)
+		}
+
+		deleted, err := result.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("failed to get deleted metrics count: %w", err)
+		}
+		total += deleted
+
+		if deleted < int64(batchSize) {
+			return total, nil
+		}
+		time.Sleep(metricsCleanupBatchPause)
+	}
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// mojibakeCharmaps однобайтовые кодировки, через которые UTF-8 текст ошибочно прочитывается:
+// CP866 дает "╨У╨Ю╨б╨в" вместо "ГОСТ", Windows-1251 - "Р“РћРЎРў"
+var mojibakeCharmaps = []*charmap.Charmap{charmap.CodePage866, charmap.Windows1251}
+
+// maxMojibakePasses сколько раз подряд текст мог быть испорчен повторной перекодировкой
+const maxMojibakePasses = 3
+
+// mojibakeKeywords слова, присутствие которых надежно указывает на правильно декодированный текст ГОСТа
+var mojibakeKeywords = []string{"гост", "стандарт"}
+
+// RepairMojibake восстанавливает кириллический текст, испорченный повторной перекодировкой,
+// например "╨У╨Ю╨б╨в 7798-70" -> "ГОСТ 7798-70". Невалидный UTF-8 (сырые байты Windows-1251)
+// сначала декодируется как Windows-1251. Обратная перекодировка (UTF-8, прочитанный как CP866
+// или Windows-1251, кодируется обратно) применяется, только если в результате становится больше
+// правильных русских слов; поэтому правильный текст и текст на других языках не изменяются.
+// Возвращает исходную строку и false, если исправлять нечего или восстановить текст не удалось.
+func RepairMojibake(text string) (string, bool) {
+	if text == "" {
+		return text, false
+	}
+
+	result := text
+	if !utf8.ValidString(result) {
+		decoded, err := charmap.Windows1251.NewDecoder().String(result)
+		if err != nil || !utf8.ValidString(decoded) {
+			return text, false
+		}
+		result = decoded
+	}
+
+	if hasNonASCII(result) {
+		result, _ = bestMojibakeRepair(result, russianWordScore(result), maxMojibakePasses)
+	}
+	return result, result != text
+}
+
+// bestMojibakeRepair перебирает цепочки обратных перекодировок длиной до passes и возвращает
+// вариант с наибольшим числом русских слов. Промежуточный проход может не улучшать текст:
+// после первого прохода двойной "кракозябры" остается "╨У╨Ю╨б╨в", где слов еще нет.
+// При равенстве остается вариант с меньшим числом проходов, в том числе исходный текст.
+func bestMojibakeRepair(text string, score, passes int) (string, int) {
+	best, bestScore := text, score
+	if passes == 0 {
+		return best, bestScore
+	}
+	for _, cm := range mojibakeCharmaps {
+		candidate, err := cm.NewEncoder().String(text)
+		if err != nil || candidate == text || !utf8.ValidString(candidate) {
+			continue
+		}
+		repaired, repairedScore := bestMojibakeRepair(candidate, russianWordScore(candidate), passes-1)
+		if repairedScore > bestScore {
+			best, bestScore = repaired, repairedScore
+		}
+	}
+	return best, bestScore
+}
+
+// russianWordScore число слов текста из трех и более букв русского алфавита с обычным регистром
+// (строчные, заглавные или с заглавной первой буквой). Слова "ГОСТ" и "стандарт" учитываются
+// с большим весом. В "кракозябрах" буквы перемешаны с псевдографикой, знаками и буквами других
+// кириллических алфавитов, а оставшиеся короткие обрывки вроде "вЁР" отсеиваются по регистру.
+func russianWordScore(s string) int {
+	score := 0
+	for _, word := range strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if utf8.RuneCountInString(word) < 3 || !isRussianWord(word) {
+			continue
+		}
+		score++
+		lower := strings.ToLower(word)
+		for _, keyword := range mojibakeKeywords {
+			if strings.HasPrefix(lower, keyword) {
+				score += 5
+				break
+			}
+		}
+	}
+	return score
+}
+
+// isRussianWord проверяет, что слово состоит из букв русского алфавита и регистр букв обычный
+func isRussianWord(word string) bool {
+	runes := []rune(word)
+	upper := 0
+	for i, r := range runes {
+		if !(r >= 'А' && r <= 'я') && r != 'ё' && r != 'Ё' {
+			return false
+		}
+		if unicode.IsUpper(r) {
+			if upper < i {
+				return false // Заглавная буква после строчной
+			}
+			upper++
+		}
+	}
+	return upper <= 1 || upper == len(runes)
+}
+
+// HasEncodingIssue проверяет, содержит ли текст невалидный UTF-8 или восстановимую "кракозябру"
+func HasEncodingIssue(text string) bool {
+	_, changed := RepairMojibake(text)
+	return changed || !utf8.ValidString(text)
+}
+
+func hasNonASCII(text string) bool {
+	for i := 0; i < len(text); i++ {
+		if text[i] >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
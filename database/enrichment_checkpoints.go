@@ -0,0 +1,189 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Статусы прогона обогащения проекта (enrichment_checkpoints.status)
+const (
+	EnrichmentRunRunning     = "running"
+	EnrichmentRunCompleted   = "completed"
+	EnrichmentRunInterrupted = "interrupted" // остановлен или прерван - следующий запуск продолжит с last_benchmark_id
+	EnrichmentRunFailed      = "failed"
+)
+
+// EnrichmentCheckpoint прогресс обогащения эталонов контрагентов проекта.
+// Эталоны обрабатываются по возрастанию id, поэтому продолжение начинается после LastBenchmarkID.
+type EnrichmentCheckpoint struct {
+	ProjectID       int        `json:"project_id"`
+	LastBenchmarkID int        `json:"last_benchmark_id"`
+	Processed       int        `json:"processed"`
+	Enriched        int        `json:"enriched"`
+	Skipped         int        `json:"skipped"` // Качество уже не ниже Enrichment.MinQualityScore или нет ИНН/БИН
+	Failed          int        `json:"failed"`
+	Status          string     `json:"status"`
+	LastError       string     `json:"last_error,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+}
+
+// BenchmarkEnrichment данные сервиса обогащения для эталона контрагента. Пустые поля не изменяют эталон.
+type BenchmarkEnrichment struct {
+	NormalizedName string
+	TaxID          string
+	KPP            string
+	OGRN           string
+	LegalAddress   string
+	ContactPhone   string
+	ContactEmail   string
+	BankName       string
+	BankAccount    string
+	BIK            string
+	QualityScore   float64 // Качество поднимается до этого значения, но не понижается
+}
+
+// CreateEnrichmentCheckpointsTable создает таблицу контрольных точек обогащения проектов
+func CreateEnrichmentCheckpointsTable(db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS enrichment_checkpoints (
+			project_id INTEGER PRIMARY KEY,
+			last_benchmark_id INTEGER NOT NULL DEFAULT 0,
+			processed INTEGER NOT NULL DEFAULT 0,
+			enriched INTEGER NOT NULL DEFAULT 0,
+			skipped INTEGER NOT NULL DEFAULT 0,
+			failed INTEGER NOT NULL DEFAULT 0,
+			status TEXT NOT NULL DEFAULT 'running',
+			last_error TEXT,
+			started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			completed_at TIMESTAMP,
+			FOREIGN KEY(project_id) REFERENCES client_projects(id) ON DELETE CASCADE
+		)
+	`
+	if _, err := db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create enrichment_checkpoints table: %w", err)
+	}
+	return nil
+}
+
+// GetEnrichmentCheckpoint возвращает контрольную точку обогащения проекта или nil, если обогащение не запускалось
+func (db *ServiceDB) GetEnrichmentCheckpoint(projectID int) (*EnrichmentCheckpoint, error) {
+	checkpoint := &EnrichmentCheckpoint{ProjectID: projectID}
+	var lastError sql.NullString
+	var completedAt sql.NullTime
+
+	err := db.conn.QueryRow(`
+		SELECT last_benchmark_id, processed, enriched, skipped, failed, status, last_error,
+		       started_at, updated_at, completed_at
+		FROM enrichment_checkpoints WHERE project_id = ?
+	`, projectID).Scan(
+		&checkpoint.LastBenchmarkID, &checkpoint.Processed, &checkpoint.Enriched, &checkpoint.Skipped,
+		&checkpoint.Failed, &checkpoint.Status, &lastError,
+		&checkpoint.StartedAt, &checkpoint.UpdatedAt, &completedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enrichment checkpoint: %w", err)
+	}
+
+	checkpoint.LastError = lastError.String
+	if completedAt.Valid {
+		checkpoint.CompletedAt = &completedAt.Time
+	}
+
+	return checkpoint, nil
+}
+
+// SaveEnrichmentCheckpoint сохраняет контрольную точку обогащения проекта (создает или обновляет)
+func (db *ServiceDB) SaveEnrichmentCheckpoint(checkpoint *EnrichmentCheckpoint) error {
+	now := time.Now()
+	if checkpoint.StartedAt.IsZero() {
+		checkpoint.StartedAt = now
+	}
+	checkpoint.UpdatedAt = now
+	lastError := sql.NullString{String: checkpoint.LastError, Valid: checkpoint.LastError != ""}
+
+	_, err := db.conn.Exec(`
+		INSERT INTO enrichment_checkpoints
+		(project_id, last_benchmark_id, processed, enriched, skipped, failed, status, last_error,
+		 started_at, updated_at, completed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(project_id) DO UPDATE SET
+			last_benchmark_id = excluded.last_benchmark_id,
+			processed = excluded.processed,
+			enriched = excluded.enriched,
+			skipped = excluded.skipped,
+			failed = excluded.failed,
+			status = excluded.status,
+			last_error = excluded.last_error,
+			started_at = excluded.started_at,
+			updated_at = excluded.updated_at,
+			completed_at = excluded.completed_at
+	`,
+		checkpoint.ProjectID, checkpoint.LastBenchmarkID, checkpoint.Processed, checkpoint.Enriched,
+		checkpoint.Skipped, checkpoint.Failed, checkpoint.Status, lastError,
+		checkpoint.StartedAt, checkpoint.UpdatedAt, checkpoint.CompletedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save enrichment checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// ResetEnrichmentCheckpoint удаляет контрольную точку, чтобы следующее обогащение началось с начала
+func (db *ServiceDB) ResetEnrichmentCheckpoint(projectID int) error {
+	if _, err := db.conn.Exec(`DELETE FROM enrichment_checkpoints WHERE project_id = ?`, projectID); err != nil {
+		return fmt.Errorf("failed to reset enrichment checkpoint: %w", err)
+	}
+	return nil
+}
+
+// GetCounterpartyBenchmarksAfter возвращает до limit эталонов контрагентов проекта с id больше afterID по возрастанию id
+func (db *ServiceDB) GetCounterpartyBenchmarksAfter(projectID, afterID, limit int) ([]*ClientBenchmark, error) {
+	rows, err := db.conn.Query(`SELECT `+clientBenchmarkListColumns+`
+		FROM client_benchmarks
+		WHERE client_project_id = ? AND category = 'counterparty' AND id > ?
+		ORDER BY id
+		LIMIT ?
+	`, projectID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get counterparty benchmarks: %w", err)
+	}
+	defer rows.Close()
+
+	return scanClientBenchmarks(rows)
+}
+
+// ApplyBenchmarkEnrichment записывает в эталон контрагента данные сервиса обогащения
+func (db *ServiceDB) ApplyBenchmarkEnrichment(benchmarkID int, data BenchmarkEnrichment) error {
+	_, err := db.conn.Exec(`
+		UPDATE client_benchmarks
+		SET normalized_name = COALESCE(NULLIF(?, ''), normalized_name),
+		    tax_id = COALESCE(NULLIF(?, ''), tax_id),
+		    kpp = COALESCE(NULLIF(?, ''), kpp),
+		    ogrn = COALESCE(NULLIF(?, ''), ogrn),
+		    legal_address = COALESCE(NULLIF(?, ''), legal_address),
+		    contact_phone = COALESCE(NULLIF(?, ''), contact_phone),
+		    contact_email = COALESCE(NULLIF(?, ''), contact_email),
+		    bank_name = COALESCE(NULLIF(?, ''), bank_name),
+		    bank_account = COALESCE(NULLIF(?, ''), bank_account),
+		    bik = COALESCE(NULLIF(?, ''), bik),
+		    quality_score = MAX(COALESCE(quality_score, 0), ?),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`,
+		data.NormalizedName, data.TaxID, data.KPP, data.OGRN, data.LegalAddress,
+		data.ContactPhone, data.ContactEmail, data.BankName, data.BankAccount, data.BIK,
+		data.QualityScore, benchmarkID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to apply benchmark enrichment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// GostImportSource источник открытых данных Росстандарта, из которого import_gosts скачивает ГОСТы.
+// В отличие от GostSource (состояние синхронизации), это настройка: какие URL загружать при -all.
+type GostImportSource struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DefaultGostImportSources источники данных Росстандарта, которыми заполняется
+// таблица gost_import_sources при ее создании (50 источников)
+var DefaultGostImportSources = []GostImportSource{
+	{Name: "tulist", URL: "https://www.rst.gov.ru/opendata/7706406291-tulist"},
+	{Name: "nationalstandards", URL: "https://www.rst.gov.ru/opendata/7706406291-nationalstandards"},
+	{Name: "interstatestandards", URL: "https://www.rst.gov.ru/opendata/7706406291-interstatestandards"},
+	{Name: "techcommit", URL: "https://www.rst.gov.ru/opendata/7706406291-techcommit"},
+	{Name: "publiccouncils", URL: "https://www.rst.gov.ru/opendata/7706406291-publiccouncils"},
+	{Name: "gosuslugi", URL: "https://www.rst.gov.ru/opendata/7706406291-gosuslugi"},
+	{Name: "npa", URL: "https://www.rst.gov.ru/opendata/7706406291-npa"},
+	{Name: "zased", URL: "https://www.rst.gov.ru/opendata/7706406291-zased"},
+	{Name: "plan", URL: "https://www.rst.gov.ru/opendata/7706406291-plan"},
+	{Name: "reqsmi", URL: "https://www.rst.gov.ru/opendata/7706406291-reqsmi"},
+	{Name: "listinstrros", URL: "https://www.rst.gov.ru/opendata/7706406291-listinstrros"},
+	{Name: "vniiftricouncilplan", URL: "https://www.rst.gov.ru/opendata/7706406291-vniiftricouncilplan"},
+	{Name: "memoranda", URL: "https://www.rst.gov.ru/opendata/7706406291-memoranda"},
+	{Name: "regulations", URL: "https://www.rst.gov.ru/opendata/7706406291-regulations"},
+	{Name: "publiccouncilplan", URL: "https://www.rst.gov.ru/opendata/7706406291-publiccouncilplan"},
+	{Name: "rosstandartsystems", URL: "https://www.rst.gov.ru/opendata/7706406291-rosstandartsystems"},
+	{Name: "incomeemployees", URL: "https://www.rst.gov.ru/opendata/7706406291-incomeemployees"},
+	{Name: "efficiencyfact", URL: "https://www.rst.gov.ru/opendata/7706406291-efficiencyfact"},
+	{Name: "indicatorsindustry", URL: "https://www.rst.gov.ru/opendata/7706406291-indicatorsindustry"},
+	{Name: "targetsindustry", URL: "https://www.rst.gov.ru/opendata/7706406291-targetsindustry"},
+	{Name: "efficiencyplan", URL: "https://www.rst.gov.ru/opendata/7706406291-efficiencyplan"},
+	{Name: "budgetappropriations", URL: "https://www.rst.gov.ru/opendata/7706406291-budgetappropriations"},
+	{Name: "gostevents", URL: "https://www.rst.gov.ru/opendata/7706406291-gostevents"},
+	{Name: "establishedmedia", URL: "https://www.rst.gov.ru/opendata/7706406291-establishedmedia"},
+	{Name: "standartcouncil", URL: "https://www.rst.gov.ru/opendata/7706406291-standartcouncil"},
+	{Name: "vacanciesinfo", URL: "https://www.rst.gov.ru/opendata/7706406291-vacanciesinfo"},
+	{Name: "anticorruption", URL: "https://www.rst.gov.ru/opendata/7706406291-anticorruption"},
+	{Name: "stateprograms", URL: "https://www.rst.gov.ru/opendata/7706406291-stateprograms"},
+	{Name: "etalonros", URL: "https://www.rst.gov.ru/opendata/7706406291-etalonros"},
+	{Name: "citizensappeals", URL: "https://www.rst.gov.ru/opendata/7706406291-citizensappeals"},
+	{Name: "rosstandartstructure", URL: "https://www.rst.gov.ru/opendata/7706406291-rosstandartstructure"},
+	{Name: "informationsystems", URL: "https://www.rst.gov.ru/opendata/7706406291-informationsystems"},
+	{Name: "podved", URL: "https://www.rst.gov.ru/opendata/7706406291-podved"},
+	{Name: "rules", URL: "https://www.rst.gov.ru/opendata/7706406291-rules"},
+	{Name: "interaction", URL: "https://www.rst.gov.ru/opendata/7706406291-interaction"},
+	{Name: "controlresult", URL: "https://www.rst.gov.ru/opendata/7706406291-controlresult"},
+	{Name: "controlplan", URL: "https://www.rst.gov.ru/opendata/7706406291-controlplan"},
+	{Name: "incomepodved", URL: "https://www.rst.gov.ru/opendata/7706406291-incomepodved"},
+	{Name: "ndtlist", URL: "https://www.rst.gov.ru/opendata/7706406291-ndtlist"},
+	{Name: "verification", URL: "https://www.rst.gov.ru/opendata/7706406291-verification"},
+	{Name: "nssregistry", URL: "https://www.rst.gov.ru/opendata/7706406291-nssregistry"},
+	{Name: "nssblacklist", URL: "https://www.rst.gov.ru/opendata/7706406291--nssblacklist"},
+	{Name: "rstmaingoals", URL: "https://www.rst.gov.ru/opendata/7706406291-rstmaingoals"},
+	{Name: "koomet", URL: "https://www.rst.gov.ru/opendata/7706406291-koomet"},
+	{Name: "eaeutechregs", URL: "https://www.rst.gov.ru/opendata/7706406291-eaeutechregs"},
+	{Name: "orglist", URL: "https://www.rst.gov.ru/opendata/7706406291-orglist"},
+	{Name: "timezones", URL: "https://www.rst.gov.ru/opendata/7706406291-timezones"},
+	{Name: "declaredproducts", URL: "https://www.rst.gov.ru/opendata/7706406291-declaredproducts"},
+	{Name: "productcommoncertification", URL: "https://www.rst.gov.ru/opendata/7706406291-productcommoncertification"},
+	{Name: "listnationalstandarts", URL: "https://www.rst.gov.ru/opendata/7706406291-listnationalstandarts"},
+}
+
+// MigrateGostImportSources создает таблицу настраиваемых источников импорта ГОСТов.
+// При первом создании таблица заполняется DefaultGostImportSources; удаленные оператором
+// источники при следующих запусках не восстанавливаются.
+func MigrateGostImportSources(db *sql.DB) error {
+	var tableExists bool
+	err := db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM sqlite_master
+			WHERE type='table' AND name='gost_import_sources'
+		)
+	`).Scan(&tableExists)
+	if err != nil {
+		return fmt.Errorf("failed to check gost_import_sources existence: %w", err)
+	}
+	if tableExists {
+		return nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS gost_import_sources (
+			id INTEGER PRIMARY KEY,
+			name TEXT UNIQUE NOT NULL,
+			url TEXT NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create gost_import_sources table: %w", err)
+	}
+
+	for _, source := range DefaultGostImportSources {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO gost_import_sources (name, url, enabled) VALUES (?, ?, TRUE)`,
+			source.Name, source.URL); err != nil {
+			return fmt.Errorf("failed to seed gost import source %s: %w", source.Name, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit gost_import_sources migration: %w", err)
+	}
+
+	log.Printf("Created gost_import_sources table with %d default sources", len(DefaultGostImportSources))
+	return nil
+}
+
+// ListImportSources возвращает источники импорта, отсортированные по ID.
+// При enabledOnly возвращаются только включенные источники.
+func (db *GostsDB) ListImportSources(enabledOnly bool) ([]*GostImportSource, error) {
+	query := `SELECT id, name, url, enabled, created_at, updated_at FROM gost_import_sources`
+	if enabledOnly {
+		query += ` WHERE enabled = TRUE`
+	}
+	query += ` ORDER BY id`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list gost import sources: %w", err)
+	}
+	defer rows.Close()
+
+	sources := make([]*GostImportSource, 0)
+	for rows.Next() {
+		source := &GostImportSource{}
+		if err := rows.Scan(&source.ID, &source.Name, &source.URL, &source.Enabled, &source.CreatedAt, &source.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan gost import source: %w", err)
+		}
+		sources = append(sources, source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating gost import sources: %w", err)
+	}
+
+	return sources, nil
+}
+
+// GetImportSource получает источник импорта по имени
+func (db *GostsDB) GetImportSource(name string) (*GostImportSource, error) {
+	source := &GostImportSource{}
+	err := db.conn.QueryRow(`
+		SELECT id, name, url, enabled, created_at, updated_at
+		FROM gost_import_sources WHERE name = ?
+	`, name).Scan(&source.ID, &source.Name, &source.URL, &source.Enabled, &source.CreatedAt, &source.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gost import source %s: %w", name, err)
+	}
+	return source, nil
+}
+
+// CreateImportSource добавляет новый включенный источник импорта.
+// Возвращает ErrDuplicate, если источник с таким именем уже есть.
+func (db *GostsDB) CreateImportSource(name, url string) (*GostImportSource, error) {
+	name = strings.TrimSpace(name)
+	url = strings.TrimSpace(url)
+	if name == "" || url == "" {
+		return nil, fmt.Errorf("gost import source name and url are required")
+	}
+
+	_, err := db.conn.Exec(`INSERT INTO gost_import_sources (name, url, enabled) VALUES (?, ?, TRUE)`, name, url)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, fmt.Errorf("%w: gost import source %s already exists", ErrDuplicate, name)
+		}
+		return nil, fmt.Errorf("failed to create gost import source: %w", err)
+	}
+
+	return db.GetImportSource(name)
+}
+
+// UpdateImportSourceURL изменяет URL источника импорта
+func (db *GostsDB) UpdateImportSourceURL(name, url string) error {
+	url = strings.TrimSpace(url)
+	if url == "" {
+		return fmt.Errorf("gost import source url is required")
+	}
+	return db.updateImportSource(name, `url = ?`, url)
+}
+
+// SetImportSourceEnabled включает или отключает источник импорта
+func (db *GostsDB) SetImportSourceEnabled(name string, enabled bool) error {
+	return db.updateImportSource(name, `enabled = ?`, enabled)
+}
+
+// updateImportSource обновляет поле источника импорта и проверяет, что источник существует
+func (db *GostsDB) updateImportSource(name, set string, value interface{}) error {
+	result, err := db.conn.Exec(`UPDATE gost_import_sources SET `+set+`, updated_at = CURRENT_TIMESTAMP WHERE name = ?`, value, name)
+	if err != nil {
+		return fmt.Errorf("failed to update gost import source %s: %w", name, err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("gost import source %s not found", name)
+	}
+	return nil
+}
+
+// DeleteImportSource удаляет источник импорта. Уже загруженные из него ГОСТы не затрагиваются.
+func (db *GostsDB) DeleteImportSource(name string) error {
+	result, err := db.conn.Exec(`DELETE FROM gost_import_sources WHERE name = ?`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete gost import source %s: %w", name, err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return fmt.Errorf("gost import source %s not found", name)
+	}
+	return nil
+}
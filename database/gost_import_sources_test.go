@@ -0,0 +1,112 @@
+package database
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGostImportSources_SeededWithDefaults(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	sources, err := db.ListImportSources(false)
+	if err != nil {
+		t.Fatalf("ListImportSources failed: %v", err)
+	}
+	if len(sources) != len(DefaultGostImportSources) {
+		t.Fatalf("Expected %d seeded sources, got %d", len(DefaultGostImportSources), len(sources))
+	}
+	for i, source := range sources {
+		if source.Name != DefaultGostImportSources[i].Name || source.URL != DefaultGostImportSources[i].URL {
+			t.Errorf("Source %d: expected %s (%s), got %s (%s)", i,
+				DefaultGostImportSources[i].Name, DefaultGostImportSources[i].URL, source.Name, source.URL)
+		}
+		if !source.Enabled {
+			t.Errorf("Seeded source %s should be enabled", source.Name)
+		}
+	}
+}
+
+func TestGostImportSources_EnableDisableFiltering(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	if err := db.SetImportSourceEnabled("nationalstandards", false); err != nil {
+		t.Fatalf("SetImportSourceEnabled failed: %v", err)
+	}
+	if err := db.SetImportSourceEnabled("tulist", false); err != nil {
+		t.Fatalf("SetImportSourceEnabled failed: %v", err)
+	}
+
+	enabled, err := db.ListImportSources(true)
+	if err != nil {
+		t.Fatalf("ListImportSources failed: %v", err)
+	}
+	if len(enabled) != len(DefaultGostImportSources)-2 {
+		t.Errorf("Expected %d enabled sources, got %d", len(DefaultGostImportSources)-2, len(enabled))
+	}
+	for _, source := range enabled {
+		if source.Name == "nationalstandards" || source.Name == "tulist" {
+			t.Errorf("Disabled source %s returned as enabled", source.Name)
+		}
+	}
+
+	// Повторное включение возвращает источник в список
+	if err := db.SetImportSourceEnabled("tulist", true); err != nil {
+		t.Fatalf("SetImportSourceEnabled failed: %v", err)
+	}
+	source, err := db.GetImportSource("tulist")
+	if err != nil {
+		t.Fatalf("GetImportSource failed: %v", err)
+	}
+	if !source.Enabled {
+		t.Error("Expected tulist to be enabled again")
+	}
+
+	if err := db.SetImportSourceEnabled("missing", false); err == nil {
+		t.Error("Expected error for unknown source")
+	}
+}
+
+func TestGostImportSources_CRUD(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	created, err := db.CreateImportSource("custom", "https://example.com/opendata/custom")
+	if err != nil {
+		t.Fatalf("CreateImportSource failed: %v", err)
+	}
+	if !created.Enabled {
+		t.Error("New source should be enabled")
+	}
+
+	if _, err := db.CreateImportSource("custom", "https://example.com/other"); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate, got %v", err)
+	}
+
+	if err := db.UpdateImportSourceURL("custom", "https://example.com/opendata/custom-v2"); err != nil {
+		t.Fatalf("UpdateImportSourceURL failed: %v", err)
+	}
+	updated, err := db.GetImportSource("custom")
+	if err != nil {
+		t.Fatalf("GetImportSource failed: %v", err)
+	}
+	if updated.URL != "https://example.com/opendata/custom-v2" {
+		t.Errorf("Expected updated URL, got %s", updated.URL)
+	}
+
+	if err := db.DeleteImportSource("custom"); err != nil {
+		t.Fatalf("DeleteImportSource failed: %v", err)
+	}
+	if _, err := db.GetImportSource("custom"); err == nil {
+		t.Error("Expected error for deleted source")
+	}
+
+	// Удаленный источник по умолчанию не восстанавливается повторной миграцией
+	if err := db.DeleteImportSource("timezones"); err != nil {
+		t.Fatalf("DeleteImportSource failed: %v", err)
+	}
+	if err := MigrateGostsSchema(db.GetDB()); err != nil {
+		t.Fatalf("MigrateGostsSchema failed: %v", err)
+	}
+	if _, err := db.GetImportSource("timezones"); err == nil {
+		t.Error("Deleted default source should not be re-seeded")
+	}
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Правила согласованности ГОСТов, проверяемые FindInconsistentGosts
+const (
+	// GostRuleActiveNotYetEffective действующий ГОСТ с датой вступления в силу в будущем
+	GostRuleActiveNotYetEffective = "active_not_yet_effective"
+	// GostRuleActiveWithdrawn действующий ГОСТ с датой отмены в прошлом
+	GostRuleActiveWithdrawn = "active_withdrawn"
+)
+
+// gostActiveStatuses статусы, означающие, что стандарт действует
+var gostActiveStatuses = map[string]bool{
+	"действующий": true,
+	"действует":   true,
+	"active":      true,
+}
+
+// GostInconsistency ГОСТ, статус которого противоречит его датам
+type GostInconsistency struct {
+	GostID         int        `json:"gost_id"`
+	GostNumber     string     `json:"gost_number"`
+	Status         string     `json:"status"`
+	EffectiveDate  *time.Time `json:"effective_date,omitempty"`
+	WithdrawalDate *time.Time `json:"withdrawal_date,omitempty"`
+	Rule           string     `json:"rule"`
+	Message        string     `json:"message"`
+}
+
+// FindInconsistentGosts возвращает ГОСТы со статусом "действующий", у которых дата вступления
+// в силу еще не наступила или дата отмены уже прошла. Даты сравниваются с текущим днем.
+// ГОСТ, нарушающий оба правила, попадает в результат дважды.
+func (db *GostsDB) FindInconsistentGosts() ([]GostInconsistency, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, gost_number, status, effective_date, withdrawal_date
+		FROM gosts
+		WHERE status IS NOT NULL AND status != ''
+		  AND (effective_date IS NOT NULL OR withdrawal_date IS NOT NULL)
+		ORDER BY gost_number
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gosts for audit: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	var inconsistencies []GostInconsistency
+	for rows.Next() {
+		var (
+			id                            int
+			number, status                string
+			effectiveDate, withdrawalDate sql.NullTime
+		)
+		if err := rows.Scan(&id, &number, &status, &effectiveDate, &withdrawalDate); err != nil {
+			return nil, fmt.Errorf("failed to scan gost for audit: %w", err)
+		}
+
+		// Статус сравнивается в Go: lower() в SQLite не работает с кириллицей
+		if !gostActiveStatuses[strings.ToLower(strings.TrimSpace(status))] {
+			continue
+		}
+
+		base := GostInconsistency{GostID: id, GostNumber: number, Status: status}
+		if effectiveDate.Valid {
+			base.EffectiveDate = &effectiveDate.Time
+		}
+		if withdrawalDate.Valid {
+			base.WithdrawalDate = &withdrawalDate.Time
+		}
+
+		if effectiveDate.Valid && gostDay(effectiveDate.Time).After(today) {
+			item := base
+			item.Rule = GostRuleActiveNotYetEffective
+			item.Message = fmt.Sprintf("статус %q, но дата вступления в силу %s еще не наступила",
+				status, effectiveDate.Time.Format("2006-01-02"))
+			inconsistencies = append(inconsistencies, item)
+		}
+		if withdrawalDate.Valid && gostDay(withdrawalDate.Time).Before(today) {
+			item := base
+			item.Rule = GostRuleActiveWithdrawn
+			item.Message = fmt.Sprintf("статус %q, но стандарт отменен %s",
+				status, withdrawalDate.Time.Format("2006-01-02"))
+			inconsistencies = append(inconsistencies, item)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate gosts for audit: %w", err)
+	}
+
+	return inconsistencies, nil
+}
+
+// gostDay отбрасывает время, оставляя календарную дату
+func gostDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,75 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindInconsistentGosts(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	now := time.Now()
+	future := now.AddDate(0, 1, 0)
+	past := now.AddDate(-1, 0, 0)
+
+	gosts := []*Gost{
+		// Нарушения
+		{GostNumber: "ГОСТ 1-2030", Title: "Вступит в силу позже", Status: "действующий", EffectiveDate: &future},
+		{GostNumber: "ГОСТ 2-2000", Title: "Отменен", Status: "Действующий", EffectiveDate: &past, WithdrawalDate: &past},
+		{GostNumber: "ГОСТ 3-2030", Title: "Оба нарушения", Status: "действующий", EffectiveDate: &future, WithdrawalDate: &past},
+		// Корректные записи
+		{GostNumber: "ГОСТ 4-2000", Title: "Действует", Status: "действующий", EffectiveDate: &past, WithdrawalDate: &future},
+		{GostNumber: "ГОСТ 5-2000", Title: "Отменен корректно", Status: "отменен", EffectiveDate: &past, WithdrawalDate: &past},
+		{GostNumber: "ГОСТ 6-2030", Title: "Утвержден, но не введен", Status: "утвержден", EffectiveDate: &future},
+		{GostNumber: "ГОСТ 7-2000", Title: "Без дат", Status: "действующий"},
+		{GostNumber: "ГОСТ 8-2000", Title: "Вступил сегодня", Status: "действующий", EffectiveDate: &now},
+	}
+	for _, gost := range gosts {
+		if _, err := db.CreateOrUpdateGost(gost); err != nil {
+			t.Fatalf("Failed to seed gost %s: %v", gost.GostNumber, err)
+		}
+	}
+
+	inconsistencies, err := db.FindInconsistentGosts()
+	if err != nil {
+		t.Fatalf("FindInconsistentGosts failed: %v", err)
+	}
+
+	type finding struct{ number, rule string }
+	want := []finding{
+		{"ГОСТ 1-2030", GostRuleActiveNotYetEffective},
+		{"ГОСТ 2-2000", GostRuleActiveWithdrawn},
+		{"ГОСТ 3-2030", GostRuleActiveNotYetEffective},
+		{"ГОСТ 3-2030", GostRuleActiveWithdrawn},
+	}
+	if len(inconsistencies) != len(want) {
+		t.Fatalf("Expected %d inconsistencies, got %d: %+v", len(want), len(inconsistencies), inconsistencies)
+	}
+	for i, w := range want {
+		got := inconsistencies[i]
+		if got.GostNumber != w.number || got.Rule != w.rule {
+			t.Errorf("inconsistency[%d] = %s/%s, want %s/%s", i, got.GostNumber, got.Rule, w.number, w.rule)
+		}
+		if got.GostID == 0 || got.Message == "" {
+			t.Errorf("inconsistency[%d] missing id or message: %+v", i, got)
+		}
+	}
+}
+
+func TestCreateOrUpdateGost_KeepsWithdrawalDate(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	withdrawn := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := db.CreateOrUpdateGost(&Gost{GostNumber: "ГОСТ 9-2000", Title: "Отменен", Status: "отменен", WithdrawalDate: &withdrawn}); err != nil {
+		t.Fatalf("CreateOrUpdateGost failed: %v", err)
+	}
+
+	// Повторный импорт без даты отмены не стирает ее
+	gost, err := db.CreateOrUpdateGost(&Gost{GostNumber: "ГОСТ 9-2000", Title: "Отменен", Status: "отменен"})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateGost failed: %v", err)
+	}
+	if gost.WithdrawalDate == nil || !gost.WithdrawalDate.Equal(withdrawn) {
+		t.Errorf("Expected withdrawal date %v to be kept, got %v", withdrawn, gost.WithdrawalDate)
+	}
+}
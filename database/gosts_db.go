@@ -14,6 +14,7 @@ import (
 type GostsDB struct {
 	conn             *sql.DB
 	tableCreateMutex sync.Mutex
+	encoding         GostEncodingOptions // проверка кодировки при чтении (см. SetEncodingOptions)
 }
 
 // NewGostsDB создает новое подключение к базе данных ГОСТов
@@ -101,19 +102,24 @@ func (db *GostsDB) GetConnection() *sql.DB {
 
 // Gost структура ГОСТа
 type Gost struct {
-	ID            int        `json:"id"`
-	GostNumber    string     `json:"gost_number"`
-	Title         string     `json:"title"`
-	AdoptionDate  *time.Time `json:"adoption_date"`
-	EffectiveDate *time.Time `json:"effective_date"`
-	Status        string     `json:"status"`
-	SourceType    string     `json:"source_type"`
-	SourceID      *int       `json:"source_id"`
-	SourceURL     string     `json:"source_url"`
-	Description   string     `json:"description"`
-	Keywords      string     `json:"keywords"`
-	CreatedAt     time.Time  `json:"created_at"`
-	UpdatedAt     time.Time  `json:"updated_at"`
+	ID             int        `json:"id"`
+	GostNumber     string     `json:"gost_number"`
+	Title          string     `json:"title"`
+	AdoptionDate   *time.Time `json:"adoption_date"`
+	EffectiveDate  *time.Time `json:"effective_date"`
+	WithdrawalDate *time.Time `json:"withdrawal_date,omitempty"` // Дата отмены (заполняется в GetGost и GetGostByNumber)
+	Status         string     `json:"status"`
+	SourceType     string     `json:"source_type"`
+	SourceID       *int       `json:"source_id"`
+	SourceURL      string     `json:"source_url"`
+	Description    string     `json:"description"`
+	Keywords       string     `json:"keywords"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	// Score релевантность ГОСТа запросу (заполняется только в SuggestByText)
+	Score float64 `json:"score,omitempty"`
+	// EncodingIssue текстовые поля содержат испорченную кодировку (только в режиме StrictEncoding)
+	EncodingIssue bool `json:"encoding_issue,omitempty"`
 }
 
 // GostDocument структура документа ГОСТа
@@ -137,55 +143,16 @@ type GostSource struct {
 	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
-// CreateOrUpdateGost создает или обновляет ГОСТ
+// CreateOrUpdateGost создает или обновляет ГОСТ (см. UpsertGost)
 func (db *GostsDB) CreateOrUpdateGost(gost *Gost) (*Gost, error) {
-	query := `
-		INSERT INTO gosts (gost_number, title, adoption_date, effective_date, status, 
-		                   source_type, source_id, source_url, description, keywords, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
-		ON CONFLICT(gost_number) DO UPDATE SET
-			title = excluded.title,
-			adoption_date = excluded.adoption_date,
-			effective_date = excluded.effective_date,
-			status = excluded.status,
-			source_type = excluded.source_type,
-			source_id = excluded.source_id,
-			source_url = excluded.source_url,
-			description = excluded.description,
-			keywords = excluded.keywords,
-			updated_at = CURRENT_TIMESTAMP
-	`
-
-	result, err := db.conn.Exec(query,
-		gost.GostNumber, gost.Title, gost.AdoptionDate, gost.EffectiveDate,
-		gost.Status, gost.SourceType, gost.SourceID, gost.SourceURL,
-		gost.Description, gost.Keywords)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create or update gost: %w", err)
-	}
-
-	// Получаем ID записи (либо новый, либо существующий)
-	var id int64
-	insertID, err := result.LastInsertId()
-	if err == nil && insertID > 0 {
-		id = insertID
-	} else {
-		// Если это UPDATE, получаем ID по номеру ГОСТа
-		err := db.conn.QueryRow("SELECT id FROM gosts WHERE gost_number = ?", gost.GostNumber).Scan(&id)
-		if err != nil {
-			// Если не удалось получить ID, возвращаем ошибку
-			return nil, fmt.Errorf("failed to get gost ID: %w", err)
-		}
-	}
-
-	// Получаем полную запись
-	return db.GetGost(int(id))
+	saved, _, err := db.UpsertGost(gost)
+	return saved, err
 }
 
 // GetGost получает ГОСТ по ID
 func (db *GostsDB) GetGost(id int) (*Gost, error) {
 	query := `
-		SELECT id, gost_number, title, adoption_date, effective_date, status,
+		SELECT id, gost_number, title, adoption_date, effective_date, withdrawal_date, status,
 		       source_type, source_id, source_url, description, keywords,
 		       created_at, updated_at
 		FROM gosts WHERE id = ?
@@ -194,14 +161,14 @@ func (db *GostsDB) GetGost(id int) (*Gost, error) {
 	row := db.conn.QueryRow(query, id)
 	gost := &Gost{}
 
-	var adoptionDate, effectiveDate sql.NullTime
+	var adoptionDate, effectiveDate, withdrawalDate sql.NullTime
 	var sourceID sql.NullInt64
 
 	var createdAt sql.NullTime
 
 	err := row.Scan(
 		&gost.ID, &gost.GostNumber, &gost.Title,
-		&adoptionDate, &effectiveDate,
+		&adoptionDate, &effectiveDate, &withdrawalDate,
 		&gost.Status, &gost.SourceType, &sourceID,
 		&gost.SourceURL, &gost.Description, &gost.Keywords,
 		&createdAt, &gost.UpdatedAt,
@@ -224,10 +191,14 @@ func (db *GostsDB) GetGost(id int) (*Gost, error) {
 	if effectiveDate.Valid {
 		gost.EffectiveDate = &effectiveDate.Time
 	}
+	if withdrawalDate.Valid {
+		gost.WithdrawalDate = &withdrawalDate.Time
+	}
 	if sourceID.Valid {
 		id := int(sourceID.Int64)
 		gost.SourceID = &id
 	}
+	db.checkGostEncoding(gost)
 
 	return gost, nil
 }
@@ -235,7 +206,7 @@ func (db *GostsDB) GetGost(id int) (*Gost, error) {
 // GetGostByNumber получает ГОСТ по номеру
 func (db *GostsDB) GetGostByNumber(gostNumber string) (*Gost, error) {
 	query := `
-		SELECT id, gost_number, title, adoption_date, effective_date, status,
+		SELECT id, gost_number, title, adoption_date, effective_date, withdrawal_date, status,
 		       source_type, source_id, source_url, description, keywords,
 		       created_at, updated_at
 		FROM gosts WHERE gost_number = ?
@@ -244,13 +215,13 @@ func (db *GostsDB) GetGostByNumber(gostNumber string) (*Gost, error) {
 	row := db.conn.QueryRow(query, gostNumber)
 	gost := &Gost{}
 
-	var adoptionDate, effectiveDate sql.NullTime
+	var adoptionDate, effectiveDate, withdrawalDate sql.NullTime
 	var sourceID sql.NullInt64
 	var createdAt sql.NullTime
 
 	err := row.Scan(
 		&gost.ID, &gost.GostNumber, &gost.Title,
-		&adoptionDate, &effectiveDate,
+		&adoptionDate, &effectiveDate, &withdrawalDate,
 		&gost.Status, &gost.SourceType, &sourceID,
 		&gost.SourceURL, &gost.Description, &gost.Keywords,
 		&createdAt, &gost.UpdatedAt,
@@ -273,52 +244,68 @@ func (db *GostsDB) GetGostByNumber(gostNumber string) (*Gost, error) {
 	if effectiveDate.Valid {
 		gost.EffectiveDate = &effectiveDate.Time
 	}
+	if withdrawalDate.Valid {
+		gost.WithdrawalDate = &withdrawalDate.Time
+	}
 	if sourceID.Valid {
 		id := int(sourceID.Int64)
 		gost.SourceID = &id
 	}
+	db.checkGostEncoding(gost)
 
 	return gost, nil
 }
 
-// SearchGosts выполняет поиск ГОСТов
+// SearchGosts выполняет поиск ГОСТов. Совпадения ищутся полнотекстовым индексом gosts_fts
+// (каждое слово запроса по префиксу) и упорядочиваются по релевантности BM25; если индекс
+// недоступен или в запросе нет слов, используется поиск подстроки с сортировкой по номеру.
 func (db *GostsDB) SearchGosts(
 	query string,
 	limit, offset int,
 	status, sourceType,
 	adoptionFrom, adoptionTo, effectiveFrom, effectiveTo string,
 ) ([]*Gost, int, error) {
-	whereClause := "(gost_number LIKE ? OR title LIKE ? OR keywords LIKE ?)"
-	args := []interface{}{}
-
-	searchPattern := "%" + query + "%"
-	args = append(args, searchPattern, searchPattern, searchPattern)
+	filters := ""
+	filterArgs := []interface{}{}
 
 	if status != "" {
-		whereClause += " AND status = ?"
-		args = append(args, status)
+		filters += " AND status = ?"
+		filterArgs = append(filterArgs, status)
 	}
 	if sourceType != "" {
-		whereClause += " AND source_type = ?"
-		args = append(args, sourceType)
+		filters += " AND source_type = ?"
+		filterArgs = append(filterArgs, sourceType)
 	}
 	if adoptionFrom != "" {
-		whereClause += " AND adoption_date IS NOT NULL AND date(adoption_date) >= date(?)"
-		args = append(args, adoptionFrom)
+		filters += " AND adoption_date IS NOT NULL AND date(adoption_date) >= date(?)"
+		filterArgs = append(filterArgs, adoptionFrom)
 	}
 	if adoptionTo != "" {
-		whereClause += " AND adoption_date IS NOT NULL AND date(adoption_date) <= date(?)"
-		args = append(args, adoptionTo)
+		filters += " AND adoption_date IS NOT NULL AND date(adoption_date) <= date(?)"
+		filterArgs = append(filterArgs, adoptionTo)
 	}
 	if effectiveFrom != "" {
-		whereClause += " AND effective_date IS NOT NULL AND date(effective_date) >= date(?)"
-		args = append(args, effectiveFrom)
+		filters += " AND effective_date IS NOT NULL AND date(effective_date) >= date(?)"
+		filterArgs = append(filterArgs, effectiveFrom)
 	}
 	if effectiveTo != "" {
-		whereClause += " AND effective_date IS NOT NULL AND date(effective_date) <= date(?)"
-		args = append(args, effectiveTo)
+		filters += " AND effective_date IS NOT NULL AND date(effective_date) <= date(?)"
+		filterArgs = append(filterArgs, effectiveTo)
+	}
+
+	if matchQuery := buildGostMatchQuery(query); matchQuery != "" {
+		gosts, total, err := db.searchGostsFTS(matchQuery, filters, filterArgs, limit, offset)
+		if err == nil {
+			return gosts, total, nil
+		}
+		// Индекс может отсутствовать, если миграция не выполнилась
+		log.Printf("Warning: gosts_fts search failed, falling back to LIKE: %v", err)
 	}
 
+	searchPattern := "%" + query + "%"
+	whereClause := "(gost_number LIKE ? OR title LIKE ? OR keywords LIKE ?)" + filters
+	args := append([]interface{}{searchPattern, searchPattern, searchPattern}, filterArgs...)
+
 	searchQuery := fmt.Sprintf(`
 		SELECT id, gost_number, title, adoption_date, effective_date, status,
 		       source_type, source_id, source_url, description, keywords,
@@ -337,6 +324,7 @@ func (db *GostsDB) SearchGosts(
 	defer rows.Close()
 
 	var gosts []*Gost
+	repaired := 0
 	for rows.Next() {
 		gost := &Gost{}
 		var adoptionDate, effectiveDate sql.NullTime
@@ -363,10 +351,17 @@ func (db *GostsDB) SearchGosts(
 			id := int(sourceID.Int64)
 			gost.SourceID = &id
 		}
+		if db.checkGostEncoding(gost) {
+			repaired++
+		}
 
 		gosts = append(gosts, gost)
 	}
 
+	if repaired > 0 {
+		log.Printf("SearchGosts: repaired encoding in %d of %d GOST rows", repaired, len(gosts))
+	}
+
 	// Получаем общее количество
 	countQuery := fmt.Sprintf(`
 		SELECT COUNT(*) FROM gosts
@@ -433,6 +428,7 @@ func (db *GostsDB) ListGosts(
 	defer rows.Close()
 
 	var gosts []*Gost
+	repaired := 0
 	for rows.Next() {
 		gost := &Gost{}
 		var adoptionDate, effectiveDate sql.NullTime
@@ -467,10 +463,17 @@ func (db *GostsDB) ListGosts(
 			id := int(sourceID.Int64)
 			gost.SourceID = &id
 		}
+		if db.checkGostEncoding(gost) {
+			repaired++
+		}
 
 		gosts = append(gosts, gost)
 	}
 
+	if repaired > 0 {
+		log.Printf("ListGosts: repaired encoding in %d of %d GOST rows", repaired, len(gosts))
+	}
+
 	// Получаем общее количество
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM gosts WHERE %s", whereClause)
 	countArgs := args[:len(args)-2] // Убираем limit и offset
@@ -516,18 +519,15 @@ func (db *GostsDB) CreateOrUpdateSource(source *GostSource) (*GostSource, error)
 		`
 	}
 
-	result, err := db.conn.Exec(query, source.SourceName, source.SourceURL, source.LastSyncDate, source.RecordsCount)
+	_, err := db.conn.Exec(query, source.SourceName, source.SourceURL, source.LastSyncDate, source.RecordsCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create or update source: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		// Если это UPDATE, получаем ID по имени
-		err := db.conn.QueryRow("SELECT id FROM gost_sources WHERE source_name = ?", source.SourceName).Scan(&id)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get source ID: %w", err)
-		}
+	// Получаем ID по имени: при UPDATE через ON CONFLICT LastInsertId не меняется
+	var id int64
+	if err := db.conn.QueryRow("SELECT id FROM gost_sources WHERE source_name = ?", source.SourceName).Scan(&id); err != nil {
+		return nil, fmt.Errorf("failed to get source ID: %w", err)
 	}
 
 	return db.GetSource(int(id))
@@ -701,6 +701,74 @@ func (db *GostsDB) GetDocumentsByGostID(gostID int) ([]*GostDocument, error) {
 	return documents, nil
 }
 
+// gostFieldClearSQL очистка значения каждого поля ГОСТа при удалении данных источника.
+// Текстовые поля очищаются пустой строкой (как их записывает импорт); вместе с названием
+// сбрасываются source_type, source_id и source_url, которые следуют за источником названия (см. MergeGost).
+var gostFieldClearSQL = map[string]string{
+	GostFieldTitle:          "title = '', source_type = '', source_id = NULL, source_url = ''",
+	GostFieldAdoptionDate:   "adoption_date = NULL",
+	GostFieldEffectiveDate:  "effective_date = NULL",
+	GostFieldWithdrawalDate: "withdrawal_date = NULL",
+	GostFieldStatus:         "status = ''",
+	GostFieldDescription:    "description = ''",
+	GostFieldKeywords:       "keywords = ''",
+}
+
+// DeleteBySource удаляет данные источника sourceType, определяя их по gost_field_sources.
+// ГОСТы, все поля которых взяты из этого источника, удаляются вместе с документами и
+// источниками полей; у ГОСТов, собранных из нескольких источников, очищаются только поля
+// этого источника (и сбрасывается row_hash), чтобы повторный импорт заполнил их заново.
+// Записи, сохраненные до появления gost_field_sources, определяются по source_type.
+// Возвращает количество удаленных ГОСТов. Используется перед повторным импортом одного источника.
+func (db *GostsDB) DeleteBySource(sourceType string) (int, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		DELETE FROM gosts
+		WHERE (id IN (SELECT gost_id FROM gost_field_sources WHERE source_type = ?)
+		       AND id NOT IN (SELECT gost_id FROM gost_field_sources WHERE source_type != ?))
+		   OR (source_type = ? AND id NOT IN (SELECT gost_id FROM gost_field_sources))
+	`, sourceType, sourceType, sourceType)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete gosts of source %s: %w", sourceType, err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get deleted gosts count: %w", err)
+	}
+
+	for field, clear := range gostFieldClearSQL {
+		_, err := tx.Exec(`
+			UPDATE gosts SET `+clear+`, row_hash = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE id IN (SELECT gost_id FROM gost_field_sources WHERE field = ? AND source_type = ?)
+		`, field, sourceType)
+		if err != nil {
+			return 0, fmt.Errorf("failed to clear %s of source %s: %w", field, sourceType, err)
+		}
+	}
+	if _, err := tx.Exec(`DELETE FROM gost_field_sources WHERE source_type = ?`, sourceType); err != nil {
+		return 0, fmt.Errorf("failed to delete field sources of source %s: %w", sourceType, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit source deletion: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// CountGosts возвращает общее количество ГОСТов
+func (db *GostsDB) CountGosts() (int, error) {
+	var count int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM gosts").Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count gosts: %w", err)
+	}
+	return count, nil
+}
+
 // GetStatistics возвращает статистику по базе ГОСТов
 func (db *GostsDB) GetStatistics() (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
@@ -0,0 +1,264 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// setupTestGostsDB создает временную базу ГОСТов в каталоге теста
+func setupTestGostsDB(t *testing.T) *GostsDB {
+	t.Helper()
+
+	db, err := NewGostsDB(filepath.Join(t.TempDir(), "gosts.db"))
+	if err != nil {
+		t.Fatalf("Failed to create GOSTs DB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+// seedTestGosts заполняет базу набором ГОСТов для проверки поиска
+func seedTestGosts(t *testing.T, db *GostsDB) {
+	t.Helper()
+
+	gosts := []*Gost{
+		{GostNumber: "ГОСТ 29273-92", Title: "Свариваемость. Определение", Keywords: "сварка, свариваемость"},
+		{GostNumber: "ГОСТ 26388-84", Title: "Соединения сварные. Методы испытаний на сопротивляемость образованию холодных трещин при сварке плавлением", Keywords: "сварные соединения"},
+		{GostNumber: "ГОСТ 6996-66", Title: "Сварные соединения. Методы определения механических свойств", Description: "Испытания сталей и сплавов"},
+		{GostNumber: "ГОСТ 32144-2013", Title: "Электрическая энергия. Нормы качества электрической энергии", Keywords: "электроэнергия"},
+		{GostNumber: "ГОСТ 31986-2012", Title: "Услуги общественного питания. Метод органолептической оценки качества продукции", Keywords: "питание"},
+		{GostNumber: "ГОСТ Р 70000-2022", Title: "Стали конструкционные. Метод оценки свариваемости"},
+		{GostNumber: "ГОСТ 380-2005", Title: "Сталь углеродистая обыкновенного качества. Марки", Keywords: "сталь"},
+	}
+
+	for _, gost := range gosts {
+		if _, err := db.CreateOrUpdateGost(gost); err != nil {
+			t.Fatalf("Failed to seed gost %s: %v", gost.GostNumber, err)
+		}
+	}
+}
+
+func TestStemGostQuery(t *testing.T) {
+	stems := stemGostQuery("Свариваемость сталей и сплавов")
+	if len(stems) != 3 {
+		t.Fatalf("Expected 3 stems (stopword removed), got %v", stems)
+	}
+	if stems[0] != "свариваем" {
+		t.Errorf("Expected stem 'свариваем', got '%s'", stems[0])
+	}
+}
+
+func TestSuggestByText_RanksRelevantFirst(t *testing.T) {
+	db := setupTestGostsDB(t)
+	seedTestGosts(t, db)
+
+	results, err := db.SuggestByText("свариваемость сталей", 10)
+	if err != nil {
+		t.Fatalf("SuggestByText failed: %v", err)
+	}
+	if len(results) == 0 {
+		t.Fatal("Expected suggestions, got none")
+	}
+
+	if results[0].GostNumber != "ГОСТ Р 70000-2022" {
+		t.Errorf("Expected 'ГОСТ Р 70000-2022' first, got '%s'", results[0].GostNumber)
+	}
+
+	positions := make(map[string]int)
+	for i, gost := range results {
+		positions[gost.GostNumber] = i
+		if gost.Score <= 0 || gost.Score > 1 {
+			t.Errorf("Score for %s out of range: %f", gost.GostNumber, gost.Score)
+		}
+		if i > 0 && results[i-1].Score < gost.Score {
+			t.Errorf("Results are not sorted by score at position %d", i)
+		}
+	}
+
+	for _, irrelevant := range []string{"ГОСТ 32144-2013", "ГОСТ 31986-2012"} {
+		if _, found := positions[irrelevant]; found {
+			t.Errorf("Irrelevant standard %s should not be suggested", irrelevant)
+		}
+	}
+}
+
+func TestSuggestByText_MorphologicalVariants(t *testing.T) {
+	db := setupTestGostsDB(t)
+	seedTestGosts(t, db)
+
+	results, err := db.SuggestByText("сварных соединений", 2)
+	if err != nil {
+		t.Fatalf("SuggestByText failed: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 suggestions (limit), got %d", len(results))
+	}
+	for _, gost := range results {
+		if gost.GostNumber != "ГОСТ 26388-84" && gost.GostNumber != "ГОСТ 6996-66" {
+			t.Errorf("Unexpected suggestion %s", gost.GostNumber)
+		}
+	}
+}
+
+func TestSuggestByText_EmptyQuery(t *testing.T) {
+	db := setupTestGostsDB(t)
+	seedTestGosts(t, db)
+
+	results, err := db.SuggestByText("  и в  ", 10)
+	if err != nil {
+		t.Fatalf("SuggestByText failed: %v", err)
+	}
+	if len(results) != 0 {
+		t.Errorf("Expected no suggestions for stopword-only query, got %d", len(results))
+	}
+}
+
+func TestSuggestByText_UpdatedGostReindexed(t *testing.T) {
+	db := setupTestGostsDB(t)
+	seedTestGosts(t, db)
+
+	if _, err := db.CreateOrUpdateGost(&Gost{GostNumber: "ГОСТ 32144-2013", Title: "Трубы стальные сварные"}); err != nil {
+		t.Fatalf("Failed to update gost: %v", err)
+	}
+
+	results, err := db.SuggestByText("электрической энергии", 10)
+	if err != nil {
+		t.Fatalf("SuggestByText failed: %v", err)
+	}
+	for _, gost := range results {
+		if gost.GostNumber == "ГОСТ 32144-2013" {
+			t.Error("Updated gost should not match its old title")
+		}
+	}
+}
+
+func TestDeleteBySource_KeepsOtherSources(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	gosts := []*Gost{
+		{GostNumber: "ГОСТ 1-2000", Title: "Болты", SourceType: "nationalstandards"},
+		{GostNumber: "ГОСТ 2-2000", Title: "Гайки", SourceType: "nationalstandards"},
+		{GostNumber: "ГОСТ 3-2000", Title: "Шайбы", SourceType: "interstatestandards"},
+		{GostNumber: "ГОСТ 4-2000", Title: "Винты", SourceType: "tulist"},
+	}
+	for _, gost := range gosts {
+		if _, err := db.MergeGost(gost, DefaultGostSourcePriority); err != nil {
+			t.Fatalf("Failed to seed gost %s: %v", gost.GostNumber, err)
+		}
+	}
+	removed, err := db.GetGostByNumber("ГОСТ 1-2000")
+	if err != nil {
+		t.Fatalf("GetGostByNumber failed: %v", err)
+	}
+	if _, err := db.conn.Exec(`INSERT INTO gost_documents (gost_id, file_path, file_type, uploaded_at) VALUES (?, '/tmp/gost1.pdf', 'pdf', CURRENT_TIMESTAMP)`, removed.ID); err != nil {
+		t.Fatalf("Failed to add document: %v", err)
+	}
+
+	deleted, err := db.DeleteBySource("nationalstandards")
+	if err != nil {
+		t.Fatalf("DeleteBySource failed: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Expected 2 deleted GOSTs, got %d", deleted)
+	}
+
+	remaining, total, err := db.ListGosts(10, 0, "", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("ListGosts failed: %v", err)
+	}
+	if total != 2 {
+		t.Fatalf("Expected 2 remaining GOSTs, got %d", total)
+	}
+	for _, gost := range remaining {
+		if gost.SourceType == "nationalstandards" {
+			t.Errorf("GOST %s of deleted source remains", gost.GostNumber)
+		}
+	}
+
+	if documents, err := db.GetDocumentsByGostID(removed.ID); err != nil || len(documents) != 0 {
+		t.Errorf("Expected documents of deleted GOST to be removed, got %d (%v)", len(documents), err)
+	}
+	if sources, err := db.GetGostFieldSources(removed.ID); err != nil || len(sources) != 0 {
+		t.Errorf("Expected field sources of deleted GOST to be removed, got %v (%v)", sources, err)
+	}
+
+	// Повторное удаление ничего не находит
+	if deleted, err := db.DeleteBySource("nationalstandards"); err != nil || deleted != 0 {
+		t.Errorf("Expected nothing to delete, got %d (%v)", deleted, err)
+	}
+}
+
+func TestCreateOrUpdateSource_UpdateKeepsID(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	first, err := db.CreateOrUpdateSource(&GostSource{SourceName: "nationalstandards", RecordsCount: 1})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateSource failed: %v", err)
+	}
+	if _, err := db.CreateOrUpdateSource(&GostSource{SourceName: "tulist", RecordsCount: 1}); err != nil {
+		t.Fatalf("CreateOrUpdateSource failed: %v", err)
+	}
+
+	// Повторный импорт источника обновляет существующую запись
+	updated, err := db.CreateOrUpdateSource(&GostSource{SourceName: "nationalstandards", RecordsCount: 5})
+	if err != nil {
+		t.Fatalf("CreateOrUpdateSource on update failed: %v", err)
+	}
+	if updated.ID != first.ID || updated.RecordsCount != 5 {
+		t.Errorf("Expected source %d with 5 records, got %d with %d", first.ID, updated.ID, updated.RecordsCount)
+	}
+}
+
+func TestDeleteBySource_ClearsFieldsOfMergedGosts(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	// Название от tulist, описание от nationalstandards
+	// и название от nationalstandards, статус от tulist
+	merges := []*Gost{
+		{GostNumber: "ГОСТ 5-2000", Title: "Винты", Status: "Действует", SourceType: "tulist"},
+		{GostNumber: "ГОСТ 5-2000", Description: "Винты с потайной головкой", SourceType: "nationalstandards"},
+		{GostNumber: "ГОСТ 6-2000", Title: "Шпильки", Status: "Отменен", SourceType: "tulist"},
+		{GostNumber: "ГОСТ 6-2000", Title: "Шпильки резьбовые", SourceType: "nationalstandards"},
+	}
+	for _, gost := range merges {
+		if _, err := db.MergeGost(gost, DefaultGostSourcePriority); err != nil {
+			t.Fatalf("Failed to merge gost %s: %v", gost.GostNumber, err)
+		}
+	}
+
+	deleted, err := db.DeleteBySource("nationalstandards")
+	if err != nil {
+		t.Fatalf("DeleteBySource failed: %v", err)
+	}
+	if deleted != 0 {
+		t.Errorf("GOSTs with fields of other sources must be kept, got %d deleted", deleted)
+	}
+
+	screws, err := db.GetGostByNumber("ГОСТ 5-2000")
+	if err != nil {
+		t.Fatalf("GetGostByNumber failed: %v", err)
+	}
+	if screws.Title != "Винты" || screws.Status != "Действует" || screws.Description != "" {
+		t.Errorf("Expected only description of deleted source cleared, got %+v", screws)
+	}
+
+	studs, err := db.GetGostByNumber("ГОСТ 6-2000")
+	if err != nil {
+		t.Fatalf("GetGostByNumber failed: %v", err)
+	}
+	if studs.Title != "" || studs.Status != "Отменен" {
+		t.Errorf("Expected title cleared and status kept, got %+v", studs)
+	}
+	if sources, err := db.GetGostFieldSources(studs.ID); err != nil || sources[GostFieldTitle] != "" || sources[GostFieldStatus] != "tulist" {
+		t.Errorf("Expected only tulist field sources left, got %v (%v)", sources, err)
+	}
+
+	// Повторный импорт источника заполняет очищенные поля
+	if _, err := db.MergeGost(merges[3], DefaultGostSourcePriority); err != nil {
+		t.Fatalf("Failed to re-import gost: %v", err)
+	}
+	if studs, err = db.GetGostByNumber("ГОСТ 6-2000"); err != nil || studs.Title != "Шпильки резьбовые" {
+		t.Errorf("Expected title restored on re-import, got %+v (%v)", studs, err)
+	}
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// gostYearSuffix год редакции в конце номера: "ГОСТ 2590-2006", "ГОСТ 2590-88"
+var gostYearSuffix = regexp.MustCompile(`^(.*\S)-(\d{2}|\d{4})$`)
+
+// GostBaseNumber возвращает номер ГОСТа без года редакции в нормализованном виде:
+// "гост  2590-2006" и "2590" дают "ГОСТ 2590"
+func GostBaseNumber(number string) string {
+	base, _ := splitGostNumber(number)
+	return base
+}
+
+// splitGostNumber разбирает номер на базовую часть и год редакции.
+// Двузначный год раскрывается через ExpandGostYear, номер без года возвращает год 0.
+func splitGostNumber(number string) (string, int) {
+	normalized := strings.Join(strings.Fields(strings.ToUpper(number)), " ")
+	if normalized != "" && normalized[0] >= '0' && normalized[0] <= '9' {
+		normalized = "ГОСТ " + normalized
+	}
+
+	match := gostYearSuffix.FindStringSubmatch(normalized)
+	if match == nil {
+		return normalized, 0
+	}
+
+	year, _ := strconv.Atoi(match[2])
+	if len(match[2]) == 2 {
+		year = ExpandGostYear(year)
+	}
+	return match[1], year
+}
+
+// ExpandGostYear переводит двузначный год редакции в четырехзначный: годы не позже текущего
+// относятся к 20xx, остальные к 19xx ("ГОСТ 2590-88" - 1988, "ГОСТ Р 2.901-06" - 2006)
+func ExpandGostYear(year int) int {
+	if year >= 100 {
+		return year
+	}
+	if year <= time.Now().Year()%100 {
+		return 2000 + year
+	}
+	return 1900 + year
+}
+
+// FindByNumberLoose находит все редакции ГОСТа по номеру без учета года:
+// "ГОСТ 2590" находит "ГОСТ 2590-71", "ГОСТ 2590-88" и "ГОСТ 2590-2006",
+// но не "ГОСТ 2590.1-80". Редакции упорядочены от новой к старой.
+func (db *GostsDB) FindByNumberLoose(number string) ([]*Gost, error) {
+	base := GostBaseNumber(number)
+	if base == "" {
+		return []*Gost{}, nil
+	}
+
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(base)
+	query := `
+		SELECT id, gost_number, title, adoption_date, effective_date, status,
+		       source_type, source_id, source_url, description, keywords,
+		       created_at, updated_at
+		FROM gosts
+		WHERE gost_number = ? OR gost_number LIKE ? ESCAPE '\'
+	`
+
+	rows, err := db.conn.Query(query, base, escaped+"-%")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find gosts by base number: %w", err)
+	}
+	defer rows.Close()
+
+	years := make(map[int]int)
+	gosts := []*Gost{}
+	for rows.Next() {
+		gost := &Gost{}
+		var adoptionDate, effectiveDate sql.NullTime
+		var sourceID sql.NullInt64
+		var createdAt sql.NullTime
+
+		err := rows.Scan(
+			&gost.ID, &gost.GostNumber, &gost.Title,
+			&adoptionDate, &effectiveDate,
+			&gost.Status, &gost.SourceType, &sourceID,
+			&gost.SourceURL, &gost.Description, &gost.Keywords,
+			&createdAt, &gost.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan gost: %w", err)
+		}
+
+		if createdAt.Valid {
+			gost.CreatedAt = createdAt.Time
+		} else {
+			gost.CreatedAt = gost.UpdatedAt
+		}
+		if adoptionDate.Valid {
+			gost.AdoptionDate = &adoptionDate.Time
+		}
+		if effectiveDate.Valid {
+			gost.EffectiveDate = &effectiveDate.Time
+		}
+		if sourceID.Valid {
+			id := int(sourceID.Int64)
+			gost.SourceID = &id
+		}
+		db.checkGostEncoding(gost)
+
+		// LIKE лишь отбирает кандидатов: "ГОСТ 2590-1-80" тоже начинается с "ГОСТ 2590-"
+		gostBase, year := splitGostNumber(gost.GostNumber)
+		if gostBase != base {
+			continue
+		}
+		if year == 0 && gost.AdoptionDate != nil {
+			year = gost.AdoptionDate.Year()
+		}
+		years[gost.ID] = year
+		gosts = append(gosts, gost)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate gosts: %w", err)
+	}
+
+	sort.SliceStable(gosts, func(i, j int) bool {
+		if years[gosts[i].ID] != years[gosts[j].ID] {
+			return years[gosts[i].ID] > years[gosts[j].ID]
+		}
+		return gosts[i].ID > gosts[j].ID
+	})
+
+	return gosts, nil
+}
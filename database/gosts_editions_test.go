@@ -0,0 +1,80 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGostBaseNumber(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"ГОСТ 2590-2006", "ГОСТ 2590"},
+		{"ГОСТ 2590-88", "ГОСТ 2590"},
+		{"гост  2590", "ГОСТ 2590"},
+		{"2590-2006", "ГОСТ 2590"},
+		{"ГОСТ 2590.1-80", "ГОСТ 2590.1"},
+		{"ГОСТ 12.1.004-91", "ГОСТ 12.1.004"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := GostBaseNumber(tt.input); got != tt.want {
+			t.Errorf("GostBaseNumber(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestExpandGostYear(t *testing.T) {
+	current := time.Now().Year() % 100
+	tests := []struct {
+		year int
+		want int
+	}{
+		{88, 1988},
+		{6, 2006},
+		{current, 2000 + current},
+		{current + 1, 1900 + current + 1},
+		{2006, 2006},
+	}
+	for _, tt := range tests {
+		if got := ExpandGostYear(tt.year); got != tt.want {
+			t.Errorf("ExpandGostYear(%d) = %d, want %d", tt.year, got, tt.want)
+		}
+	}
+}
+
+func TestFindByNumberLoose_ReturnsEditionsNewestFirst(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	for _, number := range []string{"ГОСТ 2590-88", "ГОСТ 2590-2006", "ГОСТ 2590-71", "ГОСТ 2590.1-80", "ГОСТ 25900-2015"} {
+		if _, err := db.CreateOrUpdateGost(&Gost{GostNumber: number, Title: "Прокат стальной", Status: "Действует", SourceType: "test"}); err != nil {
+			t.Fatalf("CreateOrUpdateGost(%q) failed: %v", number, err)
+		}
+	}
+
+	want := []string{"ГОСТ 2590-2006", "ГОСТ 2590-88", "ГОСТ 2590-71"}
+	for _, query := range []string{"ГОСТ 2590", "2590", "ГОСТ 2590-88"} {
+		gosts, err := db.FindByNumberLoose(query)
+		if err != nil {
+			t.Fatalf("FindByNumberLoose(%q) failed: %v", query, err)
+		}
+		if len(gosts) != len(want) {
+			t.Fatalf("FindByNumberLoose(%q) returned %d editions, want %d", query, len(gosts), len(want))
+		}
+		for i, gost := range gosts {
+			if gost.GostNumber != want[i] {
+				t.Errorf("FindByNumberLoose(%q)[%d] = %q, want %q", query, i, gost.GostNumber, want[i])
+			}
+		}
+	}
+
+	gosts, err := db.FindByNumberLoose("ГОСТ 3000")
+	if err != nil {
+		t.Fatalf("FindByNumberLoose failed: %v", err)
+	}
+	if len(gosts) != 0 {
+		t.Errorf("Expected no editions for unknown number, got %d", len(gosts))
+	}
+}
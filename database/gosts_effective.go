@@ -0,0 +1,121 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gostWithdrawnStatuses статусы, означающие, что стандарт отменен или заменен
+var gostWithdrawnStatuses = map[string]bool{
+	"отменен":    true,
+	"заменен":    true,
+	"cancelled":  true,
+	"replaced":   true,
+	"deprecated": true,
+}
+
+// gostWithdrawnStatusVariants возвращает статусы отмены в вариантах регистра, в которых они
+// встречаются в данных ("отменен", "Отменен", "ОТМЕНЕН"): lower() в SQLite не работает с кириллицей,
+// поэтому статус сравнивается в SQL с готовым списком
+func gostWithdrawnStatusVariants() []string {
+	seen := make(map[string]bool)
+	var variants []string
+	for status := range gostWithdrawnStatuses {
+		runes := []rune(status)
+		capitalized := strings.ToUpper(string(runes[0])) + string(runes[1:])
+		for _, variant := range []string{status, capitalized, strings.ToUpper(status)} {
+			if !seen[variant] {
+				seen[variant] = true
+				variants = append(variants, variant)
+			}
+		}
+	}
+	sort.Strings(variants)
+	return variants
+}
+
+// ListEffectiveAsOf возвращает ГОСТы, действовавшие на указанную дату: дата вступления в силу
+// не позже date, а дата отмены (если есть) позже date. ГОСТы без даты вступления в силу
+// исключаются, как и отмененные ГОСТы без даты отмены - момент отмены для них неизвестен.
+// limit и offset применяются в SQL; limit <= 0 - без ограничения.
+func (db *GostsDB) ListEffectiveAsOf(date time.Time, limit, offset int) ([]*Gost, int, error) {
+	day := gostDay(date).Format("2006-01-02")
+
+	statuses := gostWithdrawnStatusVariants()
+	where := `
+		WHERE effective_date IS NOT NULL AND date(effective_date) <= date(?)
+		  AND (withdrawal_date IS NULL OR date(withdrawal_date) > date(?))
+		  AND NOT (withdrawal_date IS NULL AND trim(COALESCE(status, '')) IN (?` + strings.Repeat(", ?", len(statuses)-1) + `))`
+	args := []interface{}{day, day}
+	for _, status := range statuses {
+		args = append(args, status)
+	}
+
+	var total int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM gosts`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count effective gosts: %w", err)
+	}
+
+	if limit <= 0 {
+		limit = -1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	rows, err := db.conn.Query(`
+		SELECT id, gost_number, title, adoption_date, effective_date, withdrawal_date, status,
+		       source_type, source_id, source_url, description, keywords,
+		       created_at, updated_at
+		FROM gosts`+where+`
+		ORDER BY gost_number
+		LIMIT ? OFFSET ?
+	`, append(args, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list effective gosts: %w", err)
+	}
+	defer rows.Close()
+
+	gosts := []*Gost{}
+	for rows.Next() {
+		gost := &Gost{}
+		var adoptionDate, effectiveDate, withdrawalDate, createdAt sql.NullTime
+		var sourceID sql.NullInt64
+
+		if err := rows.Scan(
+			&gost.ID, &gost.GostNumber, &gost.Title,
+			&adoptionDate, &effectiveDate, &withdrawalDate,
+			&gost.Status, &gost.SourceType, &sourceID,
+			&gost.SourceURL, &gost.Description, &gost.Keywords,
+			&createdAt, &gost.UpdatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan gost: %w", err)
+		}
+
+		if createdAt.Valid {
+			gost.CreatedAt = createdAt.Time
+		} else {
+			gost.CreatedAt = gost.UpdatedAt
+		}
+		if adoptionDate.Valid {
+			gost.AdoptionDate = &adoptionDate.Time
+		}
+		gost.EffectiveDate = &effectiveDate.Time
+		if withdrawalDate.Valid {
+			gost.WithdrawalDate = &withdrawalDate.Time
+		}
+		if sourceID.Valid {
+			id := int(sourceID.Int64)
+			gost.SourceID = &id
+		}
+
+		gosts = append(gosts, gost)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate effective gosts: %w", err)
+	}
+
+	return gosts, total, nil
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestListEffectiveAsOf(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	date := func(value string) *time.Time {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			t.Fatalf("Failed to parse date %s: %v", value, err)
+		}
+		return &parsed
+	}
+
+	gosts := []*Gost{
+		{GostNumber: "ГОСТ 1-2000", Title: "Действует давно", Status: "действующий", EffectiveDate: date("2000-01-01")},
+		{GostNumber: "ГОСТ 2-2020", Title: "Вступает в дату среза", Status: "действующий", EffectiveDate: date("2020-06-01")},
+		{GostNumber: "ГОСТ 3-2021", Title: "Вступит позже", Status: "действующий", EffectiveDate: date("2021-01-01")},
+		{GostNumber: "ГОСТ 4-2000", Title: "Отменен после среза", Status: "отменен", EffectiveDate: date("2000-01-01"), WithdrawalDate: date("2020-12-31")},
+		{GostNumber: "ГОСТ 5-2000", Title: "Отменен до среза", Status: "отменен", EffectiveDate: date("2000-01-01"), WithdrawalDate: date("2019-01-01")},
+		{GostNumber: "ГОСТ 6-2000", Title: "Отменен в дату среза", Status: "заменен", EffectiveDate: date("2000-01-01"), WithdrawalDate: date("2020-06-01")},
+		{GostNumber: "ГОСТ 7-2000", Title: "Отменен без даты отмены", Status: "Отменен", EffectiveDate: date("2000-01-01")},
+		{GostNumber: "ГОСТ 8-2000", Title: "Без даты вступления", Status: "действующий"},
+		{GostNumber: "ГОСТ 9-2000", Title: "Без статуса", EffectiveDate: date("2010-01-01")},
+	}
+	for _, gost := range gosts {
+		if _, err := db.CreateOrUpdateGost(gost); err != nil {
+			t.Fatalf("Failed to seed gost %s: %v", gost.GostNumber, err)
+		}
+	}
+
+	numbers := func(gosts []*Gost) []string {
+		result := make([]string, len(gosts))
+		for i, gost := range gosts {
+			result[i] = gost.GostNumber
+		}
+		return result
+	}
+
+	result, total, err := db.ListEffectiveAsOf(*date("2020-06-01"), 50, 0)
+	if err != nil {
+		t.Fatalf("ListEffectiveAsOf failed: %v", err)
+	}
+	want := []string{"ГОСТ 1-2000", "ГОСТ 2-2020", "ГОСТ 4-2000", "ГОСТ 9-2000"}
+	if got := numbers(result); !reflect.DeepEqual(got, want) {
+		t.Errorf("ListEffectiveAsOf = %v, want %v", got, want)
+	}
+	if total != len(want) {
+		t.Errorf("Expected total %d, got %d", len(want), total)
+	}
+	if result[2].WithdrawalDate == nil {
+		t.Error("Expected withdrawal date to be filled")
+	}
+
+	page, total, err := db.ListEffectiveAsOf(*date("2020-06-01"), 2, 1)
+	if err != nil {
+		t.Fatalf("ListEffectiveAsOf failed: %v", err)
+	}
+	if got := numbers(page); !reflect.DeepEqual(got, want[1:3]) || total != len(want) {
+		t.Errorf("Page = %v (total %d), want %v (total %d)", got, total, want[1:3], len(want))
+	}
+
+	beyond, total, err := db.ListEffectiveAsOf(*date("2020-06-01"), 2, 10)
+	if err != nil {
+		t.Fatalf("ListEffectiveAsOf failed: %v", err)
+	}
+	if beyond == nil || len(beyond) != 0 || total != len(want) {
+		t.Errorf("Expected an empty page past the end with total %d, got %v (total %d)", len(want), numbers(beyond), total)
+	}
+
+	empty, total, err := db.ListEffectiveAsOf(*date("1999-01-01"), 50, 0)
+	if err != nil {
+		t.Fatalf("ListEffectiveAsOf failed: %v", err)
+	}
+	if len(empty) != 0 || total != 0 {
+		t.Errorf("Expected no effective gosts in 1999, got %v", numbers(empty))
+	}
+}
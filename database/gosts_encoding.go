@@ -0,0 +1,47 @@
+package database
+
+// GostEncodingOptions настройки проверки кодировки текстовых полей ГОСТов при чтении.
+// Даже после cmd/fix_encoding новые импорты могут снова записать "кракозябры",
+// поэтому GostsDB может проверять UTF-8 при каждом чтении.
+type GostEncodingOptions struct {
+	// Validate включает проверку номера, названия, статуса, описания и ключевых слов
+	Validate bool
+	// StrictEncoding не исправляет текст, а только помечает строку флагом Gost.EncodingIssue.
+	// Без него испорченные поля восстанавливаются через RepairMojibake.
+	StrictEncoding bool
+}
+
+// SetEncodingOptions задает проверку кодировки при чтении ГОСТов.
+// Вызывается при инициализации, до начала работы с базой.
+func (db *GostsDB) SetEncodingOptions(opts GostEncodingOptions) {
+	db.encoding = opts
+}
+
+// checkGostEncoding проверяет текстовые поля прочитанного ГОСТа согласно настройкам.
+// Возвращает true, если хотя бы одно поле было исправлено.
+func (db *GostsDB) checkGostEncoding(gost *Gost) bool {
+	if !db.encoding.Validate {
+		return false
+	}
+
+	fields := []*string{&gost.GostNumber, &gost.Title, &gost.Status, &gost.Description, &gost.Keywords}
+
+	if db.encoding.StrictEncoding {
+		for _, field := range fields {
+			if HasEncodingIssue(*field) {
+				gost.EncodingIssue = true
+				break
+			}
+		}
+		return false
+	}
+
+	repaired := false
+	for _, field := range fields {
+		if fixed, ok := RepairMojibake(*field); ok {
+			*field = fixed
+			repaired = true
+		}
+	}
+	return repaired
+}
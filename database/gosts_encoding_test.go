@@ -0,0 +1,122 @@
+package database
+
+import (
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// mojibake портит UTF-8 текст так, как это делает чтение через однобайтовую кодировку
+func mojibake(t *testing.T, cm *charmap.Charmap, text string) string {
+	t.Helper()
+	broken, err := cm.NewDecoder().String(text)
+	if err != nil {
+		t.Fatalf("Failed to build mojibake: %v", err)
+	}
+	return broken
+}
+
+func TestRepairMojibake(t *testing.T) {
+	raw1251, err := charmap.Windows1251.NewEncoder().String("Болты с шестигранной головкой")
+	if err != nil {
+		t.Fatalf("Failed to encode: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+		fixed bool
+	}{
+		{"cp866", mojibake(t, charmap.CodePage866, "ГОСТ 7798-70"), "ГОСТ 7798-70", true},
+		{"windows-1251", mojibake(t, charmap.Windows1251, "Действует"), "Действует", true},
+		{"double", mojibake(t, charmap.CodePage866, mojibake(t, charmap.CodePage866, "ГОСТ Р 52-2000")), "ГОСТ Р 52-2000", true},
+		{"invalid utf-8", raw1251, "Болты с шестигранной головкой", true},
+		{"cp866 literal", "╨У╨Ю╨б╨в 7798-70 ╨С╨╛╨╗╤В╤Л", "ГОСТ 7798-70 Болты", true},
+		{"windows-1251 literal", "РЎС‚Р°РЅРґР°СЂС‚ РґРµР№СЃС‚РІСѓРµС‚", "Стандарт действует", true},
+		{"windows-1251 title", "РўСЂСѓР±С‹ СЃС‚Р°Р»СЊРЅС‹Рµ", "Трубы стальные", true},
+		{"double cp866 literal", "тХи╨гтХи╨отХи╨▒тХи╨▓ тХи╨░ 52-2000", "ГОСТ Р 52-2000", true},
+		{"raw windows-1251 bytes", "\xc3\xce\xd1\xd2 12345-2020", "ГОСТ 12345-2020", true},
+		{"correct cyrillic", "Трубы стальные ёмкостные", "Трубы стальные ёмкостные", false},
+		{"box drawing kept", "Таблица ╨ 1", "Таблица ╨ 1", false},
+		{"ascii", "ISO 4014", "ISO 4014", false},
+		{"latin", "ISO 4014 Hexagon head bolts", "ISO 4014 Hexagon head bolts", false},
+		{"empty", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fixed := RepairMojibake(tt.input)
+			if got != tt.want || fixed != tt.fixed {
+				t.Errorf("RepairMojibake(%q) = %q, %v; want %q, %v", tt.input, got, fixed, tt.want, tt.fixed)
+			}
+			if HasEncodingIssue(tt.input) != tt.fixed {
+				t.Errorf("HasEncodingIssue(%q) = %v, want %v", tt.input, !tt.fixed, tt.fixed)
+			}
+		})
+	}
+}
+
+func TestGostsDB_EncodingOptions(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	if _, err := db.CreateOrUpdateGost(&Gost{GostNumber: "ГОСТ 1-2000", Title: "Болты", Status: "Действует", SourceType: "test"}); err != nil {
+		t.Fatalf("CreateOrUpdateGost failed: %v", err)
+	}
+	_, err := db.conn.Exec(`
+		INSERT INTO gosts (gost_number, title, status, source_type, source_url, description, keywords, updated_at)
+		VALUES (?, ?, ?, 'test', '', '', '', CURRENT_TIMESTAMP)
+	`, "ГОСТ 2-2000", mojibake(t, charmap.CodePage866, "Гайки шестигранные"), mojibake(t, charmap.Windows1251, "Действует"))
+	if err != nil {
+		t.Fatalf("Failed to insert mojibake row: %v", err)
+	}
+
+	list := func() map[string]*Gost {
+		t.Helper()
+		gosts, total, err := db.ListGosts(10, 0, "", "", "", "", "", "")
+		if err != nil {
+			t.Fatalf("ListGosts failed: %v", err)
+		}
+		if total != 2 {
+			t.Fatalf("Expected 2 GOSTs, got %d", total)
+		}
+		byNumber := make(map[string]*Gost)
+		for _, gost := range gosts {
+			byNumber[gost.GostNumber] = gost
+		}
+		return byNumber
+	}
+
+	// По умолчанию проверка выключена, данные возвращаются как есть
+	if gost := list()["ГОСТ 2-2000"]; gost.Title == "Гайки шестигранные" || gost.EncodingIssue {
+		t.Errorf("Expected raw row without validation, got %+v", gost)
+	}
+
+	// Строгий режим: строка помечается, текст не меняется
+	db.SetEncodingOptions(GostEncodingOptions{Validate: true, StrictEncoding: true})
+	gosts := list()
+	if gost := gosts["ГОСТ 2-2000"]; !gost.EncodingIssue || gost.Title == "Гайки шестигранные" {
+		t.Errorf("Expected flagged unrepaired row in strict mode, got %+v", gost)
+	}
+	if gosts["ГОСТ 1-2000"].EncodingIssue {
+		t.Error("Correct row must not be flagged")
+	}
+
+	// Автоисправление через общий декодер
+	db.SetEncodingOptions(GostEncodingOptions{Validate: true})
+	gosts = list()
+	if gost := gosts["ГОСТ 2-2000"]; gost.Title != "Гайки шестигранные" || gost.Status != "Действует" || gost.EncodingIssue {
+		t.Errorf("Expected repaired row, got %+v", gost)
+	}
+	if gost := gosts["ГОСТ 1-2000"]; gost.Title != "Болты" {
+		t.Errorf("Correct row must not change, got %+v", gost)
+	}
+
+	gost, err := db.GetGostByNumber("ГОСТ 2-2000")
+	if err != nil {
+		t.Fatalf("GetGostByNumber failed: %v", err)
+	}
+	if gost.Title != "Гайки шестигранные" {
+		t.Errorf("Expected GetGostByNumber to repair title, got %q", gost.Title)
+	}
+}
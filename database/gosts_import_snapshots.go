@@ -0,0 +1,189 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+)
+
+// gostImportSnapshotsKept сколько последних снимков импорта хранится в базе
+const gostImportSnapshotsKept = 10
+
+// GostImportSnapshot снимок состава ГОСТов после импорта: количество и хеш статуса каждого номера
+type GostImportSnapshot struct {
+	ID           int               `json:"id"`
+	GostCount    int               `json:"gost_count"`
+	StatusHashes map[string]string `json:"-"` // номер ГОСТа -> хеш статуса
+	CreatedAt    time.Time         `json:"created_at"`
+}
+
+// GostImportDiff изменения состава ГОСТов относительно предыдущего снимка.
+// Выборки содержат не больше sampleSize номеров в алфавитном порядке.
+type GostImportDiff struct {
+	SnapshotID         int        `json:"snapshot_id"`
+	PreviousSnapshotID int        `json:"previous_snapshot_id,omitempty"`
+	PreviousTakenAt    *time.Time `json:"previous_taken_at,omitempty"`
+	PreviousCount      int        `json:"previous_count"`
+	CurrentCount       int        `json:"current_count"`
+	Added              int        `json:"added"`
+	Changed            int        `json:"changed"` // изменился статус (в том числе отмена стандарта)
+	Removed            int        `json:"removed"`
+	AddedSample        []string   `json:"added_sample"`
+	ChangedSample      []string   `json:"changed_sample"`
+	RemovedSample      []string   `json:"removed_sample"`
+}
+
+// MigrateGostImportSnapshots создает таблицу снимков импорта ГОСТов
+func MigrateGostImportSnapshots(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS gost_import_snapshots (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			gost_count INTEGER NOT NULL,
+			status_hashes TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create gost_import_snapshots table: %w", err)
+	}
+	return nil
+}
+
+// gostStatusHash возвращает короткий хеш статуса ГОСТа (регистр и пробелы по краям не учитываются)
+func gostStatusHash(status string) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(status))))
+	return fmt.Sprintf("%08x", h.Sum32())
+}
+
+// GetLatestGostImportSnapshot возвращает последний снимок импорта или nil, если снимков нет
+func (db *GostsDB) GetLatestGostImportSnapshot() (*GostImportSnapshot, error) {
+	var (
+		snapshot GostImportSnapshot
+		hashes   string
+	)
+	err := db.conn.QueryRow(`
+		SELECT id, gost_count, status_hashes, created_at
+		FROM gost_import_snapshots
+		ORDER BY id DESC
+		LIMIT 1
+	`).Scan(&snapshot.ID, &snapshot.GostCount, &hashes, &snapshot.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest gost import snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal([]byte(hashes), &snapshot.StatusHashes); err != nil {
+		return nil, fmt.Errorf("failed to decode gost import snapshot %d: %w", snapshot.ID, err)
+	}
+
+	return &snapshot, nil
+}
+
+// RecordGostImportSnapshot сохраняет снимок текущего состава ГОСТов и возвращает изменения
+// относительно предыдущего снимка. При первом вызове все ГОСТы считаются добавленными.
+// Хранятся только gostImportSnapshotsKept последних снимков.
+func (db *GostsDB) RecordGostImportSnapshot(sampleSize int) (*GostImportDiff, error) {
+	previous, err := db.GetLatestGostImportSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.conn.Query(`SELECT gost_number, COALESCE(status, '') FROM gosts`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gosts for snapshot: %w", err)
+	}
+	current := make(map[string]string)
+	for rows.Next() {
+		var number, status string
+		if err := rows.Scan(&number, &status); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan gost for snapshot: %w", err)
+		}
+		current[number] = gostStatusHash(status)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate gosts for snapshot: %w", err)
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode gost import snapshot: %w", err)
+	}
+
+	result, err := db.conn.Exec(`INSERT INTO gost_import_snapshots (gost_count, status_hashes) VALUES (?, ?)`, len(current), string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save gost import snapshot: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gost import snapshot id: %w", err)
+	}
+
+	if _, err := db.conn.Exec(`DELETE FROM gost_import_snapshots WHERE id <= ?`, id-gostImportSnapshotsKept); err != nil {
+		return nil, fmt.Errorf("failed to prune gost import snapshots: %w", err)
+	}
+
+	var previousHashes map[string]string
+	if previous != nil {
+		previousHashes = previous.StatusHashes
+	}
+	diff := DiffGostStatusHashes(previousHashes, current, sampleSize)
+	diff.SnapshotID = int(id)
+	if previous != nil {
+		diff.PreviousSnapshotID = previous.ID
+		diff.PreviousTakenAt = &previous.CreatedAt
+	}
+
+	return &diff, nil
+}
+
+// DiffGostStatusHashes сравнивает два снимка (номер ГОСТа -> хеш статуса)
+func DiffGostStatusHashes(previous, current map[string]string, sampleSize int) GostImportDiff {
+	var added, changed, removed []string
+	for number, hash := range current {
+		previousHash, ok := previous[number]
+		switch {
+		case !ok:
+			added = append(added, number)
+		case previousHash != hash:
+			changed = append(changed, number)
+		}
+	}
+	for number := range previous {
+		if _, ok := current[number]; !ok {
+			removed = append(removed, number)
+		}
+	}
+
+	return GostImportDiff{
+		PreviousCount: len(previous),
+		CurrentCount:  len(current),
+		Added:         len(added),
+		Changed:       len(changed),
+		Removed:       len(removed),
+		AddedSample:   gostDiffSample(added, sampleSize),
+		ChangedSample: gostDiffSample(changed, sampleSize),
+		RemovedSample: gostDiffSample(removed, sampleSize),
+	}
+}
+
+// gostDiffSample сортирует номера и оставляет не больше size первых
+func gostDiffSample(numbers []string, size int) []string {
+	sort.Strings(numbers)
+	if size >= 0 && len(numbers) > size {
+		numbers = numbers[:size]
+	}
+	if numbers == nil {
+		numbers = []string{}
+	}
+	return numbers
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GostKeywordsGenerator формирует ключевые слова ГОСТа по названию и описанию (importer.GenerateKeywords)
+type GostKeywordsGenerator func(title, description string) string
+
+// RegenerateGostKeywords заполняет keywords ГОСТов сгенерированными ключевыми словами.
+// Без force обрабатываются только ГОСТы с пустыми keywords, ключевые слова источника сохраняются.
+// Возвращает количество обновленных записей.
+func (db *GostsDB) RegenerateGostKeywords(generate GostKeywordsGenerator, force bool) (int, error) {
+	if generate == nil {
+		return 0, fmt.Errorf("keywords generator is nil")
+	}
+
+	query := `SELECT id, title, description FROM gosts WHERE keywords IS NULL OR TRIM(keywords) = ''`
+	if force {
+		query = `SELECT id, title, description FROM gosts`
+	}
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query gosts for keywords: %w", err)
+	}
+
+	type gostKeywords struct {
+		id       int
+		keywords string
+	}
+	var updates []gostKeywords
+	for rows.Next() {
+		var id int
+		var title, description sql.NullString
+		if err := rows.Scan(&id, &title, &description); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan gost for keywords: %w", err)
+		}
+		if keywords := generate(title.String, description.String); keywords != "" {
+			updates = append(updates, gostKeywords{id: id, keywords: keywords})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, fmt.Errorf("failed to iterate gosts for keywords: %w", err)
+	}
+	rows.Close()
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`UPDATE gosts SET keywords = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare keywords update: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, update := range updates {
+		if _, err := stmt.Exec(update.keywords, update.id); err != nil {
+			return 0, fmt.Errorf("failed to update keywords of gost %d: %w", update.id, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit keywords update: %w", err)
+	}
+
+	return len(updates), nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegenerateGostKeywords(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	gosts := []*Gost{
+		{GostNumber: "ГОСТ 1-2000", Title: "Трубы стальные"},
+		{GostNumber: "ГОСТ 2-2000", Title: "Кабели силовые", Keywords: "кабель, провод"},
+	}
+	for _, gost := range gosts {
+		if _, err := db.CreateOrUpdateGost(gost); err != nil {
+			t.Fatalf("Failed to seed gost %s: %v", gost.GostNumber, err)
+		}
+	}
+
+	generate := func(title, description string) string {
+		return strings.ToLower(title)
+	}
+
+	updated, err := db.RegenerateGostKeywords(generate, false)
+	if err != nil {
+		t.Fatalf("RegenerateGostKeywords failed: %v", err)
+	}
+	if updated != 1 {
+		t.Errorf("Expected 1 updated gost, got %d", updated)
+	}
+
+	gost, err := db.GetGostByNumber("ГОСТ 1-2000")
+	if err != nil {
+		t.Fatalf("GetGostByNumber failed: %v", err)
+	}
+	if gost.Keywords != "трубы стальные" {
+		t.Errorf("Expected generated keywords, got %q", gost.Keywords)
+	}
+
+	gost, err = db.GetGostByNumber("ГОСТ 2-2000")
+	if err != nil {
+		t.Fatalf("GetGostByNumber failed: %v", err)
+	}
+	if gost.Keywords != "кабель, провод" {
+		t.Errorf("Source keywords should be kept without force, got %q", gost.Keywords)
+	}
+
+	updated, err = db.RegenerateGostKeywords(generate, true)
+	if err != nil {
+		t.Fatalf("RegenerateGostKeywords with force failed: %v", err)
+	}
+	if updated != 2 {
+		t.Errorf("Expected 2 updated gosts with force, got %d", updated)
+	}
+}
@@ -120,6 +120,68 @@ func migrateGostSourcesTimestamps(db *sql.DB) error {
 	return nil
 }
 
+// MigrateGostsFTS создает полнотекстовый индекс gosts_fts (FTS4) над таблицей gosts
+// и триггеры для его синхронизации. Для существующих баз индекс перестраивается
+// из текущих данных при первом создании.
+func MigrateGostsFTS(db *sql.DB) error {
+	var ftsExists bool
+	err := db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM sqlite_master
+			WHERE type='table' AND name='gosts_fts'
+		)
+	`).Scan(&ftsExists)
+	if err != nil {
+		return fmt.Errorf("failed to check gosts_fts existence: %w", err)
+	}
+
+	migrations := []string{
+		`CREATE VIRTUAL TABLE IF NOT EXISTS gosts_fts USING fts4(
+			content="gosts", gost_number, title, keywords, description, tokenize=unicode61
+		)`,
+		`CREATE TRIGGER IF NOT EXISTS gosts_fts_bu BEFORE UPDATE ON gosts BEGIN
+			DELETE FROM gosts_fts WHERE docid = old.rowid;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS gosts_fts_bd BEFORE DELETE ON gosts BEGIN
+			DELETE FROM gosts_fts WHERE docid = old.rowid;
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS gosts_fts_au AFTER UPDATE ON gosts BEGIN
+			INSERT INTO gosts_fts(docid, gost_number, title, keywords, description)
+			VALUES (new.rowid, new.gost_number, new.title, new.keywords, new.description);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS gosts_fts_ai AFTER INSERT ON gosts BEGIN
+			INSERT INTO gosts_fts(docid, gost_number, title, keywords, description)
+			VALUES (new.rowid, new.gost_number, new.title, new.keywords, new.description);
+		END`,
+	}
+
+	for _, migration := range migrations {
+		if _, err := db.Exec(migration); err != nil {
+			return fmt.Errorf("migration failed: %s, error: %w", migration, err)
+		}
+	}
+
+	if !ftsExists {
+		if _, err := db.Exec(`INSERT INTO gosts_fts(gosts_fts) VALUES('rebuild')`); err != nil {
+			return fmt.Errorf("failed to rebuild gosts_fts: %w", err)
+		}
+		log.Println("Created gosts_fts full-text index")
+	}
+
+	return nil
+}
+
+// MigrateGostsWithdrawalDate добавляет в таблицу gosts дату отмены стандарта
+func MigrateGostsWithdrawalDate(db *sql.DB) error {
+	if _, err := db.Exec(`ALTER TABLE gosts ADD COLUMN withdrawal_date DATE`); err != nil {
+		errStr := strings.ToLower(err.Error())
+		if !strings.Contains(errStr, "duplicate column") && !strings.Contains(errStr, "already exists") {
+			return fmt.Errorf("failed to add withdrawal_date column: %w", err)
+		}
+	}
+	return nil
+}
+
 // MigrateGostsSchema выполняет все миграции для таблиц ГОСТов
 func MigrateGostsSchema(db *sql.DB) error {
 	// Выполняем миграцию для source_id
@@ -127,6 +189,36 @@ func MigrateGostsSchema(db *sql.DB) error {
 		return fmt.Errorf("failed to migrate gosts source_id: %w", err)
 	}
 
+	// Полнотекстовый индекс для подсказок по названию
+	if err := MigrateGostsFTS(db); err != nil {
+		return fmt.Errorf("failed to migrate gosts fts: %w", err)
+	}
+
+	// Настраиваемый список источников для import_gosts -all
+	if err := MigrateGostImportSources(db); err != nil {
+		return fmt.Errorf("failed to migrate gost import sources: %w", err)
+	}
+
+	// Дата отмены для проверки согласованности статусов (FindInconsistentGosts)
+	if err := MigrateGostsWithdrawalDate(db); err != nil {
+		return fmt.Errorf("failed to migrate gosts withdrawal_date: %w", err)
+	}
+
+	// Источники значений полей при слиянии нескольких источников (MergeGost)
+	if err := MigrateGostFieldSources(db); err != nil {
+		return fmt.Errorf("failed to migrate gost field sources: %w", err)
+	}
+
+	// Снимки состава ГОСТов для сравнения результатов импорта import_gosts -all
+	if err := MigrateGostImportSnapshots(db); err != nil {
+		return fmt.Errorf("failed to migrate gost import snapshots: %w", err)
+	}
+
+	// Хэш содержимого строки для пропуска неизменившихся ГОСТов при повторном импорте (UpsertGost)
+	if err := MigrateGostsRowHash(db); err != nil {
+		return fmt.Errorf("failed to migrate gosts row_hash: %w", err)
+	}
+
 	return nil
 }
 
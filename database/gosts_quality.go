@@ -0,0 +1,100 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// gostNumberPattern допустимый формат номера: "ГОСТ 2590-2006", "ГОСТ Р 7.0.0-2024",
+// "ГОСТ Р ИСО 9001-2015", "ГОСТ 2590-88"
+var gostNumberPattern = regexp.MustCompile(`(?i)^ГОСТ(\s+Р)?(\s+(ИСО|МЭК|IEC|ISO)(/(МЭК|IEC))?)?\s+\d+(\.\d+)*\s*-\s*(\d{2}|\d{4})$`)
+
+// GostQualityReport сводка по качеству данных ГОСТов перед публикацией
+type GostQualityReport struct {
+	Total                 int            `json:"total"`
+	MissingTitle          int            `json:"missing_title"`
+	MissingAdoptionDate   int            `json:"missing_adoption_date"`
+	MissingEffectiveDate  int            `json:"missing_effective_date"`
+	InvalidNumber         int            `json:"invalid_number"`
+	EncodingIssues        int            `json:"encoding_issues"`
+	Inconsistent          int            `json:"inconsistent"`
+	InconsistenciesByRule map[string]int `json:"inconsistencies_by_rule"`
+	GeneratedAt           time.Time      `json:"generated_at"`
+}
+
+// IsValidGostNumber проверяет формат номера ГОСТа (с годом редакции)
+func IsValidGostNumber(number string) bool {
+	return gostNumberPattern.MatchString(strings.TrimSpace(number))
+}
+
+// QualityReport собирает проверки качества данных ГОСТов в один отчет.
+// Кодировка проверяется по сырым данным, независимо от SetEncodingOptions;
+// противоречия статуса и дат считаются через FindInconsistentGosts.
+func (db *GostsDB) QualityReport() (*GostQualityReport, error) {
+	rows, err := db.conn.Query(`
+		SELECT gost_number, title, adoption_date, effective_date, status, description, keywords
+		FROM gosts
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gosts for quality report: %w", err)
+	}
+	defer rows.Close()
+
+	report := &GostQualityReport{
+		InconsistenciesByRule: map[string]int{
+			GostRuleActiveNotYetEffective: 0,
+			GostRuleActiveWithdrawn:       0,
+		},
+		GeneratedAt: time.Now(),
+	}
+
+	for rows.Next() {
+		var (
+			number, title, status, description, keywords sql.NullString
+			adoptionDate, effectiveDate                  sql.NullTime
+		)
+		if err := rows.Scan(&number, &title, &adoptionDate, &effectiveDate, &status, &description, &keywords); err != nil {
+			return nil, fmt.Errorf("failed to scan gost for quality report: %w", err)
+		}
+
+		report.Total++
+		if strings.TrimSpace(title.String) == "" {
+			report.MissingTitle++
+		}
+		if !adoptionDate.Valid {
+			report.MissingAdoptionDate++
+		}
+		if !effectiveDate.Valid {
+			report.MissingEffectiveDate++
+		}
+		if !IsValidGostNumber(number.String) {
+			report.InvalidNumber++
+		}
+		for _, field := range []string{number.String, title.String, status.String, description.String, keywords.String} {
+			if HasEncodingIssue(field) {
+				report.EncodingIssues++
+				break
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate gosts for quality report: %w", err)
+	}
+
+	inconsistencies, err := db.FindInconsistentGosts()
+	if err != nil {
+		return nil, err
+	}
+	// ГОСТ, нарушающий оба правила, считается в Inconsistent один раз
+	inconsistentIDs := make(map[int]bool)
+	for _, item := range inconsistencies {
+		report.InconsistenciesByRule[item.Rule]++
+		inconsistentIDs[item.GostID] = true
+	}
+	report.Inconsistent = len(inconsistentIDs)
+
+	return report, nil
+}
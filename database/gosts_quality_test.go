@@ -0,0 +1,87 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func TestQualityReport_CountsEachDefect(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	now := time.Now()
+	past := now.AddDate(-1, 0, 0)
+	future := now.AddDate(0, 1, 0)
+
+	gosts := []*Gost{
+		// Корректная запись
+		{GostNumber: "ГОСТ 2590-2006", Title: "Прокат сортовой", Status: "действующий", AdoptionDate: &past, EffectiveDate: &past},
+		// Без названия
+		{GostNumber: "ГОСТ 1-2000", Status: "действующий", AdoptionDate: &past, EffectiveDate: &past},
+		// Без дат
+		{GostNumber: "ГОСТ 2-2000", Title: "Без дат", Status: "отменен"},
+		// Неверный номер
+		{GostNumber: "ГОСТ без номера", Title: "Неверный номер", Status: "отменен", AdoptionDate: &past, EffectiveDate: &past},
+		// Противоречие: действующий, но отменен и еще не вступил в силу
+		{GostNumber: "ГОСТ 3-2030", Title: "Противоречие", Status: "действующий", AdoptionDate: &past, EffectiveDate: &future, WithdrawalDate: &past},
+	}
+	for _, gost := range gosts {
+		if _, err := db.CreateOrUpdateGost(gost); err != nil {
+			t.Fatalf("Failed to seed gost %s: %v", gost.GostNumber, err)
+		}
+	}
+
+	// Испорченная кодировка записывается в обход CreateOrUpdateGost
+	_, err := db.conn.Exec(`
+		INSERT INTO gosts (gost_number, title, status, adoption_date, effective_date, source_type, source_url, description, keywords, updated_at)
+		VALUES ('ГОСТ 4-2000', ?, 'отменен', ?, ?, 'test', '', '', '', CURRENT_TIMESTAMP)
+	`, mojibake(t, charmap.CodePage866, "Гайки шестигранные"), past, past)
+	if err != nil {
+		t.Fatalf("Failed to insert mojibake row: %v", err)
+	}
+
+	// Проверка кодировки в отчете не зависит от автоисправления при чтении
+	db.SetEncodingOptions(GostEncodingOptions{Validate: true})
+
+	report, err := db.QualityReport()
+	if err != nil {
+		t.Fatalf("QualityReport failed: %v", err)
+	}
+
+	checks := []struct {
+		name      string
+		got, want int
+	}{
+		{"total", report.Total, 6},
+		{"missing_title", report.MissingTitle, 1},
+		{"missing_adoption_date", report.MissingAdoptionDate, 1},
+		{"missing_effective_date", report.MissingEffectiveDate, 1},
+		{"invalid_number", report.InvalidNumber, 1},
+		{"encoding_issues", report.EncodingIssues, 1},
+		{"inconsistent", report.Inconsistent, 1},
+		{GostRuleActiveNotYetEffective, report.InconsistenciesByRule[GostRuleActiveNotYetEffective], 1},
+		{GostRuleActiveWithdrawn, report.InconsistenciesByRule[GostRuleActiveWithdrawn], 1},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %d, want %d", check.name, check.got, check.want)
+		}
+	}
+}
+
+func TestIsValidGostNumber(t *testing.T) {
+	valid := []string{"ГОСТ 2590-2006", "ГОСТ 2590-88", "ГОСТ Р 7.0.0-2024", "ГОСТ Р ИСО 9001-2015", "гост 12.1.004-91"}
+	for _, number := range valid {
+		if !IsValidGostNumber(number) {
+			t.Errorf("IsValidGostNumber(%q) = false, want true", number)
+		}
+	}
+
+	invalid := []string{"", "ГОСТ", "ГОСТ 2590", "ISO 4014", "ГОСТ 2590-20061"}
+	for _, number := range invalid {
+		if IsValidGostNumber(number) {
+			t.Errorf("IsValidGostNumber(%q) = true, want false", number)
+		}
+	}
+}
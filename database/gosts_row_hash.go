@@ -0,0 +1,128 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GostWriteOutcome результат записи ГОСТа через UpsertGost и MergeGostWithOutcome
+type GostWriteOutcome string
+
+const (
+	GostInserted  GostWriteOutcome = "inserted"
+	GostUpdated   GostWriteOutcome = "updated"
+	GostUnchanged GostWriteOutcome = "unchanged" // Данные совпали с сохраненными, запись не выполнялась
+)
+
+// MigrateGostsRowHash добавляет в таблицу gosts хэш содержимого строки (row_hash).
+// Хэш записывается в UpsertGost и позволяет при повторном импорте не перезаписывать
+// неизменившиеся ГОСТы. Для строк, сохраненных до миграции, хэш пуст - они обновятся один раз.
+func MigrateGostsRowHash(db *sql.DB) error {
+	if _, err := db.Exec(`ALTER TABLE gosts ADD COLUMN row_hash TEXT`); err != nil {
+		errStr := strings.ToLower(err.Error())
+		if !strings.Contains(errStr, "duplicate column") && !strings.Contains(errStr, "already exists") {
+			return fmt.Errorf("failed to add row_hash column: %w", err)
+		}
+	}
+	return nil
+}
+
+// gostRowHash вычисляет хэш записываемых полей ГОСТа. Текст сравнивается без крайних пробелов,
+// даты - с точностью до дня, поэтому повторный разбор того же файла дает тот же хэш.
+func gostRowHash(gost *Gost) string {
+	formatDate := func(date *time.Time) string {
+		if date == nil {
+			return ""
+		}
+		return date.Format("2006-01-02")
+	}
+	sourceID := ""
+	if gost.SourceID != nil {
+		sourceID = strconv.Itoa(*gost.SourceID)
+	}
+
+	fields := []string{
+		strings.TrimSpace(gost.GostNumber),
+		strings.TrimSpace(gost.Title),
+		formatDate(gost.AdoptionDate),
+		formatDate(gost.EffectiveDate),
+		formatDate(gost.WithdrawalDate),
+		strings.TrimSpace(gost.Status),
+		strings.TrimSpace(gost.SourceType),
+		sourceID,
+		strings.TrimSpace(gost.SourceURL),
+		strings.TrimSpace(gost.Description),
+		strings.TrimSpace(gost.Keywords),
+	}
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+// UpsertGost создает или обновляет ГОСТ, как CreateOrUpdateGost, но пропускает UPDATE, если хэш
+// входных данных совпадает с row_hash сохраненной строки. Так повторный импорт того же файла
+// не переписывает десятки тысяч строк и не раздувает WAL. Возвращает сохраненный ГОСТ и результат записи.
+func (db *GostsDB) UpsertGost(gost *Gost) (*Gost, GostWriteOutcome, error) {
+	hash := gostRowHash(gost)
+
+	var id int
+	var storedHash sql.NullString
+	err := db.conn.QueryRow(`SELECT id, row_hash FROM gosts WHERE gost_number = ?`, gost.GostNumber).Scan(&id, &storedHash)
+	switch {
+	case err == nil && storedHash.Valid && storedHash.String == hash:
+		existing, err := db.GetGost(id)
+		if err != nil {
+			return nil, "", err
+		}
+		return existing, GostUnchanged, nil
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		return nil, "", fmt.Errorf("failed to check gost row hash: %w", err)
+	}
+
+	outcome := GostUpdated
+	if errors.Is(err, sql.ErrNoRows) {
+		outcome = GostInserted
+	}
+
+	_, err = db.conn.Exec(`
+		INSERT INTO gosts (gost_number, title, adoption_date, effective_date, withdrawal_date, status,
+		                   source_type, source_id, source_url, description, keywords, row_hash, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(gost_number) DO UPDATE SET
+			title = excluded.title,
+			adoption_date = excluded.adoption_date,
+			effective_date = excluded.effective_date,
+			withdrawal_date = COALESCE(excluded.withdrawal_date, gosts.withdrawal_date),
+			status = excluded.status,
+			source_type = excluded.source_type,
+			source_id = excluded.source_id,
+			source_url = excluded.source_url,
+			description = excluded.description,
+			keywords = excluded.keywords,
+			row_hash = excluded.row_hash,
+			updated_at = CURRENT_TIMESTAMP
+	`,
+		gost.GostNumber, gost.Title, gost.AdoptionDate, gost.EffectiveDate, gost.WithdrawalDate,
+		gost.Status, gost.SourceType, gost.SourceID, gost.SourceURL,
+		gost.Description, gost.Keywords, hash)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create or update gost: %w", err)
+	}
+
+	// Получаем ID записи по номеру ГОСТа: при UPDATE через ON CONFLICT
+	// LastInsertId не меняется и возвращает ID предыдущей вставки
+	if err := db.conn.QueryRow("SELECT id FROM gosts WHERE gost_number = ?", gost.GostNumber).Scan(&id); err != nil {
+		return nil, "", fmt.Errorf("failed to get gost ID: %w", err)
+	}
+
+	saved, err := db.GetGost(id)
+	if err != nil {
+		return nil, "", err
+	}
+	return saved, outcome, nil
+}
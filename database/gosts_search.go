@@ -0,0 +1,192 @@
+package database
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Параметры BM25 для ранжирования результатов SearchGosts
+const (
+	gostSearchBM25K1 = 1.2
+	gostSearchBM25B  = 0.75
+
+	// gostSearchLoadBatch число ГОСТов, загружаемых одним запросом после ранжирования
+	gostSearchLoadBatch = 500
+)
+
+// gostSearchColumnWeights веса колонок gosts_fts в порядке объявления:
+// gost_number, title, keywords, description (те же, что при ранжировании подсказок)
+var gostSearchColumnWeights = []float64{
+	gostSuggestNumberWeight,
+	gostSuggestTitleWeight,
+	gostSuggestKeywordsWeight,
+	gostSuggestDescriptionWeight,
+}
+
+// buildGostMatchQuery строит выражение MATCH для gosts_fts: все слова запроса обязательны
+// и ищутся по префиксу. Например: "Сварка труб" -> "сварка* труб*".
+// Пустая строка означает, что в запросе нет слов для полнотекстового поиска.
+// В отличие от normalizeGostText "ё" не заменяется: токенизатор unicode61 хранит ее как есть.
+func buildGostMatchQuery(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		terms = append(terms, word+"*")
+	}
+	return strings.Join(terms, " ")
+}
+
+// gostBM25 вычисляет релевантность BM25 по результату matchinfo(gosts_fts, 'pcnalx').
+// FTS4 не содержит встроенной функции bm25, поэтому она считается по статистике совпадений;
+// чем больше значение, тем выше ГОСТ в выдаче.
+func gostBM25(info []byte, weights []float64) float64 {
+	values := make([]uint32, len(info)/4)
+	for i := range values {
+		values[i] = binary.NativeEndian.Uint32(info[i*4:])
+	}
+	if len(values) < 3 {
+		return 0
+	}
+
+	phrases, columns, rows := int(values[0]), int(values[1]), float64(values[2])
+	avgLengths := values[3 : 3+columns]
+	lengths := values[3+columns : 3+2*columns]
+	hits := values[3+2*columns:]
+	if len(hits) < 3*phrases*columns {
+		return 0
+	}
+
+	var score float64
+	for phrase := 0; phrase < phrases; phrase++ {
+		for column := 0; column < columns && column < len(weights); column++ {
+			offset := 3 * (phrase*columns + column)
+			termFrequency := float64(hits[offset])
+			docsWithHits := float64(hits[offset+2])
+			if termFrequency == 0 {
+				continue
+			}
+
+			// Для слов, встречающихся больше чем в половине строк, idf отрицателен - оставляем небольшой вклад
+			idf := math.Log((rows - docsWithHits + 0.5) / (docsWithHits + 0.5))
+			if idf <= 0 {
+				idf = 1e-6
+			}
+			lengthRatio := 1.0
+			if avgLengths[column] > 0 {
+				lengthRatio = float64(lengths[column]) / float64(avgLengths[column])
+			}
+			score += weights[column] * idf * termFrequency * (gostSearchBM25K1 + 1) /
+				(termFrequency + gostSearchBM25K1*(1-gostSearchBM25B+gostSearchBM25B*lengthRatio))
+		}
+	}
+
+	return score
+}
+
+// searchGostsFTS ищет ГОСТы по gosts_fts с дополнительными условиями filters (" AND ...")
+// и возвращает страницу результатов, упорядоченных по gostBM25, и общее число совпадений
+func (db *GostsDB) searchGostsFTS(matchQuery, filters string, filterArgs []interface{}, limit, offset int) ([]*Gost, int, error) {
+	args := append([]interface{}{matchQuery}, filterArgs...)
+	rows, err := db.conn.Query(`
+		SELECT g.id, g.gost_number, matchinfo(gosts_fts, 'pcnalx')
+		FROM gosts_fts
+		JOIN gosts g ON g.id = gosts_fts.docid
+		WHERE gosts_fts MATCH ?`+filters, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query gosts_fts: %w", err)
+	}
+
+	type rankedGost struct {
+		id     int
+		number string
+		score  float64
+	}
+	var ranked []rankedGost
+	for rows.Next() {
+		var r rankedGost
+		var info []byte
+		if err := rows.Scan(&r.id, &r.number, &info); err != nil {
+			rows.Close()
+			return nil, 0, fmt.Errorf("failed to scan gosts_fts match: %w", err)
+		}
+		r.score = gostBM25(info, gostSearchColumnWeights)
+		ranked = append(ranked, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("failed to iterate gosts_fts matches: %w", err)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].number < ranked[j].number
+	})
+
+	total := len(ranked)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= total {
+		return []*Gost{}, total, nil
+	}
+	end := total
+	if limit > 0 && offset+limit < end {
+		end = offset + limit
+	}
+	page := ranked[offset:end]
+
+	position := make(map[int]int, len(page))
+	for i, r := range page {
+		position[r.id] = i
+	}
+
+	// ГОСТы страницы загружаются порциями, чтобы не превысить лимит параметров SQLite при выгрузке
+	var found []*Gost
+	for start := 0; start < len(page); start += gostSearchLoadBatch {
+		batch := page[start:min(start+gostSearchLoadBatch, len(page))]
+		placeholders := make([]string, len(batch))
+		ids := make([]interface{}, len(batch))
+		for i, r := range batch {
+			placeholders[i] = "?"
+			ids[i] = r.id
+		}
+		batchRows, err := db.conn.Query(`
+			SELECT id, gost_number, title, adoption_date, effective_date, status,
+			       source_type, source_id, source_url, description, keywords,
+			       created_at, updated_at
+			FROM gosts
+			WHERE id IN (`+strings.Join(placeholders, ", ")+`)`, ids...)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to load found gosts: %w", err)
+		}
+		batchGosts, err := scanGostRows(batchRows)
+		batchRows.Close()
+		if err != nil {
+			return nil, 0, err
+		}
+		found = append(found, batchGosts...)
+	}
+
+	ordered := make([]*Gost, len(page))
+	for _, gost := range found {
+		db.checkGostEncoding(gost)
+		ordered[position[gost.ID]] = gost
+	}
+	// ГОСТ мог быть удален между запросами
+	gosts := make([]*Gost, 0, len(ordered))
+	for _, gost := range ordered {
+		if gost != nil {
+			gosts = append(gosts, gost)
+		}
+	}
+
+	return gosts, total, nil
+}
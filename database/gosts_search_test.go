@@ -0,0 +1,68 @@
+package database
+
+import "testing"
+
+func TestSearchGosts_FullTextRanking(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	for _, gost := range []*Gost{
+		{GostNumber: "ГОСТ 5264-80", Title: "Ручная дуговая сварка. Соединения сварные", Status: "действующий"},
+		{GostNumber: "ГОСТ 14771-76", Title: "Дуговая сварка в защитном газе", Keywords: "сварка, сварочная проволока", Status: "действующий"},
+		{GostNumber: "ГОСТ 10704-91", Title: "Трубы стальные электросварные", Description: "Трубы после сварки", Status: "отменен"},
+		{GostNumber: "ГОСТ 8732-78", Title: "Трубы стальные бесшовные", Status: "действующий"},
+		{GostNumber: "ГОСТ 9.032-74", Title: "Покрытия лакокрасочные", Description: "Ёмкости и резервуары", Status: "действующий"},
+	} {
+		if _, err := db.CreateOrUpdateGost(gost); err != nil {
+			t.Fatalf("Failed to seed gost %s: %v", gost.GostNumber, err)
+		}
+	}
+
+	// Заглавные буквы кириллицы и префикс слова
+	gosts, total, err := db.SearchGosts("СВАР", 10, 0, "", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("SearchGosts failed: %v", err)
+	}
+	if total != 3 || len(gosts) != 3 {
+		t.Fatalf("Expected 3 GOSTs for prefix search, got %d (%d on page)", total, len(gosts))
+	}
+	// Совпадения в названии и ключевых словах выше совпадения только в описании
+	if gosts[0].GostNumber != "ГОСТ 14771-76" || gosts[2].GostNumber != "ГОСТ 10704-91" {
+		t.Errorf("Unexpected ranking: %s, %s, %s", gosts[0].GostNumber, gosts[1].GostNumber, gosts[2].GostNumber)
+	}
+
+	// Все слова запроса обязательны, порядок слов не важен
+	gosts, total, err = db.SearchGosts("стальные трубы", 10, 0, "", "", "", "", "", "")
+	if err != nil || total != 2 {
+		t.Fatalf("Expected 2 GOSTs for two-word search, got %d (%v)", total, err)
+	}
+
+	// Фильтры применяются вместе с полнотекстовым поиском, total учитывает их
+	gosts, total, err = db.SearchGosts("трубы", 10, 0, "действующий", "", "", "", "", "")
+	if err != nil || total != 1 || gosts[0].GostNumber != "ГОСТ 8732-78" {
+		t.Fatalf("Expected only the active pipe GOST, got %d (%v)", total, err)
+	}
+
+	// Пагинация по ранжированному списку
+	gosts, total, err = db.SearchGosts("свар", 1, 1, "", "", "", "", "", "")
+	if err != nil || total != 3 || len(gosts) != 1 || gosts[0].GostNumber != "ГОСТ 5264-80" {
+		t.Fatalf("Unexpected second page: total=%d gosts=%v err=%v", total, gosts, err)
+	}
+
+	// Заглавная "Ё" приводится к нижнему регистру
+	if _, total, err = db.SearchGosts("ЁМКОСТИ", 10, 0, "", "", "", "", "", ""); err != nil || total != 1 {
+		t.Errorf("Expected search with \"Ё\" to match, got %d (%v)", total, err)
+	}
+
+	// Номер ГОСТа ищется по частям номера
+	if gosts, _, err = db.SearchGosts("5264-80", 10, 0, "", "", "", "", "", ""); err != nil || len(gosts) != 1 {
+		t.Errorf("Expected search by GOST number to match, got %v (%v)", gosts, err)
+	}
+
+	// Обновленный ГОСТ переиндексируется
+	if _, err := db.CreateOrUpdateGost(&Gost{GostNumber: "ГОСТ 8732-78", Title: "Трубы стальные бесшовные горячедеформированные", Status: "действующий"}); err != nil {
+		t.Fatalf("Failed to update gost: %v", err)
+	}
+	if _, total, err = db.SearchGosts("горячедеформированные", 10, 0, "", "", "", "", "", ""); err != nil || total != 1 {
+		t.Errorf("Expected updated title to be searchable, got %d (%v)", total, err)
+	}
+}
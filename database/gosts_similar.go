@@ -0,0 +1,132 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+)
+
+// gostSimilarMaxStemFrequency основы, встречающиеся в названиях чаще, не используются для отбора пар
+// ("технические", "условия", "общие"): иначе пришлось бы сравнивать почти все ГОСТы попарно
+const gostSimilarMaxStemFrequency = 200
+
+// GostTitleRef ГОСТ в паре похожих названий
+type GostTitleRef struct {
+	ID         int    `json:"id"`
+	GostNumber string `json:"gost_number"`
+	Title      string `json:"title"`
+	SourceType string `json:"source_type"`
+}
+
+// GostSimilarityPair пара ГОСТов с похожими названиями - кандидат в дубликаты для ручной проверки
+type GostSimilarityPair struct {
+	First      GostTitleRef `json:"first"`
+	Second     GostTitleRef `json:"second"`
+	Similarity float64      `json:"similarity"`
+}
+
+// FindSimilarTitles находит пары ГОСТов, нормализованные названия которых совпадают не меньше чем на threshold.
+// Сходство - коэффициент Дайса по основам слов названия (см. stemGostQuery). Редакции одного
+// стандарта (одинаковый номер без года, см. GostBaseNumber) парами не считаются.
+// Пары упорядочены по убыванию сходства; limit <= 0 означает без ограничения. Ничего не объединяет.
+func (db *GostsDB) FindSimilarTitles(threshold float64, limit int) ([]GostSimilarityPair, error) {
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("similarity threshold must be in (0, 1], got %g", threshold)
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT id, gost_number, title, COALESCE(source_type, '')
+		FROM gosts
+		WHERE title IS NOT NULL AND title != ''
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gost titles: %w", err)
+	}
+	defer rows.Close()
+
+	var refs []GostTitleRef
+	var stems []map[string]bool
+	for rows.Next() {
+		var ref GostTitleRef
+		if err := rows.Scan(&ref.ID, &ref.GostNumber, &ref.Title, &ref.SourceType); err != nil {
+			return nil, fmt.Errorf("failed to scan gost title: %w", err)
+		}
+		set := make(map[string]bool)
+		for _, stem := range stemGostQuery(ref.Title) {
+			set[stem] = true
+		}
+		if len(set) == 0 {
+			continue
+		}
+		refs = append(refs, ref)
+		stems = append(stems, set)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate gost titles: %w", err)
+	}
+
+	// Обратный индекс: основа -> ГОСТы, в названии которых она встречается
+	index := make(map[string][]int)
+	for i, set := range stems {
+		for stem := range set {
+			index[stem] = append(index[stem], i)
+		}
+	}
+
+	baseNumbers := make([]string, len(refs))
+	for i, ref := range refs {
+		baseNumbers[i] = GostBaseNumber(ref.GostNumber)
+	}
+
+	pairs := []GostSimilarityPair{}
+	for i, set := range stems {
+		compared := make(map[int]bool)
+		for stem := range set {
+			postings := index[stem]
+			if len(postings) > gostSimilarMaxStemFrequency {
+				continue
+			}
+			for _, j := range postings {
+				// Каждая пара рассматривается один раз: j > i
+				if j <= i || compared[j] {
+					continue
+				}
+				compared[j] = true
+				if baseNumbers[i] != "" && baseNumbers[i] == baseNumbers[j] {
+					continue
+				}
+
+				similarity := gostTitleDice(set, stems[j])
+				if similarity >= threshold {
+					pairs = append(pairs, GostSimilarityPair{First: refs[i], Second: refs[j], Similarity: similarity})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(pairs, func(a, b int) bool {
+		if pairs[a].Similarity != pairs[b].Similarity {
+			return pairs[a].Similarity > pairs[b].Similarity
+		}
+		if pairs[a].First.ID != pairs[b].First.ID {
+			return pairs[a].First.ID < pairs[b].First.ID
+		}
+		return pairs[a].Second.ID < pairs[b].Second.ID
+	})
+
+	if limit > 0 && len(pairs) > limit {
+		pairs = pairs[:limit]
+	}
+	return pairs, nil
+}
+
+// gostTitleDice коэффициент Дайса двух множеств основ: 2|A∩B| / (|A|+|B|)
+func gostTitleDice(a, b map[string]bool) float64 {
+	common := 0
+	for stem := range a {
+		if b[stem] {
+			common++
+		}
+	}
+	return 2 * float64(common) / float64(len(a)+len(b))
+}
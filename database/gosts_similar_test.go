@@ -0,0 +1,89 @@
+package database
+
+import "testing"
+
+func TestFindSimilarTitles_PairsNearDuplicates(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	gosts := []*Gost{
+		{GostNumber: "ГОСТ 7798-70", Title: "Болты с шестигранной головкой класса точности В. Конструкция и размеры", SourceType: "fstec"},
+		{GostNumber: "ГОСТ Р 7798-70", Title: "Болты с шестигранной головкой класса точности B. Конструкция и размеры.", SourceType: "manual"},
+		{GostNumber: "ГОСТ 380-2005", Title: "Сталь углеродистая обыкновенного качества. Марки", SourceType: "fstec"},
+		{GostNumber: "ГОСТ 380-94", Title: "Сталь углеродистая обыкновенного качества. Марки", SourceType: "fstec"},
+		{GostNumber: "ГОСТ 32144-2013", Title: "Электрическая энергия. Нормы качества электрической энергии", SourceType: "fstec"},
+		{GostNumber: "ГОСТ 31986-2012", Title: "Услуги общественного питания. Метод органолептической оценки качества продукции", SourceType: "fstec"},
+	}
+	for _, gost := range gosts {
+		if _, err := db.CreateOrUpdateGost(gost); err != nil {
+			t.Fatalf("CreateOrUpdateGost(%q) failed: %v", gost.GostNumber, err)
+		}
+	}
+
+	pairs, err := db.FindSimilarTitles(0.8, 0)
+	if err != nil {
+		t.Fatalf("FindSimilarTitles failed: %v", err)
+	}
+
+	// Редакции ГОСТ 380 с одинаковым названием дубликатами не считаются
+	if len(pairs) != 1 {
+		t.Fatalf("Expected 1 similar pair, got %d: %+v", len(pairs), pairs)
+	}
+	pair := pairs[0]
+	if pair.First.GostNumber != "ГОСТ 7798-70" || pair.Second.GostNumber != "ГОСТ Р 7798-70" {
+		t.Errorf("Unexpected pair: %s / %s", pair.First.GostNumber, pair.Second.GostNumber)
+	}
+	if pair.Similarity < 0.8 || pair.Similarity > 1 {
+		t.Errorf("Similarity = %f, want in [0.8, 1]", pair.Similarity)
+	}
+}
+
+func TestFindSimilarTitles_ThresholdAndLimit(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	gosts := []*Gost{
+		{GostNumber: "ГОСТ 1-01", Title: "Трубы стальные бесшовные горячедеформированные. Сортамент"},
+		{GostNumber: "ГОСТ 2-02", Title: "Трубы стальные бесшовные горячедеформированные. Сортамент"},
+		{GostNumber: "ГОСТ 3-03", Title: "Трубы стальные бесшовные холоднодеформированные. Сортамент"},
+	}
+	for _, gost := range gosts {
+		if _, err := db.CreateOrUpdateGost(gost); err != nil {
+			t.Fatalf("CreateOrUpdateGost(%q) failed: %v", gost.GostNumber, err)
+		}
+	}
+
+	pairs, err := db.FindSimilarTitles(0.7, 0)
+	if err != nil {
+		t.Fatalf("FindSimilarTitles failed: %v", err)
+	}
+	if len(pairs) != 3 {
+		t.Fatalf("Expected 3 pairs at threshold 0.7, got %d", len(pairs))
+	}
+	if pairs[0].Similarity != 1 {
+		t.Errorf("Expected identical titles first, got similarity %f", pairs[0].Similarity)
+	}
+	for i := 1; i < len(pairs); i++ {
+		if pairs[i].Similarity > pairs[i-1].Similarity {
+			t.Errorf("Pairs are not sorted by similarity: %f after %f", pairs[i].Similarity, pairs[i-1].Similarity)
+		}
+	}
+
+	pairs, err = db.FindSimilarTitles(1, 0)
+	if err != nil {
+		t.Fatalf("FindSimilarTitles failed: %v", err)
+	}
+	if len(pairs) != 1 {
+		t.Errorf("Expected only the identical pair at threshold 1, got %d", len(pairs))
+	}
+
+	pairs, err = db.FindSimilarTitles(0.7, 2)
+	if err != nil {
+		t.Fatalf("FindSimilarTitles failed: %v", err)
+	}
+	if len(pairs) != 2 {
+		t.Errorf("Expected limit to cap pairs at 2, got %d", len(pairs))
+	}
+
+	if _, err := db.FindSimilarTitles(0, 10); err == nil {
+		t.Error("Expected error for zero threshold")
+	}
+}
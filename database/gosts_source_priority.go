@@ -0,0 +1,226 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Поля ГОСТа, для которых gost_field_sources хранит источник значения
+const (
+	GostFieldTitle          = "title"
+	GostFieldAdoptionDate   = "adoption_date"
+	GostFieldEffectiveDate  = "effective_date"
+	GostFieldWithdrawalDate = "withdrawal_date"
+	GostFieldStatus         = "status"
+	GostFieldDescription    = "description"
+	GostFieldKeywords       = "keywords"
+)
+
+// DefaultGostSourcePriority порядок источников по умолчанию, от более авторитетного к менее.
+// Реестры стандартов Росстандарта важнее производных списков; источники вне списка - ниже всех.
+var DefaultGostSourcePriority = []string{
+	"nationalstandards",
+	"interstatestandards",
+	"listnationalstandarts",
+	"tulist",
+}
+
+// MigrateGostFieldSources создает таблицу gost_field_sources: источник, из которого взято
+// значение каждого поля ГОСТа при слиянии данных нескольких источников (MergeGost)
+func MigrateGostFieldSources(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS gost_field_sources (
+			gost_id INTEGER NOT NULL,
+			field TEXT NOT NULL,
+			source_type TEXT NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			PRIMARY KEY (gost_id, field),
+			FOREIGN KEY (gost_id) REFERENCES gosts(id) ON DELETE CASCADE
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create gost_field_sources table: %w", err)
+	}
+	return nil
+}
+
+// gostSourceRank возвращает позицию источника в priority (меньше - авторитетнее).
+// Источники, отсутствующие в списке, получают наименьший приоритет.
+func gostSourceRank(sourceType string, priority []string) int {
+	sourceType = strings.ToLower(strings.TrimSpace(sourceType))
+	for i, name := range priority {
+		if strings.ToLower(strings.TrimSpace(name)) == sourceType {
+			return i
+		}
+	}
+	return len(priority)
+}
+
+// MergeGost сливает запись gost с уже сохраненным ГОСТом с тем же номером. Непустое значение
+// поля из gost заменяет сохраненное, если сохраненное пусто или источник gost.SourceType
+// не ниже по priority, чем источник, из которого взято сохраненное значение. При равном
+// приоритете (в том числе при повторном импорте того же источника) побеждает новое значение.
+// Источник-победитель каждого поля записывается в gost_field_sources; source_type, source_id
+// и source_url записи следуют за источником названия.
+func (db *GostsDB) MergeGost(gost *Gost, priority []string) (*Gost, error) {
+	merged, _, err := db.MergeGostWithOutcome(gost, priority)
+	return merged, err
+}
+
+// MergeGostWithOutcome выполняет MergeGost и сообщает, был ли ГОСТ добавлен, обновлен
+// или остался без изменений (см. UpsertGost)
+func (db *GostsDB) MergeGostWithOutcome(gost *Gost, priority []string) (*Gost, GostWriteOutcome, error) {
+	existing, err := db.GetGostByNumber(gost.GostNumber)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, "", err
+		}
+		created, outcome, err := db.UpsertGost(gost)
+		if err != nil {
+			return nil, "", err
+		}
+		winners := make(map[string]string)
+		for field, empty := range gostEmptyFields(gost) {
+			if !empty {
+				winners[field] = gost.SourceType
+			}
+		}
+		if err := db.saveGostFieldSources(created.ID, winners); err != nil {
+			return nil, "", err
+		}
+		return created, outcome, nil
+	}
+
+	sources, err := db.GetGostFieldSources(existing.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	incomingRank := gostSourceRank(gost.SourceType, priority)
+	incomingEmpty := gostEmptyFields(gost)
+	existingEmpty := gostEmptyFields(existing)
+	winners := make(map[string]string)
+	for field, empty := range incomingEmpty {
+		if empty {
+			continue
+		}
+		current, ok := sources[field]
+		if !ok {
+			// Записи, сохраненные до появления gost_field_sources, целиком из source_type
+			current = existing.SourceType
+		}
+		if existingEmpty[field] || incomingRank <= gostSourceRank(current, priority) {
+			winners[field] = gost.SourceType
+		}
+	}
+	if len(winners) == 0 {
+		return existing, GostUnchanged, nil
+	}
+
+	merged := *existing
+	for field := range winners {
+		switch field {
+		case GostFieldTitle:
+			merged.Title = gost.Title
+			merged.SourceType = gost.SourceType
+			merged.SourceID = gost.SourceID
+			merged.SourceURL = gost.SourceURL
+		case GostFieldAdoptionDate:
+			merged.AdoptionDate = gost.AdoptionDate
+		case GostFieldEffectiveDate:
+			merged.EffectiveDate = gost.EffectiveDate
+		case GostFieldWithdrawalDate:
+			merged.WithdrawalDate = gost.WithdrawalDate
+		case GostFieldStatus:
+			merged.Status = gost.Status
+		case GostFieldDescription:
+			merged.Description = gost.Description
+		case GostFieldKeywords:
+			merged.Keywords = gost.Keywords
+		}
+	}
+
+	updated, outcome, err := db.UpsertGost(&merged)
+	if err != nil {
+		return nil, "", err
+	}
+	// Источники перезаписываются, только если победитель поля сменился
+	changedSources := make(map[string]string)
+	for field, sourceType := range winners {
+		if sources[field] != sourceType {
+			changedSources[field] = sourceType
+		}
+	}
+	if err := db.saveGostFieldSources(updated.ID, changedSources); err != nil {
+		return nil, "", err
+	}
+	return updated, outcome, nil
+}
+
+// GetGostFieldSources возвращает источник значения каждого поля ГОСТа (поле -> source_type)
+func (db *GostsDB) GetGostFieldSources(gostID int) (map[string]string, error) {
+	rows, err := db.conn.Query(`SELECT field, source_type FROM gost_field_sources WHERE gost_id = ?`, gostID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gost field sources: %w", err)
+	}
+	defer rows.Close()
+
+	sources := make(map[string]string)
+	for rows.Next() {
+		var field, sourceType string
+		if err := rows.Scan(&field, &sourceType); err != nil {
+			return nil, fmt.Errorf("failed to scan gost field source: %w", err)
+		}
+		sources[field] = sourceType
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate gost field sources: %w", err)
+	}
+	return sources, nil
+}
+
+// saveGostFieldSources записывает источники-победители полей ГОСТа
+func (db *GostsDB) saveGostFieldSources(gostID int, winners map[string]string) error {
+	if len(winners) == 0 {
+		return nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for field, sourceType := range winners {
+		_, err := tx.Exec(`
+			INSERT INTO gost_field_sources (gost_id, field, source_type, updated_at)
+			VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+			ON CONFLICT(gost_id, field) DO UPDATE SET
+				source_type = excluded.source_type,
+				updated_at = CURRENT_TIMESTAMP
+		`, gostID, field, sourceType)
+		if err != nil {
+			return fmt.Errorf("failed to save source of %s: %w", field, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit gost field sources: %w", err)
+	}
+	return nil
+}
+
+// gostEmptyFields возвращает для каждого отслеживаемого поля признак отсутствия значения
+func gostEmptyFields(gost *Gost) map[string]bool {
+	return map[string]bool{
+		GostFieldTitle:          strings.TrimSpace(gost.Title) == "",
+		GostFieldAdoptionDate:   gost.AdoptionDate == nil,
+		GostFieldEffectiveDate:  gost.EffectiveDate == nil,
+		GostFieldWithdrawalDate: gost.WithdrawalDate == nil,
+		GostFieldStatus:         strings.TrimSpace(gost.Status) == "",
+		GostFieldDescription:    strings.TrimSpace(gost.Description) == "",
+		GostFieldKeywords:       strings.TrimSpace(gost.Keywords) == "",
+	}
+}
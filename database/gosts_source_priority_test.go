@@ -0,0 +1,114 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGostsDB_MergeGost_HigherPriorityWinsRegardlessOfOrder(t *testing.T) {
+	national := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	scraped := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	newRecords := func() (*Gost, *Gost) {
+		return &Gost{
+			GostNumber:    "ГОСТ 1234-2020",
+			Title:         "Болты. Технические условия",
+			EffectiveDate: &national,
+			Status:        "действует",
+			SourceType:    "nationalstandards",
+			SourceURL:     "https://example.org/national",
+		}, &Gost{
+			GostNumber:    "ГОСТ 1234-2020",
+			Title:         "Болты ТУ",
+			EffectiveDate: &scraped,
+			Status:        "действует",
+			Description:   "Описание из списка",
+			SourceType:    "tulist",
+			SourceURL:     "https://example.org/tulist",
+		}
+	}
+
+	tests := []struct {
+		name  string
+		order func(high, low *Gost) []*Gost
+	}{
+		{"high first", func(high, low *Gost) []*Gost { return []*Gost{high, low} }},
+		{"low first", func(high, low *Gost) []*Gost { return []*Gost{low, high} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestGostsDB(t)
+			high, low := newRecords()
+
+			for _, gost := range tt.order(high, low) {
+				if _, err := db.MergeGost(gost, DefaultGostSourcePriority); err != nil {
+					t.Fatalf("MergeGost failed: %v", err)
+				}
+			}
+
+			got, err := db.GetGostByNumber("ГОСТ 1234-2020")
+			if err != nil {
+				t.Fatalf("GetGostByNumber failed: %v", err)
+			}
+			if got.Title != "Болты. Технические условия" {
+				t.Errorf("Expected title from nationalstandards, got %q", got.Title)
+			}
+			if got.EffectiveDate == nil || !got.EffectiveDate.Equal(national) {
+				t.Errorf("Expected effective date %v, got %v", national, got.EffectiveDate)
+			}
+			if got.SourceType != "nationalstandards" || got.SourceURL != "https://example.org/national" {
+				t.Errorf("Expected row source to follow title, got %q %q", got.SourceType, got.SourceURL)
+			}
+			// Поле, которого нет у авторитетного источника, берется из менее приоритетного
+			if got.Description != "Описание из списка" {
+				t.Errorf("Expected description from tulist, got %q", got.Description)
+			}
+
+			sources, err := db.GetGostFieldSources(got.ID)
+			if err != nil {
+				t.Fatalf("GetGostFieldSources failed: %v", err)
+			}
+			want := map[string]string{
+				GostFieldTitle:         "nationalstandards",
+				GostFieldEffectiveDate: "nationalstandards",
+				GostFieldDescription:   "tulist",
+			}
+			for field, source := range want {
+				if sources[field] != source {
+					t.Errorf("Expected %s from %s, got %q", field, source, sources[field])
+				}
+			}
+		})
+	}
+}
+
+func TestGostsDB_MergeGost_SameSourceOverwrites(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	first := &Gost{GostNumber: "ГОСТ 1-2000", Title: "Старое название", SourceType: "tulist"}
+	second := &Gost{GostNumber: "ГОСТ 1-2000", Title: "Новое название", SourceType: "tulist"}
+	for _, gost := range []*Gost{first, second} {
+		if _, err := db.MergeGost(gost, DefaultGostSourcePriority); err != nil {
+			t.Fatalf("MergeGost failed: %v", err)
+		}
+	}
+
+	got, err := db.GetGostByNumber("ГОСТ 1-2000")
+	if err != nil {
+		t.Fatalf("GetGostByNumber failed: %v", err)
+	}
+	if got.Title != "Новое название" {
+		t.Errorf("Expected re-import of the same source to overwrite title, got %q", got.Title)
+	}
+}
+
+func TestGostSourceRank(t *testing.T) {
+	priority := []string{"nationalstandards", "tulist"}
+	if gostSourceRank("NationalStandards", priority) != 0 {
+		t.Error("Expected case-insensitive match for nationalstandards")
+	}
+	if gostSourceRank("unknown", priority) != len(priority) {
+		t.Error("Expected unknown source to rank last")
+	}
+}
@@ -0,0 +1,249 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"unicode"
+
+	"httpserver/normalization/algorithms"
+)
+
+// gostSuggestStopWords служебные слова, которые не участвуют в поиске подсказок
+var gostSuggestStopWords = map[string]bool{
+	"и": true, "в": true, "во": true, "на": true, "по": true, "для": true,
+	"с": true, "со": true, "из": true, "к": true, "о": true, "об": true,
+	"от": true, "до": true, "при": true, "или": true, "не": true, "их": true,
+}
+
+// Веса полей при ранжировании подсказок
+const (
+	gostSuggestTitleWeight       = 3.0
+	gostSuggestKeywordsWeight    = 2.0
+	gostSuggestDescriptionWeight = 1.0
+	gostSuggestNumberWeight      = 1.0
+
+	// gostSuggestMaxCandidates ограничивает число кандидатов, отбираемых из FTS для ранжирования
+	gostSuggestMaxCandidates = 1000
+)
+
+var gostSuggestStemmer = algorithms.NewRussianStemmer()
+
+// normalizeGostText приводит текст к нижнему регистру, заменяет "ё" на "е"
+// и разбивает его на слова
+func normalizeGostText(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stemGostQuery нормализует поисковый запрос и возвращает уникальные основы слов
+// Например: "Свариваемость сталей" -> ["свариваем", "стал"]
+func stemGostQuery(query string) []string {
+	seen := make(map[string]bool)
+	var stems []string
+	for _, word := range normalizeGostText(query) {
+		if gostSuggestStopWords[word] || len([]rune(word)) < 2 {
+			continue
+		}
+		stem := gostSuggestStemmer.StemWithCache(word)
+		if len([]rune(stem)) < 2 {
+			stem = word
+		}
+		if !seen[stem] {
+			seen[stem] = true
+			stems = append(stems, stem)
+		}
+	}
+	return stems
+}
+
+// scoreGost вычисляет релевантность ГОСТа в диапазоне [0, 1]:
+// для каждой основы запроса берется вес лучшего поля, в котором найдено слово с этой основой
+func scoreGost(gost *Gost, stems []string) float64 {
+	if len(stems) == 0 {
+		return 0
+	}
+
+	fields := []struct {
+		words  []string
+		weight float64
+	}{
+		{normalizeGostText(gost.Title), gostSuggestTitleWeight},
+		{normalizeGostText(gost.Keywords), gostSuggestKeywordsWeight},
+		{normalizeGostText(gost.Description), gostSuggestDescriptionWeight},
+		{normalizeGostText(gost.GostNumber), gostSuggestNumberWeight},
+	}
+
+	var total float64
+	for _, stem := range stems {
+		best := 0.0
+		for _, field := range fields {
+			if field.weight <= best {
+				continue
+			}
+			for _, word := range field.words {
+				if strings.HasPrefix(word, stem) {
+					best = field.weight
+					break
+				}
+			}
+		}
+		total += best
+	}
+
+	return total / (float64(len(stems)) * gostSuggestTitleWeight)
+}
+
+// SuggestByText возвращает ГОСТы, наиболее похожие на произвольный текстовый запрос.
+// Запрос нормализуется (регистр, "ё", служебные слова) и приводится к основам слов,
+// кандидаты отбираются полнотекстовым индексом gosts_fts и ранжируются по полю Score.
+func (db *GostsDB) SuggestByText(query string, limit int) ([]*Gost, error) {
+	if limit <= 0 {
+		limit = 10
+	}
+
+	stems := stemGostQuery(query)
+	if len(stems) == 0 {
+		return []*Gost{}, nil
+	}
+
+	candidates, err := db.suggestCandidatesFTS(stems)
+	if err != nil {
+		// Индекс может отсутствовать, если миграция не выполнилась
+		log.Printf("Warning: gosts_fts search failed, falling back to LIKE: %v", err)
+		candidates, err = db.suggestCandidatesLike(stems)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, gost := range candidates {
+		gost.Score = scoreGost(gost, stems)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].GostNumber < candidates[j].GostNumber
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	return candidates, nil
+}
+
+// suggestCandidatesFTS отбирает кандидатов по префиксам основ через gosts_fts
+func (db *GostsDB) suggestCandidatesFTS(stems []string) ([]*Gost, error) {
+	terms := make([]string, 0, len(stems))
+	for _, stem := range stems {
+		terms = append(terms, stem+"*")
+	}
+
+	query := `
+		SELECT g.id, g.gost_number, g.title, g.adoption_date, g.effective_date, g.status,
+		       g.source_type, g.source_id, g.source_url, g.description, g.keywords,
+		       g.created_at, g.updated_at
+		FROM gosts_fts f
+		JOIN gosts g ON g.id = f.docid
+		WHERE gosts_fts MATCH ?
+		LIMIT ?
+	`
+
+	rows, err := db.conn.Query(query, strings.Join(terms, " OR "), gostSuggestMaxCandidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query gosts_fts: %w", err)
+	}
+	defer rows.Close()
+
+	return scanGostRows(rows)
+}
+
+// suggestCandidatesLike отбирает кандидатов через LIKE, если полнотекстовый индекс недоступен
+func (db *GostsDB) suggestCandidatesLike(stems []string) ([]*Gost, error) {
+	conditions := make([]string, 0, len(stems))
+	args := make([]interface{}, 0, len(stems)*3+1)
+	for _, stem := range stems {
+		conditions = append(conditions, "(lower(title) LIKE ? OR lower(keywords) LIKE ? OR lower(description) LIKE ?)")
+		pattern := "%" + stem + "%"
+		args = append(args, pattern, pattern, pattern)
+	}
+	args = append(args, gostSuggestMaxCandidates)
+
+	query := fmt.Sprintf(`
+		SELECT id, gost_number, title, adoption_date, effective_date, status,
+		       source_type, source_id, source_url, description, keywords,
+		       created_at, updated_at
+		FROM gosts
+		WHERE %s
+		LIMIT ?
+	`, strings.Join(conditions, " OR "))
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gosts: %w", err)
+	}
+	defer rows.Close()
+
+	return scanGostRows(rows)
+}
+
+// scanGostRows сканирует строки выборки ГОСТов в стандартном порядке колонок
+func scanGostRows(rows *sql.Rows) ([]*Gost, error) {
+	var gosts []*Gost
+	for rows.Next() {
+		gost := &Gost{}
+		var adoptionDate, effectiveDate, createdAt, updatedAt sql.NullTime
+		var sourceID sql.NullInt64
+		var status, sourceType, sourceURL, description, keywords sql.NullString
+
+		err := rows.Scan(
+			&gost.ID, &gost.GostNumber, &gost.Title,
+			&adoptionDate, &effectiveDate,
+			&status, &sourceType, &sourceID,
+			&sourceURL, &description, &keywords,
+			&createdAt, &updatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan gost: %w", err)
+		}
+
+		gost.Status = status.String
+		gost.SourceType = sourceType.String
+		gost.SourceURL = sourceURL.String
+		gost.Description = description.String
+		gost.Keywords = keywords.String
+		if updatedAt.Valid {
+			gost.UpdatedAt = updatedAt.Time
+		}
+		if createdAt.Valid {
+			gost.CreatedAt = createdAt.Time
+		} else {
+			gost.CreatedAt = gost.UpdatedAt
+		}
+		if adoptionDate.Valid {
+			gost.AdoptionDate = &adoptionDate.Time
+		}
+		if effectiveDate.Valid {
+			gost.EffectiveDate = &effectiveDate.Time
+		}
+		if sourceID.Valid {
+			id := int(sourceID.Int64)
+			gost.SourceID = &id
+		}
+
+		gosts = append(gosts, gost)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate gosts: %w", err)
+	}
+
+	return gosts, nil
+}
@@ -0,0 +1,127 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Статусы ключа идемпотентности
+const (
+	IdempotencyStatusInProgress = "in_progress" // первый запрос с ключом еще выполняется
+	IdempotencyStatusCompleted  = "completed"   // ответ сохранен и возвращается на повторы
+)
+
+// IdempotencyRecord сохраненный результат запроса с заголовком Idempotency-Key
+type IdempotencyRecord struct {
+	Key          string
+	Scope        string // метод и путь запроса, один ключ можно использовать на разных эндпоинтах
+	Status       string
+	StatusCode   int
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    time.Time
+	CompletedAt  *time.Time
+}
+
+// CreateIdempotencyKeysTable создает таблицу ключей идемпотентности для повторяемых POST-запросов
+func CreateIdempotencyKeysTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS idempotency_keys (
+			idempotency_key TEXT NOT NULL,
+			scope TEXT NOT NULL,
+			status TEXT NOT NULL DEFAULT 'in_progress' CHECK (status IN ('in_progress', 'completed')),
+			status_code INTEGER NOT NULL DEFAULT 0,
+			content_type TEXT,
+			response_body BLOB,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			completed_at TIMESTAMP,
+			PRIMARY KEY (idempotency_key, scope)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys(created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create idempotency_keys table: %w", err)
+	}
+	return nil
+}
+
+// ReserveIdempotencyKey резервирует ключ за текущим запросом.
+// Если ключ свободен (или его запись старше ttl), возвращает nil: запрос нужно выполнить
+// и затем вызвать CompleteIdempotencyKey или ReleaseIdempotencyKey.
+// Если ключ уже использован, возвращает существующую запись (в работе или с сохраненным ответом).
+func (db *ServiceDB) ReserveIdempotencyKey(key, scope string, ttl time.Duration) (*IdempotencyRecord, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Просроченные ключи удаляем целиком, чтобы таблица не росла
+	if _, err := tx.Exec(`DELETE FROM idempotency_keys WHERE created_at < datetime('now', ?)`,
+		fmt.Sprintf("-%d seconds", int64(ttl/time.Second))); err != nil {
+		return nil, fmt.Errorf("failed to purge expired idempotency keys: %w", err)
+	}
+
+	result, err := tx.Exec(`
+		INSERT OR IGNORE INTO idempotency_keys (idempotency_key, scope, status)
+		VALUES (?, ?, ?)
+	`, key, scope, IdempotencyStatusInProgress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+
+	var record *IdempotencyRecord
+	if inserted == 0 {
+		record = &IdempotencyRecord{}
+		var contentType sql.NullString
+		var completedAt sql.NullTime
+		err = tx.QueryRow(`
+			SELECT idempotency_key, scope, status, status_code, content_type, response_body, created_at, completed_at
+			FROM idempotency_keys
+			WHERE idempotency_key = ? AND scope = ?
+		`, key, scope).Scan(&record.Key, &record.Scope, &record.Status, &record.StatusCode,
+			&contentType, &record.ResponseBody, &record.CreatedAt, &completedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+		record.ContentType = contentType.String
+		if completedAt.Valid {
+			record.CompletedAt = &completedAt.Time
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit idempotency key: %w", err)
+	}
+
+	return record, nil
+}
+
+// CompleteIdempotencyKey сохраняет ответ на запрос, зарезервировавший ключ
+func (db *ServiceDB) CompleteIdempotencyKey(key, scope string, statusCode int, contentType string, body []byte) error {
+	_, err := db.conn.Exec(`
+		UPDATE idempotency_keys
+		SET status = ?, status_code = ?, content_type = ?, response_body = ?, completed_at = CURRENT_TIMESTAMP
+		WHERE idempotency_key = ? AND scope = ?
+	`, IdempotencyStatusCompleted, statusCode, contentType, body, key, scope)
+	if err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// ReleaseIdempotencyKey освобождает ключ, если запрос завершился ошибкой и его можно повторить
+func (db *ServiceDB) ReleaseIdempotencyKey(key, scope string) error {
+	_, err := db.conn.Exec(`DELETE FROM idempotency_keys WHERE idempotency_key = ? AND scope = ?`, key, scope)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
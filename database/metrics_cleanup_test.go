@@ -0,0 +1,80 @@
+package database
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCleanOldMetricsBatched_MatchesSingleStatement(t *testing.T) {
+	metricsCleanupBatchPause = 0
+	defer func() { metricsCleanupBatchPause = 50 * time.Millisecond }()
+
+	// Одинаковая история в двух базах: 8 старых и 4 свежих снимков вперемешку
+	seed := func(name string) *DB {
+		t.Helper()
+		db, err := NewDB(filepath.Join(t.TempDir(), name))
+		if err != nil {
+			t.Fatalf("Failed to create DB: %v", err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		now := time.Now().UTC()
+		for i := 0; i < 12; i++ {
+			timestamp := now.Add(-time.Duration(i) * time.Hour)
+			if i%2 == 0 || i > 8 {
+				timestamp = now.AddDate(0, 0, -30-i)
+			}
+			snapshot := &PerformanceMetricsSnapshot{Timestamp: timestamp, MetricType: "ai", MetricData: "{}"}
+			if err := db.SaveMetrics(snapshot); err != nil {
+				t.Fatalf("SaveMetrics failed: %v", err)
+			}
+		}
+		return db
+	}
+
+	remaining := func(db *DB) []int {
+		t.Helper()
+		rows, err := db.conn.Query(`SELECT id FROM performance_metrics_history ORDER BY id`)
+		if err != nil {
+			t.Fatalf("Failed to query metrics: %v", err)
+		}
+		defer rows.Close()
+		ids := []int{}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				t.Fatalf("Failed to scan metric id: %v", err)
+			}
+			ids = append(ids, id)
+		}
+		return ids
+	}
+
+	single := seed("single.db")
+	if err := single.CleanOldMetrics(7); err != nil {
+		t.Fatalf("CleanOldMetrics failed: %v", err)
+	}
+
+	batched := seed("batched.db")
+	deleted, err := batched.CleanOldMetricsBatched(7, 3)
+	if err != nil {
+		t.Fatalf("CleanOldMetricsBatched failed: %v", err)
+	}
+
+	if deleted != 8 {
+		t.Errorf("Expected 8 deleted rows, got %d", deleted)
+	}
+	if got, want := remaining(batched), remaining(single); !reflect.DeepEqual(got, want) {
+		t.Errorf("Batched cleanup kept %v, single statement kept %v", got, want)
+	}
+	if got := len(remaining(batched)); got != 4 {
+		t.Errorf("Expected 4 recent snapshots to remain, got %d", got)
+	}
+
+	// Повторная очистка ничего не удаляет
+	if deleted, err := batched.CleanOldMetricsBatched(7, 3); err != nil || deleted != 0 {
+		t.Errorf("Expected nothing to delete on second run, got %d (%v)", deleted, err)
+	}
+}
@@ -0,0 +1,141 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// MigrateNormalizationConfigProjects добавляет в normalization_config колонку client_project_id,
+// чтобы у каждого проекта могла быть своя конфигурация. Старая таблица допускала только строку
+// id = 1 (CHECK), поэтому она пересоздается; существующая строка сохраняется как глобальная
+// конфигурация с client_project_id = NULL.
+func MigrateNormalizationConfigProjects(db *sql.DB) error {
+	var hasProjectColumn bool
+	err := db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM pragma_table_info('normalization_config')
+			WHERE name = 'client_project_id'
+		)
+	`).Scan(&hasProjectColumn)
+	if err != nil {
+		return fmt.Errorf("failed to check normalization_config columns: %w", err)
+	}
+	if hasProjectColumn {
+		return nil
+	}
+
+	var hasWebsearchRules bool
+	err = db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM pragma_table_info('normalization_config')
+			WHERE name = 'websearch_rules'
+		)
+	`).Scan(&hasWebsearchRules)
+	if err != nil {
+		return fmt.Errorf("failed to check normalization_config columns: %w", err)
+	}
+
+	copyColumns := "id, database_path, source_table, reference_column, code_column, name_column, created_at, updated_at"
+	if hasWebsearchRules {
+		copyColumns += ", websearch_rules"
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	statements := []string{
+		`CREATE TABLE normalization_config_new (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			client_project_id INTEGER UNIQUE REFERENCES client_projects(id) ON DELETE CASCADE,
+			database_path TEXT NOT NULL,
+			source_table TEXT NOT NULL,
+			reference_column TEXT NOT NULL,
+			code_column TEXT NOT NULL,
+			name_column TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			websearch_rules TEXT DEFAULT '{}'
+		)`,
+		fmt.Sprintf(`INSERT INTO normalization_config_new (%s) SELECT %s FROM normalization_config`, copyColumns, copyColumns),
+		`DROP TABLE normalization_config`,
+		`ALTER TABLE normalization_config_new RENAME TO normalization_config`,
+		`INSERT OR IGNORE INTO normalization_config (id, database_path, source_table, reference_column, code_column, name_column)
+		VALUES (1, '', 'catalog_items', 'reference', 'code', 'name')`,
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("failed to migrate normalization_config: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit normalization_config migration: %w", err)
+	}
+	return nil
+}
+
+// GetNormalizationConfigForProject получает конфигурацию нормализации проекта.
+// Если у проекта нет собственной конфигурации, возвращается глобальная (см. GetNormalizationConfig).
+func (db *ServiceDB) GetNormalizationConfigForProject(projectID int) (*NormalizationConfig, error) {
+	query := `
+		SELECT id, client_project_id, database_path, source_table, reference_column, code_column, name_column, created_at, updated_at
+		FROM normalization_config
+		WHERE client_project_id = ?
+	`
+
+	config := &NormalizationConfig{}
+	var clientProjectID sql.NullInt64
+	err := db.conn.QueryRow(query, projectID).Scan(
+		&config.ID, &clientProjectID, &config.DatabasePath, &config.SourceTable,
+		&config.ReferenceColumn, &config.CodeColumn, &config.NameColumn,
+		&config.CreatedAt, &config.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return db.GetNormalizationConfig()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project normalization config: %w", err)
+	}
+
+	if clientProjectID.Valid {
+		id := int(clientProjectID.Int64)
+		config.ClientProjectID = &id
+	}
+	return config, nil
+}
+
+// UpdateNormalizationConfigForProject создает или обновляет собственную конфигурацию нормализации проекта.
+// Глобальная конфигурация не изменяется.
+func (db *ServiceDB) UpdateNormalizationConfigForProject(projectID int, databasePath, sourceTable, referenceColumn, codeColumn, nameColumn string) error {
+	query := `
+		INSERT INTO normalization_config (client_project_id, database_path, source_table, reference_column, code_column, name_column)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(client_project_id) DO UPDATE SET
+			database_path = excluded.database_path,
+			source_table = excluded.source_table,
+			reference_column = excluded.reference_column,
+			code_column = excluded.code_column,
+			name_column = excluded.name_column,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	_, err := db.conn.Exec(query, projectID, databasePath, sourceTable, referenceColumn, codeColumn, nameColumn)
+	if err != nil {
+		return fmt.Errorf("failed to update project normalization config: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteNormalizationConfigForProject удаляет собственную конфигурацию проекта,
+// после чего для проекта снова используется глобальная конфигурация
+func (db *ServiceDB) DeleteNormalizationConfigForProject(projectID int) error {
+	_, err := db.conn.Exec(`DELETE FROM normalization_config WHERE client_project_id = ?`, projectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete project normalization config: %w", err)
+	}
+	return nil
+}
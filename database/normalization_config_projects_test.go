@@ -0,0 +1,132 @@
+package database
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetNormalizationConfigForProject(t *testing.T) {
+	db := newTestServiceDB(t)
+
+	client, err := db.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	projectA, err := db.CreateClientProject(client.ID, "Проект A", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	projectB, err := db.CreateClientProject(client.ID, "Проект B", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+
+	if err := db.UpdateNormalizationConfig("global.db", "catalog_items", "reference", "code", "name"); err != nil {
+		t.Fatalf("UpdateNormalizationConfig failed: %v", err)
+	}
+	if err := db.UpdateNormalizationConfigForProject(projectA.ID, "a.db", "nomenclature_items", "ref", "article", "title"); err != nil {
+		t.Fatalf("UpdateNormalizationConfigForProject failed: %v", err)
+	}
+
+	config, err := db.GetNormalizationConfigForProject(projectA.ID)
+	if err != nil {
+		t.Fatalf("GetNormalizationConfigForProject failed: %v", err)
+	}
+	if config.ClientProjectID == nil || *config.ClientProjectID != projectA.ID {
+		t.Errorf("Expected config of project %d, got %v", projectA.ID, config.ClientProjectID)
+	}
+	if config.SourceTable != "nomenclature_items" || config.CodeColumn != "article" || config.NameColumn != "title" {
+		t.Errorf("Unexpected project config: %+v", config)
+	}
+
+	// Проект без собственной конфигурации получает глобальную
+	config, err = db.GetNormalizationConfigForProject(projectB.ID)
+	if err != nil {
+		t.Fatalf("GetNormalizationConfigForProject failed: %v", err)
+	}
+	if config.ID != 1 || config.ClientProjectID != nil || config.DatabasePath != "global.db" {
+		t.Errorf("Expected global config fallback, got %+v", config)
+	}
+
+	// Обновление проекта не затрагивает глобальную конфигурацию
+	if err := db.UpdateNormalizationConfigForProject(projectA.ID, "a2.db", "items", "ref", "code", "name"); err != nil {
+		t.Fatalf("UpdateNormalizationConfigForProject failed: %v", err)
+	}
+	if config, _ = db.GetNormalizationConfigForProject(projectA.ID); config.DatabasePath != "a2.db" {
+		t.Errorf("Expected updated project config, got %+v", config)
+	}
+	if global, _ := db.GetNormalizationConfig(); global.DatabasePath != "global.db" || global.SourceTable != "catalog_items" {
+		t.Errorf("Global config must not change, got %+v", global)
+	}
+
+	if err := db.DeleteNormalizationConfigForProject(projectA.ID); err != nil {
+		t.Fatalf("DeleteNormalizationConfigForProject failed: %v", err)
+	}
+	if config, _ = db.GetNormalizationConfigForProject(projectA.ID); config.ID != 1 {
+		t.Errorf("Expected fallback after delete, got %+v", config)
+	}
+}
+
+func TestMigrateNormalizationConfigProjects_KeepsGlobalRow(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "service.db")
+
+	// Таблица в старом формате: допускается только строка id = 1
+	conn, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("Failed to open DB: %v", err)
+	}
+	_, err = conn.Exec(`
+		CREATE TABLE normalization_config (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			database_path TEXT NOT NULL,
+			source_table TEXT NOT NULL,
+			reference_column TEXT NOT NULL,
+			code_column TEXT NOT NULL,
+			name_column TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			websearch_rules TEXT DEFAULT '{}'
+		);
+		INSERT INTO normalization_config (id, database_path, source_table, reference_column, code_column, name_column, websearch_rules)
+		VALUES (1, 'legacy.db', 'legacy_items', 'ref', 'code', 'name', '{"enabled":true}');
+	`)
+	conn.Close()
+	if err != nil {
+		t.Fatalf("Failed to create legacy table: %v", err)
+	}
+
+	db, err := NewServiceDB(dbPath)
+	if err != nil {
+		t.Fatalf("NewServiceDB failed: %v", err)
+	}
+	defer db.Close()
+
+	config, err := db.GetNormalizationConfig()
+	if err != nil {
+		t.Fatalf("GetNormalizationConfig failed: %v", err)
+	}
+	if config.DatabasePath != "legacy.db" || config.SourceTable != "legacy_items" {
+		t.Errorf("Expected legacy row to be kept as global config, got %+v", config)
+	}
+
+	var rules string
+	if err := db.conn.QueryRow(`SELECT websearch_rules FROM normalization_config WHERE id = 1`).Scan(&rules); err != nil {
+		t.Fatalf("Failed to read websearch_rules: %v", err)
+	}
+	if rules != `{"enabled":true}` {
+		t.Errorf("Expected websearch_rules to be kept, got %q", rules)
+	}
+
+	client, err := db.CreateClient("Клиент", "", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	if err := db.UpdateNormalizationConfigForProject(project.ID, "p.db", "items", "ref", "code", "name"); err != nil {
+		t.Fatalf("Expected project config after migration, got error: %v", err)
+	}
+}
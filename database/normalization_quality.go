@@ -0,0 +1,107 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// normalizationQualityTopGroups количество крупнейших групп совпадений в NormalizationQuality
+const normalizationQualityTopGroups = 10
+
+// NormalizationCollisionGroup нормализованное название, к которому сведено несколько исходных записей
+type NormalizationCollisionGroup struct {
+	NormalizedName string   `json:"normalized_name"`
+	Count          int      `json:"count"`
+	Originals      []string `json:"originals"` // Различные исходные названия группы
+}
+
+// NormalizationQuality сводка уникальности нормализованных названий эталонов проекта
+type NormalizationQuality struct {
+	ProjectID          int                           `json:"project_id"`
+	Category           string                        `json:"category,omitempty"`
+	TotalOriginals     int                           `json:"total_originals"`
+	DistinctOriginals  int                           `json:"distinct_originals"`
+	DistinctNormalized int                           `json:"distinct_normalized"`
+	CompressionRatio   float64                       `json:"compression_ratio"` // TotalOriginals / DistinctNormalized
+	CollisionGroups    []NormalizationCollisionGroup `json:"collision_groups"`
+}
+
+// GetNormalizationQuality считает, сколько различных нормализованных названий приходится на исходные
+// записи эталонов проекта, и возвращает крупнейшие группы совпадений. Пустой category - все категории.
+// Нормализованные названия сравниваются без учета пробелов по краям.
+func (db *ServiceDB) GetNormalizationQuality(projectID int, category string) (NormalizationQuality, error) {
+	quality := NormalizationQuality{ProjectID: projectID, Category: category, CollisionGroups: []NormalizationCollisionGroup{}}
+
+	where := "client_project_id = ?"
+	args := []interface{}{projectID}
+	if category = strings.TrimSpace(category); category != "" {
+		where += " AND category = ?"
+		args = append(args, category)
+	}
+
+	err := db.conn.QueryRow(fmt.Sprintf(`
+		SELECT COUNT(*), COUNT(DISTINCT TRIM(original_name)), COUNT(DISTINCT TRIM(normalized_name))
+		FROM client_benchmarks
+		WHERE %s
+	`, where), args...).Scan(&quality.TotalOriginals, &quality.DistinctOriginals, &quality.DistinctNormalized)
+	if err != nil {
+		return quality, fmt.Errorf("failed to count benchmarks: %w", err)
+	}
+	if quality.DistinctNormalized > 0 {
+		quality.CompressionRatio = float64(quality.TotalOriginals) / float64(quality.DistinctNormalized)
+	}
+
+	rows, err := db.conn.Query(fmt.Sprintf(`
+		SELECT TRIM(normalized_name) AS name, COUNT(*) AS cnt
+		FROM client_benchmarks
+		WHERE %s
+		GROUP BY name
+		HAVING cnt > 1
+		ORDER BY cnt DESC, name
+		LIMIT ?
+	`, where), append(args, normalizationQualityTopGroups)...)
+	if err != nil {
+		return quality, fmt.Errorf("failed to get collision groups: %w", err)
+	}
+	for rows.Next() {
+		var group NormalizationCollisionGroup
+		if err := rows.Scan(&group.NormalizedName, &group.Count); err != nil {
+			rows.Close()
+			return quality, fmt.Errorf("failed to scan collision group: %w", err)
+		}
+		quality.CollisionGroups = append(quality.CollisionGroups, group)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return quality, fmt.Errorf("failed to iterate collision groups: %w", err)
+	}
+	rows.Close()
+
+	for i := range quality.CollisionGroups {
+		group := &quality.CollisionGroups[i]
+		originals, err := db.conn.Query(fmt.Sprintf(`
+			SELECT DISTINCT TRIM(original_name)
+			FROM client_benchmarks
+			WHERE %s AND TRIM(normalized_name) = ?
+			ORDER BY 1
+		`, where), append(args, group.NormalizedName)...)
+		if err != nil {
+			return quality, fmt.Errorf("failed to get originals of %q: %w", group.NormalizedName, err)
+		}
+		for originals.Next() {
+			var original string
+			if err := originals.Scan(&original); err != nil {
+				originals.Close()
+				return quality, fmt.Errorf("failed to scan original name: %w", err)
+			}
+			group.Originals = append(group.Originals, original)
+		}
+		err = originals.Err()
+		originals.Close()
+		if err != nil {
+			return quality, fmt.Errorf("failed to iterate originals of %q: %w", group.NormalizedName, err)
+		}
+	}
+
+	return quality, nil
+}
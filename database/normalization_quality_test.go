@@ -0,0 +1,85 @@
+package database
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestServiceDB_GetNormalizationQuality(t *testing.T) {
+	db := newTestServiceDB(t)
+
+	client, err := db.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	other, err := db.CreateClientProject(client.ID, "Другой проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+
+	seed := []struct {
+		projectID            int
+		original, normalized string
+		category             string
+	}{
+		{project.ID, "Болт М10", "болт м10", "nomenclature"},
+		{project.ID, "БОЛТ М10", "болт м10", "nomenclature"},
+		{project.ID, "Болт  М10", "болт м10 ", "nomenclature"},
+		{project.ID, "Гайка М10", "гайка м10", "nomenclature"},
+		{project.ID, "Гайка м10", "гайка м10", "nomenclature"},
+		{project.ID, "Шайба", "шайба", "nomenclature"},
+		{project.ID, "ООО Ромашка", "ромашка", "counterparty"},
+		{project.ID, "Ромашка ООО", "ромашка", "counterparty"},
+		{other.ID, "Болт М10", "болт м10", "nomenclature"},
+	}
+	for _, item := range seed {
+		if _, err := db.CreateClientBenchmark(item.projectID, item.original, item.normalized, item.category, "", "{}", "", 0.8); err != nil {
+			t.Fatalf("CreateClientBenchmark %q failed: %v", item.original, err)
+		}
+	}
+
+	quality, err := db.GetNormalizationQuality(project.ID, "nomenclature")
+	if err != nil {
+		t.Fatalf("GetNormalizationQuality failed: %v", err)
+	}
+	if quality.TotalOriginals != 6 || quality.DistinctNormalized != 3 {
+		t.Errorf("Expected 6 originals and 3 normalized names, got %+v", quality)
+	}
+	if math.Abs(quality.CompressionRatio-2.0) > 1e-9 {
+		t.Errorf("Expected compression ratio 2.0, got %v", quality.CompressionRatio)
+	}
+	if len(quality.CollisionGroups) != 2 {
+		t.Fatalf("Expected 2 collision groups, got %+v", quality.CollisionGroups)
+	}
+	first := quality.CollisionGroups[0]
+	if first.NormalizedName != "болт м10" || first.Count != 3 {
+		t.Errorf("Expected largest group 'болт м10' x3, got %+v", first)
+	}
+	if want := []string{"БОЛТ М10", "Болт  М10", "Болт М10"}; !reflect.DeepEqual(first.Originals, want) {
+		t.Errorf("Expected originals %v, got %v", want, first.Originals)
+	}
+	if second := quality.CollisionGroups[1]; second.NormalizedName != "гайка м10" || second.Count != 2 {
+		t.Errorf("Expected second group 'гайка м10' x2, got %+v", second)
+	}
+
+	all, err := db.GetNormalizationQuality(project.ID, "")
+	if err != nil {
+		t.Fatalf("GetNormalizationQuality failed: %v", err)
+	}
+	if all.TotalOriginals != 8 || all.DistinctNormalized != 4 {
+		t.Errorf("Expected 8 originals and 4 normalized names across categories, got %+v", all)
+	}
+
+	empty, err := db.GetNormalizationQuality(project.ID, "unknown")
+	if err != nil {
+		t.Fatalf("GetNormalizationQuality failed: %v", err)
+	}
+	if empty.TotalOriginals != 0 || empty.CompressionRatio != 0 || !reflect.DeepEqual(empty.CollisionGroups, []NormalizationCollisionGroup{}) {
+		t.Errorf("Expected empty quality, got %+v", empty)
+	}
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -246,7 +247,7 @@ func TestNormalizationSessions_MultipleDatabases(t *testing.T) {
 		projectDB, err := db.CreateProjectDatabase(
 			project.ID,
 			"Test DB",
-			"/test/path.db",
+			fmt.Sprintf("/test/path_%d.db", i),
 			"Test Database",
 			1024,
 		)
@@ -303,3 +304,59 @@ func TestNormalizationSessions_MultipleDatabases(t *testing.T) {
 	}
 }
 
+
+// TestNormalizationSessions_PhaseTimings проверяет сохранение и чтение времени по фазам сессии
+func TestNormalizationSessions_PhaseTimings(t *testing.T) {
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	client, err := db.CreateClient("Test Client", "Test Client Legal Name", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Test Project", "counterparty", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	sessionID, timings, err := db.GetLatestSessionPhaseTimings(project.ID)
+	if err != nil {
+		t.Fatalf("GetLatestSessionPhaseTimings failed: %v", err)
+	}
+	if sessionID != 0 || timings != nil {
+		t.Errorf("Expected no timings for project without sessions, got session %d", sessionID)
+	}
+
+	projectDB, err := db.CreateProjectDatabase(project.ID, "Test DB", "/test/timings.db", "", 0)
+	if err != nil {
+		t.Fatalf("Failed to create project database: %v", err)
+	}
+	sessionID, err = db.CreateNormalizationSession(projectDB.ID, 0, 0)
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+
+	saved := map[string]time.Duration{
+		"ai":       1500 * time.Millisecond,
+		"db_write": 250 * time.Millisecond,
+	}
+	if err := db.SaveSessionPhaseTimings(sessionID, saved); err != nil {
+		t.Fatalf("SaveSessionPhaseTimings failed: %v", err)
+	}
+
+	gotSessionID, got, err := db.GetLatestSessionPhaseTimings(project.ID)
+	if err != nil {
+		t.Fatalf("GetLatestSessionPhaseTimings failed: %v", err)
+	}
+	if gotSessionID != sessionID {
+		t.Errorf("Expected session %d, got %d", sessionID, gotSessionID)
+	}
+	for phase, duration := range saved {
+		if got[phase] != duration {
+			t.Errorf("Phase %s: expected %v, got %v", phase, duration, got[phase])
+		}
+	}
+}
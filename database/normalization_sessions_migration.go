@@ -41,6 +41,8 @@ func MigrateNormalizationSessions(db *sql.DB) error {
 		`ALTER TABLE normalization_sessions ADD COLUMN priority INTEGER DEFAULT 0`,
 		`ALTER TABLE normalization_sessions ADD COLUMN timeout_seconds INTEGER DEFAULT 3600`,
 		`ALTER TABLE normalization_sessions ADD COLUMN last_activity_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP`,
+		// JSON с временем по фазам нормализации (наносекунды)
+		`ALTER TABLE normalization_sessions ADD COLUMN phase_timings TEXT`,
 	}
 	
 	for _, alterSQL := range alterTableSQL {
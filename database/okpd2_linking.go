@@ -0,0 +1,235 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultOkpd2LinkMinConfidence минимальная уверенность AI, при которой номенклатура связывается с кодом ОКПД2
+const DefaultOkpd2LinkMinConfidence = 0.7
+
+// DefaultOkpd2LinkNegativeCacheTTL срок, в течение которого ответ "код не найден" берется из кэша.
+// После него наименование снова отправляется в AI: справочник ОКПД2 мог пополниться.
+const DefaultOkpd2LinkNegativeCacheTTL = 30 * 24 * time.Hour
+
+// Okpd2LinkCacheEntry сохраненный ответ AI для наименования номенклатуры.
+// Пустой Code означает, что подходящий код не найден: такое наименование не отправляется
+// повторно, пока запись не старше срока жизни отрицательного ответа.
+type Okpd2LinkCacheEntry struct {
+	NameKey    string    `json:"name_key"`
+	Code       string    `json:"code"`
+	Confidence float64   `json:"confidence"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// UnlinkedNomenclature номенклатура эталонов без ссылки на ОКПД2
+type UnlinkedNomenclature struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Okpd2Candidate код ОКПД2 из короткого списка кандидатов для AI
+type Okpd2Candidate struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Okpd2LinkNameKey ключ кэша связывания: наименование без регистра и лишних пробелов
+func Okpd2LinkNameKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// CreateOkpd2LinkCacheTable создает постоянный кэш ответов AI при связывании номенклатуры с ОКПД2
+func CreateOkpd2LinkCacheTable(db *sql.DB) error {
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS okpd2_link_cache (
+			name_key TEXT PRIMARY KEY,
+			okpd2_code TEXT NOT NULL DEFAULT '',
+			confidence REAL NOT NULL DEFAULT 0,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create okpd2_link_cache table: %w", err)
+	}
+	return nil
+}
+
+// GetOkpd2LinkCache возвращает сохраненный ответ по ключу наименования или nil, если его нет
+func (db *ServiceDB) GetOkpd2LinkCache(nameKey string) (*Okpd2LinkCacheEntry, error) {
+	entry := &Okpd2LinkCacheEntry{}
+	err := db.conn.QueryRow(`
+		SELECT name_key, okpd2_code, confidence, created_at
+		FROM okpd2_link_cache
+		WHERE name_key = ?
+	`, nameKey).Scan(&entry.NameKey, &entry.Code, &entry.Confidence, &entry.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get okpd2 link cache: %w", err)
+	}
+	return entry, nil
+}
+
+// SaveOkpd2LinkCache сохраняет ответ AI для наименования, перезаписывая предыдущий
+func (db *ServiceDB) SaveOkpd2LinkCache(entry *Okpd2LinkCacheEntry) error {
+	_, err := db.conn.Exec(`
+		INSERT INTO okpd2_link_cache (name_key, okpd2_code, confidence)
+		VALUES (?, ?, ?)
+		ON CONFLICT(name_key) DO UPDATE SET
+			okpd2_code = excluded.okpd2_code,
+			confidence = excluded.confidence,
+			created_at = CURRENT_TIMESTAMP
+	`, entry.NameKey, entry.Code, entry.Confidence)
+	if err != nil {
+		return fmt.Errorf("failed to save okpd2 link cache: %w", err)
+	}
+	return nil
+}
+
+// GetUnlinkedNomenclatures возвращает номенклатуры эталонов без okpd2_reference_id.
+// limit <= 0 означает без ограничения.
+func (db *ServiceDB) GetUnlinkedNomenclatures(limit int) ([]UnlinkedNomenclature, error) {
+	query := `
+		SELECT id, COALESCE(NULLIF(TRIM(normalized_name), ''), original_name)
+		FROM client_benchmarks
+		WHERE category = 'nomenclature' AND okpd2_reference_id IS NULL
+		ORDER BY id
+	`
+	args := []interface{}{}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unlinked nomenclatures: %w", err)
+	}
+	defer rows.Close()
+
+	var items []UnlinkedNomenclature
+	for rows.Next() {
+		var item UnlinkedNomenclature
+		if err := rows.Scan(&item.ID, &item.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan unlinked nomenclature: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate unlinked nomenclatures: %w", err)
+	}
+	return items, nil
+}
+
+// SearchOkpd2Candidates подбирает короткий список кодов ОКПД2 для наименования:
+// коды, в названии которых встречается больше слов наименования, идут первыми.
+func (db *ServiceDB) SearchOkpd2Candidates(name string, limit int) ([]Okpd2Candidate, error) {
+	var words []string
+	for _, word := range strings.Fields(strings.ToLower(name)) {
+		word = strings.Trim(word, ".,;:()\"'«»")
+		// Короткие слова и размеры ("м8", "10х20") только засоряют выборку
+		if len([]rune(word)) >= 4 {
+			words = append(words, word)
+		}
+	}
+	if len(words) == 0 || limit <= 0 {
+		return []Okpd2Candidate{}, nil
+	}
+
+	stems := make([]string, 0, len(words))
+	conditions := make([]string, 0, len(words))
+	args := make([]interface{}, 0, 2*len(words))
+	for _, word := range words {
+		// Окончание отбрасывается, чтобы "болты" находили "болт"
+		stem := []rune(word)
+		if len(stem) > 5 {
+			stem = stem[:len(stem)-2]
+		} else if len(stem) > 4 {
+			stem = stem[:len(stem)-1]
+		}
+		stems = append(stems, string(stem))
+
+		// LIKE в SQLite не учитывает регистр только для ASCII, поэтому ищем и слово с заглавной буквы
+		capitalized := strings.ToUpper(string(stem[:1])) + string(stem[1:])
+		conditions = append(conditions, "name LIKE ? OR name LIKE ?")
+		args = append(args, "%"+string(stem)+"%", "%"+capitalized+"%")
+	}
+
+	rows, err := db.conn.Query(
+		fmt.Sprintf(`SELECT code, name FROM okpd2_classifier WHERE %s`, strings.Join(conditions, " OR ")),
+		args...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search okpd2 candidates: %w", err)
+	}
+	defer rows.Close()
+
+	type scored struct {
+		candidate Okpd2Candidate
+		score     int
+	}
+	var found []scored
+	for rows.Next() {
+		var candidate Okpd2Candidate
+		if err := rows.Scan(&candidate.Code, &candidate.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan okpd2 candidate: %w", err)
+		}
+		lowerName := strings.ToLower(candidate.Name)
+		score := 0
+		for _, stem := range stems {
+			if strings.Contains(lowerName, stem) {
+				score++
+			}
+		}
+		if score > 0 {
+			found = append(found, scored{candidate, score})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate okpd2 candidates: %w", err)
+	}
+
+	// При равном числе совпадений более длинный код точнее
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].score != found[j].score {
+			return found[i].score > found[j].score
+		}
+		return len(found[i].candidate.Code) > len(found[j].candidate.Code)
+	})
+
+	if len(found) > limit {
+		found = found[:limit]
+	}
+	candidates := make([]Okpd2Candidate, 0, len(found))
+	for _, item := range found {
+		candidates = append(candidates, item.candidate)
+	}
+	return candidates, nil
+}
+
+// LinkNomenclatureOkpd2 связывает номенклатуру эталона с кодом ОКПД2 из справочника
+func (db *ServiceDB) LinkNomenclatureOkpd2(benchmarkID int, code string) error {
+	var okpd2ID int
+	err := db.conn.QueryRow(`SELECT id FROM okpd2_classifier WHERE code = ?`, code).Scan(&okpd2ID)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("okpd2 code %s not found", code)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get okpd2 code: %w", err)
+	}
+
+	_, err = db.conn.Exec(`
+		UPDATE client_benchmarks
+		SET okpd2_reference_id = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, okpd2ID, benchmarkID)
+	if err != nil {
+		return fmt.Errorf("failed to link nomenclature with okpd2: %w", err)
+	}
+	return nil
+}
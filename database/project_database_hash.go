@@ -0,0 +1,81 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ComputeFileSHA256 возвращает SHA-256 содержимого файла в шестнадцатеричном виде
+func ComputeFileSHA256(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file for hashing: %w", err)
+	}
+	defer file.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, file); err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// MigrateProjectDatabaseContentHash добавляет в project_databases поле content_hash (SHA-256 содержимого файла)
+// и индекс для поиска одинаковых выгрузок в пределах проекта.
+// Существующие записи не пересчитываются: хеш заполняется при регистрации новых файлов.
+func MigrateProjectDatabaseContentHash(db *sql.DB) error {
+	if _, err := db.Exec(`ALTER TABLE project_databases ADD COLUMN content_hash TEXT`); err != nil {
+		errStr := strings.ToLower(err.Error())
+		if !strings.Contains(errStr, "duplicate column") && !strings.Contains(errStr, "already exists") {
+			return fmt.Errorf("failed to add content_hash column: %w", err)
+		}
+	}
+
+	if _, err := db.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_project_databases_content_hash
+		ON project_databases(client_project_id, content_hash)
+	`); err != nil {
+		return fmt.Errorf("failed to create content_hash index: %w", err)
+	}
+
+	return nil
+}
+
+// GetProjectDatabaseByHash возвращает базу данных проекта с тем же содержимым файла.
+// Если такой базы нет, возвращает nil без ошибки.
+func (db *ServiceDB) GetProjectDatabaseByHash(projectID int, contentHash string) (*ProjectDatabase, error) {
+	if contentHash == "" {
+		return nil, nil
+	}
+
+	var id int
+	err := db.conn.QueryRow(`
+		SELECT id FROM project_databases
+		WHERE client_project_id = ? AND content_hash = ?
+		ORDER BY id
+		LIMIT 1
+	`, projectID, contentHash).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project database by hash: %w", err)
+	}
+
+	return db.GetProjectDatabase(id)
+}
+
+// projectDatabaseContentHash вычисляет хеш файла для записи в project_databases.
+// Отсутствующий или нечитаемый файл не мешает регистрации: хеш остается пустым.
+func projectDatabaseContentHash(filePath string) sql.NullString {
+	hash, err := ComputeFileSHA256(filePath)
+	if err != nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: hash, Valid: true}
+}
@@ -0,0 +1,357 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// windowsDrivePathRegex путь Windows с буквой диска ("C:/data/db.db") после замены обратных слешей
+var windowsDrivePathRegex = regexp.MustCompile(`^[A-Za-z]:/`)
+
+// projectDatabaseReferences таблицы сервисной БД, ссылающиеся на project_databases.
+// При слиянии дубликатов ссылки переносятся на оставшуюся запись.
+var projectDatabaseReferences = []struct {
+	table  string
+	column string
+}{
+	{"counterparty_databases", "project_database_id"},
+	{"normalization_sessions", "project_database_id"},
+	{"database_table_metadata", "database_id"},
+}
+
+// CanonicalDatabasePath приводит путь к файлу БД к каноническому абсолютному виду:
+// прямые слеши, без "." и "..", с раскрытыми символическими ссылками (если файл существует).
+// Пути Windows (с буквой диска или на Windows) приводятся к нижнему регистру, так как файловая система
+// не различает регистр. Разные написания одного физического файла дают одинаковый результат.
+// Для баз в памяти (":memory:", "file::memory:...") возвращается пустая строка: это не файл,
+// и каждое подключение к такой базе - отдельная база.
+func CanonicalDatabasePath(filePath string) string {
+	p := strings.ReplaceAll(strings.TrimSpace(filePath), `\`, "/")
+	if p == "" || isInMemoryDatabasePath(p) {
+		return ""
+	}
+
+	// Путь Windows на другой ОС нельзя разрешить через filepath - только нормализуем
+	if runtime.GOOS != "windows" && windowsDrivePathRegex.MatchString(p) {
+		return strings.ToLower(path.Clean(p))
+	}
+
+	native := filepath.FromSlash(p)
+	if abs, err := filepath.Abs(native); err == nil {
+		native = abs
+	}
+	if resolved, err := filepath.EvalSymlinks(native); err == nil {
+		native = resolved
+	}
+
+	p = filepath.ToSlash(native)
+	if runtime.GOOS == "windows" {
+		p = strings.ToLower(p)
+	}
+	return p
+}
+
+// isInMemoryDatabasePath проверяет, что путь указывает на базу SQLite в памяти
+func isInMemoryDatabasePath(p string) bool {
+	return p == ":memory:" || strings.HasPrefix(p, "file::memory:") || strings.Contains(p, "mode=memory")
+}
+
+// nullableAbsPath значение abs_path для записи: NULL, если канонического пути нет (база в памяти)
+func nullableAbsPath(absPath string) interface{} {
+	if absPath == "" {
+		return nil
+	}
+	return absPath
+}
+
+// projectDatabaseAbsPathTriggers запрещают регистрировать один файл дважды у одного клиента.
+// Уникальный индекс по abs_path не подходит: клиент определяется через client_projects,
+// а разные клиенты могут ссылаться на один и тот же файл. Текст ошибки совпадает с ошибкой
+// уникального индекса, чтобы ее распознавал isUniqueConstraintError.
+var projectDatabaseAbsPathTriggers = []string{
+	`CREATE TRIGGER IF NOT EXISTS trg_project_databases_abs_path_insert
+	BEFORE INSERT ON project_databases
+	WHEN NEW.abs_path IS NOT NULL AND NEW.abs_path != '' AND EXISTS (
+		SELECT 1 FROM project_databases d
+		JOIN client_projects p ON p.id = d.client_project_id
+		WHERE d.abs_path = NEW.abs_path
+		  AND p.client_id = (SELECT client_id FROM client_projects WHERE id = NEW.client_project_id)
+	)
+	BEGIN
+		SELECT RAISE(ABORT, 'UNIQUE constraint failed: project_databases.abs_path');
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS trg_project_databases_abs_path_update
+	BEFORE UPDATE OF abs_path, client_project_id ON project_databases
+	WHEN NEW.abs_path IS NOT NULL AND NEW.abs_path != '' AND EXISTS (
+		SELECT 1 FROM project_databases d
+		JOIN client_projects p ON p.id = d.client_project_id
+		WHERE d.abs_path = NEW.abs_path AND d.id != NEW.id
+		  AND p.client_id = (SELECT client_id FROM client_projects WHERE id = NEW.client_project_id)
+	)
+	BEGIN
+		SELECT RAISE(ABORT, 'UNIQUE constraint failed: project_databases.abs_path');
+	END`,
+}
+
+// MigrateProjectDatabaseAbsPath добавляет в project_databases поле abs_path с каноническим путем,
+// заполняет его для существующих записей, объединяет записи одного и того же файла в пределах клиента
+// и создает триггеры, запрещающие повторную регистрацию файла у того же клиента.
+func MigrateProjectDatabaseAbsPath(db *sql.DB) error {
+	if _, err := db.Exec(`ALTER TABLE project_databases ADD COLUMN abs_path TEXT`); err != nil {
+		errStr := strings.ToLower(err.Error())
+		if !strings.Contains(errStr, "duplicate column") && !strings.Contains(errStr, "already exists") {
+			return fmt.Errorf("failed to add abs_path column: %w", err)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := backfillProjectDatabaseAbsPath(tx); err != nil {
+		return err
+	}
+
+	merged, err := mergeDuplicateProjectDatabases(tx)
+	if err != nil {
+		return err
+	}
+
+	// Уникальный индекс по abs_path из ранней версии миграции запрещал общий файл у разных клиентов
+	if _, err := tx.Exec(`DROP INDEX IF EXISTS idx_project_databases_abs_path`); err != nil {
+		return fmt.Errorf("failed to drop abs_path unique index: %w", err)
+	}
+	if _, err := tx.Exec(`CREATE INDEX IF NOT EXISTS idx_project_databases_abs_path_lookup ON project_databases(abs_path)`); err != nil {
+		return fmt.Errorf("failed to create abs_path index: %w", err)
+	}
+	for _, trigger := range projectDatabaseAbsPathTriggers {
+		if _, err := tx.Exec(trigger); err != nil {
+			return fmt.Errorf("failed to create abs_path trigger: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit abs_path migration: %w", err)
+	}
+
+	if merged > 0 {
+		log.Printf("[Migration] Merged %d duplicate project database registrations", merged)
+	}
+	return nil
+}
+
+// backfillProjectDatabaseAbsPath заполняет abs_path для записей, где он не задан.
+// Базам в памяти abs_path не назначается (см. CanonicalDatabasePath).
+func backfillProjectDatabaseAbsPath(tx *sql.Tx) error {
+	// Ранняя версия миграции сохраняла для ":memory:" путь в текущем каталоге
+	if _, err := tx.Exec(`
+		UPDATE project_databases SET abs_path = NULL
+		WHERE abs_path IS NOT NULL
+		  AND (file_path = ':memory:' OR file_path LIKE 'file::memory:%' OR file_path LIKE '%mode=memory%')
+	`); err != nil {
+		return fmt.Errorf("failed to clear abs_path of in-memory databases: %w", err)
+	}
+
+	rows, err := tx.Query(`SELECT id, file_path FROM project_databases WHERE abs_path IS NULL OR abs_path = ''`)
+	if err != nil {
+		return fmt.Errorf("failed to get project databases without abs_path: %w", err)
+	}
+
+	type pathRow struct {
+		id      int
+		absPath string
+	}
+	var pending []pathRow
+	for rows.Next() {
+		var id int
+		var filePath string
+		if err := rows.Scan(&id, &filePath); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan project database path: %w", err)
+		}
+		if absPath := CanonicalDatabasePath(filePath); absPath != "" {
+			pending = append(pending, pathRow{id: id, absPath: absPath})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("error iterating project database paths: %w", err)
+	}
+	rows.Close()
+
+	for _, row := range pending {
+		if _, err := tx.Exec(`UPDATE project_databases SET abs_path = ? WHERE id = ?`, row.absPath, row.id); err != nil {
+			return fmt.Errorf("failed to backfill abs_path for project database %d: %w", row.id, err)
+		}
+	}
+	return nil
+}
+
+// mergeDuplicateProjectDatabases оставляет по одной (самой ранней) записи на каждый abs_path в пределах
+// клиента, переносит на нее ссылки из зависимых таблиц и удаляет дубликаты. Записи разных клиентов
+// с общим файлом не объединяются. Возвращает число удаленных записей.
+func mergeDuplicateProjectDatabases(tx *sql.Tx) (int, error) {
+	rows, err := tx.Query(`
+		SELECT d.id, k.keep_id
+		FROM project_databases d
+		JOIN client_projects p ON p.id = d.client_project_id
+		JOIN (
+			SELECT pd.abs_path, cp.client_id, MIN(pd.id) AS keep_id
+			FROM project_databases pd
+			JOIN client_projects cp ON cp.id = pd.client_project_id
+			WHERE pd.abs_path IS NOT NULL AND pd.abs_path != ''
+			GROUP BY pd.abs_path, cp.client_id
+			HAVING COUNT(*) > 1
+		) k ON k.abs_path = d.abs_path AND k.client_id = p.client_id
+		WHERE d.id != k.keep_id
+	`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find duplicate project databases: %w", err)
+	}
+
+	duplicates := make(map[int]int)
+	for rows.Next() {
+		var id, keepID int
+		if err := rows.Scan(&id, &keepID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan duplicate project database: %w", err)
+		}
+		duplicates[id] = keepID
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return 0, fmt.Errorf("error iterating duplicate project databases: %w", err)
+	}
+	rows.Close()
+
+	for id, keepID := range duplicates {
+		for _, ref := range projectDatabaseReferences {
+			// OR IGNORE: ссылки, уже существующие у оставшейся записи, удалятся каскадно вместе с дубликатом
+			query := fmt.Sprintf(`UPDATE OR IGNORE %s SET %s = ? WHERE %s = ?`, ref.table, ref.column, ref.column)
+			if _, err := tx.Exec(query, keepID, id); err != nil {
+				if strings.Contains(strings.ToLower(err.Error()), "no such table") {
+					continue
+				}
+				return 0, fmt.Errorf("failed to move %s references from project database %d to %d: %w", ref.table, id, keepID, err)
+			}
+		}
+
+		if _, err := tx.Exec(`DELETE FROM project_databases WHERE id = ?`, id); err != nil {
+			return 0, fmt.Errorf("failed to delete duplicate project database %d: %w", id, err)
+		}
+	}
+
+	return len(duplicates), nil
+}
+
+// findProjectDatabaseByAbsPath возвращает ID записи, зарегистрированной для того же физического файла
+// у клиента проекта projectID (0 - у любого клиента)
+func (db *ServiceDB) findProjectDatabaseByAbsPath(absPath string, projectID, excludeID int) (int, error) {
+	if absPath == "" {
+		return 0, nil
+	}
+
+	var id int
+	err := db.conn.QueryRow(`
+		SELECT d.id
+		FROM project_databases d
+		JOIN client_projects p ON p.id = d.client_project_id
+		WHERE d.abs_path = ? AND d.id != ?
+		  AND (? = 0 OR p.client_id = (SELECT client_id FROM client_projects WHERE id = ?))
+		ORDER BY d.id
+		LIMIT 1
+	`, absPath, excludeID, projectID, projectID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to check project database path: %w", err)
+	}
+	return id, nil
+}
+
+// isUniqueConstraintError проверяет, что ошибка вызвана нарушением уникального индекса
+func isUniqueConstraintError(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "unique constraint failed")
+}
+
+// RelinkRestoredProjectDatabase возвращает в работу запись project_databases для файла,
+// восстановленного из резервной копии: запись ищется по каноническому пути, становится активной,
+// размер и хэш содержимого обновляются по файлу. Если файл зарегистрирован у нескольких клиентов,
+// обновляются все записи, а возвращается самая ранняя. Возвращает nil, если файл не зарегистрирован ни в одном проекте.
+func (db *ServiceDB) RelinkRestoredProjectDatabase(filePath string) (*ProjectDatabase, error) {
+	absPath := CanonicalDatabasePath(filePath)
+	id, err := db.findProjectDatabaseByAbsPath(absPath, 0, 0)
+	if err != nil || id == 0 {
+		return nil, err
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat restored database %s: %w", filePath, err)
+	}
+
+	_, err = db.conn.Exec(`
+		UPDATE project_databases
+		SET is_active = TRUE, file_size = ?, content_hash = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE abs_path = ?
+	`, info.Size(), projectDatabaseContentHash(filePath), absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to relink project databases for %s: %w", filePath, err)
+	}
+
+	return db.GetProjectDatabase(id)
+}
+
+// ResolveProjectDatabasePath находит файл базы данных проекта по пути из project_databases.
+// Путь мог быть сохранен относительно корня приложения или каталога data, с обратными слешами
+// или абсолютным путем другой машины, поэтому проверяются варианты по порядку:
+// путь как есть, data/<путь>, data/uploads/<имя файла>, uploads/<имя файла>.
+// Возвращает абсолютный путь первого существующего файла или ошибку, совместимую с os.ErrNotExist.
+func ResolveProjectDatabasePath(storedPath string) (string, error) {
+	trimmed := strings.TrimSpace(storedPath)
+	if trimmed == "" {
+		return "", fmt.Errorf("%w: empty database path", os.ErrNotExist)
+	}
+
+	native := filepath.FromSlash(strings.ReplaceAll(trimmed, `\`, "/"))
+	fileName := filepath.Base(native)
+	candidates := []string{native}
+	if !filepath.IsAbs(native) && !windowsDrivePathRegex.MatchString(filepath.ToSlash(native)) {
+		candidates = append(candidates, filepath.Join("data", native))
+	}
+	candidates = append(candidates,
+		filepath.Join("data", "uploads", fileName),
+		filepath.Join("uploads", fileName),
+	)
+
+	checked := make([]string, 0, len(candidates))
+	seen := make(map[string]bool, len(candidates))
+	for _, candidate := range candidates {
+		candidate = filepath.Clean(candidate)
+		if seen[candidate] {
+			continue
+		}
+		seen[candidate] = true
+		checked = append(checked, candidate)
+
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			absPath, err := filepath.Abs(candidate)
+			if err != nil {
+				return "", fmt.Errorf("failed to resolve database path %s: %w", candidate, err)
+			}
+			return absPath, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: database file %s (checked: %s)", os.ErrNotExist, storedPath, strings.Join(checked, ", "))
+}
@@ -0,0 +1,69 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveProjectDatabasePath(t *testing.T) {
+	root := t.TempDir()
+	t.Chdir(root)
+
+	uploadsDir := filepath.Join(root, "data", "uploads")
+	if err := os.MkdirAll(uploadsDir, 0755); err != nil {
+		t.Fatalf("Failed to create uploads dir: %v", err)
+	}
+	uploadPath := filepath.Join(uploadsDir, "upload.db")
+	mainPath := filepath.Join(root, "main.db")
+	for _, path := range []string{uploadPath, mainPath} {
+		if err := os.WriteFile(path, []byte("SQLite format 3\x00"), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		storedPath string
+		want       string
+	}{
+		{"relative as is", "data/uploads/upload.db", uploadPath},
+		{"relative to data dir", "uploads/upload.db", uploadPath},
+		{"windows separators", `data\uploads\upload.db`, uploadPath},
+		{"bare file name", "upload.db", uploadPath},
+		{"root relative", "./main.db", mainPath},
+		{"absolute", uploadPath, uploadPath},
+		{"absolute from another machine", "/srv/app/data/uploads/upload.db", uploadPath},
+		{"windows absolute", `C:\app\data\uploads\upload.db`, uploadPath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveProjectDatabasePath(tt.storedPath)
+			if err != nil {
+				t.Fatalf("ResolveProjectDatabasePath(%q) failed: %v", tt.storedPath, err)
+			}
+			if !filepath.IsAbs(got) {
+				t.Errorf("Expected absolute path, got %q", got)
+			}
+			if CanonicalDatabasePath(got) != CanonicalDatabasePath(tt.want) {
+				t.Errorf("ResolveProjectDatabasePath(%q) = %q, want %q", tt.storedPath, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveProjectDatabasePath_Missing(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(filepath.Join("data", "uploads", "dir.db"), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+
+	for _, storedPath := range []string{"", "data/uploads/missing.db", "/srv/missing.db", "dir.db"} {
+		_, err := ResolveProjectDatabasePath(storedPath)
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("ResolveProjectDatabasePath(%q): expected os.ErrNotExist, got %v", storedPath, err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package database
+
+import (
+	"bytes"
+	"compress/gzip"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DefaultMetricDetailsCompressThreshold размер details метрики качества (байт JSON),
+// начиная с которого details сохраняются сжатыми gzip
+const DefaultMetricDetailsCompressThreshold = 4096
+
+// SetMetricDetailsCompression задает порог сжатия details метрик качества в SaveQualityMetric.
+// 0 отключает сжатие. Чтение сжатых и несжатых записей от настройки не зависит.
+func (db *DB) SetMetricDetailsCompression(threshold int) {
+	db.metricDetailsCompressThreshold = threshold
+}
+
+// MigrateDataQualityMetricsCompression добавляет в data_quality_metrics признак сжатия details.
+// Если таблицы нет (сервисная БД), ничего не делает.
+func MigrateDataQualityMetricsCompression(db *sql.DB) error {
+	var exists bool
+	if err := db.QueryRow(`
+		SELECT COUNT(*) > 0 FROM sqlite_master
+		WHERE type = 'table' AND name = 'data_quality_metrics'
+	`).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check data_quality_metrics table existence: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+
+	if _, err := db.Exec(`ALTER TABLE data_quality_metrics ADD COLUMN details_compressed INTEGER NOT NULL DEFAULT 0`); err != nil {
+		errStr := strings.ToLower(err.Error())
+		if !strings.Contains(errStr, "duplicate column") && !strings.Contains(errStr, "already exists") {
+			return fmt.Errorf("failed to add details_compressed column: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// encodeMetricDetails сериализует details в JSON и сжимает его gzip, если JSON не меньше threshold байт.
+// Возвращает значение для колонки details и признак сжатия.
+func encodeMetricDetails(details map[string]interface{}, threshold int) ([]byte, bool, error) {
+	if details == nil {
+		return []byte{}, false, nil
+	}
+
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal details: %w", err)
+	}
+	if threshold <= 0 || len(data) < threshold {
+		return data, false, nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, false, fmt.Errorf("failed to compress details: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to compress details: %w", err)
+	}
+	return buf.Bytes(), true, nil
+}
+
+// decodeMetricDetails восстанавливает details из значения колонки
+func decodeMetricDetails(raw []byte, compressed bool) (map[string]interface{}, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	if compressed {
+		reader, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress details: %w", err)
+		}
+		defer reader.Close()
+		if raw, err = io.ReadAll(reader); err != nil {
+			return nil, fmt.Errorf("failed to decompress details: %w", err)
+		}
+	}
+
+	var details map[string]interface{}
+	if err := json.Unmarshal(raw, &details); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal details: %w", err)
+	}
+	return details, nil
+}
+
+// metricDetailsCompressedColumn возвращает выражение признака сжатия details для SELECT.
+// В сервисной БД таблицу метрик могут создать без details_compressed: тогда все details считаются несжатыми.
+func metricDetailsCompressedColumn(conn *sql.DB) string {
+	var hasColumn bool
+	conn.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM pragma_table_info('data_quality_metrics')
+			WHERE name='details_compressed'
+		)
+	`).Scan(&hasColumn)
+
+	if hasColumn {
+		return "details_compressed"
+	}
+	return "0"
+}
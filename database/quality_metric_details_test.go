@@ -0,0 +1,78 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestQualityMetricDetails_CompressedRoundTrip(t *testing.T) {
+	// Метрики качества хранятся в основной БД, database_id - ID базы проекта в сервисной БД
+	db := newUploadRecordsTestDB(t, "metrics.db")
+	const projectDatabaseID = 7
+	upload := createTestUpload(t, db, "metrics-upload", projectDatabaseID)
+
+	items := make([]interface{}, 0, 500)
+	for i := 0; i < 500; i++ {
+		items = append(items, fmt.Sprintf("Номенклатура без артикула №%d", i))
+	}
+	large := map[string]interface{}{"items": items, "source": "completeness_check"}
+	small := map[string]interface{}{"items": []interface{}{"одна запись"}}
+
+	db.SetMetricDetailsCompression(1024)
+	for name, details := range map[string]map[string]interface{}{"large": large, "small": small} {
+		metric := &DataQualityMetric{
+			UploadID:       upload.ID,
+			DatabaseID:     projectDatabaseID,
+			MetricCategory: "completeness",
+			MetricName:     name,
+			MetricValue:    0.5,
+			Status:         "WARNING",
+			MeasuredAt:     time.Now(),
+			Details:        details,
+		}
+		if err := db.SaveQualityMetric(metric); err != nil {
+			t.Fatalf("SaveQualityMetric(%s) failed: %v", name, err)
+		}
+	}
+
+	var compressed bool
+	var storedSize int
+	if err := db.conn.QueryRow(`
+		SELECT details_compressed, length(details) FROM data_quality_metrics WHERE metric_name = 'large'
+	`).Scan(&compressed, &storedSize); err != nil {
+		t.Fatalf("Failed to read stored details: %v", err)
+	}
+	if !compressed {
+		t.Fatal("Expected large details to be stored compressed")
+	}
+	if err := db.conn.QueryRow(`
+		SELECT details_compressed FROM data_quality_metrics WHERE metric_name = 'small'
+	`).Scan(&compressed); err != nil {
+		t.Fatalf("Failed to read stored details: %v", err)
+	}
+	if compressed {
+		t.Error("Expected details below threshold to be stored as plain JSON")
+	}
+
+	metrics, err := db.GetQualityMetricsForDatabases([]int{projectDatabaseID}, QualityMetricsPeriodStart("day", time.Now()))
+	if err != nil {
+		t.Fatalf("GetQualityMetricsForDatabases failed: %v", err)
+	}
+	if len(metrics) != 2 {
+		t.Fatalf("Expected 2 metrics, got %d", len(metrics))
+	}
+	for _, metric := range metrics {
+		want := len(small["items"].([]interface{}))
+		if metric.MetricName == "large" {
+			want = len(items)
+			if metric.Details["source"] != "completeness_check" {
+				t.Errorf("Unexpected decompressed details source: %v", metric.Details["source"])
+			}
+		}
+		got, _ := metric.Details["items"].([]interface{})
+		if len(got) != want {
+			t.Errorf("Metric %s: expected %d detail items, got %d", metric.MetricName, want, len(got))
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ReferenceEntry запись справочника (ОКПД2, ТН ВЭД, КПВЭД) для сравнения версий
+type ReferenceEntry struct {
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	ParentCode string `json:"parent_code,omitempty"`
+	Level      int    `json:"level,omitempty"`
+	OldName    string `json:"old_name,omitempty"` // Прежнее название (только для переименованных кодов)
+}
+
+// referenceBookTable описание таблицы справочника
+type referenceBookTable struct {
+	table           string // таблица справочника
+	benchmarkColumn string // колонка client_benchmarks со ссылкой на справочник (пусто - ссылок нет)
+}
+
+// referenceBookTables справочники, поддерживаемые DiffReferenceBook и ApplyReferenceBookDiff
+var referenceBookTables = map[string]referenceBookTable{
+	"okpd2": {table: "okpd2_classifier", benchmarkColumn: "okpd2_reference_id"},
+	"tnved": {table: "tnved_reference", benchmarkColumn: "tnved_reference_id"},
+	"kpved": {table: "kpved_classifier"},
+}
+
+// lookupReferenceBook возвращает описание таблицы справочника по имени
+func lookupReferenceBook(book string) (referenceBookTable, error) {
+	info, ok := referenceBookTables[strings.ToLower(book)]
+	if !ok {
+		return referenceBookTable{}, fmt.Errorf("unknown reference book: %s", book)
+	}
+	return info, nil
+}
+
+// Okpd2EntriesToReference преобразует записи ОКПД2 в записи для сравнения
+func Okpd2EntriesToReference(entries []Okpd2Entry) []ReferenceEntry {
+	result := make([]ReferenceEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = ReferenceEntry{Code: entry.Code, Name: entry.Name, ParentCode: entry.ParentCode, Level: entry.Level}
+	}
+	return result
+}
+
+// TnvedEntriesToReference преобразует записи ТН ВЭД в записи для сравнения
+func TnvedEntriesToReference(entries []TnvedEntry) []ReferenceEntry {
+	result := make([]ReferenceEntry, len(entries))
+	for i, entry := range entries {
+		result[i] = ReferenceEntry{Code: entry.Code, Name: entry.Name, ParentCode: entry.ParentCode, Level: entry.Level}
+	}
+	return result
+}
+
+// DiffReferenceBook сравнивает новую версию справочника с загруженной в БД, не изменяя данные.
+// added - коды, которых нет в БД; removed - коды из БД, отсутствующие в новой версии;
+// renamed - коды с изменившимся названием (OldName - название в БД).
+// При повторе кода в incoming учитывается первое вхождение. Результаты отсортированы по коду.
+func DiffReferenceBook(conn DBConnection, book string, incoming []ReferenceEntry) (added, removed, renamed []ReferenceEntry, err error) {
+	info, err := lookupReferenceBook(book)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	rows, err := conn.GetDB().Query(fmt.Sprintf(
+		`SELECT code, COALESCE(name, ''), COALESCE(parent_code, ''), COALESCE(level, 0) FROM %s`, info.table))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to read %s: %w", info.table, err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]ReferenceEntry)
+	for rows.Next() {
+		var entry ReferenceEntry
+		if err := rows.Scan(&entry.Code, &entry.Name, &entry.ParentCode, &entry.Level); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to scan %s entry: %w", info.table, err)
+		}
+		existing[entry.Code] = entry
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("error iterating %s: %w", info.table, err)
+	}
+
+	seen := make(map[string]bool, len(incoming))
+	for _, entry := range incoming {
+		code := strings.TrimSpace(entry.Code)
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+		entry.Code = code
+
+		current, ok := existing[code]
+		switch {
+		case !ok:
+			added = append(added, entry)
+		case strings.TrimSpace(current.Name) != strings.TrimSpace(entry.Name):
+			entry.OldName = current.Name
+			renamed = append(renamed, entry)
+		}
+	}
+
+	for code, entry := range existing {
+		if !seen[code] {
+			removed = append(removed, entry)
+		}
+	}
+
+	sortReferenceEntries(added)
+	sortReferenceEntries(removed)
+	sortReferenceEntries(renamed)
+
+	return added, removed, renamed, nil
+}
+
+// sortReferenceEntries сортирует записи по коду
+func sortReferenceEntries(entries []ReferenceEntry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+}
+
+// ApplyReferenceBookDiff применяет результат DiffReferenceBook одной транзакцией:
+// добавляет новые коды, обновляет названия переименованных и удаляет исчезнувшие.
+// Ссылки эталонов на удаленные коды обнуляются, ссылки на остальные коды сохраняются.
+func ApplyReferenceBookDiff(conn DBConnection, book string, added, removed, renamed []ReferenceEntry) error {
+	info, err := lookupReferenceBook(book)
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.GetDB().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, entry := range added {
+		parentCode := sql.NullString{String: entry.ParentCode, Valid: entry.ParentCode != ""}
+		if _, err := tx.Exec(fmt.Sprintf(`INSERT INTO %s (code, name, parent_code, level) VALUES (?, ?, ?, ?)`, info.table),
+			entry.Code, entry.Name, parentCode, entry.Level); err != nil {
+			return fmt.Errorf("failed to add %s entry %s: %w", book, entry.Code, err)
+		}
+	}
+
+	for _, entry := range renamed {
+		if _, err := tx.Exec(fmt.Sprintf(`UPDATE %s SET name = ? WHERE code = ?`, info.table),
+			entry.Name, entry.Code); err != nil {
+			return fmt.Errorf("failed to rename %s entry %s: %w", book, entry.Code, err)
+		}
+	}
+
+	for _, entry := range removed {
+		if info.benchmarkColumn != "" {
+			if _, err := tx.Exec(fmt.Sprintf(
+				`UPDATE client_benchmarks SET %[1]s = NULL WHERE %[1]s IN (SELECT id FROM %[2]s WHERE code = ?)`,
+				info.benchmarkColumn, info.table), entry.Code); err != nil {
+				return fmt.Errorf("failed to unlink benchmarks from %s entry %s: %w", book, entry.Code, err)
+			}
+		}
+		if _, err := tx.Exec(fmt.Sprintf(`DELETE FROM %s WHERE code = ?`, info.table), entry.Code); err != nil {
+			return fmt.Errorf("failed to remove %s entry %s: %w", book, entry.Code, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// WriteReferenceBookDiff выводит результат DiffReferenceBook в формате text, csv или json
+func WriteReferenceBookDiff(w io.Writer, format string, added, removed, renamed []ReferenceEntry) error {
+	switch strings.ToLower(format) {
+	case "", "text":
+		fmt.Fprintf(w, "Добавлено: %d, удалено: %d, переименовано: %d\n", len(added), len(removed), len(renamed))
+		for _, entry := range added {
+			fmt.Fprintf(w, "+ %s: %s\n", entry.Code, entry.Name)
+		}
+		for _, entry := range removed {
+			fmt.Fprintf(w, "- %s: %s\n", entry.Code, entry.Name)
+		}
+		for _, entry := range renamed {
+			fmt.Fprintf(w, "~ %s: %s -> %s\n", entry.Code, entry.OldName, entry.Name)
+		}
+		return nil
+
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"change", "code", "name", "old_name"}); err != nil {
+			return err
+		}
+		for _, group := range []struct {
+			change  string
+			entries []ReferenceEntry
+		}{{"added", added}, {"removed", removed}, {"renamed", renamed}} {
+			for _, entry := range group.entries {
+				if err := writer.Write([]string{group.change, entry.Code, entry.Name, entry.OldName}); err != nil {
+					return err
+				}
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string][]ReferenceEntry{
+			"added":   nonNilReferenceEntries(added),
+			"removed": nonNilReferenceEntries(removed),
+			"renamed": nonNilReferenceEntries(renamed),
+		})
+
+	default:
+		return fmt.Errorf("unsupported diff format: %s", format)
+	}
+}
+
+// nonNilReferenceEntries заменяет nil пустым срезом, чтобы в JSON выводился [] вместо null
+func nonNilReferenceEntries(entries []ReferenceEntry) []ReferenceEntry {
+	if entries == nil {
+		return []ReferenceEntry{}
+	}
+	return entries
+}
@@ -0,0 +1,219 @@
+package database
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// referenceCodes возвращает коды записей в исходном порядке
+func referenceCodes(entries []ReferenceEntry) []string {
+	codes := make([]string, len(entries))
+	for i, entry := range entries {
+		codes[i] = entry.Code
+	}
+	return codes
+}
+
+func setupReferenceDiffDB(t *testing.T) *ServiceDB {
+	t.Helper()
+
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := LoadOkpd2ToDatabase(db, []Okpd2Entry{
+		{Code: "25", Name: "Изделия металлические готовые", Level: 1},
+		{Code: "25.9", Name: "Изделия металлические прочие", ParentCode: "25", Level: 2},
+		{Code: "25.94", Name: "Изделия крепежные", ParentCode: "25.9", Level: 3},
+		{Code: "25.99", Name: "Изделия прочие", ParentCode: "25.9", Level: 3},
+	}); err != nil {
+		t.Fatalf("Failed to load OKPD2: %v", err)
+	}
+
+	return db
+}
+
+func TestDiffReferenceBook_OverlappingChangedRemoved(t *testing.T) {
+	db := setupReferenceDiffDB(t)
+
+	incoming := []ReferenceEntry{
+		{Code: "25", Name: "Изделия металлические готовые", Level: 1},                                 // без изменений
+		{Code: "25.94", Name: "Изделия крепежные и винтовые крепежные", ParentCode: "25.9", Level: 3}, // переименован
+		{Code: "25.93", Name: "Изделия из проволоки", ParentCode: "25.9", Level: 3},                   // добавлен
+		{Code: "25.9", Name: "Изделия металлические прочие", ParentCode: "25", Level: 2},              // без изменений
+		{Code: "25.93", Name: "Повтор кода", ParentCode: "25.9", Level: 3},                            // повтор игнорируется
+		{Code: " 25 ", Name: "Изделия металлические готовые ", Level: 1},                              // пробелы не считаются изменением
+	}
+
+	added, removed, renamed, err := DiffReferenceBook(db, "okpd2", incoming)
+	if err != nil {
+		t.Fatalf("DiffReferenceBook failed: %v", err)
+	}
+
+	if codes := referenceCodes(added); !reflect.DeepEqual(codes, []string{"25.93"}) {
+		t.Errorf("added = %v, want [25.93]", codes)
+	}
+	if added[0].Name != "Изделия из проволоки" {
+		t.Errorf("added name = %q, want first occurrence", added[0].Name)
+	}
+	if codes := referenceCodes(removed); !reflect.DeepEqual(codes, []string{"25.99"}) {
+		t.Errorf("removed = %v, want [25.99]", codes)
+	}
+	if codes := referenceCodes(renamed); !reflect.DeepEqual(codes, []string{"25.94"}) {
+		t.Fatalf("renamed = %v, want [25.94]", codes)
+	}
+	if renamed[0].OldName != "Изделия крепежные" || renamed[0].Name != "Изделия крепежные и винтовые крепежные" {
+		t.Errorf("renamed entry = %+v", renamed[0])
+	}
+
+	// Сравнение не изменяет справочник
+	var count int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM okpd2_classifier`).Scan(&count); err != nil {
+		t.Fatalf("Failed to count OKPD2: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("DiffReferenceBook mutated okpd2_classifier: %d rows, want 4", count)
+	}
+
+	// Применение отличий приводит справочник к новой версии, повторное сравнение пустое
+	if err := ApplyReferenceBookDiff(db, "okpd2", added, removed, renamed); err != nil {
+		t.Fatalf("ApplyReferenceBookDiff failed: %v", err)
+	}
+	added, removed, renamed, err = DiffReferenceBook(db, "okpd2", incoming)
+	if err != nil {
+		t.Fatalf("DiffReferenceBook after apply failed: %v", err)
+	}
+	if len(added)+len(removed)+len(renamed) != 0 {
+		t.Errorf("Expected empty diff after apply, got added=%v removed=%v renamed=%v", added, removed, renamed)
+	}
+}
+
+func TestApplyReferenceBookDiff_KeepsBenchmarkLinks(t *testing.T) {
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	if err := LoadTnvedToDatabase(db, []TnvedEntry{
+		newTnvedEntry("7318", "Болты"),
+		newTnvedEntry("7319", "Иглы"),
+	}); err != nil {
+		t.Fatalf("Failed to load TNVED: %v", err)
+	}
+
+	kept, err := db.FindOrCreateTNVEDReference("7318", "")
+	if err != nil {
+		t.Fatalf("Failed to find TNVED reference: %v", err)
+	}
+	dropped, err := db.FindOrCreateTNVEDReference("7319", "")
+	if err != nil {
+		t.Fatalf("Failed to find TNVED reference: %v", err)
+	}
+
+	benchmarkID := createTestBenchmark(t, db)
+	first, err := db.GetClientBenchmark(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetClientBenchmark failed: %v", err)
+	}
+	otherBenchmark, err := db.CreateClientBenchmark(first.ClientProjectID, "Иглы швейные", "Иглы", "nomenclature", "", "", "", 0.8)
+	if err != nil {
+		t.Fatalf("CreateClientBenchmark failed: %v", err)
+	}
+	otherID := otherBenchmark.ID
+	if _, err := db.conn.Exec(`UPDATE client_benchmarks SET tnved_reference_id = ? WHERE id = ?`, kept.ID, benchmarkID); err != nil {
+		t.Fatalf("Failed to link benchmark: %v", err)
+	}
+	if _, err := db.conn.Exec(`UPDATE client_benchmarks SET tnved_reference_id = ? WHERE id = ?`, dropped.ID, otherID); err != nil {
+		t.Fatalf("Failed to link benchmark: %v", err)
+	}
+
+	incoming := TnvedEntriesToReference([]TnvedEntry{
+		newTnvedEntry("7318", "Винты, болты, гайки"),
+		newTnvedEntry("7320", "Пружины"),
+	})
+	added, removed, renamed, err := DiffReferenceBook(db, "tnved", incoming)
+	if err != nil {
+		t.Fatalf("DiffReferenceBook failed: %v", err)
+	}
+	if err := ApplyReferenceBookDiff(db, "tnved", added, removed, renamed); err != nil {
+		t.Fatalf("ApplyReferenceBookDiff failed: %v", err)
+	}
+
+	benchmark, err := db.GetClientBenchmark(benchmarkID)
+	if err != nil {
+		t.Fatalf("GetClientBenchmark failed: %v", err)
+	}
+	if benchmark.TNVEDReferenceID == nil || *benchmark.TNVEDReferenceID != kept.ID {
+		t.Errorf("Link to renamed code should be kept, got %v", benchmark.TNVEDReferenceID)
+	}
+	other, err := db.GetClientBenchmark(otherID)
+	if err != nil {
+		t.Fatalf("GetClientBenchmark failed: %v", err)
+	}
+	if other.TNVEDReferenceID != nil {
+		t.Errorf("Link to removed code should be cleared, got %v", *other.TNVEDReferenceID)
+	}
+
+	rows := getTnvedReferenceRows(t, db)
+	if _, ok := rows["7319"]; ok {
+		t.Error("Removed code 7319 is still present")
+	}
+	if rows["7318"].Name != "Винты, болты, гайки" {
+		t.Errorf("7318 name = %q, want renamed", rows["7318"].Name)
+	}
+	if row, ok := rows["7320"]; !ok || row.ParentCode != "73" || row.Level != 2 {
+		t.Errorf("Added code 7320 = %+v (present=%v)", row, ok)
+	}
+}
+
+func TestDiffReferenceBook_UnknownBook(t *testing.T) {
+	db := setupReferenceDiffDB(t)
+
+	if _, _, _, err := DiffReferenceBook(db, "unknown", nil); err == nil {
+		t.Error("Expected error for unknown reference book")
+	}
+}
+
+func TestWriteReferenceBookDiff_Formats(t *testing.T) {
+	added := []ReferenceEntry{{Code: "25.93", Name: "Изделия из проволоки"}}
+	renamed := []ReferenceEntry{{Code: "25.94", Name: "Новое", OldName: "Старое"}}
+
+	var text bytes.Buffer
+	if err := WriteReferenceBookDiff(&text, "text", added, nil, renamed); err != nil {
+		t.Fatalf("text format failed: %v", err)
+	}
+	if !strings.Contains(text.String(), "+ 25.93: Изделия из проволоки") || !strings.Contains(text.String(), "~ 25.94: Старое -> Новое") {
+		t.Errorf("unexpected text output:\n%s", text.String())
+	}
+
+	var csvOut bytes.Buffer
+	if err := WriteReferenceBookDiff(&csvOut, "csv", added, nil, renamed); err != nil {
+		t.Fatalf("csv format failed: %v", err)
+	}
+	expectedCSV := "change,code,name,old_name\nadded,25.93,Изделия из проволоки,\nrenamed,25.94,Новое,Старое\n"
+	if csvOut.String() != expectedCSV {
+		t.Errorf("csv output = %q, want %q", csvOut.String(), expectedCSV)
+	}
+
+	var jsonOut bytes.Buffer
+	if err := WriteReferenceBookDiff(&jsonOut, "json", added, nil, renamed); err != nil {
+		t.Fatalf("json format failed: %v", err)
+	}
+	var decoded map[string][]ReferenceEntry
+	if err := json.Unmarshal(jsonOut.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid json output: %v", err)
+	}
+	if decoded["removed"] == nil || len(decoded["removed"]) != 0 || len(decoded["added"]) != 1 || decoded["renamed"][0].OldName != "Старое" {
+		t.Errorf("unexpected json output: %s", jsonOut.String())
+	}
+
+	if err := WriteReferenceBookDiff(&text, "xml", nil, nil, nil); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+}
@@ -32,56 +32,75 @@ type TUGOSTReference struct {
 	UpdatedAt    time.Time `json:"updated_at"`
 }
 
-// FindOrCreateTNVEDReference находит или создает запись в справочнике ТН ВЭД
+// ReferenceSourceGISPInline источник записей справочников, созданных при импорте ГИСП
+// для кодов, отсутствующих в загруженном справочнике
+const ReferenceSourceGISPInline = "gisp_inline"
+
+// normalizeReferenceCode нормализует код ОКПД2/ТН ВЭД (убирает пробелы)
+func normalizeReferenceCode(code string) string {
+	return strings.TrimSpace(strings.ReplaceAll(code, " ", ""))
+}
+
+// FindOrCreateTNVEDReference находит или создает запись в справочнике ТН ВЭД.
+// Отсутствующий код создается с источником ReferenceSourceGISPInline.
 func (db *ServiceDB) FindOrCreateTNVEDReference(code, name string) (*TNVEDReference, error) {
+	ref, err := db.FindTNVEDReference(code)
+	if err != nil || ref != nil || normalizeReferenceCode(code) == "" {
+		return ref, err
+	}
+
+	return db.CreateTNVEDInlineReference(code, name)
+}
+
+// FindTNVEDReference ищет запись справочника ТН ВЭД по коду, не создавая ее. Возвращает nil, если кода нет.
+func (db *ServiceDB) FindTNVEDReference(code string) (*TNVEDReference, error) {
+	code = normalizeReferenceCode(code)
 	if code == "" {
 		return nil, nil
 	}
 
-	// Нормализуем код (убираем пробелы)
-	code = strings.TrimSpace(strings.ReplaceAll(code, " ", ""))
-
-	// Ищем существующую запись
 	query := `SELECT id, code, name, description, parent_code, level, source, created_at, updated_at
 	          FROM tnved_reference WHERE code = ?`
 	
 	ref := &TNVEDReference{}
-	var description, parentCode, source sql.NullString
+	var name, description, parentCode, source sql.NullString
 	var level sql.NullInt64
 	
 	err := db.conn.QueryRow(query, code).Scan(
-		&ref.ID, &ref.Code, &ref.Name, &description, &parentCode, &level, &source,
+		&ref.ID, &ref.Code, &name, &description, &parentCode, &level, &source,
 		&ref.CreatedAt, &ref.UpdatedAt,
 	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search TNVED reference: %w", err)
+	}
 
-	if err == nil {
-		// Запись найдена
-		if description.Valid {
-			ref.Description = description.String
-		}
-		if parentCode.Valid {
-			ref.ParentCode = parentCode.String
-		}
-		if level.Valid {
-			ref.Level = int(level.Int64)
-		}
-		if source.Valid {
-			ref.Source = source.String
-		}
-		return ref, nil
+	ref.Name = name.String
+	ref.Description = description.String
+	ref.ParentCode = parentCode.String
+	ref.Source = source.String
+	if level.Valid {
+		ref.Level = int(level.Int64)
 	}
 
-	if err != sql.ErrNoRows {
-		return nil, fmt.Errorf("failed to search TNVED reference: %w", err)
+	return ref, nil
+}
+
+// CreateTNVEDInlineReference создает запись-заглушку ТН ВЭД для кода, отсутствующего в справочнике
+func (db *ServiceDB) CreateTNVEDInlineReference(code, name string) (*TNVEDReference, error) {
+	code = normalizeReferenceCode(code)
+	if code == "" {
+		return nil, nil
 	}
 
-	// Запись не найдена, создаем новую
 	insertQuery := `
 		INSERT INTO tnved_reference (code, name, source, created_at, updated_at)
-		VALUES (?, ?, 'gisp_gov_ru', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
 	`
 	
-	result, err := db.conn.Exec(insertQuery, code, name)
+	result, err := db.conn.Exec(insertQuery, code, name, ReferenceSourceGISPInline)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TNVED reference: %w", err)
 	}
@@ -91,16 +110,14 @@ func (db *ServiceDB) FindOrCreateTNVEDReference(code, name string) (*TNVEDRefere
 		return nil, fmt.Errorf("failed to get TNVED reference ID: %w", err)
 	}
 
-	ref = &TNVEDReference{
+	return &TNVEDReference{
 		ID:        int(id),
 		Code:      code,
 		Name:      name,
-		Source:    "gisp_gov_ru",
+		Source:    ReferenceSourceGISPInline,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
-	}
-
-	return ref, nil
+	}, nil
 }
 
 // FindOrCreateTUGOSTReference находит или создает запись в справочнике ТУ/ГОСТ
@@ -178,37 +195,49 @@ func (db *ServiceDB) FindOrCreateTUGOSTReference(code, name string) (*TUGOSTRefe
 }
 
 // FindOrCreateOKPD2Reference находит или создает запись в справочнике ОКПД2
-// Использует существующую таблицу okpd2_classifier
+// Использует существующую таблицу okpd2_classifier. Отсутствующий код создается
+// с источником ReferenceSourceGISPInline.
 func (db *ServiceDB) FindOrCreateOKPD2Reference(code, name string) (*int, error) {
+	id, err := db.FindOKPD2ReferenceID(code)
+	if err != nil || id != nil || normalizeReferenceCode(code) == "" {
+		return id, err
+	}
+
+	return db.CreateOKPD2InlineReference(code, name)
+}
+
+// FindOKPD2ReferenceID ищет запись okpd2_classifier по коду, не создавая ее. Возвращает nil, если кода нет.
+func (db *ServiceDB) FindOKPD2ReferenceID(code string) (*int, error) {
+	code = normalizeReferenceCode(code)
 	if code == "" {
 		return nil, nil
 	}
 
-	// Нормализуем код (убираем пробелы)
-	code = strings.TrimSpace(strings.ReplaceAll(code, " ", ""))
-
-	// Ищем существующую запись в okpd2_classifier
-	query := `SELECT id FROM okpd2_classifier WHERE code = ?`
-	
 	var id int
-	err := db.conn.QueryRow(query, code).Scan(&id)
-
-	if err == nil {
-		// Запись найдена
-		return &id, nil
+	err := db.conn.QueryRow(`SELECT id FROM okpd2_classifier WHERE code = ?`, code).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, nil
 	}
-
-	if err != sql.ErrNoRows {
+	if err != nil {
 		return nil, fmt.Errorf("failed to search OKPD2 reference: %w", err)
 	}
 
-	// Запись не найдена, создаем новую
+	return &id, nil
+}
+
+// CreateOKPD2InlineReference создает запись-заглушку ОКПД2 для кода, отсутствующего в классификаторе
+func (db *ServiceDB) CreateOKPD2InlineReference(code, name string) (*int, error) {
+	code = normalizeReferenceCode(code)
+	if code == "" {
+		return nil, nil
+	}
+
 	insertQuery := `
-		INSERT INTO okpd2_classifier (code, name, created_at)
-		VALUES (?, ?, CURRENT_TIMESTAMP)
+		INSERT INTO okpd2_classifier (code, name, source, created_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
 	`
 	
-	result, err := db.conn.Exec(insertQuery, code, name)
+	result, err := db.conn.Exec(insertQuery, code, name, ReferenceSourceGISPInline)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OKPD2 reference: %w", err)
 	}
@@ -218,7 +247,6 @@ func (db *ServiceDB) FindOrCreateOKPD2Reference(code, name string) (*int, error)
 		return nil, fmt.Errorf("failed to get OKPD2 reference ID: %w", err)
 	}
 
-	id = int(insertedID)
+	id := int(insertedID)
 	return &id, nil
 }
-
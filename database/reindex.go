@@ -0,0 +1,377 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Шаги обслуживания после крупных импортов. Каждый шаг идемпотентен: повторный запуск
+// без новых расхождений ничего не меняет и возвращает нулевые счетчики изменений.
+
+// Шаги обслуживания для RunReindex
+const (
+	ReindexStepGostsFTS      = "gosts_fts"
+	ReindexStepClientStats   = "client_stats"
+	ReindexStepDatabaseSizes = "database_sizes"
+	ReindexStepReferences    = "references"
+)
+
+// ReindexSteps все шаги обслуживания в порядке выполнения
+var ReindexSteps = []string{ReindexStepGostsFTS, ReindexStepClientStats, ReindexStepDatabaseSizes, ReindexStepReferences}
+
+// ReindexReport результат RunReindex: по каждому выполненному шагу либо результат, либо ошибка
+type ReindexReport struct {
+	GostsFTS      *GostsFTSRebuildResult      `json:"gosts_fts,omitempty"`
+	ClientStats   *ClientStatsRecomputeResult `json:"client_stats,omitempty"`
+	DatabaseSizes *DatabaseSizeRefreshResult  `json:"database_sizes,omitempty"`
+	References    *ReferenceRelinkResult      `json:"references,omitempty"`
+	Errors        map[string]string           `json:"errors,omitempty"`
+}
+
+// ParseReindexSteps разбирает список шагов через запятую. Пустая строка означает все шаги.
+func ParseReindexSteps(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return ReindexSteps, nil
+	}
+
+	var steps []string
+	for _, step := range strings.Split(value, ",") {
+		step = strings.ToLower(strings.TrimSpace(step))
+		if step == "" {
+			continue
+		}
+		known := false
+		for _, candidate := range ReindexSteps {
+			if candidate == step {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unknown reindex step: %s", step)
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// RunReindex выполняет указанные шаги обслуживания. Ошибка одного шага не прерывает
+// остальные и попадает в Errors. Шаг ГОСТов пропускается с ошибкой, если gostsDB равен nil.
+func RunReindex(serviceDB *ServiceDB, gostsDB *GostsDB, steps []string) *ReindexReport {
+	report := &ReindexReport{}
+	fail := func(step string, err error) {
+		if report.Errors == nil {
+			report.Errors = make(map[string]string)
+		}
+		report.Errors[step] = err.Error()
+	}
+
+	for _, step := range steps {
+		if step == ReindexStepGostsFTS {
+			if gostsDB == nil {
+				fail(step, fmt.Errorf("gosts database is not available"))
+				continue
+			}
+			result, err := gostsDB.RebuildGostsFTS()
+			if err != nil {
+				fail(step, err)
+				continue
+			}
+			report.GostsFTS = result
+			continue
+		}
+
+		if serviceDB == nil {
+			fail(step, fmt.Errorf("service database is not available"))
+			continue
+		}
+
+		var err error
+		switch step {
+		case ReindexStepClientStats:
+			report.ClientStats, err = serviceDB.RecomputeClientStatsReport()
+		case ReindexStepDatabaseSizes:
+			report.DatabaseSizes, err = serviceDB.RefreshProjectDatabaseSizes()
+		case ReindexStepReferences:
+			report.References, err = serviceDB.RelinkMissingReferences()
+		default:
+			err = fmt.Errorf("unknown reindex step: %s", step)
+		}
+		if err != nil {
+			fail(step, err)
+		}
+	}
+
+	return report
+}
+
+// GostsFTSRebuildResult результат перестроения полнотекстового индекса ГОСТов
+type GostsFTSRebuildResult struct {
+	Gosts   int `json:"gosts"`   // ГОСТов в таблице
+	Missing int `json:"missing"` // ГОСТов, отсутствовавших в индексе
+	Stale   int `json:"stale"`   // Записей индекса без соответствующего ГОСТа
+}
+
+// ClientStatsRecomputeResult результат пересчета кэшированной статистики клиентов
+type ClientStatsRecomputeResult struct {
+	Clients int   `json:"clients"`
+	Changed []int `json:"changed,omitempty"` // ID клиентов, у которых статистика отличалась
+}
+
+// DatabaseSizeChange изменение размера файла базы данных проекта
+type DatabaseSizeChange struct {
+	DatabaseID int    `json:"database_id"`
+	FilePath   string `json:"file_path"`
+	OldSize    int64  `json:"old_size"`
+	NewSize    int64  `json:"new_size"`
+}
+
+// DatabaseSizeRefreshResult результат обновления размеров файлов баз данных проектов
+type DatabaseSizeRefreshResult struct {
+	Checked int                  `json:"checked"`
+	Updated []DatabaseSizeChange `json:"updated,omitempty"`
+	Missing []string             `json:"missing,omitempty"` // Файлы, которых нет на диске (размер не меняется)
+}
+
+// ReferenceRelinkResult результат привязки эталонов к кодам, появившимся в справочниках
+type ReferenceRelinkResult struct {
+	Checked      int            `json:"checked"`       // Эталонов с кодами, отсутствовавшими в справочниках
+	Relinked     map[string]int `json:"relinked"`      // Справочник -> количество восстановленных связей
+	StillMissing map[string]int `json:"still_missing"` // Справочник -> коды, которых по-прежнему нет
+}
+
+// RebuildGostsFTS перестраивает индекс gosts_fts из таблицы gosts и сообщает, сколько
+// записей индекса расходилось с таблицей до перестроения
+func (db *GostsDB) RebuildGostsFTS() (*GostsFTSRebuildResult, error) {
+	result := &GostsFTSRebuildResult{}
+
+	err := db.conn.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM gosts),
+			(SELECT COUNT(*) FROM gosts WHERE rowid NOT IN (SELECT docid FROM gosts_fts_docsize)),
+			(SELECT COUNT(*) FROM gosts_fts_docsize WHERE docid NOT IN (SELECT rowid FROM gosts))
+	`).Scan(&result.Gosts, &result.Missing, &result.Stale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check gosts_fts: %w", err)
+	}
+
+	if _, err := db.conn.Exec(`INSERT INTO gosts_fts(gosts_fts) VALUES('rebuild')`); err != nil {
+		return nil, fmt.Errorf("failed to rebuild gosts_fts: %w", err)
+	}
+
+	return result, nil
+}
+
+// RecomputeClientStatsReport пересчитывает кэшированную статистику всех клиентов
+// и возвращает клиентов, у которых она расходилась с фактической
+func (db *ServiceDB) RecomputeClientStatsReport() (*ClientStatsRecomputeResult, error) {
+	const snapshotQuery = `SELECT id, project_count, benchmark_count, COALESCE(last_activity, '') FROM clients ORDER BY id`
+
+	before, err := db.clientStatsSnapshot(snapshotQuery)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.RecomputeAllClientStats(); err != nil {
+		return nil, err
+	}
+	after, err := db.clientStatsSnapshot(snapshotQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ClientStatsRecomputeResult{Clients: len(after)}
+	for id, stats := range after {
+		if before[id] != stats {
+			result.Changed = append(result.Changed, id)
+		}
+	}
+	sort.Ints(result.Changed)
+
+	return result, nil
+}
+
+func (db *ServiceDB) clientStatsSnapshot(query string) (map[int]string, error) {
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client stats: %w", err)
+	}
+	defer rows.Close()
+
+	snapshot := make(map[int]string)
+	for rows.Next() {
+		var id, projects, benchmarks int
+		var lastActivity interface{}
+		if err := rows.Scan(&id, &projects, &benchmarks, &lastActivity); err != nil {
+			return nil, fmt.Errorf("failed to scan client stats: %w", err)
+		}
+		snapshot[id] = fmt.Sprintf("%d|%d|%s", projects, benchmarks, normalizeTimestampValue(lastActivity))
+	}
+	return snapshot, rows.Err()
+}
+
+// RefreshProjectDatabaseSizes обновляет file_size баз данных проектов по фактическому размеру файлов.
+// Записи, файлов которых нет на диске, не изменяются и возвращаются в Missing.
+func (db *ServiceDB) RefreshProjectDatabaseSizes() (*DatabaseSizeRefreshResult, error) {
+	rows, err := db.conn.Query(`SELECT id, file_path, COALESCE(file_size, 0) FROM project_databases ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project databases: %w", err)
+	}
+
+	result := &DatabaseSizeRefreshResult{}
+	for rows.Next() {
+		var change DatabaseSizeChange
+		if err := rows.Scan(&change.DatabaseID, &change.FilePath, &change.OldSize); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan project database: %w", err)
+		}
+		result.Checked++
+
+		info, err := os.Stat(change.FilePath)
+		if err != nil {
+			result.Missing = append(result.Missing, change.FilePath)
+			continue
+		}
+		if change.NewSize = info.Size(); change.NewSize != change.OldSize {
+			result.Updated = append(result.Updated, change)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("failed to iterate project databases: %w", err)
+	}
+	rows.Close()
+
+	for _, change := range result.Updated {
+		if _, err := db.conn.Exec(`UPDATE project_databases SET file_size = ? WHERE id = ?`,
+			change.NewSize, change.DatabaseID); err != nil {
+			return nil, fmt.Errorf("failed to update size of database %d: %w", change.DatabaseID, err)
+		}
+	}
+
+	return result, nil
+}
+
+// RelinkMissingReferences привязывает эталоны без ссылки на справочник к кодам из
+// attributes.missing_reference_codes, если эти коды появились в справочнике (например,
+// после загрузки новой версии ОКПД2/ТН ВЭД). Восстановленный код удаляется из атрибутов.
+func (db *ServiceDB) RelinkMissingReferences() (*ReferenceRelinkResult, error) {
+	return db.relinkMissingReferences("", nil)
+}
+
+// RelinkReferenceBooksForRange выполняет то же, что RelinkMissingReferences, но только для
+// эталонов проекта с id в диапазоне [minID, maxID] - например, для строк, созданных последним
+// импортом номенклатур. Полная привязка (RelinkMissingReferences) остается для перестроения.
+func (db *ServiceDB) RelinkReferenceBooksForRange(projectID int, minID, maxID int) (*ReferenceRelinkResult, error) {
+	if minID > maxID {
+		return nil, fmt.Errorf("invalid benchmark id range %d-%d", minID, maxID)
+	}
+	return db.relinkMissingReferences("AND client_project_id = ? AND id BETWEEN ? AND ?",
+		[]interface{}{projectID, minID, maxID})
+}
+
+// relinkMissingReferences привязывает эталоны с missing_reference_codes, отобранные
+// дополнительным условием filter (пусто - все эталоны)
+func (db *ServiceDB) relinkMissingReferences(filter string, args []interface{}) (*ReferenceRelinkResult, error) {
+	rows, err := db.conn.Query(fmt.Sprintf(`
+		SELECT id, attributes, okpd2_reference_id, tnved_reference_id
+		FROM client_benchmarks
+		WHERE json_valid(attributes) AND json_extract(attributes, '$.missing_reference_codes') IS NOT NULL
+		%s
+		ORDER BY id
+	`, filter), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get benchmarks with missing references: %w", err)
+	}
+
+	type pendingBenchmark struct {
+		id         int
+		attributes map[string]interface{}
+		linked     map[string]bool
+	}
+	var pending []pendingBenchmark
+	for rows.Next() {
+		var id int
+		var attributesJSON string
+		var okpd2ID, tnvedID sql.NullInt64
+		if err := rows.Scan(&id, &attributesJSON, &okpd2ID, &tnvedID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan benchmark: %w", err)
+		}
+
+		var attributes map[string]interface{}
+		if err := json.Unmarshal([]byte(attributesJSON), &attributes); err != nil {
+			continue
+		}
+		pending = append(pending, pendingBenchmark{
+			id:         id,
+			attributes: attributes,
+			linked:     map[string]bool{"okpd2": okpd2ID.Valid, "tnved": tnvedID.Valid},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return nil, fmt.Errorf("failed to iterate benchmarks: %w", err)
+	}
+	rows.Close()
+
+	result := &ReferenceRelinkResult{
+		Checked:      len(pending),
+		Relinked:     make(map[string]int),
+		StillMissing: make(map[string]int),
+	}
+
+	for _, benchmark := range pending {
+		missing, _ := benchmark.attributes["missing_reference_codes"].(map[string]interface{})
+		changed := false
+
+		for book, value := range missing {
+			info, err := lookupReferenceBook(book)
+			code, _ := value.(string)
+			if err != nil || info.benchmarkColumn == "" || code == "" {
+				continue
+			}
+
+			var referenceID int
+			err = db.conn.QueryRow(fmt.Sprintf(`SELECT id FROM %s WHERE code = ?`, info.table),
+				normalizeReferenceCode(code)).Scan(&referenceID)
+			if err == sql.ErrNoRows {
+				result.StillMissing[book]++
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to find %s code %s: %w", book, code, err)
+			}
+
+			if !benchmark.linked[book] {
+				if _, err := db.conn.Exec(fmt.Sprintf(`UPDATE client_benchmarks SET %s = ? WHERE id = ?`, info.benchmarkColumn),
+					referenceID, benchmark.id); err != nil {
+					return nil, fmt.Errorf("failed to link benchmark %d to %s: %w", benchmark.id, book, err)
+				}
+				result.Relinked[book]++
+			}
+			delete(missing, book)
+			changed = true
+		}
+
+		if !changed {
+			continue
+		}
+		if len(missing) == 0 {
+			delete(benchmark.attributes, "missing_reference_codes")
+		}
+		attributesJSON, err := json.Marshal(benchmark.attributes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal attributes of benchmark %d: %w", benchmark.id, err)
+		}
+		if _, err := db.conn.Exec(`UPDATE client_benchmarks SET attributes = ? WHERE id = ?`,
+			string(attributesJSON), benchmark.id); err != nil {
+			return nil, fmt.Errorf("failed to update attributes of benchmark %d: %w", benchmark.id, err)
+		}
+	}
+
+	return result, nil
+}
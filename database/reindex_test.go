@@ -0,0 +1,295 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRebuildGostsFTS_RestoresDriftedIndex(t *testing.T) {
+	db := setupTestGostsDB(t)
+
+	for _, gost := range []*Gost{
+		{GostNumber: "ГОСТ 1-2000", Title: "Трубы стальные"},
+		{GostNumber: "ГОСТ 2-2000", Title: "Кабели силовые"},
+	} {
+		if _, err := db.CreateOrUpdateGost(gost); err != nil {
+			t.Fatalf("Failed to seed gost %s: %v", gost.GostNumber, err)
+		}
+	}
+
+	// Расхождение: один ГОСТ выпал из индекса, в индексе осталась запись удаленного ГОСТа
+	if _, err := db.conn.Exec(`DELETE FROM gosts_fts WHERE docid = (SELECT rowid FROM gosts WHERE gost_number = 'ГОСТ 1-2000')`); err != nil {
+		t.Fatalf("Failed to drop FTS row: %v", err)
+	}
+	if _, err := db.conn.Exec(`INSERT INTO gosts_fts(docid, gost_number, title) VALUES (9999, 'ГОСТ 9999-2000', 'Удаленный')`); err != nil {
+		t.Fatalf("Failed to insert stale FTS row: %v", err)
+	}
+
+	result, err := db.RebuildGostsFTS()
+	if err != nil {
+		t.Fatalf("RebuildGostsFTS failed: %v", err)
+	}
+	if result.Gosts != 2 || result.Missing != 1 || result.Stale != 1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	var matches int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM gosts_fts WHERE gosts_fts MATCH 'трубы'`).Scan(&matches); err != nil {
+		t.Fatalf("Failed to query FTS: %v", err)
+	}
+	if matches != 1 {
+		t.Errorf("Expected restored GOST to be searchable, got %d matches", matches)
+	}
+
+	again, err := db.RebuildGostsFTS()
+	if err != nil {
+		t.Fatalf("Second RebuildGostsFTS failed: %v", err)
+	}
+	if again.Missing != 0 || again.Stale != 0 {
+		t.Errorf("Expected no drift on second run, got %+v", again)
+	}
+}
+
+func TestRecomputeClientStatsReport_FixesDriftedCounts(t *testing.T) {
+	db := newTestServiceDB(t)
+
+	client, err := db.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	other, err := db.CreateClient("Другой клиент", "ООО «Другой»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	if _, err := db.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9); err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+
+	if _, err := db.conn.Exec(`UPDATE clients SET project_count = 42 WHERE id = ?`, client.ID); err != nil {
+		t.Fatalf("Failed to corrupt stats: %v", err)
+	}
+
+	result, err := db.RecomputeClientStatsReport()
+	if err != nil {
+		t.Fatalf("RecomputeClientStatsReport failed: %v", err)
+	}
+	if result.Clients != 2 || len(result.Changed) != 1 || result.Changed[0] != client.ID {
+		t.Errorf("Expected only client %d to change, got %+v", client.ID, result)
+	}
+	assertClientStatsFresh(t, db, client.ID, 1, 0)
+	assertClientStatsFresh(t, db, other.ID, 0, 0)
+
+	again, err := db.RecomputeClientStatsReport()
+	if err != nil {
+		t.Fatalf("Second RecomputeClientStatsReport failed: %v", err)
+	}
+	if len(again.Changed) != 0 {
+		t.Errorf("Expected no changes on second run, got %v", again.Changed)
+	}
+}
+
+func TestRefreshProjectDatabaseSizes_UpdatesChangedFiles(t *testing.T) {
+	db := newTestServiceDB(t)
+
+	client, err := db.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	existingPath := filepath.Join(dir, "existing.db")
+	if err := os.WriteFile(existingPath, []byte(strings.Repeat("x", 128)), 0644); err != nil {
+		t.Fatalf("Failed to write database file: %v", err)
+	}
+	existing, err := db.CreateProjectDatabase(project.ID, "Существующая", existingPath, "", 10)
+	if err != nil {
+		t.Fatalf("CreateProjectDatabase failed: %v", err)
+	}
+	missing, err := db.CreateProjectDatabase(project.ID, "Отсутствующая", filepath.Join(dir, "missing.db"), "", 20)
+	if err != nil {
+		t.Fatalf("CreateProjectDatabase failed: %v", err)
+	}
+
+	result, err := db.RefreshProjectDatabaseSizes()
+	if err != nil {
+		t.Fatalf("RefreshProjectDatabaseSizes failed: %v", err)
+	}
+	if result.Checked != 2 || len(result.Updated) != 1 || len(result.Missing) != 1 {
+		t.Fatalf("Unexpected result %+v", result)
+	}
+	if change := result.Updated[0]; change.DatabaseID != existing.ID || change.OldSize != 10 || change.NewSize != 128 {
+		t.Errorf("Unexpected change %+v", change)
+	}
+
+	refreshed, err := db.GetProjectDatabase(existing.ID)
+	if err != nil {
+		t.Fatalf("GetProjectDatabase failed: %v", err)
+	}
+	if refreshed.FileSize != 128 {
+		t.Errorf("Expected file_size 128, got %d", refreshed.FileSize)
+	}
+	untouched, err := db.GetProjectDatabase(missing.ID)
+	if err != nil {
+		t.Fatalf("GetProjectDatabase failed: %v", err)
+	}
+	if untouched.FileSize != 20 {
+		t.Errorf("Expected missing file size to stay 20, got %d", untouched.FileSize)
+	}
+
+	again, err := db.RefreshProjectDatabaseSizes()
+	if err != nil {
+		t.Fatalf("Second RefreshProjectDatabaseSizes failed: %v", err)
+	}
+	if len(again.Updated) != 0 {
+		t.Errorf("Expected no updates on second run, got %+v", again.Updated)
+	}
+}
+
+func TestRelinkMissingReferences_LinksNewCodes(t *testing.T) {
+	db := setupReferenceDiffDB(t)
+
+	client, err := db.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+
+	found, err := db.CreateClientBenchmark(project.ID, "Болт", "болт", "nomenclature", "",
+		`{"missing_reference_codes":{"okpd2":"25.94"},"color":"black"}`, "", 0.8)
+	if err != nil {
+		t.Fatalf("CreateClientBenchmark failed: %v", err)
+	}
+	stillMissing, err := db.CreateClientBenchmark(project.ID, "Гайка", "гайка", "nomenclature", "",
+		`{"missing_reference_codes":{"okpd2":"99.99.99"}}`, "", 0.8)
+	if err != nil {
+		t.Fatalf("CreateClientBenchmark failed: %v", err)
+	}
+
+	result, err := db.RelinkMissingReferences()
+	if err != nil {
+		t.Fatalf("RelinkMissingReferences failed: %v", err)
+	}
+	if result.Checked != 2 || result.Relinked["okpd2"] != 1 || result.StillMissing["okpd2"] != 1 {
+		t.Errorf("Unexpected result %+v", result)
+	}
+
+	var referenceID, expectedID int
+	var attributes string
+	if err := db.conn.QueryRow(`SELECT COALESCE(okpd2_reference_id, 0), attributes FROM client_benchmarks WHERE id = ?`,
+		found.ID).Scan(&referenceID, &attributes); err != nil {
+		t.Fatalf("Failed to read benchmark: %v", err)
+	}
+	if err := db.conn.QueryRow(`SELECT id FROM okpd2_classifier WHERE code = '25.94'`).Scan(&expectedID); err != nil {
+		t.Fatalf("Failed to read OKPD2 code: %v", err)
+	}
+	if referenceID != expectedID {
+		t.Errorf("Expected okpd2_reference_id %d, got %d", expectedID, referenceID)
+	}
+	if strings.Contains(attributes, "missing_reference_codes") || !strings.Contains(attributes, "color") {
+		t.Errorf("Expected only missing_reference_codes to be removed, got %s", attributes)
+	}
+
+	if err := db.conn.QueryRow(`SELECT attributes FROM client_benchmarks WHERE id = ?`, stillMissing.ID).Scan(&attributes); err != nil {
+		t.Fatalf("Failed to read benchmark: %v", err)
+	}
+	if !strings.Contains(attributes, "99.99.99") {
+		t.Errorf("Expected unresolved code to be kept, got %s", attributes)
+	}
+
+	again, err := db.RelinkMissingReferences()
+	if err != nil {
+		t.Fatalf("Second RelinkMissingReferences failed: %v", err)
+	}
+	if again.Checked != 1 || len(again.Relinked) != 0 {
+		t.Errorf("Expected no relinks on second run, got %+v", again)
+	}
+}
+
+func TestRelinkReferenceBooksForRange_OnlyNewRows(t *testing.T) {
+	db := setupReferenceDiffDB(t)
+
+	client, err := db.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	otherProject, err := db.CreateClientProject(client.ID, "Другой проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+
+	const attributes = `{"missing_reference_codes":{"okpd2":"25.94"}}`
+	createBenchmark := func(projectID int, name string) int {
+		t.Helper()
+		benchmark, err := db.CreateClientBenchmark(projectID, name, name, "nomenclature", "", attributes, "", 0.8)
+		if err != nil {
+			t.Fatalf("CreateClientBenchmark failed: %v", err)
+		}
+		return benchmark.ID
+	}
+
+	// Эталон предыдущего импорта и эталон другого проекта не должны затрагиваться
+	oldID := createBenchmark(project.ID, "Болт старый")
+	firstNewID := createBenchmark(project.ID, "Болт новый")
+	otherID := createBenchmark(otherProject.ID, "Болт чужой")
+	lastNewID := createBenchmark(project.ID, "Гайка новая")
+
+	result, err := db.RelinkReferenceBooksForRange(project.ID, firstNewID, lastNewID)
+	if err != nil {
+		t.Fatalf("RelinkReferenceBooksForRange failed: %v", err)
+	}
+	if result.Checked != 2 || result.Relinked["okpd2"] != 2 {
+		t.Errorf("Expected only 2 new rows to be relinked, got %+v", result)
+	}
+
+	for id, wantLinked := range map[int]bool{oldID: false, firstNewID: true, otherID: false, lastNewID: true} {
+		var linked bool
+		if err := db.conn.QueryRow(`SELECT okpd2_reference_id IS NOT NULL FROM client_benchmarks WHERE id = ?`,
+			id).Scan(&linked); err != nil {
+			t.Fatalf("Failed to read benchmark %d: %v", id, err)
+		}
+		if linked != wantLinked {
+			t.Errorf("Benchmark %d: expected linked = %v, got %v", id, wantLinked, linked)
+		}
+	}
+
+	if _, err := db.RelinkReferenceBooksForRange(project.ID, lastNewID, firstNewID); err == nil {
+		t.Error("Expected error for inverted id range")
+	}
+
+	full, err := db.RelinkMissingReferences()
+	if err != nil {
+		t.Fatalf("RelinkMissingReferences failed: %v", err)
+	}
+	if full.Checked != 2 || full.Relinked["okpd2"] != 2 {
+		t.Errorf("Expected full relink to handle the remaining rows, got %+v", full)
+	}
+}
+
+func TestParseReindexSteps(t *testing.T) {
+	steps, err := ParseReindexSteps("")
+	if err != nil || len(steps) != len(ReindexSteps) {
+		t.Errorf("Expected all steps for empty value, got %v (%v)", steps, err)
+	}
+
+	steps, err = ParseReindexSteps(" client_stats, REFERENCES ")
+	if err != nil || len(steps) != 2 || steps[0] != ReindexStepClientStats || steps[1] != ReindexStepReferences {
+		t.Errorf("Unexpected steps %v (%v)", steps, err)
+	}
+
+	if _, err := ParseReindexSteps("gosts_fts,unknown"); err == nil {
+		t.Error("Expected error for unknown step")
+	}
+}
@@ -194,6 +194,11 @@ func InitSchema(db *sql.DB) error {
 		return fmt.Errorf("failed to create data quality tables: %w", err)
 	}
 
+	// Признак сжатия details метрик качества
+	if err := MigrateDataQualityMetricsCompression(db); err != nil {
+		return fmt.Errorf("failed to migrate data quality metrics compression: %w", err)
+	}
+
 	// Создаем таблицы для срезов данных
 	// CreateSnapshotTables должна быть определена в другом месте или закомментирована
 	// if err := CreateSnapshotTables(db); err != nil {
@@ -505,6 +510,10 @@ func InitServiceSchema(db *sql.DB) error {
 		contract_date TIMESTAMP,
 		contract_terms TEXT,
 		contract_expires_at TIMESTAMP,
+		-- Кэшированная статистика (см. MigrateClientStats)
+		project_count INTEGER NOT NULL DEFAULT 0,
+		benchmark_count INTEGER NOT NULL DEFAULT 0,
+		last_activity TIMESTAMP,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
@@ -557,6 +566,19 @@ func InitServiceSchema(db *sql.DB) error {
 		FOREIGN KEY(client_project_id) REFERENCES client_projects(id) ON DELETE CASCADE
 	);
 
+	-- История изменений нормализованных названий эталонов
+	CREATE TABLE IF NOT EXISTS benchmark_name_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		benchmark_id INTEGER NOT NULL,
+		old_normalized_name TEXT,
+		new_normalized_name TEXT NOT NULL,
+		changed_by TEXT,
+		changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY(benchmark_id) REFERENCES client_benchmarks(id) ON DELETE CASCADE
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_benchmark_name_history_benchmark ON benchmark_name_history(benchmark_id);
+
 	-- Таблица сессий нормализации для клиентов
 	CREATE TABLE IF NOT EXISTS client_normalization_sessions (
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -603,7 +625,8 @@ func InitServiceSchema(db *sql.DB) error {
 
 	-- Таблица конфигурации нормализации
 	CREATE TABLE IF NOT EXISTS normalization_config (
-		id INTEGER PRIMARY KEY CHECK (id = 1), -- Только одна конфигурация
+		id INTEGER PRIMARY KEY AUTOINCREMENT, -- id = 1 глобальная конфигурация
+		client_project_id INTEGER UNIQUE REFERENCES client_projects(id) ON DELETE CASCADE, -- NULL для глобальной конфигурации
 		database_path TEXT NOT NULL,
 		source_table TEXT NOT NULL,
 		reference_column TEXT NOT NULL,
@@ -790,6 +813,11 @@ func InitServiceSchema(db *sql.DB) error {
 		return fmt.Errorf("failed to migrate benchmark manufacturer link: %w", err)
 	}
 
+	// Выполняем миграцию для добавления поля manufacturer_match_type
+	if err := MigrateBenchmarkManufacturerMatchType(db); err != nil {
+		return fmt.Errorf("failed to migrate benchmark manufacturer match type: %w", err)
+	}
+
 	// Выполняем миграцию для добавления поля country в таблицу clients
 	if err := MigrateClientsCountry(db); err != nil {
 		return fmt.Errorf("failed to migrate clients country field: %w", err)
@@ -800,6 +828,16 @@ func InitServiceSchema(db *sql.DB) error {
 		return fmt.Errorf("failed to migrate client enhancements: %w", err)
 	}
 
+	// Выполняем миграцию для кэшированной статистики клиентов и триггеров ее обновления
+	if err := MigrateClientStats(db); err != nil {
+		return fmt.Errorf("failed to migrate client stats: %w", err)
+	}
+
+	// Создаем таблицу контрольных точек обогащения эталонов проектов
+	if err := CreateEnrichmentCheckpointsTable(db); err != nil {
+		return err
+	}
+
 	// Создаем таблицу классификатора ОКПД2 (если её нет)
 	if err := CreateOkpd2ClassifierTable(db); err != nil {
 		return fmt.Errorf("failed to create okpd2_classifier table: %w", err)
@@ -845,6 +883,21 @@ func InitServiceSchema(db *sql.DB) error {
 		return fmt.Errorf("failed to migrate benchmark reference links: %w", err)
 	}
 
+	// Выполняем миграцию для добавления ключевых слов номенклатуры
+	if err := MigrateBenchmarkKeywords(db); err != nil {
+		return fmt.Errorf("failed to migrate benchmark keywords: %w", err)
+	}
+
+	// Выполняем миграцию статусов согласования эталонов
+	if err := MigrateBenchmarkReviewStatus(db); err != nil {
+		return fmt.Errorf("failed to migrate benchmark review status: %w", err)
+	}
+
+	// Создаем таблицу пользовательских тегов эталонов
+	if err := MigrateBenchmarkTags(db); err != nil {
+		return fmt.Errorf("failed to migrate benchmark tags: %w", err)
+	}
+
 	// Создаем таблицу normalized_counterparties если её нет
 	// ВАЖНО: Создаем таблицу ДО миграций, которые работают с ней
 	if err := CreateNormalizedCounterpartiesTable(db); err != nil {
@@ -868,6 +921,11 @@ func InitServiceSchema(db *sql.DB) error {
 		return fmt.Errorf("failed to migrate normalized counterparties subcategory: %w", err)
 	}
 
+	// Выполняем миграцию для добавления поля ogrn в normalized_counterparties
+	if err := MigrateNormalizedCounterpartiesOGRN(db); err != nil {
+		return fmt.Errorf("failed to migrate normalized counterparties ogrn: %w", err)
+	}
+
 	// Выполняем миграцию для заполнения таблицы counterparty_databases из существующих данных
 	if err := MigrateCounterpartyDatabases(db); err != nil {
 		return fmt.Errorf("failed to migrate counterparty databases: %w", err)
@@ -878,6 +936,12 @@ func InitServiceSchema(db *sql.DB) error {
 		return fmt.Errorf("failed to initialize websearch schema: %w", err)
 	}
 
+	// Конфигурация нормализации по проектам (глобальная строка id = 1 остается значением по умолчанию)
+	// ВАЖНО: Вызываем ПОСЛЕ InitWebSearchSchema, чтобы перенести websearch_rules
+	if err := MigrateNormalizationConfigProjects(db); err != nil {
+		return fmt.Errorf("failed to migrate normalization config projects: %w", err)
+	}
+
 	// Создаем таблицу providers для мульти-провайдерной системы
 	// Это критически важно для инициализации multi-provider client
 	if err := CreateProvidersTable(db); err != nil {
@@ -889,6 +953,27 @@ func InitServiceSchema(db *sql.DB) error {
 		return fmt.Errorf("failed to add data standardization providers: %w", err)
 	}
 
+	// Выполняем миграцию канонических путей баз данных проектов (запрет повторной регистрации файла)
+	// ВАЖНО: Вызываем ПОСЛЕ создания таблиц, ссылающихся на project_databases
+	if err := MigrateProjectDatabaseAbsPath(db); err != nil {
+		return fmt.Errorf("failed to migrate project database abs paths: %w", err)
+	}
+
+	// Хеш содержимого файлов баз данных проектов (поиск повторно загруженных выгрузок)
+	if err := MigrateProjectDatabaseContentHash(db); err != nil {
+		return fmt.Errorf("failed to migrate project database content hash: %w", err)
+	}
+
+	// Ключи идемпотентности для повторяемых запросов импорта и нормализации
+	if err := CreateIdempotencyKeysTable(db); err != nil {
+		return fmt.Errorf("failed to create idempotency keys table: %w", err)
+	}
+
+	// Постоянный кэш ответов AI при связывании номенклатуры с ОКПД2 (cmd/link_okpd2)
+	if err := CreateOkpd2LinkCacheTable(db); err != nil {
+		return fmt.Errorf("failed to create okpd2 link cache table: %w", err)
+	}
+
 	return nil
 }
 
@@ -970,8 +1055,8 @@ func CreateOkpd2ClassifierTable(db *sql.DB) error {
 	}
 
 	if exists {
-		// Таблица уже существует, пропускаем создание
-		return nil
+		// Таблица уже существует, добавляем недостающие колонки
+		return MigrateOkpd2ClassifierSource(db)
 	}
 
 	// Создаем таблицу
@@ -982,6 +1067,7 @@ func CreateOkpd2ClassifierTable(db *sql.DB) error {
 			name TEXT NOT NULL,
 			parent_code TEXT,
 			level INTEGER,
+			source TEXT,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 		)
 	`
@@ -1008,6 +1094,20 @@ func CreateOkpd2ClassifierTable(db *sql.DB) error {
 	return nil
 }
 
+// MigrateOkpd2ClassifierSource добавляет поле source в okpd2_classifier.
+// NULL - запись из загруженного классификатора, gisp_inline - заглушка, созданная при импорте ГИСП.
+func MigrateOkpd2ClassifierSource(db *sql.DB) error {
+	_, err := db.Exec(`ALTER TABLE okpd2_classifier ADD COLUMN source TEXT`)
+	if err != nil {
+		errStr := strings.ToLower(err.Error())
+		if !strings.Contains(errStr, "duplicate column") && !strings.Contains(errStr, "already exists") {
+			return fmt.Errorf("failed to add source column to okpd2_classifier: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // MigrateNormalizedDataKpvedFields добавляет КПВЭД поля в таблицу normalized_data
 func MigrateNormalizedDataKpvedFields(db *sql.DB) error {
 	migrations := []string{
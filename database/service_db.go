@@ -29,7 +29,8 @@ type DBConfig struct {
 // ServiceDB обертка для работы с сервисной базой данных
 type ServiceDB struct {
 	conn             *sql.DB
-	tableCreateMutex sync.Mutex // Мьютекс для создания таблиц (защита от race condition)
+	tableCreateMutex sync.Mutex         // Мьютекс для создания таблиц (защита от race condition)
+	statsPending     clientStatsPending // Клиенты, статистику которых нужно пересчитать (см. FlushClientStats)
 }
 
 func nullString(ns sql.NullString) string {
@@ -164,18 +165,26 @@ func NewServiceDBWithConfig(dbPath string, config DBConfig) (*ServiceDB, error)
 
 	serviceDB := &ServiceDB{conn: conn}
 
-	// Инициализируем схему сервисной БД
-	if err := InitServiceSchema(conn); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to initialize service schema: %w", err)
-	}
+	// Инициализация схемы и демо-данных выполняется под блокировкой, чтобы одновременно
+	// запущенные процессы (сервер, CLI-утилиты импорта) не создавали таблицы и записи дважды
+	err = withSetupLock(conn, SchemaSetupLockName, DefaultSetupLockTimeout, func() error {
+		// Инициализируем схему сервисной БД
+		if err := InitServiceSchema(conn); err != nil {
+			return fmt.Errorf("failed to initialize service schema: %w", err)
+		}
 
-	// Заполняем демо-данные, если сервисная БД ещё пуста (пропускаем in-memory подключения в тестах)
-	if !isInMemoryServiceDB(dbPath) {
-		if err := ensureDemoClients(conn); err != nil {
-			conn.Close()
-			return nil, fmt.Errorf("failed to seed demo clients: %w", err)
+		// Заполняем демо-данные, если сервисная БД ещё пуста (пропускаем in-memory подключения в тестах)
+		if !isInMemoryServiceDB(dbPath) {
+			if err := ensureDemoClients(conn); err != nil {
+				return fmt.Errorf("failed to seed demo clients: %w", err)
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		conn.Close()
+		return nil, err
 	}
 
 	return serviceDB, nil
@@ -303,31 +312,37 @@ type ClientBenchmark struct {
 	SourceDatabase  string     `json:"source_database"`
 	UsageCount      int        `json:"usage_count"`
 	// Поля для контрагентов
-	TaxID                   string    `json:"tax_id"`                              // ИНН
-	KPP                     string    `json:"kpp"`                                 // КПП
-	OGRN                    string    `json:"ogrn"`                                // ОГРН
-	Region                  string    `json:"region"`                              // Регион
-	LegalAddress            string    `json:"legal_address"`                       // Юридический адрес
-	PostalAddress           string    `json:"postal_address"`                      // Почтовый адрес
-	ContactPhone            string    `json:"contact_phone"`                       // Телефон
-	ContactEmail            string    `json:"contact_email"`                       // Email
-	ContactPerson           string    `json:"contact_person"`                      // Контактное лицо
-	LegalForm               string    `json:"legal_form"`                          // Организационно-правовая форма
-	BankName                string    `json:"bank_name"`                           // Банк
-	BankAccount             string    `json:"bank_account"`                        // Расчетный счет
-	CorrespondentAccount    string    `json:"correspondent_account"`               // Корреспондентский счет
-	BIK                     string    `json:"bik"`                                 // БИК
-	ManufacturerBenchmarkID *int      `json:"manufacturer_benchmark_id,omitempty"` // ID эталона производителя (для номенклатур)
-	OKPD2ReferenceID        *int      `json:"okpd2_reference_id,omitempty"`        // ID справочника ОКПД2
-	TNVEDReferenceID        *int      `json:"tnved_reference_id,omitempty"`        // ID справочника ТН ВЭД
-	TUGOSTReferenceID       *int      `json:"tu_gost_reference_id,omitempty"`      // ID справочника ТУ/ГОСТ
-	CreatedAt               time.Time `json:"created_at"`
-	UpdatedAt               time.Time `json:"updated_at"`
+	TaxID                   string     `json:"tax_id"`                              // ИНН
+	KPP                     string     `json:"kpp"`                                 // КПП
+	OGRN                    string     `json:"ogrn"`                                // ОГРН
+	Region                  string     `json:"region"`                              // Регион
+	LegalAddress            string     `json:"legal_address"`                       // Юридический адрес
+	PostalAddress           string     `json:"postal_address"`                      // Почтовый адрес
+	ContactPhone            string     `json:"contact_phone"`                       // Телефон
+	ContactEmail            string     `json:"contact_email"`                       // Email
+	ContactPerson           string     `json:"contact_person"`                      // Контактное лицо
+	LegalForm               string     `json:"legal_form"`                          // Организационно-правовая форма
+	BankName                string     `json:"bank_name"`                           // Банк
+	BankAccount             string     `json:"bank_account"`                        // Расчетный счет
+	CorrespondentAccount    string     `json:"correspondent_account"`               // Корреспондентский счет
+	BIK                     string     `json:"bik"`                                 // БИК
+	ManufacturerBenchmarkID *int       `json:"manufacturer_benchmark_id,omitempty"` // ID эталона производителя (для номенклатур)
+	OKPD2ReferenceID        *int       `json:"okpd2_reference_id,omitempty"`        // ID справочника ОКПД2
+	TNVEDReferenceID        *int       `json:"tnved_reference_id,omitempty"`        // ID справочника ТН ВЭД
+	TUGOSTReferenceID       *int       `json:"tu_gost_reference_id,omitempty"`      // ID справочника ТУ/ГОСТ
+	Keywords                string     `json:"keywords,omitempty"`                  // Ключевые слова номенклатуры через пробел
+	ReviewStatus            string     `json:"review_status"`                       // Статус согласования: draft/pending/approved/rejected
+	ReviewNotes             string     `json:"review_notes,omitempty"`              // Заметки проверяющего
+	ReviewedBy              string     `json:"reviewed_by,omitempty"`               // Кто последним менял статус согласования
+	ReviewedAt              *time.Time `json:"reviewed_at,omitempty"`               // Когда последний раз менялся статус согласования
+	CreatedAt               time.Time  `json:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at"`
 }
 
 // NormalizationConfig структура конфигурации нормализации
 type NormalizationConfig struct {
 	ID              int       `json:"id"`
+	ClientProjectID *int      `json:"client_project_id,omitempty"` // nil для глобальной конфигурации
 	DatabasePath    string    `json:"database_path"`
 	SourceTable     string    `json:"source_table"`
 	ReferenceColumn string    `json:"reference_column"`
@@ -666,6 +681,20 @@ func (db *ServiceDB) GetClientsByIDs(ids []int) ([]*Client, error) {
 	return clients, nil
 }
 
+// GetEntityCounts возвращает количество клиентов, проектов и эталонов
+func (db *ServiceDB) GetEntityCounts() (clients, projects, benchmarks int, err error) {
+	err = db.conn.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM clients),
+			(SELECT COUNT(*) FROM client_projects),
+			(SELECT COUNT(*) FROM client_benchmarks)
+	`).Scan(&clients, &projects, &benchmarks)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("failed to count entities: %w", err)
+	}
+	return clients, projects, benchmarks, nil
+}
+
 // GetAllClients получает всех клиентов
 func (db *ServiceDB) GetAllClients() ([]*Client, error) {
 	query := `
@@ -974,8 +1003,13 @@ func (db *ServiceDB) DeleteClient(id int) error {
 	return nil
 }
 
-// GetClientsWithStats получает список клиентов со статистикой
+// GetClientsWithStats получает список клиентов со статистикой.
+// Статистика читается из кэшированных полей clients (см. MigrateClientStats, FlushClientStats).
 func (db *ServiceDB) GetClientsWithStats() ([]map[string]interface{}, error) {
+	if err := db.FlushClientStats(); err != nil {
+		return nil, err
+	}
+
 	query := `
 		SELECT 
 			c.id,
@@ -985,20 +1019,10 @@ func (db *ServiceDB) GetClientsWithStats() ([]map[string]interface{}, error) {
 			c.country,
 			c.status,
 			c.created_at,
-			COUNT(DISTINCT cp.id) as project_count,
-			COUNT(DISTINCT cb.id) as benchmark_count,
-			MAX(
-				COALESCE(
-					cb.updated_at,
-					cp.updated_at,
-					c.updated_at,
-					c.created_at
-				)
-			) as last_activity
+			c.project_count,
+			c.benchmark_count,
+			COALESCE(c.last_activity, c.updated_at, c.created_at) as last_activity
 		FROM clients c
-		LEFT JOIN client_projects cp ON c.id = cp.client_id
-		LEFT JOIN client_benchmarks cb ON cp.id = cb.client_project_id
-		GROUP BY c.id, c.name, c.legal_name, c.description, c.country, c.status, c.created_at
 		ORDER BY c.created_at DESC
 	`
 
@@ -1069,6 +1093,7 @@ func (db *ServiceDB) CreateClientProject(clientID int, name, projectType, descri
 	if err != nil {
 		return nil, fmt.Errorf("failed to get project ID: %w", err)
 	}
+	db.markClientStatsDirty(clientID)
 
 	return db.GetClientProject(int(id))
 }
@@ -1097,8 +1122,40 @@ func (db *ServiceDB) GetClientProject(id int) (*ClientProject, error) {
 	return project, nil
 }
 
-// GetOrCreateSystemProject получает или создает системный проект для глобальных эталонов
+// GetOrCreateSystemProject получает или создает системный проект для глобальных эталонов.
+// Создание выполняется под блокировкой SystemProjectLockName, поэтому параллельные вызовы
+// (в том числе из разных процессов) создают системного клиента и проект ровно один раз.
 func (db *ServiceDB) GetOrCreateSystemProject() (*ClientProject, error) {
+	// Быстрый путь без блокировки: системный проект уже существует
+	var systemProjectID int
+	err := db.conn.QueryRow(`
+		SELECT p.id FROM client_projects p
+		JOIN clients c ON c.id = p.client_id
+		WHERE c.name = 'Система' AND p.name = 'Глобальные эталоны'
+		ORDER BY p.id
+		LIMIT 1
+	`).Scan(&systemProjectID)
+	if err == nil {
+		return db.GetClientProject(systemProjectID)
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get system project: %w", err)
+	}
+
+	var project *ClientProject
+	err = db.WithSetupLock(SystemProjectLockName, DefaultSetupLockTimeout, func() error {
+		var err error
+		project, err = db.getOrCreateSystemProject()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// getOrCreateSystemProject находит или создает системного клиента и проект; вызывается под блокировкой
+func (db *ServiceDB) getOrCreateSystemProject() (*ClientProject, error) {
 	// Сначала пытаемся найти системного клиента
 	var systemClientID int
 	err := db.conn.QueryRow(`SELECT id FROM clients WHERE name = 'Система' LIMIT 1`).Scan(&systemClientID)
@@ -1313,24 +1370,51 @@ func (db *ServiceDB) UpdateClientProject(id int, name, projectType, description,
 	if err != nil {
 		return fmt.Errorf("failed to update project: %w", err)
 	}
+	db.markProjectStatsDirty(id)
 
 	return nil
 }
 
 // DeleteClientProject удаляет проект
 func (db *ServiceDB) DeleteClientProject(id int) error {
+	// Клиента нужно узнать до удаления, чтобы пересчитать его статистику
+	var clientID int
+	err := db.conn.QueryRow(`SELECT client_id FROM client_projects WHERE id = ?`, id).Scan(&clientID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get project client: %w", err)
+	}
+
 	query := `DELETE FROM client_projects WHERE id = ?`
 
-	_, err := db.conn.Exec(query, id)
+	_, err = db.conn.Exec(query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete project: %w", err)
 	}
+	db.markClientStatsDirty(clientID)
 
 	return nil
 }
 
+// ErrInvalidName возвращается при создании эталона с пустым (или состоящим из пробелов) исходным наименованием
+var ErrInvalidName = errors.New("benchmark original name is empty")
+
+// ErrDuplicate возвращается при попытке создать запись, которая уже существует
+var ErrDuplicate = errors.New("duplicate record")
+
+// validateBenchmarkName проверяет, что исходное наименование эталона не пустое
+func validateBenchmarkName(originalName string) error {
+	if strings.TrimSpace(originalName) == "" {
+		return ErrInvalidName
+	}
+	return nil
+}
+
 // CreateClientBenchmark создает эталонную запись
 func (db *ServiceDB) CreateClientBenchmark(projectID int, originalName, normalizedName, category, subcategory, attributes, sourceDatabase string, qualityScore float64) (*ClientBenchmark, error) {
+	if err := validateBenchmarkName(originalName); err != nil {
+		return nil, err
+	}
+
 	query := `
 		INSERT INTO client_benchmarks 
 		(client_project_id, original_name, normalized_name, category, subcategory, attributes, quality_score, source_database)
@@ -1346,12 +1430,17 @@ func (db *ServiceDB) CreateClientBenchmark(projectID int, originalName, normaliz
 	if err != nil {
 		return nil, fmt.Errorf("failed to get benchmark ID: %w", err)
 	}
+	db.markProjectStatsDirty(projectID)
 
 	return db.GetClientBenchmark(int(id))
 }
 
 // CreateNomenclatureBenchmark создает эталонную запись номенклатуры с привязкой к производителю и справочникам
 func (db *ServiceDB) CreateNomenclatureBenchmark(projectID int, originalName, normalizedName, subcategory, attributes, sourceDatabase string, qualityScore float64, manufacturerBenchmarkID *int, okpd2RefID, tnvedRefID, tuGostRefID *int) (*ClientBenchmark, error) {
+	if err := validateBenchmarkName(originalName); err != nil {
+		return nil, err
+	}
+
 	query := `
 		INSERT INTO client_benchmarks 
 		(client_project_id, original_name, normalized_name, category, subcategory, attributes, quality_score, source_database, 
@@ -1369,6 +1458,7 @@ func (db *ServiceDB) CreateNomenclatureBenchmark(projectID int, originalName, no
 	if err != nil {
 		return nil, fmt.Errorf("failed to get benchmark ID: %w", err)
 	}
+	db.markProjectStatsDirty(projectID)
 
 	return db.GetClientBenchmark(int(id))
 }
@@ -1381,6 +1471,10 @@ func (db *ServiceDB) CreateCounterpartyBenchmark(
 	bankName, bankAccount, correspondentAccount, bik string,
 	qualityScore float64,
 ) (*ClientBenchmark, error) {
+	if err := validateBenchmarkName(originalName); err != nil {
+		return nil, err
+	}
+
 	// Используем tax_id для поиска, если есть БИН, сохраняем его отдельно
 	// В эталонах используем tax_id как основной идентификатор (может быть ИНН или БИН)
 	searchTaxID := taxID
@@ -1413,6 +1507,7 @@ func (db *ServiceDB) CreateCounterpartyBenchmark(
 	if err != nil {
 		return nil, fmt.Errorf("failed to get benchmark ID: %w", err)
 	}
+	db.markProjectStatsDirty(projectID)
 
 	return db.GetClientBenchmark(int(id))
 }
@@ -1420,12 +1515,18 @@ func (db *ServiceDB) CreateCounterpartyBenchmark(
 // GetClientBenchmark получает эталон по ID
 func (db *ServiceDB) GetClientBenchmark(id int) (*ClientBenchmark, error) {
 	query := `
-		SELECT id, client_project_id, original_name, normalized_name, category, subcategory,
+		SELECT id, client_project_id, original_name, normalized_name, category, COALESCE(subcategory, '') as subcategory,
 		       COALESCE(attributes, '') as attributes, quality_score, is_approved, approved_by, approved_at,
-		       source_database, usage_count, tax_id, kpp, COALESCE(ogrn, '') as ogrn, COALESCE(region, '') as region,
-		       legal_address, postal_address, contact_phone, contact_email, contact_person, legal_form,
-		       bank_name, bank_account, correspondent_account, bik, manufacturer_benchmark_id,
+		       COALESCE(source_database, '') as source_database, usage_count,
+		       COALESCE(tax_id, '') as tax_id, COALESCE(kpp, '') as kpp, COALESCE(ogrn, '') as ogrn, COALESCE(region, '') as region,
+		       COALESCE(legal_address, '') as legal_address, COALESCE(postal_address, '') as postal_address,
+		       COALESCE(contact_phone, '') as contact_phone, COALESCE(contact_email, '') as contact_email,
+		       COALESCE(contact_person, '') as contact_person, COALESCE(legal_form, '') as legal_form,
+		       COALESCE(bank_name, '') as bank_name, COALESCE(bank_account, '') as bank_account,
+		       COALESCE(correspondent_account, '') as correspondent_account, COALESCE(bik, '') as bik, manufacturer_benchmark_id,
 		       okpd2_reference_id, tnved_reference_id, tu_gost_reference_id,
+		       review_status, COALESCE(review_notes, '') as review_notes,
+		       COALESCE(reviewed_by, '') as reviewed_by, reviewed_at,
 		       created_at, updated_at
 		FROM client_benchmarks WHERE id = ?
 	`
@@ -1433,7 +1534,7 @@ func (db *ServiceDB) GetClientBenchmark(id int) (*ClientBenchmark, error) {
 	row := db.conn.QueryRow(query, id)
 	benchmark := &ClientBenchmark{}
 
-	var approvedAt sql.NullTime
+	var approvedAt, reviewedAt sql.NullTime
 	var approvedBy sql.NullString
 	var manufacturerID, okpd2RefID, tnvedRefID, tuGostRefID sql.NullInt64
 	err := row.Scan(
@@ -1446,6 +1547,7 @@ func (db *ServiceDB) GetClientBenchmark(id int) (*ClientBenchmark, error) {
 		&benchmark.ContactPhone, &benchmark.ContactEmail, &benchmark.ContactPerson, &benchmark.LegalForm,
 		&benchmark.BankName, &benchmark.BankAccount, &benchmark.CorrespondentAccount, &benchmark.BIK,
 		&manufacturerID, &okpd2RefID, &tnvedRefID, &tuGostRefID,
+		&benchmark.ReviewStatus, &benchmark.ReviewNotes, &benchmark.ReviewedBy, &reviewedAt,
 		&benchmark.CreatedAt, &benchmark.UpdatedAt,
 	)
 	if err != nil {
@@ -1458,6 +1560,9 @@ func (db *ServiceDB) GetClientBenchmark(id int) (*ClientBenchmark, error) {
 	if approvedAt.Valid {
 		benchmark.ApprovedAt = &approvedAt.Time
 	}
+	if reviewedAt.Valid {
+		benchmark.ReviewedAt = &reviewedAt.Time
+	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to get benchmark: %w", err)
@@ -1560,10 +1665,85 @@ func (db *ServiceDB) FindClientBenchmark(projectID int, name string) (*ClientBen
 	return benchmark, nil
 }
 
-// GetClientBenchmarks получает эталоны проекта
-func (db *ServiceDB) GetClientBenchmarks(projectID int, category string, approvedOnly bool) ([]*ClientBenchmark, error) {
-	query := `
-		SELECT id, client_project_id, original_name, normalized_name, category, 
+// GetClientBenchmarks получает эталоны проекта.
+// reviewStatuses (необязательно) ограничивает выборку статусами согласования (BenchmarkReviewDraft и т.д.).
+func (db *ServiceDB) GetClientBenchmarks(projectID int, category string, approvedOnly bool, reviewStatuses ...string) ([]*ClientBenchmark, error) {
+	return db.SearchBenchmarks(projectID, BenchmarkSearchFilter{
+		Category:       category,
+		ApprovedOnly:   approvedOnly,
+		ReviewStatuses: reviewStatuses,
+	})
+}
+
+// ClientBenchmarkCompact облегченное представление эталона для списков в UI
+type ClientBenchmarkCompact struct {
+	ID             int     `json:"id"`
+	OriginalName   string  `json:"original_name"`
+	NormalizedName string  `json:"normalized_name"`
+	Category       string  `json:"category"`
+	QualityScore   float64 `json:"quality_score"`
+	IsApproved     bool    `json:"is_approved"`
+}
+
+// GetClientBenchmarksCompact получает страницу эталонов проекта только с полями для списка
+// и общее количество эталонов, подходящих под фильтр. limit <= 0 - без ограничения.
+// В отличие от GetClientBenchmarks не читает адреса, банковские реквизиты и прочие длинные поля.
+func (db *ServiceDB) GetClientBenchmarksCompact(projectID int, category string, approvedOnly bool, limit, offset int) ([]*ClientBenchmarkCompact, int, error) {
+	where := " WHERE client_project_id = ?"
+	args := []interface{}{projectID}
+
+	if category != "" {
+		where += " AND category = ?"
+		args = append(args, category)
+	}
+
+	if approvedOnly {
+		where += " AND is_approved = TRUE"
+	}
+
+	var total int
+	if err := db.conn.QueryRow("SELECT COUNT(*) FROM client_benchmarks"+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count benchmarks: %w", err)
+	}
+
+	query := `SELECT id, original_name, normalized_name, category, quality_score, is_approved
+		FROM client_benchmarks` + where + " ORDER BY created_at DESC"
+	if limit > 0 {
+		if offset < 0 {
+			offset = 0
+		}
+		query += " LIMIT ? OFFSET ?"
+		args = append(args, limit, offset)
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get compact benchmarks: %w", err)
+	}
+	defer rows.Close()
+
+	benchmarks := make([]*ClientBenchmarkCompact, 0)
+	for rows.Next() {
+		benchmark := &ClientBenchmarkCompact{}
+		if err := rows.Scan(
+			&benchmark.ID, &benchmark.OriginalName, &benchmark.NormalizedName,
+			&benchmark.Category, &benchmark.QualityScore, &benchmark.IsApproved,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan compact benchmark: %w", err)
+		}
+		benchmarks = append(benchmarks, benchmark)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating compact benchmarks: %w", err)
+	}
+
+	return benchmarks, total, nil
+}
+
+// clientBenchmarkListColumns колонки client_benchmarks в порядке, ожидаемом scanClientBenchmarks
+const clientBenchmarkListColumns = `
+		       id, client_project_id, original_name, normalized_name, category, 
 		       COALESCE(subcategory, '') as subcategory,
 		       COALESCE(attributes, '') as attributes, quality_score, is_approved, 
 		       COALESCE(approved_by, '') as approved_by, approved_at,
@@ -1581,34 +1761,17 @@ func (db *ServiceDB) GetClientBenchmarks(projectID int, category string, approve
 		       COALESCE(correspondent_account, '') as correspondent_account, 
 		       COALESCE(bik, '') as bik, manufacturer_benchmark_id,
 		       okpd2_reference_id, tnved_reference_id, tu_gost_reference_id,
-		       created_at, updated_at
-		FROM client_benchmarks 
-		WHERE client_project_id = ?
-	`
-
-	args := []interface{}{projectID}
-
-	if category != "" {
-		query += " AND category = ?"
-		args = append(args, category)
-	}
-
-	if approvedOnly {
-		query += " AND is_approved = TRUE"
-	}
-
-	query += " ORDER BY created_at DESC"
-
-	rows, err := db.conn.Query(query, args...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get benchmarks: %w", err)
-	}
-	defer rows.Close()
+		       COALESCE(keywords, '') as keywords,
+		       review_status, COALESCE(review_notes, '') as review_notes,
+		       COALESCE(reviewed_by, '') as reviewed_by, reviewed_at,
+		       created_at, updated_at`
 
+// scanClientBenchmarks сканирует строки, выбранные с колонками clientBenchmarkListColumns
+func scanClientBenchmarks(rows *sql.Rows) ([]*ClientBenchmark, error) {
 	var benchmarks []*ClientBenchmark
 	for rows.Next() {
 		benchmark := &ClientBenchmark{}
-		var approvedAt sql.NullTime
+		var approvedAt, reviewedAt sql.NullTime
 		var manufacturerID, okpd2RefID, tnvedRefID, tuGostRefID sql.NullInt64
 
 		err := rows.Scan(
@@ -1621,6 +1784,8 @@ func (db *ServiceDB) GetClientBenchmarks(projectID int, category string, approve
 			&benchmark.ContactPhone, &benchmark.ContactEmail, &benchmark.ContactPerson, &benchmark.LegalForm,
 			&benchmark.BankName, &benchmark.BankAccount, &benchmark.CorrespondentAccount, &benchmark.BIK,
 			&manufacturerID, &okpd2RefID, &tnvedRefID, &tuGostRefID,
+			&benchmark.Keywords,
+			&benchmark.ReviewStatus, &benchmark.ReviewNotes, &benchmark.ReviewedBy, &reviewedAt,
 			&benchmark.CreatedAt, &benchmark.UpdatedAt,
 		)
 		if err != nil {
@@ -1630,6 +1795,9 @@ func (db *ServiceDB) GetClientBenchmarks(projectID int, category string, approve
 		if approvedAt.Valid {
 			benchmark.ApprovedAt = &approvedAt.Time
 		}
+		if reviewedAt.Valid {
+			benchmark.ReviewedAt = &reviewedAt.Time
+		}
 
 		if manufacturerID.Valid {
 			id := int(manufacturerID.Int64)
@@ -1654,7 +1822,7 @@ func (db *ServiceDB) GetClientBenchmarks(projectID int, category string, approve
 		benchmarks = append(benchmarks, benchmark)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating benchmarks: %w", err)
 	}
 
@@ -1677,7 +1845,8 @@ func (db *ServiceDB) UpdateBenchmarkUsage(benchmarkID int) error {
 func (db *ServiceDB) ApproveBenchmark(benchmarkID int, approvedBy string) error {
 	query := `
 		UPDATE client_benchmarks
-		SET is_approved = TRUE, approved_by = ?, approved_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		SET is_approved = TRUE, review_status = 'approved', approved_by = ?, approved_at = CURRENT_TIMESTAMP,
+		    updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
 
@@ -1685,12 +1854,27 @@ func (db *ServiceDB) ApproveBenchmark(benchmarkID int, approvedBy string) error
 	if err != nil {
 		return fmt.Errorf("failed to approve benchmark: %w", err)
 	}
+	db.markBenchmarkStatsDirty(benchmarkID)
 
 	return nil
 }
 
-// UpdateBenchmark обновляет эталон контрагента
+// UpdateBenchmark обновляет эталон контрагента.
+// Если нормализованное название меняется, изменение записывается в benchmark_name_history.
 func (db *ServiceDB) UpdateBenchmark(benchmarkID int, originalName, normalizedName, ogrn, region, attributes string, qualityScore float64) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var oldNormalizedName string
+	err = tx.QueryRow(`SELECT normalized_name FROM client_benchmarks WHERE id = ?`, benchmarkID).Scan(&oldNormalizedName)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get current benchmark name: %w", err)
+	}
+	benchmarkExists := err == nil
+
 	query := `
 		UPDATE client_benchmarks
 		SET original_name = ?,
@@ -1700,17 +1884,29 @@ func (db *ServiceDB) UpdateBenchmark(benchmarkID int, originalName, normalizedNa
 		    attributes = COALESCE(NULLIF(?, ''), attributes),
 		    quality_score = ?,
 		    is_approved = TRUE,
+		    review_status = 'approved',
 		    approved_by = 'system',
 		    approved_at = CURRENT_TIMESTAMP,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
 
-	_, err := db.conn.Exec(query, originalName, normalizedName, ogrn, region, attributes, qualityScore, benchmarkID)
+	_, err = tx.Exec(query, originalName, normalizedName, ogrn, region, attributes, qualityScore, benchmarkID)
 	if err != nil {
 		return fmt.Errorf("failed to update benchmark: %w", err)
 	}
 
+	if benchmarkExists && oldNormalizedName != normalizedName {
+		if err := recordBenchmarkNameChange(tx, benchmarkID, oldNormalizedName, normalizedName, "system"); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit benchmark update: %w", err)
+	}
+	db.markBenchmarkStatsDirty(benchmarkID)
+
 	return nil
 }
 
@@ -1788,12 +1984,49 @@ func (db *ServiceDB) FindManufacturerByINN(projectID int, inn string) (*ClientBe
 	return benchmark, nil
 }
 
-// FindManufacturerByOGRN ищет производителя по ОГРН в проекте
+// FindManufacturerByOGRN ищет производителя по ОГРН в проекте.
+// Если в проекте производитель не найден, ищет в глобальном (системном) проекте.
 func (db *ServiceDB) FindManufacturerByOGRN(projectID int, ogrn string) (*ClientBenchmark, error) {
 	if ogrn == "" {
 		return nil, nil
 	}
 
+	manufacturer, err := db.findManufacturerByOGRNInProject(projectID, ogrn)
+	if err != nil || manufacturer != nil {
+		return manufacturer, err
+	}
+
+	systemProject, err := db.GetOrCreateSystemProject()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system project: %w", err)
+	}
+	if systemProject.ID == projectID {
+		return nil, nil
+	}
+
+	return db.findManufacturerByOGRNInProject(systemProject.ID, ogrn)
+}
+
+// FindManufacturerByOGRNWithFallback ищет производителя по ОГРН в проекте, а если не нашел -
+// в проекте fallbackProjectID (обычно системном). Для массового импорта: системный проект
+// получается один раз, а не при каждом поиске, как в FindManufacturerByOGRN.
+// fallbackProjectID <= 0 отключает поиск во втором проекте.
+func (db *ServiceDB) FindManufacturerByOGRNWithFallback(projectID, fallbackProjectID int, ogrn string) (*ClientBenchmark, error) {
+	if ogrn == "" {
+		return nil, nil
+	}
+
+	manufacturer, err := db.findManufacturerByOGRNInProject(projectID, ogrn)
+	if err != nil || manufacturer != nil || fallbackProjectID <= 0 || fallbackProjectID == projectID {
+		return manufacturer, err
+	}
+
+	return db.findManufacturerByOGRNInProject(fallbackProjectID, ogrn)
+}
+
+// findManufacturerByOGRNInProject ищет производителя по ОГРН только в указанном проекте
+func (db *ServiceDB) findManufacturerByOGRNInProject(projectID int, ogrn string) (*ClientBenchmark, error) {
+
 	query := `
 		SELECT id, client_project_id, original_name, normalized_name, category, subcategory,
 		       attributes, quality_score, is_approved, approved_by, approved_at,
@@ -1862,6 +2095,27 @@ func (db *ServiceDB) FindManufacturerByOGRN(projectID int, ogrn string) (*Client
 	return benchmark, nil
 }
 
+// Ключи, по которым номенклатура связана с производителем (manufacturer_match_type)
+const (
+	ManufacturerMatchINN  = "inn"
+	ManufacturerMatchOGRN = "ogrn"
+	ManufacturerMatchNone = "none"
+)
+
+// SetBenchmarkManufacturerMatchType сохраняет ключ, по которому номенклатура связана с производителем
+func (db *ServiceDB) SetBenchmarkManufacturerMatchType(benchmarkID int, matchType string) error {
+	_, err := db.conn.Exec(`
+		UPDATE client_benchmarks
+		SET manufacturer_match_type = ?
+		WHERE id = ?
+	`, matchType, benchmarkID)
+	if err != nil {
+		return fmt.Errorf("failed to set manufacturer match type: %w", err)
+	}
+
+	return nil
+}
+
 // UpdateBenchmarkFields обновляет дополнительные поля эталона (subcategory, source_database)
 func (db *ServiceDB) UpdateBenchmarkFields(benchmarkID int, subcategory, sourceDatabase string) error {
 	query := `
@@ -1876,6 +2130,7 @@ func (db *ServiceDB) UpdateBenchmarkFields(benchmarkID int, subcategory, sourceD
 	if err != nil {
 		return fmt.Errorf("failed to update benchmark fields: %w", err)
 	}
+	db.markBenchmarkStatsDirty(benchmarkID)
 
 	return nil
 }
@@ -1933,19 +2188,35 @@ func (db *ServiceDB) UpdateNormalizationConfig(databasePath, sourceTable, refere
 }
 
 // CreateProjectDatabase создает новую базу данных для проекта
-// Нормализует путь к файлу для консистентности (использует filepath.Clean)
+// Нормализует путь к файлу для консистентности (использует filepath.Clean).
+// Если тот же физический файл уже зарегистрирован у клиента проекта (в том числе под другим
+// написанием пути), возвращает ErrDuplicate. Для существующего файла сохраняется SHA-256 содержимого (см. GetProjectDatabaseByHash).
 func (db *ServiceDB) CreateProjectDatabase(projectID int, name, filePath, description string, fileSize int64) (*ProjectDatabase, error) {
 	// Нормализуем путь к файлу для консистентности
 	normalizedPath := filepath.Clean(filePath)
+	absPath := CanonicalDatabasePath(filePath)
+
+	existingID, err := db.findProjectDatabaseByAbsPath(absPath, projectID, 0)
+	if err != nil {
+		return nil, err
+	}
+	if existingID != 0 {
+		return nil, fmt.Errorf("%w: database file %s is already registered (project database %d)", ErrDuplicate, filePath, existingID)
+	}
 
 	query := `
 		INSERT INTO project_databases
-		(client_project_id, name, file_path, description, file_size, is_active)
-		VALUES (?, ?, ?, ?, ?, TRUE)
+		(client_project_id, name, file_path, abs_path, content_hash, description, file_size, is_active)
+		VALUES (?, ?, ?, ?, ?, ?, ?, TRUE)
 	`
 
-	result, err := db.conn.Exec(query, projectID, name, normalizedPath, description, fileSize)
+	result, err := db.conn.Exec(query, projectID, name, normalizedPath, nullableAbsPath(absPath), projectDatabaseContentHash(filePath),
+		description, fileSize)
 	if err != nil {
+		// Параллельная регистрация того же файла отсекается триггером trg_project_databases_abs_path_insert
+		if isUniqueConstraintError(err) {
+			return nil, fmt.Errorf("%w: database file %s is already registered", ErrDuplicate, filePath)
+		}
 		return nil, fmt.Errorf("failed to create project database: %w", err)
 	}
 
@@ -2019,6 +2290,12 @@ func (db *ServiceDB) GetProjectDatabases(projectID int, activeOnly bool) ([]*Pro
 	}
 	defer rows.Close()
 
+	return scanProjectDatabases(rows)
+}
+
+// scanProjectDatabases читает строки project_databases (id, client_project_id, name, file_path, description,
+// is_active, file_size, last_used_at, created_at, updated_at); NULL в file_size читается как 0
+func scanProjectDatabases(rows *sql.Rows) ([]*ProjectDatabase, error) {
 	var databases []*ProjectDatabase
 	for rows.Next() {
 		projectDB := &ProjectDatabase{}
@@ -2047,7 +2324,7 @@ func (db *ServiceDB) GetProjectDatabases(projectID int, activeOnly bool) ([]*Pro
 		databases = append(databases, projectDB)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error iterating project databases: %w", err)
 	}
 
@@ -2075,40 +2352,26 @@ func (db *ServiceDB) GetProjectDatabaseCount(projectID int, activeOnly bool) (in
 	return count, nil
 }
 
-// GetAllProjectDatabases получает все базы данных из всех проектов всех клиентов
+// GetAllProjectDatabases получает все базы данных из всех проектов всех клиентов одним запросом.
+// Порядок тот же, что при обходе клиентов и проектов: клиенты и проекты от новых к старым,
+// внутри проекта базы данных от новых к старым.
 func (db *ServiceDB) GetAllProjectDatabases() ([]*ProjectDatabase, error) {
-	// Получаем всех клиентов
-	clients, err := db.GetAllClients()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get clients: %w", err)
-	}
-
-	var allDatabases []*ProjectDatabase
-
-	// Для каждого клиента получаем проекты и базы данных
-	for _, client := range clients {
-		// Получаем проекты клиента
-		projects, err := db.GetClientProjects(client.ID)
-		if err != nil {
-			// Логируем ошибку, но продолжаем обработку других клиентов
-			log.Printf("Failed to get projects for client %d: %v", client.ID, err)
-			continue
-		}
-
-		// Для каждого проекта получаем базы данных
-		for _, project := range projects {
-			databases, err := db.GetProjectDatabases(project.ID, false)
-			if err != nil {
-				// Логируем ошибку, но продолжаем обработку других проектов
-				log.Printf("Failed to get databases for project %d: %v", project.ID, err)
-				continue
-			}
+	query := `
+		SELECT pd.id, pd.client_project_id, pd.name, pd.file_path, pd.description, pd.is_active,
+		       pd.file_size, pd.last_used_at, pd.created_at, pd.updated_at
+		FROM project_databases pd
+		JOIN client_projects cp ON cp.id = pd.client_project_id
+		JOIN clients c ON c.id = cp.client_id
+		ORDER BY c.created_at DESC, c.id, cp.created_at DESC, cp.id, pd.created_at DESC
+	`
 
-			allDatabases = append(allDatabases, databases...)
-		}
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all project databases: %w", err)
 	}
+	defer rows.Close()
 
-	return allDatabases, nil
+	return scanProjectDatabases(rows)
 }
 
 // GetProjectDatabaseByFilePath проверяет, существует ли база данных с таким же путем к файлу
@@ -2125,11 +2388,12 @@ func (db *ServiceDB) GetProjectDatabaseByFilePath(projectID int, filePath string
 		       file_size, last_used_at, created_at, updated_at
 		FROM project_databases
 		WHERE client_project_id = ? 
-		  AND (file_path = ? OR file_path = ? OR file_path = ? OR file_path = ?)
+		  AND (file_path = ? OR file_path = ? OR file_path = ? OR file_path = ? OR abs_path = ?)
 		LIMIT 1
 	`
 
-	row := db.conn.QueryRow(query, projectID, filePath, normalizedPath, normalizedPathSlash, normalizedPathBackslash)
+	row := db.conn.QueryRow(query, projectID, filePath, normalizedPath, normalizedPathSlash, normalizedPathBackslash,
+		nullableAbsPath(CanonicalDatabasePath(filePath)))
 	projectDB := &ProjectDatabase{}
 
 	var lastUsedAt sql.NullTime
@@ -2214,15 +2478,35 @@ func (db *ServiceDB) FindClientAndProjectByDatabasePath(filePath string) (client
 }
 
 // UpdateProjectDatabase обновляет базу данных проекта
+// Возвращает ErrDuplicate, если новый путь указывает на файл, зарегистрированный другой записью того же клиента
 func (db *ServiceDB) UpdateProjectDatabase(id int, name, filePath, description string, isActive bool) error {
+	absPath := CanonicalDatabasePath(filePath)
+
+	var projectID int
+	err := db.conn.QueryRow(`SELECT client_project_id FROM project_databases WHERE id = ?`, id).Scan(&projectID)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get project database: %w", err)
+	}
+
+	existingID, err := db.findProjectDatabaseByAbsPath(absPath, projectID, id)
+	if err != nil {
+		return err
+	}
+	if existingID != 0 {
+		return fmt.Errorf("%w: database file %s is already registered (project database %d)", ErrDuplicate, filePath, existingID)
+	}
+
 	query := `
 		UPDATE project_databases
-		SET name = ?, file_path = ?, description = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
+		SET name = ?, file_path = ?, abs_path = ?, description = ?, is_active = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`
 
-	_, err := db.conn.Exec(query, name, filePath, description, isActive, id)
+	_, err = db.conn.Exec(query, name, filePath, nullableAbsPath(absPath), description, isActive, id)
 	if err != nil {
+		if isUniqueConstraintError(err) {
+			return fmt.Errorf("%w: database file %s is already registered", ErrDuplicate, filePath)
+		}
 		return fmt.Errorf("failed to update project database: %w", err)
 	}
 
@@ -2293,11 +2577,15 @@ func (db *ServiceDB) LinkProjectDatabase(databaseID, projectID int) error {
 }
 
 // GetQualityMetricsForProject получает метрики качества для проекта
+//
+// Deprecated: таблица data_quality_metrics создается только в основной БД (InitSchema), в сервисной
+// ее нет, поэтому запрос к ServiceDB возвращает ошибку. Используйте ServiceDB.GetProjectDatabases
+// и DB.GetQualityMetricsForDatabases с QualityMetricsPeriodStart.
 func (db *ServiceDB) GetQualityMetricsForProject(projectID int, period string) ([]DataQualityMetric, error) {
 	query := `
 		SELECT 
 			id, upload_id, database_id, metric_category, metric_name, 
-			metric_value, threshold_value, status, measured_at, details
+			metric_value, threshold_value, status, measured_at, details, ` + metricDetailsCompressedColumn(db.conn) + `
 		FROM data_quality_metrics
 		WHERE database_id IN (
 			SELECT id FROM project_databases 
@@ -2328,23 +2616,22 @@ func (db *ServiceDB) GetQualityMetricsForProject(projectID int, period string) (
 	var metrics []DataQualityMetric
 	for rows.Next() {
 		var metric DataQualityMetric
-		var details string
+		var details []byte
+		var compressed bool
 
 		err := rows.Scan(
 			&metric.ID, &metric.UploadID, &metric.DatabaseID,
 			&metric.MetricCategory, &metric.MetricName,
 			&metric.MetricValue, &metric.ThresholdValue,
-			&metric.Status, &metric.MeasuredAt, &details,
+			&metric.Status, &metric.MeasuredAt, &details, &compressed,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan metric: %w", err)
 		}
 
-		// Десериализация details из JSON
-		if details != "" {
-			if err := json.Unmarshal([]byte(details), &metric.Details); err != nil {
-				log.Printf("Error unmarshaling metric details: %v", err)
-			}
+		// Десериализация details из JSON (сжатые распаковываются)
+		if metric.Details, err = decodeMetricDetails(details, compressed); err != nil {
+			log.Printf("Error unmarshaling metric details: %v", err)
 		}
 
 		metrics = append(metrics, metric)
@@ -2416,7 +2703,7 @@ func (db *ServiceDB) CompareProjectsQuality(projectIDs []int) (map[int][]DataQua
 	query := `
 		SELECT 
 			id, upload_id, database_id, metric_category, metric_name, 
-			metric_value, threshold_value, status, measured_at, details
+			metric_value, threshold_value, status, measured_at, details, ` + metricDetailsCompressedColumn(db.conn) + `
 		FROM data_quality_metrics
 		WHERE database_id IN (
 			SELECT id FROM project_databases 
@@ -2448,24 +2735,23 @@ func (db *ServiceDB) CompareProjectsQuality(projectIDs []int) (map[int][]DataQua
 	results := make(map[int][]DataQualityMetric)
 	for rows.Next() {
 		var metric DataQualityMetric
-		var details string
+		var details []byte
+		var compressed bool
 		var dbID int
 
 		err := rows.Scan(
 			&metric.ID, &metric.UploadID, &dbID,
 			&metric.MetricCategory, &metric.MetricName,
 			&metric.MetricValue, &metric.ThresholdValue,
-			&metric.Status, &metric.MeasuredAt, &details,
+			&metric.Status, &metric.MeasuredAt, &details, &compressed,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan metric: %w", err)
 		}
 
-		// Десериализация details из JSON
-		if details != "" {
-			if err := json.Unmarshal([]byte(details), &metric.Details); err != nil {
-				log.Printf("Error unmarshaling metric details: %v", err)
-			}
+		// Десериализация details из JSON (сжатые распаковываются)
+		if metric.Details, err = decodeMetricDetails(details, compressed); err != nil {
+			log.Printf("Error unmarshaling metric details: %v", err)
 		}
 
 		// Получаем projectID для текущей базы данных
@@ -3452,6 +3738,20 @@ func (db *ServiceDB) UpdateNormalizedCounterparty(
 	return nil
 }
 
+// SetNormalizedCounterpartyOGRN записывает ОГРН контрагента, если он еще не заполнен.
+// Уже сохраненный ОГРН не перезаписывается, чтобы при объединении дубликатов эталон сохранял свой.
+func (db *ServiceDB) SetNormalizedCounterpartyOGRN(id int, ogrn string) error {
+	_, err := db.conn.Exec(`
+		UPDATE normalized_counterparties
+		SET ogrn = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND COALESCE(ogrn, '') = ''
+	`, ogrn, id)
+	if err != nil {
+		return fmt.Errorf("failed to set normalized counterparty ogrn: %w", err)
+	}
+	return nil
+}
+
 // GetNormalizedCounterparty получает контрагента по ID
 func (db *ServiceDB) GetNormalizedCounterparty(id int) (*NormalizedCounterparty, error) {
 	query := `
@@ -5508,6 +5808,47 @@ func (db *ServiceDB) UpdateNormalizationSession(sessionID int, status string, fi
 	return nil
 }
 
+// SaveSessionPhaseTimings сохраняет время по фазам нормализации для сессии
+func (db *ServiceDB) SaveSessionPhaseTimings(sessionID int, timings map[string]time.Duration) error {
+	data, err := json.Marshal(timings)
+	if err != nil {
+		return fmt.Errorf("failed to marshal phase timings: %w", err)
+	}
+
+	_, err = db.conn.Exec(`UPDATE normalization_sessions SET phase_timings = ? WHERE id = ?`, string(data), sessionID)
+	if err != nil {
+		return fmt.Errorf("failed to save session phase timings: %w", err)
+	}
+	return nil
+}
+
+// GetLatestSessionPhaseTimings возвращает время по фазам последней сессии нормализации проекта,
+// для которой оно сохранено. Если таких сессий нет, возвращает sessionID = 0 и nil.
+func (db *ServiceDB) GetLatestSessionPhaseTimings(projectID int) (int, map[string]time.Duration, error) {
+	var sessionID int
+	var data string
+	err := db.conn.QueryRow(`
+		SELECT ns.id, ns.phase_timings
+		FROM normalization_sessions ns
+		JOIN project_databases pd ON pd.id = ns.project_database_id
+		WHERE pd.client_project_id = ? AND ns.phase_timings IS NOT NULL AND ns.phase_timings != ''
+		ORDER BY COALESCE(ns.finished_at, ns.last_activity_at) DESC, ns.id DESC
+		LIMIT 1
+	`, projectID).Scan(&sessionID, &data)
+	if err == sql.ErrNoRows {
+		return 0, nil, nil
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get session phase timings: %w", err)
+	}
+
+	timings := make(map[string]time.Duration)
+	if err := json.Unmarshal([]byte(data), &timings); err != nil {
+		return 0, nil, fmt.Errorf("failed to unmarshal phase timings: %w", err)
+	}
+	return sessionID, timings, nil
+}
+
 // UpdateSessionActivity обновляет время последней активности сессии
 func (db *ServiceDB) UpdateSessionActivity(sessionID int) error {
 	query := `
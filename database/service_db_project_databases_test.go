@@ -0,0 +1,155 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// queryCountingDriver драйвер SQLite, считающий выполненные запросы
+type queryCountingDriver struct {
+	driver.Driver
+	queries atomic.Int64
+}
+
+func (d *queryCountingDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.Driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &queryCountingConn{Conn: conn, queries: &d.queries}, nil
+}
+
+type queryCountingConn struct {
+	driver.Conn
+	queries *atomic.Int64
+}
+
+func (c *queryCountingConn) Prepare(query string) (driver.Stmt, error) {
+	c.queries.Add(1)
+	return c.Conn.Prepare(query)
+}
+
+func (c *queryCountingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.queries.Add(1)
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, query, args)
+}
+
+var (
+	countingDriver     = &queryCountingDriver{Driver: &sqlite3.SQLiteDriver{}}
+	registerCountingDB sync.Once
+)
+
+// openQueryCountingServiceDB открывает ServiceDB для файла path через считающий драйвер
+func openQueryCountingServiceDB(t *testing.T, path string) *ServiceDB {
+	t.Helper()
+	registerCountingDB.Do(func() { sql.Register("sqlite3_query_counting", countingDriver) })
+
+	conn, err := sql.Open("sqlite3_query_counting", path)
+	if err != nil {
+		t.Fatalf("Failed to open counting connection: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &ServiceDB{conn: conn}
+}
+
+func TestGetAllProjectDatabases_SingleQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.db")
+	db, err := NewServiceDB(path)
+	if err != nil {
+		t.Fatalf("Failed to create service DB: %v", err)
+	}
+	defer db.Close()
+
+	want := 0
+	for c := 0; c < 3; c++ {
+		client, err := db.CreateClient(fmt.Sprintf("Client %d", c), "", "", "", "", "", "tests")
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		for p := 0; p < 2; p++ {
+			project, err := db.CreateClientProject(client.ID, fmt.Sprintf("Project %d-%d", c, p), "nomenclature", "", "1C", 0.8)
+			if err != nil {
+				t.Fatalf("Failed to create project: %v", err)
+			}
+			for d := 0; d < 2; d++ {
+				name := fmt.Sprintf("db_%d_%d_%d.db", c, p, d)
+				if _, err := db.CreateProjectDatabase(project.ID, name, filepath.Join(t.TempDir(), name), "", int64(100*d)); err != nil {
+					t.Fatalf("Failed to create project database: %v", err)
+				}
+				want++
+			}
+		}
+	}
+
+	// Проект без баз данных и база с NULL в file_size и last_used_at
+	client, err := db.CreateClient("Client without databases", "", "", "", "", "", "tests")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := db.CreateClientProject(client.ID, "Empty project", "nomenclature", "", "1C", 0.8); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := db.conn.Exec(`UPDATE project_databases SET file_size = NULL, last_used_at = NULL WHERE name = 'db_0_0_1.db'`); err != nil {
+		t.Fatalf("Failed to clear nullable fields: %v", err)
+	}
+	if _, err := db.conn.Exec(`UPDATE project_databases SET last_used_at = CURRENT_TIMESTAMP WHERE name = 'db_1_0_1.db'`); err != nil {
+		t.Fatalf("Failed to set last_used_at: %v", err)
+	}
+
+	counting := openQueryCountingServiceDB(t, path)
+	before := countingDriver.queries.Load()
+	databases, err := counting.GetAllProjectDatabases()
+	if err != nil {
+		t.Fatalf("GetAllProjectDatabases failed: %v", err)
+	}
+	if queries := countingDriver.queries.Load() - before; queries != 1 {
+		t.Errorf("Expected 1 query, got %d", queries)
+	}
+
+	if len(databases) != want {
+		t.Fatalf("Expected %d databases, got %d", want, len(databases))
+	}
+	for _, projectDB := range databases {
+		switch projectDB.Name {
+		case "db_0_0_1.db":
+			if projectDB.FileSize != 0 || projectDB.LastUsedAt != nil {
+				t.Errorf("Expected NULL file_size and last_used_at, got %+v", projectDB)
+			}
+		case "db_1_0_1.db":
+			if projectDB.FileSize != 100 || projectDB.LastUsedAt == nil {
+				t.Errorf("Expected file_size and last_used_at to be set, got %+v", projectDB)
+			}
+		}
+	}
+
+	// Результат совпадает с обходом проектов по одному
+	var perProject int
+	clients, err := db.GetAllClients()
+	if err != nil {
+		t.Fatalf("GetAllClients failed: %v", err)
+	}
+	for _, c := range clients {
+		clientProjects, err := db.GetClientProjects(c.ID)
+		if err != nil {
+			t.Fatalf("GetClientProjects failed: %v", err)
+		}
+		for _, project := range clientProjects {
+			projectDatabases, err := db.GetProjectDatabases(project.ID, false)
+			if err != nil {
+				t.Fatalf("GetProjectDatabases failed: %v", err)
+			}
+			perProject += len(projectDatabases)
+		}
+	}
+	if perProject != len(databases) {
+		t.Errorf("Per-project traversal found %d databases, batch query %d", perProject, len(databases))
+	}
+}
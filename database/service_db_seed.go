@@ -143,6 +143,10 @@ func ensureDemoClients(db *sql.DB) error {
 				return fmt.Errorf("failed to insert demo project %q: %w", project.Name, err)
 			}
 		}
+
+		if _, err := tx.Exec(`UPDATE clients SET`+clientStatsSetClause+` WHERE id = ?`, clientID); err != nil {
+			return fmt.Errorf("failed to compute demo client stats for %q: %w", client.Name, err)
+		}
 	}
 
 	if err := tx.Commit(); err != nil {
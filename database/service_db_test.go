@@ -1,6 +1,9 @@
 package database
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -349,3 +352,290 @@ func TestGetClientProject(t *testing.T) {
 	}
 }
 
+func TestCreateBenchmark_RejectsEmptyName(t *testing.T) {
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	client, err := db.CreateClient("Test Client", "Test Client Legal Name", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Test Project", "normalization", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	for _, name := range []string{"", "   ", "\t\n"} {
+		if _, err := db.CreateClientBenchmark(project.ID, name, "name", "nomenclature", "", "", "", 0.9); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("CreateClientBenchmark(%q): expected ErrInvalidName, got %v", name, err)
+		}
+		if _, err := db.CreateNomenclatureBenchmark(project.ID, name, "name", "", "", "", 0.9, nil, nil, nil, nil); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("CreateNomenclatureBenchmark(%q): expected ErrInvalidName, got %v", name, err)
+		}
+		if _, err := db.CreateCounterpartyBenchmark(project.ID, name, "name",
+			"1234567890", "", "", "", "", "", "", "", "", "", "", "", "", "", "", 0.9); !errors.Is(err, ErrInvalidName) {
+			t.Errorf("CreateCounterpartyBenchmark(%q): expected ErrInvalidName, got %v", name, err)
+		}
+	}
+
+	benchmarks, err := db.GetClientBenchmarks(project.ID, "", false)
+	if err != nil {
+		t.Fatalf("Failed to get benchmarks: %v", err)
+	}
+	if len(benchmarks) != 0 {
+		t.Errorf("Expected no benchmarks to be created, got %d", len(benchmarks))
+	}
+
+	benchmark, err := db.CreateClientBenchmark(project.ID, " Болт М10 ", "болт м10", "nomenclature", "", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientBenchmark with valid name failed: %v", err)
+	}
+	if benchmark.OriginalName != " Болт М10 " {
+		t.Errorf("Expected original name to be stored as is, got %q", benchmark.OriginalName)
+	}
+}
+
+func TestCreateProjectDatabase_RejectsDuplicatePath(t *testing.T) {
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	client, err := db.CreateClient("Test Client", "Test Client Legal Name", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Test Project", "normalization", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("Failed to create dir: %v", err)
+	}
+	filePath := filepath.Join(dir, "sub", "data.db")
+	if err := os.WriteFile(filePath, nil, 0o644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	first, err := db.CreateProjectDatabase(project.ID, "First", filePath, "", 0)
+	if err != nil {
+		t.Fatalf("Failed to create project database: %v", err)
+	}
+
+	// Тот же файл с обратными слешами и лишними сегментами пути
+	variant := filepath.ToSlash(dir) + `\sub\..\sub\.\data.db`
+	if _, err := db.CreateProjectDatabase(project.ID, "Second", variant, "", 0); !errors.Is(err, ErrDuplicate) {
+		t.Fatalf("Expected ErrDuplicate for %q, got %v", variant, err)
+	}
+
+	databases, err := db.GetProjectDatabases(project.ID, false)
+	if err != nil {
+		t.Fatalf("Failed to get project databases: %v", err)
+	}
+	if len(databases) != 1 || databases[0].ID != first.ID {
+		t.Errorf("Expected only project database %d, got %d records", first.ID, len(databases))
+	}
+}
+
+func TestCreateProjectDatabase_SharedFileAcrossClients(t *testing.T) {
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	var projects []*ClientProject
+	for _, name := range []string{"First Client", "Second Client"} {
+		client, err := db.CreateClient(name, name+" Legal Name", "", "", "", "", "RU", "test_user")
+		if err != nil {
+			t.Fatalf("Failed to create client: %v", err)
+		}
+		project, err := db.CreateClientProject(client.ID, "Project", "normalization", "", "1C", 0.8)
+		if err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+		projects = append(projects, project)
+	}
+
+	filePath := filepath.Join(t.TempDir(), "shared.db")
+	if err := os.WriteFile(filePath, nil, 0o644); err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+
+	for _, project := range projects {
+		if _, err := db.CreateProjectDatabase(project.ID, "Shared", filePath, "", 0); err != nil {
+			t.Fatalf("Expected each client to register the shared file, got %v", err)
+		}
+	}
+
+	// Базы в памяти не считаются одним файлом
+	for i := 0; i < 2; i++ {
+		if _, err := db.CreateProjectDatabase(projects[0].ID, "Memory", ":memory:", "", 0); err != nil {
+			t.Fatalf("Expected in-memory database to be registered, got %v", err)
+		}
+	}
+
+	// Проверка выполняется и в БД, в обход CreateProjectDatabase
+	_, err = db.GetDB().Exec(`INSERT INTO project_databases (client_project_id, name, file_path, abs_path, is_active) VALUES (?, ?, ?, ?, TRUE)`,
+		projects[1].ID, "Direct", filePath, CanonicalDatabasePath(filePath))
+	if !isUniqueConstraintError(err) {
+		t.Errorf("Expected unique constraint error from trigger, got %v", err)
+	}
+}
+
+func TestGetProjectDatabaseByHash(t *testing.T) {
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	client, err := db.CreateClient("Test Client", "Test Client Legal Name", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Test Project", "normalization", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	otherProject, err := db.CreateClientProject(client.ID, "Other Project", "normalization", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	dir := t.TempDir()
+	content := []byte("SQLite format 3\x00 export")
+	firstPath := filepath.Join(dir, "export_1.db")
+	secondPath := filepath.Join(dir, "export_2.db")
+	for _, path := range []string{firstPath, secondPath} {
+		if err := os.WriteFile(path, content, 0o644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+	}
+
+	hash, err := ComputeFileSHA256(secondPath)
+	if err != nil {
+		t.Fatalf("ComputeFileSHA256 failed: %v", err)
+	}
+	if len(hash) != 64 {
+		t.Fatalf("Expected hex SHA-256, got %q", hash)
+	}
+
+	if found, err := db.GetProjectDatabaseByHash(project.ID, hash); err != nil || found != nil {
+		t.Fatalf("Expected no database before registration, got %v, %v", found, err)
+	}
+
+	first, err := db.CreateProjectDatabase(project.ID, "First", firstPath, "", int64(len(content)))
+	if err != nil {
+		t.Fatalf("Failed to create project database: %v", err)
+	}
+
+	// Другой файл с тем же содержимым находится по хешу
+	found, err := db.GetProjectDatabaseByHash(project.ID, hash)
+	if err != nil {
+		t.Fatalf("GetProjectDatabaseByHash failed: %v", err)
+	}
+	if found == nil || found.ID != first.ID {
+		t.Fatalf("Expected database %d by hash, got %v", first.ID, found)
+	}
+
+	// Поиск ограничен проектом
+	if found, err := db.GetProjectDatabaseByHash(otherProject.ID, hash); err != nil || found != nil {
+		t.Errorf("Expected no database in other project, got %v, %v", found, err)
+	}
+}
+
+func TestMigrateProjectDatabaseAbsPath_MergesDuplicates(t *testing.T) {
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	client, err := db.CreateClient("Test Client", "Test Client Legal Name", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := db.CreateClientProject(client.ID, "Test Project", "normalization", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	otherClient, err := db.CreateClient("Other Client", "Other Client Legal Name", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	otherProject, err := db.CreateClientProject(otherClient.ID, "Other Project", "normalization", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	// Имитируем данные, зарегистрированные до появления abs_path
+	conn := db.GetDB()
+	for _, trigger := range []string{"trg_project_databases_abs_path_insert", "trg_project_databases_abs_path_update"} {
+		if _, err := conn.Exec(`DROP TRIGGER ` + trigger); err != nil {
+			t.Fatalf("Failed to drop trigger: %v", err)
+		}
+	}
+	var ids []int64
+	insert := func(projectID int, p string) int64 {
+		t.Helper()
+		result, err := conn.Exec(`INSERT INTO project_databases (client_project_id, name, file_path, is_active) VALUES (?, ?, ?, TRUE)`,
+			projectID, p, p)
+		if err != nil {
+			t.Fatalf("Failed to insert project database: %v", err)
+		}
+		id, _ := result.LastInsertId()
+		return id
+	}
+	for _, p := range []string{"/data/bases/main.db", `/data\bases\..\bases\main.db`, "/data/bases/other.db", ":memory:", ":memory:"} {
+		ids = append(ids, insert(project.ID, p))
+	}
+	// Тот же файл у другого клиента не объединяется
+	otherClientDB := insert(otherProject.ID, "/data/bases/main.db")
+	if _, err := conn.Exec(`INSERT INTO normalization_sessions (project_database_id) VALUES (?)`, ids[1]); err != nil {
+		t.Fatalf("Failed to insert normalization session: %v", err)
+	}
+
+	if err := MigrateProjectDatabaseAbsPath(conn); err != nil {
+		t.Fatalf("MigrateProjectDatabaseAbsPath failed: %v", err)
+	}
+
+	var count int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM project_databases`).Scan(&count); err != nil {
+		t.Fatalf("Failed to count project databases: %v", err)
+	}
+	if count != 5 {
+		t.Errorf("Expected 5 project databases after merge, got %d", count)
+	}
+	var otherProjectID int
+	if err := conn.QueryRow(`SELECT client_project_id FROM project_databases WHERE id = ?`, otherClientDB).Scan(&otherProjectID); err != nil || otherProjectID != otherProject.ID {
+		t.Errorf("Expected project database of other client to be kept, got project %d, %v", otherProjectID, err)
+	}
+	var memoryAbsPaths int
+	if err := conn.QueryRow(`SELECT COUNT(*) FROM project_databases WHERE file_path = ':memory:' AND abs_path IS NOT NULL`).Scan(&memoryAbsPaths); err != nil {
+		t.Fatalf("Failed to count in-memory abs paths: %v", err)
+	}
+	if memoryAbsPaths != 0 {
+		t.Errorf("Expected no abs_path for in-memory databases, got %d", memoryAbsPaths)
+	}
+
+	var sessionDBID int64
+	if err := conn.QueryRow(`SELECT project_database_id FROM normalization_sessions`).Scan(&sessionDBID); err != nil {
+		t.Fatalf("Failed to get normalization session: %v", err)
+	}
+	if sessionDBID != ids[0] {
+		t.Errorf("Expected session to reference project database %d, got %d", ids[0], sessionDBID)
+	}
+
+	// Повторный запуск миграции не должен ничего менять
+	if err := MigrateProjectDatabaseAbsPath(conn); err != nil {
+		t.Fatalf("Repeated MigrateProjectDatabaseAbsPath failed: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// SchemaSetupLockName блокировка инициализации схемы сервисной БД и миграций CLI-утилит
+	SchemaSetupLockName = "schema_setup"
+	// SystemProjectLockName блокировка создания системного клиента и проекта глобальных эталонов
+	SystemProjectLockName = "system_project"
+
+	// DefaultSetupLockTimeout время ожидания освобождения блокировки другим процессом
+	DefaultSetupLockTimeout = 2 * time.Minute
+	// SetupLockTTL время, после которого блокировка считается брошенной (процесс-владелец завершился аварийно)
+	SetupLockTTL = 5 * time.Minute
+
+	setupLockRetryInterval = 50 * time.Millisecond
+)
+
+// ErrSetupLockTimeout возвращается, если блокировку не удалось получить за отведенное время
+var ErrSetupLockTimeout = errors.New("timed out waiting for setup lock")
+
+// setupLockCounter делает владельца блокировки уникальным для каждого захвата внутри процесса
+var setupLockCounter int64
+
+// WithSetupLock выполняет fn, удерживая межпроцессную блокировку name в сервисной БД.
+// Блокировка - строка в таблице setup_locks: параллельно запущенные CLI-утилиты и горутины
+// выполняют инициализацию по очереди. Блокировка снимается по завершении fn,
+// а брошенная блокировка считается истекшей через SetupLockTTL.
+func (db *ServiceDB) WithSetupLock(name string, timeout time.Duration, fn func() error) error {
+	return withSetupLock(db.conn, name, timeout, fn)
+}
+
+// withSetupLock реализует WithSetupLock для произвольного подключения
+func withSetupLock(conn *sql.DB, name string, timeout time.Duration, fn func() error) error {
+	release, err := acquireSetupLock(conn, name, timeout)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return fn()
+}
+
+// acquireSetupLock захватывает блокировку name и возвращает функцию ее освобождения
+func acquireSetupLock(conn *sql.DB, name string, timeout time.Duration) (func(), error) {
+	if _, err := execSetupLock(conn, `
+		CREATE TABLE IF NOT EXISTS setup_locks (
+			name TEXT PRIMARY KEY,
+			owner TEXT NOT NULL,
+			acquired_at INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL
+		)
+	`); err != nil {
+		return nil, fmt.Errorf("failed to create setup_locks table: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	owner := fmt.Sprintf("%s:%d:%d", hostname, os.Getpid(), atomic.AddInt64(&setupLockCounter, 1))
+	deadline := time.Now().Add(timeout)
+
+	for {
+		now := time.Now()
+
+		// Снимаем брошенную блокировку, срок которой истек
+		if _, err := execSetupLock(conn, `DELETE FROM setup_locks WHERE name = ? AND expires_at < ?`,
+			name, now.UnixMilli()); err != nil {
+			return nil, fmt.Errorf("failed to clear expired setup lock %s: %w", name, err)
+		}
+
+		result, err := execSetupLock(conn, `
+			INSERT OR IGNORE INTO setup_locks (name, owner, acquired_at, expires_at)
+			VALUES (?, ?, ?, ?)
+		`, name, owner, now.UnixMilli(), now.Add(SetupLockTTL).UnixMilli())
+		if err != nil {
+			return nil, fmt.Errorf("failed to acquire setup lock %s: %w", name, err)
+		}
+		if affected, _ := result.RowsAffected(); affected == 1 {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			var holder string
+			_ = conn.QueryRow(`SELECT owner FROM setup_locks WHERE name = ?`, name).Scan(&holder)
+			return nil, fmt.Errorf("%w %s (held by %s)", ErrSetupLockTimeout, name, holder)
+		}
+		time.Sleep(setupLockRetryInterval)
+	}
+
+	return func() {
+		_, _ = execSetupLock(conn, `DELETE FROM setup_locks WHERE name = ? AND owner = ?`, name, owner)
+	}, nil
+}
+
+// execSetupLock выполняет запрос, повторяя его, пока БД занята записью другого процесса
+func execSetupLock(conn *sql.DB, query string, args ...interface{}) (sql.Result, error) {
+	var lastErr error
+	for attempt := 0; attempt < 20; attempt++ {
+		result, err := conn.Exec(query, args...)
+		if err == nil {
+			return result, nil
+		}
+		errStr := strings.ToLower(err.Error())
+		if !strings.Contains(errStr, "database is locked") && !strings.Contains(errStr, "busy") {
+			return nil, err
+		}
+		lastErr = err
+		time.Sleep(setupLockRetryInterval)
+	}
+	return nil, lastErr
+}
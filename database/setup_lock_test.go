@@ -0,0 +1,148 @@
+package database
+
+import (
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrCreateSystemProject_ConcurrentSetup(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "service.db")
+
+	// Два подключения к одному файлу имитируют две одновременно запущенные CLI-утилиты
+	const processes = 2
+	dbs := make([]*ServiceDB, processes)
+	var openWG sync.WaitGroup
+	openErrs := make([]error, processes)
+	for i := 0; i < processes; i++ {
+		openWG.Add(1)
+		go func(i int) {
+			defer openWG.Done()
+			dbs[i], openErrs[i] = NewServiceDB(dbPath)
+		}(i)
+	}
+	openWG.Wait()
+	for i, err := range openErrs {
+		if err != nil {
+			t.Fatalf("Failed to open ServiceDB %d: %v", i, err)
+		}
+		defer dbs[i].Close()
+	}
+
+	const callsPerProcess = 4
+	projectIDs := make(chan int, processes*callsPerProcess)
+	errs := make(chan error, processes*callsPerProcess)
+	var wg sync.WaitGroup
+	for _, db := range dbs {
+		for j := 0; j < callsPerProcess; j++ {
+			wg.Add(1)
+			go func(db *ServiceDB) {
+				defer wg.Done()
+				project, err := db.GetOrCreateSystemProject()
+				if err != nil {
+					errs <- err
+					return
+				}
+				projectIDs <- project.ID
+			}(db)
+		}
+	}
+	wg.Wait()
+	close(projectIDs)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("GetOrCreateSystemProject failed: %v", err)
+	}
+
+	ids := make(map[int]bool)
+	for id := range projectIDs {
+		ids[id] = true
+	}
+	if len(ids) != 1 {
+		t.Errorf("Expected a single system project, got IDs %v", ids)
+	}
+
+	var clients, projects int
+	if err := dbs[0].conn.QueryRow(`SELECT COUNT(*) FROM clients WHERE name = 'Система'`).Scan(&clients); err != nil {
+		t.Fatalf("Failed to count system clients: %v", err)
+	}
+	if err := dbs[0].conn.QueryRow(`SELECT COUNT(*) FROM client_projects WHERE name = 'Глобальные эталоны'`).Scan(&projects); err != nil {
+		t.Fatalf("Failed to count system projects: %v", err)
+	}
+	if clients != 1 || projects != 1 {
+		t.Errorf("Expected 1 system client and 1 system project, got %d and %d", clients, projects)
+	}
+
+	var locks int
+	if err := dbs[0].conn.QueryRow(`SELECT COUNT(*) FROM setup_locks`).Scan(&locks); err != nil {
+		t.Fatalf("Failed to count setup locks: %v", err)
+	}
+	if locks != 0 {
+		t.Errorf("Expected all setup locks to be released, got %d", locks)
+	}
+}
+
+func TestWithSetupLock_SerializesAndTimesOut(t *testing.T) {
+	db, err := NewServiceDB(filepath.Join(t.TempDir(), "service.db"))
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := db.WithSetupLock("test_lock", 5*time.Second, func() error {
+				current := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				for {
+					prev := atomic.LoadInt32(&maxRunning)
+					if current <= prev || atomic.CompareAndSwapInt32(&maxRunning, prev, current) {
+						break
+					}
+				}
+				time.Sleep(20 * time.Millisecond)
+				return nil
+			})
+			if err != nil {
+				t.Errorf("WithSetupLock failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxRunning != 1 {
+		t.Errorf("Expected setups to run one at a time, got %d concurrently", maxRunning)
+	}
+
+	// Пока блокировка удерживается, второй захват завершается по таймауту
+	held := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		_ = db.WithSetupLock("test_lock", time.Second, func() error {
+			close(held)
+			<-done
+			return nil
+		})
+	}()
+	<-held
+	err = db.WithSetupLock("test_lock", 100*time.Millisecond, func() error { return nil })
+	close(done)
+	if !errors.Is(err, ErrSetupLockTimeout) {
+		t.Errorf("Expected ErrSetupLockTimeout, got %v", err)
+	}
+
+	// Брошенная блокировка с истекшим сроком перехватывается
+	if _, err := db.conn.Exec(`INSERT OR REPLACE INTO setup_locks (name, owner, acquired_at, expires_at) VALUES ('stale_lock', 'dead', 0, 0)`); err != nil {
+		t.Fatalf("Failed to insert stale lock: %v", err)
+	}
+	if err := db.WithSetupLock("stale_lock", 100*time.Millisecond, func() error { return nil }); err != nil {
+		t.Errorf("Expected stale lock to be taken over, got %v", err)
+	}
+}
@@ -0,0 +1,322 @@
+package database
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// TnvedEntry представляет одну позицию номенклатуры ТН ВЭД
+type TnvedEntry struct {
+	Code       string
+	Name       string
+	ParentCode string
+	Level      int
+}
+
+// tnvedCodeLengths длины кодов ТН ВЭД ЕАЭС по уровням иерархии:
+// группа (2), товарная позиция (4), субпозиция (6), подсубпозиции (8 и 9), полный код (10)
+var tnvedCodeLengths = []int{2, 4, 6, 8, 9, 10}
+
+// normalizeTnvedCode приводит код ТН ВЭД к строке из цифр без пробелов и разделителей.
+// Возвращает пустую строку, если значение не является кодом (например, заголовок раздела).
+// Коды, у которых Excel отбросил ведущий ноль ("101" вместо "0101"), дополняются нулем.
+func normalizeTnvedCode(value string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(value) {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == ' ' || r == '.' || r == '\u00a0':
+			// Разделители разрядов кода: "0101 21 000 0", "0101.21"
+		default:
+			return ""
+		}
+	}
+
+	code := b.String()
+	if len(code) == 0 || len(code) > 10 {
+		return ""
+	}
+	if len(code)%2 == 1 && len(code) < 9 {
+		code = "0" + code
+	}
+	return code
+}
+
+// determineTnvedLevel определяет уровень кода ТН ВЭД (1 - группа, 6 - полный десятизначный код)
+func determineTnvedLevel(code string) int {
+	for i, length := range tnvedCodeLengths {
+		if len(code) <= length {
+			return i + 1
+		}
+	}
+	return len(tnvedCodeLengths)
+}
+
+// determineTnvedParentCode определяет родительский код ТН ВЭД
+func determineTnvedParentCode(code string) string {
+	parent := ""
+	for _, length := range tnvedCodeLengths {
+		if length >= len(code) {
+			break
+		}
+		parent = code[:length]
+	}
+	return parent
+}
+
+// newTnvedEntry создает запись ТН ВЭД с уровнем и родителем, вычисленными по коду
+func newTnvedEntry(code, name string) TnvedEntry {
+	return TnvedEntry{
+		Code:       code,
+		Name:       strings.Join(strings.Fields(name), " "),
+		ParentCode: determineTnvedParentCode(code),
+		Level:      determineTnvedLevel(code),
+	}
+}
+
+// ParseTnvedExcelFile парсит Excel-файл номенклатуры ТН ВЭД.
+// Колонки кода и наименования определяются по заголовкам ("код", "наименование");
+// если заголовки не найдены, используются первые две колонки.
+func ParseTnvedExcelFile(filePath string) ([]TnvedEntry, error) {
+	f, err := excelize.OpenFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Excel file: %w", err)
+	}
+	defer f.Close()
+
+	sheetName := f.GetSheetName(0)
+	if sheetName == "" {
+		return nil, fmt.Errorf("no sheets found in Excel file")
+	}
+
+	rows, err := f.GetRows(sheetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows: %w", err)
+	}
+
+	codeCol, nameCol := 0, 1
+	startRow := 0
+	// Заголовок может быть не в первой строке: официальные выгрузки начинаются с шапки документа
+	for i := 0; i < len(rows) && i < 10; i++ {
+		headerCode, headerName := -1, -1
+		for j, cell := range rows[i] {
+			header := strings.ToLower(strings.TrimSpace(cell))
+			switch {
+			case headerCode == -1 && strings.HasPrefix(header, "код"):
+				headerCode = j
+			case headerName == -1 && strings.HasPrefix(header, "наименование"):
+				headerName = j
+			}
+		}
+		if headerCode != -1 && headerName != -1 {
+			codeCol, nameCol = headerCode, headerName
+			startRow = i + 1
+			break
+		}
+	}
+
+	entries := make([]TnvedEntry, 0, len(rows))
+	for _, row := range rows[startRow:] {
+		if codeCol >= len(row) || nameCol >= len(row) {
+			continue
+		}
+		code := normalizeTnvedCode(row[codeCol])
+		name := strings.TrimSpace(row[nameCol])
+		if code == "" || name == "" {
+			continue
+		}
+		entries = append(entries, newTnvedEntry(code, name))
+	}
+
+	return dedupeTnvedEntries(entries), nil
+}
+
+// ParseTnvedXML парсит XML номенклатуры ТН ВЭД.
+// Позиция - любой элемент с кодом в атрибуте code или в дочернем элементе <code>;
+// наименование берется из атрибута name или дочернего элемента <name>.
+// Позиции могут быть как вложенными друг в друга, так и перечисленными подряд.
+func ParseTnvedXML(r io.Reader) ([]TnvedEntry, error) {
+	type frame struct {
+		code string
+		name string
+	}
+
+	decoder := xml.NewDecoder(r)
+	// Официальные выгрузки бывают в windows-1251, который encoding/xml не поддерживает
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		if strings.EqualFold(charset, "utf-8") {
+			return input, nil
+		}
+		return nil, fmt.Errorf("unsupported XML charset %q, convert the file to UTF-8", charset)
+	}
+
+	var stack []*frame
+	entries := make([]TnvedEntry, 0)
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse TNVED XML: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			field := strings.ToLower(t.Name.Local)
+			if (field == "code" || field == "name") && len(stack) > 0 {
+				var text string
+				if err := decoder.DecodeElement(&text, &t); err != nil {
+					return nil, fmt.Errorf("failed to parse TNVED XML element %s: %w", t.Name.Local, err)
+				}
+				if field == "code" {
+					stack[len(stack)-1].code = text
+				} else {
+					stack[len(stack)-1].name = text
+				}
+				continue
+			}
+
+			f := &frame{}
+			for _, attr := range t.Attr {
+				switch strings.ToLower(attr.Name.Local) {
+				case "code":
+					f.code = attr.Value
+				case "name":
+					f.name = attr.Value
+				}
+			}
+			stack = append(stack, f)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			code := normalizeTnvedCode(f.code)
+			name := strings.TrimSpace(f.name)
+			if code != "" && name != "" {
+				entries = append(entries, newTnvedEntry(code, name))
+			}
+		}
+	}
+
+	return dedupeTnvedEntries(entries), nil
+}
+
+// dedupeTnvedEntries удаляет повторы кодов (остается последнее наименование) и сортирует записи по коду.
+// Родителем назначается ближайший вышестоящий код, присутствующий в файле: в номенклатуре часть уровней
+// пропускается (например, после субпозиции сразу идет десятизначный код).
+func dedupeTnvedEntries(entries []TnvedEntry) []TnvedEntry {
+	byCode := make(map[string]TnvedEntry, len(entries))
+	for _, entry := range entries {
+		byCode[entry.Code] = entry
+	}
+
+	result := make([]TnvedEntry, 0, len(byCode))
+	for _, entry := range byCode {
+		for parent := entry.ParentCode; parent != ""; parent = determineTnvedParentCode(parent) {
+			if _, ok := byCode[parent]; ok {
+				entry.ParentCode = parent
+				break
+			}
+		}
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Code < result[j].Code
+	})
+	return result
+}
+
+// ParseTnvedFile парсит файл номенклатуры ТН ВЭД, формат определяется по расширению (.xlsx/.xlsm или .xml)
+func ParseTnvedFile(filePath string) ([]TnvedEntry, error) {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".xlsx", ".xlsm":
+		return ParseTnvedExcelFile(filePath)
+	case ".xml":
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open TNVED file: %w", err)
+		}
+		defer file.Close()
+		return ParseTnvedXML(file)
+	default:
+		return nil, fmt.Errorf("unsupported TNVED file format: %s", filePath)
+	}
+}
+
+// LoadTnvedToDatabase загружает записи ТН ВЭД в справочник tnved_reference.
+// Существующие коды обновляются (upsert по уникальному коду), ссылки эталонов на справочник сохраняются.
+func LoadTnvedToDatabase(db DBConnection, entries []TnvedEntry) error {
+	if err := CreateTNVEDReferenceTable(db.GetDB()); err != nil {
+		return err
+	}
+
+	tx, err := db.GetDB().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`
+		INSERT INTO tnved_reference (code, name, parent_code, level, source, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 'tnved_official', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT(code) DO UPDATE SET
+			name = excluded.name,
+			parent_code = excluded.parent_code,
+			level = excluded.level,
+			source = excluded.source,
+			updated_at = CURRENT_TIMESTAMP
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, entry := range entries {
+		var parentCode interface{}
+		if entry.ParentCode != "" {
+			parentCode = entry.ParentCode
+		}
+
+		if _, err := stmt.Exec(entry.Code, entry.Name, parentCode, entry.Level); err != nil {
+			return fmt.Errorf("failed to upsert TNVED entry %s: %w", entry.Code, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Successfully loaded %d TNVED entries to database", len(entries))
+	return nil
+}
+
+// LoadTnvedFromFile - вспомогательная функция для загрузки ТН ВЭД из файла (Excel или XML) в БД
+func LoadTnvedFromFile(db DBConnection, filePath string) error {
+	log.Printf("Loading TNVED nomenclature from file: %s", filePath)
+
+	entries, err := ParseTnvedFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to parse TNVED file: %w", err)
+	}
+
+	if err := LoadTnvedToDatabase(db, entries); err != nil {
+		return fmt.Errorf("failed to load TNVED to database: %w", err)
+	}
+
+	log.Printf("TNVED nomenclature loaded successfully (%d entries)", len(entries))
+	return nil
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// tnvedReferenceRow строка справочника tnved_reference для проверок
+type tnvedReferenceRow struct {
+	Name       string
+	ParentCode string
+	Level      int
+	Source     string
+}
+
+// getTnvedReferenceRows возвращает содержимое справочника tnved_reference по кодам
+func getTnvedReferenceRows(t *testing.T, db *ServiceDB) map[string]tnvedReferenceRow {
+	t.Helper()
+
+	rows, err := db.conn.Query(`SELECT code, name, COALESCE(parent_code, ''), level, source FROM tnved_reference`)
+	if err != nil {
+		t.Fatalf("Failed to query tnved_reference: %v", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]tnvedReferenceRow)
+	for rows.Next() {
+		var code string
+		var row tnvedReferenceRow
+		if err := rows.Scan(&code, &row.Name, &row.ParentCode, &row.Level, &row.Source); err != nil {
+			t.Fatalf("Failed to scan tnved_reference: %v", err)
+		}
+		result[code] = row
+	}
+	return result
+}
+
+func TestLoadTnvedFromFile_XMLHierarchy(t *testing.T) {
+	db, err := NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer db.Close()
+
+	// Код, созданный ранее импортом ГИСП, должен обновиться, а не задублироваться
+	existing, err := db.FindOrCreateTNVEDReference("7318", "Болты")
+	if err != nil {
+		t.Fatalf("Failed to create existing TNVED reference: %v", err)
+	}
+
+	if err := LoadTnvedFromFile(db, filepath.Join("testdata", "tnved_sample.xml")); err != nil {
+		t.Fatalf("LoadTnvedFromFile failed: %v", err)
+	}
+
+	rows := getTnvedReferenceRows(t, db)
+	expected := map[string]tnvedReferenceRow{
+		"73":         {Name: "Изделия из черных металлов", ParentCode: "", Level: 1},
+		"7318":       {Name: "Винты, болты, гайки, шурупы, глухари, ввертные крюки, заклепки, шпонки, шплинты, шайбы", ParentCode: "73", Level: 2},
+		"731815":     {Name: "Винты и болты прочие, с гайками или шайбами или без них", ParentCode: "7318", Level: 3},
+		"731815810":  {Name: "Болты с шестигранной головкой из нержавеющей стали", ParentCode: "731815", Level: 5},
+		"7318158100": {Name: "Болты с шестигранной головкой из нержавеющей стали", ParentCode: "731815810", Level: 6},
+		"8481":       {Name: "Краны, клапаны, вентили и аналогичная арматура", ParentCode: "84", Level: 2},
+	}
+	if len(rows) != len(expected) {
+		t.Errorf("Expected %d TNVED entries, got %d: %+v", len(expected), len(rows), rows)
+	}
+	for code, want := range expected {
+		got, ok := rows[code]
+		if !ok {
+			t.Errorf("TNVED code %s was not loaded", code)
+			continue
+		}
+		if got.Name != want.Name || got.ParentCode != want.ParentCode || got.Level != want.Level {
+			t.Errorf("TNVED code %s: expected %+v, got %+v", code, want, got)
+		}
+		if got.Source != "tnved_official" {
+			t.Errorf("TNVED code %s: expected source 'tnved_official', got %q", code, got.Source)
+		}
+	}
+
+	updated, err := db.FindOrCreateTNVEDReference("7318", "")
+	if err != nil {
+		t.Fatalf("Failed to get TNVED reference: %v", err)
+	}
+	if updated.ID != existing.ID {
+		t.Errorf("Expected upsert to keep ID %d, got %d", existing.ID, updated.ID)
+	}
+
+	// Повторная загрузка не создает дублей
+	if err := LoadTnvedFromFile(db, filepath.Join("testdata", "tnved_sample.xml")); err != nil {
+		t.Fatalf("Repeated LoadTnvedFromFile failed: %v", err)
+	}
+	if rows := getTnvedReferenceRows(t, db); len(rows) != len(expected) {
+		t.Errorf("Expected %d TNVED entries after reload, got %d", len(expected), len(rows))
+	}
+}
+
+func TestParseTnvedExcelFile(t *testing.T) {
+	f := excelize.NewFile()
+	sheet := f.GetSheetName(0)
+	cells := [][]interface{}{
+		{"Единая Товарная номенклатура внешнеэкономической деятельности ЕАЭС"},
+		{},
+		{"Код ТН ВЭД", "Наименование позиции", "Доп. ед. изм."},
+		{"РАЗДЕЛ I", "Живые животные; продукты животного происхождения"},
+		{"01", "Живые животные"},
+		// Excel хранит код числом и теряет ведущий ноль
+		{101, "Лошади, ослы, мулы и лошаки живые"},
+		{"0101 21 000 0", "Чистопородные племенные животные", "шт"},
+	}
+	for i, row := range cells {
+		cell, err := excelize.CoordinatesToCellName(1, i+1)
+		if err != nil {
+			t.Fatalf("Failed to build cell name: %v", err)
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			t.Fatalf("Failed to fill row %d: %v", i+1, err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "tnved.xlsx")
+	if err := f.SaveAs(path); err != nil {
+		t.Fatalf("Failed to save Excel file: %v", err)
+	}
+
+	entries, err := ParseTnvedFile(path)
+	if err != nil {
+		t.Fatalf("ParseTnvedFile failed: %v", err)
+	}
+
+	expected := []TnvedEntry{
+		{Code: "01", Name: "Живые животные", ParentCode: "", Level: 1},
+		{Code: "0101", Name: "Лошади, ослы, мулы и лошаки живые", ParentCode: "01", Level: 2},
+		{Code: "0101210000", Name: "Чистопородные племенные животные", ParentCode: "0101", Level: 6},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d: %+v", len(expected), len(entries), entries)
+	}
+	for i, want := range expected {
+		if entries[i] != want {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, want, entries[i])
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Результаты проверки ссылки номенклатуры на ТУ/ГОСТ
+const (
+	// TuGostReferenceValid ГОСТ найден в базе ГОСТов и действует
+	TuGostReferenceValid = "valid"
+	// TuGostReferenceMissing ГОСТ (или указанная редакция) отсутствует в базе ГОСТов
+	TuGostReferenceMissing = "missing"
+	// TuGostReferenceWithdrawn ГОСТ найден, но отменен или заменен
+	TuGostReferenceWithdrawn = "withdrawn"
+	// TuGostReferenceSkipped ссылка на ТУ: технические условия в базе ГОСТов не хранятся
+	TuGostReferenceSkipped = "skipped"
+)
+
+// TuGostReferenceCheck результат проверки одной записи справочника ТУ/ГОСТ, на которую ссылаются номенклатуры проекта
+type TuGostReferenceCheck struct {
+	ReferenceID    int        `json:"reference_id"`
+	Code           string     `json:"code"`
+	Name           string     `json:"name"`
+	DocumentType   string     `json:"document_type"`
+	BenchmarkIDs   []int      `json:"benchmark_ids"`
+	Result         string     `json:"result"`
+	GostID         int        `json:"gost_id,omitempty"`
+	GostNumber     string     `json:"gost_number,omitempty"`
+	GostStatus     string     `json:"gost_status,omitempty"`
+	WithdrawalDate *time.Time `json:"withdrawal_date,omitempty"`
+	Message        string     `json:"message,omitempty"`
+}
+
+// VerifyReport итоги проверки ссылок номенклатур проекта на ТУ/ГОСТ по базе ГОСТов
+type VerifyReport struct {
+	ProjectID  int                    `json:"project_id"`
+	Total      int                    `json:"total"`
+	Valid      int                    `json:"valid"`
+	Missing    int                    `json:"missing"`
+	Withdrawn  int                    `json:"withdrawn"`
+	Skipped    int                    `json:"skipped"`
+	References []TuGostReferenceCheck `json:"references"`
+	CheckedAt  time.Time              `json:"checked_at"`
+}
+
+// VerifyTuGostReferences проверяет, что ГОСТы, на которые ссылаются номенклатуры проекта
+// (client_benchmarks.tu_gost_reference_id), есть в базе ГОСТов и действуют.
+// Номер с годом ищется в точности до редакции, номер без года сопоставляется с последней редакцией.
+// Ссылки на ТУ не проверяются и учитываются как пропущенные. Ничего не изменяет.
+func (db *ServiceDB) VerifyTuGostReferences(projectID int, gostsDB *GostsDB) (VerifyReport, error) {
+	report := VerifyReport{ProjectID: projectID, References: []TuGostReferenceCheck{}}
+	if gostsDB == nil {
+		return report, fmt.Errorf("gosts database is not available")
+	}
+
+	rows, err := db.conn.Query(`
+		SELECT r.id, r.code, r.name, COALESCE(r.document_type, ''), b.id
+		FROM client_benchmarks b
+		JOIN tu_gost_reference r ON r.id = b.tu_gost_reference_id
+		WHERE b.client_project_id = ?
+		ORDER BY r.code, b.id
+	`, projectID)
+	if err != nil {
+		return report, fmt.Errorf("failed to query TU/GOST references: %w", err)
+	}
+
+	byReference := make(map[int]int)
+	for rows.Next() {
+		var check TuGostReferenceCheck
+		var benchmarkID int
+		if err := rows.Scan(&check.ReferenceID, &check.Code, &check.Name, &check.DocumentType, &benchmarkID); err != nil {
+			rows.Close()
+			return report, fmt.Errorf("failed to scan TU/GOST reference: %w", err)
+		}
+		if idx, ok := byReference[check.ReferenceID]; ok {
+			report.References[idx].BenchmarkIDs = append(report.References[idx].BenchmarkIDs, benchmarkID)
+			continue
+		}
+		check.BenchmarkIDs = []int{benchmarkID}
+		byReference[check.ReferenceID] = len(report.References)
+		report.References = append(report.References, check)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return report, fmt.Errorf("failed to iterate TU/GOST references: %w", err)
+	}
+	rows.Close()
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for i := range report.References {
+		check := &report.References[i]
+		if err := verifyTuGostReference(check, gostsDB, today); err != nil {
+			return report, err
+		}
+
+		switch check.Result {
+		case TuGostReferenceValid:
+			report.Valid++
+		case TuGostReferenceMissing:
+			report.Missing++
+		case TuGostReferenceWithdrawn:
+			report.Withdrawn++
+		case TuGostReferenceSkipped:
+			report.Skipped++
+		}
+	}
+
+	report.Total = len(report.References)
+	report.CheckedAt = time.Now()
+	return report, nil
+}
+
+// verifyTuGostReference заполняет результат проверки одной ссылки
+func verifyTuGostReference(check *TuGostReferenceCheck, gostsDB *GostsDB, today time.Time) error {
+	if !strings.Contains(strings.ToUpper(check.Code), "ГОСТ") && check.DocumentType != "ГОСТ" {
+		check.Result = TuGostReferenceSkipped
+		check.Message = "технические условия не проверяются по базе ГОСТов"
+		return nil
+	}
+
+	editions, err := gostsDB.FindByNumberLoose(check.Code)
+	if err != nil {
+		return fmt.Errorf("failed to find gost %q: %w", check.Code, err)
+	}
+
+	// Номер с годом должен совпасть с редакцией, без года - последняя редакция (editions упорядочены от новой)
+	var match *Gost
+	_, year := splitGostNumber(check.Code)
+	for _, edition := range editions {
+		if _, editionYear := splitGostNumber(edition.GostNumber); year == 0 || editionYear == year {
+			match = edition
+			break
+		}
+	}
+	if match == nil {
+		check.Result = TuGostReferenceMissing
+		if len(editions) > 0 {
+			check.Message = fmt.Sprintf("редакция не найдена, в базе есть %s", editions[0].GostNumber)
+		} else {
+			check.Message = "ГОСТ не найден в базе ГОСТов"
+		}
+		return nil
+	}
+
+	// FindByNumberLoose не заполняет дату отмены
+	gost, err := gostsDB.GetGost(match.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get gost %d: %w", match.ID, err)
+	}
+
+	check.GostID = gost.ID
+	check.GostNumber = gost.GostNumber
+	check.GostStatus = gost.Status
+	check.WithdrawalDate = gost.WithdrawalDate
+
+	// Статус сравнивается в Go: lower() в SQLite не работает с кириллицей
+	status := strings.ToLower(strings.TrimSpace(gost.Status))
+	switch {
+	case status != "" && !gostActiveStatuses[status]:
+		check.Result = TuGostReferenceWithdrawn
+		check.Message = fmt.Sprintf("статус ГОСТа %q", gost.Status)
+	case gost.WithdrawalDate != nil && gostDay(*gost.WithdrawalDate).Before(today):
+		check.Result = TuGostReferenceWithdrawn
+		check.Message = fmt.Sprintf("стандарт отменен %s", gost.WithdrawalDate.Format("2006-01-02"))
+	default:
+		check.Result = TuGostReferenceValid
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerifyTuGostReferences(t *testing.T) {
+	serviceDB := newTestServiceDB(t)
+	gostsDB := setupTestGostsDB(t)
+
+	withdrawn := time.Now().AddDate(-1, 0, 0)
+	gosts := []*Gost{
+		{GostNumber: "ГОСТ 2590-2006", Title: "Прокат сортовой стальной горячекатаный круглый", Status: "действующий"},
+		{GostNumber: "ГОСТ 380-88", Title: "Сталь углеродистая обыкновенного качества", Status: "отменен", WithdrawalDate: &withdrawn},
+	}
+	for _, gost := range gosts {
+		if _, err := gostsDB.CreateOrUpdateGost(gost); err != nil {
+			t.Fatalf("CreateOrUpdateGost(%q) failed: %v", gost.GostNumber, err)
+		}
+	}
+
+	client, err := serviceDB.CreateClient("GOST Client", "GOST Client LLC", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "GOST Project", "nomenclature", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	for _, code := range []string{"ГОСТ 2590-2006", "ГОСТ 380-88", "ТУ 14-1-1234-90"} {
+		ref, err := serviceDB.FindOrCreateTUGOSTReference(code, code)
+		if err != nil {
+			t.Fatalf("FindOrCreateTUGOSTReference(%q) failed: %v", code, err)
+		}
+		if _, err := serviceDB.conn.Exec(`
+			INSERT INTO client_benchmarks (client_project_id, original_name, normalized_name, category, quality_score, tu_gost_reference_id)
+			VALUES (?, ?, ?, 'nomenclature', 0.9, ?)
+		`, project.ID, "Номенклатура "+code, "Номенклатура "+code, ref.ID); err != nil {
+			t.Fatalf("Failed to create benchmark: %v", err)
+		}
+	}
+
+	report, err := serviceDB.VerifyTuGostReferences(project.ID, gostsDB)
+	if err != nil {
+		t.Fatalf("VerifyTuGostReferences failed: %v", err)
+	}
+
+	if report.Total != 3 || report.Valid != 1 || report.Withdrawn != 1 || report.Skipped != 1 || report.Missing != 0 {
+		t.Fatalf("Unexpected report counts: %+v", report)
+	}
+
+	results := make(map[string]string)
+	for _, check := range report.References {
+		results[check.Code] = check.Result
+		if len(check.BenchmarkIDs) != 1 {
+			t.Errorf("Reference %s: expected 1 benchmark, got %v", check.Code, check.BenchmarkIDs)
+		}
+	}
+	if results["ГОСТ 2590-2006"] != TuGostReferenceValid {
+		t.Errorf("ГОСТ 2590-2006: result = %q, want %q", results["ГОСТ 2590-2006"], TuGostReferenceValid)
+	}
+	if results["ГОСТ 380-88"] != TuGostReferenceWithdrawn {
+		t.Errorf("ГОСТ 380-88: result = %q, want %q", results["ГОСТ 380-88"], TuGostReferenceWithdrawn)
+	}
+
+	// Редакция, которой нет в базе, считается отсутствующей
+	missing := TuGostReferenceCheck{Code: "ГОСТ 2590-88", DocumentType: "ГОСТ"}
+	if err := verifyTuGostReference(&missing, gostsDB, time.Now()); err != nil {
+		t.Fatalf("verifyTuGostReference failed: %v", err)
+	}
+	if missing.Result != TuGostReferenceMissing {
+		t.Errorf("ГОСТ 2590-88: result = %q, want %q", missing.Result, TuGostReferenceMissing)
+	}
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// OrphanInfo выгрузка, ссылающаяся на удаленные записи сервисной БД.
+// Флаги Missing* указывают, какие из ссылок database_id/client_id/project_id больше не существуют.
+type OrphanInfo struct {
+	UploadID        int    `json:"upload_id"`
+	UploadUUID      string `json:"upload_uuid"`
+	DatabaseID      *int   `json:"database_id,omitempty"`
+	ClientID        *int   `json:"client_id,omitempty"`
+	ProjectID       *int   `json:"project_id,omitempty"`
+	MissingDatabase bool   `json:"missing_database"`
+	MissingClient   bool   `json:"missing_client"`
+	MissingProject  bool   `json:"missing_project"`
+}
+
+// OrphanRepairResult результат исправления выгрузок с висячими ссылками
+type OrphanRepairResult struct {
+	Found      int          `json:"found"`
+	Repaired   int          `json:"repaired"`
+	Reassigned int          `json:"reassigned"` // client_id/project_id восстановлены по базе данных выгрузки
+	Orphans    []OrphanInfo `json:"orphans"`
+}
+
+// FindOrphanedUploads ищет в uploadsDB выгрузки, у которых database_id, client_id или project_id
+// ссылаются на несуществующие project_databases, clients или client_projects сервисной БД
+func (db *ServiceDB) FindOrphanedUploads(uploadsDB *DB) ([]OrphanInfo, error) {
+	if uploadsDB == nil {
+		return nil, fmt.Errorf("uploads database is nil")
+	}
+
+	databaseIDs, err := db.existingIDs("project_databases")
+	if err != nil {
+		return nil, err
+	}
+	clientIDs, err := db.existingIDs("clients")
+	if err != nil {
+		return nil, err
+	}
+	projectIDs, err := db.existingIDs("client_projects")
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := uploadsDB.conn.Query(`
+		SELECT id, upload_uuid, database_id, client_id, project_id
+		FROM uploads
+		WHERE database_id IS NOT NULL OR client_id IS NOT NULL OR project_id IS NOT NULL
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query uploads: %w", err)
+	}
+	defer rows.Close()
+
+	orphans := []OrphanInfo{}
+	for rows.Next() {
+		var info OrphanInfo
+		var databaseID, clientID, projectID sql.NullInt64
+		if err := rows.Scan(&info.UploadID, &info.UploadUUID, &databaseID, &clientID, &projectID); err != nil {
+			return nil, fmt.Errorf("failed to scan upload: %w", err)
+		}
+
+		info.DatabaseID = nullInt64Ptr(databaseID)
+		info.ClientID = nullInt64Ptr(clientID)
+		info.ProjectID = nullInt64Ptr(projectID)
+		info.MissingDatabase = info.DatabaseID != nil && !databaseIDs[*info.DatabaseID]
+		info.MissingClient = info.ClientID != nil && !clientIDs[*info.ClientID]
+		info.MissingProject = info.ProjectID != nil && !projectIDs[*info.ProjectID]
+
+		if info.MissingDatabase || info.MissingClient || info.MissingProject {
+			orphans = append(orphans, info)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate uploads: %w", err)
+	}
+
+	return orphans, nil
+}
+
+// RepairOrphanedUploads обнуляет висячие ссылки выгрузок, найденных FindOrphanedUploads.
+// Если reassign = true и база данных выгрузки существует, client_id и project_id берутся
+// из проекта этой базы данных; висячий database_id восстановить не из чего, он всегда обнуляется.
+func (db *ServiceDB) RepairOrphanedUploads(uploadsDB *DB, reassign bool) (*OrphanRepairResult, error) {
+	orphans, err := db.FindOrphanedUploads(uploadsDB)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &OrphanRepairResult{Found: len(orphans), Orphans: orphans}
+	if len(orphans) == 0 {
+		return result, nil
+	}
+
+	tx, err := uploadsDB.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, orphan := range orphans {
+		databaseID, clientID, projectID := orphan.DatabaseID, orphan.ClientID, orphan.ProjectID
+		if orphan.MissingDatabase {
+			databaseID = nil
+		}
+		if orphan.MissingClient {
+			clientID = nil
+		}
+		if orphan.MissingProject {
+			projectID = nil
+		}
+
+		reassigned := false
+		if reassign && databaseID != nil && (orphan.MissingClient || orphan.MissingProject) {
+			var ownerClientID, ownerProjectID int
+			err := db.conn.QueryRow(`
+				SELECT cp.client_id, cp.id
+				FROM project_databases pd
+				JOIN client_projects cp ON cp.id = pd.client_project_id
+				WHERE pd.id = ?
+			`, *databaseID).Scan(&ownerClientID, &ownerProjectID)
+			if err != nil && err != sql.ErrNoRows {
+				return nil, fmt.Errorf("failed to get owner of database %d: %w", *databaseID, err)
+			}
+			if err == nil {
+				clientID, projectID = &ownerClientID, &ownerProjectID
+				reassigned = true
+			}
+		}
+
+		if _, err := tx.Exec(`UPDATE uploads SET database_id = ?, client_id = ?, project_id = ? WHERE id = ?`,
+			databaseID, clientID, projectID, orphan.UploadID); err != nil {
+			return nil, fmt.Errorf("failed to repair upload %d: %w", orphan.UploadID, err)
+		}
+
+		result.Repaired++
+		if reassigned {
+			result.Reassigned++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// existingIDs возвращает множество id таблицы сервисной БД
+func (db *ServiceDB) existingIDs(table string) (map[int]bool, error) {
+	rows, err := db.conn.Query(fmt.Sprintf("SELECT id FROM %s", table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s ids: %w", table, err)
+	}
+	defer rows.Close()
+
+	ids := make(map[int]bool)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan %s id: %w", table, err)
+		}
+		ids[id] = true
+	}
+	return ids, rows.Err()
+}
+
+func nullInt64Ptr(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	id := int(value.Int64)
+	return &id
+}
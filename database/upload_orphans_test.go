@@ -0,0 +1,100 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestServiceDB_FindAndRepairOrphanedUploads(t *testing.T) {
+	serviceDB := newTestServiceDB(t)
+
+	uploadsDB, err := NewDB(filepath.Join(t.TempDir(), "uploads.db"))
+	if err != nil {
+		t.Fatalf("failed to create uploads DB: %v", err)
+	}
+	defer uploadsDB.Close()
+
+	client, err := serviceDB.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+	keptDB, err := serviceDB.CreateProjectDatabase(project.ID, "Основная", filepath.Join(t.TempDir(), "kept.db"), "", 0)
+	if err != nil {
+		t.Fatalf("CreateProjectDatabase failed: %v", err)
+	}
+	deletedDB, err := serviceDB.CreateProjectDatabase(project.ID, "Удаленная", filepath.Join(t.TempDir(), "deleted.db"), "", 0)
+	if err != nil {
+		t.Fatalf("CreateProjectDatabase failed: %v", err)
+	}
+
+	insertUpload := func(uuid string, databaseID, clientID, projectID int) int {
+		t.Helper()
+		upload, err := uploadsDB.CreateUploadWithDatabase(uuid, "8.3", "Бухгалтерия", &databaseID,
+			"", "", "", 1, "", "", "", nil)
+		if err != nil {
+			t.Fatalf("failed to create upload: %v", err)
+		}
+		if err := uploadsDB.UpdateUploadClientProject(upload.ID, clientID, projectID); err != nil {
+			t.Fatalf("failed to link upload: %v", err)
+		}
+		return upload.ID
+	}
+
+	insertUpload("valid", keptDB.ID, client.ID, project.ID)
+	danglingDatabase := insertUpload("dangling-database", deletedDB.ID, client.ID, project.ID)
+	danglingProject := insertUpload("dangling-project", keptDB.ID, client.ID+100, project.ID+100)
+
+	if err := serviceDB.DeleteProjectDatabase(deletedDB.ID); err != nil {
+		t.Fatalf("DeleteProjectDatabase failed: %v", err)
+	}
+
+	orphans, err := serviceDB.FindOrphanedUploads(uploadsDB)
+	if err != nil {
+		t.Fatalf("FindOrphanedUploads failed: %v", err)
+	}
+	if len(orphans) != 2 {
+		t.Fatalf("Expected 2 orphaned uploads, got %+v", orphans)
+	}
+	if orphans[0].UploadID != danglingDatabase || !orphans[0].MissingDatabase || orphans[0].MissingClient {
+		t.Errorf("Unexpected orphan for deleted database: %+v", orphans[0])
+	}
+	if orphans[1].UploadID != danglingProject || orphans[1].MissingDatabase || !orphans[1].MissingClient || !orphans[1].MissingProject {
+		t.Errorf("Unexpected orphan for deleted project: %+v", orphans[1])
+	}
+
+	result, err := serviceDB.RepairOrphanedUploads(uploadsDB, true)
+	if err != nil {
+		t.Fatalf("RepairOrphanedUploads failed: %v", err)
+	}
+	if result.Found != 2 || result.Repaired != 2 || result.Reassigned != 1 {
+		t.Errorf("Unexpected repair result: %+v", result)
+	}
+
+	upload, err := uploadsDB.GetUploadByID(danglingDatabase)
+	if err != nil {
+		t.Fatalf("GetUploadByID failed: %v", err)
+	}
+	if upload.DatabaseID != nil || upload.ClientID == nil || *upload.ClientID != client.ID {
+		t.Errorf("Dangling database_id should be nulled, valid client kept: %+v", upload)
+	}
+
+	upload, err = uploadsDB.GetUploadByID(danglingProject)
+	if err != nil {
+		t.Fatalf("GetUploadByID failed: %v", err)
+	}
+	if upload.ClientID == nil || *upload.ClientID != client.ID || upload.ProjectID == nil || *upload.ProjectID != project.ID {
+		t.Errorf("client_id/project_id should be reassigned from database owner: %+v", upload)
+	}
+
+	orphans, err = serviceDB.FindOrphanedUploads(uploadsDB)
+	if err != nil {
+		t.Fatalf("FindOrphanedUploads failed: %v", err)
+	}
+	if len(orphans) != 0 {
+		t.Errorf("Expected no orphans after repair, got %+v", orphans)
+	}
+}
@@ -0,0 +1,203 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+
+	"github.com/google/uuid"
+)
+
+// UploadRecordsRepairResult изменения, внесенные EnsureUploadRecords в выгрузки исходной базы
+type UploadRecordsRepairResult struct {
+	DatabaseID int   `json:"database_id"`
+	ClientID   int   `json:"client_id"`
+	ProjectID  int   `json:"project_id"`
+	Created    []int `json:"created"` // ID созданных выгрузок
+	Updated    []int `json:"updated"` // ID выгрузок, которым назначены client_id/project_id
+	Failed     []int `json:"failed"`  // ID выгрузок, которые не удалось обновить
+}
+
+// RepairStats количество выгрузок, измененных EnsureUploadRecordsForDatabase
+type RepairStats struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Failed  int `json:"failed"`
+}
+
+// EnsureUploadRecords создает или обновляет upload записи в исходной базе данных dbPath,
+// чтобы данные базы находились через таблицу uploads по client_id и project_id.
+// Если ни одна выгрузка не привязана к проекту, выгрузкам назначаются clientID и projectID;
+// если выгрузок нет или ни одна не привязана ни к какому проекту, создается новая.
+// Выгрузки и данные никогда не удаляются.
+func EnsureUploadRecords(dbPath string, clientID, projectID, databaseID int) (*UploadRecordsRepairResult, error) {
+	// Открываем исходную базу данных
+	sourceDB, err := NewDB(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source database %s: %w", dbPath, err)
+	}
+	defer sourceDB.Close()
+
+	return repairUploadRecords(sourceDB, dbPath, clientID, projectID, databaseID)
+}
+
+// EnsureUploadRecordsForDatabase выполняет то же, что EnsureUploadRecords, для уже открытой
+// исходной базы и возвращает количество созданных и обновленных выгрузок
+func EnsureUploadRecordsForDatabase(sourceDB *DB, clientID, projectID, databaseID int) (RepairStats, error) {
+	result, err := repairUploadRecords(sourceDB, sourceDB.filePath(), clientID, projectID, databaseID)
+	if err != nil {
+		return RepairStats{}, err
+	}
+	return RepairStats{
+		Created: len(result.Created),
+		Updated: len(result.Updated),
+		Failed:  len(result.Failed),
+	}, nil
+}
+
+// repairUploadRecords общая реализация EnsureUploadRecords; dbPath используется для сообщений
+// и определения имени конфигурации по имени файла
+func repairUploadRecords(sourceDB *DB, dbPath string, clientID, projectID, databaseID int) (*UploadRecordsRepairResult, error) {
+	result := &UploadRecordsRepairResult{
+		DatabaseID: databaseID,
+		ClientID:   clientID,
+		ProjectID:  projectID,
+		Created:    []int{},
+		Updated:    []int{},
+		Failed:     []int{},
+	}
+
+	// Получаем все существующие upload записи
+	uploads, err := sourceDB.GetAllUploads()
+	if err != nil {
+		// Если таблица uploads не существует, это нормально - база может быть пустой
+		log.Printf("Note: Could not get uploads from %s (table may not exist): %v", dbPath, err)
+		uploads = []*Upload{}
+	}
+
+	// Проверяем, есть ли upload записи с правильными client_id и project_id
+	needsUpdate := false
+	needsCreate := false
+
+	if len(uploads) == 0 {
+		needsCreate = true
+	} else {
+		// Проверяем, есть ли хотя бы одна запись с правильными client_id и project_id
+		hasCorrectUpload := false
+		for _, upload := range uploads {
+			if upload.ClientID != nil && *upload.ClientID == clientID &&
+				upload.ProjectID != nil && *upload.ProjectID == projectID {
+				hasCorrectUpload = true
+				break
+			}
+		}
+
+		if !hasCorrectUpload {
+			needsUpdate = true
+			// Если все upload записи не имеют правильных client_id/project_id, создаем новую
+			allMissingIDs := true
+			for _, upload := range uploads {
+				if upload.ClientID != nil || upload.ProjectID != nil {
+					allMissingIDs = false
+					break
+				}
+			}
+			if allMissingIDs {
+				needsCreate = true
+			}
+		}
+	}
+
+	// Обновляем существующие upload записи
+	if needsUpdate {
+		for _, upload := range uploads {
+			// Обновляем только если client_id или project_id отсутствуют или неверны
+			shouldUpdate := false
+			if upload.ClientID == nil || *upload.ClientID != clientID {
+				shouldUpdate = true
+			}
+			if upload.ProjectID == nil || *upload.ProjectID != projectID {
+				shouldUpdate = true
+			}
+
+			if shouldUpdate {
+				err := sourceDB.UpdateUploadClientProject(upload.ID, clientID, projectID)
+				if err != nil {
+					log.Printf("Warning: Failed to update upload %d in %s: %v", upload.ID, dbPath, err)
+					result.Failed = append(result.Failed, upload.ID)
+				} else {
+					log.Printf("Updated upload %d in %s with client_id=%d, project_id=%d", upload.ID, dbPath, clientID, projectID)
+					result.Updated = append(result.Updated, upload.ID)
+				}
+			}
+		}
+	}
+
+	// Создаем новую upload запись, если нужно
+	if needsCreate {
+		uploadUUID := uuid.New().String()
+		dbID := databaseID
+
+		// Пытаемся определить версию 1С и имя конфигурации из метаданных или имени файла
+		version1C := "8.3"
+		configName := "Unknown"
+
+		// Парсим имя файла для получения информации
+		fileName := filepath.Base(dbPath)
+		fileInfo := ParseDatabaseFileInfo(fileName)
+		if fileInfo.ConfigName != "" && fileInfo.ConfigName != "Unknown" {
+			configName = fileInfo.ConfigName
+		}
+
+		upload, err := sourceDB.CreateUploadWithDatabase(
+			uploadUUID,
+			version1C,
+			configName,
+			&dbID,
+			"",  // computerName
+			"",  // userName
+			"",  // configVersion
+			1,   // iterationNumber
+			"",  // iterationLabel
+			"",  // programmerName
+			"",  // uploadPurpose
+			nil, // parentUploadID
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create upload in %s: %w", dbPath, err)
+		}
+		result.Created = append(result.Created, upload.ID)
+
+		// Обновляем client_id и project_id
+		err = sourceDB.UpdateUploadClientProject(upload.ID, clientID, projectID)
+		if err != nil {
+			log.Printf("Warning: Failed to update new upload %d with client_id/project_id: %v", upload.ID, err)
+			result.Failed = append(result.Failed, upload.ID)
+		} else {
+			log.Printf("Created and updated upload %d in %s with client_id=%d, project_id=%d", upload.ID, dbPath, clientID, projectID)
+		}
+	}
+
+	return result, nil
+}
+
+// filePath возвращает путь к файлу основной базы SQLite; пустая строка - база в памяти
+func (db *DB) filePath() string {
+	rows, err := db.conn.Query(`PRAGMA database_list`)
+	if err != nil {
+		return ""
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var seq int
+		var name, file string
+		if err := rows.Scan(&seq, &name, &file); err != nil {
+			return ""
+		}
+		if name == "main" {
+			return file
+		}
+	}
+	return ""
+}
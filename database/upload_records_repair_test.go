@@ -0,0 +1,133 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newUploadRecordsTestDB(t *testing.T, fileName string) *DB {
+	t.Helper()
+	db, err := NewDB(filepath.Join(t.TempDir(), fileName))
+	if err != nil {
+		t.Fatalf("failed to create source DB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func createTestUpload(t *testing.T, db *DB, uuid string, databaseID int) *Upload {
+	t.Helper()
+	upload, err := db.CreateUploadWithDatabase(uuid, "8.3", "Бухгалтерия", &databaseID,
+		"", "", "", 1, "", "", "", nil)
+	if err != nil {
+		t.Fatalf("failed to create upload: %v", err)
+	}
+	return upload
+}
+
+func TestEnsureUploadRecordsForDatabase_CreatesUploadForEmptyDatabase(t *testing.T) {
+	db := newUploadRecordsTestDB(t, "Выгрузка_Номенклатура_ERPWE_2024.db")
+
+	stats, err := EnsureUploadRecordsForDatabase(db, 1, 2, 3)
+	if err != nil {
+		t.Fatalf("EnsureUploadRecordsForDatabase failed: %v", err)
+	}
+	if stats != (RepairStats{Created: 1}) {
+		t.Fatalf("Expected one created upload, got %+v", stats)
+	}
+
+	uploads, err := db.GetAllUploads()
+	if err != nil {
+		t.Fatalf("GetAllUploads failed: %v", err)
+	}
+	if len(uploads) != 1 {
+		t.Fatalf("Expected 1 upload, got %d", len(uploads))
+	}
+	upload := uploads[0]
+	if upload.ClientID == nil || *upload.ClientID != 1 || upload.ProjectID == nil || *upload.ProjectID != 2 {
+		t.Errorf("Upload is not linked to client 1 / project 2: %+v", upload)
+	}
+	if upload.DatabaseID == nil || *upload.DatabaseID != 3 {
+		t.Errorf("Expected database_id 3, got %v", upload.DatabaseID)
+	}
+	if upload.ConfigName != "ERPWE" {
+		t.Errorf("Expected config name from file name, got %q", upload.ConfigName)
+	}
+}
+
+func TestEnsureUploadRecordsForDatabase_UpdatesUploadOfAnotherProject(t *testing.T) {
+	db := newUploadRecordsTestDB(t, "source.db")
+	upload := createTestUpload(t, db, "other-project", 3)
+	if err := db.UpdateUploadClientProject(upload.ID, 10, 20); err != nil {
+		t.Fatalf("UpdateUploadClientProject failed: %v", err)
+	}
+
+	stats, err := EnsureUploadRecordsForDatabase(db, 1, 2, 3)
+	if err != nil {
+		t.Fatalf("EnsureUploadRecordsForDatabase failed: %v", err)
+	}
+	if stats != (RepairStats{Updated: 1}) {
+		t.Fatalf("Expected one updated upload, got %+v", stats)
+	}
+
+	uploads, err := db.GetAllUploads()
+	if err != nil {
+		t.Fatalf("GetAllUploads failed: %v", err)
+	}
+	if len(uploads) != 1 {
+		t.Fatalf("Expected no new uploads, got %d", len(uploads))
+	}
+	if *uploads[0].ClientID != 1 || *uploads[0].ProjectID != 2 {
+		t.Errorf("Upload was not relinked: client %d, project %d", *uploads[0].ClientID, *uploads[0].ProjectID)
+	}
+}
+
+func TestEnsureUploadRecordsForDatabase_UnlinkedUploadsGetNewUpload(t *testing.T) {
+	db := newUploadRecordsTestDB(t, "source.db")
+	createTestUpload(t, db, "unlinked", 3)
+
+	stats, err := EnsureUploadRecordsForDatabase(db, 1, 2, 3)
+	if err != nil {
+		t.Fatalf("EnsureUploadRecordsForDatabase failed: %v", err)
+	}
+	// Выгрузки без client_id/project_id обновляются, и дополнительно создается новая
+	if stats != (RepairStats{Created: 1, Updated: 1}) {
+		t.Fatalf("Expected one created and one updated upload, got %+v", stats)
+	}
+
+	uploads, err := db.GetAllUploads()
+	if err != nil {
+		t.Fatalf("GetAllUploads failed: %v", err)
+	}
+	if len(uploads) != 2 {
+		t.Fatalf("Expected 2 uploads, got %d", len(uploads))
+	}
+}
+
+func TestEnsureUploadRecordsForDatabase_AlreadyLinked(t *testing.T) {
+	db := newUploadRecordsTestDB(t, "source.db")
+	linked := createTestUpload(t, db, "linked", 3)
+	if err := db.UpdateUploadClientProject(linked.ID, 1, 2); err != nil {
+		t.Fatalf("UpdateUploadClientProject failed: %v", err)
+	}
+	other := createTestUpload(t, db, "other-project", 3)
+	if err := db.UpdateUploadClientProject(other.ID, 10, 20); err != nil {
+		t.Fatalf("UpdateUploadClientProject failed: %v", err)
+	}
+
+	stats, err := EnsureUploadRecordsForDatabase(db, 1, 2, 3)
+	if err != nil {
+		t.Fatalf("EnsureUploadRecordsForDatabase failed: %v", err)
+	}
+	if stats != (RepairStats{}) {
+		t.Fatalf("Expected no changes, got %+v", stats)
+	}
+
+	otherUpload, err := db.GetUploadByID(other.ID)
+	if err != nil {
+		t.Fatalf("GetUploadByID failed: %v", err)
+	}
+	if *otherUpload.ProjectID != 20 {
+		t.Errorf("Upload of another project must stay untouched, got project %d", *otherUpload.ProjectID)
+	}
+}
@@ -23,9 +23,7 @@ func NewAdataEnricher(config *EnricherConfig) *AdataEnricher {
 
 	return &AdataEnricher{
 		config: config,
-		client: &http.Client{
-			Timeout: config.Timeout,
-		},
+		client: newEnricherHTTPClient(config.Timeout),
 	}
 }
 
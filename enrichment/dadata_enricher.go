@@ -29,9 +29,7 @@ func NewDadataEnricher(config *EnricherConfig) *DadataEnricher {
 
 	return &DadataEnricher{
 		config: config,
-		client: &http.Client{
-			Timeout: config.Timeout,
-		},
+		client: newEnricherHTTPClient(config.Timeout),
 		rateLimit: time.Minute / time.Duration(config.MaxRequests),
 	}
 }
@@ -2,9 +2,12 @@ package enrichment
 
 import (
 	"encoding/json"
+	"net/http"
 	"regexp"
 	"strings"
 	"time"
+
+	"httpserver/internal/httpclient"
 )
 
 // EnrichmentResult содержит результаты обогащения данных контрагента
@@ -91,6 +94,18 @@ type EnricherConfig struct {
 	Priority        int           `json:"priority"`
 }
 
+// enricherRetryCount число повторов запросов к внешним API обогащения при временных сбоях
+const enricherRetryCount = 2
+
+// newEnricherHTTPClient создает HTTP-клиент обогатителя с повторами при временных сбоях API
+func newEnricherHTTPClient(timeout time.Duration) *http.Client {
+	return httpclient.New(httpclient.Options{
+		Timeout:     timeout,
+		RetryCount:  enricherRetryCount,
+		RetryJitter: httpclient.DefaultRetryJitter,
+	})
+}
+
 // CacheConfig конфигурация кэша
 type CacheConfig struct {
 	Enabled         bool          `json:"enabled"`
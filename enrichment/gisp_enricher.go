@@ -23,9 +23,7 @@ func NewGispEnricher(config *EnricherConfig) *GispEnricher {
 
 	return &GispEnricher{
 		config: config,
-		client: &http.Client{
-			Timeout: config.Timeout,
-		},
+		client: newEnricherHTTPClient(config.Timeout),
 	}
 }
 
@@ -3,6 +3,7 @@ package extractors
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 )
 
@@ -51,7 +52,12 @@ func ExtractINNFromAttributes(attributesXML string) (string, error) {
 	// Если встретили длинную последовательность цифр, попробуем взять первые 10
 	re = regexp.MustCompile(`\d{10,}`)
 	longMatches := re.FindAllString(attributesXML, -1)
+	ogrnValues := ogrnCandidates(attributesXML)
 	for _, block := range longMatches {
+		// Значение с меткой ОГРН (в том числе ошибочной длины) не является ИНН
+		if slices.Contains(ogrnValues, block) {
+			continue
+		}
 		// Если рядом нет прямого упоминания БИН, считаем это ИНН
 		if len(block) >= 10 && !(strings.Contains(lowerXML, "бин") || strings.Contains(lowerXML, "bin")) {
 			return block[:10], nil
@@ -134,6 +140,60 @@ func ExtractBINFromAttributes(attributesXML string) (string, error) {
 	return "", fmt.Errorf("БИН not found in attributes")
 }
 
+// ExtractOGRNFromAttributes извлекает ОГРН (13 цифр) или ОГРНИП (15 цифр) из XML атрибутов.
+// Значения другой длины считаются ошибочными и не возвращаются.
+func ExtractOGRNFromAttributes(attributesXML string) (string, error) {
+	if attributesXML == "" {
+		return "", fmt.Errorf("empty attributes XML")
+	}
+
+	for _, candidate := range ogrnCandidates(attributesXML) {
+		if ogrn := NormalizeOGRN(candidate); ogrn != "" {
+			return ogrn, nil
+		}
+	}
+
+	return "", fmt.Errorf("ОГРН not found in attributes")
+}
+
+// ogrnCandidates возвращает значения с меткой ОГРН/ОГРНИП без проверки длины:
+// из текста вида "ОГРН: 1027700132195" и из XML полей
+func ogrnCandidates(attributesXML string) []string {
+	var candidates []string
+
+	re := regexp.MustCompile(`(?i)(?:огрнип|огрн|ogrnip|ogrn)[\s:]*(\d+)`)
+	for _, matches := range re.FindAllStringSubmatch(attributesXML, -1) {
+		candidates = append(candidates, matches[1])
+	}
+
+	possibleFields := []string{"ОГРН", "ОГРНИП", "ОГРНКонтрагента", "ОГРНЮридическогоЛица", "ogrn", "OGRN", "OGRNIP"}
+	if strings.Contains(attributesXML, "<") || strings.Contains(attributesXML, ">") {
+		for _, field := range possibleFields {
+			re := regexp.MustCompile(fmt.Sprintf(`(?i)<%s[^>]*>([^<]+)</%s>`, field, field))
+			if matches := re.FindStringSubmatch(attributesXML); len(matches) > 1 {
+				candidates = append(candidates, strings.TrimSpace(matches[1]))
+			}
+		}
+	}
+
+	return candidates
+}
+
+// NormalizeOGRN удаляет пробелы из ОГРН и проверяет длину: 13 цифр для ОГРН юридического лица,
+// 15 для ОГРНИП. Для значений другой длины или с посторонними символами возвращает пустую строку.
+func NormalizeOGRN(value string) string {
+	ogrn := strings.Join(strings.Fields(value), "")
+	if len(ogrn) != 13 && len(ogrn) != 15 {
+		return ""
+	}
+	for _, r := range ogrn {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return ogrn
+}
+
 // ExtractAddressFromAttributes извлекает адрес из XML атрибутов
 func ExtractAddressFromAttributes(attributesXML string) (string, error) {
 	if attributesXML == "" {
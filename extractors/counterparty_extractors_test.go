@@ -552,3 +552,68 @@ func TestExtractBIKFromAttributes(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractOGRNFromAttributes(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes string
+		wantOGRN   string
+		wantErr    bool
+	}{
+		{
+			name:       "ОГРН в XML",
+			attributes: "<ОГРН>1027700132195</ОГРН>",
+			wantOGRN:   "1027700132195",
+		},
+		{
+			name:       "ОГРНИП в тексте",
+			attributes: "ОГРНИП: 304500116000157",
+			wantOGRN:   "304500116000157",
+		},
+		{
+			name:       "ОГРН с пробелами в XML",
+			attributes: "<OGRN> 1027700132195 </OGRN>",
+			wantOGRN:   "1027700132195",
+		},
+		{
+			name:       "неправильная длина ОГРН",
+			attributes: "<ОГРН>102770013219</ОГРН>",
+			wantErr:    true,
+		},
+		{
+			name:       "число без метки ОГРН",
+			attributes: "1027700132195",
+			wantErr:    true,
+		},
+		{
+			name:       "пустой XML",
+			attributes: "",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractOGRNFromAttributes(tt.attributes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ExtractOGRNFromAttributes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.wantOGRN {
+				t.Errorf("ExtractOGRNFromAttributes() = %v, want %v", got, tt.wantOGRN)
+			}
+		})
+	}
+}
+
+// TestExtractINNFromAttributes_IgnoresOGRN проверяет, что первые цифры ОГРН не принимаются за ИНН
+func TestExtractINNFromAttributes_IgnoresOGRN(t *testing.T) {
+	if inn, err := ExtractINNFromAttributes("<ОГРН>1027700132195</ОГРН>"); err == nil {
+		t.Errorf("ExtractINNFromAttributes() = %v, want error for OGRN-only attributes", inn)
+	}
+
+	inn, err := ExtractINNFromAttributes("<ИНН>7707083893</ИНН><ОГРН>1027700132195</ОГРН>")
+	if err != nil || inn != "7707083893" {
+		t.Errorf("ExtractINNFromAttributes() = %v, %v, want 7707083893", inn, err)
+	}
+}
@@ -145,14 +145,16 @@ func (w *Window) updateStats(stats types.ServerStats) {
 • Активных выгрузок: %v
 • Всего констант: %v
 • Всего справочников: %v
-• Всего элементов: %v`,
+• Всего элементов: %v
+• Потеряно строк лога: %v`,
 		map[bool]string{true: "Работает", false: "Остановлен"}[stats.IsRunning],
 		stats.LastActivity.Format("15:04:05"),
 		stats.TotalStats["total_uploads"],
 		stats.TotalStats["active_uploads"],
 		stats.TotalStats["total_constants"],
 		stats.TotalStats["total_catalogs"],
-		stats.TotalStats["total_items"])
+		stats.TotalStats["total_items"],
+		stats.TotalStats["dropped_log_entries"])
 	
 	if stats.CurrentUpload != nil {
 		statsText += fmt.Sprintf(`
@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxDecompressedCSVSize ограничение размера распакованного CSV (защита от архивов-бомб)
+const maxDecompressedCSVSize = 512 << 20
+
+// CSVPayload содержимое одного CSV файла из скачанных данных
+type CSVPayload struct {
+	Name string // Имя файла (для архивов - имя записи внутри архива)
+	Data []byte
+}
+
+// ExtractCSVPayloads распаковывает скачанные данные, если они сжаты: gzip определяется по
+// Content-Encoding, расширению .gz или сигнатуре, zip - по расширению .zip или сигнатуре PK\x03\x04.
+// Из zip-архива возвращаются все CSV файлы в порядке записей архива. Несжатые данные
+// возвращаются как есть одним элементом.
+func ExtractCSVPayloads(data []byte, name, contentEncoding string) ([]CSVPayload, error) {
+	lowerName := strings.ToLower(name)
+
+	if strings.Contains(strings.ToLower(contentEncoding), "gzip") || strings.HasSuffix(lowerName, ".gz") ||
+		bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip data: %w", err)
+		}
+		defer reader.Close()
+		if data, err = readLimited(reader); err != nil {
+			return nil, fmt.Errorf("failed to decompress gzip data: %w", err)
+		}
+		if strings.HasSuffix(lowerName, ".gz") {
+			name = name[:len(name)-len(".gz")]
+			lowerName = strings.ToLower(name)
+		}
+	}
+
+	if strings.HasSuffix(lowerName, ".zip") || bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return extractZipCSV(data)
+	}
+
+	return []CSVPayload{{Name: name, Data: data}}, nil
+}
+
+// extractZipCSV возвращает все CSV файлы zip-архива
+func extractZipCSV(data []byte) ([]CSVPayload, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip archive: %w", err)
+	}
+
+	var payloads []CSVPayload
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !strings.EqualFold(path.Ext(file.Name), ".csv") {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s in zip archive: %w", file.Name, err)
+		}
+		content, err := readLimited(reader)
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from zip archive: %w", file.Name, err)
+		}
+		payloads = append(payloads, CSVPayload{Name: file.Name, Data: content})
+	}
+
+	if len(payloads) == 0 {
+		return nil, fmt.Errorf("zip archive contains no CSV files")
+	}
+	return payloads, nil
+}
+
+// readLimited читает данные, не более maxDecompressedCSVSize байт
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDecompressedCSVSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDecompressedCSVSize {
+		return nil, fmt.Errorf("decompressed data exceeds %d bytes", maxDecompressedCSVSize)
+	}
+	return data, nil
+}
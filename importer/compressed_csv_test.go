@@ -0,0 +1,89 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"testing"
+)
+
+const testGostCSV = "номер;название;дата принятия;статус\nГОСТ 12345-2020;Тестовый стандарт;2020-01-01;действующий\n"
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatalf("Failed to gzip data: %v", err)
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+func zipBytes(t *testing.T, files map[string]string, order ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, name := range order {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to create zip entry: %v", err)
+		}
+		entry.Write([]byte(files[name]))
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+func TestExtractCSVPayloads(t *testing.T) {
+	plain := []byte(testGostCSV)
+	gzipped := gzipBytes(t, plain)
+	archive := zipBytes(t, map[string]string{
+		"national.csv":        testGostCSV,
+		"readme.txt":          "not a csv",
+		"data/interstate.CSV": testGostCSV,
+	}, "national.csv", "readme.txt", "data/interstate.CSV")
+
+	tests := []struct {
+		name            string
+		data            []byte
+		fileName        string
+		contentEncoding string
+		wantNames       []string
+	}{
+		{name: "plain csv", data: plain, fileName: "gosts.csv", wantNames: []string{"gosts.csv"}},
+		{name: "gz extension", data: gzipped, fileName: "gosts.csv.gz", wantNames: []string{"gosts.csv"}},
+		{name: "content encoding", data: gzipped, fileName: "download", contentEncoding: "gzip", wantNames: []string{"download"}},
+		{name: "gzip magic bytes", data: gzipped, fileName: "data-20240101", wantNames: []string{"data-20240101"}},
+		{name: "zip extension", data: archive, fileName: "gosts.zip", wantNames: []string{"national.csv", "data/interstate.CSV"}},
+		{name: "zip magic bytes", data: archive, fileName: "opendata", wantNames: []string{"national.csv", "data/interstate.CSV"}},
+		{name: "gzipped zip", data: gzipBytes(t, archive), fileName: "gosts.zip.gz", wantNames: []string{"national.csv", "data/interstate.CSV"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payloads, err := ExtractCSVPayloads(tt.data, tt.fileName, tt.contentEncoding)
+			if err != nil {
+				t.Fatalf("ExtractCSVPayloads failed: %v", err)
+			}
+			if len(payloads) != len(tt.wantNames) {
+				t.Fatalf("Expected %d payloads, got %d", len(tt.wantNames), len(payloads))
+			}
+			for i, payload := range payloads {
+				if payload.Name != tt.wantNames[i] {
+					t.Errorf("Payload %d: expected name %q, got %q", i, tt.wantNames[i], payload.Name)
+				}
+				if !bytes.Equal(payload.Data, plain) {
+					t.Errorf("Payload %d: unexpected content %q", i, payload.Data)
+				}
+			}
+		})
+	}
+
+	if _, err := ExtractCSVPayloads(zipBytes(t, map[string]string{"readme.txt": "x"}, "readme.txt"), "gosts.zip", ""); err == nil {
+		t.Error("Expected error for zip archive without CSV files")
+	}
+	if _, err := ExtractCSVPayloads(plain, "gosts.csv.gz", ""); err == nil {
+		t.Error("Expected error for invalid gzip data")
+	}
+}
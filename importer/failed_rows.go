@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FailedNomenclatureRow строка, которую не удалось импортировать: исходная запись и текст ошибки
+type FailedNomenclatureRow struct {
+	Row    int                `json:"row"` // Номер строки в исходном файле (с 1)
+	Record NomenclatureRecord `json:"record"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// WriteFailedRowsFile записывает строки с ошибками в файл в формате JSON Lines (одна строка - одна запись).
+// Пустой список создает пустой файл.
+func WriteFailedRowsFile(path string, rows []FailedNomenclatureRow) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create failed rows file: %w", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return fmt.Errorf("failed to write row %d to failed rows file: %w", row.Row, err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write failed rows file: %w", err)
+	}
+
+	return file.Close()
+}
+
+// ReadFailedRowsFile читает строки с ошибками, записанные WriteFailedRowsFile
+func ReadFailedRowsFile(path string) ([]FailedNomenclatureRow, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open failed rows file: %w", err)
+	}
+	defer file.Close()
+
+	rows := make([]FailedNomenclatureRow, 0)
+	scanner := bufio.NewScanner(file)
+	// Записи реестра могут содержать длинные тексты заключений
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var row FailedNomenclatureRow
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			return nil, fmt.Errorf("failed to parse line %d of failed rows file: %w", lineNum, err)
+		}
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read failed rows file: %w", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,157 @@
+package importer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"httpserver/database"
+)
+
+// UnknownGostSourceType тип источника для файлов, имя которых не соответствует ни одному источнику
+const UnknownGostSourceType = "unknown"
+
+// GostImportLogger логгер для сообщений о ходе импорта ГОСТов
+type GostImportLogger interface {
+	Printf(format string, v ...interface{})
+}
+
+// GostFileImportResult результат импорта одного CSV файла из каталога
+type GostFileImportResult struct {
+	File       string   `json:"file"`
+	SourceType string   `json:"source_type"`
+	Total      int      `json:"total"`
+	Success    int      `json:"success"`
+	Inserted   int      `json:"inserted"`
+	Updated    int      `json:"updated"`
+	Unchanged  int      `json:"unchanged"`        // Записи, совпавшие с сохраненными (см. database.GostsDB.UpsertGost)
+	Errors     []string `json:"errors,omitempty"` // Ошибки отдельных записей
+	Error      string   `json:"error,omitempty"`  // Ошибка чтения или разбора файла целиком
+}
+
+// GostDirectoryImportResult сводный результат импорта каталога с CSV файлами ГОСТов
+type GostDirectoryImportResult struct {
+	Directory  string                 `json:"directory"`
+	Files      []GostFileImportResult `json:"files"`
+	Skipped    []string               `json:"skipped,omitempty"` // Файлы, не являющиеся CSV
+	Total      int                    `json:"total"`
+	Success    int                    `json:"success"`
+	Inserted   int                    `json:"inserted"`
+	Updated    int                    `json:"updated"`
+	Unchanged  int                    `json:"unchanged"`
+	ErrorCount int                    `json:"errors"`
+}
+
+// InferSourceType определяет тип источника ГОСТов по имени файла, например
+// "7706406291-nationalstandards.csv" или "data-20240101-interstatestandards.csv".
+// Выбирается самое длинное имя из database.DefaultGostImportSources, входящее в имя файла;
+// если совпадений нет, возвращается UnknownGostSourceType.
+func InferSourceType(filename string) string {
+	base := strings.ToLower(filepath.Base(filename))
+	base = strings.TrimSuffix(base, filepath.Ext(base))
+
+	sourceType := ""
+	for _, source := range database.DefaultGostImportSources {
+		if len(source.Name) > len(sourceType) && strings.Contains(base, source.Name) {
+			sourceType = source.Name
+		}
+	}
+	if sourceType == "" {
+		return UnknownGostSourceType
+	}
+
+	return sourceType
+}
+
+// gostSourceURL возвращает URL источника по умолчанию для типа источника (пустая строка, если не найден)
+func gostSourceURL(sourceType string) string {
+	for _, source := range database.DefaultGostImportSources {
+		if source.Name == sourceType {
+			return source.URL
+		}
+	}
+	return ""
+}
+
+// ImportGostDirectory импортирует все CSV файлы из каталога (включая вложенные).
+// Тип источника каждого файла определяется InferSourceType, остальные файлы пропускаются.
+// Ошибка разбора одного файла не прерывает импорт - она сохраняется в результате файла.
+// ГОСТы, встречающиеся в нескольких файлах, сливаются по priority (см. database.GostsDB.MergeGost),
+// поэтому результат не зависит от порядка файлов в каталоге.
+func ImportGostDirectory(gostsDB *database.GostsDB, dir string, priority []string, logger GostImportLogger) (*GostDirectoryImportResult, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	result := &GostDirectoryImportResult{
+		Directory: dir,
+		Files:     []GostFileImportResult{},
+	}
+
+	err = filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if !strings.EqualFold(filepath.Ext(path), ".csv") {
+			result.Skipped = append(result.Skipped, path)
+			return nil
+		}
+
+		fileResult := ImportGostFile(gostsDB, path, InferSourceType(path), priority, logger)
+		result.Files = append(result.Files, fileResult)
+		result.Total += fileResult.Total
+		result.Success += fileResult.Success
+		result.Inserted += fileResult.Inserted
+		result.Updated += fileResult.Updated
+		result.Unchanged += fileResult.Unchanged
+		result.ErrorCount += len(fileResult.Errors)
+		if fileResult.Error != "" {
+			result.ErrorCount++
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	return result, nil
+}
+
+// ImportGostFile импортирует один CSV файл с ГОСТами источника sourceType.
+// ГОСТы сливаются с уже сохраненными по priority (см. database.GostsDB.MergeGost).
+func ImportGostFile(gostsDB *database.GostsDB, path, sourceType string, priority []string, logger GostImportLogger) GostFileImportResult {
+	fileResult := GostFileImportResult{File: path, SourceType: sourceType}
+
+	records, err := ParseGostCSV(path)
+	if err != nil {
+		fileResult.Error = err.Error()
+		logger.Printf("Failed to parse %s: %v", path, err)
+		return fileResult
+	}
+	imported, err := ImportGostRecords(gostsDB, records, sourceType, gostSourceURL(sourceType), priority)
+	if err != nil {
+		fileResult.Error = err.Error()
+		return fileResult
+	}
+	fileResult.Total = imported.Total
+	fileResult.Success = imported.Success
+	fileResult.Inserted = imported.Inserted
+	fileResult.Updated = imported.Updated
+	fileResult.Unchanged = imported.Unchanged
+	if len(imported.Errors) > 0 {
+		fileResult.Errors = imported.Errors
+	}
+
+	logger.Printf("Imported %d/%d GOSTs from %s (source: %s, inserted: %d, updated: %d, unchanged: %d)",
+		fileResult.Success, fileResult.Total, path, sourceType, fileResult.Inserted, fileResult.Updated, fileResult.Unchanged)
+	return fileResult
+}
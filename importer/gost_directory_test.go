@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"httpserver/database"
+)
+
+func TestInferSourceType(t *testing.T) {
+	tests := map[string]string{
+		"7706406291-nationalstandards.csv":       "nationalstandards",
+		"/tmp/data/INTERSTATESTANDARDS-2024.csv": "interstatestandards",
+		"7706406291-publiccouncilplan.csv":       "publiccouncilplan",
+		"data-20240101-structure-techcommit.csv": "techcommit",
+		"export.csv":                             UnknownGostSourceType,
+	}
+
+	for filename, want := range tests {
+		if got := InferSourceType(filename); got != want {
+			t.Errorf("InferSourceType(%q) = %q, want %q", filename, got, want)
+		}
+	}
+}
+
+func TestImportGostDirectory(t *testing.T) {
+	dir := t.TempDir()
+	fixtures := map[string]string{
+		"7706406291-nationalstandards.csv": "номер;название;дата принятия;статус\n" +
+			"ГОСТ Р 12345-2020;Национальный стандарт;2020-01-01;действующий\n" +
+			"ГОСТ Р 67890-2021;Еще один национальный стандарт;2021-01-01;действующий\n",
+		filepath.Join("archive", "7706406291-interstatestandards.csv"): "номер;название;дата принятия;статус\n" +
+			"ГОСТ 11111-2019;Межгосударственный стандарт;2019-01-01;действующий\n",
+		"readme.txt": "not a csv",
+	}
+	for name, content := range fixtures {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create fixture directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write fixture %s: %v", name, err)
+		}
+	}
+
+	gostsDB, err := database.NewGostsDB(filepath.Join(t.TempDir(), "gosts.db"))
+	if err != nil {
+		t.Fatalf("Failed to open gosts DB: %v", err)
+	}
+	defer gostsDB.Close()
+
+	result, err := ImportGostDirectory(gostsDB, dir, database.DefaultGostSourcePriority, &testLogger{})
+	if err != nil {
+		t.Fatalf("ImportGostDirectory failed: %v", err)
+	}
+
+	if len(result.Files) != 2 || len(result.Skipped) != 1 {
+		t.Fatalf("Expected 2 imported and 1 skipped file, got %+v", result)
+	}
+	if result.Total != 3 || result.Success != 3 || result.ErrorCount != 0 {
+		t.Errorf("Unexpected totals: total=%d success=%d errors=%d", result.Total, result.Success, result.ErrorCount)
+	}
+
+	gost, err := gostsDB.GetGostByNumber("ГОСТ 11111-2019")
+	if err != nil {
+		t.Fatalf("GetGostByNumber failed: %v", err)
+	}
+	if gost.SourceType != "interstatestandards" {
+		t.Errorf("Expected source type inferred from file name, got %q", gost.SourceType)
+	}
+}
+
+func TestImportGostDirectory_SnapshotDiff(t *testing.T) {
+	gostsDB, err := database.NewGostsDB(filepath.Join(t.TempDir(), "gosts.db"))
+	if err != nil {
+		t.Fatalf("Failed to open gosts DB: %v", err)
+	}
+	defer gostsDB.Close()
+
+	runImport := func(csv string) *database.GostImportDiff {
+		t.Helper()
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "7706406291-nationalstandards.csv"), []byte(csv), 0644); err != nil {
+			t.Fatalf("Failed to write fixture: %v", err)
+		}
+		if _, err := ImportGostDirectory(gostsDB, dir, database.DefaultGostSourcePriority, &testLogger{}); err != nil {
+			t.Fatalf("ImportGostDirectory failed: %v", err)
+		}
+		diff, err := gostsDB.RecordGostImportSnapshot(10)
+		if err != nil {
+			t.Fatalf("RecordGostImportSnapshot failed: %v", err)
+		}
+		return diff
+	}
+
+	first := runImport("номер;название;дата принятия;статус\n" +
+		"ГОСТ 10001-2020;Первый стандарт;2020-01-01;действующий\n" +
+		"ГОСТ 10002-2020;Второй стандарт;2020-01-01;действующий\n")
+	if first.PreviousSnapshotID != 0 || first.Added != 2 || first.CurrentCount != 2 {
+		t.Errorf("Unexpected baseline diff %+v", first)
+	}
+
+	// Второй стандарт отменен, добавлен третий
+	second := runImport("номер;название;дата принятия;статус\n" +
+		"ГОСТ 10001-2020;Первый стандарт;2020-01-01;действующий\n" +
+		"ГОСТ 10002-2020;Второй стандарт;2020-01-01;отменен\n" +
+		"ГОСТ 10003-2021;Третий стандарт;2021-01-01;действующий\n")
+	if second.PreviousSnapshotID != first.SnapshotID || second.PreviousCount != 2 || second.CurrentCount != 3 {
+		t.Errorf("Unexpected snapshot references %+v", second)
+	}
+	if second.Added != 1 || second.Changed != 1 || second.Removed != 0 {
+		t.Errorf("Expected 1 added and 1 changed, got %+v", second)
+	}
+	if len(second.AddedSample) != 1 || second.AddedSample[0] != "ГОСТ 10003-2021" {
+		t.Errorf("Unexpected added sample %v", second.AddedSample)
+	}
+	if len(second.ChangedSample) != 1 || second.ChangedSample[0] != "ГОСТ 10002-2020" {
+		t.Errorf("Unexpected changed sample %v", second.ChangedSample)
+	}
+}
@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"httpserver/database"
+)
+
+// gostReferencePattern находит упоминания ГОСТов в произвольном тексте:
+// ГОСТ 8732-78, ГОСТ Р 52289-2019, ГОСТ ISO 9001-2011, ГОСТ Р ИСО/МЭК 17025-2019, ГОСТ IEC 60335-1-2015.
+// Группы: 1 - признак "Р", 2 - международный стандарт, 3 - номер (с частями через дефис, год включительно).
+// Пробелы вокруг дефиса допускаются только перед четырехзначным годом, чтобы не захватывать соседние числа.
+var gostReferencePattern = regexp.MustCompile(
+	`(?i)(?:^|[^\p{L}\p{N}])ГОСТ\s*(?:([РP])\s*)?` +
+		`(?:((?:ISO|ИСО|IEC|МЭК|EN|ЕН)(?:\s*/\s*(?:IEC|МЭК))?)\s*)?` +
+		`(\d+(?:\.\d+)*(?:[-–—]\d+)*(?:\s*[-–—]\s*\d{4}\b)?)`)
+
+var (
+	gostDashPattern  = regexp.MustCompile(`[-–—]`)
+	gostSpacePattern = regexp.MustCompile(`\s+`)
+)
+
+// ExtractGostNumbers извлекает номера ГОСТов из произвольного текста (описаний, спецификаций).
+// Номера приводятся к каноническому виду, в котором хранятся в базе ГОСТов;
+// повторы отбрасываются, порядок первого упоминания сохраняется.
+func ExtractGostNumbers(text string) []string {
+	matches := gostReferencePattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(matches))
+	numbers := make([]string, 0, len(matches))
+	for _, m := range matches {
+		parts := []string{"ГОСТ"}
+		if m[1] != "" {
+			parts = append(parts, "Р")
+		}
+		if m[2] != "" {
+			parts = append(parts, strings.ToUpper(gostSpacePattern.ReplaceAllString(m[2], "")))
+		}
+		parts = append(parts, splitGostYear(m[3]))
+
+		number := canonicalGostNumber(strings.Join(parts, " "))
+		if number == "" || seen[number] {
+			continue
+		}
+		seen[number] = true
+		numbers = append(numbers, number)
+	}
+
+	return numbers
+}
+
+// splitGostYear отделяет год утверждения от номера и дополняет двузначный год до четырехзначного.
+// Последняя часть считается годом, если она четырехзначная или если номер состоит из двух частей
+// (ГОСТ 8732-78); в остальных случаях (IEC 60335-2-24) дефисные части относятся к номеру.
+func splitGostYear(raw string) string {
+	segments := gostDashPattern.Split(gostSpacePattern.ReplaceAllString(raw, ""), -1)
+	if len(segments) < 2 {
+		return segments[0]
+	}
+
+	year := segments[len(segments)-1]
+	number := strings.Join(segments[:len(segments)-1], "-")
+	switch {
+	case len(year) == 4:
+	case len(year) == 2 && len(segments) == 2:
+		yy, _ := strconv.Atoi(year)
+		year = strconv.Itoa(database.ExpandGostYear(yy))
+	default:
+		return strings.Join(segments, "-")
+	}
+
+	return number + "-" + year
+}
@@ -0,0 +1,74 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractGostNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{
+			name:     "простой ГОСТ",
+			text:     "Труба стальная бесшовная 57х3.5 ГОСТ 8732-2019",
+			expected: []string{"ГОСТ 8732-2019"},
+		},
+		{
+			name:     "ГОСТ Р приводится к базовому виду",
+			text:     "Знаки дорожные по ГОСТ Р 52290-2004, тип 1",
+			expected: []string{"ГОСТ 52290-2004"},
+		},
+		{
+			name:     "латинская P и длинное тире",
+			text:     "Покрытие по ГОСТ P 52289 — 2019",
+			expected: []string{"ГОСТ 52289-2019"},
+		},
+		{
+			name:     "номер с точками и двузначным годом",
+			text:     "Пожарная безопасность (ГОСТ 12.1.004-91); сварка по ГОСТ 5264-80",
+			expected: []string{"ГОСТ 12.1.004-1991", "ГОСТ 5264-1980"},
+		},
+		{
+			name:     "международные стандарты",
+			text:     "Система менеджмента ГОСТ Р ИСО 9001-2015 и ГОСТ ISO/IEC 17025-2019",
+			expected: []string{"ГОСТ Р ИСО 9001-2015", "ГОСТ ISO/IEC 17025-2019"},
+		},
+		{
+			name:     "номер с частью стандарта",
+			text:     "Электроприборы бытовые ГОСТ IEC 60335-1-2015",
+			expected: []string{"ГОСТ IEC 60335-1-2015"},
+		},
+		{
+			name:     "без года",
+			text:     "Болт М12х60 гост 7798",
+			expected: []string{"ГОСТ 7798"},
+		},
+		{
+			name:     "повторы и разные варианты записи одного номера",
+			text:     "ГОСТ 8732-2019, ГОСТ Р 8732-2019; ГОСТ8732 - 2019",
+			expected: []string{"ГОСТ 8732-2019"},
+		},
+		{
+			name:     "слово ГОСТ внутри другого слова не учитывается",
+			text:     "ПРОГОСТ 123-2020 и ГОСТы без номеров",
+			expected: nil,
+		},
+		{
+			name:     "пустой текст",
+			text:     "",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := ExtractGostNumbers(tt.text)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ExtractGostNumbers(%q) = %q, want %q", tt.text, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package importer
+
+import (
+	"fmt"
+	"time"
+
+	"httpserver/database"
+)
+
+// GostRecordsImportResult итоги слияния разобранных записей ГОСТов с базой (см. ImportGostRecords)
+type GostRecordsImportResult struct {
+	SourceID  int
+	Total     int
+	Success   int
+	Inserted  int
+	Updated   int
+	Unchanged int      // Записи, совпавшие с сохраненными (см. database.GostsDB.UpsertGost)
+	Errors    []string // Ошибки отдельных записей
+}
+
+// ImportGostRecords создает или обновляет источник sourceType и сливает записи с базой по priority
+// (см. database.GostsDB.MergeGost). Записи без номера ГОСТа пропускаются, записи без названия
+// сохраняются с номером вместо названия. Ошибка возвращается, только если не удалось сохранить источник;
+// ошибки отдельных записей попадают в GostRecordsImportResult.Errors.
+func ImportGostRecords(gostsDB *database.GostsDB, records []GostRecord, sourceType, sourceURL string, priority []string) (*GostRecordsImportResult, error) {
+	// Источник создается до импорта, чтобы знать source_id
+	sourceRecord, err := gostsDB.CreateOrUpdateSource(&database.GostSource{
+		SourceName:   sourceType,
+		SourceURL:    sourceURL,
+		LastSyncDate: gostTimePtr(time.Now()),
+		RecordsCount: len(records),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update source: %w", err)
+	}
+
+	result := &GostRecordsImportResult{
+		SourceID: sourceRecord.ID,
+		Total:    len(records),
+		Errors:   []string{},
+	}
+	for i, record := range records {
+		if record.GostNumber == "" {
+			result.Errors = append(result.Errors, fmt.Sprintf("строка %d: отсутствует номер ГОСТа", i+1))
+			continue
+		}
+		title := record.Title
+		if title == "" {
+			title = record.GostNumber
+		}
+
+		_, outcome, err := gostsDB.MergeGostWithOutcome(&database.Gost{
+			GostNumber:     record.GostNumber,
+			Title:          title,
+			AdoptionDate:   record.AdoptionDate,
+			EffectiveDate:  record.EffectiveDate,
+			WithdrawalDate: record.WithdrawalDate,
+			Status:         record.Status,
+			SourceType:     sourceType,
+			SourceID:       &sourceRecord.ID,
+			SourceURL:      sourceURL,
+			Description:    record.Description,
+			Keywords:       record.Keywords,
+		}, priority)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("ГОСТ %s: %v", record.GostNumber, err))
+			continue
+		}
+		result.Success++
+		switch outcome {
+		case database.GostInserted:
+			result.Inserted++
+		case database.GostUpdated:
+			result.Updated++
+		case database.GostUnchanged:
+			result.Unchanged++
+		}
+	}
+
+	return result, nil
+}
+
+func gostTimePtr(t time.Time) *time.Time {
+	return &t
+}
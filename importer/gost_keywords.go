@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"httpserver/normalization"
+	"httpserver/normalization/algorithms"
+)
+
+// maxGeneratedKeywords максимальное количество ключевых слов, генерируемых GenerateKeywords
+const maxGeneratedKeywords = 12
+
+// gostKeywordStopwords типовые обороты названий ГОСТов, не несущие смысла для поиска.
+// Общие служебные слова берутся из набора normalization.Stopwords().
+var gostKeywordStopwords = map[string]bool{
+	"числе": true,
+	// Типовые слова заголовков стандартов
+	"гост": true, "ост": true, "снип": true, "стандарт": true,
+	"межгосударственный": true, "национальный": true, "государственный": true, "система": true,
+	"общие": true, "положения": true, "часть": true, "изменение": true, "поправка": true,
+	"технические": true, "условия": true, "требования": true, "введения": true, "введен": true,
+	"впервые": true, "взамен": true, "применения": true, "область": true, "настоящий": true,
+	"распространяется": true, "устанавливает": true, "вид": true,
+}
+
+var (
+	keywordStemmer = algorithms.NewRussianStemmer()
+	// gostKeywordStopStems основы служебных слов - отсекают и их словоформы ("стандартов", "требованиям")
+	gostKeywordStopStems = make(map[string]bool)
+)
+
+func init() {
+	for word := range gostKeywordStopwords {
+		gostKeywordStopStems[keywordStemmer.Stem(word)] = true
+	}
+}
+
+// GenerateKeywords извлекает ключевые слова из названия и описания ГОСТа: слова приводятся
+// к нижнему регистру и основе (стеммер Snowball), стоп-слова, числа и слова короче 3 букв
+// отбрасываются, повторы по основе удаляются. Слова названия идут первыми, результат - основы
+// через ", " (формат поля keywords), не более maxGeneratedKeywords. Основы выбраны, чтобы поиск
+// по keywords LIKE находил разные словоформы ("трубы", "трубам" -> "труб").
+func GenerateKeywords(title, description string) string {
+	seen := make(map[string]bool)
+	var keywords []string
+
+	for _, text := range []string{title, description} {
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-'
+		})
+
+		for _, word := range words {
+			word = strings.Trim(word, "-")
+			if !isSalientKeyword(word) {
+				continue
+			}
+
+			stem := keywordStemmer.StemWithCache(word)
+			if utf8.RuneCountInString(stem) < 3 || gostKeywordStopStems[stem] || seen[stem] {
+				continue
+			}
+			seen[stem] = true
+			keywords = append(keywords, stem)
+
+			if len(keywords) == maxGeneratedKeywords {
+				return strings.Join(keywords, ", ")
+			}
+		}
+	}
+
+	return strings.Join(keywords, ", ")
+}
+
+// isSalientKeyword проверяет, может ли слово быть ключевым: не служебное, не короче 3 букв и содержит буквы
+func isSalientKeyword(word string) bool {
+	if utf8.RuneCountInString(word) < 3 || gostKeywordStopwords[word] || normalization.Stopwords().Contains(word) {
+		return false
+	}
+	for _, r := range word {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"httpserver/normalization"
+)
+
+func TestGenerateKeywords(t *testing.T) {
+	tests := []struct {
+		name        string
+		title       string
+		description string
+		want        string
+	}{
+		{
+			name:  "трубы",
+			title: "Трубы стальные бесшовные горячедеформированные. Технические условия",
+			want:  "труб, стальн, бесшовн, горячедеформирова",
+		},
+		{
+			name:  "повтор словоформ",
+			title: "Бетоны. Методы определения прочности бетона по контрольным образцам",
+			want:  "бетон, метод, определен, прочност, контрольн, образц",
+		},
+		{
+			name:        "описание дополняет название",
+			title:       "Кабели силовые",
+			description: "Стандарт распространяется на силовые кабели с медными жилами",
+			want:        "кабел, силов, медн, жил",
+		},
+		{
+			name:  "числа и служебные слова отбрасываются",
+			title: "ГОСТ Р 12345-2020. Система стандартов безопасности труда. Общие требования",
+			want:  "безопасн, труд",
+		},
+		{
+			name:  "пустое название",
+			title: "",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GenerateKeywords(tt.title, tt.description); got != tt.want {
+				t.Errorf("GenerateKeywords(%q, %q) = %q, want %q", tt.title, tt.description, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateKeywords_Limit(t *testing.T) {
+	title := "альфа бета гамма дельта эпсилон дзета эта тета йота каппа лямбда мю ню кси омикрон пи ро сигма"
+	keywords := strings.Split(GenerateKeywords(title, ""), ", ")
+	if len(keywords) != maxGeneratedKeywords {
+		t.Errorf("Expected %d keywords, got %d: %v", maxGeneratedKeywords, len(keywords), keywords)
+	}
+}
+
+func TestNormalizeGostData_KeepsSourceKeywords(t *testing.T) {
+	parser := NewGostParser(DefaultParserConfig(), &testLogger{})
+
+	gost := &Gost{GostNumber: "ГОСТ 1", Title: "Трубы стальные", Keywords: "трубопровод, сталь"}
+	if err := parser.NormalizeGostData(gost); err != nil {
+		t.Fatalf("NormalizeGostData failed: %v", err)
+	}
+	if gost.Keywords != "трубопровод, сталь" {
+		t.Errorf("Source keywords should be kept, got %q", gost.Keywords)
+	}
+
+	gost = &Gost{GostNumber: "ГОСТ 2", Title: "Трубы стальные"}
+	if err := parser.NormalizeGostData(gost); err != nil {
+		t.Fatalf("NormalizeGostData failed: %v", err)
+	}
+	if gost.Keywords != "труб, стальн" {
+		t.Errorf("Expected generated keywords, got %q", gost.Keywords)
+	}
+}
+
+func TestGenerateKeywords_SharedStopwords(t *testing.T) {
+	defer normalization.SetStopwords(nil)
+
+	title := "Трубы стальные для трубопроводов между цехами"
+	if got, want := GenerateKeywords(title, ""), "труб, стальн, трубопровод, цех"; got != want {
+		t.Errorf("GenerateKeywords(%q) = %q, want %q", title, got, want)
+	}
+
+	normalization.SetStopwords(normalization.DefaultStopwordSet("цехами"))
+	if got, want := GenerateKeywords(title, ""), "труб, стальн, трубопровод"; got != want {
+		t.Errorf("GenerateKeywords with extra stopword = %q, want %q", got, want)
+	}
+}
@@ -2,6 +2,7 @@
 package importer
 
 import (
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
@@ -9,10 +10,13 @@ import (
 	"io"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
 
+	"httpserver/database"
+
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/charmap"
 	"golang.org/x/text/transform"
@@ -20,58 +24,62 @@ import (
 
 // GostCSVRecord represents a GOST record from CSV file
 type GostCSVRecord struct {
-	Number        string `csv:"number"`
-	Title         string `csv:"title"`
-	AdoptionDate  string `csv:"adoption_date"`
-	EffectiveDate string `csv:"effective_date"`
-	Status        string `csv:"status"`
-	SourceType    string `csv:"source_type"`
-	SourceURL     string `csv:"source_url"`
-	Description   string `csv:"description"`
-	Keywords      string `csv:"keywords"`
+	Number         string `csv:"number"`
+	Title          string `csv:"title"`
+	AdoptionDate   string `csv:"adoption_date"`
+	EffectiveDate  string `csv:"effective_date"`
+	WithdrawalDate string `csv:"withdrawal_date"`
+	Status         string `csv:"status"`
+	SourceType     string `csv:"source_type"`
+	SourceURL      string `csv:"source_url"`
+	Description    string `csv:"description"`
+	Keywords       string `csv:"keywords"`
 }
 
 // GostRecord представляет запись ГОСТа из CSV файла Росстандарта
 type GostRecord struct {
-	GostNumber    string
-	Title         string
-	AdoptionDate  *time.Time
-	EffectiveDate *time.Time
-	Status        string
-	SourceType    string
-	SourceURL     string
-	Description   string
-	Keywords      string
+	GostNumber     string
+	Title          string
+	AdoptionDate   *time.Time
+	EffectiveDate  *time.Time
+	WithdrawalDate *time.Time
+	Status         string
+	SourceType     string
+	SourceURL      string
+	Description    string
+	Keywords       string
 }
 
 // Gost represents a parsed GOST standard
 type Gost struct {
-	ID            int        `json:"id"`
-	GostNumber    string     `json:"gost_number"`
-	Title         string     `json:"title"`
-	AdoptionDate  *time.Time `json:"adoption_date"`
-	EffectiveDate *time.Time `json:"effective_date"`
-	Status        string     `json:"status"`
-	SourceType    string     `json:"source_type"`
-	SourceURL     string     `json:"source_url"`
-	Description   string     `json:"description"`
-	Keywords      string     `json:"keywords"`
+	ID             int        `json:"id"`
+	GostNumber     string     `json:"gost_number"`
+	Title          string     `json:"title"`
+	AdoptionDate   *time.Time `json:"adoption_date"`
+	EffectiveDate  *time.Time `json:"effective_date"`
+	WithdrawalDate *time.Time `json:"withdrawal_date,omitempty"`
+	Status         string     `json:"status"`
+	SourceType     string     `json:"source_type"`
+	SourceURL      string     `json:"source_url"`
+	Description    string     `json:"description"`
+	Keywords       string     `json:"keywords"`
 }
 
 // GostColumnIndices holds column indices for GOST CSV parsing
 type GostColumnIndices struct {
-	gostNumber    int
-	title         int
-	adoptionDate  int
-	effectiveDate int
-	status        int
-	description   int
-	keywords      int
+	gostNumber     int
+	title          int
+	adoptionDate   int
+	effectiveDate  int
+	withdrawalDate int
+	status         int
+	description    int
+	keywords       int
 }
 
 // ParserConfig holds configuration options for the GOST parser
 type ParserConfig struct {
-	Delimiter      rune   // CSV delimiter (default: comma)
+	Delimiter      rune   // CSV delimiter (0 - detect from the header line, see detectCSVDelimiter)
 	HasHeader      bool   // Whether CSV has header row
 	Encoding       string // Expected encoding (default: utf-8)
 	SkipEmptyRows  bool   // Skip empty rows
@@ -83,7 +91,7 @@ type ParserConfig struct {
 // DefaultParserConfig returns default configuration for the parser
 func DefaultParserConfig() ParserConfig {
 	return ParserConfig{
-		Delimiter:      ';', // Росстандарт использует точку с запятой
+		Delimiter:      0, // Определяется по строке заголовка: Росстандарт использует и ";", и ","
 		HasHeader:      true,
 		Encoding:       "utf-8",
 		SkipEmptyRows:  true,
@@ -102,9 +110,6 @@ type GostParser struct {
 
 // NewGostParser creates a new GOST parser with the given configuration
 func NewGostParser(config ParserConfig, logger interface{ Printf(format string, v ...interface{}) }) *GostParser {
-	if config.Delimiter == 0 {
-		config.Delimiter = ','
-	}
 	if config.ErrorCallback == nil {
 		config.ErrorCallback = func(err error) { fmt.Printf("Parsing error: %v\n", err) }
 	}
@@ -139,63 +144,80 @@ func (p *GostParser) ParseCSVFile(filePath string) ([]*Gost, error) {
 	return p.ParseCSVData(data)
 }
 
+// utf8BOM метка порядка байтов UTF-8, с которой начинаются некоторые файлы Росстандарта
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// csvDelimiterCandidates разделители, из которых выбирает detectCSVDelimiter (при равенстве - первый)
+var csvDelimiterCandidates = []rune{';', ',', '\t'}
+
+// detectCSVDelimiter определяет разделитель по строке заголовка: выбирается символ из
+// csvDelimiterCandidates, который встречается в ней чаще всего (вне кавычек).
+// Если ни один не встречается, используется запятая.
+func detectCSVDelimiter(data []byte) rune {
+	header := data
+	if end := bytes.IndexByte(header, '\n'); end >= 0 {
+		header = header[:end]
+	}
+
+	counts := make(map[rune]int, len(csvDelimiterCandidates))
+	inQuotes := false
+	for _, r := range string(header) {
+		if r == '"' {
+			inQuotes = !inQuotes
+			continue
+		}
+		if !inQuotes {
+			counts[r]++
+		}
+	}
+
+	delimiter, best := ',', 0
+	for _, candidate := range csvDelimiterCandidates {
+		if counts[candidate] > best {
+			delimiter, best = candidate, counts[candidate]
+		}
+	}
+	return delimiter
+}
+
 // ParseCSVData parses CSV data from byte slice and returns GOST records
 func (p *GostParser) ParseCSVData(data []byte) ([]*Gost, error) {
+	// BOM в начале файла иначе попадает в название первой колонки (после Windows-1251 - как "п»ї")
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	// "Кракозябры" (UTF-8, прочитанный как CP866 или Windows-1251) в UTF-8 файле исправляются
+	// до определения кодировки: декодирование такого файла как Windows-1251 теряет часть байтов
+	repairedLines := 0
+	if utf8.Valid(data) {
+		data, repairedLines = repairMojibakeLines(data)
+	}
+
 	// Detect and convert encoding if necessary
 	convertedData, err := p.detectAndConvertEncoding(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to detect/convert encoding: %w", err)
 	}
-	
-	// КРИТИЧЕСКАЯ ПРОВЕРКА: Проверяем, что после конвертации нет некорректных символов
-	convertedStr := string(convertedData)
-	hasInvalidChars := strings.Contains(convertedStr, "╨У") || strings.Contains(convertedStr, "╨Ю") || 
-	                   strings.Contains(convertedStr, "╨б") || strings.Contains(convertedStr, "╨в")
-	
-	if hasInvalidChars {
-		// КРИТИЧЕСКАЯ ОШИБКА: Результат все еще содержит некорректные символы
-		// Принудительно декодируем исходные данные как Windows-1251
-		if p.logger != nil {
-			p.logger.Printf("CRITICAL: Converted data still contains invalid chars, forcing Windows-1251 decode on raw data")
-		}
-		// Если все еще есть некорректные символы, пробуем более агрессивное исправление
-		if p.logger != nil {
-			p.logger.Printf("Warning: still detecting invalid encoding characters, attempting aggressive fix")
-		}
-		
-		// Пробуем декодировать исходные данные как Windows-1251 напрямую
-		decoder := charmap.Windows1251.NewDecoder()
-		fixed, _, err := transform.Bytes(decoder, data)
-		if err == nil && len(fixed) > 0 && utf8.Valid(fixed) {
-			fixedStr := string(fixed)
-			// Если после декодирования все еще есть некорректные символы, значит файл уже был в UTF-8
-			// с неправильными символами - пробуем декодировать еще раз (двойное декодирование)
-			if strings.Contains(fixedStr, "╨У") || strings.Contains(fixedStr, "╨Ю") {
-				// Декодируем еще раз - это двойная конвертация
-				fixed2, _, err2 := transform.Bytes(decoder, fixed)
-				if err2 == nil && len(fixed2) > 0 && utf8.Valid(fixed2) {
-					fixedStr2 := string(fixed2)
-					if hasCyrillicRunes(fixedStr2) && strings.Contains(fixedStr2, "ГОСТ") && !strings.Contains(fixedStr2, "╨У") {
-						convertedData = fixed2
-						if p.logger != nil {
-							p.logger.Printf("Successfully fixed encoding using double-decoding method")
-						}
-					}
-				}
-			} else if hasCyrillicRunes(fixedStr) && strings.Contains(fixedStr, "ГОСТ") {
-				// Первое декодирование помогло
-				convertedData = fixed
-				if p.logger != nil {
-					p.logger.Printf("Successfully fixed encoding using Windows-1251 decoder")
-				}
-			}
-		}
+
+	// Строки, оставшиеся "кракозябрами" после перекодировки, исправляются до разбора,
+	// чтобы испорченный текст не попал в базу
+	convertedData, residualLines := repairMojibakeLines(convertedData)
+	if repairedLines+residualLines > 0 && p.logger != nil {
+		p.logger.Printf("Repaired double-encoded text in %d lines", repairedLines+residualLines)
 	}
 
 	// Create CSV reader
 	// Use converted data directly (already in UTF-8 from detectAndConvertEncoding)
+	convertedData = bytes.TrimPrefix(convertedData, utf8BOM)
+	delimiter := p.config.Delimiter
+	if delimiter == 0 {
+		delimiter = detectCSVDelimiter(convertedData)
+	}
+	if p.logger != nil {
+		p.logger.Printf("Using CSV delimiter %q", delimiter)
+	}
+
 	reader := csv.NewReader(strings.NewReader(string(convertedData)))
-	reader.Comma = p.config.Delimiter
+	reader.Comma = delimiter
 	reader.LazyQuotes = true
 	reader.TrimLeadingSpace = true
 
@@ -298,6 +320,17 @@ func (p *GostParser) ParseCSVData(data []byte) ([]*Gost, error) {
 			}
 		}
 		
+		// Извлекаем дату отмены
+		if colIndices.withdrawalDate >= 0 && colIndices.withdrawalDate < len(row) {
+			dateStr := strings.TrimSpace(row[colIndices.withdrawalDate])
+			if dateStr != "" {
+				date, parseErr := p.parseDate(dateStr)
+				if parseErr == nil && date != nil {
+					gost.WithdrawalDate = date
+				}
+			}
+		}
+		
 		// Извлекаем статус
 		if colIndices.status >= 0 && colIndices.status < len(row) {
 			gost.Status = strings.TrimSpace(row[colIndices.status])
@@ -646,6 +679,14 @@ func (p *GostParser) NormalizeGostData(gost *Gost) error {
 				gost.EffectiveDate = date
 			}
 		}
+
+		if gost.WithdrawalDate != nil {
+			normalized := gost.WithdrawalDate.Format("2006-01-02")
+			date, err := p.parseDate(normalized)
+			if err == nil && date != nil {
+				gost.WithdrawalDate = date
+			}
+		}
 	}
 
 	// Normalize status
@@ -661,6 +702,11 @@ func (p *GostParser) NormalizeGostData(gost *Gost) error {
 	gost.Keywords = strings.TrimSpace(gost.Keywords)
 	gost.SourceURL = strings.TrimSpace(gost.SourceURL)
 
+	// Источник без ключевых слов - генерируем их из названия и описания
+	if gost.Keywords == "" {
+		gost.Keywords = GenerateKeywords(gost.Title, gost.Description)
+	}
+
 	return nil
 }
 
@@ -1097,6 +1143,12 @@ func (p *GostParser) parseDate(dateStr string) (*time.Time, error) {
 
 // normalizeGostNumber normalizes GOST number to standard format
 func (p *GostParser) normalizeGostNumber(number string) string {
+	return canonicalGostNumber(number)
+}
+
+// canonicalGostNumber приводит номер ГОСТа к каноническому виду "ГОСТ номер-год",
+// в котором номера хранятся в базе ГОСТов
+func canonicalGostNumber(number string) string {
 	number = strings.TrimSpace(number)
 	if number == "" {
 		return ""
@@ -1127,12 +1179,8 @@ func (p *GostParser) normalizeGostNumber(number string) string {
 		shortYearPattern := regexp.MustCompile(`(?i)^(ГОСТ\s*Р?\s*)?(\d+(?:\.\d+)*)\s*[-–—]\s*(\d{2})$`)
 		shortMatches := shortYearPattern.FindStringSubmatch(number)
 		if len(shortMatches) == 4 {
-			year := shortMatches[3]
-			if len(year) == 2 {
-				// Преобразуем 2-значный год в 4-значный (предполагаем 20xx)
-				year = "20" + year
-			}
-			return fmt.Sprintf("ГОСТ %s-%s", shortMatches[2], year)
+			yy, _ := strconv.Atoi(shortMatches[3])
+			return fmt.Sprintf("ГОСТ %s-%d", shortMatches[2], database.ExpandGostYear(yy))
 		}
 
 	// If doesn't match pattern but contains "ГОСТ", return as is (normalize spaces)
@@ -1244,26 +1292,25 @@ func ParseGostCSVFromReader(reader io.Reader) ([]GostRecord, error) {
 		return nil, fmt.Errorf("failed to parse CSV data: %w", err)
 	}
 	
-	// Конвертируем из []*Gost в []GostRecord
+	return gostRecordsFromGosts(gosts), nil
+}
+
+// gostRecordsFromGosts конвертирует разобранные парсером ГОСТы в записи для импорта
+func gostRecordsFromGosts(gosts []*Gost) []GostRecord {
 	records := make([]GostRecord, 0, len(gosts))
 	for _, gost := range gosts {
-		record := GostRecord{
-			GostNumber:    gost.GostNumber,
-			Title:         gost.Title,
-			Status:        gost.Status,
-			Description:   gost.Description,
-			Keywords:      gost.Keywords,
-		}
-		if gost.AdoptionDate != nil {
-			record.AdoptionDate = gost.AdoptionDate
-		}
-		if gost.EffectiveDate != nil {
-			record.EffectiveDate = gost.EffectiveDate
-		}
-		records = append(records, record)
-	}
-	
-	return records, nil
+		records = append(records, GostRecord{
+			GostNumber:     gost.GostNumber,
+			Title:          gost.Title,
+			AdoptionDate:   gost.AdoptionDate,
+			EffectiveDate:  gost.EffectiveDate,
+			WithdrawalDate: gost.WithdrawalDate,
+			Status:         gost.Status,
+			Description:    gost.Description,
+			Keywords:       gost.Keywords,
+		})
+	}
+	return records
 }
 
 // simpleLogger простой логгер для парсера
@@ -1320,13 +1367,14 @@ func normalizeGostNumber(number string) string {
 // findGostColumnIndices определяет индексы колонок для парсинга ГОСТов
 func findGostColumnIndices(headerMap map[string]int) GostColumnIndices {
 	indices := GostColumnIndices{
-		gostNumber:    -1,
-		title:         -1,
-		adoptionDate:  -1,
-		effectiveDate: -1,
-		status:        -1,
-		description:   -1,
-		keywords:      -1,
+		gostNumber:     -1,
+		title:          -1,
+		adoptionDate:   -1,
+		effectiveDate:  -1,
+		withdrawalDate: -1,
+		status:         -1,
+		description:    -1,
+		keywords:       -1,
 	}
 
 	// Ищем номер ГОСТа
@@ -1361,6 +1409,14 @@ func findGostColumnIndices(headerMap map[string]int) GostColumnIndices {
 		}
 	}
 
+	// Ищем дату отмены
+	for key, val := range headerMap {
+		if strings.Contains(key, "дата отмены") || strings.Contains(key, "дата прекращения") {
+			indices.withdrawalDate = val
+			break
+		}
+	}
+
 	// Ищем статус
 	for key, val := range headerMap {
 		if strings.Contains(key, "статус") {
@@ -1426,6 +1482,18 @@ func parseGostRow(row []string, indices GostColumnIndices) GostRecord {
 		}
 	}
 
+	// Извлекаем дату отмены
+	if indices.withdrawalDate >= 0 && indices.withdrawalDate < len(row) {
+		dateStr := strings.TrimSpace(row[indices.withdrawalDate])
+		if dateStr != "" {
+			if date, err := time.Parse("2006-01-02", dateStr); err == nil {
+				record.WithdrawalDate = &date
+			} else if date, err := time.Parse("02.01.2006", dateStr); err == nil {
+				record.WithdrawalDate = &date
+			}
+		}
+	}
+
 	// Извлекаем статус
 	if indices.status >= 0 && indices.status < len(row) {
 		record.Status = strings.TrimSpace(row[indices.status])
@@ -761,6 +761,57 @@ func TestParseCSVFromJSON(t *testing.T) {
 	}
 }
 
+func TestParseCSVData_DelimiterAndBOMDetection(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		delimiter rune // Значение ParserConfig.Delimiter (0 - автоопределение)
+		want      rune
+	}{
+		{"semicolon", "номер;название;статус\nГОСТ 12345-2020;Стандарт, с запятой;действующий\n", 0, ';'},
+		{"comma with BOM", "\uFEFFномер,название,статус\nГОСТ 12345-2020,\"Стандарт; с точкой с запятой\",действующий\n", 0, ','},
+		{"tab", "номер\tназвание\tстатус\nГОСТ 12345-2020\tСтандарт\tдействующий\n", 0, '\t'},
+		{"quoted header", "\"номер;обозначение\",название,статус\nГОСТ 12345-2020,Стандарт,действующий\n", 0, ','},
+		{"explicit override", "номер|название|статус\nГОСТ 12345-2020|Стандарт|действующий\n", '|', '|'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := &testLogger{}
+			config := DefaultParserConfig()
+			config.Delimiter = tt.delimiter
+			gosts, err := NewGostParser(config, logger).ParseCSVData([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("ParseCSVData failed: %v", err)
+			}
+			if len(gosts) != 1 {
+				t.Fatalf("Expected 1 GOST, got %d", len(gosts))
+			}
+			if gosts[0].GostNumber != "ГОСТ 12345-2020" || !strings.HasPrefix(gosts[0].Title, "Стандарт") || gosts[0].Status != "действующий" {
+				t.Errorf("Fields split incorrectly: %+v", gosts[0])
+			}
+
+			logged := fmt.Sprintf("Using CSV delimiter %q", tt.want)
+			found := false
+			for _, message := range logger.messages {
+				found = found || message == logged
+			}
+			if !found {
+				t.Errorf("Expected %q in log, got %v", logged, logger.messages)
+			}
+		})
+	}
+
+	// Файл с BOM через ParseGostCSVFromReader: BOM не попадает в название первой колонки
+	records, err := ParseGostCSVFromReader(strings.NewReader("\uFEFFномер;название;статус\nГОСТ 54321-2021;Стандарт;действующий\n"))
+	if err != nil {
+		t.Fatalf("ParseGostCSVFromReader failed: %v", err)
+	}
+	if len(records) != 1 || records[0].GostNumber != "ГОСТ 54321-2021" {
+		t.Errorf("Unexpected records from BOM file: %+v", records)
+	}
+}
+
 // testLogger simple logger for tests
 type testLogger struct {
 	messages []string
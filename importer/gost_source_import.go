@@ -0,0 +1,324 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"httpserver/database"
+	"httpserver/internal/httpclient"
+)
+
+// gostDownloadTimeout таймаут скачивания файла источника ГОСТов (файлы Росстандарта большие)
+const gostDownloadTimeout = 5 * time.Minute
+
+// ImportOptions настройки импорта ГОСТов из источника (ImportGostSource, ImportGostData)
+type ImportOptions struct {
+	// Priority порядок источников при слиянии пересекающихся ГОСТов (см. database.GostsDB.MergeGost)
+	Priority []string
+	// TempFiles сохраняет скачанные данные на время импорта; при ошибке файл остается для отладки.
+	// nil - данные на диск не сохраняются.
+	TempFiles *TempFileManager
+	// Client HTTP клиент для скачивания; nil - клиент с таймаутом 5 минут и повторами при временных сбоях
+	Client *http.Client
+	// WriteLock сериализует запись в БД при параллельном импорте нескольких источников; nil - без блокировки
+	WriteLock sync.Locker
+	// Logger сообщения о ходе импорта; nil - сообщения не выводятся
+	Logger GostImportLogger
+}
+
+func (o ImportOptions) logger() GostImportLogger {
+	if o.Logger == nil {
+		return discardGostLogger{}
+	}
+	return o.Logger
+}
+
+// discardGostLogger логгер, отбрасывающий сообщения
+type discardGostLogger struct{}
+
+func (discardGostLogger) Printf(format string, v ...interface{}) {}
+
+// ImportGostSource скачивает CSV источника sourceType по url и импортирует ГОСТы в gostsDB.
+// Если по url отдается HTML страница, на ней ищется ссылка на CSV; сжатые ответы (.csv.gz, .zip)
+// распаковываются, каждый CSV из архива импортируется отдельно.
+// Ошибка возвращается, если источник не удалось скачать или разобрать; ошибки отдельных записей
+// попадают в ImportResult.Errors и импорт не прерывают.
+func ImportGostSource(gostsDB *database.GostsDB, url, sourceType string, opts ImportOptions) (*ImportResult, error) {
+	logger := opts.logger()
+	result := &ImportResult{Started: time.Now(), Errors: []string{}}
+
+	data, fileName, contentType, contentEncoding, err := downloadGostSource(url, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	process := func(tempPath string) error {
+		if tempPath != "" {
+			logger.Printf("Saved download to %s", tempPath)
+		}
+		payloads, err := ExtractCSVPayloads(data, fileName, contentEncoding)
+		if err != nil {
+			return err
+		}
+		if opts.WriteLock != nil {
+			opts.WriteLock.Lock()
+			defer opts.WriteLock.Unlock()
+		}
+		for _, payload := range payloads {
+			if len(payloads) > 1 {
+				logger.Printf("Importing %s from archive (%d bytes)", payload.Name, len(payload.Data))
+			}
+			if err := importGostCSV(gostsDB, payload.Data, url, sourceType, contentType, opts, result); err != nil {
+				return fmt.Errorf("%s: %w", payload.Name, err)
+			}
+		}
+		return nil
+	}
+
+	if opts.TempFiles != nil {
+		err = opts.TempFiles.Process(sourceType, data, process)
+	} else {
+		err = process("")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.Completed = time.Now()
+	result.Duration = result.Completed.Sub(result.Started)
+	return result, nil
+}
+
+// ImportGostData импортирует уже прочитанный CSV (например, локальный файл) как данные источника sourceType
+func ImportGostData(gostsDB *database.GostsDB, data []byte, url, sourceType string, opts ImportOptions) (*ImportResult, error) {
+	result := &ImportResult{Started: time.Now(), Errors: []string{}}
+
+	if opts.WriteLock != nil {
+		opts.WriteLock.Lock()
+		defer opts.WriteLock.Unlock()
+	}
+	if err := importGostCSV(gostsDB, data, url, sourceType, "", opts, result); err != nil {
+		return nil, err
+	}
+
+	result.Completed = time.Now()
+	result.Duration = result.Completed.Sub(result.Started)
+	return result, nil
+}
+
+// downloadGostSource скачивает данные источника, переходя по ссылке на CSV, если сервер вернул HTML.
+// Возвращает данные, имя файла из итогового URL, Content-Type и Content-Encoding ответа.
+func downloadGostSource(sourceURL string, opts ImportOptions) (data []byte, fileName, contentType, contentEncoding string, err error) {
+	logger := opts.logger()
+	client := opts.Client
+	if client == nil {
+		client = httpclient.New(httpclient.Options{
+			Timeout:     gostDownloadTimeout,
+			RetryCount:  2,
+			RetryJitter: httpclient.DefaultRetryJitter,
+		})
+	}
+
+	logger.Printf("Downloading CSV from: %s", sourceURL)
+	resp, err := client.Get(sourceURL)
+	if err != nil {
+		return nil, "", "", "", fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", "", "", fmt.Errorf("failed to download file: status code %d", resp.StatusCode)
+	}
+
+	contentType = resp.Header.Get("Content-Type")
+	if contentType != "" {
+		logger.Printf("Content-Type: %s", contentType)
+	}
+
+	// Если сервер вернул HTML вместо CSV, ищем на странице ссылку на CSV
+	if strings.Contains(contentType, "text/html") {
+		logger.Printf("Server returned HTML, searching for CSV download links...")
+
+		htmlContent, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, "", "", "", fmt.Errorf("failed to read HTML content: %w", err)
+		}
+		csvURL, err := findCSVLinkInHTML(string(htmlContent), sourceURL)
+		if err != nil {
+			return nil, "", "", "", fmt.Errorf("failed to find CSV link in HTML: %w", err)
+		}
+		if csvURL == "" {
+			return nil, "", "", "", fmt.Errorf("no CSV download link found in HTML page")
+		}
+		logger.Printf("Found CSV link: %s", csvURL)
+
+		resp, err = client.Get(csvURL)
+		if err != nil {
+			return nil, "", "", "", fmt.Errorf("failed to download CSV from found link: %w", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, "", "", "", fmt.Errorf("failed to download CSV: status code %d", resp.StatusCode)
+		}
+		contentType = resp.Header.Get("Content-Type")
+		if contentType != "" {
+			logger.Printf("CSV Content-Type: %s", contentType)
+		}
+	}
+
+	// Данные читаются целиком, чтобы парсер определил кодировку до разбора
+	data, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", "", "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, "", "", "", fmt.Errorf("downloaded file is empty")
+	}
+	logger.Printf("Downloaded file size: %d bytes", len(data))
+
+	// Имя файла из итогового URL (после редиректов и перехода по ссылке из HTML) для определения сжатия
+	if resp.Request != nil && resp.Request.URL != nil {
+		fileName = path.Base(resp.Request.URL.Path)
+	}
+	return data, fileName, contentType, resp.Header.Get("Content-Encoding"), nil
+}
+
+// importGostCSV разбирает CSV и сливает ГОСТы с базой, добавляя итоги в result
+func importGostCSV(gostsDB *database.GostsDB, data []byte, url, sourceType, contentType string, opts ImportOptions, result *ImportResult) error {
+	logger := opts.logger()
+	parser := NewGostParser(DefaultParserConfig(), logger)
+
+	// Парсер сам определяет и исправляет кодировку
+	records, err := parser.ParseCSVData(data)
+	if err != nil {
+		if strings.Contains(contentType, "text/html") {
+			return fmt.Errorf("server returned HTML instead of CSV. This source may not provide CSV format or URL format is incorrect: %w", err)
+		}
+		return fmt.Errorf("failed to parse CSV: %w", err)
+	}
+	logger.Printf("Parsed %d records from %s", len(records), sourceType)
+
+	imported, err := ImportGostRecords(gostsDB, gostRecordsFromGosts(records), sourceType, url, opts.Priority)
+	if err != nil {
+		return err
+	}
+	result.SourceID = imported.SourceID
+	result.Total += imported.Total
+	result.Success += imported.Success
+	result.Inserted += imported.Inserted
+	result.Updated += imported.Updated
+	result.Unchanged += imported.Unchanged
+	result.Errors = append(result.Errors, imported.Errors...)
+
+	logger.Printf("Imported %d/%d GOSTs from %s (inserted: %d, updated: %d, unchanged: %d)",
+		result.Success, result.Total, sourceType, result.Inserted, result.Updated, result.Unchanged)
+	return nil
+}
+
+// findCSVLinkInHTML парсит HTML и ищет ссылки на CSV файлы
+func findCSVLinkInHTML(htmlContent, baseURL string) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlContent))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse HTML: %w", err)
+	}
+
+	// Базовый URL для разрешения относительных ссылок
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse base URL: %w", err)
+	}
+
+	var csvURL string
+
+	// Стратегия 1: Ищем ссылки с расширением .csv или параметрами формата
+	doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
+		if csvURL != "" {
+			return
+		}
+		href, exists := s.Attr("href")
+		if !exists {
+			return
+		}
+
+		hrefLower := strings.ToLower(href)
+		if strings.Contains(hrefLower, ".csv") ||
+			strings.Contains(hrefLower, "format=csv") ||
+			strings.Contains(hrefLower, "export=csv") ||
+			strings.Contains(hrefLower, "download") {
+			parsedURL, err := url.Parse(href)
+			if err != nil {
+				return
+			}
+			csvURL = base.ResolveReference(parsedURL).String()
+		}
+	})
+
+	// Стратегия 2: Ищем кнопки или элементы с data-атрибутами
+	if csvURL == "" {
+		doc.Find("[data-format='csv'], [data-export='csv'], [data-download='csv']").Each(func(i int, s *goquery.Selection) {
+			if csvURL != "" {
+				return
+			}
+			href, exists := s.Attr("href")
+			if !exists {
+				href, exists = s.Attr("data-url")
+			}
+			if !exists {
+				href, exists = s.Attr("data-link")
+			}
+			if exists && href != "" {
+				if parsedURL, err := url.Parse(href); err == nil {
+					csvURL = base.ResolveReference(parsedURL).String()
+				}
+			}
+		})
+	}
+
+	// Стратегия 3: Ищем ссылки с текстом "CSV", "Скачать", "Download"
+	if csvURL == "" {
+		doc.Find("a").Each(func(i int, s *goquery.Selection) {
+			if csvURL != "" {
+				return
+			}
+			text := strings.ToLower(strings.TrimSpace(s.Text()))
+			if !strings.Contains(text, "csv") &&
+				!strings.Contains(text, "скачать") &&
+				!strings.Contains(text, "download") &&
+				!strings.Contains(text, "экспорт") {
+				return
+			}
+			href, exists := s.Attr("href")
+			if !exists || href == "" {
+				return
+			}
+			parsedURL, err := url.Parse(href)
+			if err != nil {
+				return
+			}
+			// Проверяем, что ссылка действительно ведет на CSV
+			resolvedURL := base.ResolveReference(parsedURL)
+			resolvedStr := strings.ToLower(resolvedURL.String())
+			if strings.Contains(resolvedStr, ".csv") ||
+				strings.Contains(resolvedStr, "format=csv") ||
+				strings.Contains(resolvedStr, "export=csv") {
+				csvURL = resolvedURL.String()
+			}
+		})
+	}
+
+	// Стратегия 4: Пробуем добавить .csv к базовому URL
+	if csvURL == "" && !strings.HasSuffix(baseURL, ".csv") {
+		csvURL = baseURL + ".csv"
+	}
+
+	return csvURL, nil
+}
@@ -0,0 +1,146 @@
+package importer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"httpserver/database"
+)
+
+func TestImportGostSource_FollowsCSVLinkFromHTML(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/opendata/nationalstandards", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><body><a href="/files/data-nationalstandards.csv">Скачать CSV</a></body></html>`))
+	})
+	mux.HandleFunc("/files/data-nationalstandards.csv", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Write([]byte(testGostCSV + "ГОСТ 54321-2021;Второй стандарт;2021-01-01;действующий\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	dir := t.TempDir()
+	gostsDB, err := database.NewGostsDB(filepath.Join(dir, "gosts.db"))
+	if err != nil {
+		t.Fatalf("Failed to create GOSTs DB: %v", err)
+	}
+	defer gostsDB.Close()
+
+	sourceURL := server.URL + "/opendata/nationalstandards"
+	result, err := ImportGostSource(gostsDB, sourceURL, "nationalstandards", ImportOptions{
+		TempFiles: NewTempFileManager(filepath.Join(dir, "temp")),
+	})
+	if err != nil {
+		t.Fatalf("ImportGostSource failed: %v", err)
+	}
+	if result.Total != 2 || result.Success != 2 || len(result.Errors) != 0 {
+		t.Errorf("Unexpected import result: total=%d success=%d errors=%v", result.Total, result.Success, result.Errors)
+	}
+	if result.SourceID == 0 {
+		t.Error("Expected source to be created")
+	}
+
+	gost, err := gostsDB.GetGostByNumber("ГОСТ 54321-2021")
+	if err != nil {
+		t.Fatalf("Expected GOST to be imported: %v", err)
+	}
+	if gost.SourceType != "nationalstandards" || gost.SourceURL != sourceURL {
+		t.Errorf("Unexpected GOST source: %s %s", gost.SourceType, gost.SourceURL)
+	}
+
+	if _, err := ImportGostSource(gostsDB, server.URL+"/missing", "nationalstandards", ImportOptions{}); err == nil {
+		t.Error("Expected error for unavailable source")
+	}
+}
+
+func TestFindCSVLinkInHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{"csv extension", `<a href="files/gosts.csv">Файл</a>`, "https://example.com/opendata/files/gosts.csv"},
+		{"data attribute", `<button data-format="csv" data-url="/export?id=1">CSV</button>`, "https://example.com/export?id=1"},
+		{"fallback", `<p>нет ссылок</p>`, "https://example.com/opendata/gosts.csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findCSVLinkInHTML(tt.html, "https://example.com/opendata/gosts")
+			if err != nil {
+				t.Fatalf("findCSVLinkInHTML failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestImportGostData_SecondImportLeavesRowsUnchanged(t *testing.T) {
+	gostsDB, err := database.NewGostsDB(filepath.Join(t.TempDir(), "gosts.db"))
+	if err != nil {
+		t.Fatalf("Failed to create GOSTs DB: %v", err)
+	}
+	defer gostsDB.Close()
+
+	data := []byte(testGostCSV + "ГОСТ 54321-2021;Второй стандарт;2021-01-01;действующий\n")
+	first, err := ImportGostData(gostsDB, data, "https://example.com/gosts.csv", "nationalstandards", ImportOptions{})
+	if err != nil {
+		t.Fatalf("First import failed: %v", err)
+	}
+	if first.Inserted != 2 || first.Updated != 0 || first.Unchanged != 0 {
+		t.Errorf("Unexpected first import: inserted=%d updated=%d unchanged=%d", first.Inserted, first.Updated, first.Unchanged)
+	}
+
+	second, err := ImportGostData(gostsDB, data, "https://example.com/gosts.csv", "nationalstandards", ImportOptions{})
+	if err != nil {
+		t.Fatalf("Second import failed: %v", err)
+	}
+	if second.Inserted != 0 || second.Updated != 0 || second.Unchanged != 2 {
+		t.Errorf("Expected second import to leave all rows unchanged: inserted=%d updated=%d unchanged=%d",
+			second.Inserted, second.Updated, second.Unchanged)
+	}
+
+	changed := []byte(testGostCSV + "ГОСТ 54321-2021;Второй стандарт (изм. 1);2021-01-01;действующий\n")
+	third, err := ImportGostData(gostsDB, changed, "https://example.com/gosts.csv", "nationalstandards", ImportOptions{})
+	if err != nil {
+		t.Fatalf("Third import failed: %v", err)
+	}
+	if third.Updated != 1 || third.Unchanged != 1 {
+		t.Errorf("Expected only the changed row to be updated: updated=%d unchanged=%d", third.Updated, third.Unchanged)
+	}
+}
+
+func TestImportGostData_StoresWithdrawalDate(t *testing.T) {
+	gostsDB, err := database.NewGostsDB(filepath.Join(t.TempDir(), "gosts.db"))
+	if err != nil {
+		t.Fatalf("Failed to create GOSTs DB: %v", err)
+	}
+	defer gostsDB.Close()
+
+	data := []byte("номер;название;дата принятия;дата отмены;статус\n" +
+		"ГОСТ 12345-2020;Отмененный стандарт;2020-01-01;2022-07-01;действующий\n")
+	if _, err := ImportGostData(gostsDB, data, "https://example.com/gosts.csv", "nationalstandards", ImportOptions{}); err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	gost, err := gostsDB.GetGostByNumber("ГОСТ 12345-2020")
+	if err != nil {
+		t.Fatalf("Expected GOST to be imported: %v", err)
+	}
+	if gost.WithdrawalDate == nil || gost.WithdrawalDate.Format("2006-01-02") != "2022-07-01" {
+		t.Fatalf("Expected withdrawal date 2022-07-01, got %v", gost.WithdrawalDate)
+	}
+
+	// Действующий статус при прошедшей дате отмены должен находиться аудитом
+	inconsistencies, err := gostsDB.FindInconsistentGosts()
+	if err != nil {
+		t.Fatalf("FindInconsistentGosts failed: %v", err)
+	}
+	if len(inconsistencies) != 1 || inconsistencies[0].Rule != database.GostRuleActiveWithdrawn {
+		t.Errorf("Expected one %s inconsistency, got %+v", database.GostRuleActiveWithdrawn, inconsistencies)
+	}
+}
@@ -25,10 +25,25 @@ type ImportResult struct {
 	Total     int           `json:"total"`
 	Success   int           `json:"success"`
 	Updated   int           `json:"updated"`
+	Inserted  int           `json:"inserted,omitempty"`  // Новые ГОСТы (ImportGostSource, ImportGostData)
+	Unchanged int           `json:"unchanged,omitempty"` // ГОСТы, совпавшие с сохраненными (см. database.GostsDB.UpsertGost)
 	Errors    []string      `json:"errors"`
 	Started   time.Time     `json:"started"`
 	Completed time.Time     `json:"completed"`
 	Duration  time.Duration `json:"duration"`
+	Cancelled bool          `json:"cancelled,omitempty"` // Импорт прерван до обработки всех записей
+	// ManufacturerMatches количество номенклатур по ключу связи с производителем (inn, ogrn, none)
+	ManufacturerMatches map[string]int `json:"manufacturer_matches,omitempty"`
+	// StubReferences количество созданных заглушек справочников (gisp_inline) по справочнику
+	StubReferences map[string]int `json:"stub_references,omitempty"`
+	// MissingReferenceCodes коды, отсутствующие в справочниках: справочник -> код -> количество строк
+	MissingReferenceCodes map[string]map[string]int `json:"missing_reference_codes,omitempty"`
+	// CreatedMinID, CreatedMaxID диапазон id эталонов, созданных импортом (0 - новых эталонов нет).
+	// Используется для привязки к справочникам только свежих строк (RelinkReferenceBooksForRange).
+	CreatedMinID int `json:"created_min_id,omitempty"`
+	CreatedMaxID int `json:"created_max_id,omitempty"`
+	// SourceID источник ГОСТов в gosts.db, в который выполнен импорт (ImportGostSource, ImportGostData)
+	SourceID int `json:"source_id,omitempty"`
 }
 
 // ImportManufacturers импортирует данные из перечня в базу эталонов
@@ -69,6 +84,9 @@ func (ri *ReferenceImporter) importManufacturer(m ManufacturerRecord, projectID
 	if m.INN == "" {
 		return false, fmt.Errorf("INN is required")
 	}
+	if strings.TrimSpace(m.Name) == "" {
+		return false, fmt.Errorf("manufacturer name is required: %w", database.ErrInvalidName)
+	}
 
 	// Проверяем, существует ли уже эталон с таким ИНН в этом проекте
 	existing, err := ri.findExistingBenchmark(projectID, m.INN)
@@ -0,0 +1,37 @@
+package importer
+
+import (
+	"bytes"
+	"unicode/utf8"
+
+	"httpserver/database"
+)
+
+// repairMojibakeLines исправляет "кракозябры" построчно: в файле могут быть испорчены
+// только отдельные строки, а правильные строки database.RepairMojibake не изменяет
+func repairMojibakeLines(data []byte) ([]byte, int) {
+	lines := bytes.Split(data, []byte("\n"))
+	repaired := 0
+	for i, line := range lines {
+		if !hasNonASCIIBytes(line) {
+			continue
+		}
+		if fixed, ok := database.RepairMojibake(string(line)); ok {
+			lines[i] = []byte(fixed)
+			repaired++
+		}
+	}
+	if repaired == 0 {
+		return data, 0
+	}
+	return bytes.Join(lines, []byte("\n")), repaired
+}
+
+func hasNonASCIIBytes(data []byte) bool {
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,27 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCSVData_RepairsDoubleEncodedRows(t *testing.T) {
+	parser := NewGostParser(DefaultParserConfig(), &testLogger{})
+	data := []byte("номер;название;дата принятия;статус\n" +
+		"╨У╨Ю╨б╨в 7798-70;╨С╨╛╨╗╤В╤Л ╤Б ╤И╨╡╤Б╤В╨╕╨│╤А╨░╨╜╨╜╨╛╨╣ ╨│╨╛╨╗╨╛╨▓╨║╨╛╨╣;1970-01-01;╨┤╨╡╨╣╤Б╤В╨▓╤Г╤О╤Й╨╕╨╣\n" +
+		"ГОСТ 8732-78;Трубы стальные бесшовные;1978-01-01;действующий\n")
+
+	gosts, err := parser.ParseCSVData(data)
+	if err != nil {
+		t.Fatalf("ParseCSVData failed: %v", err)
+	}
+	if len(gosts) != 2 {
+		t.Fatalf("Expected 2 GOSTs, got %d", len(gosts))
+	}
+	if !strings.HasPrefix(gosts[0].GostNumber, "ГОСТ 7798-") || gosts[0].Title != "Болты с шестигранной головкой" {
+		t.Errorf("Expected repaired row, got %q %q", gosts[0].GostNumber, gosts[0].Title)
+	}
+	if gosts[1].Title != "Трубы стальные бесшовные" {
+		t.Errorf("Correct row must stay unchanged, got %q", gosts[1].Title)
+	}
+}
@@ -1,6 +1,7 @@
 package importer
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -8,22 +9,98 @@ import (
 	"time"
 
 	"httpserver/database"
+	"httpserver/normalization"
+)
+
+// Справочники, коды которых проверяются при импорте номенклатур
+const (
+	ReferenceBookOKPD2 = "okpd2"
+	ReferenceBookTNVED = "tnved"
+)
+
+// MissingReferenceMode определяет, что делать с кодом ОКПД2/ТН ВЭД, отсутствующим в справочнике
+type MissingReferenceMode string
+
+const (
+	// MissingReferenceStub создает запись-заглушку с source = gisp_inline и связывает с ней номенклатуру
+	MissingReferenceStub MissingReferenceMode = "stub"
+	// MissingReferenceWarn оставляет связь пустой и сохраняет код в атрибутах номенклатуры (missing_reference_codes)
+	MissingReferenceWarn MissingReferenceMode = "warn"
 )
 
 // NomenclatureImporter импортер для загрузки эталонов номенклатур из реестра gisp.gov.ru
 type NomenclatureImporter struct {
-	db *database.ServiceDB
+	db                   *database.ServiceDB
+	failedRowsPath       string               // Путь к файлу для записи строк с ошибками (пусто - не записывать)
+	missingReferenceMode MissingReferenceMode // Обработка кодов, отсутствующих в справочниках
+}
+
+// nomenclatureImportOutcome результат импорта одной записи номенклатуры
+type nomenclatureImportOutcome struct {
+	updated           bool              // Эталон обновлен (false - создан новый)
+	benchmarkID       int               // ID созданного или обновленного эталона
+	manufacturerMatch string            // Ключ связи с производителем (database.ManufacturerMatch*)
+	stubReferences    []string          // Справочники, в которых созданы заглушки
+	missingReferences map[string]string // Справочник -> код, отсутствующий в нем (режим MissingReferenceWarn)
 }
 
 // NewNomenclatureImporter создает новый импортер номенклатур
 func NewNomenclatureImporter(db *database.ServiceDB) *NomenclatureImporter {
-	return &NomenclatureImporter{db: db}
+	return &NomenclatureImporter{db: db, missingReferenceMode: MissingReferenceStub}
+}
+
+// SetMissingReferenceMode задает обработку кодов ОКПД2/ТН ВЭД, отсутствующих в справочниках
+// (по умолчанию MissingReferenceStub)
+func (ni *NomenclatureImporter) SetMissingReferenceMode(mode MissingReferenceMode) {
+	ni.missingReferenceMode = mode
+}
+
+// SetFailedRowsFile включает запись строк с ошибками импорта в указанный файл.
+// Файл можно прочитать через ReadFailedRowsFile и передать в RetryFailedRows,
+// чтобы повторно импортировать только эти строки.
+func (ni *NomenclatureImporter) SetFailedRowsFile(path string) {
+	ni.failedRowsPath = path
 }
 
 // ImportNomenclatures импортирует номенклатуры из реестра в базу эталонов
 func (ni *NomenclatureImporter) ImportNomenclatures(records []NomenclatureRecord, projectID int) (*ImportResult, error) {
+	return ni.ImportNomenclaturesContext(context.Background(), records, projectID, nil)
+}
+
+// ImportNomenclaturesContext импортирует номенклатуры с возможностью отмены и отслеживания прогресса.
+// progressCallback (может быть nil) вызывается после каждой обработанной записи.
+// При отмене ctx импорт останавливается перед следующей записью и возвращает частичный
+// результат с Cancelled = true вместе с ошибкой контекста.
+func (ni *NomenclatureImporter) ImportNomenclaturesContext(
+	ctx context.Context,
+	records []NomenclatureRecord,
+	projectID int,
+	progressCallback func(processed, total int),
+) (*ImportResult, error) {
+	rows := make([]FailedNomenclatureRow, len(records))
+	for idx, record := range records {
+		rows[idx] = FailedNomenclatureRow{Row: idx + 1, Record: record}
+	}
+	return ni.importRows(ctx, rows, projectID, progressCallback)
+}
+
+// RetryFailedRows повторно импортирует строки из файла ошибок предыдущего импорта.
+// В сообщениях об ошибках сохраняются номера строк исходного файла.
+func (ni *NomenclatureImporter) RetryFailedRows(rows []FailedNomenclatureRow, projectID int) (*ImportResult, error) {
+	return ni.importRows(context.Background(), rows, projectID, nil)
+}
+
+// importRows импортирует строки и, если задан файл ошибок, записывает в него неимпортированные строки.
+// При отмене в файл попадают и строки, до которых импорт не дошел, поэтому после отмены
+// повтора (RetryFailedRows) необработанные строки предыдущего файла не теряются.
+func (ni *NomenclatureImporter) importRows(
+	ctx context.Context,
+	rows []FailedNomenclatureRow,
+	projectID int,
+	progressCallback func(processed, total int),
+) (*ImportResult, error) {
 	result := &ImportResult{
-		Total:   len(records),
+		Total:   len(rows),
 		Success: 0,
 		Updated: 0,
 		Errors:  make([]string, 0),
@@ -32,49 +109,95 @@ func (ni *NomenclatureImporter) ImportNomenclatures(records []NomenclatureRecord
 
 	// Логируем прогресс каждые 100 записей
 	logInterval := 100
-	if len(records) > 1000 {
+	if len(rows) > 1000 {
 		logInterval = 500
 	}
 
-	for idx, record := range records {
-		wasUpdated, err := ni.importNomenclature(record, projectID)
+	// Системный проект с глобальными производителями получаем один раз на импорт, а не для каждой строки
+	systemProject, err := ni.db.GetOrCreateSystemProject()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get system project: %w", err)
+	}
+
+	failedRows := make([]FailedNomenclatureRow, 0)
+	for idx, row := range rows {
+		if ctx.Err() != nil {
+			result.Cancelled = true
+			failedRows = append(failedRows, rows[idx:]...)
+			break
+		}
+
+		record := row.Record
+		outcome, err := ni.importNomenclature(record, projectID, systemProject.ID)
 		if err != nil {
 			result.Errors = append(result.Errors,
-				fmt.Sprintf("Row %d: %s (Производитель: %s): %v", idx+1, record.ProductName, record.ManufacturerName, err))
+				fmt.Sprintf("Row %d: %s (Производитель: %s): %v", row.Row, record.ProductName, record.ManufacturerName, err))
+			failedRows = append(failedRows, FailedNomenclatureRow{Row: row.Row, Record: record, Error: err.Error()})
 		} else {
 			result.Success++
-			if wasUpdated {
+			if outcome.updated {
 				result.Updated++
 			}
+			result.addOutcome(outcome)
 		}
 
 		// Логируем прогресс
 		if (idx+1)%logInterval == 0 {
-			log.Printf("Processed %d/%d records (%.1f%%)", idx+1, len(records), float64(idx+1)/float64(len(records))*100)
+			log.Printf("Processed %d/%d records (%.1f%%)", idx+1, len(rows), float64(idx+1)/float64(len(rows))*100)
+		}
+		if progressCallback != nil {
+			progressCallback(idx+1, len(rows))
 		}
 	}
 
 	result.Completed = time.Now()
 	result.Duration = result.Completed.Sub(result.Started)
 
-	log.Printf("Import completed: %d/%d successful, %d updated, %d errors",
-		result.Success, result.Total, result.Updated, len(result.Errors))
+	if result.Cancelled {
+		log.Printf("Import cancelled: %d/%d successful, %d updated, %d errors",
+			result.Success, result.Total, result.Updated, len(result.Errors))
+	} else {
+		log.Printf("Import completed: %d/%d successful, %d updated, %d errors",
+			result.Success, result.Total, result.Updated, len(result.Errors))
+	}
+	log.Printf("Manufacturer matches: INN %d, OGRN %d, none %d",
+		result.ManufacturerMatches[database.ManufacturerMatchINN],
+		result.ManufacturerMatches[database.ManufacturerMatchOGRN],
+		result.ManufacturerMatches[database.ManufacturerMatchNone])
+	for _, book := range []string{ReferenceBookOKPD2, ReferenceBookTNVED} {
+		if result.StubReferences[book] > 0 || len(result.MissingReferenceCodes[book]) > 0 {
+			log.Printf("Reference %s: %d stubs created (%s), %d codes missing",
+				book, result.StubReferences[book], database.ReferenceSourceGISPInline, len(result.MissingReferenceCodes[book]))
+		}
+	}
+
+	// Файл перезаписывается всегда, чтобы после успешного повтора в нем не оставались старые строки
+	if ni.failedRowsPath != "" {
+		if err := WriteFailedRowsFile(ni.failedRowsPath, failedRows); err != nil {
+			return result, err
+		}
+	}
+
+	if result.Cancelled {
+		return result, ctx.Err()
+	}
 
 	return result, nil
 }
 
-// importNomenclature импортирует одну запись номенклатуры
-// Возвращает true, если эталон был обновлен, false если создан новый
-func (ni *NomenclatureImporter) importNomenclature(record NomenclatureRecord, projectID int) (bool, error) {
-	if record.ProductName == "" {
-		return false, fmt.Errorf("product name is required")
+// importNomenclature импортирует одну запись номенклатуры.
+// systemProjectID - проект глобальных производителей для поиска по ОГРН (см. findOrCreateManufacturer).
+func (ni *NomenclatureImporter) importNomenclature(record NomenclatureRecord, projectID, systemProjectID int) (*nomenclatureImportOutcome, error) {
+	if strings.TrimSpace(record.ProductName) == "" {
+		return nil, fmt.Errorf("product name is required: %w", database.ErrInvalidName)
 	}
 
 	// Находим или создаем производителя
-	manufacturerBenchmark, err := ni.findOrCreateManufacturer(record, projectID)
+	manufacturerBenchmark, matchType, err := ni.findOrCreateManufacturer(record, projectID, systemProjectID)
 	if err != nil {
-		return false, fmt.Errorf("failed to find or create manufacturer: %v", err)
+		return nil, fmt.Errorf("failed to find or create manufacturer: %v", err)
 	}
+	outcome := &nomenclatureImportOutcome{manufacturerMatch: matchType}
 
 	var manufacturerBenchmarkID *int
 	if manufacturerBenchmark != nil {
@@ -89,22 +212,18 @@ func (ni *NomenclatureImporter) importNomenclature(record NomenclatureRecord, pr
 
 	var okpd2RefID *int
 	if hadOKPD2 {
-		refID, err := ni.db.FindOrCreateOKPD2Reference(record.OKPD2, record.ProductName)
-		if err != nil {
-			log.Printf("Warning: failed to find or create OKPD2 reference for %s: %v", record.OKPD2, err)
-		} else if refID != nil {
-			okpd2RefID = refID
-		}
+		okpd2RefID = ni.resolveReference(outcome, ReferenceBookOKPD2, record.OKPD2,
+			ni.db.FindOKPD2ReferenceID,
+			func(code string) (*int, error) { return ni.db.CreateOKPD2InlineReference(code, record.ProductName) })
 	}
 
 	var tnvedRefID *int
 	if hadTNVED {
-		tnvedRef, err := ni.db.FindOrCreateTNVEDReference(record.TNVED, record.ProductName)
-		if err != nil {
-			log.Printf("Warning: failed to find or create TNVED reference for %s: %v", record.TNVED, err)
-		} else if tnvedRef != nil {
-			tnvedRefID = &tnvedRef.ID
-		}
+		tnvedRefID = ni.resolveReference(outcome, ReferenceBookTNVED, record.TNVED,
+			func(code string) (*int, error) { return tnvedReferenceID(ni.db.FindTNVEDReference(code)) },
+			func(code string) (*int, error) {
+				return tnvedReferenceID(ni.db.CreateTNVEDInlineReference(code, record.ProductName))
+			})
 	}
 
 	var tuGostRefID *int
@@ -137,32 +256,41 @@ func (ni *NomenclatureImporter) importNomenclature(record NomenclatureRecord, pr
 		"manufacturer_name":    record.ManufacturerName,
 		"manufacturer_address": record.ActualAddress,
 	}
+	if len(outcome.missingReferences) > 0 {
+		attributes["missing_reference_codes"] = outcome.missingReferences
+	}
 
 	attributesJSON, err := json.Marshal(attributes)
 	if err != nil {
-		return false, fmt.Errorf("failed to marshal attributes: %v", err)
+		return nil, fmt.Errorf("failed to marshal attributes: %v", err)
 	}
 
 	// Нормализуем название номенклатуры
 	normalizedName := strings.TrimSpace(record.ProductName)
 	normalizedName = strings.Join(strings.Fields(normalizedName), " ")
+	keywords := normalization.ExtractKeywords(normalizedName)
 
 	// Проверяем, существует ли уже эталон номенклатуры
 	existing, err := ni.findExistingNomenclature(projectID, normalizedName, manufacturerBenchmarkID)
 	if err != nil {
-		return false, fmt.Errorf("failed to check existing nomenclature: %v", err)
+		return nil, fmt.Errorf("failed to check existing nomenclature: %v", err)
 	}
 
 	if existing != nil {
 		// Обновляем существующий эталон
-		if err := ni.updateNomenclatureBenchmark(existing.ID, record, normalizedName, string(attributesJSON), manufacturerBenchmarkID, okpd2RefID, tnvedRefID, tuGostRefID); err != nil {
-			return false, err
+		if err := ni.updateNomenclatureBenchmark(existing.ID, record, normalizedName, string(attributesJSON), keywords, manufacturerBenchmarkID, okpd2RefID, tnvedRefID, tuGostRefID); err != nil {
+			return nil, err
 		}
 		// Устанавливаем subcategory и source_database
 		if err := ni.db.UpdateBenchmarkFields(existing.ID, "", "gisp_gov_ru"); err != nil {
 			log.Printf("Warning: failed to update benchmark fields: %v", err)
 		}
-		return true, nil
+		if err := ni.db.SetBenchmarkManufacturerMatchType(existing.ID, matchType); err != nil {
+			log.Printf("Warning: failed to set manufacturer match type for benchmark %d: %v", existing.ID, err)
+		}
+		outcome.updated = true
+		outcome.benchmarkID = existing.ID
+		return outcome, nil
 	}
 
 	// Создаем новый эталон номенклатуры
@@ -181,7 +309,17 @@ func (ni *NomenclatureImporter) importNomenclature(record NomenclatureRecord, pr
 	)
 
 	if err != nil {
-		return false, fmt.Errorf("failed to create nomenclature benchmark: %v", err)
+		return nil, fmt.Errorf("failed to create nomenclature benchmark: %v", err)
+	}
+	outcome.benchmarkID = benchmark.ID
+
+	if err := ni.db.SetBenchmarkManufacturerMatchType(benchmark.ID, matchType); err != nil {
+		log.Printf("Warning: failed to set manufacturer match type for benchmark %d: %v", benchmark.ID, err)
+	}
+
+	// Сохраняем ключевые слова для поиска
+	if err := ni.db.UpdateBenchmarkKeywords(benchmark.ID, keywords); err != nil {
+		log.Printf("Warning: failed to update keywords for benchmark %d: %v", benchmark.ID, err)
 	}
 
 	// Утверждаем эталон
@@ -189,39 +327,121 @@ func (ni *NomenclatureImporter) importNomenclature(record NomenclatureRecord, pr
 		log.Printf("Warning: failed to approve benchmark %d: %v", benchmark.ID, err)
 	}
 
-	return false, nil
+	return outcome, nil
 }
 
-// findOrCreateManufacturer находит или создает производителя по данным из реестра
-func (ni *NomenclatureImporter) findOrCreateManufacturer(record NomenclatureRecord, projectID int) (*database.ClientBenchmark, error) {
-	// Сначала пытаемся найти по ИНН
-	var manufacturer *database.ClientBenchmark
-	var err error
+// addOutcome учитывает результат импорта одной номенклатуры в сводных счетчиках
+func (r *ImportResult) addOutcome(outcome *nomenclatureImportOutcome) {
+	if r.ManufacturerMatches == nil {
+		r.ManufacturerMatches = make(map[string]int)
+	}
+	r.ManufacturerMatches[outcome.manufacturerMatch]++
 
-	if record.INN != "" {
-		manufacturer, err = ni.db.FindManufacturerByINN(projectID, record.INN)
-		if err != nil {
-			return nil, err
+	if !outcome.updated && outcome.benchmarkID > 0 {
+		if r.CreatedMinID == 0 || outcome.benchmarkID < r.CreatedMinID {
+			r.CreatedMinID = outcome.benchmarkID
+		}
+		if outcome.benchmarkID > r.CreatedMaxID {
+			r.CreatedMaxID = outcome.benchmarkID
+		}
+	}
+
+	for _, book := range outcome.stubReferences {
+		if r.StubReferences == nil {
+			r.StubReferences = make(map[string]int)
+		}
+		r.StubReferences[book]++
+	}
+
+	for book, code := range outcome.missingReferences {
+		if r.MissingReferenceCodes == nil {
+			r.MissingReferenceCodes = make(map[string]map[string]int)
+		}
+		if r.MissingReferenceCodes[book] == nil {
+			r.MissingReferenceCodes[book] = make(map[string]int)
+		}
+		r.MissingReferenceCodes[book][code]++
+	}
+}
+
+// resolveReference ищет код в справочнике; отсутствующий код в зависимости от режима
+// создается заглушкой или записывается в outcome.missingReferences
+func (ni *NomenclatureImporter) resolveReference(
+	outcome *nomenclatureImportOutcome,
+	book, code string,
+	find func(code string) (*int, error),
+	createStub func(code string) (*int, error),
+) *int {
+	code = strings.TrimSpace(code)
+
+	refID, err := find(code)
+	if err != nil {
+		log.Printf("Warning: failed to find %s reference for %s: %v", book, code, err)
+		return nil
+	}
+	if refID != nil {
+		return refID
+	}
+
+	if ni.missingReferenceMode == MissingReferenceWarn {
+		if outcome.missingReferences == nil {
+			outcome.missingReferences = make(map[string]string)
 		}
+		outcome.missingReferences[book] = code
+		return nil
+	}
+
+	refID, err = createStub(code)
+	if err != nil {
+		log.Printf("Warning: failed to create %s reference stub for %s: %v", book, code, err)
+		return nil
+	}
+	if refID != nil {
+		outcome.stubReferences = append(outcome.stubReferences, book)
+	}
+	return refID
+}
+
+// tnvedReferenceID возвращает ID записи ТН ВЭД (nil, если записи нет)
+func tnvedReferenceID(ref *database.TNVEDReference, err error) (*int, error) {
+	if err != nil || ref == nil {
+		return nil, err
 	}
+	return &ref.ID, nil
+}
 
-	// Если не нашли по ИНН, пытаемся найти по ОГРН
-	if manufacturer == nil && record.OGRN != "" {
-		manufacturer, err = ni.db.FindManufacturerByOGRN(projectID, record.OGRN)
+// findOrCreateManufacturer находит или создает производителя по данным из реестра.
+// Возвращает также ключ, по которому найден производитель (database.ManufacturerMatch*);
+// для созданного производителя или его отсутствия - ManufacturerMatchNone.
+func (ni *NomenclatureImporter) findOrCreateManufacturer(record NomenclatureRecord, projectID, systemProjectID int) (*database.ClientBenchmark, string, error) {
+	// Сначала пытаемся найти по ИНН
+	inn := strings.TrimSpace(record.INN)
+	if inn != "" {
+		manufacturer, err := ni.db.FindManufacturerByINN(projectID, inn)
 		if err != nil {
-			return nil, err
+			return nil, "", err
+		}
+		if manufacturer != nil {
+			return manufacturer, database.ManufacturerMatchINN, nil
 		}
 	}
 
-	// Если производитель найден, возвращаем его
-	if manufacturer != nil {
-		return manufacturer, nil
+	// Если не нашли по ИНН, пытаемся найти по ОГРН (в проекте, затем в системном проекте)
+	ogrn := strings.TrimSpace(record.OGRN)
+	if ogrn != "" {
+		manufacturer, err := ni.db.FindManufacturerByOGRNWithFallback(projectID, systemProjectID, ogrn)
+		if err != nil {
+			return nil, "", err
+		}
+		if manufacturer != nil {
+			return manufacturer, database.ManufacturerMatchOGRN, nil
+		}
 	}
 
 	// Если производитель не найден, создаем его
-	if record.ManufacturerName == "" {
+	if strings.TrimSpace(record.ManufacturerName) == "" {
 		// Если нет названия производителя, возвращаем nil (без ошибки)
-		return nil, nil
+		return nil, database.ManufacturerMatchNone, nil
 	}
 
 	// Нормализуем название производителя
@@ -238,11 +458,11 @@ func (ni *NomenclatureImporter) findOrCreateManufacturer(record NomenclatureReco
 
 	attributesJSON, err := json.Marshal(manufacturerAttributes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal manufacturer attributes: %v", err)
+		return nil, "", fmt.Errorf("failed to marshal manufacturer attributes: %v", err)
 	}
 
 	// Создаем нового производителя
-	manufacturer, err = ni.db.CreateCounterpartyBenchmark(
+	manufacturer, err := ni.db.CreateCounterpartyBenchmark(
 		projectID,
 		record.ManufacturerName, // original_name
 		normalizedName,           // normalized_name
@@ -265,7 +485,7 @@ func (ni *NomenclatureImporter) findOrCreateManufacturer(record NomenclatureReco
 	)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to create manufacturer: %v", err)
+		return nil, "", fmt.Errorf("failed to create manufacturer: %v", err)
 	}
 
 	// Обновляем subcategory, attributes и source_database
@@ -283,7 +503,7 @@ func (ni *NomenclatureImporter) findOrCreateManufacturer(record NomenclatureReco
 		log.Printf("Warning: failed to approve manufacturer benchmark %d: %v", manufacturer.ID, err)
 	}
 
-	return manufacturer, nil
+	return manufacturer, database.ManufacturerMatchNone, nil
 }
 
 // findExistingNomenclature ищет существующий эталон номенклатуры
@@ -311,12 +531,13 @@ func (ni *NomenclatureImporter) findExistingNomenclature(projectID int, normaliz
 }
 
 // updateNomenclatureBenchmark обновляет существующий эталон номенклатуры
-func (ni *NomenclatureImporter) updateNomenclatureBenchmark(benchmarkID int, record NomenclatureRecord, normalizedName, attributes string, manufacturerBenchmarkID, okpd2RefID, tnvedRefID, tuGostRefID *int) error {
+func (ni *NomenclatureImporter) updateNomenclatureBenchmark(benchmarkID int, record NomenclatureRecord, normalizedName, attributes string, keywords []string, manufacturerBenchmarkID, okpd2RefID, tnvedRefID, tuGostRefID *int) error {
 	query := `
 		UPDATE client_benchmarks
 		SET original_name = ?,
 		    normalized_name = ?,
 		    attributes = ?,
+		    keywords = ?,
 		    manufacturer_benchmark_id = ?,
 		    okpd2_reference_id = ?,
 		    tnved_reference_id = ?,
@@ -330,6 +551,7 @@ func (ni *NomenclatureImporter) updateNomenclatureBenchmark(benchmarkID int, rec
 		record.ProductName,
 		normalizedName,
 		attributes,
+		strings.Join(keywords, " "),
 		manufacturerBenchmarkID,
 		okpd2RefID,
 		tnvedRefID,
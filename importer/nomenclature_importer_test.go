@@ -1,6 +1,9 @@
 package importer
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"path/filepath"
 	"testing"
 	"time"
@@ -128,13 +131,22 @@ func TestImportResult(t *testing.T) {
 	}
 }
 
-// TestImportNomenclatures_ValidRecord проверяет импорт валидной записи
+// TestImportNomenclatures_ValidRecord проверяет импорт валидной записи и диапазон ID созданных строк
 func TestImportNomenclatures_ValidRecord(t *testing.T) {
 	serviceDB := setupTestServiceDB(t)
 	defer serviceDB.Close()
-	
+
+	client, err := serviceDB.CreateClient("Import Client", "Import Client LLC", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Import Project", "nomenclature", "", "gisp", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
 	importer := NewNomenclatureImporter(serviceDB)
-	
+
 	records := []NomenclatureRecord{
 		{
 			ManufacturerName: "Test Manufacturer",
@@ -142,20 +154,544 @@ func TestImportNomenclatures_ValidRecord(t *testing.T) {
 			INN:              "1234567890",
 		},
 	}
-	
-	result, err := importer.ImportNomenclatures(records, 1)
-	// Может быть ошибка из-за отсутствия схемы БД - это нормально для unit тестов
+
+	result, err := importer.ImportNomenclatures(records, project.ID)
 	if err != nil {
-		t.Logf("ImportNomenclatures() returned error (may be expected): %v", err)
-		return
+		t.Fatalf("ImportNomenclatures() failed: %v", err)
+	}
+	if result.Total != 1 || result.Success != 1 || result.Updated != 0 {
+		t.Fatalf("ImportNomenclatures() Total = %d, Success = %d, Updated = %d, want 1, 1, 0 (errors: %v)",
+			result.Total, result.Success, result.Updated, result.Errors)
+	}
+	if result.CreatedMinID == 0 || result.CreatedMinID != result.CreatedMaxID {
+		t.Errorf("Expected id range of the created row, got %d-%d", result.CreatedMinID, result.CreatedMaxID)
+	}
+
+	// Повторный импорт обновляет эталон и не создает новых строк
+	again, err := importer.ImportNomenclatures(records, project.ID)
+	if err != nil {
+		t.Fatalf("Repeated ImportNomenclatures() failed: %v", err)
+	}
+	if again.Success != 1 || again.Updated != 1 {
+		t.Fatalf("Repeated ImportNomenclatures() Success = %d, Updated = %d, want 1, 1", again.Success, again.Updated)
+	}
+	if again.CreatedMinID != 0 || again.CreatedMaxID != 0 {
+		t.Errorf("Expected no created rows on re-import, got %d-%d", again.CreatedMinID, again.CreatedMaxID)
+	}
+}
+
+
+// TestImportNomenclatures_RetryFailedRows проверяет запись строк с ошибками в файл
+// и повторный импорт только этих строк
+func TestImportNomenclatures_RetryFailedRows(t *testing.T) {
+	serviceDB := setupTestServiceDB(t)
+	defer serviceDB.Close()
+
+	if err := database.MigrateBenchmarkManufacturerLink(serviceDB.GetConnection()); err != nil {
+		t.Fatalf("Failed to run manufacturer link migration: %v", err)
+	}
+	if err := database.CreateReferenceBooksTables(serviceDB.GetConnection()); err != nil {
+		t.Fatalf("Failed to create reference books tables: %v", err)
+	}
+	if err := database.MigrateBenchmarkReferenceLinks(serviceDB.GetConnection()); err != nil {
+		t.Fatalf("Failed to run reference links migration: %v", err)
+	}
+
+	systemProject, err := serviceDB.GetOrCreateSystemProject()
+	if err != nil {
+		t.Fatalf("Failed to get system project: %v", err)
+	}
+
+	failedPath := filepath.Join(t.TempDir(), "gisp.failed.jsonl")
+	importer := NewNomenclatureImporter(serviceDB)
+	importer.SetFailedRowsFile(failedPath)
+
+	records := []NomenclatureRecord{
+		{ProductName: "Болт М10", RegistryNumber: "10001"},
+		{ProductName: "", RegistryNumber: "10002", Conclusion: "Заключение №2"},
+		{ProductName: "Гайка М10", RegistryNumber: "10003"},
+	}
+
+	result, err := importer.ImportNomenclatures(records, systemProject.ID)
+	if err != nil {
+		t.Fatalf("ImportNomenclatures() failed: %v", err)
+	}
+	if result.Success != 2 || len(result.Errors) != 1 {
+		t.Fatalf("ImportNomenclatures() Success = %d, Errors = %v, want 2 successes and 1 error", result.Success, result.Errors)
+	}
+
+	failedRows, err := ReadFailedRowsFile(failedPath)
+	if err != nil {
+		t.Fatalf("ReadFailedRowsFile() failed: %v", err)
+	}
+	if len(failedRows) != 1 {
+		t.Fatalf("Failed rows file contains %d rows, want 1", len(failedRows))
+	}
+	if failedRows[0].Row != 2 {
+		t.Errorf("Failed row number = %d, want 2", failedRows[0].Row)
+	}
+	if failedRows[0].Record.RegistryNumber != "10002" || failedRows[0].Record.Conclusion != "Заключение №2" {
+		t.Errorf("Failed row does not contain the original record: %+v", failedRows[0].Record)
+	}
+	if failedRows[0].Error == "" {
+		t.Error("Failed row does not contain the error")
+	}
+
+	// Исправляем запись и повторяем импорт только строк с ошибками
+	failedRows[0].Record.ProductName = "Шайба М10"
+	retryResult, err := importer.RetryFailedRows(failedRows, systemProject.ID)
+	if err != nil {
+		t.Fatalf("RetryFailedRows() failed: %v", err)
+	}
+	if retryResult.Total != 1 || retryResult.Success != 1 {
+		t.Errorf("RetryFailedRows() Total = %d, Success = %d, want 1 and 1", retryResult.Total, retryResult.Success)
+	}
+
+	remaining, err := ReadFailedRowsFile(failedPath)
+	if err != nil {
+		t.Fatalf("ReadFailedRowsFile() after retry failed: %v", err)
+	}
+	if len(remaining) != 0 {
+		t.Errorf("Failed rows file should be empty after successful retry, got %d rows", len(remaining))
+	}
+
+	benchmarks, err := serviceDB.GetClientBenchmarks(systemProject.ID, "nomenclature", false)
+	if err != nil {
+		t.Fatalf("GetClientBenchmarks() failed: %v", err)
+	}
+	if len(benchmarks) != 3 {
+		t.Errorf("Expected 3 nomenclature benchmarks after retry, got %d", len(benchmarks))
+	}
+}
+
+// TestImportNomenclaturesContext_CancelMidImport проверяет, что отмена контекста из колбэка прогресса
+// останавливает импорт и возвращает частичный результат
+func TestImportNomenclaturesContext_CancelMidImport(t *testing.T) {
+	serviceDB := setupTestServiceDB(t)
+	defer serviceDB.Close()
+
+	importer := NewNomenclatureImporter(serviceDB)
+
+	// Записи без наименования отклоняются без обращения к БД, поэтому схема не нужна
+	records := make([]NomenclatureRecord, 10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var calls []int
+	result, err := importer.ImportNomenclaturesContext(ctx, records, 1, func(processed, total int) {
+		calls = append(calls, processed)
+		if total != len(records) {
+			t.Errorf("progress total = %d, want %d", total, len(records))
+		}
+		if processed == 3 {
+			cancel()
+		}
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("ImportNomenclaturesContext() error = %v, want context.Canceled", err)
 	}
-	
 	if result == nil {
-		t.Fatal("ImportNomenclatures() returned nil result")
+		t.Fatal("ImportNomenclaturesContext() returned nil result on cancellation")
 	}
-	
-	if result.Total != 1 {
-		t.Errorf("ImportNomenclatures() Total = %d, want 1", result.Total)
+	if !result.Cancelled {
+		t.Error("ImportNomenclaturesContext() result should be marked Cancelled")
+	}
+	if result.Total != len(records) {
+		t.Errorf("result.Total = %d, want %d", result.Total, len(records))
+	}
+	if len(result.Errors) != 3 {
+		t.Errorf("Expected 3 processed rows before cancellation, got %d errors", len(result.Errors))
+	}
+	if len(calls) != 3 || calls[len(calls)-1] != 3 {
+		t.Errorf("progress callback calls = %v, want [1 2 3]", calls)
+	}
+
+	// Без отмены импорт обрабатывает все записи и не помечается прерванным
+	full, err := importer.ImportNomenclatures(records, 1)
+	if err != nil {
+		t.Fatalf("ImportNomenclatures() failed: %v", err)
+	}
+	if full.Cancelled || len(full.Errors) != len(records) {
+		t.Errorf("ImportNomenclatures() Cancelled = %v, Errors = %d, want false and %d", full.Cancelled, len(full.Errors), len(records))
 	}
 }
 
+// TestImportRows_CancelKeepsUnattemptedRows проверяет, что при отмене повтора в файле ошибок
+// остаются и строки, до которых импорт не дошел
+func TestImportRows_CancelKeepsUnattemptedRows(t *testing.T) {
+	serviceDB := setupTestServiceDB(t)
+	defer serviceDB.Close()
+
+	failedPath := filepath.Join(t.TempDir(), "gisp.failed.jsonl")
+	importer := NewNomenclatureImporter(serviceDB)
+	importer.SetFailedRowsFile(failedPath)
+
+	// Записи без наименования отклоняются без обращения к БД, поэтому схема не нужна
+	rows := make([]FailedNomenclatureRow, 5)
+	for idx := range rows {
+		rows[idx] = FailedNomenclatureRow{Row: (idx + 1) * 10, Error: "previous error"}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := importer.importRows(ctx, rows, 1, func(processed, total int) {
+		if processed == 2 {
+			cancel()
+		}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("importRows() error = %v, want context.Canceled", err)
+	}
+
+	remaining, err := ReadFailedRowsFile(failedPath)
+	if err != nil {
+		t.Fatalf("ReadFailedRowsFile() failed: %v", err)
+	}
+	if len(remaining) != len(rows) {
+		t.Fatalf("Failed rows file contains %d rows, want %d", len(remaining), len(rows))
+	}
+	for idx, row := range remaining {
+		if row.Row != rows[idx].Row {
+			t.Errorf("Failed row %d number = %d, want %d", idx, row.Row, rows[idx].Row)
+		}
+	}
+	if remaining[4].Error != "previous error" {
+		t.Errorf("Unattempted row should keep its previous error, got %q", remaining[4].Error)
+	}
+}
+
+// TestImportNomenclatures_SkipsEmptyNames проверяет, что записи с пустыми наименованиями
+// не создают эталонов и учитываются в ошибках импорта
+func TestImportNomenclatures_SkipsEmptyNames(t *testing.T) {
+	serviceDB := setupTestServiceDB(t)
+	defer serviceDB.Close()
+
+	client, err := serviceDB.CreateClient("Import Client", "Import Client LLC", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Import Project", "nomenclature", "", "gisp", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	records := []NomenclatureRecord{
+		{ProductName: "   ", ManufacturerName: "ООО Завод", INN: "7700000001"},
+		{ProductName: "\t", ManufacturerName: "ООО Завод", INN: "7700000001"},
+		{ProductName: "Болт М10х40", ManufacturerName: "   ", INN: "7700000002"},
+	}
+
+	result, err := NewNomenclatureImporter(serviceDB).ImportNomenclatures(records, project.ID)
+	if err != nil {
+		t.Fatalf("ImportNomenclatures() failed: %v", err)
+	}
+	if result.Success != 1 {
+		t.Errorf("ImportNomenclatures() Success = %d, want 1", result.Success)
+	}
+	if len(result.Errors) != 2 {
+		t.Errorf("ImportNomenclatures() Errors = %v, want 2 errors", result.Errors)
+	}
+
+	benchmarks, err := serviceDB.GetClientBenchmarks(project.ID, "", false)
+	if err != nil {
+		t.Fatalf("Failed to get benchmarks: %v", err)
+	}
+	// Производитель с пустым наименованием не создается, номенклатура создается без него
+	if len(benchmarks) != 1 || benchmarks[0].OriginalName != "Болт М10х40" {
+		t.Errorf("Expected only the valid nomenclature benchmark, got %+v", benchmarks)
+	}
+}
+
+// TestImportManufacturers_SkipsEmptyNames проверяет, что производители без наименования
+// учитываются в ошибках импорта перечня
+func TestImportManufacturers_SkipsEmptyNames(t *testing.T) {
+	serviceDB := setupTestServiceDB(t)
+	defer serviceDB.Close()
+
+	client, err := serviceDB.CreateClient("Import Client", "Import Client LLC", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Import Project", "counterparty", "", "perechen", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	records := []ManufacturerRecord{
+		{Name: "", INN: "7700000001"},
+		{Name: "  ", INN: "7700000002"},
+		{Name: "ООО Завод", INN: "7700000003"},
+	}
+
+	result, err := NewReferenceImporter(serviceDB).ImportManufacturers(records, project.ID)
+	if err != nil {
+		t.Fatalf("ImportManufacturers() failed: %v", err)
+	}
+	if result.Success != 1 {
+		t.Errorf("ImportManufacturers() Success = %d, want 1", result.Success)
+	}
+	if len(result.Errors) != 2 {
+		t.Errorf("ImportManufacturers() Errors = %v, want 2 errors", result.Errors)
+	}
+}
+
+// setupManufacturerMatchTest создает клиентский проект и производителей:
+// один в проекте (ИНН и ОГРН), другой только в системном проекте (найти можно лишь по ОГРН)
+func setupManufacturerMatchTest(t *testing.T) (*database.ServiceDB, int, *database.ClientBenchmark, *database.ClientBenchmark) {
+	t.Helper()
+	serviceDB := setupTestServiceDB(t)
+	t.Cleanup(func() { serviceDB.Close() })
+
+	client, err := serviceDB.CreateClient("Клиент", "ООО Клиент", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	systemProject, err := serviceDB.GetOrCreateSystemProject()
+	if err != nil {
+		t.Fatalf("Failed to get system project: %v", err)
+	}
+
+	local, err := serviceDB.CreateCounterpartyBenchmark(project.ID, "ООО Завод", "ООО Завод",
+		"7701000001", "", "", "1027700000001", "", "", "", "", "", "", "", "", "", "", "", 0.95)
+	if err != nil {
+		t.Fatalf("Failed to create project manufacturer: %v", err)
+	}
+	global, err := serviceDB.CreateCounterpartyBenchmark(systemProject.ID, "АО Комбинат", "АО Комбинат",
+		"", "", "", "1027700000002", "", "", "", "", "", "", "", "", "", "", "", 0.95)
+	if err != nil {
+		t.Fatalf("Failed to create global manufacturer: %v", err)
+	}
+
+	// Как и импортер, заполняем атрибуты созданных производителей
+	for _, m := range []*database.ClientBenchmark{local, global} {
+		if err := serviceDB.UpdateBenchmark(m.ID, m.OriginalName, m.NormalizedName, m.OGRN, "", `{}`, 0.95); err != nil {
+			t.Fatalf("Failed to update manufacturer %d: %v", m.ID, err)
+		}
+	}
+
+	return serviceDB, project.ID, local, global
+}
+
+// getManufacturerLink возвращает производителя и ключ связи номенклатуры с указанным названием
+func getManufacturerLink(t *testing.T, serviceDB *database.ServiceDB, projectID int, name string) (*int, string) {
+	t.Helper()
+	var manufacturerID *int
+	var matchType *string
+	err := serviceDB.GetConnection().QueryRow(`
+		SELECT manufacturer_benchmark_id, manufacturer_match_type
+		FROM client_benchmarks
+		WHERE client_project_id = ? AND category = 'nomenclature' AND normalized_name = ?
+	`, projectID, name).Scan(&manufacturerID, &matchType)
+	if err != nil {
+		t.Fatalf("Failed to read nomenclature %q: %v", name, err)
+	}
+	if matchType == nil {
+		return manufacturerID, ""
+	}
+	return manufacturerID, *matchType
+}
+
+// TestImportNomenclatures_ManufacturerMatchByINN проверяет связь с производителем по ИНН
+func TestImportNomenclatures_ManufacturerMatchByINN(t *testing.T) {
+	serviceDB, projectID, local, _ := setupManufacturerMatchTest(t)
+
+	result, err := NewNomenclatureImporter(serviceDB).ImportNomenclatures([]NomenclatureRecord{
+		{ProductName: "Болт М12", ManufacturerName: "ООО Завод", INN: "7701000001"},
+	}, projectID)
+	if err != nil {
+		t.Fatalf("ImportNomenclatures() failed: %v", err)
+	}
+
+	manufacturerID, matchType := getManufacturerLink(t, serviceDB, projectID, "Болт М12")
+	if manufacturerID == nil || *manufacturerID != local.ID {
+		t.Errorf("manufacturer_benchmark_id = %v, want %d", manufacturerID, local.ID)
+	}
+	if matchType != database.ManufacturerMatchINN {
+		t.Errorf("manufacturer_match_type = %q, want %q", matchType, database.ManufacturerMatchINN)
+	}
+	if result.ManufacturerMatches[database.ManufacturerMatchINN] != 1 {
+		t.Errorf("ManufacturerMatches = %v, want 1 INN match", result.ManufacturerMatches)
+	}
+}
+
+// TestImportNomenclatures_ManufacturerMatchByOGRN проверяет связь по ОГРН, в том числе
+// с производителем из системного проекта, когда ИНН в строке отсутствует
+func TestImportNomenclatures_ManufacturerMatchByOGRN(t *testing.T) {
+	serviceDB, projectID, local, global := setupManufacturerMatchTest(t)
+
+	result, err := NewNomenclatureImporter(serviceDB).ImportNomenclatures([]NomenclatureRecord{
+		{ProductName: "Гайка М12", ManufacturerName: "ООО Завод", OGRN: "1027700000001"},
+		{ProductName: "Шайба М12", ManufacturerName: "АО Комбинат", OGRN: "1027700000002"},
+	}, projectID)
+	if err != nil {
+		t.Fatalf("ImportNomenclatures() failed: %v", err)
+	}
+
+	for name, wantID := range map[string]int{"Гайка М12": local.ID, "Шайба М12": global.ID} {
+		manufacturerID, matchType := getManufacturerLink(t, serviceDB, projectID, name)
+		if manufacturerID == nil || *manufacturerID != wantID {
+			t.Errorf("%s: manufacturer_benchmark_id = %v, want %d", name, manufacturerID, wantID)
+		}
+		if matchType != database.ManufacturerMatchOGRN {
+			t.Errorf("%s: manufacturer_match_type = %q, want %q", name, matchType, database.ManufacturerMatchOGRN)
+		}
+	}
+	if result.ManufacturerMatches[database.ManufacturerMatchOGRN] != 2 {
+		t.Errorf("ManufacturerMatches = %v, want 2 OGRN matches", result.ManufacturerMatches)
+	}
+}
+
+// TestImportNomenclatures_ManufacturerNoMatch проверяет строки без совпадений по ИНН и ОГРН
+func TestImportNomenclatures_ManufacturerNoMatch(t *testing.T) {
+	serviceDB, projectID, local, global := setupManufacturerMatchTest(t)
+
+	result, err := NewNomenclatureImporter(serviceDB).ImportNomenclatures([]NomenclatureRecord{
+		{ProductName: "Винт М12", ManufacturerName: "ИП Новый", INN: "7701000009", OGRN: "1027700000009"},
+		{ProductName: "Шпилька М12"},
+	}, projectID)
+	if err != nil {
+		t.Fatalf("ImportNomenclatures() failed: %v", err)
+	}
+
+	// Для неизвестного производителя создается новый эталон
+	manufacturerID, matchType := getManufacturerLink(t, serviceDB, projectID, "Винт М12")
+	if manufacturerID == nil || *manufacturerID == local.ID || *manufacturerID == global.ID {
+		t.Errorf("Expected a newly created manufacturer, got %v", manufacturerID)
+	}
+	if matchType != database.ManufacturerMatchNone {
+		t.Errorf("manufacturer_match_type = %q, want %q", matchType, database.ManufacturerMatchNone)
+	}
+
+	manufacturerID, matchType = getManufacturerLink(t, serviceDB, projectID, "Шпилька М12")
+	if manufacturerID != nil {
+		t.Errorf("Expected no manufacturer, got %d", *manufacturerID)
+	}
+	if matchType != database.ManufacturerMatchNone {
+		t.Errorf("manufacturer_match_type = %q, want %q", matchType, database.ManufacturerMatchNone)
+	}
+
+	if result.ManufacturerMatches[database.ManufacturerMatchNone] != 2 {
+		t.Errorf("ManufacturerMatches = %v, want 2 unmatched rows", result.ManufacturerMatches)
+	}
+}
+
+// setupMissingReferenceTest создает системный проект и классификатор ОКПД2 с одним известным кодом
+func setupMissingReferenceTest(t *testing.T) (*database.ServiceDB, int) {
+	t.Helper()
+	serviceDB := setupTestServiceDB(t)
+	t.Cleanup(func() { serviceDB.Close() })
+
+	systemProject, err := serviceDB.GetOrCreateSystemProject()
+	if err != nil {
+		t.Fatalf("Failed to get system project: %v", err)
+	}
+	if _, err := serviceDB.GetConnection().Exec(
+		`INSERT INTO okpd2_classifier (code, name) VALUES ('25.94.11', 'Изделия крепежные')`); err != nil {
+		t.Fatalf("Failed to seed OKPD2 classifier: %v", err)
+	}
+
+	return serviceDB, systemProject.ID
+}
+
+// getReferenceLinks возвращает ссылки на справочники и атрибуты номенклатуры
+func getReferenceLinks(t *testing.T, serviceDB *database.ServiceDB, projectID int, name string) (okpd2ID, tnvedID *int, attributes string) {
+	t.Helper()
+	err := serviceDB.GetConnection().QueryRow(`
+		SELECT okpd2_reference_id, tnved_reference_id, attributes
+		FROM client_benchmarks
+		WHERE client_project_id = ? AND category = 'nomenclature' AND normalized_name = ?
+	`, projectID, name).Scan(&okpd2ID, &tnvedID, &attributes)
+	if err != nil {
+		t.Fatalf("Failed to read nomenclature %q: %v", name, err)
+	}
+	return okpd2ID, tnvedID, attributes
+}
+
+// TestImportNomenclatures_MissingReferenceStub проверяет создание заглушек для кодов, отсутствующих в справочниках
+func TestImportNomenclatures_MissingReferenceStub(t *testing.T) {
+	serviceDB, projectID := setupMissingReferenceTest(t)
+
+	result, err := NewNomenclatureImporter(serviceDB).ImportNomenclatures([]NomenclatureRecord{
+		{ProductName: "Болт М16", OKPD2: "25.94.11"},
+		{ProductName: "Гайка М16", OKPD2: "99.99.99", TNVED: "7318 16"},
+	}, projectID)
+	if err != nil {
+		t.Fatalf("ImportNomenclatures() failed: %v", err)
+	}
+
+	okpd2ID, _, _ := getReferenceLinks(t, serviceDB, projectID, "Болт М16")
+	known, _ := serviceDB.FindOKPD2ReferenceID("25.94.11")
+	if okpd2ID == nil || known == nil || *okpd2ID != *known {
+		t.Errorf("Known OKPD2 code should link to existing row %v, got %v", known, okpd2ID)
+	}
+
+	okpd2ID, tnvedID, _ := getReferenceLinks(t, serviceDB, projectID, "Гайка М16")
+	if okpd2ID == nil || tnvedID == nil {
+		t.Fatalf("Expected links to stub references, got okpd2=%v tnved=%v", okpd2ID, tnvedID)
+	}
+
+	var okpd2Source string
+	if err := serviceDB.GetConnection().QueryRow(`SELECT source FROM okpd2_classifier WHERE id = ?`, *okpd2ID).Scan(&okpd2Source); err != nil {
+		t.Fatalf("Failed to read OKPD2 stub: %v", err)
+	}
+	if okpd2Source != database.ReferenceSourceGISPInline {
+		t.Errorf("OKPD2 stub source = %q, want %q", okpd2Source, database.ReferenceSourceGISPInline)
+	}
+	tnvedRef, err := serviceDB.FindTNVEDReference("731816")
+	if err != nil || tnvedRef == nil || tnvedRef.Source != database.ReferenceSourceGISPInline {
+		t.Errorf("Expected TNVED stub with source %q, got %+v (err %v)", database.ReferenceSourceGISPInline, tnvedRef, err)
+	}
+
+	if result.StubReferences[ReferenceBookOKPD2] != 1 || result.StubReferences[ReferenceBookTNVED] != 1 {
+		t.Errorf("StubReferences = %v, want one stub per book", result.StubReferences)
+	}
+	if len(result.MissingReferenceCodes) != 0 {
+		t.Errorf("MissingReferenceCodes = %v, want none in stub mode", result.MissingReferenceCodes)
+	}
+}
+
+// TestImportNomenclatures_MissingReferenceWarn проверяет учет отсутствующих кодов без создания заглушек
+func TestImportNomenclatures_MissingReferenceWarn(t *testing.T) {
+	serviceDB, projectID := setupMissingReferenceTest(t)
+
+	importer := NewNomenclatureImporter(serviceDB)
+	importer.SetMissingReferenceMode(MissingReferenceWarn)
+	result, err := importer.ImportNomenclatures([]NomenclatureRecord{
+		{ProductName: "Шайба М16", OKPD2: "99.99.99"},
+		{ProductName: "Шплинт М16", OKPD2: "99.99.99"},
+		{ProductName: "Болт М16", OKPD2: "25.94.11"},
+	}, projectID)
+	if err != nil {
+		t.Fatalf("ImportNomenclatures() failed: %v", err)
+	}
+
+	okpd2ID, _, attributes := getReferenceLinks(t, serviceDB, projectID, "Шайба М16")
+	if okpd2ID != nil {
+		t.Errorf("Missing OKPD2 code should leave the link empty, got %d", *okpd2ID)
+	}
+	var attrs struct {
+		MissingReferenceCodes map[string]string `json:"missing_reference_codes"`
+	}
+	if err := json.Unmarshal([]byte(attributes), &attrs); err != nil {
+		t.Fatalf("Failed to parse attributes: %v", err)
+	}
+	if attrs.MissingReferenceCodes[ReferenceBookOKPD2] != "99.99.99" {
+		t.Errorf("Attributes missing_reference_codes = %v, want okpd2 99.99.99", attrs.MissingReferenceCodes)
+	}
+
+	if stub, _ := serviceDB.FindOKPD2ReferenceID("99.99.99"); stub != nil {
+		t.Error("Warn mode should not create OKPD2 stubs")
+	}
+	if got := result.MissingReferenceCodes[ReferenceBookOKPD2]["99.99.99"]; got != 2 {
+		t.Errorf("MissingReferenceCodes = %v, want 2 rows for 99.99.99", result.MissingReferenceCodes)
+	}
+	if okpd2ID, _, _ := getReferenceLinks(t, serviceDB, projectID, "Болт М16"); okpd2ID == nil {
+		t.Error("Known OKPD2 code should still be linked in warn mode")
+	}
+}
@@ -0,0 +1,134 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultTempDir каталог временных файлов импорта (скачанные CSV)
+const DefaultTempDir = "data/temp"
+
+// failedTempSuffix добавляется к имени файла, импорт которого завершился ошибкой
+const failedTempSuffix = ".failed"
+
+// TempFileManager управляет временными файлами импорта.
+// Скачанные файлы называются gost_<источник>_<время>.csv (их же читает cmd/analyze_csv),
+// после успешного импорта удаляются, после ошибки остаются для отладки с пометкой .failed.
+type TempFileManager struct {
+	dir string
+	now func() time.Time
+}
+
+// TempCleanupResult итоги очистки каталога временных файлов
+type TempCleanupResult struct {
+	Removed    []string `json:"removed"`
+	KeptFailed []string `json:"kept_failed"`
+	FreedBytes int64    `json:"freed_bytes"`
+}
+
+// NewTempFileManager создает менеджер временных файлов в каталоге dir (пусто - DefaultTempDir)
+func NewTempFileManager(dir string) *TempFileManager {
+	if dir == "" {
+		dir = DefaultTempDir
+	}
+	return &TempFileManager{dir: dir, now: time.Now}
+}
+
+// Dir возвращает каталог временных файлов
+func (m *TempFileManager) Dir() string {
+	return m.dir
+}
+
+// DownloadPath возвращает имя файла для загрузки из источника
+func (m *TempFileManager) DownloadPath(sourceType string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		default:
+			return '_'
+		}
+	}, sourceType)
+	if name == "" {
+		name = "unknown"
+	}
+	return filepath.Join(m.dir, fmt.Sprintf("gost_%s_%s.csv", name, m.now().Format("20060102_150405")))
+}
+
+// SaveDownload сохраняет скачанные данные во временный файл и возвращает его путь
+func (m *TempFileManager) SaveDownload(sourceType string, data []byte) (string, error) {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	path := m.DownloadPath(sourceType)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write temp file: %w", err)
+	}
+	return path, nil
+}
+
+// Process сохраняет данные во временный файл и вызывает process.
+// При успехе файл удаляется, при ошибке переименовывается в *.failed.csv и остается для отладки.
+func (m *TempFileManager) Process(sourceType string, data []byte, process func(path string) error) error {
+	path, err := m.SaveDownload(sourceType, data)
+	if err != nil {
+		return err
+	}
+
+	if err := process(path); err != nil {
+		failedPath := strings.TrimSuffix(path, ".csv") + failedTempSuffix + ".csv"
+		if renameErr := os.Rename(path, failedPath); renameErr == nil {
+			return fmt.Errorf("%w (data kept in %s)", err, failedPath)
+		}
+		return fmt.Errorf("%w (data kept in %s)", err, path)
+	}
+
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove temp file: %w", err)
+	}
+	return nil
+}
+
+// Clean удаляет файлы каталога старше olderThan.
+// Файлы неудачных импортов сохраняются, если не указан force.
+func (m *TempFileManager) Clean(olderThan time.Duration, force bool) (*TempCleanupResult, error) {
+	result := &TempCleanupResult{Removed: []string{}, KeptFailed: []string{}}
+
+	entries, err := os.ReadDir(m.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read temp directory: %w", err)
+	}
+
+	cutoff := m.now().Add(-olderThan)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+
+		path := filepath.Join(m.dir, entry.Name())
+		if !force && strings.Contains(entry.Name(), failedTempSuffix+".") {
+			result.KeptFailed = append(result.KeptFailed, path)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return result, fmt.Errorf("failed to remove temp file %s: %w", path, err)
+		}
+		result.Removed = append(result.Removed, path)
+		result.FreedBytes += info.Size()
+	}
+
+	return result, nil
+}
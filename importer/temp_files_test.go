@@ -0,0 +1,95 @@
+package importer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTempFileManager_ProcessRemovesFileOnSuccess(t *testing.T) {
+	manager := NewTempFileManager(t.TempDir())
+
+	var seenPath string
+	err := manager.Process("Rosstandart Main", []byte("номер;название\n"), func(path string) error {
+		seenPath = path
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Temp file must exist during import: %v", err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Process failed: %v", err)
+	}
+
+	if base := filepath.Base(seenPath); !strings.HasPrefix(base, "gost_rosstandart_main_") || !strings.HasSuffix(base, ".csv") {
+		t.Errorf("Unexpected temp file name %q", base)
+	}
+	if _, err := os.Stat(seenPath); !os.IsNotExist(err) {
+		t.Errorf("Expected temp file to be removed after successful import, stat err = %v", err)
+	}
+	entries, _ := os.ReadDir(manager.Dir())
+	if len(entries) != 0 {
+		t.Errorf("Expected empty temp directory, got %d files", len(entries))
+	}
+}
+
+func TestTempFileManager_ProcessKeepsFileOnFailure(t *testing.T) {
+	manager := NewTempFileManager(t.TempDir())
+	importErr := errors.New("parse failed")
+
+	err := manager.Process("test", []byte("broken"), func(string) error { return importErr })
+	if !errors.Is(err, importErr) {
+		t.Fatalf("Expected import error, got %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(manager.Dir(), "gost_test_*.failed.csv"))
+	if len(files) != 1 {
+		t.Fatalf("Expected failed import data to be kept, got %v", files)
+	}
+}
+
+func TestTempFileManager_Clean(t *testing.T) {
+	dir := t.TempDir()
+	manager := NewTempFileManager(dir)
+
+	old := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{"gost_a_20240101_000000.csv", "gost_b_20240101_000000.failed.csv"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("data"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fresh := filepath.Join(dir, "gost_c_20240101_000000.csv")
+	if err := os.WriteFile(fresh, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := manager.Clean(24*time.Hour, false)
+	if err != nil {
+		t.Fatalf("Clean failed: %v", err)
+	}
+	if len(result.Removed) != 1 || len(result.KeptFailed) != 1 {
+		t.Fatalf("Expected 1 removed and 1 kept file, got %+v", result)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("Fresh file must not be removed: %v", err)
+	}
+
+	result, err = manager.Clean(24*time.Hour, true)
+	if err != nil {
+		t.Fatalf("Clean with force failed: %v", err)
+	}
+	if len(result.Removed) != 1 || len(result.KeptFailed) != 0 {
+		t.Errorf("Expected failed file to be removed with force, got %+v", result)
+	}
+
+	if _, err := NewTempFileManager(filepath.Join(dir, "missing")).Clean(time.Hour, false); err != nil {
+		t.Errorf("Clean of missing directory must not fail: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"httpserver/database"
@@ -33,7 +34,14 @@ type Config struct {
 
 	// Логирование
 	LogBufferSize int    `json:"log_buffer_size"`
-	LogLevel       string `json:"log_level"`
+	LogLevel      string `json:"log_level"`
+	LogFile       string `json:"log_file"`         // Файл логов (пусто - только stdout)
+	LogMaxSizeMB  int    `json:"log_max_size_mb"`  // Размер файла логов для ротации (0 - без ротации)
+	LogMaxAgeDays int    `json:"log_max_age_days"` // Срок хранения архивных логов (0 - без ограничения)
+	LogMaxBackups int    `json:"log_max_backups"`  // Количество архивных логов (0 - без ограничения)
+
+	// Очистка истории метрик производительности
+	MetricsCleanupBatchSize int `json:"metrics_cleanup_batch_size"` // Строк, удаляемых за один запрос
 
 	// Нормализация
 	NormalizerEventsBufferSize int `json:"normalizer_events_buffer_size"`
@@ -48,6 +56,27 @@ type Config struct {
 
 	// Веб-поиск для валидации
 	WebSearch *WebSearchConfig `json:"web_search"`
+
+	// Приоритет источников ГОСТов при слиянии (от более авторитетного к менее)
+	GostSourcePriority []string `json:"gost_source_priority"`
+
+	// Проверка кодировки текстовых полей ГОСТов при чтении (см. database.GostEncodingOptions)
+	GostValidateEncoding bool `json:"gost_validate_encoding"`
+	GostStrictEncoding   bool `json:"gost_strict_encoding"` // Только помечать, не исправлять
+
+	// Дополнительные стоп-слова для извлечения ключевых слов (к встроенным русским и английским)
+	Stopwords []string `json:"stopwords"`
+
+	// Минимальная уверенность AI для связывания номенклатуры с ОКПД2 (cmd/link_okpd2)
+	Okpd2LinkMinConfidence float64 `json:"okpd2_link_min_confidence"`
+
+	// Временные файлы импорта (скачанные CSV)
+	TempDir             string        `json:"temp_dir"`
+	TempCleanupInterval time.Duration `json:"temp_cleanup_interval"` // Период очистки на сервере (0 - не очищать)
+	TempFileMaxAge      time.Duration `json:"temp_file_max_age"`     // Удаляются файлы старше
+
+	// Каталог документов клиентов (файлы клиента - в подкаталоге с его ID)
+	ClientDocumentsDir string `json:"client_documents_dir"`
 }
 
 // EnrichmentConfig конфигурация обогащения
@@ -59,6 +88,12 @@ type EnrichmentConfig struct {
 	Cache           *enrichment.CacheConfig               `json:"cache"`
 }
 
+// defaultTempDir каталог временных файлов импорта (совпадает с importer.DefaultTempDir)
+const defaultTempDir = "data/temp"
+
+// defaultClientDocumentsDir каталог документов клиентов (совпадает с handlers.DefaultClientDocumentsDir)
+const defaultClientDocumentsDir = "data/client_documents"
+
 // LoadConfig загружает конфигурацию из сервисной БД (если serviceDB передан) или из переменных окружения
 func LoadConfig(serviceDB ...*database.ServiceDB) (*Config, error) {
 	var config *Config
@@ -79,6 +114,34 @@ func LoadConfig(serviceDB ...*database.ServiceDB) (*Config, error) {
 				if err != nil {
 					aiTimeout = 30 * time.Second // fallback
 				}
+				metricsCleanupBatchSize := cfgJSON.MetricsCleanupBatchSize
+				if metricsCleanupBatchSize == 0 {
+					metricsCleanupBatchSize = database.DefaultMetricsCleanupBatchSize // fallback
+				}
+				gostSourcePriority := cfgJSON.GostSourcePriority
+				if len(gostSourcePriority) == 0 {
+					gostSourcePriority = database.DefaultGostSourcePriority // fallback
+				}
+				okpd2LinkMinConfidence := cfgJSON.Okpd2LinkMinConfidence
+				if okpd2LinkMinConfidence == 0 {
+					okpd2LinkMinConfidence = database.DefaultOkpd2LinkMinConfidence // fallback
+				}
+				tempDir := cfgJSON.TempDir
+				if tempDir == "" {
+					tempDir = defaultTempDir // fallback
+				}
+				clientDocumentsDir := cfgJSON.ClientDocumentsDir
+				if clientDocumentsDir == "" {
+					clientDocumentsDir = defaultClientDocumentsDir // fallback
+				}
+				tempCleanupInterval, err := time.ParseDuration(cfgJSON.TempCleanupInterval)
+				if err != nil {
+					tempCleanupInterval = time.Hour // fallback
+				}
+				tempFileMaxAge, err := time.ParseDuration(cfgJSON.TempFileMaxAge)
+				if err != nil {
+					tempFileMaxAge = 24 * time.Hour // fallback
+				}
 
 				config = &Config{
 					Port:                       cfgJSON.Port,
@@ -92,12 +155,26 @@ func LoadConfig(serviceDB ...*database.ServiceDB) (*Config, error) {
 					ConnMaxLifetime:            connMaxLifetime,
 					LogBufferSize:              cfgJSON.LogBufferSize,
 					LogLevel:                   cfgJSON.LogLevel,
+					LogFile:                    cfgJSON.LogFile,
+					LogMaxSizeMB:               cfgJSON.LogMaxSizeMB,
+					LogMaxAgeDays:              cfgJSON.LogMaxAgeDays,
+					LogMaxBackups:              cfgJSON.LogMaxBackups,
+					MetricsCleanupBatchSize:    metricsCleanupBatchSize,
 					NormalizerEventsBufferSize: cfgJSON.NormalizerEventsBufferSize,
 					MultiProviderEnabled:       cfgJSON.MultiProviderEnabled,
 					AggregationStrategy:        cfgJSON.AggregationStrategy,
 					AITimeout:                  aiTimeout,
 					Enrichment:                 cfgJSON.Enrichment,
 					WebSearch:                  cfgJSON.WebSearch,
+					GostSourcePriority:         gostSourcePriority,
+					GostValidateEncoding:       cfgJSON.GostValidateEncoding,
+					GostStrictEncoding:         cfgJSON.GostStrictEncoding,
+					Stopwords:                  cfgJSON.Stopwords,
+					Okpd2LinkMinConfidence:     okpd2LinkMinConfidence,
+					TempDir:                    tempDir,
+					TempCleanupInterval:        tempCleanupInterval,
+					TempFileMaxAge:             tempFileMaxAge,
+					ClientDocumentsDir:         clientDocumentsDir,
 				}
 
 				log.Printf("Config loaded from service database")
@@ -136,6 +213,13 @@ func LoadConfig(serviceDB ...*database.ServiceDB) (*Config, error) {
 		// Логирование
 		LogBufferSize: getEnvInt("LOG_BUFFER_SIZE", 100),
 		LogLevel:      getEnv("LOG_LEVEL", "INFO"),
+		LogFile:       os.Getenv("LOG_FILE"),
+		LogMaxSizeMB:  getEnvInt("LOG_MAX_SIZE_MB", 100),
+		LogMaxAgeDays: getEnvInt("LOG_MAX_AGE_DAYS", 30),
+		LogMaxBackups: getEnvInt("LOG_MAX_BACKUPS", 10),
+
+		// Очистка истории метрик
+		MetricsCleanupBatchSize: getEnvInt("METRICS_CLEANUP_BATCH_SIZE", database.DefaultMetricsCleanupBatchSize),
 
 		// Нормализация
 		NormalizerEventsBufferSize: getEnvInt("NORMALIZER_EVENTS_BUFFER_SIZE", 100),
@@ -150,6 +234,25 @@ func LoadConfig(serviceDB ...*database.ServiceDB) (*Config, error) {
 
 		// Веб-поиск
 		WebSearch: LoadWebSearchConfig(),
+
+		// ГОСТы
+		GostSourcePriority:   LoadGostSourcePriority(),
+		GostValidateEncoding: getEnv("GOST_VALIDATE_ENCODING", "false") == "true",
+		GostStrictEncoding:   getEnv("GOST_STRICT_ENCODING", "false") == "true",
+
+		// Ключевые слова
+		Stopwords: LoadStopwords(),
+
+		// Связывание с ОКПД2
+		Okpd2LinkMinConfidence: getEnvFloat("OKPD2_LINK_MIN_CONFIDENCE", database.DefaultOkpd2LinkMinConfidence),
+
+		// Временные файлы импорта
+		TempDir:             getEnv("TEMP_DIR", defaultTempDir),
+		TempCleanupInterval: getEnvDuration("TEMP_CLEANUP_INTERVAL", time.Hour),
+		TempFileMaxAge:      getEnvDuration("TEMP_FILE_MAX_AGE", 24*time.Hour),
+
+		// Документы клиентов
+		ClientDocumentsDir: getEnv("CLIENT_DOCUMENTS_DIR", defaultClientDocumentsDir),
 	}
 
 	// Валидация
@@ -226,6 +329,14 @@ func LoadEnrichmentConfig() *EnrichmentConfig {
 	}
 }
 
+// GostEncodingOptions настройки проверки кодировки для database.GostsDB
+func (c *Config) GostEncodingOptions() database.GostEncodingOptions {
+	return database.GostEncodingOptions{
+		Validate:       c.GostValidateEncoding || c.GostStrictEncoding,
+		StrictEncoding: c.GostStrictEncoding,
+	}
+}
+
 // getEnv получает переменную окружения или возвращает значение по умолчанию
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -244,6 +355,43 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvFloat получает переменную окружения как float64 или возвращает значение по умолчанию
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value := os.Getenv(key); value != "" {
+		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
+			return floatValue
+		}
+	}
+	return defaultValue
+}
+
+// LoadStopwords возвращает дополнительные стоп-слова из переменной окружения STOPWORDS.
+// Для утилит, которым из конфигурации нужны только стоп-слова: остальные настройки не проверяются.
+func LoadStopwords() []string {
+	return getEnvList("STOPWORDS", nil)
+}
+
+// LoadGostSourcePriority возвращает приоритет источников ГОСТов из переменной окружения
+// GOST_SOURCE_PRIORITY или порядок по умолчанию. Для утилит импорта ГОСТов, которым не нужна
+// остальная конфигурация.
+func LoadGostSourcePriority() []string {
+	return getEnvList("GOST_SOURCE_PRIORITY", database.DefaultGostSourcePriority)
+}
+
+// getEnvList получает переменную окружения как список через запятую или возвращает значение по умолчанию
+func getEnvList(key string, defaultValue []string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	if len(list) == 0 {
+		return defaultValue
+	}
+	return list
+}
+
 // getEnvDuration получает переменную окружения как Duration или возвращает значение по умолчанию
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
@@ -262,6 +410,16 @@ type WebSearchConfig struct {
 	CacheEnabled    bool          `json:"cache_enabled"`
 	RateLimitPerSec int           `json:"rate_limit_per_sec"`
 	BaseURL         string        `json:"base_url"`
+	HTMLBaseURL     string        `json:"html_base_url"`
+	SearchMode      string        `json:"search_mode"` // auto, api или html
+	// HTMLSearchURL адрес произвольного HTML-поиска с {query} вместо запроса; если задан,
+	// используется вместо DuckDuckGo (см. websearch.NewHTMLSearchClient)
+	HTMLSearchURL    string `json:"html_search_url,omitempty"`
+	HTMLResultClass  string `json:"html_result_class,omitempty"`
+	HTMLSnippetClass string `json:"html_snippet_class,omitempty"`
+	// CachePersistPath базовый путь файлов кэша между перезапусками (пусто - кэш только в памяти).
+	// Каждый кэш сохраняется в свой файл, см. websearch.CachePersistPathFor.
+	CachePersistPath string `json:"cache_persist_path,omitempty"`
 }
 
 // LoadWebSearchConfig загружает конфигурацию веб-поиска
@@ -270,16 +428,28 @@ func LoadWebSearchConfig() *WebSearchConfig {
 	timeout := getEnvDuration("WEB_SEARCH_TIMEOUT", 5*time.Second)
 	cacheTTL := getEnvDuration("WEB_SEARCH_CACHE_TTL", 24*time.Hour)
 	cacheEnabled := getEnv("WEB_SEARCH_CACHE_ENABLED", "true") == "true"
+	cachePersistPath := getEnv("WEB_SEARCH_CACHE_PATH", "")
 	rateLimit := getEnvInt("WEB_SEARCH_RATE_LIMIT_PER_SEC", 1)
 	baseURL := getEnv("WEB_SEARCH_BASE_URL", "https://api.duckduckgo.com")
+	htmlBaseURL := getEnv("WEB_SEARCH_HTML_BASE_URL", "https://html.duckduckgo.com")
+	searchMode := getEnv("WEB_SEARCH_MODE", "auto")
+	htmlSearchURL := getEnv("WEB_SEARCH_HTML_SEARCH_URL", "")
+	htmlResultClass := getEnv("WEB_SEARCH_HTML_RESULT_CLASS", "")
+	htmlSnippetClass := getEnv("WEB_SEARCH_HTML_SNIPPET_CLASS", "")
 
 	return &WebSearchConfig{
-		Enabled:         enabled,
-		Timeout:         timeout,
-		CacheTTL:        cacheTTL,
-		CacheEnabled:    cacheEnabled,
-		RateLimitPerSec: rateLimit,
-		BaseURL:         baseURL,
+		Enabled:          enabled,
+		Timeout:          timeout,
+		CacheTTL:         cacheTTL,
+		CacheEnabled:     cacheEnabled,
+		CachePersistPath: cachePersistPath,
+		RateLimitPerSec:  rateLimit,
+		BaseURL:          baseURL,
+		HTMLBaseURL:      htmlBaseURL,
+		SearchMode:       searchMode,
+		HTMLSearchURL:    htmlSearchURL,
+		HTMLResultClass:  htmlResultClass,
+		HTMLSnippetClass: htmlSnippetClass,
 	}
 }
 
@@ -296,12 +466,26 @@ type configJSON struct {
 	ConnMaxLifetime            string                     `json:"conn_max_lifetime"` // time.Duration как строка
 	LogBufferSize              int                        `json:"log_buffer_size"`
 	LogLevel                   string                     `json:"log_level"`
+	LogFile                    string                     `json:"log_file"`
+	LogMaxSizeMB               int                        `json:"log_max_size_mb"`
+	LogMaxAgeDays              int                        `json:"log_max_age_days"`
+	LogMaxBackups              int                        `json:"log_max_backups"`
+	MetricsCleanupBatchSize    int                        `json:"metrics_cleanup_batch_size"`
 	NormalizerEventsBufferSize int                        `json:"normalizer_events_buffer_size"`
 	MultiProviderEnabled       bool                       `json:"multi_provider_enabled"`
 	AggregationStrategy        string                     `json:"aggregation_strategy"`
 	AITimeout                  string                     `json:"ai_timeout"` // time.Duration как строка
 	Enrichment                 *EnrichmentConfig          `json:"enrichment"`
 	WebSearch                  *WebSearchConfig           `json:"web_search"`
+	GostSourcePriority         []string                   `json:"gost_source_priority"`
+	GostValidateEncoding       bool                       `json:"gost_validate_encoding"`
+	GostStrictEncoding         bool                       `json:"gost_strict_encoding"`
+	Stopwords                  []string                   `json:"stopwords"`
+	Okpd2LinkMinConfidence     float64                    `json:"okpd2_link_min_confidence"`
+	TempDir                    string                     `json:"temp_dir"`
+	TempCleanupInterval        string                     `json:"temp_cleanup_interval"` // time.Duration как строка
+	TempFileMaxAge             string                     `json:"temp_file_max_age"`     // time.Duration как строка
+	ClientDocumentsDir         string                     `json:"client_documents_dir"`
 }
 
 // SaveConfig сохраняет конфигурацию в сервисную БД
@@ -328,12 +512,26 @@ func SaveConfigWithHistory(cfg *Config, serviceDB *database.ServiceDB, changedBy
 		ConnMaxLifetime:            cfg.ConnMaxLifetime.String(),
 		LogBufferSize:              cfg.LogBufferSize,
 		LogLevel:                   cfg.LogLevel,
+		LogFile:                    cfg.LogFile,
+		LogMaxSizeMB:               cfg.LogMaxSizeMB,
+		LogMaxAgeDays:              cfg.LogMaxAgeDays,
+		LogMaxBackups:              cfg.LogMaxBackups,
+		MetricsCleanupBatchSize:    cfg.MetricsCleanupBatchSize,
 		NormalizerEventsBufferSize: cfg.NormalizerEventsBufferSize,
 		MultiProviderEnabled:       cfg.MultiProviderEnabled,
 		AggregationStrategy:        cfg.AggregationStrategy,
 		AITimeout:                  cfg.AITimeout.String(),
 		Enrichment:                 cfg.Enrichment,
 		WebSearch:                  cfg.WebSearch,
+		GostSourcePriority:         cfg.GostSourcePriority,
+		GostValidateEncoding:       cfg.GostValidateEncoding,
+		GostStrictEncoding:         cfg.GostStrictEncoding,
+		Stopwords:                  cfg.Stopwords,
+		Okpd2LinkMinConfidence:     cfg.Okpd2LinkMinConfidence,
+		TempDir:                    cfg.TempDir,
+		TempCleanupInterval:        cfg.TempCleanupInterval.String(),
+		TempFileMaxAge:             cfg.TempFileMaxAge.String(),
+		ClientDocumentsDir:         cfg.ClientDocumentsDir,
 	}
 
 	configJSONBytes, err := json.Marshal(cfgJSON)
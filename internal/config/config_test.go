@@ -1,8 +1,14 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
+
+	"httpserver/database"
 )
 
 func TestConfigLogLevelValidation(t *testing.T) {
@@ -65,3 +71,108 @@ func TestConfigLogLevelDefault(t *testing.T) {
 	}
 }
 
+
+func TestConfigGostSourcePriorityFromEnv(t *testing.T) {
+	t.Setenv("GOST_SOURCE_PRIORITY", " tulist, nationalstandards ,")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	want := []string{"tulist", "nationalstandards"}
+	if !reflect.DeepEqual(cfg.GostSourcePriority, want) {
+		t.Errorf("GostSourcePriority = %v, want %v", cfg.GostSourcePriority, want)
+	}
+}
+
+func TestConfigGostSourcePriorityDefault(t *testing.T) {
+	t.Setenv("GOST_SOURCE_PRIORITY", "")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(cfg.GostSourcePriority, database.DefaultGostSourcePriority) {
+		t.Errorf("GostSourcePriority = %v, want default %v", cfg.GostSourcePriority, database.DefaultGostSourcePriority)
+	}
+}
+
+func TestLoadStopwordsIgnoresInvalidSettings(t *testing.T) {
+	t.Setenv("STOPWORDS", " шт, упак ,")
+	t.Setenv("LOG_LEVEL", "trace")
+
+	if _, err := LoadConfig(); err == nil {
+		t.Fatal("LoadConfig() expected error for invalid LOG_LEVEL")
+	}
+
+	want := []string{"шт", "упак"}
+	if got := LoadStopwords(); !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadStopwords() = %v, want %v", got, want)
+	}
+}
+
+func TestConfigGostEncodingOptionsFromEnv(t *testing.T) {
+	t.Setenv("GOST_VALIDATE_ENCODING", "")
+	t.Setenv("GOST_STRICT_ENCODING", "true")
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	want := database.GostEncodingOptions{Validate: true, StrictEncoding: true}
+	if got := cfg.GostEncodingOptions(); got != want {
+		t.Errorf("GostEncodingOptions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestConfigValidateReportsAllViolations(t *testing.T) {
+	cfg := GetDefaults()
+	cfg.Port = "70000"
+	cfg.MaxOpenConns = 0
+	cfg.LogLevel = "LOUD"
+	cfg.Okpd2LinkMinConfidence = 1.5
+	cfg.Enrichment.MinQualityScore = -1
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("Validate() expected error for invalid config")
+	}
+
+	violations := ValidationErrors(err)
+	wantFields := []string{"port", "max_open_conns", "max_idle_conns", "okpd2_link_min_confidence", "log_level", "enrichment.min_quality_score"}
+	if len(violations) != len(wantFields) {
+		t.Fatalf("ValidationErrors() returned %d violations, want %d: %v", len(violations), len(wantFields), err)
+	}
+	for i, violation := range violations {
+		var fieldErr *FieldError
+		if !errors.As(violation, &fieldErr) {
+			t.Fatalf("violation %d is %T, want *FieldError", i, violation)
+		}
+		if fieldErr.Field != wantFields[i] {
+			t.Errorf("violation %d field = %q, want %q", i, fieldErr.Field, wantFields[i])
+		}
+	}
+
+	// Сообщение содержит значение и ожидаемый диапазон
+	if !strings.Contains(err.Error(), `port: got "70000", expected integer between 1 and 65535`) {
+		t.Errorf("Validate() error does not describe port violation: %v", err)
+	}
+
+	// Ошибка LoadConfig оборачивает ошибку Validate и раскладывается так же
+	wrapped := fmt.Errorf("invalid config: %w", err)
+	if got := len(ValidationErrors(wrapped)); got != len(wantFields) {
+		t.Errorf("ValidationErrors(wrapped) returned %d violations, want %d", got, len(wantFields))
+	}
+}
+
+func TestConfigValidateValid(t *testing.T) {
+	if err := GetDefaults().Validate(); err != nil {
+		t.Errorf("Validate() for defaults = %v, want nil", err)
+	}
+	if got := ValidationErrors(nil); got != nil {
+		t.Errorf("ValidationErrors(nil) = %v, want nil", got)
+	}
+}
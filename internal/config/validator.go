@@ -1,7 +1,9 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -9,53 +11,119 @@ import (
 	"httpserver/enrichment"
 )
 
-// Validate проверяет корректность конфигурации
+// FieldError ошибка проверки одного поля конфигурации: имя поля (как в JSON), значение и ожидаемые значения
+type FieldError struct {
+	Field    string
+	Value    interface{}
+	Expected string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: got %v, expected %s", e.Field, formatFieldValue(e.Value), e.Expected)
+}
+
+// formatFieldValue выводит строки в кавычках, чтобы была видна пустая строка
+func formatFieldValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return fmt.Sprint(value)
+}
+
+// ValidationErrors раскладывает ошибку Validate (в том числе обернутую LoadConfig) на отдельные нарушения
+func ValidationErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var result []error
+		for _, e := range joined.Unwrap() {
+			result = append(result, ValidationErrors(e)...)
+		}
+		return result
+	}
+	if _, ok := err.(*FieldError); !ok {
+		if inner := errors.Unwrap(err); inner != nil {
+			if nested := ValidationErrors(inner); len(nested) > 1 {
+				return nested
+			}
+		}
+	}
+	return []error{err}
+}
+
+// Validate проверяет корректность конфигурации.
+// Возвращает все нарушения сразу (errors.Join из *FieldError), nil - если конфигурация корректна.
 func (c *Config) Validate() error {
-	var errors []string
+	var errs []error
+	invalid := func(field string, value interface{}, expected string) {
+		errs = append(errs, &FieldError{Field: field, Value: value, Expected: expected})
+	}
 
 	// Валидация порта
 	if c.Port == "" {
-		errors = append(errors, "port is required")
+		invalid("port", c.Port, "non-empty port number")
 	} else {
 		port, err := strconv.Atoi(c.Port)
-		if err != nil {
-			errors = append(errors, fmt.Sprintf("invalid port: %s", c.Port))
-		} else if port < 1 || port > 65535 {
-			errors = append(errors, fmt.Sprintf("port must be between 1 and 65535, got %d", port))
+		if err != nil || port < 1 || port > 65535 {
+			invalid("port", c.Port, "integer between 1 and 65535")
 		}
 	}
 
 	// Валидация путей к базам данных
 	if c.DatabasePath == "" {
-		errors = append(errors, "database path is required")
+		invalid("database_path", c.DatabasePath, "non-empty path")
 	}
 	if c.NormalizedDatabasePath == "" {
-		errors = append(errors, "normalized database path is required")
+		invalid("normalized_database_path", c.NormalizedDatabasePath, "non-empty path")
 	}
 	if c.ServiceDatabasePath == "" {
-		errors = append(errors, "service database path is required")
+		invalid("service_database_path", c.ServiceDatabasePath, "non-empty path")
 	}
 
 	// Валидация connection pooling
 	if c.MaxOpenConns < 1 {
-		errors = append(errors, "max open connections must be at least 1")
+		invalid("max_open_conns", c.MaxOpenConns, ">= 1")
 	}
 	if c.MaxIdleConns < 1 {
-		errors = append(errors, "max idle connections must be at least 1")
+		invalid("max_idle_conns", c.MaxIdleConns, ">= 1")
 	}
 	if c.MaxIdleConns > c.MaxOpenConns {
-		errors = append(errors, "max idle connections cannot be greater than max open connections")
+		invalid("max_idle_conns", c.MaxIdleConns, fmt.Sprintf("<= max_open_conns (%d)", c.MaxOpenConns))
 	}
 	if c.ConnMaxLifetime < time.Second {
-		errors = append(errors, "connection max lifetime must be at least 1 second")
+		invalid("conn_max_lifetime", c.ConnMaxLifetime, ">= 1s")
 	}
 
 	// Валидация буферов
 	if c.LogBufferSize < 1 {
-		errors = append(errors, "log buffer size must be at least 1")
+		invalid("log_buffer_size", c.LogBufferSize, ">= 1")
 	}
 	if c.NormalizerEventsBufferSize < 1 {
-		errors = append(errors, "normalizer events buffer size must be at least 1")
+		invalid("normalizer_events_buffer_size", c.NormalizerEventsBufferSize, ">= 1")
+	}
+
+	// Валидация ротации файла логов
+	if c.LogMaxSizeMB < 0 {
+		invalid("log_max_size_mb", c.LogMaxSizeMB, ">= 0")
+	}
+	if c.LogMaxAgeDays < 0 {
+		invalid("log_max_age_days", c.LogMaxAgeDays, ">= 0")
+	}
+	if c.LogMaxBackups < 0 {
+		invalid("log_max_backups", c.LogMaxBackups, ">= 0")
+	}
+	if c.MetricsCleanupBatchSize < 0 {
+		invalid("metrics_cleanup_batch_size", c.MetricsCleanupBatchSize, ">= 0")
+	}
+	if c.Okpd2LinkMinConfidence < 0 || c.Okpd2LinkMinConfidence > 1 {
+		invalid("okpd2_link_min_confidence", c.Okpd2LinkMinConfidence, "between 0 and 1")
+	}
+	if c.TempCleanupInterval < 0 {
+		invalid("temp_cleanup_interval", c.TempCleanupInterval, ">= 0 (0 disables cleanup)")
+	}
+	if c.TempCleanupInterval > 0 && c.TempFileMaxAge <= 0 {
+		invalid("temp_file_max_age", c.TempFileMaxAge, "> 0 when temp cleanup is enabled")
 	}
 
 	// Валидация уровня логирования
@@ -70,19 +138,18 @@ func (c *Config) Validate() error {
 			}
 		}
 		if !valid {
-			errors = append(errors, fmt.Sprintf("invalid log level: %s (valid: %s)", 
-				c.LogLevel, strings.Join(validLogLevels, ", ")))
+			invalid("log_level", c.LogLevel, "one of "+strings.Join(validLogLevels, ", "))
 		}
 	}
 
 	// Валидация AI конфигурации
 	if c.ArliaiModel == "" {
-		errors = append(errors, "arliai model is required")
+		invalid("arliai_model", c.ArliaiModel, "non-empty model name")
 	}
 
 	// Валидация таймаутов
 	if c.AITimeout < time.Second {
-		errors = append(errors, "AI timeout must be at least 1 second")
+		invalid("ai_timeout", c.AITimeout, ">= 1s")
 	}
 
 	// Валидация стратегии агрегации
@@ -96,69 +163,68 @@ func (c *Config) Validate() error {
 			}
 		}
 		if !valid {
-			errors = append(errors, fmt.Sprintf("invalid aggregation strategy: %s (valid: %s)", 
-				c.AggregationStrategy, strings.Join(validStrategies, ", ")))
+			invalid("aggregation_strategy", c.AggregationStrategy, "one of "+strings.Join(validStrategies, ", "))
 		}
 	}
 
 	// Валидация конфигурации обогащения
 	if c.Enrichment != nil {
-		if err := c.Enrichment.Validate(); err != nil {
-			errors = append(errors, fmt.Sprintf("enrichment config: %v", err))
-		}
-	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("validation errors: %s", strings.Join(errors, "; "))
+		errs = append(errs, ValidationErrors(c.Enrichment.Validate())...)
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
-// Validate проверяет корректность конфигурации обогащения
+// Validate проверяет корректность конфигурации обогащения.
+// Как и Config.Validate, возвращает все нарушения сразу; имена полей начинаются с "enrichment.".
 func (ec *EnrichmentConfig) Validate() error {
-	var errors []string
+	var errs []error
+	invalid := func(field string, value interface{}, expected string) {
+		errs = append(errs, &FieldError{Field: "enrichment." + field, Value: value, Expected: expected})
+	}
 
 	// Валидация минимального качества
 	if ec.MinQualityScore < 0 || ec.MinQualityScore > 1 {
-		errors = append(errors, "min quality score must be between 0 and 1")
+		invalid("min_quality_score", ec.MinQualityScore, "between 0 and 1")
 	}
 
-	// Валидация сервисов
-	for name, service := range ec.Services {
+	// Валидация сервисов (в порядке имен, чтобы список ошибок был стабильным)
+	names := make([]string, 0, len(ec.Services))
+	for name := range ec.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		service := ec.Services[name]
 		if service == nil {
-			errors = append(errors, fmt.Sprintf("service %s is nil", name))
+			invalid("services."+name, nil, "service config")
 			continue
 		}
 
 		if service.Timeout < time.Second {
-			errors = append(errors, fmt.Sprintf("service %s timeout must be at least 1 second", name))
+			invalid("services."+name+".timeout", service.Timeout, ">= 1s")
 		}
 
 		if service.MaxRequests < 1 {
-			errors = append(errors, fmt.Sprintf("service %s max requests must be at least 1", name))
+			invalid("services."+name+".max_requests", service.MaxRequests, ">= 1")
 		}
 
 		if service.Priority < 1 {
-			errors = append(errors, fmt.Sprintf("service %s priority must be at least 1", name))
+			invalid("services."+name+".priority", service.Priority, ">= 1")
 		}
 	}
 
 	// Валидация кэша
 	if ec.Cache != nil {
 		if ec.Cache.TTL < time.Minute {
-			errors = append(errors, "cache TTL must be at least 1 minute")
+			invalid("cache.ttl", ec.Cache.TTL, ">= 1m")
 		}
 		if ec.Cache.CleanupInterval < time.Minute {
-			errors = append(errors, "cache cleanup interval must be at least 1 minute")
+			invalid("cache.cleanup_interval", ec.Cache.CleanupInterval, ">= 1m")
 		}
 	}
 
-	if len(errors) > 0 {
-		return fmt.Errorf("enrichment validation errors: %s", strings.Join(errors, "; "))
-	}
-
-	return nil
+	return errors.Join(errs...)
 }
 
 // GetDefaults возвращает конфигурацию со значениями по умолчанию
@@ -385,6 +385,7 @@ func (c *Container) initWebSearch() error {
 		TTL:             c.Config.WebSearch.CacheTTL,
 		CleanupInterval: c.Config.WebSearch.CacheTTL / 4,
 		MaxSize:         1000,
+		PersistPath:     websearch.CachePersistPathFor(c.Config.WebSearch.CachePersistPath, "main"),
 	}
 	cache := websearch.NewCache(cacheConfig)
 	c.WebSearchCache = cache
@@ -431,6 +432,9 @@ func (c *Container) initMultiProviderWebSearch(cache *websearch.Cache) error {
 		return c.initSimpleWebSearch(cache)
 	}
 
+	// Провайдер, переставший находить результаты, временно отключается до успешной повторной проверки
+	providers = websearch.WithHealthTracking(providers, websearch.DefaultProviderHealthConfig())
+
 	// Создаем ReliabilityManager для отслеживания статистики
 	// Временно используем stub реализацию
 	var reliabilityManager websearch.ReliabilityManagerInterface
@@ -600,12 +604,17 @@ func (c *Container) initServices() error {
 	// GISP service
 	c.GISPService = services.NewGISPService(c.ServiceDB)
 
+	// Стоп-слова для ключевых слов номенклатуры и ГОСТов
+	normalization.SetStopwords(normalization.DefaultStopwordSet(c.Config.Stopwords...))
+
 	// Gost service
 	gostsDB, err := database.NewGostsDB("gosts.db")
 	if err != nil {
 		log.Printf("Warning: failed to initialize GOSTs database: %v", err)
 	} else if gostsDB != nil {
+		gostsDB.SetEncodingOptions(c.Config.GostEncodingOptions())
 		c.GostService = services.NewGostService(gostsDB)
+		c.GostService.SetSourcePriority(c.Config.GostSourcePriority)
 	}
 
 	// Processing1C service
@@ -661,6 +670,7 @@ func (c *Container) initHandlers() error {
 		c.ClientService,
 		baseHandler,
 	)
+	c.ClientHandler.SetDocumentsDir(c.Config.ClientDocumentsDir)
 
 	// Database handler
 	c.DatabaseHandler = handlers.NewDatabaseHandler(
@@ -713,9 +723,7 @@ func (c *Container) initHandlers() error {
 		func() map[string]interface{} {
 			return map[string]interface{}{}
 		},
-		func() *database.PerformanceMetricsSnapshot {
-			return nil // TODO: Реализовать позже
-		},
+		c.collectWebSearchMetricsSnapshot,
 		func() handlers.MonitoringData {
 			return handlers.MonitoringData{}
 		},
@@ -758,6 +766,7 @@ func (c *Container) initHandlers() error {
 				TTL:             c.Config.WebSearch.CacheTTL,
 				CleanupInterval: c.Config.WebSearch.CacheTTL / 4,
 				MaxSize:         1000,
+				PersistPath:     websearch.CachePersistPathFor(c.Config.WebSearch.CachePersistPath, "handlers"),
 			}
 			searchCache := websearch.NewCache(cacheConfig)
 			rateLimit := rate.Every(time.Duration(1000/c.Config.WebSearch.RateLimitPerSec) * time.Millisecond)
@@ -770,6 +779,12 @@ func (c *Container) initHandlers() error {
 			simpleClient := websearch.NewClient(clientConfig)
 			searchClient = simpleClient
 			c.WebSearchClient = simpleClient
+			// Заменяемый кэш сохраняется сразу: Shutdown закрывает только c.WebSearchCache
+			if previous, ok := c.WebSearchCache.(*websearch.Cache); ok {
+				if err := previous.Close(); err != nil {
+					log.Printf("Error closing web search cache: %v", err)
+				}
+			}
 			c.WebSearchCache = searchCache
 		}
 
@@ -912,6 +927,13 @@ func (c *Container) Shutdown(ctx context.Context) error {
 
 	c.cancel()
 
+	// Сохраняем кэш веб-поиска на диск (CacheConfig.PersistPath)
+	if cache, ok := c.WebSearchCache.(*websearch.Cache); ok {
+		if err := cache.Close(); err != nil {
+			log.Printf("Error closing web search cache: %v", err)
+		}
+	}
+
 	// Закрываем базы данных
 	if c.DB != nil {
 		if err := c.DB.Close(); err != nil {
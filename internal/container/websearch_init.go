@@ -1,9 +1,12 @@
 package container
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"time"
 
+	"httpserver/database"
 	"httpserver/internal/infrastructure/persistence"
 	"httpserver/websearch"
 
@@ -26,6 +29,7 @@ func (c *Container) InitWebSearch() error {
 			TTL:             c.Config.WebSearch.CacheTTL,
 			CleanupInterval: c.Config.WebSearch.CacheTTL / 4,
 			MaxSize:         1000,
+			PersistPath:     websearch.CachePersistPathFor(c.Config.WebSearch.CachePersistPath, "simple"),
 		}
 		cache := websearch.NewCache(cacheConfig)
 		return c.initSimpleWebSearch(cache)
@@ -44,6 +48,7 @@ func (c *Container) InitWebSearch() error {
 		TTL:             c.Config.WebSearch.CacheTTL,
 		CleanupInterval: c.Config.WebSearch.CacheTTL / 4,
 		MaxSize:         1000,
+		PersistPath:     websearch.CachePersistPathFor(c.Config.WebSearch.CachePersistPath, "multi"),
 	}
 	cache := websearch.NewCache(cacheConfig)
 	c.WebSearchCache = cache
@@ -72,6 +77,9 @@ func (c *Container) InitWebSearch() error {
 		return c.initSimpleWebSearchWithCache(cache)
 	}
 
+	// Провайдер, переставший находить результаты, временно отключается до успешной повторной проверки
+	providers = websearch.WithHealthTracking(providers, websearch.DefaultProviderHealthConfig())
+
 	// Создаем ReliabilityManager для отслеживания статистики
 	var reliabilityManager websearch.ReliabilityManagerInterface
 	if webSearchRepo != nil {
@@ -114,15 +122,62 @@ func (c *Container) initSimpleWebSearchWithCache(cache *websearch.Cache) error {
 	c.WebSearchCache = cache
 
 	// Создаем простой клиент
+	searchMode, err := websearch.ParseSearchMode(c.Config.WebSearch.SearchMode)
+	if err != nil {
+		return fmt.Errorf("invalid web search config: %w", err)
+	}
 	rateLimit := rate.Every(time.Duration(1000/c.Config.WebSearch.RateLimitPerSec) * time.Millisecond)
+
+	// Произвольный HTML-поиск вместо DuckDuckGo
+	if c.Config.WebSearch.HTMLSearchURL != "" {
+		client, err := websearch.NewHTMLSearchClient(websearch.HTMLSearchConfig{
+			URLTemplate:  c.Config.WebSearch.HTMLSearchURL,
+			ResultClass:  c.Config.WebSearch.HTMLResultClass,
+			SnippetClass: c.Config.WebSearch.HTMLSnippetClass,
+			Timeout:      c.Config.WebSearch.Timeout,
+			RateLimit:    rateLimit,
+			Cache:        cache,
+		})
+		if err != nil {
+			return fmt.Errorf("invalid web search config: %w", err)
+		}
+		c.WebSearchClient = client
+		return nil
+	}
+
 	clientConfig := websearch.ClientConfig{
-		BaseURL:   c.Config.WebSearch.BaseURL,
-		Timeout:   c.Config.WebSearch.Timeout,
-		RateLimit: rateLimit,
-		Cache:     cache,
+		BaseURL:     c.Config.WebSearch.BaseURL,
+		HTMLBaseURL: c.Config.WebSearch.HTMLBaseURL,
+		SearchMode:  searchMode,
+		Timeout:     c.Config.WebSearch.Timeout,
+		RateLimit:   rateLimit,
+		Cache:       cache,
 	}
 	client := websearch.NewClient(clientConfig)
 	c.WebSearchClient = client
 
 	return nil
 }
+
+// collectWebSearchMetricsSnapshot возвращает снимок метрик со здоровьем провайдеров веб-поиска.
+// Для простого клиента (без MultiProviderClient) снимка нет.
+func (c *Container) collectWebSearchMetricsSnapshot() *database.PerformanceMetricsSnapshot {
+	multiClient, ok := c.WebSearchClient.(*websearch.MultiProviderClient)
+	if !ok {
+		return nil
+	}
+
+	metricData, err := json.Marshal(map[string]interface{}{
+		"websearch_providers": multiClient.GetProviderHealth(),
+	})
+	if err != nil {
+		log.Printf("Failed to marshal web search provider health: %v", err)
+		return nil
+	}
+
+	return &database.PerformanceMetricsSnapshot{
+		Timestamp:  time.Now(),
+		MetricType: "websearch",
+		MetricData: string(metricData),
+	}
+}
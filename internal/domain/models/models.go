@@ -298,6 +298,8 @@ type NormalizationStatus struct {
 	KpvedClassified int      `json:"kpvedClassified,omitempty"` // количество классифицированных групп по КПВЭД
 	KpvedTotal      int      `json:"kpvedTotal,omitempty"`      // общее количество групп для КПВЭД
 	KpvedProgress   float64  `json:"kpvedProgress,omitempty"`   // процент классифицированных групп по КПВЭД
+	// PhaseTimings накопленное время по фазам нормализации (db_lookup, rules, ai, ...), в наносекундах
+	PhaseTimings map[string]time.Duration `json:"phaseTimings,omitempty"`
 }
 
 // ActivityLog представляет запись активности
@@ -0,0 +1,148 @@
+// Package httpclient создает HTTP-клиенты с единообразными таймаутами, прокси,
+// пулом соединений и необязательными повторами запросов.
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Значения по умолчанию для незаданных параметров Options
+const (
+	DefaultTimeout             = 30 * time.Second
+	DefaultDialTimeout         = 10 * time.Second
+	DefaultKeepAlive           = 30 * time.Second
+	DefaultTLSHandshakeTimeout = 10 * time.Second
+	DefaultIdleConnTimeout     = 90 * time.Second
+	DefaultMaxIdleConns        = 100
+	DefaultMaxIdleConnsPerHost = 10
+	DefaultRetryBackoff        = 500 * time.Millisecond
+	DefaultRetryJitter         = 0.2
+)
+
+// Options параметры HTTP-клиента. Нулевые значения заменяются значениями по умолчанию.
+type Options struct {
+	// Timeout общий таймаут запроса, включая чтение тела ответа
+	Timeout time.Duration
+	// DialTimeout таймаут установки TCP-соединения
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout таймаут TLS-рукопожатия
+	TLSHandshakeTimeout time.Duration
+	// ResponseHeaderTimeout таймаут ожидания заголовков ответа (0 - ограничивается только Timeout)
+	ResponseHeaderTimeout time.Duration
+	// IdleConnTimeout время жизни простаивающего соединения в пуле
+	IdleConnTimeout time.Duration
+
+	// MaxIdleConns максимальное число простаивающих соединений в пуле
+	MaxIdleConns int
+	// MaxIdleConnsPerHost максимальное число простаивающих соединений на хост
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost ограничение числа соединений на хост (0 - без ограничения)
+	MaxConnsPerHost int
+
+	// Proxy адрес прокси-сервера; если не задан, используются HTTP_PROXY/HTTPS_PROXY/NO_PROXY из окружения
+	Proxy *url.URL
+	// TLSConfig настройки TLS; если не заданы, используется проверка сертификатов и TLS не ниже 1.2
+	TLSConfig *tls.Config
+
+	// RetryCount число повторов при сетевых ошибках и временных ответах сервера (0 - без повторов)
+	RetryCount int
+	// RetryBackoff задержка перед первым повтором, далее удваивается
+	RetryBackoff time.Duration
+	// RetryMaxBackoff верхняя граница задержки между повторами (0 - без ограничения)
+	RetryMaxBackoff time.Duration
+	// RetryJitter доля случайного разброса задержки от 0 до 1 (0 - без разброса)
+	RetryJitter float64
+	// RetryOnStatus коды ответов для повтора (nil - DefaultRetryStatuses: 429/502/503/504)
+	RetryOnStatus []int
+	// RetryWait вызывается перед каждым повтором (см. RetryTransport.Wait)
+	RetryWait func(ctx context.Context) error
+}
+
+// New создает *http.Client с заданными параметрами
+func New(opts Options) *http.Client {
+	opts = withDefaults(opts)
+
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: NewTransport(opts),
+	}
+}
+
+// NewTransport создает транспорт с пулом соединений, прокси и, при RetryCount > 0, повторами запросов
+func NewTransport(opts Options) http.RoundTripper {
+	opts = withDefaults(opts)
+
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != nil {
+		proxy = http.ProxyURL(opts.Proxy)
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   opts.DialTimeout,
+		KeepAlive: DefaultKeepAlive,
+	}
+
+	tlsConfig := opts.TLSConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		TLSClientConfig:       tlsConfig,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   opts.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: opts.ResponseHeaderTimeout,
+		IdleConnTimeout:       opts.IdleConnTimeout,
+		MaxIdleConns:          opts.MaxIdleConns,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       opts.MaxConnsPerHost,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if opts.RetryCount > 0 {
+		return &RetryTransport{
+			Base:          transport,
+			MaxRetries:    opts.RetryCount,
+			Backoff:       opts.RetryBackoff,
+			MaxBackoff:    opts.RetryMaxBackoff,
+			Jitter:        opts.RetryJitter,
+			RetryOnStatus: opts.RetryOnStatus,
+			Wait:          opts.RetryWait,
+		}
+	}
+
+	return transport
+}
+
+// withDefaults заполняет незаданные параметры значениями по умолчанию
+func withDefaults(opts Options) Options {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = DefaultDialTimeout
+	}
+	if opts.TLSHandshakeTimeout <= 0 {
+		opts.TLSHandshakeTimeout = DefaultTLSHandshakeTimeout
+	}
+	if opts.IdleConnTimeout <= 0 {
+		opts.IdleConnTimeout = DefaultIdleConnTimeout
+	}
+	if opts.MaxIdleConns <= 0 {
+		opts.MaxIdleConns = DefaultMaxIdleConns
+	}
+	if opts.MaxIdleConnsPerHost <= 0 {
+		opts.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+	if opts.RetryBackoff <= 0 {
+		opts.RetryBackoff = DefaultRetryBackoff
+	}
+	return opts
+}
@@ -0,0 +1,132 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNew_DefaultTransportSettings(t *testing.T) {
+	client := New(Options{})
+
+	if client.Timeout != DefaultTimeout {
+		t.Errorf("Timeout = %v, want %v", client.Timeout, DefaultTimeout)
+	}
+
+	transport, ok := client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("Transport = %T, want *http.Transport", client.Transport)
+	}
+	if transport.MaxIdleConns != DefaultMaxIdleConns || transport.MaxIdleConnsPerHost != DefaultMaxIdleConnsPerHost {
+		t.Errorf("Unexpected pool settings: MaxIdleConns=%d, MaxIdleConnsPerHost=%d",
+			transport.MaxIdleConns, transport.MaxIdleConnsPerHost)
+	}
+	if transport.IdleConnTimeout != DefaultIdleConnTimeout || transport.TLSHandshakeTimeout != DefaultTLSHandshakeTimeout {
+		t.Errorf("Unexpected timeouts: IdleConnTimeout=%v, TLSHandshakeTimeout=%v",
+			transport.IdleConnTimeout, transport.TLSHandshakeTimeout)
+	}
+	if transport.TLSClientConfig == nil || transport.TLSClientConfig.MinVersion != tls.VersionTLS12 {
+		t.Error("Expected TLS 1.2 as the minimum version")
+	}
+	if transport.Proxy == nil {
+		t.Error("Expected proxy from environment to be configured")
+	}
+}
+
+func TestNew_CustomOptions(t *testing.T) {
+	proxyURL, _ := url.Parse("http://proxy.local:3128")
+	client := New(Options{
+		Timeout:               5 * time.Minute,
+		ResponseHeaderTimeout: 15 * time.Second,
+		MaxIdleConnsPerHost:   4,
+		MaxConnsPerHost:       8,
+		Proxy:                 proxyURL,
+	})
+
+	if client.Timeout != 5*time.Minute {
+		t.Errorf("Timeout = %v, want 5m", client.Timeout)
+	}
+
+	transport := client.Transport.(*http.Transport)
+	if transport.ResponseHeaderTimeout != 15*time.Second || transport.MaxIdleConnsPerHost != 4 || transport.MaxConnsPerHost != 8 {
+		t.Errorf("Unexpected transport settings: %+v", transport)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com", nil)
+	proxy, err := transport.Proxy(req)
+	if err != nil || proxy == nil || proxy.Host != "proxy.local:3128" {
+		t.Errorf("Proxy = %v (err %v), want proxy.local:3128", proxy, err)
+	}
+}
+
+func TestNew_RetryTransport(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(Options{RetryCount: 2, RetryBackoff: time.Millisecond})
+	if _, ok := client.Transport.(*RetryTransport); !ok {
+		t.Fatalf("Transport = %T, want *RetryTransport", client.Transport)
+	}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("StatusCode = %d, want 200", resp.StatusCode)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 attempts, got %d", calls)
+	}
+
+	// Повторы исчерпаны - возвращается последний ответ сервера
+	atomic.StoreInt32(&calls, -10)
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("StatusCode = %d, want 503", resp.StatusCode)
+	}
+}
+
+func TestRetryTransport_ResendsBody(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("Attempt %d: body = %q, want payload", atomic.LoadInt32(&calls)+1, body)
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := New(Options{RetryCount: 1, RetryBackoff: time.Millisecond})
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls != 2 {
+		t.Errorf("StatusCode = %d after %d attempts, want 200 after 2", resp.StatusCode, calls)
+	}
+}
@@ -0,0 +1,198 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRetryStatuses коды ответов, при которых RetryTransport повторяет запрос, если RetryOnStatus не задан
+var DefaultRetryStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryTransport повторяет запрос при сетевых ошибках и временных ответах сервера
+// с экспоненциально растущей задержкой. Если ответ 429 или 503 содержит Retry-After,
+// повтор выполняется через указанное сервером время, но не позже MaxBackoff. Тело запроса без GetBody буферизуется
+// в памяти, поэтому повторяются и POST-запросы. Исходный запрос не изменяется.
+type RetryTransport struct {
+	// Base транспорт, выполняющий запросы (nil - http.DefaultTransport)
+	Base http.RoundTripper
+	// MaxRetries число повторов после первой попытки
+	MaxRetries int
+	// Backoff задержка перед первым повтором, далее удваивается (0 - DefaultRetryBackoff)
+	Backoff time.Duration
+	// MaxBackoff верхняя граница задержки (0 - без ограничения)
+	MaxBackoff time.Duration
+	// Jitter доля случайного разброса задержки от 0 до 1: задержка выбирается из [d*(1-Jitter), d*(1+Jitter)]
+	Jitter float64
+	// RetryOnStatus коды ответов для повтора (nil - DefaultRetryStatuses)
+	RetryOnStatus []int
+	// Wait вызывается перед каждым повтором после задержки, например ожидание rate.Limiter
+	// вызывающего кода; ошибка прерывает повторы
+	Wait func(ctx context.Context) error
+}
+
+// retryableStatus проверяет, нужно ли повторить запрос при коде ответа code
+func (t *RetryTransport) retryableStatus(code int) bool {
+	statuses := t.RetryOnStatus
+	if statuses == nil {
+		statuses = DefaultRetryStatuses
+	}
+	for _, status := range statuses {
+		if status == code {
+			return true
+		}
+	}
+	return false
+}
+
+// RoundTrip выполняет запрос с повторами. Если все попытки неудачны, возвращается
+// последний ответ сервера или последняя сетевая ошибка.
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	req, err := rewindableRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	delay := t.Backoff
+	if delay <= 0 {
+		delay = DefaultRetryBackoff
+	}
+	for attempt := 0; ; attempt++ {
+		// Каждая попытка выполняется на копии: тело перематывается только у нее,
+		// запрос вызывающего кода не изменяется
+		attemptReq := req.Clone(req.Context())
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			attemptReq.Body = body
+		}
+
+		resp, err := base.RoundTrip(attemptReq)
+		if attempt >= t.MaxRetries || (err == nil && !t.retryableStatus(resp.StatusCode)) {
+			return resp, err
+		}
+
+		wait := jitteredDelay(delay, t.Jitter, rand.Float64())
+		if retryAfter, ok := retryAfterDelay(resp, time.Now(), t.MaxBackoff); ok {
+			// Сервер просит подождать дольше, чем осталось до отмены запроса - повтор не успеет
+			if deadline, ok := req.Context().Deadline(); ok && time.Until(deadline) < retryAfter {
+				return resp, nil
+			}
+			wait = retryAfter
+		}
+
+		// Освобождаем соединение перед повтором
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		if t.Wait != nil {
+			if err := t.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+
+		delay *= 2
+		if t.MaxBackoff > 0 && delay > t.MaxBackoff {
+			delay = t.MaxBackoff
+		}
+	}
+}
+
+// retryAfterDelay возвращает задержку из заголовка Retry-After ответа 429 или 503.
+// Значение задается числом секунд или HTTP-датой; дата в прошлом означает повтор без задержки.
+// Задержка ограничивается maxDelay (0 - без ограничения), чтобы сервер не мог задержать повтор на часы.
+func retryAfterDelay(resp *http.Response, now time.Time, maxDelay time.Duration) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = max(date.Sub(now), 0)
+	} else {
+		return 0, false
+	}
+	if maxDelay > 0 && delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay, true
+}
+
+// CloseIdleConnections закрывает простаивающие соединения базового транспорта
+func (t *RetryTransport) CloseIdleConnections() {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if closer, ok := base.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// rewindableRequest возвращает копию запроса, тело которой можно прочитать повторно.
+// Если у запроса есть тело без GetBody, оно читается в память.
+func rewindableRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return req, nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to buffer request body: %w", err)
+	}
+
+	clone := req.Clone(req.Context())
+	clone.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	clone.Body, _ = clone.GetBody()
+	clone.ContentLength = int64(len(data))
+	return clone, nil
+}
+
+// jitteredDelay отклоняет задержку delay на долю jitter; r - случайное число из [0, 1)
+func jitteredDelay(delay time.Duration, jitter, r float64) time.Duration {
+	if jitter <= 0 {
+		return delay
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	return time.Duration(float64(delay) * (1 - jitter + 2*jitter*r))
+}
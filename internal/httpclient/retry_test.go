@@ -0,0 +1,247 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// unavailableThenOK возвращает сервер, отвечающий 503 на первые failures запросов и 200 на остальные
+func unavailableThenOK(t *testing.T, failures int32, calls *int32, bodies chan<- string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if bodies != nil {
+			bodies <- string(body)
+		}
+		if atomic.AddInt32(calls, 1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRetryTransport_BuffersBodyWithoutGetBody(t *testing.T) {
+	var calls int32
+	bodies := make(chan string, 3)
+	server := unavailableThenOK(t, 1, &calls, bodies)
+
+	// io.NopCloser скрывает тип reader, поэтому http.NewRequest не заполняет GetBody
+	req, err := http.NewRequest(http.MethodPost, server.URL, io.NopCloser(strings.NewReader(`{"query":"ООО"}`)))
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	if req.GetBody != nil {
+		t.Fatal("Expected request without GetBody")
+	}
+
+	transport := &RetryTransport{MaxRetries: 2, Backoff: time.Millisecond}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 2 {
+		t.Fatalf("StatusCode = %d after %d attempts, want 200 after 2", resp.StatusCode, calls)
+	}
+	close(bodies)
+	for body := range bodies {
+		if body != `{"query":"ООО"}` {
+			t.Errorf("Attempt body = %q, want original payload", body)
+		}
+	}
+	if req.GetBody != nil {
+		t.Error("RoundTrip must not modify the caller's request")
+	}
+}
+
+func TestRetryTransport_RetryKeepsCallerRequest(t *testing.T) {
+	var calls int32
+	bodies := make(chan string, 3)
+	server := unavailableThenOK(t, 2, &calls, bodies)
+
+	// strings.Reader - http.NewRequest заполняет GetBody, тело перематывается без буферизации
+	req, err := http.NewRequest(http.MethodPost, server.URL, strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("NewRequest failed: %v", err)
+	}
+	originalBody := req.Body
+
+	transport := &RetryTransport{MaxRetries: 2, Backoff: time.Millisecond}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 3 {
+		t.Fatalf("StatusCode = %d after %d attempts, want 200 after 3", resp.StatusCode, calls)
+	}
+	close(bodies)
+	for body := range bodies {
+		if body != "payload" {
+			t.Errorf("Attempt body = %q, want original payload", body)
+		}
+	}
+	if req.Body != originalBody {
+		t.Error("RoundTrip must not replace the caller's request body")
+	}
+}
+
+func TestRetryTransport_RetryOnStatus(t *testing.T) {
+	var calls int32
+	server := unavailableThenOK(t, 1, &calls, nil)
+
+	// 503 не входит в заданный список - повтора нет
+	transport := &RetryTransport{MaxRetries: 2, Backoff: time.Millisecond, RetryOnStatus: []int{http.StatusInternalServerError}}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable || calls != 1 {
+		t.Errorf("StatusCode = %d after %d attempts, want 503 after 1", resp.StatusCode, calls)
+	}
+}
+
+func TestJitteredDelay(t *testing.T) {
+	delay := 100 * time.Millisecond
+
+	tests := []struct {
+		jitter, r float64
+		want      time.Duration
+	}{
+		{0, 0.9, delay},
+		{0.2, 0, 80 * time.Millisecond},
+		{0.2, 0.5, delay},
+		{0.2, 1, 120 * time.Millisecond},
+		{5, 0, 0},
+	}
+	for _, tt := range tests {
+		if got := jitteredDelay(delay, tt.jitter, tt.r); got != tt.want {
+			t.Errorf("jitteredDelay(%v, %v, %v) = %v, want %v", delay, tt.jitter, tt.r, got, tt.want)
+		}
+	}
+}
+
+func TestRetryAfterDelay(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	response := func(status int, retryAfter string) *http.Response {
+		resp := &http.Response{StatusCode: status, Header: http.Header{}}
+		if retryAfter != "" {
+			resp.Header.Set("Retry-After", retryAfter)
+		}
+		return resp
+	}
+
+	tests := []struct {
+		name     string
+		resp     *http.Response
+		maxDelay time.Duration
+		want     time.Duration
+		wantOK   bool
+	}{
+		{"seconds", response(http.StatusTooManyRequests, "3"), 0, 3 * time.Second, true},
+		{"http date", response(http.StatusServiceUnavailable, "Wed, 01 May 2024 12:00:10 GMT"), 0, 10 * time.Second, true},
+		{"date in the past", response(http.StatusServiceUnavailable, "Wed, 01 May 2024 11:00:00 GMT"), 0, 0, true},
+		{"seconds clamped", response(http.StatusTooManyRequests, "3600"), 5 * time.Second, 5 * time.Second, true},
+		{"http date clamped", response(http.StatusServiceUnavailable, "Wed, 01 May 2024 13:00:00 GMT"), 5 * time.Second, 5 * time.Second, true},
+		{"below max delay", response(http.StatusTooManyRequests, "3"), 5 * time.Second, 3 * time.Second, true},
+		{"no header", response(http.StatusTooManyRequests, ""), 0, 0, false},
+		{"invalid value", response(http.StatusTooManyRequests, "soon"), 0, 0, false},
+		{"other status", response(http.StatusBadGateway, "3"), 0, 0, false},
+		{"network error", nil, 0, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfterDelay(tt.resp, now, tt.maxDelay)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("%s: retryAfterDelay = %v, %v, want %v, %v", tt.name, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRetryTransport_RetryAfterBeyondDeadline(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+
+	// Повтор через 60 секунд не успеет до истечения контекста - возвращается ответ 429 без ожидания
+	transport := &RetryTransport{MaxRetries: 2, Backoff: time.Millisecond}
+	start := time.Now()
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusTooManyRequests || calls != 1 || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("StatusCode = %d after %d attempts in %v, want immediate 429", resp.StatusCode, calls, time.Since(start))
+	}
+}
+
+func TestRetryTransport_RetryAfterClampedToMaxBackoff(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Retry-After в час ограничивается MaxBackoff - повтор выполняется почти сразу
+	transport := &RetryTransport{MaxRetries: 1, Backoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	start := time.Now()
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 2 || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("StatusCode = %d after %d attempts in %v, want 200 after a clamped retry", resp.StatusCode, calls, time.Since(start))
+	}
+}
+
+func TestRetryTransport_WaitBeforeRetry(t *testing.T) {
+	var calls, waits int32
+	server := unavailableThenOK(t, 2, &calls, nil)
+
+	transport := &RetryTransport{MaxRetries: 3, Backoff: time.Millisecond, Wait: func(ctx context.Context) error {
+		atomic.AddInt32(&waits, 1)
+		return nil
+	}}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 3 || waits != 2 {
+		t.Errorf("StatusCode = %d after %d attempts and %d waits, want 200 after 3 attempts and 2 waits", resp.StatusCode, calls, waits)
+	}
+}
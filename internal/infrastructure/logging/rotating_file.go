@@ -0,0 +1,211 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat формат метки времени в имени архивного файла (сортируется лексикографически)
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// RotatingFile io.Writer для записи логов в файл с ротацией по размеру (по аналогии с lumberjack).
+// Когда запись превысила бы maxSize, текущий файл переименовывается в <имя>-<время><расширение>
+// и открывается новый. Архивные файлы старше maxAge удаляются, сверх maxBackups - тоже.
+type RotatingFile struct {
+	filename   string
+	maxSize    int64         // Максимальный размер файла в байтах (0 - без ротации)
+	maxAge     time.Duration // Срок хранения архивных файлов (0 - без ограничения)
+	maxBackups int           // Количество хранимых архивных файлов (0 - без ограничения)
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	now  func() time.Time
+}
+
+// NewRotatingFile открывает (или создает) файл логов и возвращает writer с ротацией
+func NewRotatingFile(filename string, maxSize int64, maxAge time.Duration, maxBackups int) (*RotatingFile, error) {
+	if filename == "" {
+		return nil, fmt.Errorf("log file name is required")
+	}
+
+	rf := &RotatingFile{
+		filename:   filename,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+		now:        time.Now,
+	}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	return rf, nil
+}
+
+// SetupStdLogger направляет стандартный логгер в stdout и файл с ротацией.
+// maxSizeMB, maxAgeDays и maxBackups задаются в единицах конфигурации (0 - без ограничения).
+// Возвращенный RotatingFile нужно закрыть при завершении работы.
+func SetupStdLogger(filename string, maxSizeMB, maxAgeDays, maxBackups int) (*RotatingFile, error) {
+	rf, err := NewRotatingFile(filename, int64(maxSizeMB)*1024*1024, time.Duration(maxAgeDays)*24*time.Hour, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	log.SetOutput(io.MultiWriter(os.Stdout, rf))
+	return rf, nil
+}
+
+// Write записывает данные, предварительно выполняя ротацию при превышении размера
+func (rf *RotatingFile) Write(p []byte) (int, error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		if err := rf.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	// Пустой файл не ротируем, даже если одна запись больше maxSize
+	if rf.maxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		if err := rf.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := rf.file.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+// Rotate принудительно начинает новый файл логов
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.rotate()
+}
+
+// Close закрывает текущий файл логов
+func (rf *RotatingFile) Close() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return nil
+	}
+	err := rf.file.Close()
+	rf.file = nil
+	return err
+}
+
+// open открывает файл логов на дозапись, создавая каталог при необходимости
+func (rf *RotatingFile) open() error {
+	if dir := filepath.Dir(rf.filename); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create log directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(rf.filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	rf.file = file
+	rf.size = info.Size()
+	return nil
+}
+
+// rotate переименовывает текущий файл в архивный, открывает новый и удаляет устаревшие архивы
+func (rf *RotatingFile) rotate() error {
+	if rf.file != nil {
+		if err := rf.file.Close(); err != nil {
+			return fmt.Errorf("failed to close log file: %w", err)
+		}
+		rf.file = nil
+	}
+
+	if err := os.Rename(rf.filename, rf.backupName(rf.now())); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rename log file: %w", err)
+	}
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	return rf.removeOldBackups()
+}
+
+// backupName возвращает имя архивного файла для указанного времени
+func (rf *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(rf.filename)
+	prefix := strings.TrimSuffix(rf.filename, ext)
+	return fmt.Sprintf("%s-%s%s", prefix, t.Format(backupTimeFormat), ext)
+}
+
+// Backups возвращает архивные файлы логов, от новых к старым
+func (rf *RotatingFile) Backups() ([]string, error) {
+	ext := filepath.Ext(rf.filename)
+	prefix := strings.TrimSuffix(rf.filename, ext) + "-"
+
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list log backups: %w", err)
+	}
+
+	backups := make([]string, 0, len(matches))
+	for _, match := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(match, prefix), ext)
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			backups = append(backups, match)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(backups)))
+
+	return backups, nil
+}
+
+// removeOldBackups удаляет архивы сверх maxBackups и старше maxAge
+func (rf *RotatingFile) removeOldBackups() error {
+	if rf.maxBackups <= 0 && rf.maxAge <= 0 {
+		return nil
+	}
+
+	backups, err := rf.Backups()
+	if err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(rf.filename)
+	prefix := strings.TrimSuffix(rf.filename, ext) + "-"
+	cutoff := rf.now().Add(-rf.maxAge)
+
+	for idx, backup := range backups {
+		remove := rf.maxBackups > 0 && idx >= rf.maxBackups
+		if !remove && rf.maxAge > 0 {
+			stamp, _ := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(backup, prefix), ext), time.Local)
+			remove = stamp.Before(cutoff)
+		}
+		if remove {
+			if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove old log backup: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
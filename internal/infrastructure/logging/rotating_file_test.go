@@ -0,0 +1,91 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotatingFile_RotatesAtMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "server.log")
+
+	rf, err := NewRotatingFile(path, 100, 0, 0)
+	if err != nil {
+		t.Fatalf("NewRotatingFile failed: %v", err)
+	}
+	defer rf.Close()
+
+	first := strings.Repeat("a", 60) + "\n"
+	second := strings.Repeat("b", 60) + "\n"
+
+	if _, err := rf.Write([]byte(first)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	backups, _ := rf.Backups()
+	if len(backups) != 0 {
+		t.Fatalf("Expected no rotation below max size, got backups %v", backups)
+	}
+
+	// Вторая запись превысила бы 100 байт - файл должен быть ротирован
+	if _, err := rf.Write([]byte(second)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	backups, err = rf.Backups()
+	if err != nil {
+		t.Fatalf("Backups failed: %v", err)
+	}
+	if len(backups) != 1 {
+		t.Fatalf("Expected 1 backup after rotation, got %v", backups)
+	}
+
+	archived, _ := os.ReadFile(backups[0])
+	if string(archived) != first {
+		t.Errorf("Backup content = %q, want %q", archived, first)
+	}
+	current, _ := os.ReadFile(path)
+	if string(current) != second {
+		t.Errorf("Current log content = %q, want %q", current, second)
+	}
+}
+
+func TestRotatingFile_RemovesOldBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "server.log")
+
+	rf, err := NewRotatingFile(path, 10, 24*time.Hour, 2)
+	if err != nil {
+		t.Fatalf("NewRotatingFile failed: %v", err)
+	}
+	defer rf.Close()
+
+	// Архив старше maxAge удаляется при следующей ротации
+	expired := rf.backupName(time.Now().Add(-48 * time.Hour))
+	if err := os.WriteFile(expired, []byte("old\n"), 0644); err != nil {
+		t.Fatalf("Failed to create expired backup: %v", err)
+	}
+
+	now := time.Now()
+	rf.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := rf.Write([]byte("0123456789\n")); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	backups, err := rf.Backups()
+	if err != nil {
+		t.Fatalf("Backups failed: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("Expected maxBackups=2 backups to be kept, got %v", backups)
+	}
+	if _, err := os.Stat(expired); !os.IsNotExist(err) {
+		t.Error("Expired backup should be removed")
+	}
+}
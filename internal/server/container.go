@@ -349,13 +349,18 @@ func (c *Container) InitServices() error {
 	// NormalizationBenchmarkService
 	c.NormalizationBenchmarkService = services.NewNormalizationBenchmarkService()
 
+	// Стоп-слова для ключевых слов номенклатуры и ГОСТов
+	normalization.SetStopwords(normalization.DefaultStopwordSet(c.Config.Stopwords...))
+
 	// GostsDB (опционально, может не инициализироваться)
 	gostsDB, err := database.NewGostsDB("gosts.db")
 	if err != nil {
 		log.Printf("Warning: failed to initialize GOSTs database: %v", err)
 	} else {
+		gostsDB.SetEncodingOptions(c.Config.GostEncodingOptions())
 		c.GostsDB = gostsDB
 		c.GostService = services.NewGostService(gostsDB)
+		c.GostService.SetSourcePriority(c.Config.GostSourcePriority)
 	}
 
 	// WorkerService, MonitoringService, NomenclatureService, DashboardService, PatternDetectionService
@@ -382,6 +387,7 @@ func (c *Container) InitHandlers() error {
 
 	// ClientHandler
 	c.ClientHandler = handlers.NewClientHandler(c.ClientService, c.BaseHandler)
+	c.ClientHandler.SetDocumentsDir(c.Config.ClientDocumentsDir)
 
 	// NormalizationHandler
 	c.NormalizationHandler = handlers.NewNormalizationHandler(
@@ -510,20 +516,42 @@ func (c *Container) InitHandlers() error {
 			TTL:             c.Config.WebSearch.CacheTTL,
 			CleanupInterval: c.Config.WebSearch.CacheTTL / 4,
 			MaxSize:         1000, // По умолчанию
+			PersistPath:     websearch.CachePersistPathFor(c.Config.WebSearch.CachePersistPath, "server"),
 		}
 		searchCache := websearch.NewCache(cacheConfig)
 
 		// Создаем клиент веб-поиска
 		// Преобразуем RateLimitPerSec в rate.Limit
+		searchMode, err := websearch.ParseSearchMode(c.Config.WebSearch.SearchMode)
+		if err != nil {
+			return fmt.Errorf("invalid web search config: %w", err)
+		}
 		rateLimit := rate.Every(time.Duration(1000/c.Config.WebSearch.RateLimitPerSec) * time.Millisecond)
 		clientConfig := websearch.ClientConfig{
-			BaseURL:    c.Config.WebSearch.BaseURL,
-			Timeout:    c.Config.WebSearch.Timeout,
-			RateLimit:  rateLimit,
-			Cache:      searchCache,
+			BaseURL:     c.Config.WebSearch.BaseURL,
+			HTMLBaseURL: c.Config.WebSearch.HTMLBaseURL,
+			SearchMode:  searchMode,
+			Timeout:     c.Config.WebSearch.Timeout,
+			RateLimit:   rateLimit,
+			Cache:       searchCache,
 		}
 		searchClient := websearch.NewClient(clientConfig)
 
+		// Произвольный HTML-поиск вместо DuckDuckGo
+		if c.Config.WebSearch.HTMLSearchURL != "" {
+			searchClient, err = websearch.NewHTMLSearchClient(websearch.HTMLSearchConfig{
+				URLTemplate:  c.Config.WebSearch.HTMLSearchURL,
+				ResultClass:  c.Config.WebSearch.HTMLResultClass,
+				SnippetClass: c.Config.WebSearch.HTMLSnippetClass,
+				Timeout:      c.Config.WebSearch.Timeout,
+				RateLimit:    rateLimit,
+				Cache:        searchCache,
+			})
+			if err != nil {
+				return fmt.Errorf("invalid web search config: %w", err)
+			}
+		}
+
 		// Создаем handler
 		c.WebSearchHandler = handlers.NewWebSearchHandler(c.BaseHandler, searchClient)
 	}
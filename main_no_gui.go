@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"httpserver/database"
+	"httpserver/internal/infrastructure/logging"
 	"httpserver/server"
 )
 
@@ -107,6 +108,17 @@ func main() {
 		log.Printf("✓ Конфигурация загружена из БД")
 	}
 
+	// Дублируем логи в файл с ротацией, если он задан (по умолчанию только stdout)
+	if config.LogFile != "" {
+		logFile, err := logging.SetupStdLogger(config.LogFile, config.LogMaxSizeMB, config.LogMaxAgeDays, config.LogMaxBackups)
+		if err != nil {
+			log.Printf("Предупреждение: не удалось открыть файл логов %s: %v", config.LogFile, err)
+		} else {
+			defer logFile.Close()
+			log.Printf("Логи записываются в файл: %s", config.LogFile)
+		}
+	}
+
 	// Если конфигурации нет в БД, сохраняем текущую из env
 	configJSON, _ := serviceDB.GetAppConfig()
 	if configJSON == "" {
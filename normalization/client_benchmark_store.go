@@ -87,7 +87,7 @@ func (s *ClientBenchmarkStore) SavePotentialBenchmark(originalName, normalizedNa
 		return nil
 	}
 
-	_, err := s.db.CreateClientBenchmark(
+	benchmark, err := s.db.CreateClientBenchmark(
 		s.projectID,
 		originalName,
 		normalizedName,
@@ -97,8 +97,12 @@ func (s *ClientBenchmarkStore) SavePotentialBenchmark(originalName, normalizedNa
 		"", // source_database
 		qualityScore,
 	)
+	if err != nil {
+		return err
+	}
 
-	return err
+	// Сохраняем ключевые слова для поиска по эталонам
+	return s.db.UpdateBenchmarkKeywords(benchmark.ID, ExtractKeywords(normalizedName))
 }
 
 // GetBenchmarksByCategory получает эталоны по категории
@@ -10,8 +10,8 @@ import (
 
 // CounterpartyDuplicateGroup группа дубликатов контрагентов
 type CounterpartyDuplicateGroup struct {
-	Key        string // Ключ группы (ИНН/КПП или БИН)
-	KeyType    string // Тип ключа: "inn_kpp", "bin"
+	Key        string // Ключ группы (ИНН/КПП, БИН или ОГРН)
+	KeyType    string // Тип ключа: "inn_kpp", "bin", "ogrn"
 	Items      []*CounterpartyDuplicateItem
 	MasterItem *CounterpartyDuplicateItem // Рекомендуемая основная запись
 	Confidence float64                    // Уверенность в том, что это дубликаты (1.0 для ИНН/КПП и БИН)
@@ -27,6 +27,7 @@ type CounterpartyDuplicateItem struct {
 	INN                  string
 	KPP                  string
 	BIN                  string
+	OGRN                 string // ОГРН или ОГРНИП (только допустимой длины, см. extractors.NormalizeOGRN)
 	LegalAddress         string
 	PostalAddress        string
 	ContactPhone         string
@@ -52,7 +53,8 @@ func NewCounterpartyDuplicateAnalyzer() *CounterpartyDuplicateAnalyzer {
 	return &CounterpartyDuplicateAnalyzer{}
 }
 
-// AnalyzeDuplicates анализирует контрагентов на наличие дублей по ИНН/КПП и БИН
+// AnalyzeDuplicates анализирует контрагентов на наличие дублей по ИНН/КПП и БИН,
+// а записи без ИНН и БИН - по ОГРН
 func (cda *CounterpartyDuplicateAnalyzer) AnalyzeDuplicates(counterparties []*database.CatalogItem) []CounterpartyDuplicateGroup {
 	groups := []CounterpartyDuplicateGroup{}
 
@@ -64,6 +66,10 @@ func (cda *CounterpartyDuplicateAnalyzer) AnalyzeDuplicates(counterparties []*da
 	binGroups := cda.groupByBIN(counterparties)
 	groups = append(groups, binGroups...)
 
+	// 2.1. Группируем по ОГРН записи без ИНН и БИН (частый случай в выгрузках 1С)
+	ogrnGroups := cda.groupByOGRN(counterparties)
+	groups = append(groups, ogrnGroups...)
+
 	// 3. Объединяем пересекающиеся группы (если у контрагента есть и ИНН/КПП, и БИН)
 	mergedGroups := cda.mergeOverlappingGroups(groups)
 
@@ -151,6 +157,41 @@ func (cda *CounterpartyDuplicateAnalyzer) groupByBIN(counterparties []*database.
 	return groups
 }
 
+// groupByOGRN группирует по ОГРН контрагентов, у которых нет ни ИНН, ни БИН.
+// Записи с ИНН или БИН уже сгруппированы по ним, ОГРН для них не используется.
+func (cda *CounterpartyDuplicateAnalyzer) groupByOGRN(counterparties []*database.CatalogItem) []CounterpartyDuplicateGroup {
+	groups := []CounterpartyDuplicateGroup{}
+	ogrnMap := make(map[string][]*CounterpartyDuplicateItem)
+
+	for _, item := range counterparties {
+		if inn, _ := extractors.ExtractINNFromAttributes(item.Attributes); inn != "" {
+			continue
+		}
+		if bin, _ := extractors.ExtractBINFromAttributes(item.Attributes); bin != "" {
+			continue
+		}
+		ogrn, err := extractors.ExtractOGRNFromAttributes(item.Attributes)
+		if err != nil || ogrn == "" {
+			continue // Пропускаем, если нет ОГРН допустимой длины
+		}
+
+		ogrnMap[ogrn] = append(ogrnMap[ogrn], cda.catalogItemToDuplicateItem(item, "", "", ""))
+	}
+
+	for ogrn, items := range ogrnMap {
+		if len(items) > 1 {
+			groups = append(groups, CounterpartyDuplicateGroup{
+				Key:        ogrn,
+				KeyType:    "ogrn",
+				Items:      items,
+				Confidence: 1.0, // ОГРН уникален так же, как ИНН
+			})
+		}
+	}
+
+	return groups
+}
+
 // mergeOverlappingGroups объединяет пересекающиеся группы
 // Например, если контрагент имеет и ИНН/КПП, и БИН, и они попадают в разные группы
 func (cda *CounterpartyDuplicateAnalyzer) mergeOverlappingGroups(groups []CounterpartyDuplicateGroup) []CounterpartyDuplicateGroup {
@@ -308,8 +349,8 @@ func (cda *CounterpartyDuplicateAnalyzer) SelectMasterRecordWithStrategy(items [
 func (cda *CounterpartyDuplicateAnalyzer) calculateMasterScore(item *CounterpartyDuplicateItem) float64 {
 	score := 0.0
 
-	// Наличие ИНН/КПП/БИН (обязательно)
-	if item.INN != "" || item.BIN != "" {
+	// Наличие ИНН/КПП/БИН/ОГРН (обязательно)
+	if item.INN != "" || item.BIN != "" || item.OGRN != "" {
 		score += 30.0
 	}
 
@@ -395,6 +436,9 @@ func (cda *CounterpartyDuplicateAnalyzer) countFilledFields(item *CounterpartyDu
 	if item.BIN != "" {
 		count++
 	}
+	if item.OGRN != "" {
+		count++
+	}
 	if item.KPP != "" {
 		count++
 	}
@@ -480,6 +524,9 @@ func (cda *CounterpartyDuplicateAnalyzer) catalogItemToDuplicateItem(item *datab
 				duplicateItem.BIN = extractedBIN
 			}
 		}
+		if ogrn, err := extractors.ExtractOGRNFromAttributes(item.Attributes); err == nil {
+			duplicateItem.OGRN = ogrn
+		}
 
 		// Извлекаем адреса
 		if addr, err := extractors.ExtractAddressFromAttributes(item.Attributes); err == nil {
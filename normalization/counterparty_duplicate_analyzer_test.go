@@ -809,3 +809,65 @@ func TestGetDuplicateSummary_Empty(t *testing.T) {
 		t.Errorf("Expected total_duplicates 0, got %v", summary["total_duplicates"])
 	}
 }
+
+// createTestCounterpartyItemWithOGRN создает тестовый элемент контрагента только с ОГРН
+func createTestCounterpartyItemWithOGRN(id int, name, ogrn string) *database.CatalogItem {
+	return &database.CatalogItem{
+		ID:         id,
+		Reference:  "ref_" + name,
+		Code:       "code_" + name,
+		Name:       name,
+		Attributes: `<ОГРН>` + ogrn + `</ОГРН>`,
+	}
+}
+
+// TestAnalyzeDuplicates_OGRNOnly проверяет группировку по ОГРН записей без ИНН и БИН
+func TestAnalyzeDuplicates_OGRNOnly(t *testing.T) {
+	analyzer := NewCounterpartyDuplicateAnalyzer()
+
+	counterparties := []*database.CatalogItem{
+		createTestCounterpartyItemWithOGRN(1, "ООО Ромашка", "1027700132195"),
+		createTestCounterpartyItemWithOGRN(2, "Ромашка ООО", "1027700132195"),
+		createTestCounterpartyItemWithOGRN(3, "ИП Иванов", "304500116000157"),
+		// ОГРН недопустимой длины не используется как ключ
+		createTestCounterpartyItemWithOGRN(4, "ООО Лютик", "10277001321"),
+		createTestCounterpartyItemWithOGRN(5, "Лютик ООО", "10277001321"),
+	}
+
+	groups := analyzer.AnalyzeDuplicates(counterparties)
+	if len(groups) != 1 {
+		t.Fatalf("Expected 1 duplicate group, got %d: %+v", len(groups), groups)
+	}
+
+	group := groups[0]
+	if group.KeyType != "ogrn" || group.Key != "1027700132195" {
+		t.Errorf("Expected ogrn group 1027700132195, got %s %s", group.KeyType, group.Key)
+	}
+	if len(group.Items) != 2 {
+		t.Fatalf("Expected 2 items in group, got %d", len(group.Items))
+	}
+	for _, item := range group.Items {
+		if item.INN != "" || item.OGRN != "1027700132195" {
+			t.Errorf("Item %d: INN = %q, OGRN = %q", item.ID, item.INN, item.OGRN)
+		}
+	}
+	if group.MasterItem == nil {
+		t.Error("Expected master item for ogrn group")
+	}
+}
+
+// TestAnalyzeDuplicates_OGRNIgnoredWithINN проверяет, что записи с ИНН группируются по ИНН, а не по ОГРН
+func TestAnalyzeDuplicates_OGRNIgnoredWithINN(t *testing.T) {
+	analyzer := NewCounterpartyDuplicateAnalyzer()
+
+	withINN := createTestCounterpartyItem(1, "ООО Ромашка", "7707083893", "", "")
+	withINN.Attributes += `<ОГРН>1027700132195</ОГРН>`
+	counterparties := []*database.CatalogItem{
+		withINN,
+		createTestCounterpartyItemWithOGRN(2, "Ромашка ООО", "1027700132195"),
+	}
+
+	if groups := analyzer.AnalyzeDuplicates(counterparties); len(groups) != 0 {
+		t.Errorf("Expected no groups, got %+v", groups)
+	}
+}
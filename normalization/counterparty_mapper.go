@@ -13,15 +13,47 @@ type CounterpartyMapper struct {
 	serviceDB *database.ServiceDB
 	logger    *slog.Logger
 	analyzer  *CounterpartyDuplicateAnalyzer
+	// maxNameChanges ограничение списка изменений в сводке NormalizeNamesForProject
+	maxNameChanges int
+	// nameBatchSize сколько контрагентов NormalizeNamesForProject читает и обновляет за одну транзакцию
+	nameBatchSize int
+	// legalForms словарь ОПФ для NormalizeNamesForProject
+	legalForms *legalFormDictionary
+}
+
+// MapperOptions настройки CounterpartyMapper
+type MapperOptions struct {
+	// LegalFormMap дополнительные написания организационно-правовых форм: написание -> каноническая ОПФ,
+	// например {"ЖШС": "ТОО", "Жауапкершілігі шектеулі серіктестік": "ТОО", "АҚ": "АО"}.
+	// Правила нормализации в NormalizeNamesForProject:
+	//   - ОПФ выделяется из начала или конца названия ("ТОО «Арман»", "Арман ЖШС") без учета регистра,
+	//     более длинные написания проверяются раньше коротких;
+	//   - значения legal_form сравниваются без пробелов, точек, дефисов и кавычек ("Т.О.О." = "ТОО");
+	//   - заполненный legal_form приоритетнее ОПФ из названия; неизвестное значение сохраняется как есть.
+	// Написания дополняют встроенный словарь (ООО, АО, ПАО, ТОО, ИП, LLC и др.), а совпадающие
+	// с встроенными без учета регистра переопределяют их каноническую форму.
+	LegalFormMap map[string]string
 }
 
 // NewCounterpartyMapper создает новый сервис мэппинга контрагентов
 func NewCounterpartyMapper(serviceDB *database.ServiceDB) *CounterpartyMapper {
+	return NewCounterpartyMapperWithOptions(serviceDB, MapperOptions{})
+}
+
+// NewCounterpartyMapperWithOptions создает сервис мэппинга контрагентов с настройками options
+func NewCounterpartyMapperWithOptions(serviceDB *database.ServiceDB, options MapperOptions) *CounterpartyMapper {
 	logger := slog.Default().With("component", "counterparty_mapper")
+	legalForms := defaultLegalForms
+	if len(options.LegalFormMap) > 0 {
+		legalForms = newLegalFormDictionary(options.LegalFormMap)
+	}
 	return &CounterpartyMapper{
-		serviceDB: serviceDB,
-		logger:    logger,
-		analyzer:  NewCounterpartyDuplicateAnalyzer(),
+		serviceDB:      serviceDB,
+		logger:         logger,
+		analyzer:       NewCounterpartyDuplicateAnalyzer(),
+		maxNameChanges: DefaultMaxCounterpartyNameChanges,
+		nameBatchSize:  counterpartyNameBatchSize,
+		legalForms:     legalForms,
 	}
 }
 
@@ -365,6 +397,15 @@ func (cm *CounterpartyMapper) findAndMergeDuplicatesWithConfig(projectID int, co
 				continue
 			}
 
+			// Эталон без ОГРН получает ОГРН дубликата
+			if duplicateItem.OGRN != "" {
+				if err := cm.serviceDB.SetNormalizedCounterpartyOGRN(masterNormalized.ID, duplicateItem.OGRN); err != nil {
+					cm.logger.Warn("Failed to transfer OGRN from duplicate to master",
+						"error", err,
+						"master_id", masterNormalized.ID)
+				}
+			}
+
 			// Переносим все связи с базами данных из дубликата в эталон
 			duplicateDatabases, err := cm.serviceDB.GetCounterpartyDatabases(duplicateNormalized.ID)
 			databasesTransferred := 0
@@ -582,6 +623,13 @@ func (cm *CounterpartyMapper) getOrCreateNormalizedCounterparty(projectID int, i
 		return nil, fmt.Errorf("created normalized counterparty is nil (reference: %s)", item.Reference)
 	}
 
+	// ОГРН сохраняется отдельно: по нему группируются контрагенты без ИНН и БИН
+	if item.OGRN != "" {
+		if err := cm.serviceDB.SetNormalizedCounterpartyOGRN(normalized.ID, item.OGRN); err != nil {
+			cm.logger.Warn("Failed to save counterparty OGRN", "error", err, "counterparty_id", normalized.ID)
+		}
+	}
+
 	// Создаем связь с базой данных
 	if databaseID > 0 {
 		if err := cm.serviceDB.SaveCounterpartyDatabaseLink(normalized.ID, databaseID, item.Reference, item.Name); err != nil {
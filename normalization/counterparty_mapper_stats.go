@@ -64,7 +64,8 @@ func (cm *CounterpartyMapper) GetNormalizedCounterpartyStats(projectID int) (*Co
 		FROM normalized_counterparties 
 		WHERE client_project_id = ? 
 		  AND COALESCE(tax_id, '') = '' 
-		  AND COALESCE(bin, '') = ''`, projectID).Scan(&unmatched); err != nil && err != sql.ErrNoRows {
+		  AND COALESCE(bin, '') = ''
+		  AND LENGTH(COALESCE(ogrn, '')) NOT IN (13, 15)`, projectID).Scan(&unmatched); err != nil && err != sql.ErrNoRows {
 		return nil, fmt.Errorf("failed to count unmatched records: %w", err)
 	}
 	report.UnmatchedRecords = unmatched
@@ -102,17 +103,28 @@ func (cm *CounterpartyMapper) collectDuplicateGroups(projectID int, groups map[s
 	if err := cm.collectGroupedDuplicates(projectID, "bin", groups); err != nil {
 		return err
 	}
+	// По ОГРН группируются только записи без ИНН и БИН, остальные уже учтены выше
+	if err := cm.collectGroupedDuplicates(projectID, "ogrn", groups,
+		"COALESCE(tax_id, '') = ''", "COALESCE(bin, '') = ''", "LENGTH(ogrn) IN (13, 15)"); err != nil {
+		return err
+	}
 	return nil
 }
 
-func (cm *CounterpartyMapper) collectGroupedDuplicates(projectID int, column string, groups map[string]*DuplicateGroupSummary) error {
+// collectGroupedDuplicates добавляет в groups значения column, встречающиеся больше одного раза.
+// conditions - дополнительные условия отбора записей (объединяются через AND).
+func (cm *CounterpartyMapper) collectGroupedDuplicates(projectID int, column string, groups map[string]*DuplicateGroupSummary, conditions ...string) error {
+	filter := ""
+	for _, condition := range conditions {
+		filter += " AND " + condition
+	}
 	query := fmt.Sprintf(`
 		SELECT %[1]s, COUNT(*) as cnt
 		FROM normalized_counterparties
-		WHERE client_project_id = ? AND %[1]s IS NOT NULL AND %[1]s != ''
+		WHERE client_project_id = ? AND %[1]s IS NOT NULL AND %[1]s != ''%[2]s
 		GROUP BY %[1]s
 		HAVING cnt > 1
-	`, column)
+	`, column, filter)
 
 	rows, err := cm.serviceDB.Query(query, projectID)
 	if err != nil {
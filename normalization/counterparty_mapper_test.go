@@ -306,3 +306,56 @@ func TestCounterpartyDuplicateAnalyzer_SelectMasterRecordWithStrategy_SingleItem
 		t.Errorf("Expected master ID 1, got %d", master.ID)
 	}
 }
+
+func TestCounterpartyMapper_MergeByOGRNAndStats(t *testing.T) {
+	mapper, serviceDB := setupTestMapper(t)
+
+	client := createTestClientForMapper(t, serviceDB)
+	project, err := serviceDB.CreateClientProject(client.ID, "OGRN Project", "test", "", "test_system", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	items := []*database.CatalogItem{
+		createTestCounterpartyItemWithOGRN(1, "ООО Ромашка", "1027700132195"),
+		createTestCounterpartyItemWithOGRN(2, "Ромашка ООО", "1027700132195"),
+	}
+	if err := mapper.findAndMergeDuplicates(project.ID, items, 0); err != nil {
+		t.Fatalf("findAndMergeDuplicates failed: %v", err)
+	}
+
+	// Записи без идентификаторов и с ОГРН недопустимой длины остаются несопоставленными
+	for _, name := range []string{"Без реквизитов", "Неверный ОГРН"} {
+		if err := serviceDB.SaveNormalizedCounterparty(project.ID, "ref_"+name, name, name,
+			"", "", "", "", "", "", "", "", "", "", "", "", "",
+			0, 0.5, false, "", "", ""); err != nil {
+			t.Fatalf("Failed to save counterparty %q: %v", name, err)
+		}
+	}
+	invalid, err := serviceDB.GetNormalizedCounterpartyBySourceReference(project.ID, "ref_Неверный ОГРН")
+	if err != nil {
+		t.Fatalf("Failed to get counterparty: %v", err)
+	}
+	if err := serviceDB.SetNormalizedCounterpartyOGRN(invalid.ID, "10277001321"); err != nil {
+		t.Fatalf("SetNormalizedCounterpartyOGRN failed: %v", err)
+	}
+
+	stats, err := mapper.GetNormalizedCounterpartyStats(project.ID)
+	if err != nil {
+		t.Fatalf("GetNormalizedCounterpartyStats failed: %v", err)
+	}
+
+	if stats.TotalMappedCounterparties != 4 {
+		t.Errorf("TotalMappedCounterparties = %d, want 4", stats.TotalMappedCounterparties)
+	}
+	if stats.UnmatchedRecords != 2 {
+		t.Errorf("UnmatchedRecords = %d, want 2", stats.UnmatchedRecords)
+	}
+	if len(stats.TopGroups) != 1 {
+		t.Fatalf("TopGroups = %+v, want one ogrn group", stats.TopGroups)
+	}
+	group := stats.TopGroups[0]
+	if group.KeyType != "ogrn" || group.Identifier != "1027700132195" || group.Count != 2 {
+		t.Errorf("TopGroups[0] = %+v", group)
+	}
+}
@@ -0,0 +1,185 @@
+package normalization
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Причины изменения в файле предлагаемых изменений
+const (
+	CounterpartyChangeReasonName      = "name_cleaned"
+	CounterpartyChangeReasonLegalForm = "legal_form_normalized"
+)
+
+// counterpartyNameChangesHeader заголовок CSV файла предлагаемых изменений
+var counterpartyNameChangesHeader = []string{"id", "old_name", "new_name", "old_legal_form", "new_legal_form", "reason"}
+
+// CounterpartyNameChange предлагаемое изменение названия и ОПФ нормализованного контрагента
+type CounterpartyNameChange struct {
+	ID           int    `json:"id"`
+	OldName      string `json:"old_name"`
+	NewName      string `json:"new_name"`
+	OldLegalForm string `json:"old_legal_form"`
+	NewLegalForm string `json:"new_legal_form"`
+	Reason       string `json:"reason"`
+}
+
+// CounterpartyNameApplySummary результат применения файла предлагаемых изменений
+type CounterpartyNameApplySummary struct {
+	ProjectID    int   `json:"project_id"`
+	TotalChanges int   `json:"total_changes"`
+	Applied      int   `json:"applied"`
+	StaleIDs     []int `json:"stale_ids,omitempty"` // Записи удалены или изменены после dry run - пропущены
+}
+
+func counterpartyNameChangeReason(updatedName, updatedForm bool) string {
+	var reasons []string
+	if updatedName {
+		reasons = append(reasons, CounterpartyChangeReasonName)
+	}
+	if updatedForm {
+		reasons = append(reasons, CounterpartyChangeReasonLegalForm)
+	}
+	return strings.Join(reasons, ";")
+}
+
+// WriteCounterpartyNameChangesCSV записывает предлагаемые изменения в CSV для ручной проверки
+func WriteCounterpartyNameChangesCSV(w io.Writer, changes []CounterpartyNameChange) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(counterpartyNameChangesHeader); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	for _, change := range changes {
+		record := []string{
+			strconv.Itoa(change.ID),
+			change.OldName,
+			change.NewName,
+			change.OldLegalForm,
+			change.NewLegalForm,
+			change.Reason,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("failed to write change %d: %w", change.ID, err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush changes: %w", err)
+	}
+	return nil
+}
+
+// ReadCounterpartyNameChangesCSV читает файл предлагаемых изменений, записанный WriteCounterpartyNameChangesCSV.
+// Строки, удаленные из файла при проверке, не применяются; new_name и new_legal_form можно исправить вручную.
+func ReadCounterpartyNameChangesCSV(r io.Reader) ([]CounterpartyNameChange, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(counterpartyNameChangesHeader)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	for i, column := range counterpartyNameChangesHeader {
+		if strings.TrimPrefix(header[i], "\ufeff") != column {
+			return nil, fmt.Errorf("unexpected column %q at position %d, expected %q", header[i], i+1, column)
+		}
+	}
+
+	var changes []CounterpartyNameChange
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read line %d: %w", line, err)
+		}
+
+		id, err := strconv.Atoi(strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid id %q on line %d: %w", record[0], line, err)
+		}
+		if strings.TrimSpace(record[2]) == "" {
+			return nil, fmt.Errorf("empty new_name on line %d", line)
+		}
+
+		changes = append(changes, CounterpartyNameChange{
+			ID:           id,
+			OldName:      record[1],
+			NewName:      record[2],
+			OldLegalForm: record[3],
+			NewLegalForm: record[4],
+			Reason:       record[5],
+		})
+	}
+
+	return changes, nil
+}
+
+// ApplyCounterpartyNameChanges применяет ровно переданные изменения к контрагентам проекта.
+// Изменение пропускается (StaleIDs), если название или ОПФ записи отличаются от old_name/old_legal_form,
+// т.е. запись изменилась после dry run.
+func (cm *CounterpartyMapper) ApplyCounterpartyNameChanges(projectID int, changes []CounterpartyNameChange) (*CounterpartyNameApplySummary, error) {
+	if cm.serviceDB == nil {
+		return nil, fmt.Errorf("serviceDB is nil")
+	}
+
+	if _, err := cm.serviceDB.GetClientProject(projectID); err != nil {
+		return nil, fmt.Errorf("project %d not found: %w", projectID, err)
+	}
+
+	summary := &CounterpartyNameApplySummary{
+		ProjectID:    projectID,
+		TotalChanges: len(changes),
+	}
+
+	tx, err := cm.serviceDB.GetDB().Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	updateStmt := `
+		UPDATE normalized_counterparties
+		SET normalized_name = ?, legal_form = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND client_project_id = ?
+		  AND COALESCE(normalized_name, '') = ? AND COALESCE(legal_form, '') = ?
+	`
+
+	for _, change := range changes {
+		legalFormValue := sql.NullString{String: change.NewLegalForm, Valid: change.NewLegalForm != ""}
+		result, err := tx.Exec(updateStmt, change.NewName, legalFormValue,
+			change.ID, projectID, change.OldName, change.OldLegalForm)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update counterparty %d: %w", change.ID, err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to check update of counterparty %d: %w", change.ID, err)
+		}
+		if affected == 0 {
+			summary.StaleIDs = append(summary.StaleIDs, change.ID)
+			continue
+		}
+		summary.Applied++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit normalization changes: %w", err)
+	}
+
+	cm.logger.Info("Applied counterparty name changes",
+		"project_id", projectID,
+		"total", summary.TotalChanges,
+		"applied", summary.Applied,
+		"stale", len(summary.StaleIDs))
+
+	return summary, nil
+}
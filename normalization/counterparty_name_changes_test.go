@@ -0,0 +1,233 @@
+package normalization
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"httpserver/database"
+)
+
+func seedCounterpartiesForNameChanges(t *testing.T, serviceDB *database.ServiceDB) int {
+	t.Helper()
+	client := createTestClientForMapper(t, serviceDB)
+	project, err := serviceDB.CreateClientProject(client.ID, "Контрагенты", "counterparty", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	for i, name := range []string{`ООО "Ромашка"`, `Лютик АО`, `Василек`} {
+		err := serviceDB.SaveNormalizedCounterparty(project.ID, "ref_"+name, name, name,
+			"77070838"+string(rune('0'+i))+"0", "", "", "", "", "", "", "", "", "", "", "", "",
+			0, 0.5, false, "", "", "")
+		if err != nil {
+			t.Fatalf("Failed to save counterparty %q: %v", name, err)
+		}
+	}
+	return project.ID
+}
+
+func TestCounterpartyMapper_DryRunWritesProposedChanges(t *testing.T) {
+	mapper, serviceDB := setupTestMapper(t)
+	projectID := seedCounterpartiesForNameChanges(t, serviceDB)
+
+	summary, err := mapper.NormalizeNamesForProject(projectID, true)
+	if err != nil {
+		t.Fatalf("NormalizeNamesForProject failed: %v", err)
+	}
+	if len(summary.Changes) != summary.UpdatedRecords || len(summary.Changes) != 2 {
+		t.Fatalf("Expected 2 proposed changes, got %d (updated records: %d)", len(summary.Changes), summary.UpdatedRecords)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCounterpartyNameChangesCSV(&buf, summary.Changes); err != nil {
+		t.Fatalf("WriteCounterpartyNameChangesCSV failed: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "id,old_name,new_name,old_legal_form,new_legal_form,reason\n") {
+		t.Errorf("Unexpected CSV header: %q", buf.String())
+	}
+
+	changes, err := ReadCounterpartyNameChangesCSV(&buf)
+	if err != nil {
+		t.Fatalf("ReadCounterpartyNameChangesCSV failed: %v", err)
+	}
+	if len(changes) != 2 || changes[0] != summary.Changes[0] || changes[1] != summary.Changes[1] {
+		t.Errorf("CSV round trip mismatch: got %+v, want %+v", changes, summary.Changes)
+	}
+	if changes[0].NewName != "Ромашка" || changes[0].NewLegalForm != "ООО" ||
+		changes[0].Reason != CounterpartyChangeReasonName+";"+CounterpartyChangeReasonLegalForm {
+		t.Errorf("Unexpected change for ООО \"Ромашка\": %+v", changes[0])
+	}
+
+	// Dry run ничего не записывает
+	var name string
+	if err := serviceDB.QueryRow(`SELECT normalized_name FROM normalized_counterparties WHERE id = ?`, changes[0].ID).Scan(&name); err != nil {
+		t.Fatalf("Failed to read counterparty: %v", err)
+	}
+	if name != `ООО "Ромашка"` {
+		t.Errorf("Dry run modified counterparty name: %q", name)
+	}
+}
+
+func TestCounterpartyMapper_ApplyCounterpartyNameChanges(t *testing.T) {
+	mapper, serviceDB := setupTestMapper(t)
+	projectID := seedCounterpartiesForNameChanges(t, serviceDB)
+
+	summary, err := mapper.NormalizeNamesForProject(projectID, true)
+	if err != nil {
+		t.Fatalf("NormalizeNamesForProject failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCounterpartyNameChangesCSV(&buf, summary.Changes); err != nil {
+		t.Fatalf("WriteCounterpartyNameChangesCSV failed: %v", err)
+	}
+
+	// При проверке оставляем только первую строку и правим предложенное название
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	reviewed := lines[0] + "\n" + strings.Replace(lines[1], ",Ромашка,", ",Ромашка Плюс,", 1) + "\n"
+
+	changes, err := ReadCounterpartyNameChangesCSV(strings.NewReader(reviewed))
+	if err != nil {
+		t.Fatalf("ReadCounterpartyNameChangesCSV failed: %v", err)
+	}
+
+	applied, err := mapper.ApplyCounterpartyNameChanges(projectID, changes)
+	if err != nil {
+		t.Fatalf("ApplyCounterpartyNameChanges failed: %v", err)
+	}
+	if applied.TotalChanges != 1 || applied.Applied != 1 || len(applied.StaleIDs) != 0 {
+		t.Errorf("Unexpected apply summary: %+v", applied)
+	}
+
+	var name, legalForm string
+	err = serviceDB.QueryRow(`SELECT normalized_name, COALESCE(legal_form, '') FROM normalized_counterparties WHERE id = ?`,
+		changes[0].ID).Scan(&name, &legalForm)
+	if err != nil {
+		t.Fatalf("Failed to read counterparty: %v", err)
+	}
+	if name != "Ромашка Плюс" || legalForm != "ООО" {
+		t.Errorf("Reviewed change not applied: name=%q legal_form=%q", name, legalForm)
+	}
+
+	// Удаленная из файла строка не применяется
+	err = serviceDB.QueryRow(`SELECT normalized_name FROM normalized_counterparties WHERE id = ?`,
+		summary.Changes[1].ID).Scan(&name)
+	if err != nil {
+		t.Fatalf("Failed to read counterparty: %v", err)
+	}
+	if name != summary.Changes[1].OldName {
+		t.Errorf("Change removed during review was applied: %q", name)
+	}
+
+	// Повторное применение того же файла пропускает уже измененные записи
+	applied, err = mapper.ApplyCounterpartyNameChanges(projectID, changes)
+	if err != nil {
+		t.Fatalf("ApplyCounterpartyNameChanges failed: %v", err)
+	}
+	if applied.Applied != 0 || len(applied.StaleIDs) != 1 || applied.StaleIDs[0] != changes[0].ID {
+		t.Errorf("Expected stale change on second apply, got %+v", applied)
+	}
+}
+
+func TestReadCounterpartyNameChangesCSV_InvalidHeader(t *testing.T) {
+	_, err := ReadCounterpartyNameChangesCSV(strings.NewReader("id,name\n1,Ромашка\n"))
+	if err == nil {
+		t.Error("Expected error for invalid header")
+	}
+}
+
+func TestCounterpartyMapper_DryRunChangesMatchAppliedUpdates(t *testing.T) {
+	mapper, serviceDB := setupTestMapper(t)
+	projectID := seedCounterpartiesForNameChanges(t, serviceDB)
+
+	proposed, err := mapper.NormalizeNamesForProject(projectID, true)
+	if err != nil {
+		t.Fatalf("NormalizeNamesForProject (dry run) failed: %v", err)
+	}
+	applied, err := mapper.NormalizeNamesForProject(projectID, false)
+	if err != nil {
+		t.Fatalf("NormalizeNamesForProject failed: %v", err)
+	}
+
+	if applied.AppliedUpdates != len(proposed.Changes) || len(applied.Changes) != len(proposed.Changes) {
+		t.Fatalf("Applied %d updates (%d changes), dry run proposed %d",
+			applied.AppliedUpdates, len(applied.Changes), len(proposed.Changes))
+	}
+	for i, change := range proposed.Changes {
+		if applied.Changes[i] != change {
+			t.Errorf("Applied change %+v differs from proposed %+v", applied.Changes[i], change)
+		}
+
+		var name, legalForm string
+		err := serviceDB.QueryRow(`SELECT normalized_name, COALESCE(legal_form, '') FROM normalized_counterparties WHERE id = ?`,
+			change.ID).Scan(&name, &legalForm)
+		if err != nil {
+			t.Fatalf("Failed to read counterparty %d: %v", change.ID, err)
+		}
+		if name != change.NewName || legalForm != change.NewLegalForm {
+			t.Errorf("Counterparty %d: got name=%q legal_form=%q, dry run proposed %+v", change.ID, name, legalForm, change)
+		}
+	}
+
+	// Повторный запуск ничего не меняет
+	again, err := mapper.NormalizeNamesForProject(projectID, true)
+	if err != nil {
+		t.Fatalf("NormalizeNamesForProject (second dry run) failed: %v", err)
+	}
+	if len(again.Changes) != 0 {
+		t.Errorf("Expected no changes after apply, got %+v", again.Changes)
+	}
+}
+
+func TestCounterpartyMapper_NameChangesTruncated(t *testing.T) {
+	mapper, serviceDB := setupTestMapper(t)
+	projectID := seedCounterpartiesForNameChanges(t, serviceDB)
+
+	mapper.SetMaxNameChanges(1)
+	summary, err := mapper.NormalizeNamesForProject(projectID, true)
+	if err != nil {
+		t.Fatalf("NormalizeNamesForProject failed: %v", err)
+	}
+	if len(summary.Changes) != 1 || !summary.ChangesTruncated || summary.UpdatedRecords != 2 {
+		t.Errorf("Expected 1 of 2 changes with truncation flag, got %d changes (truncated=%t, updated=%d)",
+			len(summary.Changes), summary.ChangesTruncated, summary.UpdatedRecords)
+	}
+
+	mapper.SetMaxNameChanges(0)
+	summary, err = mapper.NormalizeNamesForProject(projectID, true)
+	if err != nil {
+		t.Fatalf("NormalizeNamesForProject failed: %v", err)
+	}
+	if len(summary.Changes) != 2 || summary.ChangesTruncated {
+		t.Errorf("Expected all 2 changes without limit, got %d (truncated=%t)", len(summary.Changes), summary.ChangesTruncated)
+	}
+}
+
+func TestCounterpartyMapper_NormalizeNamesAppliesInBatches(t *testing.T) {
+	mapper, serviceDB := setupTestMapper(t)
+	projectID := seedCounterpartiesForNameChanges(t, serviceDB)
+
+	// Каждая пачка из одного контрагента, в сводке сохраняется только первое изменение
+	mapper.nameBatchSize = 1
+	mapper.SetMaxNameChanges(1)
+	summary, err := mapper.NormalizeNamesForProject(projectID, false)
+	if err != nil {
+		t.Fatalf("NormalizeNamesForProject failed: %v", err)
+	}
+	if summary.TotalRecords != 3 || summary.UpdatedRecords != 2 || summary.AppliedUpdates != 2 {
+		t.Errorf("Expected 3 records with 2 applied updates, got %+v", summary)
+	}
+	if len(summary.Changes) != 1 || !summary.ChangesTruncated {
+		t.Errorf("Expected 1 change with truncation flag, got %d (truncated=%t)", len(summary.Changes), summary.ChangesTruncated)
+	}
+
+	// Изменения, не попавшие в сводку, все равно записаны
+	again, err := mapper.NormalizeNamesForProject(projectID, true)
+	if err != nil {
+		t.Fatalf("NormalizeNamesForProject (dry run) failed: %v", err)
+	}
+	if again.UpdatedRecords != 0 || len(again.Changes) != 0 || again.ChangesTruncated {
+		t.Errorf("Expected no changes after batched apply, got %+v", again)
+	}
+}
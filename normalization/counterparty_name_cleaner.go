@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 )
@@ -19,6 +20,23 @@ type CounterpartyNameNormalizationSummary struct {
 	AppliedUpdates        int           `json:"applied_updates"`
 	DryRun                bool          `json:"dry_run"`
 	Duration              time.Duration `json:"duration"`
+	// Changes изменения названий в порядке id: в режиме dry run - предлагаемые, иначе - записанные в базу.
+	// Список ограничен SetMaxNameChanges; при превышении лимита выставляется ChangesTruncated,
+	// а счетчики сводки учитывают все изменения.
+	Changes          []CounterpartyNameChange `json:"changes,omitempty"`
+	ChangesTruncated bool                     `json:"changes_truncated,omitempty"`
+}
+
+// DefaultMaxCounterpartyNameChanges сколько изменений NormalizeNamesForProject сохраняет в сводке по умолчанию
+const DefaultMaxCounterpartyNameChanges = 10000
+
+// counterpartyNameBatchSize сколько контрагентов NormalizeNamesForProject читает и обновляет за один проход
+const counterpartyNameBatchSize = 500
+
+// SetMaxNameChanges задает, сколько изменений NormalizeNamesForProject сохраняет в Changes.
+// 0 и отрицательные значения снимают ограничение.
+func (cm *CounterpartyMapper) SetMaxNameChanges(limit int) {
+	cm.maxNameChanges = limit
 }
 
 // NormalizeNamesForProject удаляет ОПФ из названий и нормализует legal_form.
@@ -35,38 +53,83 @@ func (cm *CounterpartyMapper) NormalizeNamesForProject(projectID int, dryRun boo
 		"project_id", projectID,
 		"dry_run", dryRun)
 
-	rows, err := cm.serviceDB.Query(`
-		SELECT id, COALESCE(source_name, ''), COALESCE(normalized_name, ''), COALESCE(legal_form, '')
-		FROM normalized_counterparties
-		WHERE client_project_id = ?
-	`, projectID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch normalized counterparties: %w", err)
-	}
-	defer rows.Close()
-
 	summary := &CounterpartyNameNormalizationSummary{
 		ProjectID: projectID,
 		DryRun:    dryRun,
 	}
 	start := time.Now()
 
-	var executor execRunner = cm.serviceDB.GetDB()
-	var tx *sql.Tx
-	if !dryRun {
-		tx, err = cm.serviceDB.GetDB().Begin()
+	// Контрагенты читаются пачками по id, изменения пачки записываются после закрытия выборки:
+	// in-memory база работает с одним соединением, и транзакция не может начаться, пока открыт курсор SELECT
+	for lastID := 0; ; {
+		changes, batchLastID, err := cm.collectCounterpartyNameChanges(projectID, lastID, summary)
 		if err != nil {
-			return nil, fmt.Errorf("failed to begin transaction: %w", err)
+			return nil, err
+		}
+		if batchLastID == 0 {
+			break
 		}
-		executor = tx
+		lastID = batchLastID
+
+		if !dryRun && len(changes) > 0 {
+			if err := cm.applyNormalizedNames(changes); err != nil {
+				return nil, err
+			}
+			summary.AppliedUpdates += len(changes)
+		}
+		cm.appendNameChanges(summary, changes)
 	}
 
-	updateStmt := `
-		UPDATE normalized_counterparties
-		SET normalized_name = ?, legal_form = ?, updated_at = CURRENT_TIMESTAMP
-		WHERE id = ?
-	`
+	summary.Duration = time.Since(start)
+
+	cm.logger.Info("Finished counterparty name normalization",
+		"project_id", projectID,
+		"total", summary.TotalRecords,
+		"updated_records", summary.UpdatedRecords,
+		"updated_names", summary.UpdatedNameCount,
+		"updated_legal_forms", summary.UpdatedLegalFormCount,
+		"skipped", summary.SkippedWithoutName,
+		"dry_run", dryRun,
+		"duration", summary.Duration)
+
+	return summary, nil
+}
+
+// appendNameChanges добавляет изменения в сводку, пока их не больше maxNameChanges,
+// и выставляет ChangesTruncated, если часть изменений не поместилась
+func (cm *CounterpartyMapper) appendNameChanges(summary *CounterpartyNameNormalizationSummary, changes []CounterpartyNameChange) {
+	if cm.maxNameChanges > 0 {
+		if room := cm.maxNameChanges - len(summary.Changes); len(changes) > room {
+			changes = changes[:max(room, 0)]
+			summary.ChangesTruncated = true
+		}
+	}
+	summary.Changes = append(summary.Changes, changes...)
+}
+
+// collectCounterpartyNameChanges вычисляет изменения названий и ОПФ очередной пачки контрагентов проекта
+// с id больше afterID и заполняет счетчики summary. Возвращает id последнего прочитанного контрагента
+// (0, если контрагентов больше нет).
+func (cm *CounterpartyMapper) collectCounterpartyNameChanges(projectID, afterID int, summary *CounterpartyNameNormalizationSummary) ([]CounterpartyNameChange, int, error) {
+	batchSize := cm.nameBatchSize
+	if batchSize <= 0 {
+		batchSize = counterpartyNameBatchSize
+	}
+
+	rows, err := cm.serviceDB.Query(`
+		SELECT id, COALESCE(source_name, ''), COALESCE(normalized_name, ''), COALESCE(legal_form, '')
+		FROM normalized_counterparties
+		WHERE client_project_id = ? AND id > ?
+		ORDER BY id
+		LIMIT ?
+	`, projectID, afterID, batchSize)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch normalized counterparties: %w", err)
+	}
+	defer rows.Close()
 
+	var changes []CounterpartyNameChange
+	lastID := 0
 	for rows.Next() {
 		var (
 			id             int
@@ -76,12 +139,10 @@ func (cm *CounterpartyMapper) NormalizeNamesForProject(projectID int, dryRun boo
 		)
 
 		if err := rows.Scan(&id, &sourceName, &normalizedName, &legalForm); err != nil {
-			if tx != nil {
-				_ = tx.Rollback()
-			}
-			return nil, fmt.Errorf("failed to scan counterparty: %w", err)
+			return nil, 0, fmt.Errorf("failed to scan counterparty: %w", err)
 		}
 
+		lastID = id
 		summary.TotalRecords++
 
 		rawName := normalizedName
@@ -89,7 +150,7 @@ func (cm *CounterpartyMapper) NormalizeNamesForProject(projectID int, dryRun boo
 			rawName = sourceName
 		}
 
-		cleanName, canonicalForm := normalizeCounterpartyNameAndForm(rawName, legalForm)
+		cleanName, canonicalForm := cm.legalForms.normalizeNameAndForm(rawName, legalForm)
 		if cleanName == "" {
 			summary.SkippedWithoutName++
 			continue
@@ -107,60 +168,139 @@ func (cm *CounterpartyMapper) NormalizeNamesForProject(projectID int, dryRun boo
 
 		if updatedName || updatedForm {
 			summary.UpdatedRecords++
-			if !dryRun {
-				var legalFormValue interface{}
-				if canonicalForm != "" {
-					legalFormValue = canonicalForm
-				}
-				if _, err := executor.Exec(updateStmt, cleanName, legalFormValue, id); err != nil {
-					_ = tx.Rollback()
-					return nil, fmt.Errorf("failed to update counterparty %d: %w", id, err)
-				}
-			}
+			changes = append(changes, CounterpartyNameChange{
+				ID:           id,
+				OldName:      normalizedName,
+				NewName:      cleanName,
+				OldLegalForm: legalForm,
+				NewLegalForm: canonicalForm,
+				Reason:       counterpartyNameChangeReason(updatedName, updatedForm),
+			})
 		}
 	}
 
 	if err := rows.Err(); err != nil {
-		if tx != nil {
-			_ = tx.Rollback()
+		return nil, 0, fmt.Errorf("failed to iterate counterparties: %w", err)
+	}
+
+	return changes, lastID, nil
+}
+
+// applyNormalizedNames записывает новые названия и ОПФ пачки в одной транзакции
+func (cm *CounterpartyMapper) applyNormalizedNames(changes []CounterpartyNameChange) error {
+	tx, err := cm.serviceDB.GetDB().Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	updateStmt := `
+		UPDATE normalized_counterparties
+		SET normalized_name = ?, legal_form = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`
+	for _, change := range changes {
+		legalFormValue := sql.NullString{String: change.NewLegalForm, Valid: change.NewLegalForm != ""}
+		if _, err := tx.Exec(updateStmt, change.NewName, legalFormValue, change.ID); err != nil {
+			return fmt.Errorf("failed to update counterparty %d: %w", change.ID, err)
 		}
-		return nil, fmt.Errorf("failed to iterate counterparties: %w", err)
 	}
 
-	if tx != nil {
-		if err := tx.Commit(); err != nil {
-			return nil, fmt.Errorf("failed to commit normalization changes: %w", err)
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit normalization changes: %w", err)
+	}
+	return nil
+}
+
+// legalFormDictionary словарь ОПФ: сокращенные и полные написания и их канонические формы
+type legalFormDictionary struct {
+	// aliases написание без пробелов и знаков в верхнем регистре -> каноническая ОПФ
+	aliases map[string]string
+	// prefixPatterns, suffixPatterns выделяют ОПФ в начале и в конце названия,
+	// более длинные написания проверяются первыми ("АОЗТ" раньше "АО")
+	prefixPatterns []legalFormPattern
+	suffixPatterns []legalFormPattern
+}
+
+// defaultLegalForms словарь ОПФ по умолчанию (NewCounterpartyMapper)
+var defaultLegalForms = newLegalFormDictionary(nil)
+
+// newLegalFormDictionary строит словарь из legalFormAlias и legalFormSynonyms, дополненный overrides
+// (написание -> каноническая ОПФ). Написание из overrides заменяет встроенное сопоставление,
+// если совпадает с ним без учета регистра.
+func newLegalFormDictionary(overrides map[string]string) *legalFormDictionary {
+	aliases := make(map[string]string, len(legalFormAlias)+len(overrides))
+	for key, canonical := range legalFormAlias {
+		aliases[key] = canonical
+	}
+
+	type spellingPattern struct {
+		canonical string
+		source    string
+	}
+	var spellings []spellingPattern
+	for canonical, sources := range legalFormSynonyms {
+		for _, source := range sources {
+			spellings = append(spellings, spellingPattern{canonical: canonical, source: source})
 		}
-		summary.AppliedUpdates = summary.UpdatedRecords
 	}
 
-	summary.Duration = time.Since(start)
+	for spelling, canonical := range overrides {
+		key := sanitizeLegalFormToken(spelling)
+		canonical = strings.TrimSpace(canonical)
+		if key == "" || canonical == "" {
+			continue
+		}
+		aliases[key] = canonical
+		if canonicalKey := sanitizeLegalFormToken(canonical); aliases[canonicalKey] == "" {
+			aliases[canonicalKey] = canonical
+		}
 
-	cm.logger.Info("Finished counterparty name normalization",
-		"project_id", projectID,
-		"total", summary.TotalRecords,
-		"updated_records", summary.UpdatedRecords,
-		"updated_names", summary.UpdatedNameCount,
-		"updated_legal_forms", summary.UpdatedLegalFormCount,
-		"skipped", summary.SkippedWithoutName,
-		"dry_run", dryRun,
-		"duration", summary.Duration)
+		// Встроенные написания, совпадающие с переопределенным, удаляются
+		kept := spellings[:0]
+		for _, existing := range spellings {
+			if !regexp.MustCompile(`^(?i)(?:` + existing.source + `)$`).MatchString(strings.TrimSpace(spelling)) {
+				kept = append(kept, existing)
+			}
+		}
+		spellings = append(kept, spellingPattern{canonical: canonical, source: legalFormSpellingPattern(spelling)})
+	}
 
-	return summary, nil
+	sort.Slice(spellings, func(i, j int) bool {
+		if len(spellings[i].source) != len(spellings[j].source) {
+			return len(spellings[i].source) > len(spellings[j].source)
+		}
+		return spellings[i].source < spellings[j].source
+	})
+
+	dict := &legalFormDictionary{aliases: aliases}
+	for _, spelling := range spellings {
+		prefix := regexp.MustCompile(fmt.Sprintf(`^(?i)\s*(?:%s)\s*[«"“”']?(.*)$`, spelling.source))
+		suffix := regexp.MustCompile(fmt.Sprintf(`^(?i)\s*[«"“”']?(.+?)[«"“”']?\s*(?:%s)\.?$`, spelling.source))
+		dict.prefixPatterns = append(dict.prefixPatterns, legalFormPattern{canonical: spelling.canonical, regex: prefix})
+		dict.suffixPatterns = append(dict.suffixPatterns, legalFormPattern{canonical: spelling.canonical, regex: suffix})
+	}
+	return dict
 }
 
-type execRunner interface {
-	Exec(query string, args ...any) (sql.Result, error)
+// legalFormSpellingPattern преобразует написание ОПФ в регулярное выражение:
+// "Жауапкершілігі шектеулі серіктестік" -> `Жауапкершілігі\s+шектеулі\s+серіктестік`
+func legalFormSpellingPattern(spelling string) string {
+	words := strings.Fields(spelling)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return strings.Join(words, `\s+`)
 }
 
-// normalizeCounterpartyNameAndForm приводит название к чистому виду и определяет ОПФ.
-func normalizeCounterpartyNameAndForm(name, existingForm string) (string, string) {
+// normalizeNameAndForm приводит название к чистому виду и определяет ОПФ.
+func (d *legalFormDictionary) normalizeNameAndForm(name, existingForm string) (string, string) {
 	cleanName := cleanupCounterpartyName(name)
-	inferredForm, strippedName := extractLegalFormFromName(cleanName)
+	inferredForm, strippedName := d.extractFromName(cleanName)
 
-	canonicalForm := normalizeLegalFormValue(existingForm)
+	canonicalForm := d.normalizeValue(existingForm)
 	if canonicalForm == "" {
-		canonicalForm = normalizeLegalFormValue(inferredForm)
+		canonicalForm = d.normalizeValue(inferredForm)
 	}
 
 	if strippedName == "" {
@@ -180,14 +320,14 @@ func cleanupCounterpartyName(name string) string {
 	return strings.TrimSpace(trimmed)
 }
 
-func extractLegalFormFromName(name string) (string, string) {
-	for _, pattern := range legalFormPrefixPatterns {
+func (d *legalFormDictionary) extractFromName(name string) (string, string) {
+	for _, pattern := range d.prefixPatterns {
 		if matches := pattern.regex.FindStringSubmatch(name); len(matches) == 2 {
 			return pattern.canonical, cleanupCounterpartyName(matches[1])
 		}
 	}
 
-	for _, pattern := range legalFormSuffixPatterns {
+	for _, pattern := range d.suffixPatterns {
 		if matches := pattern.regex.FindStringSubmatch(name); len(matches) == 2 {
 			return pattern.canonical, cleanupCounterpartyName(matches[1])
 		}
@@ -196,13 +336,13 @@ func extractLegalFormFromName(name string) (string, string) {
 	return "", cleanupCounterpartyName(name)
 }
 
-func normalizeLegalFormValue(value string) string {
+func (d *legalFormDictionary) normalizeValue(value string) string {
 	if strings.TrimSpace(value) == "" {
 		return ""
 	}
 
 	key := sanitizeLegalFormToken(value)
-	if canonical, ok := legalFormAlias[key]; ok {
+	if canonical, ok := d.aliases[key]; ok {
 		return canonical
 	}
 	return strings.TrimSpace(value)
@@ -226,10 +366,8 @@ func sanitizeLegalFormToken(value string) string {
 }
 
 var (
-	whitespaceRegex         = regexp.MustCompile(`\s+`)
-	legalFormPrefixPatterns []legalFormPattern
-	legalFormSuffixPatterns []legalFormPattern
-	legalFormAlias          = map[string]string{
+	whitespaceRegex = regexp.MustCompile(`\s+`)
+	legalFormAlias  = map[string]string{
 		"ООО": "ООО",
 		"ОБЩЕСТВОСОГРАНИЧЕННОЙОТВЕТСТВЕННОСТЬЮ": "ООО",
 		"ЗАО": "ЗАО",
@@ -317,19 +455,3 @@ type legalFormPattern struct {
 	canonical string
 	regex     *regexp.Regexp
 }
-
-func init() {
-	for canonical, patterns := range legalFormSynonyms {
-		alternation := strings.Join(patterns, "|")
-		prefix := regexp.MustCompile(fmt.Sprintf(`^(?i)\s*(?:%s)\s*[«"“”']?(.*)$`, alternation))
-		suffix := regexp.MustCompile(fmt.Sprintf(`^(?i)\s*[«"“”']?(.+?)[«"“”']?\s*(?:%s)\.?$`, alternation))
-		legalFormPrefixPatterns = append(legalFormPrefixPatterns, legalFormPattern{
-			canonical: canonical,
-			regex:     prefix,
-		})
-		legalFormSuffixPatterns = append(legalFormSuffixPatterns, legalFormPattern{
-			canonical: canonical,
-			regex:     suffix,
-		})
-	}
-}
@@ -0,0 +1,118 @@
+package normalization
+
+import (
+	"testing"
+)
+
+var kazakhLegalForms = map[string]string{
+	"АҚ":  "АО",
+	"ЖШС": "ТОО",
+	"Жауапкершілігі шектеулі серіктестік": "ТОО",
+}
+
+func TestLegalFormDictionary_Default(t *testing.T) {
+	tests := []struct {
+		name, legalForm   string
+		wantName, wantOPF string
+	}{
+		{`ООО "Ромашка"`, "", "Ромашка", "ООО"},
+		{"ТОО «Арман»", "", "Арман", "ТОО"},
+		{"АОЗТ Восток", "", "Восток", "АОЗТ"},
+		{"Лютик", "о.о.о.", "Лютик", "ООО"},
+		// Казахские ОПФ во встроенном словаре отсутствуют
+		{"Арман ЖШС", "", "Арман ЖШС", ""},
+	}
+
+	for _, tt := range tests {
+		gotName, gotOPF := defaultLegalForms.normalizeNameAndForm(tt.name, tt.legalForm)
+		if gotName != tt.wantName || gotOPF != tt.wantOPF {
+			t.Errorf("normalizeNameAndForm(%q, %q) = (%q, %q), want (%q, %q)",
+				tt.name, tt.legalForm, gotName, gotOPF, tt.wantName, tt.wantOPF)
+		}
+	}
+}
+
+func TestLegalFormDictionary_KazakhForms(t *testing.T) {
+	dict := newLegalFormDictionary(kazakhLegalForms)
+
+	tests := []struct {
+		name, legalForm   string
+		wantName, wantOPF string
+	}{
+		{"АҚ «Қазақтелеком»", "", "Қазақтелеком", "АО"},
+		{"Арман ЖШС", "", "Арман", "ТОО"},
+		{"жшс Арман", "", "Арман", "ТОО"},
+		{"Жауапкершілігі шектеулі серіктестік «Арман»", "", "Арман", "ТОО"},
+		{"Арман", "Ж.Ш.С.", "Арман", "ТОО"},
+		// Встроенные формы продолжают работать
+		{"ТОО «Арман»", "", "Арман", "ТОО"},
+		{`ООО "Ромашка"`, "", "Ромашка", "ООО"},
+	}
+
+	for _, tt := range tests {
+		gotName, gotOPF := dict.normalizeNameAndForm(tt.name, tt.legalForm)
+		if gotName != tt.wantName || gotOPF != tt.wantOPF {
+			t.Errorf("normalizeNameAndForm(%q, %q) = (%q, %q), want (%q, %q)",
+				tt.name, tt.legalForm, gotName, gotOPF, tt.wantName, tt.wantOPF)
+		}
+	}
+}
+
+func TestLegalFormDictionary_OverridesDefault(t *testing.T) {
+	dict := newLegalFormDictionary(map[string]string{"тоо": "ЖШС"})
+
+	gotName, gotOPF := dict.normalizeNameAndForm("ТОО «Арман»", "")
+	if gotName != "Арман" || gotOPF != "ЖШС" {
+		t.Errorf("Expected (Арман, ЖШС), got (%q, %q)", gotName, gotOPF)
+	}
+	if got := dict.normalizeValue("Товарищество с ограниченной ответственностью"); got != "ТОО" {
+		t.Errorf("Full form should keep its default mapping, got %q", got)
+	}
+	if got := defaultLegalForms.normalizeValue("ТОО"); got != "ТОО" {
+		t.Errorf("Override leaked into default dictionary: %q", got)
+	}
+}
+
+func TestCounterpartyMapper_NormalizeNamesWithKazakhLegalForms(t *testing.T) {
+	serviceDB := setupTestServiceDBForDuplicate(t)
+	mapper := NewCounterpartyMapperWithOptions(serviceDB, MapperOptions{LegalFormMap: kazakhLegalForms})
+
+	client := createTestClientForMapper(t, serviceDB)
+	project, err := serviceDB.CreateClientProject(client.ID, "AITAS", "counterparty", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	for i, name := range []string{"АҚ «Қазақтелеком»", "Арман ЖШС"} {
+		err := serviceDB.SaveNormalizedCounterparty(project.ID, "ref_"+name, name, name,
+			"", "", "12345678901"+string(rune('0'+i)), "", "", "", "", "", "", "", "", "", "",
+			0, 0.5, false, "", "", "")
+		if err != nil {
+			t.Fatalf("Failed to save counterparty %q: %v", name, err)
+		}
+	}
+
+	summary, err := mapper.NormalizeNamesForProject(project.ID, true)
+	if err != nil {
+		t.Fatalf("NormalizeNamesForProject failed: %v", err)
+	}
+	if len(summary.Changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %+v", summary.Changes)
+	}
+	if c := summary.Changes[0]; c.NewName != "Қазақтелеком" || c.NewLegalForm != "АО" {
+		t.Errorf("Unexpected change for АҚ: %+v", c)
+	}
+	if c := summary.Changes[1]; c.NewName != "Арман" || c.NewLegalForm != "ТОО" {
+		t.Errorf("Unexpected change for ЖШС: %+v", c)
+	}
+
+	// Без словаря казахские ОПФ не распознаются
+	summary, err = NewCounterpartyMapper(serviceDB).NormalizeNamesForProject(project.ID, true)
+	if err != nil {
+		t.Fatalf("NormalizeNamesForProject failed: %v", err)
+	}
+	for _, c := range summary.Changes {
+		if c.NewLegalForm == "АО" || c.NewLegalForm == "ТОО" {
+			t.Errorf("Default mapper recognized Kazakh legal form: %+v", c)
+		}
+	}
+}
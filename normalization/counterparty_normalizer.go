@@ -27,6 +27,7 @@ type CounterpartyNormalizer struct {
 	benchmarkFinder BenchmarkFinder  // Интерфейс для поиска эталонов
 	logger          *slog.Logger     // Структурированный логгер
 	beforeProcessHook func(*database.CatalogItem) // Используется в тестах для синхронизации остановки
+	phaseTimer      PhaseTimer       // Время по фазам текущего запуска ProcessNormalization
 }
 
 // CounterpartyNormalizationResult результат нормализации контрагентов
@@ -39,6 +40,11 @@ type CounterpartyNormalizationResult struct {
 	TotalProcessed    int      `json:"total_processed"`
 	CreatedBenchmarks int      `json:"created_benchmarks"`
 	Errors            []string `json:"errors"`
+	// TotalDuration время нормализации по часам
+	TotalDuration time.Duration `json:"total_duration"`
+	// PhaseTimings суммарное время воркеров по фазам (см. константы Phase*).
+	// Обработка идет в несколько потоков, поэтому сумма фаз может превышать TotalDuration.
+	PhaseTimings map[string]time.Duration `json:"phase_timings"`
 }
 
 // ErrMsgNormalizationStopped сообщение об остановке нормализации
@@ -119,6 +125,11 @@ func (cn *CounterpartyNormalizer) ProcessNormalization(counterparties []*databas
 	}
 
 	startTime := time.Now()
+	cn.phaseTimer.Reset()
+	defer func() {
+		result.TotalDuration = time.Since(startTime)
+		result.PhaseTimings = cn.phaseTimer.Snapshot()
+	}()
 	cn.logger.Info("Starting counterparty normalization",
 		"total", len(counterparties),
 		"skip_normalized", skipNormalized)
@@ -147,6 +158,7 @@ func (cn *CounterpartyNormalizer) ProcessNormalization(counterparties []*databas
 				"duration_ms", time.Since(checkStart).Milliseconds())
 			}
 		}
+		cn.phaseTimer.since(phaseDBLookup, checkStart)
 	}
 
 	// Обрабатываем контрагентов
@@ -286,6 +298,7 @@ func (cn *CounterpartyNormalizer) ProcessNormalization(counterparties []*databas
 
 	// После нормализации всех контрагентов выполняем автоматический мэппинг и объединение дубликатов
 	cn.logger.Info("Starting automatic counterparty mapping after normalization")
+	mappingStart := time.Now()
 	mapper := NewCounterpartyMapper(cn.serviceDB)
 	if err := mapper.MapAllCounterpartiesForProject(cn.projectID); err != nil {
 		cn.logger.Warn("Failed to auto-map counterparties after normalization", "error", err)
@@ -293,6 +306,7 @@ func (cn *CounterpartyNormalizer) ProcessNormalization(counterparties []*databas
 	} else {
 		cn.logger.Info("Successfully completed automatic counterparty mapping")
 	}
+	cn.phaseTimer.since(phaseMapping, mappingStart)
 
 	totalDuration := time.Since(startTime)
 	cn.logger.Info("Counterparty normalization completed",
@@ -314,7 +328,8 @@ func (cn *CounterpartyNormalizer) ProcessNormalization(counterparties []*databas
 				return float64(result.TotalProcessed) / totalDuration.Seconds()
 			}
 			return 0
-		}())
+		}(),
+		"phase_timings", cn.phaseTimer.Snapshot())
 
 	return result, nil
 }
@@ -329,6 +344,7 @@ func (cn *CounterpartyNormalizer) processCounterparty(
 	if cp == nil {
 		return fmt.Errorf("counterparty is nil")
 	}
+	rulesStart := time.Now()
 	if cp.Name == "" {
 		cn.logger.Debug("Skipping counterparty with empty name", "counterparty_id", cp.ID)
 		return nil // Пропускаем контрагентов без имени
@@ -375,11 +391,14 @@ func (cn *CounterpartyNormalizer) processCounterparty(
 		}
 	}
 
+	cn.phaseTimer.since(phaseRules, rulesStart)
+
 	// Сначала проверяем эталоны перед AI-нормализацией
 	normalizedName := cp.Name
 	benchmarkFound := false
 
 	if cn.benchmarkFinder != nil {
+		benchmarkStart := time.Now()
 		normalized, found, err := cn.benchmarkFinder.FindBestMatch(cp.Name, "counterparty")
 		if err == nil && found {
 			normalizedName = normalized
@@ -393,10 +412,12 @@ func (cn *CounterpartyNormalizer) processCounterparty(
 				"counterparty_id", cp.ID,
 				"error", err.Error())
 		}
+		cn.phaseTimer.since(phaseBenchmark, benchmarkStart)
 	}
 
 	// Если эталон не найден, используем AI-нормализацию
 	if !benchmarkFound && cn.nameNormalizer != nil {
+		aiStart := time.Now()
 		normalizedCtx, cancel := context.WithTimeout(cn.ctx, 30*time.Second)
 		defer cancel()
 
@@ -416,9 +437,11 @@ func (cn *CounterpartyNormalizer) processCounterparty(
 			// Используем исходное имя при ошибке
 			normalizedName = cp.Name
 		}
+		cn.phaseTimer.since(phaseAI, aiStart)
 	}
 
 	// Извлекаем дополнительные данные из атрибутов
+	enrichmentStart := time.Now()
 	legalAddress := ""
 	postalAddress := ""
 	contactPhone := ""
@@ -460,6 +483,7 @@ func (cn *CounterpartyNormalizer) processCounterparty(
 			bik = bikCode
 		}
 	}
+	cn.phaseTimer.since(phaseEnrichment, enrichmentStart)
 
 	// Проверяем на эталоны (benchmarks) по ИНН/БИН
 	benchmarkID := 0
@@ -475,6 +499,7 @@ func (cn *CounterpartyNormalizer) processCounterparty(
 			benchmarkStart := time.Now()
 			benchmark, err := cn.serviceDB.FindBenchmarkByTaxID(cn.projectID, taxID)
 			benchmarkDuration := time.Since(benchmarkStart)
+			cn.phaseTimer.add(phaseDBLookup, benchmarkDuration)
 			
 			if err != nil {
 				cn.logger.Warn("Error searching for benchmark by tax ID",
@@ -573,6 +598,7 @@ func (cn *CounterpartyNormalizer) processCounterparty(
 	
 	err := Retry(saveFunc, retryConfig)
 	saveDuration := time.Since(saveStart)
+	cn.phaseTimer.add(phaseDBWrite, saveDuration)
 
 	if err != nil {
 		cn.logger.Warn("Failed to save normalized counterparty after retries",
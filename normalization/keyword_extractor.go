@@ -0,0 +1,91 @@
+package normalization
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// keywordFillerWords слова-заполнители наименований номенклатуры; общие служебные слова
+// берутся из набора Stopwords()
+var keywordFillerWords = map[string]bool{
+	"тип": true, "вид": true, "марка": true, "арт": true, "артикул": true,
+}
+
+// keywordUnits единицы измерения, которые отбрасываются как отдельные слова
+var keywordUnits = map[string]bool{
+	"мм": true, "см": true, "м": true, "км": true, "мкм": true,
+	"г": true, "гр": true, "кг": true, "т": true, "мг": true,
+	"л": true, "мл": true, "шт": true, "уп": true, "компл": true, "пар": true,
+	"в": true, "а": true, "вт": true, "квт": true, "ква": true, "ч": true, "мин": true, "сек": true,
+	"гц": true, "об": true, "бар": true, "атм": true, "мпа": true, "кпа": true,
+	"mm": true, "cm": true, "m": true, "kg": true, "g": true, "l": true, "ml": true,
+	"w": true, "kw": true, "v": true, "a": true, "pcs": true,
+}
+
+// keywordMeasureRegex числа, дроби и размеры с необязательной единицей измерения:
+// "100", "2,5", "120мм", "2.5кг", "100x100", "20х30х40мм"
+var keywordMeasureRegex = regexp.MustCompile(`^\d+(?:[.,]\d+)?(?:[xх*]\d+(?:[.,]\d+)?)*(\p{L}*)$`)
+
+// ExtractKeywords извлекает нормализованные ключевые слова из наименования номенклатуры:
+// текст приводится к нижнему регистру ("ё" заменяется на "е"), разбивается на слова,
+// из которых удаляются знаки препинания, числа, размеры, единицы измерения и стоп-слова (Stopwords).
+// Слова возвращаются без повторов в порядке первого появления.
+// Например: "Болт М10х40 оцинкованный, 100 шт." -> ["болт", "м10х40", "оцинкованный"]
+func ExtractKeywords(name string) []string {
+	text := strings.ReplaceAll(strings.ToLower(name), "ё", "е")
+
+	// Точка, запятая и знак умножения внутри чисел сохраняются, чтобы распознать "2.5кг" и "10*20"
+	tokens := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != ',' && r != '*'
+	})
+
+	seen := make(map[string]bool)
+	keywords := make([]string, 0, len(tokens))
+	for _, token := range tokens {
+		token = strings.Trim(token, ".,*")
+		if token == "" || seen[token] {
+			continue
+		}
+
+		// Знаки внутри слов ("т.д", "a,b") не относятся к числам - разбиваем по ним
+		if !startsWithDigit(token) && strings.ContainsAny(token, ".,*") {
+			for _, part := range strings.FieldsFunc(token, func(r rune) bool { return r == '.' || r == ',' || r == '*' }) {
+				if isKeyword(part) && !seen[part] {
+					seen[part] = true
+					keywords = append(keywords, part)
+				}
+			}
+			continue
+		}
+
+		if isKeyword(token) {
+			seen[token] = true
+			keywords = append(keywords, token)
+		}
+	}
+
+	return keywords
+}
+
+// isKeyword проверяет, является ли токен значимым ключевым словом
+func isKeyword(token string) bool {
+	if len([]rune(token)) < 2 {
+		return false
+	}
+	if keywordFillerWords[token] || keywordUnits[token] || Stopwords().Contains(token) {
+		return false
+	}
+	if match := keywordMeasureRegex.FindStringSubmatch(token); match != nil && (match[1] == "" || keywordUnits[match[1]]) {
+		return false
+	}
+	return true
+}
+
+// startsWithDigit проверяет, начинается ли токен с цифры
+func startsWithDigit(token string) bool {
+	for _, r := range token {
+		return unicode.IsDigit(r)
+	}
+	return false
+}
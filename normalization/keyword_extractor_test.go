@@ -0,0 +1,59 @@
+package normalization
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractKeywords(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "крепеж с размером и количеством",
+			input:    "Болт М10х40 оцинкованный, 100 шт.",
+			expected: []string{"болт", "м10х40", "оцинкованный"},
+		},
+		{
+			name:     "слитные единицы измерения",
+			input:    "Кабель ВВГнг 3х2.5мм 100м",
+			expected: []string{"кабель", "ввгнг"},
+		},
+		{
+			name:     "габариты и стоп-слова",
+			input:    "Плита для перекрытий 1200x600x220 мм",
+			expected: []string{"плита", "перекрытий"},
+		},
+		{
+			name:     "ё и регистр",
+			input:    "ЁМКОСТЬ пластиковая 2,5 л",
+			expected: []string{"емкость", "пластиковая"},
+		},
+		{
+			name:     "электротехника с латинскими единицами",
+			input:    "Лампа светодиодная LED 10W 220V E27",
+			expected: []string{"лампа", "светодиодная", "led", "e27"},
+		},
+		{
+			name:     "повторы и пунктуация",
+			input:    "Труба (стальная) труба; ГОСТ 3262-75",
+			expected: []string{"труба", "стальная", "гост"},
+		},
+		{
+			name:     "пустая строка",
+			input:    "  ",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractKeywords(tt.input)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ExtractKeywords(%q) = %v, want %v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
@@ -66,6 +66,8 @@ type Normalizer struct {
 	benchmarkFinder BenchmarkFinder
 	// Движок валидации (для проверки элементов перед обработкой)
 	validationEngine *ValidationEngine
+	// Время по фазам последнего запуска ProcessNormalization
+	phaseTimer PhaseTimer
 }
 
 // groupKey ключ для группировки записей
@@ -214,16 +216,19 @@ func (n *Normalizer) sendEvent(message string) {
 // uploadID - ID выгрузки для привязки checkpoint (0 = не указан, используется значение по умолчанию)
 func (n *Normalizer) ProcessNormalization(uploadID int) error {
 	startTime := time.Now()
+	n.phaseTimer.Reset()
 	n.sendEvent("Начало нормализации данных...")
 	log.Printf("Начало нормализации данных...")
 
 	// 1. Очищаем старые записи
 	n.sendEvent("Очистка старых записей из catalog_items...")
 	log.Printf("Очистка старых записей из catalog_items...")
+	cleanStart := time.Now()
 	if err := n.db.CleanOldCatalogItems(); err != nil {
 		n.sendEvent(fmt.Sprintf("Ошибка очистки: %v", err))
 		return fmt.Errorf("failed to clean old catalog items: %w", err)
 	}
+	n.phaseTimer.since(phaseDBWrite, cleanStart)
 	n.sendEvent("Очистка завершена")
 	log.Printf("Очистка завершена")
 
@@ -231,7 +236,9 @@ func (n *Normalizer) ProcessNormalization(uploadID int) error {
 	n.sendEvent(fmt.Sprintf("Получение всех записей из %s...", n.sourceTable))
 	log.Printf("Получение всех записей из %s (ref=%s, code=%s, name=%s)...",
 		n.sourceTable, n.referenceColumn, n.codeColumn, n.nameColumn)
+	loadStart := time.Now()
 	items, err := n.db.GetCatalogItemsFromTable(n.sourceTable, n.referenceColumn, n.codeColumn, n.nameColumn)
+	n.phaseTimer.since(phaseDBLookup, loadStart)
 	if err != nil {
 		n.sendEvent(fmt.Sprintf("Ошибка получения записей: %v", err))
 		return fmt.Errorf("failed to get catalog items: %w", err)
@@ -283,8 +290,10 @@ func (n *Normalizer) ProcessNormalization(uploadID int) error {
 		}
 
 		// Валидация элемента (если настроен ValidationEngine)
+		rulesStart := time.Now()
 		if n.validationEngine != nil {
 			if !n.validationEngine.ValidateItem(item) {
+				n.phaseTimer.since(phaseRules, rulesStart)
 				// Элемент не прошел валидацию (критические ошибки)
 				// Пропускаем элемент и продолжаем со следующим
 				log.Printf("Элемент %d (%s) не прошел валидацию, пропускаем", item.ID, item.Name)
@@ -301,10 +310,12 @@ func (n *Normalizer) ProcessNormalization(uploadID int) error {
 		aiConfidence := 0.0
 		aiReasoning := ""
 		processingLevel := "basic"
+		n.phaseTimer.since(phaseRules, rulesStart)
 
 		// Сначала проверяем эталоны перед AI-обработкой
 		benchmarkFound := false
 		if n.benchmarkFinder != nil {
+			benchmarkStart := time.Now()
 			benchmarkName, found, err := n.benchmarkFinder.FindBestMatch(item.Name, "nomenclature")
 			if err == nil && found {
 				normalizedName = benchmarkName
@@ -313,11 +324,14 @@ func (n *Normalizer) ProcessNormalization(uploadID int) error {
 				aiReasoning = "Найдено в эталонах"
 				benchmarkFound = true
 			}
+			n.phaseTimer.since(phaseBenchmark, benchmarkStart)
 		}
 
 		// AI обработка если требуется (только если эталон не найден)
 		if !benchmarkFound && n.useAI && n.aiNormalizer != nil && n.aiNormalizer.RequiresAI(item.Name, category) {
+			aiStart := time.Now()
 			aiResult, err := n.processWithAI(item.Name)
+			n.phaseTimer.since(phaseAI, aiStart)
 			if err != nil {
 				n.sendEvent(fmt.Sprintf("⚠ AI ошибка для '%s': %v, используем правила", item.Name, err))
 				log.Printf("AI ошибка для '%s': %v, используем правила", item.Name, err)
@@ -352,7 +366,9 @@ func (n *Normalizer) ProcessNormalization(uploadID int) error {
 			// Для новой группы выполняем иерархическую КПВЭД классификацию
 			// Используем результат КПВЭД как категорию вместо простого Categorizer
 			if n.hierarchicalClassifier != nil {
+				classifyStart := time.Now()
 				kpvedResult, err := n.hierarchicalClassifier.Classify(normalizedName, category)
+				n.phaseTimer.since(phaseClassification, classifyStart)
 				if err != nil {
 					log.Printf("Warning: Hierarchical KPVED classification failed for '%s': %v, используем простую категорию", normalizedName, err)
 				} else {
@@ -475,6 +491,7 @@ func (n *Normalizer) ProcessNormalization(uploadID int) error {
 
 			// Вставляем пакетом для производительности
 			if len(batch) >= batchSize {
+				writeStart := time.Now()
 				// ФИЛЬТРАЦИЯ ДУБЛИКАТОВ: проверяем батч на дубликаты с данными в БД
 				// Дубликаты с confidence >= 0.95 будут удалены из батча
 				// Вместо вставки дубликата увеличивается merged_count существующей записи
@@ -534,6 +551,7 @@ func (n *Normalizer) ProcessNormalization(uploadID int) error {
 				if err := n.saveCheckpoint(checkpoint); err != nil {
 					log.Printf("⚠ Предупреждение: не удалось сохранить checkpoint: %v", err)
 				}
+				n.phaseTimer.since(phaseDBWrite, writeStart)
 
 				batch = batch[:0] // Очищаем пакет
 			}
@@ -542,6 +560,7 @@ func (n *Normalizer) ProcessNormalization(uploadID int) error {
 
 	// Вставляем оставшиеся записи
 	if len(batch) > 0 {
+		writeStart := time.Now()
 		// ФИЛЬТРАЦИЯ ДУБЛИКАТОВ для финального батча
 		filteredBatch, err := n.filterDuplicatesFromBatch(batch)
 		if err != nil {
@@ -594,12 +613,14 @@ func (n *Normalizer) ProcessNormalization(uploadID int) error {
 		if err := n.saveCheckpoint(checkpoint); err != nil {
 			log.Printf("⚠ Предупреждение: не удалось сохранить финальный checkpoint: %v", err)
 		}
+		n.phaseTimer.since(phaseDBWrite, writeStart)
 	}
 
 	elapsed := time.Since(startTime)
 	message = fmt.Sprintf("Нормализация завершена за %v. Всего обработано: %d записей", elapsed, totalInserted)
 	n.sendEvent(message)
 	log.Print(message)
+	log.Printf("Время по фазам: %v", n.phaseTimer.Snapshot())
 
 	// CHECKPOINT: Удаляем checkpoint после успешного завершения
 	if err := n.deleteCheckpoint(checkpoint.UploadID); err != nil {
@@ -980,6 +1001,11 @@ func (n *Normalizer) GetCheckpointStatus() map[string]interface{} {
 	}
 }
 
+// GetPhaseTimings возвращает накопленное время по фазам текущего или последнего запуска нормализации
+func (n *Normalizer) GetPhaseTimings() map[string]time.Duration {
+	return n.phaseTimer.Snapshot()
+}
+
 // SetSessionID устанавливает ID сессии нормализации
 func (n *Normalizer) SetSessionID(sessionID int) {
 	n.sessionID = &sessionID
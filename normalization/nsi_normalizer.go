@@ -2,6 +2,7 @@ package normalization
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 )
 
@@ -57,9 +58,37 @@ func (nsi *NSINormalizer) NormalizeName(name string, options NormalizationOption
 	return normalized
 }
 
-// FindDuplicates находит дубликаты используя все доступные алгоритмы
-func (nsi *NSINormalizer) FindDuplicates(items []DuplicateItem, config DuplicateDetectionConfig) []DuplicateGroup {
+// DuplicateDetectionSummary сводка поиска дубликатов
+type DuplicateDetectionSummary struct {
+	Algorithm      string  `json:"algorithm"` // Алгоритм схожести нечеткой группировки
+	Threshold      float64 `json:"threshold"` // Примененный порог схожести
+	TotalItems     int     `json:"total_items"`
+	GroupCount     int     `json:"group_count"`
+	DuplicateItems int     `json:"duplicate_items"`
+}
+
+// FindDuplicates находит дубликаты используя все доступные алгоритмы.
+// Если config.Similarity.Algorithm не поддерживается, нечеткая группировка не выполняется
+// и возвращается ошибка вместе с группами точного совпадения.
+func (nsi *NSINormalizer) FindDuplicates(items []DuplicateItem, config DuplicateDetectionConfig) ([]DuplicateGroup, error) {
+	groups, _, err := nsi.FindDuplicatesWithSummary(items, config)
+	return groups, err
+}
+
+// FindDuplicatesWithSummary находит дубликаты и возвращает сводку с выбранным алгоритмом схожести и порогом
+func (nsi *NSINormalizer) FindDuplicatesWithSummary(
+	items []DuplicateItem,
+	config DuplicateDetectionConfig,
+) ([]DuplicateGroup, DuplicateDetectionSummary, error) {
+	algorithmName, threshold := config.similaritySettings()
+	summary := DuplicateDetectionSummary{
+		Algorithm:  algorithmName,
+		Threshold:  threshold,
+		TotalItems: len(items),
+	}
+
 	var allGroups []DuplicateGroup
+	var algorithmErr error
 
 	// 1. Exact matching (если включен)
 	if config.UseExactMatching {
@@ -67,10 +96,15 @@ func (nsi *NSINormalizer) FindDuplicates(items []DuplicateItem, config Duplicate
 		allGroups = append(allGroups, exactGroups...)
 	}
 
-	// 2. Fuzzy matching с различными алгоритмами
+	// 2. Fuzzy matching выбранным алгоритмом
 	if config.UseFuzzyMatching {
-		fuzzyGroups := nsi.findFuzzyDuplicates(items, config)
-		allGroups = append(allGroups, fuzzyGroups...)
+		algorithm, err := NewSimilarityAlgorithm(algorithmName, config.similarityWeights())
+		if err != nil {
+			algorithmErr = err
+		} else {
+			fuzzyGroups := nsi.findFuzzyDuplicates(items, algorithm, algorithmName, threshold)
+			allGroups = append(allGroups, fuzzyGroups...)
+		}
 	}
 
 	// 3. Объединяем пересекающиеся группы
@@ -89,22 +123,25 @@ func (nsi *NSINormalizer) FindDuplicates(items []DuplicateItem, config Duplicate
 		allGroups = filteredGroups
 	}
 
-	return allGroups
+	summary.GroupCount = len(allGroups)
+	for _, group := range allGroups {
+		summary.DuplicateItems += len(group.ItemIDs)
+	}
+
+	return allGroups, summary, algorithmErr
 }
 
-// findFuzzyDuplicates находит дубликаты используя нечеткие алгоритмы
-func (nsi *NSINormalizer) findFuzzyDuplicates(items []DuplicateItem, config DuplicateDetectionConfig) []DuplicateGroup {
+// findFuzzyDuplicates находит дубликаты используя нечеткий алгоритм схожести
+func (nsi *NSINormalizer) findFuzzyDuplicates(
+	items []DuplicateItem,
+	algorithm SimilarityAlgorithm,
+	algorithmName string,
+	threshold float64,
+) []DuplicateGroup {
 	var groups []DuplicateGroup
 	processed := make(map[int]bool)
 	groupCounter := 0
 
-	weights := config.SimilarityWeights
-	if weights.Levenshtein == 0 && weights.DamerauLevenshtein == 0 &&
-		weights.Bigram == 0 && weights.Trigram == 0 &&
-		weights.Jaccard == 0 && weights.Soundex == 0 && weights.Metaphone == 0 {
-		weights = DefaultSimilarityWeights()
-	}
-
 	for i := 0; i < len(items); i++ {
 		if processed[items[i].ID] {
 			continue
@@ -120,14 +157,8 @@ func (nsi *NSINormalizer) findFuzzyDuplicates(items []DuplicateItem, config Dupl
 				continue
 			}
 
-			// Вычисляем комбинированную схожесть
-			similarity := nsi.fuzzyAlgorithms.CombinedSimilarity(
-				items[i].NormalizedName,
-				items[j].NormalizedName,
-				weights,
-			)
-
-			if similarity >= config.Threshold {
+			similarity := algorithm.Similarity(items[i].NormalizedName, items[j].NormalizedName)
+			if similarity >= threshold {
 				duplicates = append(duplicates, items[j])
 				itemIDs = append(itemIDs, items[j].ID)
 				processed[items[j].ID] = true
@@ -142,11 +173,7 @@ func (nsi *NSINormalizer) findFuzzyDuplicates(items []DuplicateItem, config Dupl
 			pairCount := 0
 			for k := 0; k < len(duplicates); k++ {
 				for l := k + 1; l < len(duplicates); l++ {
-					avgSimilarity += nsi.fuzzyAlgorithms.CombinedSimilarity(
-						duplicates[k].NormalizedName,
-						duplicates[l].NormalizedName,
-						weights,
-					)
+					avgSimilarity += algorithm.Similarity(duplicates[k].NormalizedName, duplicates[l].NormalizedName)
 					pairCount++
 				}
 			}
@@ -161,7 +188,7 @@ func (nsi *NSINormalizer) findFuzzyDuplicates(items []DuplicateItem, config Dupl
 				ItemIDs:         itemIDs,
 				Items:           duplicates,
 				Confidence:      avgSimilarity,
-				Reason:          "Fuzzy matching with combined algorithms",
+				Reason:          fmt.Sprintf("Fuzzy matching with %s similarity", algorithmName),
 			})
 			groupCounter++
 		}
@@ -304,10 +331,36 @@ type DuplicateDetectionConfig struct {
 	Threshold         float64           // Порог схожести (0.0 - 1.0)
 	MinConfidence     float64           // Минимальная уверенность
 	MergeOverlapping  bool              // Объединять пересекающиеся группы
-	SimilarityWeights SimilarityWeights // Веса для алгоритмов схожести
+	SimilarityWeights SimilarityWeights // Веса для алгоритма combined
+	Similarity        SimilarityConfig  // Алгоритм и порог нечеткой группировки
+}
+
+// similaritySettings возвращает алгоритм и порог нечеткой группировки с учетом значений по умолчанию
+func (c DuplicateDetectionConfig) similaritySettings() (string, float64) {
+	algorithm := strings.ToLower(strings.TrimSpace(c.Similarity.Algorithm))
+	if algorithm == "" {
+		algorithm = SimilarityAlgorithmCombined
+	}
+	threshold := c.Similarity.Threshold
+	if threshold <= 0 {
+		threshold = c.Threshold
+	}
+	return algorithm, threshold
+}
+
+// similarityWeights возвращает веса алгоритма combined, подставляя значения по умолчанию для нулевых весов
+func (c DuplicateDetectionConfig) similarityWeights() SimilarityWeights {
+	weights := c.SimilarityWeights
+	if weights.Levenshtein == 0 && weights.DamerauLevenshtein == 0 &&
+		weights.Bigram == 0 && weights.Trigram == 0 &&
+		weights.Jaccard == 0 && weights.Soundex == 0 && weights.Metaphone == 0 {
+		weights = DefaultSimilarityWeights()
+	}
+	return weights
 }
 
-// DefaultDuplicateDetectionConfig возвращает конфигурацию по умолчанию
+// DefaultDuplicateDetectionConfig возвращает конфигурацию по умолчанию.
+// Порог Similarity не задан, поэтому нечеткая группировка использует Threshold.
 func DefaultDuplicateDetectionConfig() DuplicateDetectionConfig {
 	return DuplicateDetectionConfig{
 		UseExactMatching:  true,
@@ -316,6 +369,9 @@ func DefaultDuplicateDetectionConfig() DuplicateDetectionConfig {
 		MinConfidence:     0.0,
 		MergeOverlapping:  true,
 		SimilarityWeights: DefaultSimilarityWeights(),
+		Similarity: SimilarityConfig{
+			Algorithm: SimilarityAlgorithmCombined,
+		},
 	}
 }
 
@@ -346,10 +402,14 @@ func (nsi *NSINormalizer) BatchNormalize(names []string, options NormalizationOp
 func (nsi *NSINormalizer) BatchFindDuplicates(
 	batches [][]DuplicateItem,
 	config DuplicateDetectionConfig,
-) [][]DuplicateGroup {
+) ([][]DuplicateGroup, error) {
 	results := make([][]DuplicateGroup, len(batches))
 	for i, batch := range batches {
-		results[i] = nsi.FindDuplicates(batch, config)
+		groups, err := nsi.FindDuplicates(batch, config)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = groups
 	}
-	return results
+	return results, nil
 }
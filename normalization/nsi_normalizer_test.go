@@ -273,7 +273,10 @@ func TestNSINormalizer_FindDuplicates(t *testing.T) {
 	config := DefaultDuplicateDetectionConfig()
 	config.Threshold = 0.8
 
-	groups := nsi.FindDuplicates(items, config)
+	groups, err := nsi.FindDuplicates(items, config)
+	if err != nil {
+		t.Fatalf("FindDuplicates failed: %v", err)
+	}
 
 	// Должны найти хотя бы одну группу дубликатов
 	if len(groups) == 0 {
@@ -350,8 +353,10 @@ func ExampleNSINormalizer_usage() {
 	}
 
 	config := DefaultDuplicateDetectionConfig()
-	groups := nsi.FindDuplicates(items, config)
-	_ = groups
+	groups, err := nsi.FindDuplicates(items, config)
+	if err != nil {
+		return
+	}
 
 	// Оцениваем алгоритм
 	metrics := nsi.EvaluateAlgorithm(groups, groups)
@@ -0,0 +1,99 @@
+package normalization
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Фазы нормализации, по которым накапливается время обработки (ключи PhaseTimings)
+const (
+	PhaseDBLookup       = "db_lookup"      // чтение из БД: исходные записи, уже нормализованные, эталоны по ИНН/БИН
+	PhaseRules          = "rules"          // правила: валидация, категоризация, извлечение атрибутов и ИНН/БИН
+	PhaseBenchmark      = "benchmark"      // поиск эталона по наименованию
+	PhaseAI             = "ai"             // вызовы AI для нормализации наименований
+	PhaseClassification = "classification" // классификация КПВЭД
+	PhaseEnrichment     = "enrichment"     // дозаполнение реквизитов из атрибутов
+	PhaseDBWrite        = "db_write"       // запись в БД: очистка, фильтрация дубликатов, вставка, checkpoint
+	PhaseMapping        = "mapping"        // автоматический мэппинг контрагентов после нормализации
+)
+
+// phase индекс фазы в PhaseTimer
+type phase int
+
+const (
+	phaseDBLookup phase = iota
+	phaseRules
+	phaseBenchmark
+	phaseAI
+	phaseClassification
+	phaseEnrichment
+	phaseDBWrite
+	phaseMapping
+	phaseCount
+)
+
+var phaseNames = [phaseCount]string{
+	PhaseDBLookup,
+	PhaseRules,
+	PhaseBenchmark,
+	PhaseAI,
+	PhaseClassification,
+	PhaseEnrichment,
+	PhaseDBWrite,
+	PhaseMapping,
+}
+
+// PhaseTimer накапливает время по фазам нормализации.
+// Счетчики атомарные, поэтому таймер можно использовать из параллельных воркеров;
+// при параллельной обработке сумма фаз - это суммарное время воркеров, а не время по часам.
+type PhaseTimer struct {
+	totals [phaseCount]int64
+}
+
+// add добавляет длительность к фазе
+func (t *PhaseTimer) add(p phase, d time.Duration) {
+	if t == nil {
+		return
+	}
+	atomic.AddInt64(&t.totals[p], int64(d))
+}
+
+// since добавляет к фазе время, прошедшее с start
+func (t *PhaseTimer) since(p phase, start time.Time) {
+	t.add(p, time.Since(start))
+}
+
+// Reset обнуляет накопленное время
+func (t *PhaseTimer) Reset() {
+	if t == nil {
+		return
+	}
+	for i := range t.totals {
+		atomic.StoreInt64(&t.totals[i], 0)
+	}
+}
+
+// Snapshot возвращает накопленное время по всем фазам
+func (t *PhaseTimer) Snapshot() map[string]time.Duration {
+	timings := make(map[string]time.Duration, phaseCount)
+	for i, name := range phaseNames {
+		var total int64
+		if t != nil {
+			total = atomic.LoadInt64(&t.totals[i])
+		}
+		timings[name] = time.Duration(total)
+	}
+	return timings
+}
+
+// Total возвращает сумму времени по всем фазам
+func (t *PhaseTimer) Total() time.Duration {
+	if t == nil {
+		return 0
+	}
+	var total int64
+	for i := range t.totals {
+		total += atomic.LoadInt64(&t.totals[i])
+	}
+	return time.Duration(total)
+}
@@ -0,0 +1,122 @@
+package normalization
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"httpserver/database"
+)
+
+// slowAINameNormalizer AI нормализатор с фиксированной задержкой ответа
+type slowAINameNormalizer struct {
+	delay time.Duration
+}
+
+func (m *slowAINameNormalizer) NormalizeName(ctx context.Context, name string) (string, error) {
+	time.Sleep(m.delay)
+	return name, nil
+}
+
+func (m *slowAINameNormalizer) NormalizeCounterparty(ctx context.Context, name, inn, bin string) (string, error) {
+	return m.NormalizeName(ctx, name)
+}
+
+// slowBenchmarkFinder поиск эталонов с фиксированной задержкой, эталон не находится
+type slowBenchmarkFinder struct {
+	delay time.Duration
+}
+
+func (m *slowBenchmarkFinder) FindBestMatch(name string, entityType string) (string, bool, error) {
+	time.Sleep(m.delay)
+	return "", false, nil
+}
+
+func TestPhaseTimer_AccumulatesConcurrently(t *testing.T) {
+	var timer PhaseTimer
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			timer.add(phaseAI, 10*time.Millisecond)
+			timer.add(phaseDBWrite, time.Millisecond)
+		}()
+	}
+	wg.Wait()
+
+	timings := timer.Snapshot()
+	if len(timings) != int(phaseCount) {
+		t.Errorf("Expected %d phases in snapshot, got %d", phaseCount, len(timings))
+	}
+	if timings[PhaseAI] != 100*time.Millisecond {
+		t.Errorf("Expected ai = 100ms, got %v", timings[PhaseAI])
+	}
+	if timings[PhaseDBWrite] != 10*time.Millisecond {
+		t.Errorf("Expected db_write = 10ms, got %v", timings[PhaseDBWrite])
+	}
+	if timer.Total() != 110*time.Millisecond {
+		t.Errorf("Expected total 110ms, got %v", timer.Total())
+	}
+
+	timer.Reset()
+	if timer.Total() != 0 {
+		t.Errorf("Expected zero total after Reset, got %v", timer.Total())
+	}
+}
+
+func TestProcessNormalization_PhaseTimingsSumToTotal(t *testing.T) {
+	serviceDB := createTestServiceDB(t)
+	defer serviceDB.Close()
+
+	client, err := serviceDB.CreateClient("Test Client", "Test Legal", "Desc", "test@test.com", "+123", "TAX", "user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Test Project", "counterparty", "Desc", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	const aiDelay = 40 * time.Millisecond
+	const benchmarkDelay = 20 * time.Millisecond
+	normalizer := createTestNormalizer(serviceDB, client.ID, project.ID, make(chan string, 100), nil,
+		&slowAINameNormalizer{delay: aiDelay}, &slowBenchmarkFinder{delay: benchmarkDelay})
+
+	// Один контрагент: воркер один, поэтому сумма фаз сопоставима со временем по часам
+	counterparties := []*database.CatalogItem{
+		createTestCounterparty(1, "ООО Тест", `<ИНН>7707083893</ИНН><Адрес>г. Москва</Адрес>`),
+	}
+
+	result, err := normalizer.ProcessNormalization(counterparties, true)
+	if err != nil {
+		t.Fatalf("ProcessNormalization failed: %v", err)
+	}
+	if result.TotalProcessed != 1 {
+		t.Fatalf("Expected 1 processed counterparty, got %d (errors: %v)", result.TotalProcessed, result.Errors)
+	}
+
+	if result.PhaseTimings[PhaseAI] < aiDelay {
+		t.Errorf("Expected ai phase >= %v, got %v", aiDelay, result.PhaseTimings[PhaseAI])
+	}
+	if result.PhaseTimings[PhaseBenchmark] < benchmarkDelay {
+		t.Errorf("Expected benchmark phase >= %v, got %v", benchmarkDelay, result.PhaseTimings[PhaseBenchmark])
+	}
+	if result.PhaseTimings[PhaseDBWrite] <= 0 {
+		t.Errorf("Expected db_write phase to be recorded, got %v", result.PhaseTimings[PhaseDBWrite])
+	}
+
+	var sum time.Duration
+	for _, d := range result.PhaseTimings {
+		sum += d
+	}
+	if sum > result.TotalDuration {
+		t.Errorf("Phases sum %v exceeds total duration %v", sum, result.TotalDuration)
+	}
+	// Неучтенными остаются только запуск горутины и отправка событий
+	if sum < result.TotalDuration*8/10 {
+		t.Errorf("Phases sum %v is much less than total duration %v: %v", sum, result.TotalDuration, result.PhaseTimings)
+	}
+}
@@ -0,0 +1,148 @@
+package normalization
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"httpserver/normalization/algorithms"
+)
+
+// Алгоритмы схожести для нечеткой группировки (SimilarityConfig.Algorithm)
+const (
+	SimilarityAlgorithmCombined    = "combined"     // Взвешенная комбинация FuzzyAlgorithms.CombinedSimilarity
+	SimilarityAlgorithmLevenshtein = "levenshtein"  // Нормализованное расстояние Левенштейна
+	SimilarityAlgorithmJaroWinkler = "jaro_winkler" // Jaro-Winkler, чувствителен к общему префиксу
+	SimilarityAlgorithmTokenSet    = "token_set"    // Token set ratio, не зависит от порядка и повторов слов
+)
+
+// SimilarityAlgorithm алгоритм схожести двух наименований (0.0 - 1.0)
+type SimilarityAlgorithm interface {
+	Similarity(a, b string) float64
+}
+
+// SimilarityConfig выбор алгоритма и порога для нечеткой группировки дублей.
+// Пустой Algorithm означает SimilarityAlgorithmCombined, нулевой Threshold - DuplicateDetectionConfig.Threshold.
+type SimilarityConfig struct {
+	Algorithm string  `json:"algorithm"`
+	Threshold float64 `json:"threshold"`
+}
+
+// SimilarityAlgorithms возвращает названия поддерживаемых алгоритмов схожести
+func SimilarityAlgorithms() []string {
+	return []string{
+		SimilarityAlgorithmCombined,
+		SimilarityAlgorithmLevenshtein,
+		SimilarityAlgorithmJaroWinkler,
+		SimilarityAlgorithmTokenSet,
+	}
+}
+
+// NewSimilarityAlgorithm создает алгоритм схожести по названию.
+// weights используются только алгоритмом SimilarityAlgorithmCombined.
+func NewSimilarityAlgorithm(name string, weights SimilarityWeights) (SimilarityAlgorithm, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", SimilarityAlgorithmCombined:
+		return combinedSimilarity{fuzzy: NewFuzzyAlgorithms(), weights: weights}, nil
+	case SimilarityAlgorithmLevenshtein:
+		return levenshteinSimilarity{}, nil
+	case SimilarityAlgorithmJaroWinkler:
+		return jaroWinklerSimilarity{}, nil
+	case SimilarityAlgorithmTokenSet:
+		return tokenSetSimilarity{}, nil
+	default:
+		return nil, fmt.Errorf("unknown similarity algorithm %q (supported: %s)",
+			name, strings.Join(SimilarityAlgorithms(), ", "))
+	}
+}
+
+// combinedSimilarity взвешенная комбинация алгоритмов FuzzyAlgorithms
+type combinedSimilarity struct {
+	fuzzy   *FuzzyAlgorithms
+	weights SimilarityWeights
+}
+
+func (s combinedSimilarity) Similarity(a, b string) float64 {
+	return s.fuzzy.CombinedSimilarity(a, b, s.weights)
+}
+
+// levenshteinSimilarity 1 - расстояние Левенштейна / длина более длинной строки (по рунам, без учета регистра)
+type levenshteinSimilarity struct{}
+
+func (levenshteinSimilarity) Similarity(a, b string) float64 {
+	return levenshteinRatio(strings.ToLower(a), strings.ToLower(b))
+}
+
+// jaroWinklerSimilarity схожесть Jaro-Winkler из пакета algorithms
+type jaroWinklerSimilarity struct{}
+
+func (jaroWinklerSimilarity) Similarity(a, b string) float64 {
+	return algorithms.JaroWinklerSimilarity(a, b)
+}
+
+// tokenSetSimilarity token set ratio: сравнивает общую часть слов с общей частью плюс
+// отличающимися словами каждой строки, поэтому перестановка слов ("болт стальной" /
+// "стальной болт") и добавочные слова снижают схожесть меньше, чем у посимвольных алгоритмов
+type tokenSetSimilarity struct{}
+
+func (tokenSetSimilarity) Similarity(a, b string) float64 {
+	tokensA, tokensB := tokenSet(a), tokenSet(b)
+	if len(tokensA) == 0 && len(tokensB) == 0 {
+		return levenshteinRatio(strings.ToLower(a), strings.ToLower(b))
+	}
+
+	var common, onlyA, onlyB []string
+	for token := range tokensA {
+		if tokensB[token] {
+			common = append(common, token)
+		} else {
+			onlyA = append(onlyA, token)
+		}
+	}
+	for token := range tokensB {
+		if !tokensA[token] {
+			onlyB = append(onlyB, token)
+		}
+	}
+	sort.Strings(common)
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+
+	base := strings.Join(common, " ")
+	withA := strings.TrimSpace(base + " " + strings.Join(onlyA, " "))
+	withB := strings.TrimSpace(base + " " + strings.Join(onlyB, " "))
+
+	best := levenshteinRatio(withA, withB)
+	if base != "" {
+		best = maxFloat(best, levenshteinRatio(base, withA), levenshteinRatio(base, withB))
+	}
+	return best
+}
+
+// tokenSet возвращает множество слов наименования
+func tokenSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, token := range tokenize(text) {
+		set[token] = true
+	}
+	return set
+}
+
+// levenshteinRatio нормализованная схожесть по расстоянию Левенштейна
+func levenshteinRatio(a, b string) float64 {
+	maxLen := maxInt(len([]rune(a)), len([]rune(b)))
+	if maxLen == 0 {
+		return 1.0
+	}
+	return 1.0 - float64(levenshteinDistance(a, b))/float64(maxLen)
+}
+
+func maxFloat(values ...float64) float64 {
+	result := 0.0
+	for _, value := range values {
+		if value > result {
+			result = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+package normalization
+
+import (
+	"testing"
+)
+
+func TestSimilarityAlgorithms_CompareOnSameInputs(t *testing.T) {
+	levenshtein, _ := NewSimilarityAlgorithm(SimilarityAlgorithmLevenshtein, SimilarityWeights{})
+	jaroWinkler, _ := NewSimilarityAlgorithm(SimilarityAlgorithmJaroWinkler, SimilarityWeights{})
+	tokenSet, _ := NewSimilarityAlgorithm(SimilarityAlgorithmTokenSet, SimilarityWeights{})
+
+	// Перестановка слов: token set не зависит от порядка, посимвольные алгоритмы - зависят
+	a, b := "болт стальной оцинкованный", "оцинкованный стальной болт"
+	if got := tokenSet.Similarity(a, b); got != 1.0 {
+		t.Errorf("token_set(%q, %q) = %v, want 1.0", a, b, got)
+	}
+	if got := levenshtein.Similarity(a, b); got >= 0.7 {
+		t.Errorf("levenshtein(%q, %q) = %v, expected low score for reordered words", a, b, got)
+	}
+
+	// Опечатка в окончании: Jaro-Winkler выше Левенштейна за счет общего префикса
+	a, b = "Гвоздь строительный", "Гвоздь строительнй"
+	if jw, lev := jaroWinkler.Similarity(a, b), levenshtein.Similarity(a, b); jw <= lev {
+		t.Errorf("jaro_winkler = %v, levenshtein = %v: expected jaro_winkler higher for typo in suffix", jw, lev)
+	}
+
+	// Одинаковые и полностью разные строки оцениваются одинаково всеми алгоритмами
+	for name, algorithm := range map[string]SimilarityAlgorithm{
+		SimilarityAlgorithmLevenshtein: levenshtein,
+		SimilarityAlgorithmJaroWinkler: jaroWinkler,
+		SimilarityAlgorithmTokenSet:    tokenSet,
+	} {
+		if got := algorithm.Similarity("шуруп саморез", "шуруп саморез"); got != 1.0 {
+			t.Errorf("%s: identical strings = %v, want 1.0", name, got)
+		}
+		if got := algorithm.Similarity("кабель медный", "фанера березовая"); got >= 0.6 {
+			t.Errorf("%s: unrelated strings = %v, expected low score", name, got)
+		}
+	}
+}
+
+func TestNewSimilarityAlgorithm_Unknown(t *testing.T) {
+	if _, err := NewSimilarityAlgorithm("soundex_v2", SimilarityWeights{}); err == nil {
+		t.Error("Expected error for unknown algorithm")
+	}
+}
+
+func TestNSINormalizer_FindDuplicatesWithSummary_Algorithm(t *testing.T) {
+	nsi := NewNSINormalizer()
+	items := []DuplicateItem{
+		{ID: 1, NormalizedName: "болт стальной оцинкованный"},
+		{ID: 2, NormalizedName: "оцинкованный стальной болт"},
+		{ID: 3, NormalizedName: "кабель медный"},
+	}
+
+	config := DefaultDuplicateDetectionConfig()
+	config.UseExactMatching = false
+	config.Similarity = SimilarityConfig{Algorithm: SimilarityAlgorithmTokenSet, Threshold: 0.9}
+
+	groups, summary, err := nsi.FindDuplicatesWithSummary(items, config)
+	if err != nil {
+		t.Fatalf("FindDuplicatesWithSummary failed: %v", err)
+	}
+	if summary.Algorithm != SimilarityAlgorithmTokenSet || summary.Threshold != 0.9 {
+		t.Errorf("Summary should report chosen algorithm and threshold, got %+v", summary)
+	}
+	if len(groups) != 1 || len(groups[0].ItemIDs) != 2 || summary.GroupCount != 1 || summary.DuplicateItems != 2 {
+		t.Errorf("Expected one group of reordered names, got groups=%+v summary=%+v", groups, summary)
+	}
+
+	// Левенштейн с тем же порогом перестановку слов дубликатом не считает
+	config.Similarity.Algorithm = SimilarityAlgorithmLevenshtein
+	groups, summary, err = nsi.FindDuplicatesWithSummary(items, config)
+	if err != nil {
+		t.Fatalf("FindDuplicatesWithSummary failed: %v", err)
+	}
+	if len(groups) != 0 || summary.Algorithm != SimilarityAlgorithmLevenshtein {
+		t.Errorf("Expected no levenshtein groups, got groups=%+v summary=%+v", groups, summary)
+	}
+
+	config.Similarity.Algorithm = "unknown"
+	if _, _, err := nsi.FindDuplicatesWithSummary(items, config); err == nil {
+		t.Error("Expected error for unknown algorithm")
+	}
+	if _, err := nsi.FindDuplicates(items, config); err == nil {
+		t.Error("Expected FindDuplicates to return the unknown algorithm error")
+	}
+}
+
+func TestNSINormalizer_FindDuplicates_HonorsCallerThreshold(t *testing.T) {
+	nsi := NewNSINormalizer()
+	items := []DuplicateItem{
+		{ID: 1, NormalizedName: "болт стальной оцинкованный"},
+		{ID: 2, NormalizedName: "оцинкованный стальной болт"},
+	}
+
+	config := DefaultDuplicateDetectionConfig()
+	config.UseExactMatching = false
+	config.Similarity.Algorithm = SimilarityAlgorithmTokenSet
+
+	config.Threshold = 0.3
+	_, summary, err := nsi.FindDuplicatesWithSummary(items, config)
+	if err != nil {
+		t.Fatalf("FindDuplicatesWithSummary failed: %v", err)
+	}
+	if summary.Threshold != 0.3 {
+		t.Errorf("Expected caller threshold 0.3 to be applied, got %v", summary.Threshold)
+	}
+	if summary.GroupCount != 1 {
+		t.Errorf("Expected one group at threshold 0.3, got %+v", summary)
+	}
+}
@@ -0,0 +1,117 @@
+package normalization
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RussianStopwords встроенный список русских служебных слов: предлоги, союзы, частицы, местоимения
+var RussianStopwords = []string{
+	"а", "без", "более", "бы", "был", "была", "были", "было", "быть", "в", "вам", "вас", "весь", "во",
+	"вот", "все", "всего", "всех", "вы", "где", "да", "даже", "для", "до", "его", "ее", "если", "есть",
+	"еще", "же", "за", "здесь", "и", "из", "или", "им", "их", "к", "как", "ко", "когда", "кроме", "кто",
+	"ли", "либо", "между", "меньше", "мы", "на", "над", "не", "него", "нее", "нет", "ни", "них", "но",
+	"ну", "о", "об", "однако", "он", "она", "они", "оно", "от", "очень", "по", "под", "после", "при",
+	"про", "с", "со", "так", "также", "такой", "там", "те", "тем", "то", "того", "тоже", "той", "только",
+	"том", "ту", "у", "уже", "хотя", "чем", "через", "что", "чтобы", "эта", "эти", "этих", "это", "этого",
+	"этой", "этот", "я",
+}
+
+// EnglishStopwords встроенный список английских служебных слов
+var EnglishStopwords = []string{
+	"a", "an", "and", "are", "as", "at", "be", "by", "for", "from", "in", "into", "is", "it", "its",
+	"no", "not", "of", "on", "or", "per", "than", "that", "the", "this", "to", "with", "without",
+}
+
+// StopwordSet набор стоп-слов для извлечения ключевых слов. Слова хранятся в нижнем регистре
+// с заменой "ё" на "е". Набор безопасен для одновременного использования из нескольких горутин.
+type StopwordSet struct {
+	mu    sync.RWMutex
+	words map[string]bool
+}
+
+// NewStopwordSet создает набор из списков слов
+func NewStopwordSet(lists ...[]string) *StopwordSet {
+	set := &StopwordSet{words: make(map[string]bool)}
+	for _, list := range lists {
+		set.Add(list...)
+	}
+	return set
+}
+
+// DefaultStopwordSet создает набор из встроенных русского и английского списков
+// и дополнительных слов extra (например, из конфигурации)
+func DefaultStopwordSet(extra ...string) *StopwordSet {
+	return NewStopwordSet(RussianStopwords, EnglishStopwords, extra)
+}
+
+// normalizeStopword приводит слово к виду, в котором оно хранится в наборе
+func normalizeStopword(word string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(word)), "ё", "е")
+}
+
+// Add добавляет слова в набор; пустые строки пропускаются
+func (s *StopwordSet) Add(words ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, word := range words {
+		if word = normalizeStopword(word); word != "" {
+			s.words[word] = true
+		}
+	}
+}
+
+// Remove удаляет слова из набора, например чтобы сохранить термин предметной области
+func (s *StopwordSet) Remove(words ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, word := range words {
+		delete(s.words, normalizeStopword(word))
+	}
+}
+
+// Contains проверяет, является ли слово стоп-словом (без учета регистра)
+func (s *StopwordSet) Contains(word string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.words[word] {
+		return true
+	}
+	return s.words[normalizeStopword(word)]
+}
+
+// Words возвращает слова набора в алфавитном порядке
+func (s *StopwordSet) Words() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	words := make([]string, 0, len(s.words))
+	for word := range s.words {
+		words = append(words, word)
+	}
+	sort.Strings(words)
+	return words
+}
+
+var (
+	stopwordsMu sync.RWMutex
+	stopwords   = DefaultStopwordSet()
+)
+
+// Stopwords возвращает общий набор стоп-слов, используемый ExtractKeywords
+// и генерацией ключевых слов ГОСТов
+func Stopwords() *StopwordSet {
+	stopwordsMu.RLock()
+	defer stopwordsMu.RUnlock()
+	return stopwords
+}
+
+// SetStopwords заменяет общий набор стоп-слов; nil восстанавливает встроенный набор
+func SetStopwords(set *StopwordSet) {
+	if set == nil {
+		set = DefaultStopwordSet()
+	}
+	stopwordsMu.Lock()
+	defer stopwordsMu.Unlock()
+	stopwords = set
+}
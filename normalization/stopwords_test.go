@@ -0,0 +1,48 @@
+package normalization
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStopwordSet(t *testing.T) {
+	set := DefaultStopwordSet("Ещё", " прочее ")
+
+	for _, word := range []string{"для", "ИЛИ", "the", "without", "еще", "ещё", "прочее"} {
+		if !set.Contains(word) {
+			t.Errorf("Expected %q to be a stopword", word)
+		}
+	}
+	for _, word := range []string{"болт", "сталь", "труба", "steel", "гост"} {
+		if set.Contains(word) {
+			t.Errorf("Domain term %q must not be a stopword", word)
+		}
+	}
+
+	set.Remove("прочее")
+	if set.Contains("прочее") {
+		t.Error("Expected removed word to be kept")
+	}
+}
+
+func TestSetStopwords_ExtractKeywords(t *testing.T) {
+	defer SetStopwords(nil)
+
+	name := "Болт для крепления и фиксации, steel with zinc coating"
+	want := []string{"болт", "крепления", "фиксации", "steel", "zinc", "coating"}
+	if got := ExtractKeywords(name); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractKeywords(%q) = %v, want %v", name, got, want)
+	}
+
+	// Дополнительные стоп-слова из конфигурации отбрасываются, термины предметной области остаются
+	SetStopwords(DefaultStopwordSet("крепления", "coating"))
+	want = []string{"болт", "фиксации", "steel", "zinc"}
+	if got := ExtractKeywords(name); !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractKeywords with extra stopwords = %v, want %v", got, want)
+	}
+
+	SetStopwords(nil)
+	if !Stopwords().Contains("для") || Stopwords().Contains("крепления") {
+		t.Error("SetStopwords(nil) should restore the built-in set")
+	}
+}
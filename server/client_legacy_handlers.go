@@ -19,8 +19,6 @@ import (
 	"httpserver/database"
 	"httpserver/normalization"
 	"httpserver/server/services"
-
-	"github.com/google/uuid"
 )
 
 // Legacy client handlers - перемещены из server.go для рефакторинга
@@ -2144,10 +2142,22 @@ func (s *Server) handleGetClientBenchmarks(w http.ResponseWriter, r *http.Reques
 	category := r.URL.Query().Get("category")
 	approvedOnly := r.URL.Query().Get("approved_only") == "true"
 
-	log.Printf("[Benchmarks] Getting benchmarks for project %d, client %d, category: %s, approvedOnly: %v",
-		projectID, clientID, category, approvedOnly)
+	var reviewStatuses []string
+	if reviewStatusParam := r.URL.Query().Get("review_status"); reviewStatusParam != "" {
+		for _, status := range strings.Split(reviewStatusParam, ",") {
+			status = strings.TrimSpace(status)
+			if !database.IsValidBenchmarkReviewStatus(status) {
+				s.handleHTTPError(w, r, NewValidationError(fmt.Sprintf("Неизвестный статус согласования: %s", status), nil))
+				return
+			}
+			reviewStatuses = append(reviewStatuses, status)
+		}
+	}
 
-	benchmarks, err := s.serviceDB.GetClientBenchmarks(projectID, category, approvedOnly)
+	log.Printf("[Benchmarks] Getting benchmarks for project %d, client %d, category: %s, approvedOnly: %v, reviewStatuses: %v",
+		projectID, clientID, category, approvedOnly, reviewStatuses)
+
+	benchmarks, err := s.serviceDB.GetClientBenchmarks(projectID, category, approvedOnly, reviewStatuses...)
 	if err != nil {
 		log.Printf("[Benchmarks] Error getting benchmarks: %v", err)
 		s.writeJSONError(w, r, err.Error(), http.StatusInternalServerError)
@@ -2413,25 +2423,29 @@ func (s *Server) handleCreateProjectDatabase(w http.ResponseWriter, r *http.Requ
 		}
 	}
 
-	database, err := s.serviceDB.CreateProjectDatabase(projectID, req.Name, finalPath, req.Description, fileSize)
+	projectDB, err := s.serviceDB.CreateProjectDatabase(projectID, req.Name, finalPath, req.Description, fileSize)
 	if err != nil {
 		log.Printf("Error creating database for project_id=%d: %v", projectID, err)
+		if errors.Is(err, database.ErrDuplicate) {
+			s.writeJSONError(w, r, err.Error(), http.StatusConflict)
+			return
+		}
 		s.writeJSONError(w, r, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	log.Printf("Database created successfully: database_id=%d, project_id=%d, name=%q", database.ID, projectID, database.Name)
+	log.Printf("Database created successfully: database_id=%d, project_id=%d, name=%q", projectDB.ID, projectID, projectDB.Name)
 
 	// Создаем или обновляем upload записи в исходной базе данных
 	// Это необходимо для того, чтобы getNomenclatureFromMainDB мог найти данные
 	// Выполняем синхронно, чтобы данные были доступны сразу после добавления БД
-	if err := s.ensureUploadRecordsForDatabase(finalPath, clientID, projectID, database.ID); err != nil {
-		log.Printf("Warning: Failed to ensure upload records for database %d: %v (database was still created)", database.ID, err)
+	if err := s.ensureUploadRecordsForDatabase(finalPath, clientID, projectID, projectDB.ID); err != nil {
+		log.Printf("Warning: Failed to ensure upload records for database %d: %v (database was still created)", projectDB.ID, err)
 		// Не возвращаем ошибку, так как база данных уже создана
 		// Upload записи можно будет создать позже
 	}
 
-	s.writeJSONResponse(w, r, database, http.StatusCreated)
+	s.writeJSONResponse(w, r, projectDB, http.StatusCreated)
 }
 
 // handleGetProjectDatabase получает базу данных проекта
@@ -3372,6 +3386,31 @@ func (s *Server) handleUploadProjectDatabase(w http.ResponseWriter, r *http.Requ
 	}
 	log.Printf("[handleUploadProjectDatabase] ✅ Файл успешно прошел проверку целостности")
 
+	// Повторная загрузка той же выгрузки: связываем с уже зарегистрированной базой вместо создания дубликата
+	contentHash, err := database.ComputeFileSHA256(filePath)
+	if err != nil {
+		log.Printf("[handleUploadProjectDatabase] Предупреждение: не удалось вычислить хеш файла: %v", err)
+		// Продолжаем, так как это не критично
+	} else if existingDB, err := s.serviceDB.GetProjectDatabaseByHash(projectID, contentHash); err != nil {
+		log.Printf("[handleUploadProjectDatabase] Предупреждение: не удалось проверить дубликаты по содержимому: %v", err)
+	} else if existingDB != nil {
+		log.Printf("[handleUploadProjectDatabase] Файл совпадает по содержимому с базой данных ID=%d (sha256=%s), загруженная копия удалена",
+			existingDB.ID, contentHash)
+		os.Remove(filePath)
+		s.writeJSONResponse(w, r, map[string]interface{}{
+			"success":       true,
+			"message":       "Такая же база данных уже загружена в проект",
+			"database":      existingDB,
+			"database_id":   existingDB.ID,
+			"file_path":     existingDB.FilePath,
+			"file_name":     fileName,
+			"content_hash":  contentHash,
+			"is_duplicate":  true,
+			"name_required": false,
+		}, http.StatusOK)
+		return
+	}
+
 	// Проверяем лимит на количество баз данных в проекте
 	const MAX_DATABASES_PER_PROJECT = 100
 	dbCount, err := s.serviceDB.GetProjectDatabaseCount(projectID, false)
@@ -3927,121 +3966,46 @@ func (s *Server) EnsureUploadRecordsForDatabase(dbPath string, clientID, project
 // ensureUploadRecordsForDatabase создает или обновляет upload записи в исходной базе данных
 // Это необходимо для того, чтобы getNomenclatureFromMainDB мог найти данные через uploads таблицу
 func (s *Server) ensureUploadRecordsForDatabase(dbPath string, clientID, projectID, databaseID int) error {
-	// Открываем исходную базу данных
 	sourceDB, err := database.NewDB(dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to open source database %s: %w", dbPath, err)
 	}
 	defer sourceDB.Close()
 
-	// Получаем все существующие upload записи
-	uploads, err := sourceDB.GetAllUploads()
-	if err != nil {
-		// Если таблица uploads не существует, это нормально - база может быть пустой
-		log.Printf("Note: Could not get uploads from %s (table may not exist): %v", dbPath, err)
-		uploads = []*database.Upload{}
-	}
-
-	// Проверяем, есть ли upload записи с правильными client_id и project_id
-	needsUpdate := false
-	needsCreate := false
+	_, err = database.EnsureUploadRecordsForDatabase(sourceDB, clientID, projectID, databaseID)
+	return err
+}
 
-	if len(uploads) == 0 {
-		needsCreate = true
-	} else {
-		// Проверяем, есть ли хотя бы одна запись с правильными client_id и project_id
-		hasCorrectUpload := false
-		for _, upload := range uploads {
-			if upload.ClientID != nil && *upload.ClientID == clientID &&
-				upload.ProjectID != nil && *upload.ProjectID == projectID {
-				hasCorrectUpload = true
-				break
-			}
-		}
+// handleKpvedHierarchy возвращает иерархию КПВЭД классификатора
 
-		if !hasCorrectUpload {
-			needsUpdate = true
-			// Если все upload записи не имеют правильных client_id/project_id, создаем новую
-			allMissingIDs := true
-			for _, upload := range uploads {
-				if upload.ClientID != nil || upload.ProjectID != nil {
-					allMissingIDs = false
-					break
-				}
-			}
-			if allMissingIDs {
-				needsCreate = true
-			}
-		}
+// handleVerifyProjectTuGostReferences проверяет ссылки номенклатур проекта на ГОСТы по базе ГОСТов:
+// отсутствующие и отмененные стандарты попадают в отчет
+func (s *Server) handleVerifyProjectTuGostReferences(w http.ResponseWriter, r *http.Request, clientID, projectID int) {
+	if s.serviceDB == nil {
+		s.writeJSONError(w, r, "Service database not available", http.StatusInternalServerError)
+		return
 	}
-
-	// Обновляем существующие upload записи
-	if needsUpdate {
-		for _, upload := range uploads {
-			// Обновляем только если client_id или project_id отсутствуют или неверны
-			shouldUpdate := false
-			if upload.ClientID == nil || *upload.ClientID != clientID {
-				shouldUpdate = true
-			}
-			if upload.ProjectID == nil || *upload.ProjectID != projectID {
-				shouldUpdate = true
-			}
-
-			if shouldUpdate {
-				err := sourceDB.UpdateUploadClientProject(upload.ID, clientID, projectID)
-				if err != nil {
-					log.Printf("Warning: Failed to update upload %d in %s: %v", upload.ID, dbPath, err)
-				} else {
-					log.Printf("Updated upload %d in %s with client_id=%d, project_id=%d", upload.ID, dbPath, clientID, projectID)
-				}
-			}
-		}
+	if s.gostsDB == nil {
+		s.writeJSONError(w, r, "GOSTs database not available", http.StatusServiceUnavailable)
+		return
 	}
 
-	// Создаем новую upload запись, если нужно
-	if needsCreate {
-		uploadUUID := uuid.New().String()
-		dbID := databaseID
-		
-		// Пытаемся определить версию 1С и имя конфигурации из метаданных или имени файла
-		version1C := "8.3"
-		configName := "Unknown"
-		
-		// Парсим имя файла для получения информации
-		fileName := filepath.Base(dbPath)
-		fileInfo := database.ParseDatabaseFileInfo(fileName)
-		if fileInfo.ConfigName != "" && fileInfo.ConfigName != "Unknown" {
-			configName = fileInfo.ConfigName
-		}
-
-		upload, err := sourceDB.CreateUploadWithDatabase(
-			uploadUUID,
-			version1C,
-			configName,
-			&dbID,
-			"", // computerName
-			"", // userName
-			"", // configVersion
-			1,  // iterationNumber
-			"", // iterationLabel
-			"", // programmerName
-			"", // uploadPurpose
-			nil, // parentUploadID
-		)
-		if err != nil {
-			return fmt.Errorf("failed to create upload in %s: %w", dbPath, err)
-		}
+	project, err := s.serviceDB.GetClientProject(projectID)
+	if err != nil {
+		s.writeJSONError(w, r, "Project not found", http.StatusNotFound)
+		return
+	}
+	if project.ClientID != clientID {
+		s.writeJSONError(w, r, "Project does not belong to this client", http.StatusBadRequest)
+		return
+	}
 
-		// Обновляем client_id и project_id
-		err = sourceDB.UpdateUploadClientProject(upload.ID, clientID, projectID)
-		if err != nil {
-			log.Printf("Warning: Failed to update new upload %d with client_id/project_id: %v", upload.ID, err)
-		} else {
-			log.Printf("Created and updated upload %d in %s with client_id=%d, project_id=%d", upload.ID, dbPath, clientID, projectID)
-		}
+	report, err := s.serviceDB.VerifyTuGostReferences(projectID, s.gostsDB)
+	if err != nil {
+		log.Printf("Error verifying TU/GOST references for project %d: %v", projectID, err)
+		s.writeJSONError(w, r, fmt.Sprintf("Failed to verify TU/GOST references: %v", err), http.StatusInternalServerError)
+		return
 	}
 
-	return nil
+	s.writeJSONResponse(w, r, report, http.StatusOK)
 }
-
-// handleKpvedHierarchy возвращает иерархию КПВЭД классификатора
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"httpserver/database"
+)
+
+// newClientRoutesTestServer создает сервер с клиентом и проектом для проверки маршрутов Gin
+func newClientRoutesTestServer(t *testing.T) (*Server, *database.ClientProject) {
+	t.Helper()
+	t.Chdir(t.TempDir()) // Сервер создает служебные базы (gosts.db) в текущем каталоге
+
+	db, normalizedDB, serviceDB := setupTestDB(t)
+	t.Cleanup(func() {
+		db.Close()
+		normalizedDB.Close()
+		serviceDB.Close()
+	})
+
+	tempDir := t.TempDir()
+	srv := NewServerWithConfig(db, normalizedDB, serviceDB,
+		filepath.Join(tempDir, "test.db"), filepath.Join(tempDir, "test_normalized.db"), &Config{
+			Port:                   "9999",
+			DatabasePath:           filepath.Join(tempDir, "test.db"),
+			NormalizedDatabasePath: filepath.Join(tempDir, "test_normalized.db"),
+			ServiceDatabasePath:    ":memory:",
+			MaxOpenConns:           25,
+			MaxIdleConns:           5,
+		})
+
+	client, err := serviceDB.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "", "RU", "test")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Проект", "nomenclature", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	return srv, project
+}
+
+func serveClientRoute(t *testing.T, srv *Server, method, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w
+}
+
+// TestClientRoutes_ProjectDatabaseSearch проверяет, что /databases/search не перехватывается маршрутом /:databaseId
+func TestClientRoutes_ProjectDatabaseSearch(t *testing.T) {
+	srv, project := newClientRoutesTestServer(t)
+
+	path := fmt.Sprintf("/api/clients/%d/projects/%d/databases/search?q=болт", project.ClientID, project.ID)
+	w := serveClientRoute(t, srv, http.MethodGet, path)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET %s: status %d, body %s", path, w.Code, w.Body.String())
+	}
+}
+
+// TestClientRoutes_RecomputeClientStats проверяет, что пересчет статистики доступен через роутер Gin
+func TestClientRoutes_RecomputeClientStats(t *testing.T) {
+	srv, project := newClientRoutesTestServer(t)
+
+	path := fmt.Sprintf("/api/clients/%d/statistics/recompute", project.ClientID)
+	w := serveClientRoute(t, srv, http.MethodPost, path)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST %s: status %d, body %s", path, w.Code, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"project_count":1`) {
+		t.Errorf("Expected recomputed stats in response, got %s", w.Body.String())
+	}
+}
+
+// TestClientRoutes_ProjectEnrichment проверяет, что маршруты обогащения доходят до обработчика
+// (без настроенного обогащения обработчик отвечает 503)
+func TestClientRoutes_ProjectEnrichment(t *testing.T) {
+	srv, project := newClientRoutesTestServer(t)
+
+	for _, tc := range []struct {
+		method string
+		action string
+	}{
+		{http.MethodGet, "status"},
+		{http.MethodPost, "start"},
+		{http.MethodPost, "stop"},
+	} {
+		path := fmt.Sprintf("/api/clients/%d/projects/%d/enrichment/%s", project.ClientID, project.ID, tc.action)
+		w := serveClientRoute(t, srv, tc.method, path)
+		if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "Enrichment is not configured") {
+			t.Errorf("%s %s: status %d, body %s", tc.method, path, w.Code, w.Body.String())
+		}
+	}
+}
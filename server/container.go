@@ -490,6 +490,9 @@ func (c *Container) InitServices() error {
 	c.NormalizationBenchmarkService = services.NewNormalizationBenchmarkService()
 	log.Printf("  ✓ NormalizationBenchmarkService создан")
 
+	// Стоп-слова для ключевых слов номенклатуры и ГОСТов
+	normalization.SetStopwords(normalization.DefaultStopwordSet(c.Config.Stopwords...))
+
 	// GostsDB (опционально, может не инициализироваться)
 	log.Printf("  Инициализация GOSTs базы данных...")
 	gostsDB, err := database.NewGostsDB("gosts.db")
@@ -497,8 +500,10 @@ func (c *Container) InitServices() error {
 		log.Printf("  ⚠ Предупреждение: не удалось инициализировать GOSTs базу данных: %v", err)
 		log.Printf("  GOSTs функциональность будет недоступна")
 	} else {
+		gostsDB.SetEncodingOptions(c.Config.GostEncodingOptions())
 		c.GostsDB = gostsDB
 		c.GostService = services.NewGostService(gostsDB)
+		c.GostService.SetSourcePriority(c.Config.GostSourcePriority)
 		log.Printf("  ✓ GOSTs база данных и сервис инициализированы")
 	}
 
@@ -531,6 +536,12 @@ func (c *Container) InitHandlers() error {
 	// ClientHandler
 	log.Printf("  Создание ClientHandler...")
 	c.ClientHandler = handlers.NewClientHandler(c.ClientService, c.BaseHandler)
+	c.ClientHandler.SetDocumentsDir(c.Config.ClientDocumentsDir)
+	if c.EnrichmentFactory != nil {
+		c.ClientHandler.SetEnrichmentRunner(services.NewEnrichmentRunner(
+			c.ServiceDB, c.EnrichmentFactory, c.Config.Enrichment.Services, c.Config.Enrichment.MinQualityScore,
+		))
+	}
 	log.Printf("  ✓ ClientHandler создан")
 
 	// NormalizationHandler
@@ -256,6 +256,8 @@ func (s *Server) CollectMetricsSnapshot() *database.PerformanceMetricsSnapshot {
 		}
 	}
 
+	detailedMetrics["dropped_log_entries"] = s.DroppedLogEntries()
+
 	// Сериализуем детальные метрики в JSON
 	metricDataJSON, err := json.Marshal(detailedMetrics)
 	if err != nil {
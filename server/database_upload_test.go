@@ -298,6 +298,85 @@ func TestHandleUploadProjectDatabase_AutoCreate(t *testing.T) {
 	}
 }
 
+func TestHandleUploadProjectDatabase_SameContentTwice(t *testing.T) {
+	srv, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	clientID, projectID := createTestClientAndProject(t, srv)
+
+	// Валидация загрузки открывает файл как SQLite, поэтому нужна настоящая база
+	sourcePath := filepath.Join(t.TempDir(), "source.db")
+	sourceDB, err := sql.Open("sqlite3", sourcePath)
+	if err != nil {
+		t.Fatalf("Failed to open source DB: %v", err)
+	}
+	if _, err := sourceDB.Exec(`CREATE TABLE export (id INTEGER PRIMARY KEY, name TEXT)`); err != nil {
+		t.Fatalf("Failed to create source table: %v", err)
+	}
+	sourceDB.Close()
+	testFileContent, err := os.ReadFile(sourcePath)
+	if err != nil {
+		t.Fatalf("Failed to read source DB: %v", err)
+	}
+
+	upload := func() (int, map[string]interface{}) {
+		req, err := createMultipartForm(t, "export.db", testFileContent, map[string]string{
+			"auto_create": "true",
+		})
+		if err != nil {
+			t.Fatalf("Failed to create multipart form: %v", err)
+		}
+		w := httptest.NewRecorder()
+		srv.handleUploadProjectDatabase(w, req, clientID, projectID)
+
+		var response map[string]interface{}
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to parse response: %v (body: %s)", err, w.Body.String())
+		}
+		return w.Code, response
+	}
+
+	code, first := upload()
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status %d for first upload, got %d: %v", http.StatusCreated, code, first)
+	}
+	firstDB := first["database"].(map[string]interface{})
+	firstPath := firstDB["file_path"].(string)
+	defer os.Remove(firstPath)
+
+	code, second := upload()
+	if code != http.StatusOK {
+		t.Fatalf("Expected status %d for repeated upload, got %d: %v", http.StatusOK, code, second)
+	}
+	if second["is_duplicate"] != true {
+		t.Errorf("Expected is_duplicate=true, got %v", second["is_duplicate"])
+	}
+	if second["database_id"] != firstDB["id"] {
+		t.Errorf("Expected database_id=%v, got %v", firstDB["id"], second["database_id"])
+	}
+	if second["file_path"] != firstPath {
+		t.Errorf("Expected existing file path %s, got %v", firstPath, second["file_path"])
+	}
+
+	databases, err := srv.serviceDB.GetProjectDatabases(projectID, false)
+	if err != nil {
+		t.Fatalf("Failed to get project databases: %v", err)
+	}
+	if len(databases) != 1 {
+		t.Errorf("Expected 1 project database after repeated upload, got %d", len(databases))
+	}
+
+	// Повторная копия файла не остается на диске
+	matches, _ := filepath.Glob(filepath.Join(filepath.Dir(firstPath), "*export*.db"))
+	for _, match := range matches {
+		if match != firstPath {
+			if content, err := os.ReadFile(match); err == nil && bytes.Equal(content, testFileContent) {
+				t.Errorf("Duplicate upload file was not removed: %s", match)
+			}
+		}
+	}
+}
+
 func TestHandleUploadProjectDatabase_MissingFile(t *testing.T) {
 	srv, cleanup := setupTestServer(t)
 	defer cleanup()
@@ -3,13 +3,24 @@ package handlers
 import (
 	"encoding/json"
 	"net/http"
-	"strconv"
 	"strings"
 
 	"httpserver/server/models"
 	"httpserver/server/services"
 )
 
+// BenchmarkListResponse ответ списка эталонов. Поле benchmarks дублирует items для клиентов прежнего формата
+type BenchmarkListResponse struct {
+	ListResponse[*models.Benchmark]
+	Benchmarks []*models.Benchmark `json:"benchmarks"`
+}
+
+// newBenchmarkListResponse формирует ответ списка эталонов из страницы, выбранной сервисом
+func newBenchmarkListResponse(response *models.BenchmarkListResponse, params PaginationParams) BenchmarkListResponse {
+	list := NewListResponse(response.Benchmarks, response.Total, params)
+	return BenchmarkListResponse{ListResponse: list, Benchmarks: list.Items}
+}
+
 // BenchmarkHandler обработчик для работы с эталонами
 type BenchmarkHandler struct {
 	benchmarkService *services.BenchmarkService
@@ -116,37 +127,27 @@ func (h *BenchmarkHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	params, err := ParsePaginationParams(r.URL.Query(), DefaultListLimit, MaxListLimit)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, NewValidationError(err.Error(), err))
+		return
+	}
+
 	entityType := r.URL.Query().Get("type")
 	activeOnlyStr := r.URL.Query().Get("active")
-	limitStr := r.URL.Query().Get("limit")
-	offsetStr := r.URL.Query().Get("offset")
 
 	activeOnly := true
 	if activeOnlyStr != "" {
 		activeOnly = strings.ToLower(activeOnlyStr) == "true" || activeOnlyStr == "1"
 	}
 
-	limit := 50
-	if limitStr != "" {
-		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
-			limit = parsed
-		}
-	}
-
-	offset := 0
-	if offsetStr != "" {
-		if parsed, err := strconv.Atoi(offsetStr); err == nil && parsed >= 0 {
-			offset = parsed
-		}
-	}
-
-	response, err := h.benchmarkService.List(entityType, activeOnly, limit, offset)
+	response, err := h.benchmarkService.List(entityType, activeOnly, params.Limit, params.Offset)
 	if err != nil {
 		h.baseHandler.HandleHTTPError(w, r, err)
 		return
 	}
 
-	h.baseHandler.WriteJSONResponse(w, r, response, http.StatusOK)
+	h.baseHandler.WriteJSONResponse(w, r, newBenchmarkListResponse(response, params), http.StatusOK)
 }
 
 // GetByID обрабатывает GET /api/benchmarks/:id
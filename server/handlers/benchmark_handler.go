@@ -119,7 +119,9 @@ func (h *BenchmarkAPIHandler) Search(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param type query string false "Entity type to filter by"
-// @Success 200 {array} models.Benchmark "List of benchmarks"
+// @Param limit query int false "Page size" default(50)
+// @Param offset query int false "Pagination offset" default(0)
+// @Success 200 {object} BenchmarkListResponse "List of benchmarks"
 // @Failure 500 {object} ErrorResponse "Failed to get benchmarks"
 // @Router /api/benchmarks [get]
 // List обрабатывает GET /api/benchmarks
@@ -129,29 +131,20 @@ func (h *BenchmarkAPIHandler) List(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	entityType := r.URL.Query().Get("type")
-
-	// Get all active benchmarks if no type provided
-	if entityType == "" {
-		// Call a method to get all active benchmarks
-		// Since there's no direct method for this, we'll use List with empty type
-		response, err := h.service.List("", true, 1000, 0)
-		if err != nil {
-			h.HandleHTTPError(w, r, err)
-			return
-		}
-		h.WriteJSONResponse(w, r, response.Benchmarks, http.StatusOK)
+	params, err := ParsePaginationParams(r.URL.Query(), DefaultListLimit, MaxListLimit)
+	if err != nil {
+		h.HandleHTTPError(w, r, NewValidationError(err.Error(), err))
 		return
 	}
 
-	// Get benchmarks by type
-	benchmarks, err := h.service.GetByType(entityType)
+	// Active benchmarks, optionally filtered by type (empty type lists all)
+	response, err := h.service.List(r.URL.Query().Get("type"), true, params.Limit, params.Offset)
 	if err != nil {
 		h.HandleHTTPError(w, r, err)
 		return
 	}
 
-	h.WriteJSONResponse(w, r, benchmarks, http.StatusOK)
+	h.WriteJSONResponse(w, r, newBenchmarkListResponse(response, params), http.StatusOK)
 }
 
 // @Summary Get benchmark by ID
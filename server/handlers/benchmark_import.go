@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "httpserver/server/errors"
+)
+
+// benchmarkImportMaxBodySize максимальный размер тела запроса импорта эталонов
+const benchmarkImportMaxBodySize = 50 << 20
+
+// HandleImportProjectBenchmarksGin импортирует эталоны проекта из NDJSON
+// @Summary Потоковый импорт эталонов проекта
+// @Description Принимает эталоны в формате NDJSON (один JSON объект на строку: original_name, normalized_name, category, subcategory, attributes, quality_score, source_database). Строки проверяются и сохраняются по мере чтения; эталон с тем же original_name и category обновляется. Ошибки отдельных строк возвращаются с номером строки.
+// @Tags clients
+// @Accept application/x-ndjson
+// @Produce json
+// @Param id path int true "ID проекта"
+// @Success 200 {object} services.BenchmarkImportSummary "Итог импорта"
+// @Failure 400 {object} ErrorResponse "Неверный ID проекта"
+// @Failure 404 {object} ErrorResponse "Проект не найден"
+// @Failure 413 {object} map[string]interface{} "Превышен размер тела запроса (итог импорта прочитанной части в summary)"
+// @Router /api/projects/{id}/benchmarks/import [post]
+func (h *ClientHandler) HandleImportProjectBenchmarksGin(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || projectID <= 0 {
+		appErr := apperrors.NewValidationError("неверный формат ID проекта", err)
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, benchmarkImportMaxBodySize)
+	summary, err := h.clientService.ImportProjectBenchmarks(c.Request.Context(), projectID, body)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			SendJSONResponse(c, http.StatusRequestEntityTooLarge, gin.H{
+				"error":   "размер тела запроса превышает допустимый",
+				"limit":   maxBytesErr.Limit,
+				"summary": summary,
+			})
+			return
+		}
+		appErr := apperrors.WrapError(err, "не удалось импортировать эталоны")
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	SendJSONResponse(c, http.StatusOK, summary)
+}
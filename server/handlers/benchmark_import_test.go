@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"httpserver/database"
+	"httpserver/server/services"
+)
+
+func setupBenchmarkImportRouter(t *testing.T) (http.Handler, *database.ServiceDB, int) {
+	t.Helper()
+
+	serviceDB, err := database.NewServiceDB(filepath.Join(t.TempDir(), "service.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service DB: %v", err)
+	}
+	t.Cleanup(func() { serviceDB.Close() })
+
+	client, err := serviceDB.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	clientService, err := services.NewClientService(serviceDB, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client service: %v", err)
+	}
+	handler := NewClientHandler(clientService, NewBaseHandlerFromMiddleware())
+
+	router := setupGinTestRouter()
+	router.POST("/api/projects/:id/benchmarks/import", handler.HandleImportProjectBenchmarksGin)
+	return router, serviceDB, project.ID
+}
+
+func TestHandleImportProjectBenchmarksGin(t *testing.T) {
+	router, serviceDB, projectID := setupBenchmarkImportRouter(t)
+
+	body := strings.Join([]string{
+		`{"original_name":"Болт М10","normalized_name":"болт м10","category":"nomenclature","quality_score":0.9}`,
+		`{"original_name":"Гайка М10","normalized_name":"гайка м10","category":"nomenclature"}`,
+		``,
+		`{"original_name":"Шайба","category":"nomenclature"}`,
+		`not json`,
+		`{"original_name":"Болт М10","normalized_name":"болт м10х1","category":"nomenclature"}`,
+	}, "\n")
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/projects/%d/benchmarks/import", projectID), strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var summary services.BenchmarkImportSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if summary.Lines != 5 || summary.Created != 2 || summary.Updated != 1 || summary.Truncated {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if len(summary.Errors) != 2 || summary.Errors[0].Line != 4 || summary.Errors[1].Line != 5 {
+		t.Errorf("Expected errors on lines 4 and 5, got %+v", summary.Errors)
+	}
+
+	benchmarks, err := serviceDB.GetClientBenchmarks(projectID, "nomenclature", false)
+	if err != nil {
+		t.Fatalf("GetClientBenchmarks failed: %v", err)
+	}
+	if len(benchmarks) != 2 {
+		t.Errorf("Expected 2 stored benchmarks, got %d", len(benchmarks))
+	}
+}
+
+func TestHandleImportProjectBenchmarksGin_LineTooLong(t *testing.T) {
+	router, _, projectID := setupBenchmarkImportRouter(t)
+
+	long := fmt.Sprintf(`{"original_name":"%s","normalized_name":"x","category":"nomenclature"}`, strings.Repeat("а", services.BenchmarkImportMaxLineSize))
+	body := `{"original_name":"Болт","normalized_name":"болт","category":"nomenclature"}` + "\n" + long + "\n"
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/projects/%d/benchmarks/import", projectID), strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var summary services.BenchmarkImportSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if summary.Created != 1 || !summary.Truncated || len(summary.Errors) != 1 || summary.Errors[0].Line != 2 {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+}
+
+func TestHandleImportProjectBenchmarksGin_UnknownProject(t *testing.T) {
+	router, _, _ := setupBenchmarkImportRouter(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/projects/9999/benchmarks/import", strings.NewReader(""))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d: %s", w.Code, w.Body.String())
+	}
+}
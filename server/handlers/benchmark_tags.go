@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	apperrors "httpserver/server/errors"
+)
+
+// benchmarkTagRequest тело запроса добавления тега эталона
+type benchmarkTagRequest struct {
+	Tag string `json:"tag"`
+}
+
+// HandleGetBenchmarkTagsGin возвращает теги эталона проекта
+// @Summary Получить теги эталона
+// @Description Возвращает теги эталона проекта клиента в алфавитном порядке
+// @Tags projects
+// @Produce json
+// @Param clientId path int true "ID клиента"
+// @Param projectId path int true "ID проекта"
+// @Param benchmarkId path int true "ID эталона"
+// @Success 200 {object} map[string]interface{} "Теги эталона (benchmark_id, tags)"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос"
+// @Failure 404 {object} ErrorResponse "Проект или эталон не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/clients/{clientId}/projects/{projectId}/benchmarks/{benchmarkId}/tags [get]
+func (h *ClientHandler) HandleGetBenchmarkTagsGin(c *gin.Context) {
+	clientID, projectID, benchmarkID, ok := parseBenchmarkTagParams(c)
+	if !ok {
+		return
+	}
+
+	tags, err := h.clientService.GetBenchmarkTags(c.Request.Context(), clientID, projectID, benchmarkID)
+	sendBenchmarkTags(c, benchmarkID, tags, err)
+}
+
+// HandleAddBenchmarkTagGin добавляет тег эталону проекта
+// @Summary Добавить тег эталону
+// @Description Добавляет тег эталону проекта клиента. Тег приводится к нижнему регистру; повторное добавление не является ошибкой.
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param clientId path int true "ID клиента"
+// @Param projectId path int true "ID проекта"
+// @Param benchmarkId path int true "ID эталона"
+// @Param payload body benchmarkTagRequest true "Тег"
+// @Success 200 {object} map[string]interface{} "Обновленные теги эталона (benchmark_id, tags)"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос"
+// @Failure 404 {object} ErrorResponse "Проект или эталон не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/clients/{clientId}/projects/{projectId}/benchmarks/{benchmarkId}/tags [post]
+func (h *ClientHandler) HandleAddBenchmarkTagGin(c *gin.Context) {
+	clientID, projectID, benchmarkID, ok := parseBenchmarkTagParams(c)
+	if !ok {
+		return
+	}
+
+	var req benchmarkTagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendJSONError(c, http.StatusBadRequest, "неверный формат тела запроса")
+		return
+	}
+
+	tags, err := h.clientService.AddBenchmarkTag(c.Request.Context(), clientID, projectID, benchmarkID, req.Tag)
+	sendBenchmarkTags(c, benchmarkID, tags, err)
+}
+
+// HandleRemoveBenchmarkTagGin удаляет тег эталона проекта
+// @Summary Удалить тег эталона
+// @Description Удаляет тег эталона проекта клиента. Удаление отсутствующего тега не является ошибкой.
+// @Tags projects
+// @Produce json
+// @Param clientId path int true "ID клиента"
+// @Param projectId path int true "ID проекта"
+// @Param benchmarkId path int true "ID эталона"
+// @Param tag path string true "Тег"
+// @Success 200 {object} map[string]interface{} "Обновленные теги эталона (benchmark_id, tags)"
+// @Failure 400 {object} ErrorResponse "Некорректный запрос"
+// @Failure 404 {object} ErrorResponse "Проект или эталон не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/clients/{clientId}/projects/{projectId}/benchmarks/{benchmarkId}/tags/{tag} [delete]
+func (h *ClientHandler) HandleRemoveBenchmarkTagGin(c *gin.Context) {
+	clientID, projectID, benchmarkID, ok := parseBenchmarkTagParams(c)
+	if !ok {
+		return
+	}
+
+	tags, err := h.clientService.RemoveBenchmarkTag(c.Request.Context(), clientID, projectID, benchmarkID, c.Param("tag"))
+	sendBenchmarkTags(c, benchmarkID, tags, err)
+}
+
+// parseBenchmarkTagParams разбирает ID клиента, проекта и эталона из пути; при ошибке отправляет 400
+func parseBenchmarkTagParams(c *gin.Context) (clientID, projectID, benchmarkID int, ok bool) {
+	params := []struct {
+		name   string
+		target *int
+		label  string
+	}{
+		{"clientId", &clientID, "клиента"},
+		{"projectId", &projectID, "проекта"},
+		{"benchmarkId", &benchmarkID, "эталона"},
+	}
+	for _, p := range params {
+		value, err := strconv.Atoi(c.Param(p.name))
+		if err != nil || value <= 0 {
+			appErr := apperrors.NewValidationError("неверный формат ID "+p.label, err)
+			SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+			return 0, 0, 0, false
+		}
+		*p.target = value
+	}
+	return clientID, projectID, benchmarkID, true
+}
+
+// sendBenchmarkTags отправляет теги эталона или ошибку сервиса
+func sendBenchmarkTags(c *gin.Context, benchmarkID int, tags []string, err error) {
+	if err != nil {
+		appErr := apperrors.WrapError(err, "не удалось обработать теги эталона")
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	SendJSONResponse(c, http.StatusOK, gin.H{
+		"benchmark_id": benchmarkID,
+		"tags":         tags,
+	})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"httpserver/database"
+	"httpserver/server/services"
+)
+
+func TestBenchmarkTagsHandlers(t *testing.T) {
+	serviceDB, err := database.NewServiceDB(filepath.Join(t.TempDir(), "service.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service DB: %v", err)
+	}
+	defer serviceDB.Close()
+
+	client, err := serviceDB.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	bolt, err := serviceDB.CreateClientBenchmark(project.ID, "Болт М10", "болт м10", "nomenclature", "", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("Failed to create benchmark: %v", err)
+	}
+	if _, err := serviceDB.CreateClientBenchmark(project.ID, "Гайка М10", "гайка м10", "nomenclature", "", "", "", 0.9); err != nil {
+		t.Fatalf("Failed to create benchmark: %v", err)
+	}
+
+	clientService, err := services.NewClientService(serviceDB, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client service: %v", err)
+	}
+	handler := NewClientHandler(clientService, NewBaseHandlerFromMiddleware())
+
+	router := setupGinTestRouter()
+	tagsPath := "/api/clients/:clientId/projects/:projectId/benchmarks/:benchmarkId/tags"
+	router.GET(tagsPath, handler.HandleGetBenchmarkTagsGin)
+	router.POST(tagsPath, handler.HandleAddBenchmarkTagGin)
+	router.DELETE(tagsPath+"/:tag", handler.HandleRemoveBenchmarkTagGin)
+	router.GET("/api/clients/:clientId/projects/:projectId/benchmarks", func(c *gin.Context) {
+		handler.GetProjectBenchmarks(c.Writer, c.Request, client.ID, project.ID)
+	})
+
+	do := func(method, path, body string) (int, []string) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp struct {
+			Tags []string `json:"tags"`
+		}
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return w.Code, resp.Tags
+	}
+
+	base := fmt.Sprintf("/api/clients/%d/projects/%d/benchmarks/%d/tags", client.ID, project.ID, bolt.ID)
+
+	code, tags := do(http.MethodPost, base, `{"tag":"Крепеж"}`)
+	if code != http.StatusOK || !reflect.DeepEqual(tags, []string{"крепеж"}) {
+		t.Fatalf("POST tag: got %d %v", code, tags)
+	}
+	if code, tags = do(http.MethodPost, base, `{"tag":"metric"}`); code != http.StatusOK || len(tags) != 2 {
+		t.Fatalf("POST second tag: got %d %v", code, tags)
+	}
+	if code, _ = do(http.MethodPost, base, `{"tag":"  "}`); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for empty tag, got %d", code)
+	}
+
+	// Фильтр по тегам в списке эталонов проекта
+	listPath := fmt.Sprintf("/api/clients/%d/projects/%d/benchmarks", client.ID, project.ID)
+	req := httptest.NewRequest(http.MethodGet, listPath+"?tags=крепеж,METRIC", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var list struct {
+		Items []map[string]interface{} `json:"items"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode list response: %v (%s)", err, w.Body.String())
+	}
+	if len(list.Items) != 1 || list.Items[0]["original_name"] != "Болт М10" {
+		t.Errorf("Expected only tagged benchmark in filtered list, got %v", list.Items)
+	}
+
+	if code, tags = do(http.MethodDelete, base+"/metric", ""); code != http.StatusOK || !reflect.DeepEqual(tags, []string{"крепеж"}) {
+		t.Errorf("DELETE tag: got %d %v", code, tags)
+	}
+	if code, tags = do(http.MethodGet, base, ""); code != http.StatusOK || !reflect.DeepEqual(tags, []string{"крепеж"}) {
+		t.Errorf("GET tags: got %d %v", code, tags)
+	}
+
+	// Эталон чужого проекта недоступен
+	otherProject, err := serviceDB.CreateClientProject(client.ID, "Другой проект", "nomenclature", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	foreign := fmt.Sprintf("/api/clients/%d/projects/%d/benchmarks/%d/tags", client.ID, otherProject.ID, bolt.ID)
+	if code, _ = do(http.MethodGet, foreign, ""); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for benchmark of another project, got %d", code)
+	}
+}
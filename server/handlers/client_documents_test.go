@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"httpserver/database"
+	"httpserver/server/services"
+)
+
+func TestClientDocumentsUploadAndDownload(t *testing.T) {
+	serviceDB, err := database.NewServiceDB(filepath.Join(t.TempDir(), "service.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service DB: %v", err)
+	}
+	defer serviceDB.Close()
+
+	client, err := serviceDB.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	clientService, err := services.NewClientService(serviceDB, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client service: %v", err)
+	}
+	handler := NewClientHandler(clientService, NewBaseHandlerFromMiddleware())
+	documentsDir := t.TempDir()
+	handler.SetDocumentsDir(documentsDir)
+
+	router := setupGinTestRouter()
+	router.POST("/api/clients/:clientId/documents", func(c *gin.Context) {
+		handler.HandleUploadClientDocument(c.Writer, c.Request, client.ID)
+	})
+	router.GET("/api/clients/:clientId/documents", func(c *gin.Context) {
+		handler.HandleGetClientDocuments(c.Writer, c.Request, client.ID)
+	})
+	router.GET("/api/clients/:clientId/documents/:docId", func(c *gin.Context) {
+		var docID int
+		fmt.Sscanf(c.Param("docId"), "%d", &docID)
+		handler.HandleDownloadClientDocument(c.Writer, c.Request, client.ID, docID)
+	})
+
+	upload := func(fileName, contentType string, content []byte) *httptest.ResponseRecorder {
+		t.Helper()
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		partHeader := textproto.MIMEHeader{}
+		partHeader.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, fileName))
+		if contentType != "" {
+			partHeader.Set("Content-Type", contentType)
+		}
+		part, err := writer.CreatePart(partHeader)
+		if err != nil {
+			t.Fatalf("Failed to create form part: %v", err)
+		}
+		part.Write(content)
+		writer.WriteField("category", "contract")
+		writer.Close()
+
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/clients/%d/documents", client.ID), &body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	content := []byte("%PDF-1.4 договор поставки")
+	w := upload("../договор поставки.pdf", "application/pdf", content)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected 201 on upload, got %d: %s", w.Code, w.Body.String())
+	}
+	var document database.ClientDocument
+	if err := json.Unmarshal(w.Body.Bytes(), &document); err != nil {
+		t.Fatalf("Failed to decode uploaded document: %v", err)
+	}
+	if document.FileType != "application/pdf" || document.FileSize != int64(len(content)) || document.Category != "contract" {
+		t.Errorf("Unexpected document metadata: %+v", document)
+	}
+	clientDir := filepath.Join(documentsDir, fmt.Sprintf("%d", client.ID))
+	if filepath.Dir(document.FilePath) != clientDir {
+		t.Errorf("Expected document stored in %s, got %s", clientDir, document.FilePath)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/clients/%d/documents", client.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var list struct {
+		Documents []database.ClientDocument `json:"documents"`
+		Total     int                       `json:"total"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("Failed to decode document list: %v", err)
+	}
+	if w.Code != http.StatusOK || list.Total != 1 || list.Documents[0].ID != document.ID {
+		t.Fatalf("Unexpected document list (%d): %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/clients/%d/documents/%d", client.ID, document.ID), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200 on download, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "application/pdf" {
+		t.Errorf("Expected stored content type, got %q", got)
+	}
+	if !bytes.Equal(w.Body.Bytes(), content) {
+		t.Errorf("Downloaded content differs: %q", w.Body.String())
+	}
+
+	if w := upload("setup.exe", "application/octet-stream", []byte("MZ")); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for disallowed extension, got %d", w.Code)
+	}
+	if w := upload("big.pdf", "application/pdf", bytes.Repeat([]byte("x"), int(clientDocumentMaxSize)+1)); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for oversized document, got %d", w.Code)
+	}
+
+	entries, _ := os.ReadDir(clientDir)
+	if len(entries) != 1 {
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		t.Errorf("Expected only the accepted document on disk, got %s", strings.Join(names, ", "))
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"mime"
 	"net/http"
 	"os"
 	"path/filepath"
@@ -59,7 +60,14 @@ type ClientHandler struct {
 	findMatchingProjectForDatabaseFunc func(serviceDB *database.ServiceDB, clientID int, filePath string) (*database.ClientProject, error)
 	parseDatabaseFileInfoFunc          func(fileName string) database.DatabaseFilenameInfo
 	// Опциональные handlers для вложенных маршрутов
-	normalizationHandler *NormalizationHandler // Handler для маршрутов нормализации
+	normalizationHandler *NormalizationHandler      // Handler для маршрутов нормализации
+	enrichmentRunner     *services.EnrichmentRunner // Обогащение эталонов контрагентов проекта
+	documentsDir         string                     // Каталог документов клиентов (пусто - DefaultClientDocumentsDir)
+}
+
+// SetDocumentsDir задает каталог, в подкаталогах которого хранятся документы клиентов
+func (h *ClientHandler) SetDocumentsDir(dir string) {
+	h.documentsDir = dir
 }
 
 // SetNormalizationHandler устанавливает normalizationHandler
@@ -67,6 +75,11 @@ func (h *ClientHandler) SetNormalizationHandler(handler *NormalizationHandler) {
 	h.normalizationHandler = handler
 }
 
+// SetEnrichmentRunner устанавливает runner обогащения эталонов проектов
+func (h *ClientHandler) SetEnrichmentRunner(runner *services.EnrichmentRunner) {
+	h.enrichmentRunner = runner
+}
+
 // NewClientHandler создает новый обработчик для работы с клиентами
 func NewClientHandler(
 	clientService *services.ClientService,
@@ -111,15 +124,29 @@ func (h *ClientHandler) HandleClients(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// GetClients возвращает список всех клиентов
+// ClientListResponse ответ списка клиентов.
+// Поле clients сохранено для клиентов, которые раньше получали массив (прокси фронтенда читает clients)
+type ClientListResponse struct {
+	ListResponse[*database.Client]
+	Clients []*database.Client `json:"clients"`
+}
+
+// GetClients возвращает список клиентов в стандартном конверте ListResponse
 func (h *ClientHandler) GetClients(w http.ResponseWriter, r *http.Request) {
+	params, err := ParsePaginationParams(r.URL.Query(), MaxListLimit, MaxListLimit)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, NewValidationError(err.Error(), err))
+		return
+	}
+
 	clients, err := h.clientService.GetAllClients(r.Context())
 	if err != nil {
 		h.baseHandler.HandleHTTPError(w, r, NewInternalError("не удалось получить список клиентов", err))
 		return
 	}
 
-	h.baseHandler.WriteJSONResponse(w, r, clients, http.StatusOK)
+	list := PaginateSlice(clients, params)
+	h.baseHandler.WriteJSONResponse(w, r, ClientListResponse{ListResponse: list, Clients: list.Items}, http.StatusOK)
 }
 
 // CreateClient создает нового клиента
@@ -173,6 +200,15 @@ func (h *ClientHandler) HandleClientRoutes(w http.ResponseWriter, r *http.Reques
 	if len(parts) > 1 {
 		switch parts[1] {
 		case "statistics":
+			// POST /api/clients/{id}/statistics/recompute
+			if len(parts) == 3 && parts[2] == "recompute" {
+				if r.Method == http.MethodPost {
+					h.RecomputeClientStats(w, r, clientID)
+					return
+				}
+				h.baseHandler.HandleMethodNotAllowed(w, r, http.MethodPost)
+				return
+			}
 			if r.Method == http.MethodGet {
 				h.GetClientStatistics(w, r, clientID)
 				return
@@ -315,6 +351,16 @@ func (h *ClientHandler) HandleClientRoutes(w http.ResponseWriter, r *http.Reques
 					return
 				}
 
+				// Обработка /api/clients/{id}/projects/{projectId}/databases/search
+				if len(parts) == 5 && parts[3] == "databases" && parts[4] == "search" {
+					if r.Method == http.MethodGet {
+						h.SearchProjectDatabases(w, r, clientID, projectID)
+						return
+					}
+					h.baseHandler.HandleMethodNotAllowed(w, r, http.MethodGet)
+					return
+				}
+
 				// Обработка /api/clients/{id}/projects/{projectId}/databases/{dbId}
 				if len(parts) == 5 && parts[3] == "databases" {
 					dbID, err := ValidateIntPathParam(parts[4], "database_id")
@@ -336,6 +382,22 @@ func (h *ClientHandler) HandleClientRoutes(w http.ResponseWriter, r *http.Reques
 					return
 				}
 
+				// Обработка /api/clients/{id}/projects/{projectId}/normalization-quality
+				if len(parts) == 4 && parts[3] == "normalization-quality" {
+					if r.Method == http.MethodGet {
+						h.GetNormalizationQuality(w, r, clientID, projectID)
+						return
+					}
+					h.baseHandler.HandleMethodNotAllowed(w, r, http.MethodGet)
+					return
+				}
+
+				// Обработка /api/clients/{id}/projects/{projectId}/enrichment/{status|start|stop}
+				if len(parts) == 5 && parts[3] == "enrichment" {
+					h.HandleProjectEnrichment(w, r, clientID, projectID, parts[4])
+					return
+				}
+
 				// Обработка вложенных маршрутов normalization
 				if len(parts) >= 4 && parts[3] == "normalization" {
 					if h.normalizationHandler != nil {
@@ -1355,12 +1417,22 @@ func (h *ClientHandler) AutoLinkClientDatabases(w http.ResponseWriter, r *http.R
 
 // GetProjectDatabases возвращает базы данных проекта
 func (h *ClientHandler) GetProjectDatabases(w http.ResponseWriter, r *http.Request, clientID, projectID int) {
-	databases, err := h.clientService.GetProjectDatabases(r.Context(), clientID, projectID)
+	params, err := ParsePaginationParams(r.URL.Query(), MaxListLimit, MaxListLimit)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, NewValidationError(err.Error(), err))
+		return
+	}
+
+	allDatabases, err := h.clientService.GetProjectDatabases(r.Context(), clientID, projectID)
 	if err != nil {
 		h.baseHandler.HandleHTTPError(w, r, NewInternalError("не удалось получить базы данных проекта", err))
 		return
 	}
 
+	// Статистику и метаданные собираем только для запрошенной страницы
+	page := PaginateSlice(allDatabases, params)
+	databases := page.Items
+
 	// Получаем статистику для всех баз данных одним batch-запросом (оптимизация N+1)
 	var statsMap map[int]map[string]interface{}
 	if h.databaseService != nil && h.databaseService.GetDB() != nil && len(databases) > 0 {
@@ -1430,12 +1502,149 @@ func (h *ClientHandler) GetProjectDatabases(w http.ResponseWriter, r *http.Reque
 		databasesWithStats = append(databasesWithStats, dbInfo)
 	}
 
+	h.baseHandler.WriteJSONResponse(w, r, struct {
+		ListResponse[map[string]interface{}]
+		Databases []map[string]interface{} `json:"databases"` // совместимость с клиентами прежнего формата
+	}{
+		ListResponse: NewListResponse(databasesWithStats, page.Total, params),
+		Databases:    databasesWithStats,
+	}, http.StatusOK)
+}
+
+// SearchProjectDatabases ищет запись по коду или названию во всех активных базах данных проекта
+// @Summary Поиск по базам данных проекта
+// @Description Ищет элементы справочников и номенклатуру по коду или названию во всех активных БД проекта
+// @Tags clients
+// @Produce json
+// @Param clientId path int true "ID клиента"
+// @Param projectId path int true "ID проекта"
+// @Param q query string true "Код или часть названия"
+// @Param limit query int false "Максимальное количество результатов" default(200)
+// @Success 200 {object} map[string]interface{} "Найденные записи с указанием БД"
+// @Failure 400 {object} ErrorResponse "Не задан параметр поиска"
+// @Failure 404 {object} ErrorResponse "Проект не найден"
+// @Router /api/clients/{clientId}/projects/{projectId}/databases/search [get]
+func (h *ClientHandler) SearchProjectDatabases(w http.ResponseWriter, r *http.Request, clientID, projectID int) {
+	params, err := ParsePaginationParams(r.URL.Query(), database.DefaultCrossDBSearchLimit, MaxListLimit)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, NewValidationError(err.Error(), err))
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	hits, err := h.clientService.SearchProjectDatabases(r.Context(), clientID, projectID, query, params.Limit)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, err)
+		return
+	}
+
 	h.baseHandler.WriteJSONResponse(w, r, map[string]interface{}{
-		"databases": databasesWithStats,
-		"total":     len(databasesWithStats),
+		"query": query,
+		"hits":  hits,
+		"total": len(hits),
+		"limit": params.Limit,
 	}, http.StatusOK)
 }
 
+// PreviewCounterpartyNormalization показывает результат нормализации названий контрагентов проекта
+// без записи в базу данных
+// @Summary Предпросмотр нормализации контрагентов
+// @Description Выполняет нормализацию названий и ОПФ контрагентов проекта в режиме dry run и возвращает сводку и выборку предлагаемых изменений
+// @Tags clients
+// @Produce json
+// @Param clientId path int true "ID клиента"
+// @Param projectId path int true "ID проекта"
+// @Param limit query int false "Количество предлагаемых изменений в выборке" default(50)
+// @Success 200 {object} services.CounterpartyNormalizationPreview "Сводка и выборка изменений"
+// @Failure 400 {object} ErrorResponse "Некорректные параметры"
+// @Failure 404 {object} ErrorResponse "Проект не найден"
+// @Router /api/clients/{clientId}/projects/{projectId}/normalization/preview [post]
+func (h *ClientHandler) PreviewCounterpartyNormalization(w http.ResponseWriter, r *http.Request, clientID, projectID int) {
+	params, err := ParsePaginationParams(r.URL.Query(), services.DefaultNormalizationPreviewSampleSize, MaxListLimit)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, NewValidationError(err.Error(), err))
+		return
+	}
+
+	preview, err := h.clientService.PreviewCounterpartyNormalization(r.Context(), clientID, projectID, params.Limit)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, err)
+		return
+	}
+
+	h.baseHandler.WriteJSONResponse(w, r, preview, http.StatusOK)
+}
+
+// GetNormalizationQuality возвращает количество исходных записей и различных нормализованных названий
+// эталонов проекта и крупнейшие группы совпадений (параметр category - фильтр по категории)
+func (h *ClientHandler) GetNormalizationQuality(w http.ResponseWriter, r *http.Request, clientID, projectID int) {
+	quality, err := h.clientService.GetNormalizationQuality(r.Context(), clientID, projectID, r.URL.Query().Get("category"))
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, err)
+		return
+	}
+
+	h.baseHandler.WriteJSONResponse(w, r, quality, http.StatusOK)
+}
+
+// HandleProjectEnrichment обрабатывает запросы к обогащению эталонов контрагентов проекта:
+// GET status - состояние и контрольная точка, POST start - запуск или продолжение, POST stop - остановка
+func (h *ClientHandler) HandleProjectEnrichment(w http.ResponseWriter, r *http.Request, clientID, projectID int, action string) {
+	if h.enrichmentRunner == nil {
+		h.baseHandler.WriteJSONError(w, r, "Enrichment is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	wantMethod := http.MethodPost
+	if action == "status" {
+		wantMethod = http.MethodGet
+	}
+	if action != "status" && action != "start" && action != "stop" {
+		h.baseHandler.HandleHTTPError(w, r, NewNotFoundError("Unknown enrichment action", nil))
+		return
+	}
+	if r.Method != wantMethod {
+		h.baseHandler.HandleMethodNotAllowed(w, r, wantMethod)
+		return
+	}
+
+	// Проверяем принадлежность проекта клиенту
+	project, err := h.clientService.GetClientProject(r.Context(), clientID, projectID)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, err)
+		return
+	}
+	if project.ClientID != clientID {
+		h.baseHandler.HandleHTTPError(w, r, NewNotFoundError("Project not found", nil))
+		return
+	}
+
+	switch action {
+	case "start":
+		if err := h.enrichmentRunner.Start(projectID); err != nil {
+			h.baseHandler.HandleHTTPError(w, r, err)
+			return
+		}
+	case "stop":
+		if !h.enrichmentRunner.Stop(projectID) {
+			h.baseHandler.WriteJSONError(w, r, "Enrichment is not running", http.StatusConflict)
+			return
+		}
+	}
+
+	status, err := h.enrichmentRunner.Status(projectID)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, err)
+		return
+	}
+
+	httpStatus := http.StatusOK
+	if action == "start" {
+		httpStatus = http.StatusAccepted
+	}
+	h.baseHandler.WriteJSONResponse(w, r, status, httpStatus)
+}
+
 // GetProjectDatabase возвращает базу данных проекта
 func (h *ClientHandler) GetProjectDatabase(w http.ResponseWriter, r *http.Request, clientID, projectID, dbID int) {
 	projectDB, err := h.clientService.GetProjectDatabase(r.Context(), clientID, projectID, dbID)
@@ -1658,6 +1867,26 @@ func (h *ClientHandler) DeleteProjectDatabase(w http.ResponseWriter, r *http.Req
 	h.baseHandler.WriteJSONResponse(w, r, map[string]interface{}{"message": "Database deleted successfully"}, http.StatusOK)
 }
 
+// RecomputeClientStats пересчитывает кэшированную статистику клиента
+// @Summary Пересчитать статистику клиента
+// @Description Пересчитывает кэшированные project_count, benchmark_count и last_activity клиента
+// @Tags clients
+// @Produce json
+// @Param clientId path int true "ID клиента"
+// @Success 200 {object} database.ClientStats "Статистика клиента"
+// @Failure 404 {object} ErrorResponse "Клиент не найден"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/clients/{clientId}/statistics/recompute [post]
+func (h *ClientHandler) RecomputeClientStats(w http.ResponseWriter, r *http.Request, clientID int) {
+	stats, err := h.clientService.RecomputeClientStats(r.Context(), clientID)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, err)
+		return
+	}
+
+	h.baseHandler.WriteJSONResponse(w, r, stats, http.StatusOK)
+}
+
 // GetClientStatistics получает расширенную статистику клиента
 // @Summary Получить статистику клиента
 // @Description Возвращает расширенную статистику по клиенту: проекты, базы данных, эталоны, номенклатуру, контрагенты и т.д.
@@ -2321,13 +2550,17 @@ func (h *ClientHandler) CreateProjectDatabase(w http.ResponseWriter, r *http.Req
 	}
 
 	// Создаем базу данных через сервис
-	database, err := h.clientService.CreateProjectDatabase(r.Context(), clientID, projectID, req.Name, filePath, req.Description, fileSize)
+	projectDB, err := h.clientService.CreateProjectDatabase(r.Context(), clientID, projectID, req.Name, filePath, req.Description, fileSize)
 	if err != nil {
+		if errors.Is(err, database.ErrDuplicate) {
+			h.baseHandler.HandleHTTPError(w, r, err)
+			return
+		}
 		h.baseHandler.HandleHTTPError(w, r, NewInternalError("не удалось создать базу данных", err))
 		return
 	}
 
-	h.baseHandler.WriteJSONResponse(w, r, database, http.StatusCreated)
+	h.baseHandler.WriteJSONResponse(w, r, projectDB, http.StatusCreated)
 }
 
 // GetProjectBenchmarks получает эталоны проекта
@@ -2339,7 +2572,11 @@ func (h *ClientHandler) CreateProjectDatabase(w http.ResponseWriter, r *http.Req
 // @Param projectId path int true "ID проекта"
 // @Param category query string false "Фильтр по категории"
 // @Param approved_only query bool false "Только одобренные эталоны" default(false)
-// @Success 200 {object} map[string]interface{} "Список эталонов"
+// @Param review_status query string false "Статусы согласования через запятую (draft, pending, approved, rejected)"
+// @Param tags query string false "Теги через запятую (эталон должен иметь все теги)"
+// @Param limit query int false "Количество записей на странице" default(1000)
+// @Param offset query int false "Смещение для пагинации" default(0)
+// @Success 200 {object} map[string]interface{} "Список эталонов (items, total, limit, offset; benchmarks - для совместимости)"
 // @Failure 400 {object} ErrorResponse "Некорректный запрос"
 // @Failure 404 {object} ErrorResponse "Проект не найден"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
@@ -2366,25 +2603,76 @@ func (h *ClientHandler) GetProjectBenchmarks(w http.ResponseWriter, r *http.Requ
 	}
 
 	// Получаем параметры запроса
+	params, err := ParsePaginationParams(r.URL.Query(), MaxListLimit, MaxListLimit)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, NewValidationError(err.Error(), err))
+		return
+	}
 	category := r.URL.Query().Get("category")
 	approvedOnly := r.URL.Query().Get("approved_only") == "true"
 
-	log.Printf("[GetProjectBenchmarks] Getting benchmarks for project %d, client %d, category: %s, approvedOnly: %v",
-		projectID, clientID, category, approvedOnly)
+	var reviewStatuses []string
+	if reviewStatusParam := r.URL.Query().Get("review_status"); reviewStatusParam != "" {
+		for _, status := range strings.Split(reviewStatusParam, ",") {
+			status = strings.TrimSpace(status)
+			if !database.IsValidBenchmarkReviewStatus(status) {
+				h.baseHandler.HandleHTTPError(w, r, NewValidationError(fmt.Sprintf("Неизвестный статус согласования: %s", status), nil))
+				return
+			}
+			reviewStatuses = append(reviewStatuses, status)
+		}
+	}
+
+	var tags []string
+	if tagsParam := r.URL.Query().Get("tags"); tagsParam != "" {
+		for _, tag := range strings.Split(tagsParam, ",") {
+			tag, err := database.NormalizeBenchmarkTag(tag)
+			if err != nil {
+				h.baseHandler.HandleHTTPError(w, r, NewValidationError("Некорректный тег в параметре tags", err))
+				return
+			}
+			tags = append(tags, tag)
+		}
+	}
+
+	log.Printf("[GetProjectBenchmarks] Getting benchmarks for project %d, client %d, category: %s, approvedOnly: %v, reviewStatuses: %v, tags: %v",
+		projectID, clientID, category, approvedOnly, reviewStatuses, tags)
 
 	// Получаем эталоны из БД
-	benchmarks, err := serviceDB.GetClientBenchmarks(projectID, category, approvedOnly)
+	allBenchmarks, err := serviceDB.SearchBenchmarks(projectID, database.BenchmarkSearchFilter{
+		Category:       category,
+		ApprovedOnly:   approvedOnly,
+		ReviewStatuses: reviewStatuses,
+		Tags:           tags,
+	})
 	if err != nil {
 		log.Printf("[GetProjectBenchmarks] Error getting benchmarks: %v", err)
 		h.baseHandler.HandleHTTPError(w, r, NewInternalError("не удалось получить эталоны проекта", err))
 		return
 	}
 
-	log.Printf("[GetProjectBenchmarks] Found %d benchmarks for project %d", len(benchmarks), projectID)
+	log.Printf("[GetProjectBenchmarks] Found %d benchmarks for project %d", len(allBenchmarks), projectID)
+
+	page := PaginateSlice(allBenchmarks, params)
+	benchmarks := page.Items
+
+	benchmarkIDs := make([]int, len(benchmarks))
+	for i, b := range benchmarks {
+		benchmarkIDs[i] = b.ID
+	}
+	benchmarkTags, err := serviceDB.GetBenchmarkTagsBatch(benchmarkIDs)
+	if err != nil {
+		h.baseHandler.HandleHTTPError(w, r, NewInternalError("не удалось получить теги эталонов", err))
+		return
+	}
 
 	// Формируем ответ
 	responseBenchmarks := make([]map[string]interface{}, len(benchmarks))
 	for i, b := range benchmarks {
+		bTags := benchmarkTags[b.ID]
+		if bTags == nil {
+			bTags = []string{}
+		}
 		responseBenchmarks[i] = map[string]interface{}{
 			"id":                b.ID,
 			"client_project_id": b.ClientProjectID,
@@ -2404,6 +2692,9 @@ func (h *ClientHandler) GetProjectBenchmarks(w http.ResponseWriter, r *http.Requ
 			}(),
 			"source_database": b.SourceDatabase,
 			"usage_count":     b.UsageCount,
+			"review_status":   b.ReviewStatus,
+			"review_notes":    b.ReviewNotes,
+			"tags":            bTags,
 			"created_at":      b.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			"updated_at":      b.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		}
@@ -2411,9 +2702,12 @@ func (h *ClientHandler) GetProjectBenchmarks(w http.ResponseWriter, r *http.Requ
 
 	log.Printf("[GetProjectBenchmarks] Returning %d benchmarks to client", len(responseBenchmarks))
 
-	h.baseHandler.WriteJSONResponse(w, r, map[string]interface{}{
-		"benchmarks": responseBenchmarks,
-		"total":      len(responseBenchmarks),
+	h.baseHandler.WriteJSONResponse(w, r, struct {
+		ListResponse[map[string]interface{}]
+		Benchmarks []map[string]interface{} `json:"benchmarks"` // совместимость с клиентами прежнего формата
+	}{
+		ListResponse: NewListResponse(responseBenchmarks, page.Total, params),
+		Benchmarks:   responseBenchmarks,
 	}, http.StatusOK)
 }
 
@@ -2742,7 +3036,20 @@ func (h *ClientHandler) UpdateAllDatabasesMetadata(w http.ResponseWriter, r *htt
 	h.baseHandler.WriteJSONResponse(w, r, response, statusCode)
 }
 
-// HandleUploadClientDocument обрабатывает загрузку документа клиента
+// DefaultClientDocumentsDir каталог документов клиентов: файлы клиента лежат в подкаталоге с его ID
+var DefaultClientDocumentsDir = filepath.Join("data", "client_documents")
+
+// clientDocumentMaxSize максимальный размер загружаемого документа клиента
+const clientDocumentMaxSize int64 = 20 << 20
+
+// clientDocumentExtensions допустимые расширения документов клиента
+var clientDocumentExtensions = []string{
+	".pdf", ".doc", ".docx", ".xls", ".xlsx", ".csv", ".txt", ".rtf", ".odt", ".ods",
+	".png", ".jpg", ".jpeg", ".zip",
+}
+
+// HandleUploadClientDocument обрабатывает загрузку документа клиента.
+// Размер файла ограничен clientDocumentMaxSize, расширение - clientDocumentExtensions.
 // POST /api/clients/{clientId}/documents
 func (h *ClientHandler) HandleUploadClientDocument(w http.ResponseWriter, r *http.Request, clientID int) {
 	if r.Method != http.MethodPost {
@@ -2758,9 +3065,15 @@ func (h *ClientHandler) HandleUploadClientDocument(w http.ResponseWriter, r *htt
 		return
 	}
 
-	// Парсим multipart form
-	err = r.ParseMultipartForm(100 << 20) // 100 MB
+	// Парсим multipart form; запас 1 MB на остальные поля формы
+	r.Body = http.MaxBytesReader(w, r.Body, clientDocumentMaxSize+(1<<20))
+	err = r.ParseMultipartForm(32 << 20)
 	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			h.baseHandler.WriteJSONError(w, r, fmt.Sprintf("Размер документа превышает %d байт", clientDocumentMaxSize), http.StatusRequestEntityTooLarge)
+			return
+		}
 		h.baseHandler.HandleHTTPError(w, r, NewValidationError("Failed to parse multipart form", err))
 		return
 	}
@@ -2772,6 +3085,20 @@ func (h *ClientHandler) HandleUploadClientDocument(w http.ResponseWriter, r *htt
 	}
 	defer file.Close()
 
+	validator := NewFileValidator(clientDocumentExtensions, clientDocumentMaxSize, 1)
+	if err := validator.ValidateExtension(header.Filename); err != nil {
+		h.baseHandler.HandleHTTPError(w, r, err)
+		return
+	}
+	if err := validator.ValidateSize(header.Size); err != nil {
+		if header.Size > clientDocumentMaxSize {
+			h.baseHandler.WriteJSONError(w, r, fmt.Sprintf("Размер документа превышает %d байт", clientDocumentMaxSize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		h.baseHandler.HandleHTTPError(w, r, err)
+		return
+	}
+
 	category := r.FormValue("category")
 	if category == "" {
 		category = "technical"
@@ -2784,34 +3111,39 @@ func (h *ClientHandler) HandleUploadClientDocument(w http.ResponseWriter, r *htt
 	}
 
 	// Создаем директорию для документов клиента
-	documentsDir := filepath.Join("data", "client_documents", fmt.Sprintf("%d", clientID))
+	baseDir := h.documentsDir
+	if baseDir == "" {
+		baseDir = DefaultClientDocumentsDir
+	}
+	documentsDir := filepath.Join(baseDir, fmt.Sprintf("%d", clientID))
 	if err := os.MkdirAll(documentsDir, 0755); err != nil {
 		h.baseHandler.HandleHTTPError(w, r, NewInternalError("Failed to create documents directory", err))
 		return
 	}
 
-	// Генерируем безопасное имя файла
+	// Генерируем безопасное имя файла: имя из запроса не должно выводить за каталог клиента
 	timestamp := time.Now().Format("20060102_150405")
-	safeName := strings.ReplaceAll(header.Filename, " ", "_")
-	fileName := fmt.Sprintf("%s_%s", timestamp, safeName)
-	filePath := filepath.Join(documentsDir, fileName)
-
-	// Сохраняем файл
-	dst, err := os.Create(filePath)
+	safeName := strings.ReplaceAll(SanitizeFilename(header.Filename), " ", "_")
+	dst, err := os.CreateTemp(documentsDir, fmt.Sprintf("%s_*_%s", timestamp, safeName))
 	if err != nil {
 		h.baseHandler.HandleHTTPError(w, r, NewInternalError("Failed to create file", err))
 		return
 	}
+	filePath := dst.Name()
 	defer dst.Close()
 
 	written, err := io.Copy(dst, file)
 	if err != nil {
+		os.Remove(filePath)
 		h.baseHandler.HandleHTTPError(w, r, NewInternalError("Failed to save file", err))
 		return
 	}
 
-	// Определяем тип файла
+	// Определяем тип файла: заголовок части формы, затем расширение
 	fileType := header.Header.Get("Content-Type")
+	if fileType == "" || fileType == "application/octet-stream" {
+		fileType = mime.TypeByExtension(strings.ToLower(filepath.Ext(safeName)))
+	}
 	if fileType == "" {
 		fileType = "application/octet-stream"
 	}
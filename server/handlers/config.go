@@ -244,6 +244,9 @@ func (h *ConfigHandler) HandleGetConfigSafe(w http.ResponseWriter, r *http.Reque
 		AITimeout                  string                     `json:"ai_timeout"`
 		Enrichment                 *config.EnrichmentConfig   `json:"enrichment"`
 		WebSearch                  *config.WebSearchConfig    `json:"web_search"`
+		GostSourcePriority         []string                   `json:"gost_source_priority"`
+		MetricsCleanupBatchSize    int                        `json:"metrics_cleanup_batch_size"`
+		Stopwords                  []string                   `json:"stopwords"`
 		HasArliaiAPIKey            bool                       `json:"has_arliai_api_key"`
 	}{
 		Port:                       cfg.Port,
@@ -262,6 +265,9 @@ func (h *ConfigHandler) HandleGetConfigSafe(w http.ResponseWriter, r *http.Reque
 		AITimeout:                  cfg.AITimeout.String(),
 		Enrichment:                 cfg.Enrichment,
 		WebSearch:                  cfg.WebSearch,
+		GostSourcePriority:         cfg.GostSourcePriority,
+		MetricsCleanupBatchSize:    cfg.MetricsCleanupBatchSize,
+		Stopwords:                  cfg.Stopwords,
 		HasArliaiAPIKey:            cfg.ArliaiAPIKey != "",
 	}
 
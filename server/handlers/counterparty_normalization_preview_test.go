@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"httpserver/database"
+	"httpserver/server/services"
+)
+
+func TestPreviewCounterpartyNormalization_NoWrites(t *testing.T) {
+	serviceDB, err := database.NewServiceDB(filepath.Join(t.TempDir(), "service.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service DB: %v", err)
+	}
+	defer serviceDB.Close()
+
+	client, err := serviceDB.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Контрагенты", "counterparty", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	for i, name := range []string{`ООО "Ромашка"`, `Лютик АО`, `Василек`} {
+		err := serviceDB.SaveNormalizedCounterparty(project.ID, "ref_"+name, name, name,
+			fmt.Sprintf("77070838%d0", i), "", "", "", "", "", "", "", "", "", "", "", "",
+			0, 0.5, false, "", "", "")
+		if err != nil {
+			t.Fatalf("Failed to save counterparty %q: %v", name, err)
+		}
+	}
+
+	snapshot := func() []string {
+		t.Helper()
+		rows, err := serviceDB.Query(`
+			SELECT id, normalized_name, COALESCE(legal_form, ''), updated_at
+			FROM normalized_counterparties ORDER BY id
+		`)
+		if err != nil {
+			t.Fatalf("Failed to read counterparties: %v", err)
+		}
+		defer rows.Close()
+		var state []string
+		for rows.Next() {
+			var id int
+			var name, form, updatedAt string
+			if err := rows.Scan(&id, &name, &form, &updatedAt); err != nil {
+				t.Fatalf("Failed to scan counterparty: %v", err)
+			}
+			state = append(state, fmt.Sprintf("%d|%s|%s|%s", id, name, form, updatedAt))
+		}
+		return state
+	}
+	before := snapshot()
+
+	clientService, err := services.NewClientService(serviceDB, nil, nil)
+	if err != nil {
+		t.Fatalf("Failed to create client service: %v", err)
+	}
+	handler := NewClientHandler(clientService, NewBaseHandlerFromMiddleware())
+
+	preview := func(clientID, projectID int, query string) *httptest.ResponseRecorder {
+		t.Helper()
+		url := fmt.Sprintf("/api/clients/%d/projects/%d/normalization/preview%s", clientID, projectID, query)
+		w := httptest.NewRecorder()
+		handler.PreviewCounterpartyNormalization(w, httptest.NewRequest(http.MethodPost, url, nil), clientID, projectID)
+		return w
+	}
+
+	w := preview(client.ID, project.ID, "?limit=1")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var result services.CounterpartyNormalizationPreview
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !result.Summary.DryRun || result.Summary.TotalRecords != 3 || result.Summary.UpdatedRecords != 2 || result.Summary.AppliedUpdates != 0 {
+		t.Errorf("Unexpected summary %+v", result.Summary)
+	}
+	if result.TotalChanges != 2 || len(result.Changes) != 1 {
+		t.Errorf("Expected a sample of 1 out of 2 changes, got %d of %d", len(result.Changes), result.TotalChanges)
+	}
+	if len(result.Changes) == 1 && (result.Changes[0].NewName != "Ромашка" || result.Changes[0].NewLegalForm != "ООО") {
+		t.Errorf("Unexpected proposed change %+v", result.Changes[0])
+	}
+
+	if after := snapshot(); !reflect.DeepEqual(before, after) {
+		t.Errorf("Preview modified counterparties:\nbefore %v\nafter  %v", before, after)
+	}
+
+	// Проект другого клиента не найден
+	if w := preview(client.ID+1, project.ID, ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for project of another client, got %d", w.Code)
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"httpserver/database"
+	"httpserver/server/services"
+)
+
+func TestHandleRepairDatabaseGin(t *testing.T) {
+	tempDir := t.TempDir()
+
+	serviceDB, err := database.NewServiceDB(filepath.Join(tempDir, "service.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service DB: %v", err)
+	}
+	defer serviceDB.Close()
+
+	client, err := serviceDB.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Проект", "nomenclature", "", "1C", 0.9)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	// Исходная база с выгрузкой, не привязанной к проекту
+	sourcePath := filepath.Join(tempDir, "source.db")
+	sourceDB, err := database.NewDB(sourcePath)
+	if err != nil {
+		t.Fatalf("Failed to create source DB: %v", err)
+	}
+	upload, err := sourceDB.CreateUpload("repair-uuid", "8.3", "TestConfig")
+	if err != nil {
+		t.Fatalf("Failed to create upload: %v", err)
+	}
+	catalog, err := sourceDB.AddCatalog(upload.ID, "Номенклатура", "nomenclature")
+	if err != nil {
+		t.Fatalf("Failed to create catalog: %v", err)
+	}
+	if err := sourceDB.AddCatalogItem(catalog.ID, "ref1", "code1", "Болт М10", "", ""); err != nil {
+		t.Fatalf("Failed to add catalog item: %v", err)
+	}
+	sourceDB.Close()
+
+	projectDB, err := serviceDB.CreateProjectDatabase(project.ID, "source", sourcePath, "", 0)
+	if err != nil {
+		t.Fatalf("Failed to create project database: %v", err)
+	}
+	missingDB, err := serviceDB.CreateProjectDatabase(project.ID, "missing", filepath.Join(tempDir, "missing.db"), "", 0)
+	if err != nil {
+		t.Fatalf("Failed to create project database: %v", err)
+	}
+
+	handler := NewDatabaseHandler(services.NewDatabaseService(serviceDB, nil, nil, "", "", nil), NewBaseHandlerFromMiddleware())
+	router := setupGinTestRouter()
+	router.POST("/api/databases/orphaned-uploads/repair", handler.HandleRepairOrphanedUploadsGin)
+	router.POST("/api/databases/:id/repair", handler.HandleRepairDatabaseGin)
+
+	repair := func(id int) (int, database.UploadRecordsRepairResult) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/api/databases/%d/repair", id), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var result database.UploadRecordsRepairResult
+		if w.Code == http.StatusOK {
+			if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+		}
+		return w.Code, result
+	}
+
+	code, result := repair(projectDB.ID)
+	if code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", code)
+	}
+	if len(result.Updated) != 1 || result.Updated[0] != upload.ID {
+		t.Errorf("Expected upload %d to be updated, got %v", upload.ID, result.Updated)
+	}
+	if len(result.Created) != 1 || len(result.Failed) != 0 {
+		t.Errorf("Expected one created upload and no failures, got %+v", result)
+	}
+
+	sourceDB, err = database.NewDB(sourcePath)
+	if err != nil {
+		t.Fatalf("Failed to reopen source DB: %v", err)
+	}
+	defer sourceDB.Close()
+	uploads, err := sourceDB.GetAllUploads()
+	if err != nil {
+		t.Fatalf("GetAllUploads failed: %v", err)
+	}
+	if len(uploads) != 2 {
+		t.Fatalf("Expected original and created uploads, got %d", len(uploads))
+	}
+	for _, u := range uploads {
+		if u.ClientID == nil || *u.ClientID != client.ID || u.ProjectID == nil || *u.ProjectID != project.ID {
+			t.Errorf("Upload %d is not linked to the project", u.ID)
+		}
+	}
+	if _, total, err := sourceDB.GetCatalogItemsByUpload(upload.ID, nil, 0, 10); err != nil || total != 1 {
+		t.Errorf("Expected catalog items to be kept, got %d (%v)", total, err)
+	}
+
+	// Повторный запуск ничего не меняет
+	if code, result = repair(projectDB.ID); code != http.StatusOK || len(result.Created)+len(result.Updated) != 0 {
+		t.Errorf("Expected no changes on second repair, got %d %+v", code, result)
+	}
+
+	// Отсутствующий файл не создается
+	if code, _ = repair(missingDB.ID); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for missing database file, got %d", code)
+	}
+	if _, err := os.Stat(missingDB.FilePath); !os.IsNotExist(err) {
+		t.Errorf("Repair must not create missing database file")
+	}
+
+	if code, _ = repair(9999); code != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown database, got %d", code)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,11 +32,12 @@ func NewDatabaseHandler(
 	}
 }
 
-// DatabaseListResponse структура ответа для списка баз данных
+// DatabaseListResponse структура ответа для списка баз данных.
+// Поле databases дублирует items для клиентов прежнего формата
 type DatabaseListResponse struct {
-	Databases         []interface{}            `json:"databases"`
-	Total             int                      `json:"total"`
-	AggregatedStats   map[string]interface{}   `json:"aggregated_stats,omitempty"`
+	ListResponse[interface{}]
+	Databases       []interface{}          `json:"databases"`
+	AggregatedStats map[string]interface{} `json:"aggregated_stats,omitempty"`
 }
 
 // DatabaseInfoResponse структура ответа для информации о базе данных
@@ -55,10 +57,18 @@ type ErrorResponse struct {
 // @Tags databases
 // @Accept json
 // @Produce json
+// @Param limit query int false "Количество записей на странице" default(1000)
+// @Param offset query int false "Смещение для пагинации" default(0)
 // @Success 200 {object} DatabaseListResponse "Список баз данных"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/databases/list [get]
 func (h *DatabaseHandler) HandleDatabasesListGin(c *gin.Context) {
+	params, err := ParsePaginationParams(c.Request.URL.Query(), MaxListLimit, MaxListLimit)
+	if err != nil {
+		SendJSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	databases, err := h.databaseService.ListDatabases()
 	if err != nil {
 		appErr := apperrors.WrapError(err, "не удалось получить список баз данных")
@@ -135,9 +145,10 @@ func (h *DatabaseHandler) HandleDatabasesListGin(c *gin.Context) {
 		// Игнорируем ошибки получения агрегированной статистики - это не критично
 	}
 
+	list := PaginateSlice(databasesInterface, params)
 	SendJSONResponse(c, http.StatusOK, DatabaseListResponse{
-		Databases:       databasesInterface,
-		Total:           len(databasesInterface),
+		ListResponse:    list,
+		Databases:       list.Items,
 		AggregatedStats: aggregatedStats,
 	})
 }
@@ -184,6 +195,8 @@ func (h *DatabaseHandler) HandleDatabaseInfoGin(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param q query string true "Поисковый запрос"
+// @Param limit query int false "Количество записей на странице" default(1000)
+// @Param offset query int false "Смещение для пагинации" default(0)
 // @Success 200 {object} DatabaseListResponse "Найденные базы данных"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
@@ -195,6 +208,12 @@ func (h *DatabaseHandler) HandleFindDatabaseGin(c *gin.Context) {
 		return
 	}
 
+	params, err := ParsePaginationParams(c.Request.URL.Query(), MaxListLimit, MaxListLimit)
+	if err != nil {
+		SendJSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	databases, err := h.databaseService.FindDatabase(query)
 	if err != nil {
 		appErr := apperrors.WrapError(err, "не удалось найти базы данных")
@@ -259,9 +278,10 @@ func (h *DatabaseHandler) HandleFindDatabaseGin(c *gin.Context) {
 		}
 	}
 
+	list := PaginateSlice(databasesInterface, params)
 	SendJSONResponse(c, http.StatusOK, DatabaseListResponse{
-		Databases:       databasesInterface,
-		Total:           len(databasesInterface),
+		ListResponse:    list,
+		Databases:       list.Items,
 		AggregatedStats: aggregatedStats,
 	})
 }
@@ -326,6 +346,145 @@ func (h *DatabaseHandler) HandlePendingDatabasesGin(c *gin.Context) {
 	SendJSONResponse(c, http.StatusOK, databasesResponse)
 }
 
+// HandleOrphanedUploadsGin обработчик поиска выгрузок с висячими ссылками для Gin
+// @Summary Найти выгрузки с висячими ссылками
+// @Description Возвращает выгрузки, у которых database_id, client_id или project_id ссылаются на удаленные записи
+// @Tags databases
+// @Produce json
+// @Success 200 {array} database.OrphanInfo "Выгрузки с висячими ссылками"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/databases/orphaned-uploads [get]
+func (h *DatabaseHandler) HandleOrphanedUploadsGin(c *gin.Context) {
+	orphans, err := h.databaseService.FindOrphanedUploads()
+	if err != nil {
+		appErr := apperrors.WrapError(err, "не удалось найти выгрузки с висячими ссылками")
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	SendJSONResponse(c, http.StatusOK, orphans)
+}
+
+// HandleRepairOrphanedUploadsGin обработчик исправления выгрузок с висячими ссылками для Gin
+// @Summary Исправить выгрузки с висячими ссылками
+// @Description Обнуляет висячие database_id/client_id/project_id; при reassign=true client_id и project_id восстанавливаются по базе данных выгрузки
+// @Tags databases
+// @Produce json
+// @Param reassign query bool false "Восстанавливать client_id/project_id по базе данных выгрузки" default(false)
+// @Success 200 {object} database.OrphanRepairResult "Результат исправления"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/databases/orphaned-uploads/repair [post]
+func (h *DatabaseHandler) HandleRepairOrphanedUploadsGin(c *gin.Context) {
+	reassign := c.Query("reassign") == "true"
+
+	result, err := h.databaseService.RepairOrphanedUploads(reassign)
+	if err != nil {
+		appErr := apperrors.WrapError(err, "не удалось исправить выгрузки с висячими ссылками")
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	SendJSONResponse(c, http.StatusOK, result)
+}
+
+// HandleRepairDatabaseGin обработчик восстановления цепочки данных базы проекта для Gin
+// @Summary Восстановить выгрузки базы данных
+// @Description Создает или привязывает к клиенту и проекту upload записи в файле базы данных проекта, чтобы ее данные находились по проекту. Только исправляет: выгрузки и данные не удаляются, отсутствующий файл не создается.
+// @Tags databases
+// @Produce json
+// @Param id path int true "ID базы данных проекта"
+// @Success 200 {object} database.UploadRecordsRepairResult "Созданные и обновленные выгрузки"
+// @Failure 400 {object} ErrorResponse "Неверный ID базы данных"
+// @Failure 404 {object} ErrorResponse "База данных или ее файл не найдены"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/databases/{id}/repair [post]
+func (h *DatabaseHandler) HandleRepairDatabaseGin(c *gin.Context) {
+	databaseID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || databaseID <= 0 {
+		SendJSONError(c, http.StatusBadRequest, "Неверный ID базы данных")
+		return
+	}
+
+	result, err := h.databaseService.RepairProjectDatabase(databaseID)
+	if err != nil {
+		appErr := apperrors.WrapError(err, "не удалось восстановить выгрузки базы данных")
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	SendJSONResponse(c, http.StatusOK, result)
+}
+
+// HandleStartBackupJobGin обработчик запуска фонового создания резервной копии для Gin
+// @Summary Запустить создание резервной копии
+// @Description Запускает создание резервной копии в фоне; прогресс доступен через GET /api/databases/backup/job
+// @Tags databases
+// @Accept json
+// @Produce json
+// @Param request body services.BackupJobRequest false "Параметры резервной копии"
+// @Success 202 {object} services.BackupJobStatus "Резервная копия создается"
+// @Failure 409 {object} ErrorResponse "Резервная копия уже создается"
+// @Router /api/databases/backup/job [post]
+func (h *DatabaseHandler) HandleStartBackupJobGin(c *gin.Context) {
+	req := services.BackupJobRequest{
+		IncludeMain:    true,
+		IncludeUploads: true,
+		Format:         "both",
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			SendJSONError(c, http.StatusBadRequest, "Неверный формат запроса")
+			return
+		}
+	}
+
+	status, err := h.databaseService.StartBackupJob(req)
+	if err != nil {
+		appErr := apperrors.WrapError(err, "не удалось запустить создание резервной копии")
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	SendJSONResponse(c, http.StatusAccepted, status)
+}
+
+// HandleBackupJobStatusGin обработчик получения прогресса создания резервной копии для Gin
+// @Summary Прогресс создания резервной копии
+// @Description Возвращает состояние и прогресс (файлы и байты) последнего фонового создания резервной копии
+// @Tags databases
+// @Produce json
+// @Success 200 {object} services.BackupJobStatus "Состояние резервной копии"
+// @Failure 404 {object} ErrorResponse "Резервная копия не создавалась"
+// @Router /api/databases/backup/job [get]
+func (h *DatabaseHandler) HandleBackupJobStatusGin(c *gin.Context) {
+	status, err := h.databaseService.GetBackupJobStatus()
+	if err != nil {
+		appErr := apperrors.WrapError(err, "не удалось получить состояние резервной копии")
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	SendJSONResponse(c, http.StatusOK, status)
+}
+
+// HandleCancelBackupJobGin обработчик отмены создания резервной копии для Gin
+// @Summary Отменить создание резервной копии
+// @Description Прерывает фоновое создание резервной копии; недописанный архив удаляется
+// @Tags databases
+// @Produce json
+// @Success 200 {object} map[string]interface{} "Отмена запрошена"
+// @Failure 409 {object} ErrorResponse "Резервная копия не создается"
+// @Router /api/databases/backup/job/cancel [post]
+func (h *DatabaseHandler) HandleCancelBackupJobGin(c *gin.Context) {
+	if err := h.databaseService.CancelBackupJob(); err != nil {
+		appErr := apperrors.WrapError(err, "не удалось отменить создание резервной копии")
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	SendJSONResponse(c, http.StatusOK, gin.H{"cancelled": true})
+}
+
 // HandleListBackups обрабатывает запросы к /api/backups
 func (h *DatabaseHandler) HandleListBackups(w http.ResponseWriter, r *http.Request) {
 	backupDir := "data/backups"
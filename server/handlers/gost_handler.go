@@ -28,6 +28,18 @@ func NewGostHandler(gostService *services.GostService) *GostHandler {
 	}
 }
 
+// GostListResponse ответ списка ГОСТов. Поле gosts дублирует items для клиентов прежнего формата
+type GostListResponse struct {
+	ListResponse[map[string]interface{}]
+	Gosts []map[string]interface{} `json:"gosts"`
+}
+
+// newGostListResponse формирует ответ списка ГОСТов
+func newGostListResponse(gosts []map[string]interface{}, total int, params PaginationParams) GostListResponse {
+	list := NewListResponse(gosts, total, params)
+	return GostListResponse{ListResponse: list, Gosts: list.Items}
+}
+
 // HandleGetGosts обработчик получения списка ГОСТов
 // @Summary Получить список ГОСТов
 // @Description Возвращает список ГОСТов с фильтрацией и пагинацией
@@ -43,13 +55,18 @@ func NewGostHandler(gostService *services.GostService) *GostHandler {
 // @Param adoption_to query string false "Дата принятия по (ГГГГ-ММ-ДД)"
 // @Param effective_from query string false "Дата вступления с (ГГГГ-ММ-ДД)"
 // @Param effective_to query string false "Дата вступления по (ГГГГ-ММ-ДД)"
-// @Success 200 {object} map[string]interface{} "Список ГОСТов"
+// @Param effective_as_of query string false "ГОСТы, действовавшие на дату (ГГГГ-ММ-ДД); не сочетается с другими фильтрами"
+// @Success 200 {object} GostListResponse "Список ГОСТов"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/gosts [get]
 func (h *GostHandler) HandleGetGosts(c *gin.Context) {
 	// Парсим параметры запроса
-	limit := 50
-	offset := 0
+	params, err := ParsePaginationParams(c.Request.URL.Query(), DefaultListLimit, MaxListLimit)
+	if err != nil {
+		SendJSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
 	status := c.Query("status")
 	sourceType := c.Query("source_type")
 	search := c.Query("search")
@@ -58,16 +75,27 @@ func (h *GostHandler) HandleGetGosts(c *gin.Context) {
 	effectiveFrom := c.Query("effective_from")
 	effectiveTo := c.Query("effective_to")
 
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
+	if effectiveAsOf := c.Query("effective_as_of"); effectiveAsOf != "" {
+		date, err := time.Parse("2006-01-02", effectiveAsOf)
+		if err != nil {
+			SendJSONError(c, http.StatusBadRequest, "Неверный формат даты для effective_as_of. Используйте формат ГГГГ-ММ-ДД")
+			return
+		}
+		if status != "" || sourceType != "" || search != "" ||
+			adoptionFrom != "" || adoptionTo != "" || effectiveFrom != "" || effectiveTo != "" {
+			SendJSONError(c, http.StatusBadRequest, "Параметр effective_as_of не сочетается с другими фильтрами")
+			return
 		}
-	}
 
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
+		gosts, total, err := h.gostService.GetEffectiveGosts(date, params.Limit, params.Offset)
+		if err != nil {
+			appErr := apperrors.WrapError(err, "не удалось получить список действующих ГОСТов")
+			SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+			return
 		}
+
+		SendJSONResponse(c, http.StatusOK, newGostListResponse(gosts, total, params))
+		return
 	}
 
 	dateParams := []struct {
@@ -90,9 +118,9 @@ func (h *GostHandler) HandleGetGosts(c *gin.Context) {
 		}
 	}
 
-	result, err := h.gostService.GetGosts(
-		limit,
-		offset,
+	gosts, total, err := h.gostService.GetGosts(
+		params.Limit,
+		params.Offset,
 		status,
 		sourceType,
 		search,
@@ -107,12 +135,12 @@ func (h *GostHandler) HandleGetGosts(c *gin.Context) {
 		return
 	}
 
-	SendJSONResponse(c, http.StatusOK, result)
+	SendJSONResponse(c, http.StatusOK, newGostListResponse(gosts, total, params))
 }
 
 // HandleGetGostDetail обработчик получения детальной информации о ГОСТе
 // @Summary Получить детальную информацию о ГОСТе
-// @Description Возвращает детальную информацию о ГОСТе по ID
+// @Description Возвращает детальную информацию о ГОСТе по ID, включая другие редакции того же номера (related_editions)
 // @Tags gosts
 // @Accept json
 // @Produce json
@@ -148,7 +176,7 @@ func (h *GostHandler) HandleGetGostDetail(c *gin.Context) {
 // @Param q query string true "Поисковый запрос"
 // @Param limit query int false "Количество записей на странице" default(50)
 // @Param offset query int false "Смещение для пагинации" default(0)
-// @Success 200 {object} map[string]interface{} "Результаты поиска"
+// @Success 200 {object} GostListResponse "Результаты поиска"
 // @Failure 400 {object} ErrorResponse "Неверный запрос"
 // @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
 // @Router /api/gosts/search [get]
@@ -159,24 +187,15 @@ func (h *GostHandler) HandleSearchGosts(c *gin.Context) {
 		return
 	}
 
-	limit := 50
-	offset := 0
-
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
-			limit = parsedLimit
-		}
-	}
-
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		if parsedOffset, err := strconv.Atoi(offsetStr); err == nil && parsedOffset >= 0 {
-			offset = parsedOffset
-		}
+	params, err := ParsePaginationParams(c.Request.URL.Query(), DefaultListLimit, MaxListLimit)
+	if err != nil {
+		SendJSONError(c, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	result, err := h.gostService.GetGosts(
-		limit,
-		offset,
+	gosts, total, err := h.gostService.GetGosts(
+		params.Limit,
+		params.Offset,
 		c.Query("status"),
 		c.Query("source_type"),
 		query,
@@ -191,6 +210,79 @@ func (h *GostHandler) HandleSearchGosts(c *gin.Context) {
 		return
 	}
 
+	SendJSONResponse(c, http.StatusOK, newGostListResponse(gosts, total, params))
+}
+
+// HandleSuggestGosts обработчик подсказок ГОСТов по произвольному тексту
+// @Summary Подсказки ГОСТов по тексту
+// @Description Возвращает ГОСТы, наиболее похожие на текстовый запрос (с учетом морфологии), с оценкой релевантности
+// @Tags gosts
+// @Accept json
+// @Produce json
+// @Param q query string true "Текстовый запрос (например, свариваемость сталей)"
+// @Param limit query int false "Максимальное количество подсказок" default(10)
+// @Success 200 {object} map[string]interface{} "Подсказки ГОСТов"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/gosts/suggest [get]
+func (h *GostHandler) HandleSuggestGosts(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		SendJSONError(c, http.StatusBadRequest, "Параметр 'q' обязателен")
+		return
+	}
+
+	limit := 10
+	if limitStr := c.Query("limit"); limitStr != "" {
+		if parsedLimit, err := strconv.Atoi(limitStr); err == nil && parsedLimit > 0 {
+			limit = parsedLimit
+		}
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	result, err := h.gostService.SuggestGosts(query, limit)
+	if err != nil {
+		appErr := apperrors.WrapError(err, "не удалось подобрать ГОСТы")
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	SendJSONResponse(c, http.StatusOK, result)
+}
+
+// HandleExtractGosts обработчик извлечения номеров ГОСТов из текста
+// @Summary Извлечь ГОСТы из текста
+// @Description Находит упоминания ГОСТов в произвольном тексте (ГОСТ, ГОСТ Р, ГОСТ ISO) и сопоставляет их с базой ГОСТов
+// @Tags gosts
+// @Accept json
+// @Produce json
+// @Param request body object true "Текст для анализа: {\"text\": \"...\"}"
+// @Success 200 {object} map[string]interface{} "Найденные номера ГОСТов"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/gosts/extract [post]
+func (h *GostHandler) HandleExtractGosts(c *gin.Context) {
+	var req struct {
+		Text string `json:"text"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		SendJSONError(c, http.StatusBadRequest, "Неверный формат запроса")
+		return
+	}
+	if strings.TrimSpace(req.Text) == "" {
+		SendJSONError(c, http.StatusBadRequest, "Поле 'text' обязательно")
+		return
+	}
+
+	result, err := h.gostService.ExtractGosts(req.Text)
+	if err != nil {
+		appErr := apperrors.WrapError(err, "не удалось извлечь ГОСТы")
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
 	SendJSONResponse(c, http.StatusOK, result)
 }
 
@@ -298,6 +390,26 @@ func (h *GostHandler) HandleGetStatistics(c *gin.Context) {
 	SendJSONResponse(c, http.StatusOK, stats)
 }
 
+// HandleGetQualityReport обработчик отчета о качестве данных ГОСТов
+// @Summary Получить отчет о качестве данных ГОСТов
+// @Description Возвращает количество ГОСТов без названия и дат, с неверным номером, испорченной кодировкой и противоречием статуса датам
+// @Tags gosts
+// @Accept json
+// @Produce json
+// @Success 200 {object} database.GostQualityReport "Отчет о качестве"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/gosts/quality [get]
+func (h *GostHandler) HandleGetQualityReport(c *gin.Context) {
+	report, err := h.gostService.GetQualityReport()
+	if err != nil {
+		appErr := apperrors.WrapError(err, "не удалось получить отчет о качестве")
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	SendJSONResponse(c, http.StatusOK, report)
+}
+
 // HandleUploadDocument обработчик загрузки документа ГОСТа
 // @Summary Загрузить документ для ГОСТа
 // @Description Загружает полный текст ГОСТа (PDF/Word)
@@ -324,6 +324,7 @@ func (h *NormalizationHandler) HandleNormalizationStatus(w http.ResponseWriter,
 		CurrentStep: "Не запущено",
 		Logs:        []string{},
 	}
+	response.PhaseTimings = status.PhaseTimings
 
 	if status.IsRunning {
 		response.CurrentStep = "Выполняется нормализация..."
@@ -1872,9 +1873,11 @@ func (h *NormalizationHandler) HandleExport(w http.ResponseWriter, r *http.Reque
 // @Tags normalization
 // @Accept json
 // @Produce json
+// @Param project_id query int false "ID проекта: собственная конфигурация проекта с откатом на глобальную"
 // @Param config body object false "Конфигурация нормализации (для PUT/POST)"
 // @Success 200 {object} map[string]interface{} "Конфигурация или сообщение об успехе"
 // @Failure 400 {object} ErrorResponse "Некорректные данные запроса"
+// @Failure 404 {object} ErrorResponse "Проект не найден"
 // @Failure 405 {object} ErrorResponse "Метод не поддерживается"
 // @Failure 503 {object} ErrorResponse "Сервис недоступен"
 // @Router /api/normalization/config [get]
@@ -1886,23 +1889,40 @@ func (h *NormalizationHandler) HandleNormalizationConfig(w http.ResponseWriter,
 		return
 	}
 
+	// Необязательный project_id выбирает конфигурацию проекта; без него используется глобальная
+	projectID := 0
+	if projectIDStr := r.URL.Query().Get("project_id"); projectIDStr != "" {
+		var err error
+		if projectID, err = strconv.Atoi(projectIDStr); err != nil || projectID <= 0 {
+			h.baseHandler.WriteJSONError(w, r, "Invalid project_id", http.StatusBadRequest)
+			return
+		}
+	}
+	getConfig := func() (*database.NormalizationConfig, error) {
+		if projectID > 0 {
+			return h.normalizationService.GetNormalizationConfigForProject(projectID)
+		}
+		return h.normalizationService.GetNormalizationConfig()
+	}
+
 	if r.Method == http.MethodGet {
 		// Получение конфигурации
-		config, err := h.normalizationService.GetNormalizationConfig()
+		config, err := getConfig()
 		if err != nil {
 			h.baseHandler.WriteJSONError(w, r, fmt.Sprintf("Failed to get normalization config: %v", err), http.StatusInternalServerError)
 			return
 		}
 
 		response := map[string]interface{}{
-			"id":               config.ID,
-			"database_path":    config.DatabasePath,
-			"source_table":     config.SourceTable,
-			"reference_column": config.ReferenceColumn,
-			"code_column":      config.CodeColumn,
-			"name_column":      config.NameColumn,
-			"created_at":       config.CreatedAt.Format(time.RFC3339),
-			"updated_at":       config.UpdatedAt.Format(time.RFC3339),
+			"id":                config.ID,
+			"client_project_id": config.ClientProjectID,
+			"database_path":     config.DatabasePath,
+			"source_table":      config.SourceTable,
+			"reference_column":  config.ReferenceColumn,
+			"code_column":       config.CodeColumn,
+			"name_column":       config.NameColumn,
+			"created_at":        config.CreatedAt.Format(time.RFC3339),
+			"updated_at":        config.UpdatedAt.Format(time.RFC3339),
 		}
 		h.baseHandler.WriteJSONResponse(w, r, response, http.StatusOK)
 	} else if r.Method == http.MethodPut || r.Method == http.MethodPost {
@@ -1939,20 +1959,37 @@ func (h *NormalizationHandler) HandleNormalizationConfig(w http.ResponseWriter,
 		}
 
 		// Обновляем конфигурацию
-		err := h.normalizationService.UpdateNormalizationConfig(
-			config.DatabasePath,
-			config.SourceTable,
-			config.ReferenceColumn,
-			config.CodeColumn,
-			config.NameColumn,
-		)
+		var err error
+		if projectID > 0 {
+			err = h.normalizationService.UpdateNormalizationConfigForProject(
+				projectID,
+				config.DatabasePath,
+				config.SourceTable,
+				config.ReferenceColumn,
+				config.CodeColumn,
+				config.NameColumn,
+			)
+		} else {
+			err = h.normalizationService.UpdateNormalizationConfig(
+				config.DatabasePath,
+				config.SourceTable,
+				config.ReferenceColumn,
+				config.CodeColumn,
+				config.NameColumn,
+			)
+		}
 		if err != nil {
+			var appErr *apperrors.AppError
+			if errors.As(err, &appErr) {
+				h.baseHandler.WriteJSONError(w, r, appErr.Message, appErr.StatusCode())
+				return
+			}
 			h.baseHandler.WriteJSONError(w, r, fmt.Sprintf("Failed to update normalization config: %v", err), http.StatusInternalServerError)
 			return
 		}
 
 		// Получаем обновленную конфигурацию для ответа
-		updatedConfig, err := h.normalizationService.GetNormalizationConfig()
+		updatedConfig, err := getConfig()
 		if err != nil {
 			// Если не удалось получить обновленную конфигурацию, возвращаем успех с полученными данными
 			response := map[string]interface{}{
@@ -1966,14 +2003,15 @@ func (h *NormalizationHandler) HandleNormalizationConfig(w http.ResponseWriter,
 		response := map[string]interface{}{
 			"message": "Configuration updated successfully",
 			"config": map[string]interface{}{
-				"id":               updatedConfig.ID,
-				"database_path":    updatedConfig.DatabasePath,
-				"source_table":     updatedConfig.SourceTable,
-				"reference_column": updatedConfig.ReferenceColumn,
-				"code_column":      updatedConfig.CodeColumn,
-				"name_column":      updatedConfig.NameColumn,
-				"created_at":       updatedConfig.CreatedAt.Format(time.RFC3339),
-				"updated_at":       updatedConfig.UpdatedAt.Format(time.RFC3339),
+				"id":                updatedConfig.ID,
+				"client_project_id": updatedConfig.ClientProjectID,
+				"database_path":     updatedConfig.DatabasePath,
+				"source_table":      updatedConfig.SourceTable,
+				"reference_column":  updatedConfig.ReferenceColumn,
+				"code_column":       updatedConfig.CodeColumn,
+				"name_column":       updatedConfig.NameColumn,
+				"created_at":        updatedConfig.CreatedAt.Format(time.RFC3339),
+				"updated_at":        updatedConfig.UpdatedAt.Format(time.RFC3339),
 			},
 		}
 		h.baseHandler.WriteJSONResponse(w, r, response, http.StatusOK)
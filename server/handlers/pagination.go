@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
+
+// Параметры пагинации списковых эндпоинтов по умолчанию
+const (
+	DefaultListLimit = 50
+	MaxListLimit     = 1000
+)
+
+// PaginationParams параметры пагинации списка
+type PaginationParams struct {
+	Limit  int
+	Offset int
+}
+
+// ListResponse стандартный конверт ответа списковых эндпоинтов.
+// Total - общее количество записей без учета limit/offset,
+// HasMore - за текущей страницей есть еще записи (ответ усечен по limit).
+type ListResponse[T any] struct {
+	Items   []T  `json:"items"`
+	Total   int  `json:"total"`
+	Limit   int  `json:"limit"`
+	Offset  int  `json:"offset"`
+	HasMore bool `json:"has_more"`
+}
+
+// NewListResponse создает конверт для уже выбранной страницы записей
+func NewListResponse[T any](items []T, total int, params PaginationParams) ListResponse[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return ListResponse[T]{
+		Items:   items,
+		Total:   total,
+		Limit:   params.Limit,
+		Offset:  params.Offset,
+		HasMore: params.Offset+len(items) < total,
+	}
+}
+
+// PaginateSlice выбирает страницу из полного списка записей (для источников без limit/offset)
+func PaginateSlice[T any](items []T, params PaginationParams) ListResponse[T] {
+	total := len(items)
+	start := params.Offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if params.Limit > 0 && start+params.Limit < total {
+		end = start + params.Limit
+	}
+	return NewListResponse(items[start:end], total, params)
+}
+
+// ParsePaginationParams разбирает limit, offset и page из query string.
+// Отсутствующий или неположительный limit заменяется на defaultLimit, превышающий maxLimit - ограничивается им,
+// отрицательный offset заменяется на 0. Если offset не задан, он вычисляется из page (нумерация с 1).
+// Нечисловые значения возвращают ValidationError.
+func ParsePaginationParams(query url.Values, defaultLimit, maxLimit int) (PaginationParams, error) {
+	if defaultLimit <= 0 {
+		defaultLimit = DefaultListLimit
+	}
+	if maxLimit <= 0 {
+		maxLimit = MaxListLimit
+	}
+	if defaultLimit > maxLimit {
+		defaultLimit = maxLimit
+	}
+
+	params := PaginationParams{Limit: defaultLimit}
+
+	limit, err := parseOptionalInt(query, "limit")
+	if err != nil {
+		return PaginationParams{}, err
+	}
+	if limit != nil && *limit > 0 {
+		params.Limit = *limit
+	}
+	if params.Limit > maxLimit {
+		params.Limit = maxLimit
+	}
+
+	offset, err := parseOptionalInt(query, "offset")
+	if err != nil {
+		return PaginationParams{}, err
+	}
+	if offset != nil {
+		if *offset > 0 {
+			params.Offset = *offset
+		}
+		return params, nil
+	}
+
+	page, err := parseOptionalInt(query, "page")
+	if err != nil {
+		return PaginationParams{}, err
+	}
+	if page != nil && *page > 1 {
+		params.Offset = (*page - 1) * params.Limit
+	}
+
+	return params, nil
+}
+
+// parseOptionalInt возвращает nil, если параметр не задан
+func parseOptionalInt(query url.Values, paramName string) (*int, error) {
+	valueStr := query.Get(paramName)
+	if valueStr == "" {
+		return nil, nil
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		return nil, &ValidationError{
+			Field:   paramName,
+			Message: fmt.Sprintf("must be a valid integer, got: %s", valueStr),
+		}
+	}
+	return &value, nil
+}
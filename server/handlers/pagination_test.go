@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+)
+
+func TestParsePaginationParams(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantLimit  int
+		wantOffset int
+		wantErr    bool
+	}{
+		{name: "defaults", query: "", wantLimit: 20, wantOffset: 0},
+		{name: "explicit values", query: "limit=10&offset=30", wantLimit: 10, wantOffset: 30},
+		{name: "limit capped", query: "limit=5000", wantLimit: 100, wantOffset: 0},
+		{name: "non-positive limit", query: "limit=0", wantLimit: 20, wantOffset: 0},
+		{name: "negative offset", query: "offset=-5", wantLimit: 20, wantOffset: 0},
+		{name: "page to offset", query: "limit=10&page=3", wantLimit: 10, wantOffset: 20},
+		{name: "offset wins over page", query: "offset=5&page=3", wantLimit: 20, wantOffset: 5},
+		{name: "invalid limit", query: "limit=abc", wantErr: true},
+		{name: "invalid offset", query: "offset=1.5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			params, err := ParsePaginationParams(query, 20, 100)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error, got %+v", params)
+				}
+				if _, ok := err.(*ValidationError); !ok {
+					t.Errorf("Expected *ValidationError, got %T", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if params.Limit != tt.wantLimit || params.Offset != tt.wantOffset {
+				t.Errorf("got limit=%d offset=%d, want limit=%d offset=%d",
+					params.Limit, params.Offset, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestPaginateSlice(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	page := PaginateSlice(items, PaginationParams{Limit: 2, Offset: 2})
+	if page.Total != 5 || page.Limit != 2 || page.Offset != 2 {
+		t.Errorf("Unexpected envelope: %+v", page)
+	}
+	if len(page.Items) != 2 || page.Items[0] != 3 || page.Items[1] != 4 {
+		t.Errorf("Unexpected items: %v", page.Items)
+	}
+	if !page.HasMore {
+		t.Error("Expected has_more for a truncated page")
+	}
+
+	last := PaginateSlice(items, PaginationParams{Limit: 2, Offset: 4})
+	if last.HasMore || len(last.Items) != 1 {
+		t.Errorf("Expected the last page without has_more, got %+v", last)
+	}
+
+	// Смещение за пределами списка дает пустую страницу, а не null
+	empty := PaginateSlice(items, PaginationParams{Limit: 2, Offset: 10})
+	data, err := json.Marshal(empty)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `{"items":[],"total":5,"limit":2,"offset":10,"has_more":false}` {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+
+	nilPage := NewListResponse[int](nil, 0, PaginationParams{Limit: 10})
+	if nilPage.Items == nil {
+		t.Error("Expected nil items to be replaced with empty slice")
+	}
+}
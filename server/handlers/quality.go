@@ -38,9 +38,10 @@ type QualityHandler struct {
 	logFunc                 func(entry interface{}) // types.LogEntry, но без прямого импорта
 	normalizedDB            *database.DB
 	currentNormalizedDBPath string
-	generateQualityReport   func(string) (interface{}, error)                                         // Функция для генерации отчета
-	getProjectDatabases     func(projectID int, activeOnly bool) ([]*database.ProjectDatabase, error) // Функция для получения баз проекта
-	projectStatsCache       *ProjectQualityStatsCache                                                 // Кэш для статистики проектов
+	generateQualityReport   func(string) (interface{}, error)                                              // Функция для генерации отчета
+	getProjectDatabases     func(projectID int, activeOnly bool) ([]*database.ProjectDatabase, error)      // Функция для получения баз проекта
+	projectStatsCache       *ProjectQualityStatsCache                                                      // Кэш для статистики проектов
+	getQualityMetrics       func(databaseIDs []int, since time.Time) ([]database.DataQualityMetric, error) // Функция для получения метрик качества баз из основной БД
 	// Поля для отслеживания статуса анализа
 	qualityAnalysisRunning bool
 	qualityAnalysisMutex   sync.RWMutex
@@ -77,6 +78,12 @@ func (h *QualityHandler) SetGetProjectDatabases(fn func(projectID int, activeOnl
 	h.getProjectDatabases = fn
 }
 
+// SetGetQualityMetrics устанавливает функцию для получения метрик качества баз данных.
+// Метрики хранятся в основной БД (data_quality_metrics), базы проекта берутся через getProjectDatabases.
+func (h *QualityHandler) SetGetQualityMetrics(fn func(databaseIDs []int, since time.Time) ([]database.DataQualityMetric, error)) {
+	h.getQualityMetrics = fn
+}
+
 // SetProjectStatsCache устанавливает кэш для статистики проектов
 func (h *QualityHandler) SetProjectStatsCache(cache *ProjectQualityStatsCache) {
 	h.projectStatsCache = cache
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"httpserver/database"
+	apperrors "httpserver/server/errors"
+)
+
+// qualityMetricsPeriods допустимые значения параметра period
+// (пустое значение и "year" соответствуют периоду по умолчанию в один год)
+var qualityMetricsPeriods = map[string]bool{
+	"day":   true,
+	"week":  true,
+	"month": true,
+	"year":  true,
+}
+
+// ProjectQualityMetricsResponse структура ответа со списком метрик качества проекта
+type ProjectQualityMetricsResponse struct {
+	ProjectID int                          `json:"project_id"`
+	Period    string                       `json:"period"`
+	Category  string                       `json:"category,omitempty"`
+	Metrics   []database.DataQualityMetric `json:"metrics"`
+	Total     int                          `json:"total"`
+	Breached  int                          `json:"breached"`
+	Summary   map[string]int               `json:"summary"`
+}
+
+// HandleProjectQualityMetricsGin обработчик получения метрик качества проекта для Gin.
+// Метрики читаются из основной БД через getQualityMetrics, а не через ServiceDB.GetQualityMetricsForProject:
+// таблица data_quality_metrics есть только в основной БД, поэтому тот метод помечен устаревшим.
+// @Summary Получить метрики качества проекта
+// @Description Возвращает метрики качества данных проекта с порогами и статусами, а также сводку по статусам
+// @Tags quality
+// @Accept json
+// @Produce json
+// @Param id path int true "ID проекта"
+// @Param period query string false "Период: day, week, month, year (по умолчанию year)"
+// @Param category query string false "Категория метрик (например, completeness)"
+// @Param breached query bool false "Вернуть только метрики, нарушившие пороги"
+// @Success 200 {object} ProjectQualityMetricsResponse "Метрики качества проекта"
+// @Failure 400 {object} ErrorResponse "Неверный запрос"
+// @Failure 500 {object} ErrorResponse "Внутренняя ошибка сервера"
+// @Router /api/projects/{id}/quality-metrics [get]
+func (h *QualityHandler) HandleProjectQualityMetricsGin(c *gin.Context) {
+	projectID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || projectID <= 0 {
+		appErr := apperrors.NewValidationError("неверный формат ID проекта", err)
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	period := strings.ToLower(strings.TrimSpace(c.Query("period")))
+	if period == "" {
+		period = "year"
+	}
+	if !qualityMetricsPeriods[period] {
+		appErr := apperrors.NewValidationError("неверный период, допустимые значения: day, week, month, year", nil)
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	onlyBreached := false
+	if breachedParam := c.Query("breached"); breachedParam != "" {
+		onlyBreached, err = strconv.ParseBool(breachedParam)
+		if err != nil {
+			appErr := apperrors.NewValidationError("неверный формат параметра breached", err)
+			SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+			return
+		}
+	}
+
+	if h.getProjectDatabases == nil || h.getQualityMetrics == nil {
+		appErr := apperrors.NewInternalError("источник метрик качества не настроен", nil)
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	projectDatabases, err := h.getProjectDatabases(projectID, false)
+	if err != nil {
+		appErr := apperrors.NewInternalError("не удалось получить базы данных проекта", err)
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+	databaseIDs := make([]int, 0, len(projectDatabases))
+	for _, projectDB := range projectDatabases {
+		databaseIDs = append(databaseIDs, projectDB.ID)
+	}
+
+	metrics, err := h.getQualityMetrics(databaseIDs, database.QualityMetricsPeriodStart(period, time.Now()))
+	if err != nil {
+		appErr := apperrors.NewInternalError("не удалось получить метрики качества проекта", err)
+		SendJSONError(c, appErr.StatusCode(), appErr.UserMessage())
+		return
+	}
+
+	category := strings.TrimSpace(c.Query("category"))
+	if category != "" {
+		filtered := make([]database.DataQualityMetric, 0, len(metrics))
+		for _, metric := range metrics {
+			if strings.EqualFold(metric.MetricCategory, category) {
+				filtered = append(filtered, metric)
+			}
+		}
+		metrics = filtered
+	}
+
+	// Сводка считается по всем метрикам категории, даже если запрошены только нарушения
+	summary := map[string]int{"PASS": 0, "WARNING": 0, "FAIL": 0}
+	for _, metric := range metrics {
+		summary[metric.Status]++
+	}
+
+	breached := database.FilterBreachedMetrics(metrics)
+	if onlyBreached {
+		metrics = breached
+	}
+	if metrics == nil {
+		metrics = []database.DataQualityMetric{}
+	}
+
+	SendJSONResponse(c, http.StatusOK, ProjectQualityMetricsResponse{
+		ProjectID: projectID,
+		Period:    period,
+		Category:  category,
+		Metrics:   metrics,
+		Total:     len(metrics),
+		Breached:  len(breached),
+		Summary:   summary,
+	})
+}
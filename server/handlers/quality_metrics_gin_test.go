@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"httpserver/database"
+)
+
+// setupTestProjectQualityMetrics создает ServiceDB с проектом и основную БД с метриками качества
+// его базы и возвращает обработчик, настроенный на обе БД
+func setupTestProjectQualityMetrics(t *testing.T) (*QualityHandler, int) {
+	t.Helper()
+
+	serviceDB, err := database.NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	t.Cleanup(func() { serviceDB.Close() })
+
+	client, err := serviceDB.CreateClient("Quality Client", "Quality Client LLC", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Quality Project", "nomenclature", "", "1C", 0.8)
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	projectDB, err := serviceDB.CreateProjectDatabase(project.ID, "Quality DB", "/tmp/quality.db", "", 0)
+	if err != nil {
+		t.Fatalf("Failed to create project database: %v", err)
+	}
+
+	// Метрики качества хранятся в основной БД
+	mainDB, err := database.NewDB(filepath.Join(t.TempDir(), "main.db"))
+	if err != nil {
+		t.Fatalf("Failed to create main DB: %v", err)
+	}
+	t.Cleanup(func() { mainDB.Close() })
+
+	now := time.Now()
+	metrics := []struct {
+		category   string
+		name       string
+		value      float64
+		threshold  float64
+		status     string
+		measuredAt time.Time
+	}{
+		{"completeness", "filled_names", 0.98, 0.95, "PASS", now.Add(-time.Hour)},
+		{"completeness", "filled_codes", 0.90, 0.95, "WARNING", now.Add(-2 * time.Hour)},
+		{"completeness", "filled_units", 0.50, 0.95, "FAIL", now.Add(-3 * time.Hour)},
+		{"uniqueness", "duplicate_codes", 0.99, 0.97, "PASS", now.Add(-4 * time.Hour)},
+		// Старая метрика не попадает в период week
+		{"completeness", "filled_groups", 0.40, 0.95, "FAIL", now.AddDate(0, 0, -20)},
+	}
+	for _, m := range metrics {
+		if _, err := mainDB.Exec(`
+			INSERT INTO data_quality_metrics (upload_id, database_id, metric_category, metric_name,
+				metric_value, threshold_value, status, measured_at, details)
+			VALUES (1, ?, ?, ?, ?, ?, ?, ?, '')
+		`, projectDB.ID, m.category, m.name, m.value, m.threshold, m.status, m.measuredAt); err != nil {
+			t.Fatalf("Failed to seed metric %s: %v", m.name, err)
+		}
+	}
+
+	handler := NewQualityHandler(NewBaseHandlerFromMiddleware(), nil, func(entry interface{}) {}, nil, "")
+	handler.SetGetProjectDatabases(serviceDB.GetProjectDatabases)
+	handler.SetGetQualityMetrics(mainDB.GetQualityMetricsForDatabases)
+
+	return handler, project.ID
+}
+
+// requestProjectQualityMetrics выполняет запрос к обработчику и декодирует ответ
+func requestProjectQualityMetrics(t *testing.T, handler *QualityHandler, url string) (*httptest.ResponseRecorder, ProjectQualityMetricsResponse) {
+	t.Helper()
+
+	router := setupGinTestRouter()
+	router.GET("/api/projects/:id/quality-metrics", handler.HandleProjectQualityMetricsGin)
+
+	req := httptest.NewRequest(http.MethodGet, url, nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response ProjectQualityMetricsResponse
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+	}
+	return w, response
+}
+
+func TestHandleProjectQualityMetricsGin_FiltersByPeriodAndCategory(t *testing.T) {
+	handler, projectID := setupTestProjectQualityMetrics(t)
+
+	w, response := requestProjectQualityMetrics(t, handler,
+		"/api/projects/"+strconv.Itoa(projectID)+"/quality-metrics?period=week&category=completeness")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if response.Total != 3 || len(response.Metrics) != 3 {
+		t.Fatalf("Expected 3 completeness metrics for the week, got %d", response.Total)
+	}
+	for _, metric := range response.Metrics {
+		if metric.MetricCategory != "completeness" {
+			t.Errorf("Unexpected category %q", metric.MetricCategory)
+		}
+		if metric.ThresholdValue == nil {
+			t.Errorf("Metric %s has no threshold", metric.MetricName)
+		}
+	}
+
+	expectedSummary := map[string]int{"PASS": 1, "WARNING": 1, "FAIL": 1}
+	for status, count := range expectedSummary {
+		if response.Summary[status] != count {
+			t.Errorf("Expected summary[%s] = %d, got %d", status, count, response.Summary[status])
+		}
+	}
+	if response.Breached != 2 {
+		t.Errorf("Expected 2 breached metrics, got %d", response.Breached)
+	}
+}
+
+func TestHandleProjectQualityMetricsGin_DefaultPeriod(t *testing.T) {
+	handler, projectID := setupTestProjectQualityMetrics(t)
+
+	w, response := requestProjectQualityMetrics(t, handler, "/api/projects/"+strconv.Itoa(projectID)+"/quality-metrics")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if response.Period != "year" {
+		t.Errorf("Expected default period 'year', got %q", response.Period)
+	}
+	if response.Total != 5 {
+		t.Errorf("Expected all 5 metrics, got %d", response.Total)
+	}
+	if response.Summary["FAIL"] != 2 {
+		t.Errorf("Expected 2 failed metrics, got %d", response.Summary["FAIL"])
+	}
+}
+
+func TestHandleProjectQualityMetricsGin_OnlyBreached(t *testing.T) {
+	handler, projectID := setupTestProjectQualityMetrics(t)
+
+	w, response := requestProjectQualityMetrics(t, handler,
+		"/api/projects/"+strconv.Itoa(projectID)+"/quality-metrics?period=week&breached=true")
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if response.Total != 2 {
+		t.Fatalf("Expected 2 breached metrics, got %d", response.Total)
+	}
+	for _, metric := range response.Metrics {
+		if !metric.IsBreached() {
+			t.Errorf("Metric %s with status %s should not be returned", metric.MetricName, metric.Status)
+		}
+	}
+	// Сводка по-прежнему учитывает все метрики периода
+	if response.Summary["PASS"] != 2 {
+		t.Errorf("Expected summary to keep 2 passed metrics, got %d", response.Summary["PASS"])
+	}
+}
+
+func TestHandleProjectQualityMetricsGin_InvalidParams(t *testing.T) {
+	handler, projectID := setupTestProjectQualityMetrics(t)
+
+	urls := []string{
+		"/api/projects/abc/quality-metrics",
+		"/api/projects/" + strconv.Itoa(projectID) + "/quality-metrics?period=decade",
+		"/api/projects/" + strconv.Itoa(projectID) + "/quality-metrics?breached=maybe",
+	}
+	for _, url := range urls {
+		w, _ := requestProjectQualityMetrics(t, handler, url)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", url, w.Code)
+		}
+	}
+}
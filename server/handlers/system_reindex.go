@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"httpserver/database"
+)
+
+// HandleSystemReindexGin выполняет шаги обслуживания после крупных импортов
+// @Summary Обслуживание индексов и кэшей
+// @Description Перестраивает индекс ГОСТов, пересчитывает статистику клиентов, обновляет размеры файлов баз данных и привязывает эталоны к справочникам. Каждый шаг идемпотентен.
+// @Tags system
+// @Produce json
+// @Param steps query string false "Шаги через запятую: gosts_fts, client_stats, database_sizes, references (по умолчанию все)"
+// @Success 200 {object} database.ReindexReport "Результат по каждому шагу"
+// @Failure 400 {object} ErrorResponse "Неизвестный шаг"
+// @Router /api/system/reindex [post]
+func (h *SystemStatusHandler) HandleSystemReindexGin(c *gin.Context) {
+	steps, err := database.ParseReindexSteps(c.Query("steps"))
+	if err != nil {
+		SendJSONError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	SendJSONResponse(c, http.StatusOK, database.RunReindex(h.serviceDB, h.gostsDB, steps))
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"httpserver/database"
+)
+
+func TestHandleSystemReindexGin_SelectedSteps(t *testing.T) {
+	serviceDB, err := database.NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer serviceDB.Close()
+
+	if _, err := serviceDB.CreateClient("Reindex Client", "Reindex Client LLC", "", "", "", "", "RU", "test_user"); err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+
+	handler := NewSystemStatusHandler(NewBaseHandlerFromMiddleware(), serviceDB, nil, t.TempDir())
+	router := setupGinTestRouter()
+	router.POST("/api/system/reindex", handler.HandleSystemReindexGin)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/system/reindex?steps=client_stats,gosts_fts", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var report database.ReindexReport
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if report.ClientStats == nil || report.ClientStats.Clients != 1 {
+		t.Errorf("Expected client_stats result for 1 client, got %+v", report.ClientStats)
+	}
+	if report.DatabaseSizes != nil || report.References != nil {
+		t.Errorf("Expected unselected steps to be skipped, got %+v", report)
+	}
+	if _, ok := report.Errors[database.ReindexStepGostsFTS]; !ok {
+		t.Errorf("Expected gosts_fts error without GOSTs DB, got %v", report.Errors)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/system/reindex?steps=unknown", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown step, got %d", w.Code)
+	}
+}
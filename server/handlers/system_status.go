@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"database/sql"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"httpserver/database"
+	"httpserver/internal/config"
+)
+
+// SystemStatusDatabase именованное подключение к БД, для которого выводится статистика пула
+type SystemStatusDatabase struct {
+	Name string
+	Path string
+	Conn *sql.DB
+}
+
+// SystemStatusRunningJob выполняющаяся фоновая задача
+type SystemStatusRunningJob struct {
+	Name      string     `json:"name"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	Details   string     `json:"details,omitempty"`
+}
+
+// SystemStatusPool статистика пула подключений к БД
+type SystemStatusPool struct {
+	Path               string `json:"path"`
+	Available          bool   `json:"available"`
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDurationMs     int64  `json:"wait_duration_ms"`
+}
+
+// SystemStatusCounts количество основных сущностей
+type SystemStatusCounts struct {
+	Clients    int `json:"clients"`
+	Projects   int `json:"projects"`
+	Benchmarks int `json:"benchmarks"`
+	Gosts      int `json:"gosts"`
+}
+
+// SystemStatusJobs выполняющиеся фоновые задачи
+type SystemStatusJobs struct {
+	Running []SystemStatusRunningJob `json:"running"`
+	Count   int                      `json:"count"`
+}
+
+// SystemStatusBackup информация о последнем бэкапе
+type SystemStatusBackup struct {
+	Directory    string     `json:"directory"`
+	LastBackupAt *time.Time `json:"last_backup_at"`
+	LastBackup   string     `json:"last_backup,omitempty"`
+}
+
+// SystemStatusConfig краткая сводка конфигурации
+type SystemStatusConfig struct {
+	Model                string `json:"model"`
+	MultiProviderEnabled bool   `json:"multi_provider_enabled"`
+	AggregationStrategy  string `json:"aggregation_strategy,omitempty"`
+	EnrichmentEnabled    bool   `json:"enrichment_enabled"`
+}
+
+// SystemStatusResponse сводный статус системы
+type SystemStatusResponse struct {
+	Status    string                      `json:"status"` // ok или degraded
+	Timestamp time.Time                   `json:"timestamp"`
+	Databases map[string]SystemStatusPool `json:"databases"`
+	Counts    SystemStatusCounts          `json:"counts"`
+	Jobs      SystemStatusJobs            `json:"jobs"`
+	Backup    SystemStatusBackup          `json:"backup"`
+	Config    SystemStatusConfig          `json:"config"`
+	Errors    []string                    `json:"errors,omitempty"`
+}
+
+// SystemStatusHandler обработчик сводного статуса системы
+type SystemStatusHandler struct {
+	*BaseHandler
+	serviceDB *database.ServiceDB
+	gostsDB   *database.GostsDB
+	backupDir string
+	// Функции для получения текущего состояния сервера (передаются из server для избежания циклических зависимостей)
+	databasesFunc   func() []SystemStatusDatabase
+	runningJobsFunc func() []SystemStatusRunningJob
+	configFunc      func() *config.Config
+}
+
+// NewSystemStatusHandler создает новый обработчик сводного статуса системы
+func NewSystemStatusHandler(
+	baseHandler *BaseHandler,
+	serviceDB *database.ServiceDB,
+	gostsDB *database.GostsDB,
+	backupDir string,
+) *SystemStatusHandler {
+	return &SystemStatusHandler{
+		BaseHandler: baseHandler,
+		serviceDB:   serviceDB,
+		gostsDB:     gostsDB,
+		backupDir:   backupDir,
+	}
+}
+
+// SetDatabasesFunc устанавливает функцию получения подключений к БД
+func (h *SystemStatusHandler) SetDatabasesFunc(fn func() []SystemStatusDatabase) {
+	h.databasesFunc = fn
+}
+
+// SetRunningJobsFunc устанавливает функцию получения выполняющихся задач
+func (h *SystemStatusHandler) SetRunningJobsFunc(fn func() []SystemStatusRunningJob) {
+	h.runningJobsFunc = fn
+}
+
+// SetConfigFunc устанавливает функцию получения текущей конфигурации
+func (h *SystemStatusHandler) SetConfigFunc(fn func() *config.Config) {
+	h.configFunc = fn
+}
+
+// HandleSystemStatusGin возвращает сводный статус системы
+// @Summary Сводный статус системы
+// @Description Пулы подключений к БД, количество сущностей, выполняющиеся задачи, последний бэкап и сводка конфигурации
+// @Tags system
+// @Produce json
+// @Success 200 {object} SystemStatusResponse "Статус системы"
+// @Router /api/system/status [get]
+func (h *SystemStatusHandler) HandleSystemStatusGin(c *gin.Context) {
+	SendJSONResponse(c, http.StatusOK, h.BuildStatus())
+}
+
+// BuildStatus собирает сводный статус системы.
+// Ошибки отдельных источников не прерывают сборку, а попадают в Errors и переводят статус в degraded.
+func (h *SystemStatusHandler) BuildStatus() SystemStatusResponse {
+	status := SystemStatusResponse{
+		Status:    "ok",
+		Timestamp: time.Now(),
+		Databases: make(map[string]SystemStatusPool),
+		Jobs:      SystemStatusJobs{Running: []SystemStatusRunningJob{}},
+		Backup:    SystemStatusBackup{Directory: h.backupDir},
+	}
+
+	if h.databasesFunc != nil {
+		for _, db := range h.databasesFunc() {
+			pool := SystemStatusPool{Path: db.Path}
+			if db.Conn != nil {
+				stats := db.Conn.Stats()
+				pool.Available = true
+				pool.MaxOpenConnections = stats.MaxOpenConnections
+				pool.OpenConnections = stats.OpenConnections
+				pool.InUse = stats.InUse
+				pool.Idle = stats.Idle
+				pool.WaitCount = stats.WaitCount
+				pool.WaitDurationMs = stats.WaitDuration.Milliseconds()
+			} else {
+				status.Errors = append(status.Errors, "database "+db.Name+" is not available")
+			}
+			status.Databases[db.Name] = pool
+		}
+	}
+
+	if h.serviceDB != nil {
+		clients, projects, benchmarks, err := h.serviceDB.GetEntityCounts()
+		if err != nil {
+			status.Errors = append(status.Errors, err.Error())
+		} else {
+			status.Counts.Clients = clients
+			status.Counts.Projects = projects
+			status.Counts.Benchmarks = benchmarks
+		}
+	} else {
+		status.Errors = append(status.Errors, "service database is not available")
+	}
+
+	if h.gostsDB != nil {
+		gosts, err := h.gostsDB.CountGosts()
+		if err != nil {
+			status.Errors = append(status.Errors, err.Error())
+		} else {
+			status.Counts.Gosts = gosts
+		}
+	}
+
+	if h.runningJobsFunc != nil {
+		if jobs := h.runningJobsFunc(); jobs != nil {
+			status.Jobs.Running = jobs
+		}
+	}
+	status.Jobs.Count = len(status.Jobs.Running)
+
+	if h.backupDir != "" {
+		lastBackupAt, lastBackup, err := findLastBackup(h.backupDir)
+		if err != nil {
+			status.Errors = append(status.Errors, err.Error())
+		} else if lastBackup != "" {
+			status.Backup.LastBackupAt = &lastBackupAt
+			status.Backup.LastBackup = lastBackup
+		}
+	}
+
+	if h.configFunc != nil {
+		if cfg := h.configFunc(); cfg != nil {
+			status.Config.Model = cfg.ArliaiModel
+			status.Config.MultiProviderEnabled = cfg.MultiProviderEnabled
+			status.Config.AggregationStrategy = cfg.AggregationStrategy
+			status.Config.EnrichmentEnabled = cfg.Enrichment != nil && cfg.Enrichment.Enabled
+		}
+	}
+
+	if len(status.Errors) > 0 {
+		status.Status = "degraded"
+	}
+
+	return status
+}
+
+// findLastBackup находит самый свежий бэкап в каталоге: zip-архив backup_*.zip
+// или каталог копий files/<timestamp>. Отсутствие каталога не считается ошибкой.
+func findLastBackup(backupDir string) (time.Time, string, error) {
+	var lastTime time.Time
+	var lastName string
+
+	consider := func(dir string, filter func(os.DirEntry) bool) error {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		for _, entry := range entries {
+			if !filter(entry) {
+				continue
+			}
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			if info.ModTime().After(lastTime) {
+				lastTime = info.ModTime()
+				rel, _ := filepath.Rel(backupDir, filepath.Join(dir, entry.Name()))
+				lastName = filepath.ToSlash(rel)
+			}
+		}
+		return nil
+	}
+
+	if err := consider(backupDir, func(e os.DirEntry) bool {
+		return !e.IsDir() && strings.HasPrefix(e.Name(), "backup_") && strings.HasSuffix(e.Name(), ".zip")
+	}); err != nil {
+		return time.Time{}, "", err
+	}
+	if err := consider(filepath.Join(backupDir, "files"), func(e os.DirEntry) bool {
+		return e.IsDir()
+	}); err != nil {
+		return time.Time{}, "", err
+	}
+
+	return lastTime, lastName, nil
+}
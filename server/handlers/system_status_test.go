@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"httpserver/database"
+	"httpserver/internal/config"
+)
+
+func TestHandleSystemStatusGin_AllSections(t *testing.T) {
+	serviceDB, err := database.NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	defer serviceDB.Close()
+
+	client, err := serviceDB.CreateClient("Status Client", "Status Client LLC", "", "", "", "", "RU", "test_user")
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if _, err := serviceDB.CreateClientProject(client.ID, "Status Project", "nomenclature", "", "1C", 0.8); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	gostsDB, err := database.NewGostsDB(filepath.Join(tempDir, "gosts.db"))
+	if err != nil {
+		t.Fatalf("Failed to create GOSTs DB: %v", err)
+	}
+	defer gostsDB.Close()
+	if _, err := gostsDB.CreateOrUpdateGost(&database.Gost{GostNumber: "ГОСТ 380-2005", Title: "Сталь углеродистая обыкновенного качества"}); err != nil {
+		t.Fatalf("Failed to create gost: %v", err)
+	}
+
+	backupDir := filepath.Join(tempDir, "backups")
+	if err := os.MkdirAll(backupDir, 0755); err != nil {
+		t.Fatalf("Failed to create backup dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(backupDir, "backup_20250101_120000.zip"), []byte("zip"), 0644); err != nil {
+		t.Fatalf("Failed to create backup file: %v", err)
+	}
+
+	handler := NewSystemStatusHandler(NewBaseHandlerFromMiddleware(), serviceDB, gostsDB, backupDir)
+	handler.SetDatabasesFunc(func() []SystemStatusDatabase {
+		return []SystemStatusDatabase{
+			{Name: "main", Path: "data.db", Conn: serviceDB.GetDB()},
+			{Name: "normalized", Path: "normalized.db", Conn: serviceDB.GetDB()},
+			{Name: "service", Path: "service.db", Conn: serviceDB.GetDB()},
+		}
+	})
+	startedAt := time.Now().Add(-time.Minute)
+	handler.SetRunningJobsFunc(func() []SystemStatusRunningJob {
+		return []SystemStatusRunningJob{{Name: "normalization", StartedAt: &startedAt}}
+	})
+	handler.SetConfigFunc(func() *config.Config {
+		return &config.Config{
+			ArliaiModel:          "GLM-4.5-Air",
+			MultiProviderEnabled: true,
+			Enrichment:           &config.EnrichmentConfig{Enabled: true},
+		}
+	})
+
+	router := setupGinTestRouter()
+	router.GET("/api/system/status", handler.HandleSystemStatusGin)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/system/status", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &raw); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	for _, section := range []string{"status", "timestamp", "databases", "counts", "jobs", "backup", "config"} {
+		if _, ok := raw[section]; !ok {
+			t.Errorf("Section %q is missing from the response", section)
+		}
+	}
+
+	var status SystemStatusResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if status.Status != "ok" {
+		t.Errorf("Expected status 'ok', got %q (errors: %v)", status.Status, status.Errors)
+	}
+	for _, name := range []string{"main", "normalized", "service"} {
+		pool, ok := status.Databases[name]
+		if !ok {
+			t.Errorf("Database %q is missing", name)
+			continue
+		}
+		if !pool.Available || pool.MaxOpenConnections != 1 {
+			t.Errorf("Unexpected pool stats for %q: %+v", name, pool)
+		}
+	}
+	if status.Counts.Clients != 1 || status.Counts.Projects != 1 || status.Counts.Benchmarks != 0 || status.Counts.Gosts != 1 {
+		t.Errorf("Unexpected counts: %+v", status.Counts)
+	}
+	if status.Jobs.Count != 1 || status.Jobs.Running[0].Name != "normalization" {
+		t.Errorf("Unexpected jobs: %+v", status.Jobs)
+	}
+	if status.Backup.LastBackupAt == nil || status.Backup.LastBackup != "backup_20250101_120000.zip" {
+		t.Errorf("Unexpected backup info: %+v", status.Backup)
+	}
+	if status.Config.Model != "GLM-4.5-Air" || !status.Config.MultiProviderEnabled || !status.Config.EnrichmentEnabled {
+		t.Errorf("Unexpected config summary: %+v", status.Config)
+	}
+}
+
+func TestHandleSystemStatusGin_DegradedWithoutDatabases(t *testing.T) {
+	handler := NewSystemStatusHandler(NewBaseHandlerFromMiddleware(), nil, nil, filepath.Join(t.TempDir(), "missing"))
+	handler.SetDatabasesFunc(func() []SystemStatusDatabase {
+		return []SystemStatusDatabase{{Name: "main", Path: "data.db"}}
+	})
+
+	status := handler.BuildStatus()
+	if status.Status != "degraded" {
+		t.Errorf("Expected status 'degraded', got %q", status.Status)
+	}
+	if status.Databases["main"].Available {
+		t.Error("Database without connection should not be available")
+	}
+	if status.Backup.LastBackupAt != nil {
+		t.Error("Missing backup directory should not report a backup")
+	}
+	if status.Jobs.Running == nil {
+		t.Error("Running jobs should be an empty list, not null")
+	}
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"time"
+
+	"httpserver/server/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idempotencyKeyTTL срок, в течение которого повтор запроса с тем же Idempotency-Key возвращает прежний результат
+const idempotencyKeyTTL = 24 * time.Hour
+
+// idempotent возвращает middleware идемпотентности для эндпоинтов импорта и запуска нормализации.
+// Без сервисной БД ключи не сохраняются и запросы обрабатываются как обычно.
+func (s *Server) idempotent() gin.HandlerFunc {
+	if s.serviceDB == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return middleware.GinIdempotencyMiddleware(s.serviceDB, idempotencyKeyTTL)
+}
@@ -53,7 +53,7 @@ func (a *legacyRouteAdapter) registerBenchmarks(group *gin.RouterGroup) {
 	}
 
 	// Метод всегда существует, так как он определен в server/benchmarks.go или server/handlers/legacy/benchmarks_legacy.go
-	group.POST("/manufacturers/import", httpHandlerToGin(a.server.handleImportManufacturers))
+	group.POST("/manufacturers/import", a.server.idempotent(), httpHandlerToGin(a.server.handleImportManufacturers))
 }
 
 func (a *legacyRouteAdapter) registerSimilarity(group *gin.RouterGroup) {
@@ -83,7 +83,7 @@ func (a *legacyRouteAdapter) registerSimilarity(group *gin.RouterGroup) {
 
 	// Экспорт и импорт
 	group.POST("/export", httpHandlerToGin(a.server.handleSimilarityExport))
-	group.POST("/import", httpHandlerToGin(a.server.handleSimilarityImport))
+	group.POST("/import", a.server.idempotent(), httpHandlerToGin(a.server.handleSimilarityImport))
 
 	// Производительность
 	group.GET("/performance", httpHandlerToGin(a.server.handleSimilarityPerformance))
@@ -181,7 +181,7 @@ func (a *legacyRouteAdapter) registerGisp(group *gin.RouterGroup) {
 
 	// GISP nomenclatures endpoints
 	// Методы определены в server/handlers/legacy/gisp_nomenclatures_legacy.go
-	group.POST("/nomenclatures/import", httpHandlerToGin(a.server.handleImportGISPNomenclatures))
+	group.POST("/nomenclatures/import", a.server.idempotent(), httpHandlerToGin(a.server.handleImportGISPNomenclatures))
 	group.GET("/nomenclatures", httpHandlerToGin(a.server.handleGetGISPNomenclatures))
 	group.GET("/nomenclatures/:id", httpHandlerToGin(a.server.handleGetGISPNomenclatureDetail))
 	group.GET("/reference-books", httpHandlerToGin(a.server.handleGetGISPReferenceBooks))
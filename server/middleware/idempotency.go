@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"httpserver/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader заголовок, по которому повтор запроса распознается как тот же запрос
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotencyReplayedHeader выставляется в ответе, взятом из сохраненного результата
+	IdempotencyReplayedHeader = "Idempotency-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// IdempotencyStore хранилище ключей идемпотентности (реализуется database.ServiceDB)
+type IdempotencyStore interface {
+	ReserveIdempotencyKey(key, scope string, ttl time.Duration) (*database.IdempotencyRecord, error)
+	CompleteIdempotencyKey(key, scope string, statusCode int, contentType string, body []byte) error
+	ReleaseIdempotencyKey(key, scope string) error
+}
+
+// idempotencyResponseWriter копирует тело ответа для сохранения по ключу
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// GinIdempotencyMiddleware делает POST-запросы с заголовком Idempotency-Key безопасными для повтора.
+// Первый запрос с ключом выполняется, его ответ сохраняется; повтор с тем же ключом на тот же путь
+// в течение ttl получает сохраненный ответ без повторного запуска задачи. Пока первый запрос
+// выполняется, повтор получает 409. Ответы 5xx не сохраняются, чтобы запрос можно было повторить.
+// Запросы без заголовка обрабатываются как обычно.
+func GinIdempotencyMiddleware(store IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" || store == nil {
+			c.Next()
+			return
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error":      true,
+				"message":    "Idempotency-Key слишком длинный",
+				"request_id": GetRequestIDFromGin(c),
+			})
+			return
+		}
+
+		scope := c.Request.Method + " " + c.Request.URL.Path
+		existing, err := store.ReserveIdempotencyKey(key, scope, ttl)
+		if err != nil {
+			// Недоступность хранилища не должна блокировать импорт
+			slog.Error("[Idempotency] Failed to reserve key", "key", key, "scope", scope, "error", err)
+			c.Next()
+			return
+		}
+
+		if existing != nil {
+			if existing.Status != database.IdempotencyStatusCompleted {
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+					"error":      true,
+					"message":    "Запрос с этим Idempotency-Key еще выполняется",
+					"request_id": GetRequestIDFromGin(c),
+				})
+				return
+			}
+
+			c.Header(IdempotencyReplayedHeader, "true")
+			if existing.ContentType != "" {
+				c.Header("Content-Type", existing.ContentType)
+			}
+			c.Status(existing.StatusCode)
+			c.Writer.Write(existing.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		completed := false
+		defer func() {
+			// При панике или ошибке сервера ключ освобождается для повторной попытки
+			if !completed {
+				if err := store.ReleaseIdempotencyKey(key, scope); err != nil {
+					slog.Error("[Idempotency] Failed to release key", "key", key, "scope", scope, "error", err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+
+		if err := store.CompleteIdempotencyKey(key, scope, status, writer.Header().Get("Content-Type"), writer.body.Bytes()); err != nil {
+			slog.Error("[Idempotency] Failed to save response", "key", key, "scope", scope, "error", err)
+			return
+		}
+		completed = true
+	}
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"httpserver/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newIdempotencyTestRouter создает роутер с обработчиком, запускающим "задачу" на каждый выполненный запрос
+func newIdempotencyTestRouter(t *testing.T, ttl time.Duration) (*gin.Engine, *database.ServiceDB, *int) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := database.NewServiceDB(":memory:")
+	if err != nil {
+		t.Fatalf("Failed to create ServiceDB: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	jobs := 0
+	router := gin.New()
+	router.POST("/api/import", GinIdempotencyMiddleware(db, ttl), func(c *gin.Context) {
+		jobs++
+		c.JSON(http.StatusAccepted, gin.H{"job_id": fmt.Sprintf("job-%d", jobs)})
+	})
+	router.POST("/api/fail", GinIdempotencyMiddleware(db, ttl), func(c *gin.Context) {
+		jobs++
+		c.JSON(http.StatusInternalServerError, gin.H{"error": true})
+	})
+
+	return router, db, &jobs
+}
+
+func doIdempotentRequest(router *gin.Engine, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestGinIdempotencyMiddleware_SameKeyTwice проверяет, что повтор с тем же ключом не создает вторую задачу
+func TestGinIdempotencyMiddleware_SameKeyTwice(t *testing.T) {
+	router, _, jobs := newIdempotencyTestRouter(t, time.Hour)
+
+	first := doIdempotentRequest(router, "/api/import", "key-1")
+	second := doIdempotentRequest(router, "/api/import", "key-1")
+
+	if *jobs != 1 {
+		t.Fatalf("Expected a single job for repeated key, got %d", *jobs)
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("Replayed response %d %q differs from original %d %q",
+			second.Code, second.Body.String(), first.Code, first.Body.String())
+	}
+	if second.Header().Get(IdempotencyReplayedHeader) != "true" {
+		t.Error("Replayed response should have Idempotency-Replayed header")
+	}
+	if second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("Content-Type = %q, want %q", second.Header().Get("Content-Type"), first.Header().Get("Content-Type"))
+	}
+
+	// Другой ключ и запросы без ключа запускают новые задачи
+	doIdempotentRequest(router, "/api/import", "key-2")
+	doIdempotentRequest(router, "/api/import", "")
+	if *jobs != 3 {
+		t.Errorf("Expected 3 jobs, got %d", *jobs)
+	}
+}
+
+// TestGinIdempotencyMiddleware_ExpiredKey проверяет, что после истечения TTL ключ можно использовать снова
+func TestGinIdempotencyMiddleware_ExpiredKey(t *testing.T) {
+	router, db, jobs := newIdempotencyTestRouter(t, time.Hour)
+
+	doIdempotentRequest(router, "/api/import", "key-1")
+	if _, err := db.GetDB().Exec(`UPDATE idempotency_keys SET created_at = datetime('now', '-2 hours')`); err != nil {
+		t.Fatalf("Failed to age idempotency key: %v", err)
+	}
+	doIdempotentRequest(router, "/api/import", "key-1")
+
+	if *jobs != 2 {
+		t.Errorf("Expected expired key to start a new job, got %d jobs", *jobs)
+	}
+}
+
+// TestGinIdempotencyMiddleware_InProgress проверяет ответ 409 на повтор, пока первый запрос выполняется
+func TestGinIdempotencyMiddleware_InProgress(t *testing.T) {
+	router, db, jobs := newIdempotencyTestRouter(t, time.Hour)
+
+	if _, err := db.ReserveIdempotencyKey("key-1", "POST /api/import", time.Hour); err != nil {
+		t.Fatalf("ReserveIdempotencyKey failed: %v", err)
+	}
+
+	w := doIdempotentRequest(router, "/api/import", "key-1")
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 for in-progress key, got %d", w.Code)
+	}
+	if *jobs != 0 {
+		t.Errorf("Expected no jobs, got %d", *jobs)
+	}
+}
+
+// TestGinIdempotencyMiddleware_ServerErrorReleasesKey проверяет, что ответ 5xx не сохраняется
+func TestGinIdempotencyMiddleware_ServerErrorReleasesKey(t *testing.T) {
+	router, _, jobs := newIdempotencyTestRouter(t, time.Hour)
+
+	doIdempotentRequest(router, "/api/fail", "key-1")
+	w := doIdempotentRequest(router, "/api/fail", "key-1")
+
+	if *jobs != 2 {
+		t.Errorf("Expected failed request to be retried, got %d jobs", *jobs)
+	}
+	if w.Header().Get(IdempotencyReplayedHeader) != "" {
+		t.Error("Failed response should not be replayed")
+	}
+}
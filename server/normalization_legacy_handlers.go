@@ -13,7 +13,6 @@ import (
 	"runtime/debug"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"database/sql"
@@ -41,11 +40,12 @@ func (s *Server) handleNormalizeStart(w http.ResponseWriter, r *http.Request) {
 		MinConfidence    float64 `json:"min_confidence"`
 		RateLimitDelayMS int     `json:"rate_limit_delay_ms"`
 		MaxRetries       int     `json:"max_retries"`
-		Model            string  `json:"model"`     // Выбранная модель AI
-		Database         string  `json:"database"`  // База данных для нормализации
-		UseKpved         bool    `json:"use_kpved"` // Включить КПВЭД классификацию
-		UseOkpd2         bool    `json:"use_okpd2"` // Включить ОКПД2 классификацию
-		UploadID         int     `json:"upload_id"` // ID выгрузки для привязки checkpoint
+		Model            string  `json:"model"`      // Выбранная модель AI
+		Database         string  `json:"database"`   // База данных для нормализации
+		UseKpved         bool    `json:"use_kpved"`  // Включить КПВЭД классификацию
+		UseOkpd2         bool    `json:"use_okpd2"`  // Включить ОКПД2 классификацию
+		UploadID         int     `json:"upload_id"`  // ID выгрузки для привязки checkpoint
+		ProjectID        int     `json:"project_id"` // Проект, чья конфигурация нормализации используется
 	}
 
 	var req NormalizeRequest
@@ -72,11 +72,23 @@ func (s *Server) handleNormalizeStart(w http.ResponseWriter, r *http.Request) {
 	s.normalizerErrors = 0
 	s.normalizerMutex.Unlock()
 
-	// Загружаем конфигурацию нормализации из serviceDB
+	// Загружаем конфигурацию нормализации из serviceDB: конфигурацию проекта (явно указанного
+	// или владеющего базой данных), а если своей у проекта нет - глобальную
 	var config *database.NormalizationConfig
 	if s.serviceDB != nil {
+		projectID := req.ProjectID
+		if projectID <= 0 && req.Database != "" {
+			if _, ownerProjectID, err := s.serviceDB.FindClientAndProjectByDatabasePath(req.Database); err == nil {
+				projectID = ownerProjectID
+			}
+		}
+
 		var err error
-		config, err = s.serviceDB.GetNormalizationConfig()
+		if projectID > 0 {
+			config, err = s.serviceDB.GetNormalizationConfigForProject(projectID)
+		} else {
+			config, err = s.serviceDB.GetNormalizationConfig()
+		}
 		if err != nil {
 			LogWarn(r.Context(), "Failed to get normalization config, using defaults", "error", err)
 			config = nil
@@ -750,6 +762,11 @@ func (s *Server) handleNormalizationStatus(w http.ResponseWriter, r *http.Reques
 		KpvedProgress:   kpvedProgress,
 	}
 
+	// Время по фазам текущего или последнего запуска нормализатора
+	if s.normalizer != nil {
+		status.PhaseTimings = s.normalizer.GetPhaseTimings()
+	}
+
 	if isRunning {
 		status.CurrentStep = "Выполняется нормализация..."
 
@@ -2547,7 +2564,7 @@ func (s *Server) processLegacyNormalizationDatabase(clientID, projectID int, pro
 	}
 	if projectDB.FilePath == "" {
 		LogNormalizationError(clientID, projectID, fmt.Errorf("empty file path"), "Empty database file path")
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", nil)
+		s.updateNormalizationSession(sessionID, "failed", nil)
 		return
 	}
 	if sessionID <= 0 {
@@ -2559,7 +2576,7 @@ func (s *Server) processLegacyNormalizationDatabase(clientID, projectID int, pro
 	if _, err := os.Stat(projectDB.FilePath); err != nil {
 		if errors.Is(err, os.ErrNotExist) {
 			LogNormalizationError(clientID, projectID, err, "Database file not found")
-			s.serviceDB.UpdateNormalizationSession(sessionID, "failed", nil)
+			s.updateNormalizationSession(sessionID, "failed", nil)
 			select {
 			case s.normalizerEvents <- fmt.Sprintf("Файл БД %s не найден, пропущена", projectDB.Name):
 			default:
@@ -2567,14 +2584,14 @@ func (s *Server) processLegacyNormalizationDatabase(clientID, projectID int, pro
 			return
 		}
 		LogNormalizationError(clientID, projectID, err, "Error checking database file")
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", nil)
+		s.updateNormalizationSession(sessionID, "failed", nil)
 		return
 	}
 
 	// Открываем подключение к базе данных
 	sourceDB, err := database.NewDB(projectDB.FilePath)
 	if err != nil {
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", nil)
+		s.updateNormalizationSession(sessionID, "failed", nil)
 		LogNormalizationError(clientID, projectID, err, "Failed to open database")
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Ошибка открытия БД %s: %v", projectDB.FilePath, err):
@@ -2593,7 +2610,7 @@ func (s *Server) processLegacyNormalizationDatabase(clientID, projectID int, pro
 	// Получаем все записи из catalog_items
 	items, err := sourceDB.GetAllCatalogItems()
 	if err != nil {
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", nil)
+		s.updateNormalizationSession(sessionID, "failed", nil)
 		LogNormalizationError(clientID, projectID, err, "Failed to read data from database")
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Ошибка чтения данных из %s: %v", projectDB.FilePath, err):
@@ -2603,7 +2620,7 @@ func (s *Server) processLegacyNormalizationDatabase(clientID, projectID int, pro
 	}
 
 	if len(items) == 0 {
-		s.serviceDB.UpdateNormalizationSession(sessionID, "completed", nil)
+		s.updateNormalizationSession(sessionID, "completed", nil)
 		LogInfo(context.Background(), "No items to normalize",
 			"client_id", clientID,
 			"project_id", projectID,
@@ -2638,7 +2655,7 @@ func (s *Server) processLegacyNormalizationDatabase(clientID, projectID int, pro
 	}
 
 	// Обновляем активность сессии перед началом
-	s.serviceDB.UpdateSessionActivity(sessionID)
+	s.updateSessionActivity(sessionID)
 
 	// Запускаем горутину для периодического обновления активности
 	activityTicker := time.NewTicker(30 * time.Second)
@@ -2652,7 +2669,7 @@ func (s *Server) processLegacyNormalizationDatabase(clientID, projectID int, pro
 		for {
 			select {
 			case <-activityTicker.C:
-				s.serviceDB.UpdateSessionActivity(sessionID)
+				s.updateSessionActivity(sessionID)
 			case <-activityDone:
 				return
 			}
@@ -2672,7 +2689,7 @@ func (s *Server) processLegacyNormalizationDatabase(clientID, projectID int, pro
 	}
 
 	if err != nil {
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", &finishedAt)
+		s.updateNormalizationSession(sessionID, "failed", &finishedAt)
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Ошибка нормализации БД %s: %v", projectDB.FilePath, err):
 		default:
@@ -2709,7 +2726,7 @@ func (s *Server) processLegacyNormalizationDatabase(clientID, projectID int, pro
 	}
 
 	// Обновляем сессию как completed
-	s.serviceDB.UpdateNormalizationSession(sessionID, "completed", &finishedAt)
+	s.updateNormalizationSession(sessionID, "completed", &finishedAt)
 	select {
 	case s.normalizerEvents <- fmt.Sprintf("Нормализация БД %s завершена успешно", projectDB.Name):
 	default:
@@ -2774,7 +2791,7 @@ func (s *Server) handleCounterpartyNormalizationResult(
 
 	if wasStopped {
 		// Обновляем сессию как stopped с временем завершения
-		s.serviceDB.UpdateNormalizationSession(sessionID, "stopped", &finishedAt)
+		s.updateNormalizationSession(sessionID, "stopped", &finishedAt)
 		progressPercent := 0.0
 		if len(counterparties) > 0 {
 			progressPercent = float64(result.TotalProcessed) / float64(len(counterparties)) * 100
@@ -2806,7 +2823,7 @@ func (s *Server) handleCounterpartyNormalizationResult(
 			contextMsg, result.TotalProcessed, len(counterparties), progressPercent, projectDB.FilePath)
 	} else {
 		// Обновляем сессию как completed
-		s.serviceDB.UpdateNormalizationSession(sessionID, "completed", &finishedAt)
+		s.updateNormalizationSession(sessionID, "completed", &finishedAt)
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Нормализация контрагентов БД %s завершена: обработано %d, найдено эталонов %d, дозаполнено %d, групп дублей %d",
 			projectDB.Name, result.TotalProcessed, result.BenchmarkMatches, result.EnrichedCount, result.DuplicateGroups):
@@ -2929,110 +2946,62 @@ func (s *Server) processNomenclatureDatabasesParallel(databases []*database.Proj
 		return
 	}
 
-	// Семафор для ограничения параллелизма
-	semaphore := make(chan struct{}, maxWorkers)
-	var wg sync.WaitGroup
-
-	for _, projectDB := range databasesToProcess {
-		// Проверяем, не нужно ли остановить нормализацию
-		if s.shouldStopNormalization() {
-			log.Printf("Normalization stopped, skipping database %s", projectDB.FilePath)
-			break
-		}
-
-		// Захватываем семафор
-		semaphore <- struct{}{}
-		wg.Add(1)
-
-		// Запускаем обработку БД в отдельной горутине
-		go func(db *database.ProjectDatabase) {
-			dbStartTime := time.Now()
-			defer func() {
-				// Освобождаем семафор
-				<-semaphore
-				wg.Done()
-
-				dbDuration := time.Since(dbStartTime)
-				log.Printf("[Nomenclature] Database %s processing completed in %v", db.Name, dbDuration)
-
-				if rec := recover(); rec != nil {
-					log.Printf("Panic in nomenclature normalization goroutine for DB %s: %v\n%s", db.FilePath, rec, debug.Stack())
-					select {
-					case s.normalizerEvents <- fmt.Sprintf("Критическая ошибка нормализации БД %s: %v", db.Name, rec):
-					default:
-					}
-				}
-			}()
+	// Каждая БД обрабатывается независимо: ошибка в одной не прерывает остальные
+	result := runDatabasesParallel(projectID, databasesToProcess, maxWorkers, s.shouldStopNormalization, func(db *database.ProjectDatabase) error {
+		dbStartTime := time.Now()
+		defer func() {
+			log.Printf("[Nomenclature] Database %s processing completed in %v", db.Name, time.Since(dbStartTime))
+		}()
 
-			// Проверяем доступность файла БД перед обработкой
-			if _, err := os.Stat(db.FilePath); err != nil {
-				if errors.Is(err, os.ErrNotExist) {
-					log.Printf("Database file not found: %s, skipping", db.FilePath)
-					select {
-					case s.normalizerEvents <- fmt.Sprintf("Файл БД %s не найден, пропущена", db.Name):
-					default:
-					}
-					return
-				}
-				// Другие ошибки тоже пропускаем
-				log.Printf("Error checking database file %s: %v, skipping", db.FilePath, err)
-				return
+		// Проверяем доступность файла БД перед обработкой
+		if _, err := os.Stat(db.FilePath); err != nil {
+			log.Printf("Database file %s is not available: %v, skipping", db.FilePath, err)
+			select {
+			case s.normalizerEvents <- fmt.Sprintf("Файл БД %s не найден, пропущена", db.Name):
+			default:
 			}
-
-			// Пытаемся создать сессию нормализации для этой базы данных (приоритет 0 по умолчанию, таймаут 1 час)
-			// Используем атомарную функцию, которая создаст сессию только если нет активных
-			sessionID, created, err := s.serviceDB.TryCreateNormalizationSession(db.ID, 0, 3600)
-			if err != nil {
-				select {
-				case s.normalizerEvents <- fmt.Sprintf("Ошибка создания сессии для БД %s: %v", db.FilePath, err):
-				default:
-				}
-				log.Printf("Failed to create normalization session for database %s: %v", db.FilePath, err)
-				return
+			return fmt.Errorf("database file is not available: %w", err)
+		}
+
+		// Пытаемся создать сессию нормализации для этой базы данных (приоритет 0 по умолчанию, таймаут 1 час)
+		// Используем атомарную функцию, которая создаст сессию только если нет активных
+		var sessionID int
+		var created bool
+		err := s.withServiceDBWrite(func() error {
+			var err error
+			sessionID, created, err = s.serviceDB.TryCreateNormalizationSession(db.ID, 0, 3600)
+			if err == nil && created {
+				// Обновляем last_used_at
+				s.serviceDB.UpdateProjectDatabaseLastUsed(db.ID)
 			}
-			if !created {
-				log.Printf("[Nomenclature] Database %s already has active session, skipping", db.Name)
-				select {
-				case s.normalizerEvents <- fmt.Sprintf("БД %s уже обрабатывается, пропущена", db.Name):
-				default:
-				}
-				return
+			return err
+		})
+		if err != nil {
+			select {
+			case s.normalizerEvents <- fmt.Sprintf("Ошибка создания сессии для БД %s: %v", db.FilePath, err):
+			default:
 			}
-
-			// Обновляем last_used_at
-			s.serviceDB.UpdateProjectDatabaseLastUsed(db.ID)
-
-			// Обрабатываем БД в отдельной функции для корректной работы defer
-			s.processLegacyNormalizationDatabase(clientID, projectID, db, sessionID, project, req)
-		}(projectDB)
-	}
-
-	// Ждем завершения всех воркеров
-	wg.Wait()
-	duration := time.Since(startTime)
-
-	// Проверяем финальный статус остановки
-	wasStopped := s.shouldStopNormalization()
-
-	// Получаем статистику по сессиям для этого проекта
-	var completedCount, failedCount, stoppedCount int
-	if s.serviceDB != nil {
-		// Получаем все сессии для баз данных этого проекта
-		// Используем databasesToProcess для статистики по обработанным БД
-		for _, db := range databasesToProcess {
-			session, err := s.serviceDB.GetLastNormalizationSession(db.ID)
-			if err == nil && session != nil {
-				switch session.Status {
-				case "completed":
-					completedCount++
-				case "failed":
-					failedCount++
-				case "stopped":
-					stoppedCount++
-				}
+			log.Printf("Failed to create normalization session for database %s: %v", db.FilePath, err)
+			return fmt.Errorf("failed to create normalization session: %w", err)
+		}
+		if !created {
+			log.Printf("[Nomenclature] Database %s already has active session, skipping", db.Name)
+			select {
+			case s.normalizerEvents <- fmt.Sprintf("БД %s уже обрабатывается, пропущена", db.Name):
+			default:
 			}
+			return errDatabaseSkipped
 		}
-	}
+
+		// Обрабатываем БД в отдельной функции для корректной работы defer
+		s.processLegacyNormalizationDatabase(clientID, projectID, db, sessionID, project, req)
+		return s.normalizationSessionOutcome(db.ID, dbStartTime)
+	})
+	s.setMultiDatabaseResult(result)
+
+	duration := result.FinishedAt.Sub(startTime)
+	wasStopped := result.Status == databaseOutcomeStopped || s.shouldStopNormalization()
+	completedCount, failedCount, stoppedCount := result.Completed, result.Failed, result.Stopped
 
 	if wasStopped {
 		log.Printf("[Nomenclature] Normalization was stopped for project %d - some databases may not have been processed (duration: %v, completed: %d, failed: %d, stopped: %d)",
@@ -3136,86 +3105,31 @@ func (s *Server) processCounterpartyDatabasesParallel(databases []*database.Proj
 		return
 	}
 
-	// Семафор для ограничения параллелизма
-	semaphore := make(chan struct{}, maxWorkers)
-	var wg sync.WaitGroup
-
-	for _, projectDB := range databasesToProcess {
-		// Проверяем, не нужно ли остановить нормализацию
-		if s.shouldStopNormalization() {
-			log.Printf("Normalization stopped, skipping database %s", projectDB.FilePath)
-			break
-		}
-
-		// Захватываем семафор
-		semaphore <- struct{}{}
-		wg.Add(1)
-
-		// Запускаем обработку БД в отдельной горутине
-		go func(db *database.ProjectDatabase) {
-			dbStartTime := time.Now()
-			defer func() {
-				// Освобождаем семафор
-				<-semaphore
-				wg.Done()
-
-				dbDuration := time.Since(dbStartTime)
-				log.Printf("[Counterparty] Database %s processing completed in %v", db.Name, dbDuration)
-
-				if rec := recover(); rec != nil {
-					log.Printf("Panic in counterparty normalization goroutine for DB %s: %v\n%s", db.FilePath, rec, debug.Stack())
-					select {
-					case s.normalizerEvents <- fmt.Sprintf("Критическая ошибка нормализации БД %s: %v", db.Name, rec):
-					default:
-					}
-				}
-			}()
+	// Каждая БД обрабатывается независимо: ошибка в одной не прерывает остальные
+	result := runDatabasesParallel(projectID, databasesToProcess, maxWorkers, s.shouldStopNormalization, func(db *database.ProjectDatabase) error {
+		dbStartTime := time.Now()
+		defer func() {
+			log.Printf("[Counterparty] Database %s processing completed in %v", db.Name, time.Since(dbStartTime))
+		}()
 
-			// Проверяем доступность файла БД перед обработкой
-			if _, err := os.Stat(db.FilePath); err != nil {
-				if errors.Is(err, os.ErrNotExist) {
-					log.Printf("Database file not found: %s, skipping", db.FilePath)
-					select {
-					case s.normalizerEvents <- fmt.Sprintf("Файл БД %s не найден, пропущена", db.Name):
-					default:
-					}
-					return
-				}
-				// Другие ошибки тоже пропускаем
-				log.Printf("Error checking database file %s: %v, skipping", db.FilePath, err)
-				return
+		// Проверяем доступность файла БД перед обработкой
+		if _, err := os.Stat(db.FilePath); err != nil {
+			log.Printf("Database file %s is not available: %v, skipping", db.FilePath, err)
+			select {
+			case s.normalizerEvents <- fmt.Sprintf("Файл БД %s не найден, пропущена", db.Name):
+			default:
 			}
+			return fmt.Errorf("database file is not available: %w", err)
+		}
 
-			s.processCounterpartyDatabase(db, clientID, projectID)
-		}(projectDB)
-	}
-
-	// Ждем завершения всех воркеров
-	wg.Wait()
-	duration := time.Since(startTime)
+		s.processCounterpartyDatabase(db, clientID, projectID)
+		return s.normalizationSessionOutcome(db.ID, dbStartTime)
+	})
+	s.setMultiDatabaseResult(result)
 
-	// Проверяем финальный статус остановки
-	wasStopped := s.shouldStopNormalization()
-
-	// Получаем статистику по сессиям для этого проекта
-	var completedCount, failedCount, stoppedCount int
-	if s.serviceDB != nil {
-		// Получаем все сессии для баз данных этого проекта
-		// Используем databasesToProcess для статистики по обработанным БД
-		for _, db := range databasesToProcess {
-			session, err := s.serviceDB.GetLastNormalizationSession(db.ID)
-			if err == nil && session != nil {
-				switch session.Status {
-				case "completed":
-					completedCount++
-				case "failed":
-					failedCount++
-				case "stopped":
-					stoppedCount++
-				}
-			}
-		}
-	}
+	duration := result.FinishedAt.Sub(startTime)
+	wasStopped := result.Status == databaseOutcomeStopped || s.shouldStopNormalization()
+	completedCount, failedCount, stoppedCount := result.Completed, result.Failed, result.Stopped
 
 	if wasStopped {
 		log.Printf("[Counterparty] Normalization was stopped for project %d - some databases may not have been processed (duration: %v, completed: %d, failed: %d, stopped: %d)",
@@ -3256,7 +3170,17 @@ func (s *Server) processCounterpartyDatabase(projectDB *database.ProjectDatabase
 
 	// Пытаемся создать сессию нормализации для этой базы данных (приоритет 0 по умолчанию, таймаут 1 час)
 	// Используем атомарную функцию, которая создаст сессию только если нет активных
-	sessionID, created, err := s.serviceDB.TryCreateNormalizationSession(projectDB.ID, 0, 3600)
+	var sessionID int
+	var created bool
+	err := s.withServiceDBWrite(func() error {
+		var err error
+		sessionID, created, err = s.serviceDB.TryCreateNormalizationSession(projectDB.ID, 0, 3600)
+		if err == nil && created {
+			// Обновляем last_used_at
+			s.serviceDB.UpdateProjectDatabaseLastUsed(projectDB.ID)
+		}
+		return err
+	})
 	if err != nil {
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Ошибка создания сессии для БД %s: %v", projectDB.FilePath, err):
@@ -3274,14 +3198,11 @@ func (s *Server) processCounterpartyDatabase(projectDB *database.ProjectDatabase
 		return
 	}
 
-	// Обновляем last_used_at
-	s.serviceDB.UpdateProjectDatabaseLastUsed(projectDB.ID)
-
 	// Открываем подключение к базе данных
 	// Проверяем, что путь к файлу не пустой
 	if projectDB.FilePath == "" {
 		log.Printf("Empty file path for database ID %d, skipping", projectDB.ID)
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", nil)
+		s.updateNormalizationSession(sessionID, "failed", nil)
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Ошибка: пустой путь к файлу БД %s", projectDB.Name):
 		default:
@@ -3292,7 +3213,7 @@ func (s *Server) processCounterpartyDatabase(projectDB *database.ProjectDatabase
 	sourceDB, err := database.NewDB(projectDB.FilePath)
 	if err != nil {
 		// Обновляем сессию как failed
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", nil)
+		s.updateNormalizationSession(sessionID, "failed", nil)
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Ошибка открытия БД %s: %v", projectDB.FilePath, err):
 		default:
@@ -3339,7 +3260,7 @@ func (s *Server) processCounterpartyDatabase(projectDB *database.ProjectDatabase
 		}
 		// Обновляем сессию как completed (нет данных для обработки)
 		finishedAt := time.Now()
-		s.serviceDB.UpdateNormalizationSession(sessionID, "completed", &finishedAt)
+		s.updateNormalizationSession(sessionID, "completed", &finishedAt)
 		return
 	}
 
@@ -3370,7 +3291,7 @@ func (s *Server) processCounterpartyDatabase(projectDB *database.ProjectDatabase
 		}
 		// Обновляем сессию как completed (нет валидных данных для обработки)
 		finishedAt := time.Now()
-		s.serviceDB.UpdateNormalizationSession(sessionID, "completed", &finishedAt)
+		s.updateNormalizationSession(sessionID, "completed", &finishedAt)
 		return
 	}
 
@@ -3395,7 +3316,7 @@ func (s *Server) processCounterpartyDatabase(projectDB *database.ProjectDatabase
 	if s.shouldStopNormalization() {
 		log.Printf("Normalization stopped before processing counterparties from %s", projectDB.FilePath)
 		finishedAt := time.Now()
-		s.serviceDB.UpdateNormalizationSession(sessionID, "stopped", &finishedAt)
+		s.updateNormalizationSession(sessionID, "stopped", &finishedAt)
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Нормализация остановлена перед обработкой БД %s", projectDB.Name):
 		default:
@@ -3421,7 +3342,7 @@ func (s *Server) processCounterpartyDatabase(projectDB *database.ProjectDatabase
 		default:
 		}
 		finishedAt := time.Now()
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", &finishedAt)
+		s.updateNormalizationSession(sessionID, "failed", &finishedAt)
 		return
 	}
 
@@ -3429,7 +3350,7 @@ func (s *Server) processCounterpartyDatabase(projectDB *database.ProjectDatabase
 	if counterpartyNormalizer.IsStopped() {
 		log.Printf("[Counterparty] Normalization stopped before ProcessNormalization (resume) for database %s (project %d)", projectDB.Name, projectID)
 		finishedAt := time.Now()
-		s.serviceDB.UpdateNormalizationSession(sessionID, "stopped", &finishedAt)
+		s.updateNormalizationSession(sessionID, "stopped", &finishedAt)
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Нормализация контрагентов БД %s остановлена пользователем до начала обработки (возобновление)", projectDB.Name):
 		default:
@@ -3445,9 +3366,14 @@ func (s *Server) processCounterpartyDatabase(projectDB *database.ProjectDatabase
 
 	// Запускаем нормализацию контрагентов (skipNormalized = false для новой сессии)
 	result, err := counterpartyNormalizer.ProcessNormalization(counterparties, false)
+	if result != nil && result.PhaseTimings != nil {
+		if saveErr := s.saveSessionPhaseTimings(sessionID, result.PhaseTimings); saveErr != nil {
+			log.Printf("Warning: failed to save phase timings for session %d: %v", sessionID, saveErr)
+		}
+	}
 	if err != nil {
 		// Обновляем сессию как failed
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", nil)
+		s.updateNormalizationSession(sessionID, "failed", nil)
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Ошибка нормализации контрагентов БД %s: %v", projectDB.FilePath, err):
 		default:
@@ -3466,7 +3392,7 @@ func (s *Server) processCounterpartyDatabase(projectDB *database.ProjectDatabase
 		if wasStopped {
 			// Обновляем сессию как stopped с временем завершения
 			finishedAt := time.Now()
-			s.serviceDB.UpdateNormalizationSession(sessionID, "stopped", &finishedAt)
+			s.updateNormalizationSession(sessionID, "stopped", &finishedAt)
 			progressPercent := 0.0
 			if len(counterparties) > 0 {
 				progressPercent = float64(result.TotalProcessed) / float64(len(counterparties)) * 100
@@ -3489,7 +3415,7 @@ func (s *Server) processCounterpartyDatabase(projectDB *database.ProjectDatabase
 		} else {
 			// Обновляем сессию как completed
 			finishedAt := time.Now()
-			s.serviceDB.UpdateNormalizationSession(sessionID, "completed", &finishedAt)
+			s.updateNormalizationSession(sessionID, "completed", &finishedAt)
 			select {
 			case s.normalizerEvents <- fmt.Sprintf("Нормализация контрагентов БД %s завершена: обработано %d, найдено эталонов %d, дозаполнено %d, групп дублей %d",
 				projectDB.Name, result.TotalProcessed, result.BenchmarkMatches, result.EnrichedCount, result.DuplicateGroups):
@@ -3770,6 +3696,13 @@ done:
 		"project_id":  projectID,
 	}
 
+	// Итоги по каждой БД последнего запуска нормализации проекта
+	s.normalizerMutex.RLock()
+	if result := s.lastMultiDatabaseResult; result != nil && result.ProjectID == projectID {
+		response["databases_outcome"] = result
+	}
+	s.normalizerMutex.RUnlock()
+
 	// Добавляем информацию о сессиях и БД для контрагентов
 	if project != nil && database.IsCounterpartyProjectType(project.ProjectType) {
 		response["sessions"] = sessionsInfo
@@ -4102,13 +4035,13 @@ func (s *Server) handleResumeNormalizationSession(w http.ResponseWriter, r *http
 // resumeCounterpartyDatabase возобновляет нормализацию контрагентов для остановленной сессии
 func (s *Server) resumeCounterpartyDatabase(projectDB *database.ProjectDatabase, clientID, projectID, sessionID int) {
 	// Обновляем last_used_at
-	s.serviceDB.UpdateProjectDatabaseLastUsed(projectDB.ID)
+	s.updateProjectDatabaseLastUsed(projectDB.ID)
 
 	// Открываем подключение к базе данных
 	sourceDB, err := database.NewDB(projectDB.FilePath)
 	if err != nil {
 		// Обновляем сессию как failed
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", nil)
+		s.updateNormalizationSession(sessionID, "failed", nil)
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Ошибка открытия БД %s при возобновлении: %v", projectDB.FilePath, err):
 		default:
@@ -4161,7 +4094,7 @@ func (s *Server) resumeCounterpartyDatabase(projectDB *database.ProjectDatabase,
 	if s.shouldStopNormalization() {
 		log.Printf("Normalization stopped before resuming counterparties from %s", projectDB.FilePath)
 		finishedAt := time.Now()
-		s.serviceDB.UpdateNormalizationSession(sessionID, "stopped", &finishedAt)
+		s.updateNormalizationSession(sessionID, "stopped", &finishedAt)
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Нормализация остановлена перед возобновлением БД %s", projectDB.Name):
 		default:
@@ -4173,7 +4106,7 @@ func (s *Server) resumeCounterpartyDatabase(projectDB *database.ProjectDatabase,
 	if s.shouldStopNormalization() {
 		log.Printf("[Counterparty] Normalization stopped before starting (resume) for database %s (project %d)", projectDB.Name, projectID)
 		finishedAt := time.Now()
-		s.serviceDB.UpdateNormalizationSession(sessionID, "stopped", &finishedAt)
+		s.updateNormalizationSession(sessionID, "stopped", &finishedAt)
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Нормализация контрагентов БД %s остановлена пользователем до начала обработки (возобновление)", projectDB.Name):
 		default:
@@ -4198,15 +4131,20 @@ func (s *Server) resumeCounterpartyDatabase(projectDB *database.ProjectDatabase,
 		default:
 		}
 		finishedAt := time.Now()
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", &finishedAt)
+		s.updateNormalizationSession(sessionID, "failed", &finishedAt)
 		return
 	}
 
 	// Запускаем нормализацию контрагентов с пропуском уже нормализованных (skipNormalized = true)
 	result, err := counterpartyNormalizer.ProcessNormalization(counterparties, true)
+	if result != nil && result.PhaseTimings != nil {
+		if saveErr := s.saveSessionPhaseTimings(sessionID, result.PhaseTimings); saveErr != nil {
+			log.Printf("Warning: failed to save phase timings for session %d: %v", sessionID, saveErr)
+		}
+	}
 	if err != nil {
 		// Обновляем сессию как failed
-		s.serviceDB.UpdateNormalizationSession(sessionID, "failed", nil)
+		s.updateNormalizationSession(sessionID, "failed", nil)
 		select {
 		case s.normalizerEvents <- fmt.Sprintf("Ошибка нормализации контрагентов БД %s при возобновлении: %v", projectDB.FilePath, err):
 		default:
@@ -0,0 +1,228 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"httpserver/database"
+)
+
+// Итоги нормализации БД проекта
+const (
+	databaseOutcomeCompleted = "completed"
+	databaseOutcomeFailed    = "failed"
+	databaseOutcomeSkipped   = "skipped"
+	databaseOutcomeStopped   = "stopped"
+	// multiDatabaseStatusPartial часть БД обработана успешно, часть с ошибкой
+	multiDatabaseStatusPartial = "partial"
+)
+
+var (
+	// errDatabaseSkipped возвращается обработчиком БД, если она не обрабатывалась (например, уже есть активная сессия)
+	errDatabaseSkipped = errors.New("database skipped")
+	// errDatabaseStopped возвращается обработчиком БД, если нормализация была остановлена
+	errDatabaseStopped = errors.New("normalization stopped")
+)
+
+// DatabaseNormalizationOutcome итог нормализации одной БД проекта
+type DatabaseNormalizationOutcome struct {
+	DatabaseID   int    `json:"database_id"`
+	DatabaseName string `json:"database_name"`
+	FilePath     string `json:"file_path"`
+	Status       string `json:"status"`
+	Error        string `json:"error,omitempty"`
+	DurationMs   int64  `json:"duration_ms"`
+}
+
+// MultiDatabaseNormalizationResult итог нормализации нескольких БД проекта.
+// Status: completed - все обработанные БД успешны, failed - успешных нет, partial - есть и те и другие,
+// stopped - нормализация остановлена, skipped - ни одна БД не обрабатывалась.
+type MultiDatabaseNormalizationResult struct {
+	ProjectID  int                            `json:"project_id"`
+	Status     string                         `json:"status"`
+	Completed  int                            `json:"completed"`
+	Failed     int                            `json:"failed"`
+	Skipped    int                            `json:"skipped"`
+	Stopped    int                            `json:"stopped"`
+	Databases  []DatabaseNormalizationOutcome `json:"databases"`
+	StartedAt  time.Time                      `json:"started_at"`
+	FinishedAt time.Time                      `json:"finished_at"`
+}
+
+// runDatabasesParallel обрабатывает БД проекта не более чем в maxWorkers горутинах.
+// Каждая БД обрабатывается со своим подключением внутри process: ошибка или паника в одной БД
+// не прерывает остальные. После shouldStop() оставшиеся БД не запускаются и помечаются остановленными.
+// Итоги возвращаются в порядке databases.
+func runDatabasesParallel(projectID int, databases []*database.ProjectDatabase, maxWorkers int, shouldStop func() bool, process func(db *database.ProjectDatabase) error) *MultiDatabaseNormalizationResult {
+	if maxWorkers <= 0 {
+		maxWorkers = 1
+	}
+
+	result := &MultiDatabaseNormalizationResult{
+		ProjectID: projectID,
+		Databases: make([]DatabaseNormalizationOutcome, len(databases)),
+		StartedAt: time.Now(),
+	}
+
+	semaphore := make(chan struct{}, maxWorkers)
+	var wg sync.WaitGroup
+
+	// БД, до которых не дошла очередь из-за остановки, остаются остановленными
+	for i, db := range databases {
+		result.Databases[i] = DatabaseNormalizationOutcome{
+			DatabaseID:   db.ID,
+			DatabaseName: db.Name,
+			FilePath:     db.FilePath,
+			Status:       databaseOutcomeStopped,
+		}
+	}
+
+	for i, db := range databases {
+		semaphore <- struct{}{}
+		// Проверяем остановку после ожидания свободного воркера: за это время нормализацию могли остановить
+		if shouldStop != nil && shouldStop() {
+			<-semaphore
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, db *database.ProjectDatabase) {
+			startTime := time.Now()
+			outcome := &result.Databases[i]
+			defer func() {
+				<-semaphore
+				wg.Done()
+			}()
+			defer func() {
+				if rec := recover(); rec != nil {
+					log.Printf("Panic in normalization goroutine for DB %s: %v\n%s", db.FilePath, rec, debug.Stack())
+					outcome.Status = databaseOutcomeFailed
+					outcome.Error = fmt.Sprintf("panic: %v", rec)
+				}
+				outcome.DurationMs = time.Since(startTime).Milliseconds()
+			}()
+
+			err := process(db)
+			switch {
+			case err == nil:
+				outcome.Status = databaseOutcomeCompleted
+			case errors.Is(err, errDatabaseSkipped):
+				outcome.Status = databaseOutcomeSkipped
+			case errors.Is(err, errDatabaseStopped):
+				outcome.Status = databaseOutcomeStopped
+			default:
+				outcome.Status = databaseOutcomeFailed
+				outcome.Error = err.Error()
+			}
+		}(i, db)
+	}
+
+	wg.Wait()
+	result.FinishedAt = time.Now()
+
+	for _, outcome := range result.Databases {
+		switch outcome.Status {
+		case databaseOutcomeCompleted:
+			result.Completed++
+		case databaseOutcomeFailed:
+			result.Failed++
+		case databaseOutcomeSkipped:
+			result.Skipped++
+		case databaseOutcomeStopped:
+			result.Stopped++
+		}
+	}
+
+	switch {
+	case result.Stopped > 0:
+		result.Status = databaseOutcomeStopped
+	case result.Failed > 0 && result.Completed > 0:
+		result.Status = multiDatabaseStatusPartial
+	case result.Failed > 0:
+		result.Status = databaseOutcomeFailed
+	case result.Completed > 0:
+		result.Status = databaseOutcomeCompleted
+	default:
+		result.Status = databaseOutcomeSkipped
+	}
+
+	return result
+}
+
+// withServiceDBWrite выполняет запись в сервисную БД под общим мьютексом, чтобы параллельные
+// обработчики БД проекта не конкурировали за блокировку записи SQLite
+func (s *Server) withServiceDBWrite(fn func() error) error {
+	s.serviceDBWriteMutex.Lock()
+	defer s.serviceDBWriteMutex.Unlock()
+	return fn()
+}
+
+// updateNormalizationSession обновляет статус сессии нормализации через withServiceDBWrite
+func (s *Server) updateNormalizationSession(sessionID int, status string, finishedAt *time.Time) error {
+	return s.withServiceDBWrite(func() error {
+		return s.serviceDB.UpdateNormalizationSession(sessionID, status, finishedAt)
+	})
+}
+
+// updateSessionActivity обновляет время активности сессии нормализации через withServiceDBWrite
+func (s *Server) updateSessionActivity(sessionID int) error {
+	return s.withServiceDBWrite(func() error {
+		return s.serviceDB.UpdateSessionActivity(sessionID)
+	})
+}
+
+// saveSessionPhaseTimings сохраняет длительности этапов сессии нормализации через withServiceDBWrite
+func (s *Server) saveSessionPhaseTimings(sessionID int, timings map[string]time.Duration) error {
+	return s.withServiceDBWrite(func() error {
+		return s.serviceDB.SaveSessionPhaseTimings(sessionID, timings)
+	})
+}
+
+// updateProjectDatabaseLastUsed обновляет время использования БД проекта через withServiceDBWrite
+func (s *Server) updateProjectDatabaseLastUsed(databaseID int) error {
+	return s.withServiceDBWrite(func() error {
+		return s.serviceDB.UpdateProjectDatabaseLastUsed(databaseID)
+	})
+}
+
+// normalizationSessionOutcome возвращает итог последней сессии нормализации БД, начатой не раньше since
+func (s *Server) normalizationSessionOutcome(databaseID int, since time.Time) error {
+	session, err := s.serviceDB.GetLastNormalizationSession(databaseID)
+	if err != nil {
+		return fmt.Errorf("failed to get normalization session: %w", err)
+	}
+	// Сессия от предыдущего запуска означает, что эта обработка не дошла до создания сессии
+	if session == nil || session.CreatedAt.Before(since.Add(-time.Second)) {
+		return fmt.Errorf("normalization session was not created")
+	}
+
+	switch session.Status {
+	case "failed":
+		return fmt.Errorf("normalization session %d failed", session.ID)
+	case "stopped":
+		return errDatabaseStopped
+	default:
+		return nil
+	}
+}
+
+// setMultiDatabaseResult сохраняет итоги нормализации БД проекта для статуса нормализации и сообщает о них
+func (s *Server) setMultiDatabaseResult(result *MultiDatabaseNormalizationResult) {
+	s.normalizerMutex.Lock()
+	s.lastMultiDatabaseResult = result
+	s.normalizerMutex.Unlock()
+
+	for _, outcome := range result.Databases {
+		if outcome.Status == databaseOutcomeFailed {
+			log.Printf("[Normalization] Database %s failed: %s", outcome.DatabaseName, outcome.Error)
+			select {
+			case s.normalizerEvents <- fmt.Sprintf("Ошибка нормализации БД %s: %s", outcome.DatabaseName, outcome.Error):
+			default:
+			}
+		}
+	}
+}
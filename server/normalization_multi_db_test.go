@@ -0,0 +1,133 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"httpserver/database"
+)
+
+func TestRunDatabasesParallel_FailureDoesNotAbortOthers(t *testing.T) {
+	dir := t.TempDir()
+
+	var databases []*database.ProjectDatabase
+	for i := 1; i <= 4; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("db%d.db", i))
+		db, err := database.NewDB(path)
+		if err != nil {
+			t.Fatalf("Failed to seed database %s: %v", path, err)
+		}
+		db.Close()
+		databases = append(databases, &database.ProjectDatabase{ID: i, Name: fmt.Sprintf("db%d", i), FilePath: path})
+	}
+
+	// Третья БД повреждена: ее обработка завершается ошибкой
+	if err := os.WriteFile(databases[2].FilePath, []byte("not a sqlite database"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var running, maxRunning int32
+	var mu sync.Mutex
+	processed := make(map[int]bool)
+
+	result := runDatabasesParallel(7, databases, 2, func() bool { return false }, func(projectDB *database.ProjectDatabase) error {
+		current := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			observed := atomic.LoadInt32(&maxRunning)
+			if current <= observed || atomic.CompareAndSwapInt32(&maxRunning, observed, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		// Каждая БД открывается своим подключением
+		sourceDB, err := database.NewDB(projectDB.FilePath)
+		if err != nil {
+			return err
+		}
+		defer sourceDB.Close()
+		if _, err := sourceDB.GetAllCatalogItems(); err != nil {
+			return err
+		}
+
+		mu.Lock()
+		processed[projectDB.ID] = true
+		mu.Unlock()
+		return nil
+	})
+
+	if result.ProjectID != 7 {
+		t.Errorf("ProjectID = %d, want 7", result.ProjectID)
+	}
+	if result.Status != multiDatabaseStatusPartial {
+		t.Errorf("Status = %q, want %q", result.Status, multiDatabaseStatusPartial)
+	}
+	if result.Completed != 3 || result.Failed != 1 {
+		t.Errorf("Completed = %d, Failed = %d, want 3 and 1", result.Completed, result.Failed)
+	}
+	if maxRunning > 2 {
+		t.Errorf("Expected at most 2 databases in parallel, got %d", maxRunning)
+	}
+
+	for i, outcome := range result.Databases {
+		if outcome.DatabaseID != databases[i].ID {
+			t.Errorf("Outcome %d is for database %d, want %d", i, outcome.DatabaseID, databases[i].ID)
+		}
+		want := databaseOutcomeCompleted
+		if i == 2 {
+			want = databaseOutcomeFailed
+		}
+		if outcome.Status != want {
+			t.Errorf("Database %s status = %q, want %q (error: %s)", outcome.DatabaseName, outcome.Status, want, outcome.Error)
+		}
+	}
+	if result.Databases[2].Error == "" {
+		t.Error("Expected error message for failed database")
+	}
+	if len(processed) != 3 {
+		t.Errorf("Expected 3 databases processed, got %d", len(processed))
+	}
+}
+
+func TestRunDatabasesParallel_AggregatesStatus(t *testing.T) {
+	databases := []*database.ProjectDatabase{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}, {ID: 3, Name: "c"}}
+
+	result := runDatabasesParallel(1, databases, 3, nil, func(db *database.ProjectDatabase) error {
+		switch db.ID {
+		case 1:
+			panic("boom")
+		case 2:
+			return errDatabaseSkipped
+		default:
+			return fmt.Errorf("broken")
+		}
+	})
+	if result.Status != databaseOutcomeFailed {
+		t.Errorf("Status = %q, want %q", result.Status, databaseOutcomeFailed)
+	}
+	if result.Failed != 2 || result.Skipped != 1 {
+		t.Errorf("Failed = %d, Skipped = %d, want 2 and 1", result.Failed, result.Skipped)
+	}
+
+	// После остановки оставшиеся БД не запускаются
+	calls := 0
+	result = runDatabasesParallel(1, databases, 1, func() bool { return calls > 0 }, func(db *database.ProjectDatabase) error {
+		calls++
+		return nil
+	})
+	if result.Status != databaseOutcomeStopped {
+		t.Errorf("Status = %q, want %q", result.Status, databaseOutcomeStopped)
+	}
+	if result.Completed != 1 || result.Stopped != 2 {
+		t.Errorf("Completed = %d, Stopped = %d, want 1 and 2", result.Completed, result.Stopped)
+	}
+	if result.Databases[2].DatabaseID != 3 {
+		t.Errorf("Stopped database outcome must keep database ID, got %d", result.Databases[2].DatabaseID)
+	}
+}
@@ -0,0 +1,24 @@
+package server
+
+import (
+	"httpserver/server/handlers"
+)
+
+// ListResponse стандартный конверт ответа списковых эндпоинтов (см. handlers.ListResponse)
+type ListResponse[T any] = handlers.ListResponse[T]
+
+// PaginationParams параметры пагинации списка (см. handlers.PaginationParams)
+type PaginationParams = handlers.PaginationParams
+
+// ParsePaginationParams разбирает limit, offset и page из query string с ограничением значений
+var ParsePaginationParams = handlers.ParsePaginationParams
+
+// NewListResponse создает конверт для уже выбранной страницы записей
+func NewListResponse[T any](items []T, total int, params PaginationParams) ListResponse[T] {
+	return handlers.NewListResponse(items, total, params)
+}
+
+// PaginateSlice выбирает страницу из полного списка записей
+func PaginateSlice[T any](items []T, params PaginationParams) ListResponse[T] {
+	return handlers.PaginateSlice(items, params)
+}
@@ -13,6 +13,7 @@ import (
 
 	"httpserver/database"
 	"httpserver/enrichment"
+	"httpserver/importer"
 	"httpserver/internal/config"
 	"httpserver/internal/container"
 	"httpserver/internal/infrastructure/ai"
@@ -48,12 +49,14 @@ type Server struct {
 	db                      *database.DB
 	normalizedDB            *database.DB
 	serviceDB               *database.ServiceDB
+	gostsDB                 *database.GostsDB
 	currentDBPath           string
 	currentNormalizedDBPath string
 	config                  *Config
 	httpServer              *http.Server
 	httpHandler             http.Handler
 	logChan                 chan LogEntry
+	droppedLogEntries       int64 // записи лога, вытесненные из переполненного logChan (atomic)
 	nomenclatureProcessor   *nomenclature.NomenclatureProcessor
 	processorMutex          sync.RWMutex
 	normalizer              *normalization.Normalizer
@@ -67,6 +70,10 @@ type Server struct {
 	normalizerProcessed     int
 	normalizerSuccess       int
 	normalizerErrors        int
+	// Итоги последней нормализации нескольких БД проекта (под normalizerMutex)
+	lastMultiDatabaseResult *MultiDatabaseNormalizationResult
+	// Общий мьютекс записи в сервисную БД для параллельной обработки БД проекта
+	serviceDBWriteMutex sync.Mutex
 	// Context для управления жизненным циклом нормализации
 	normalizerCtx        context.Context
 	normalizerCancel     context.CancelFunc
@@ -160,6 +167,7 @@ type Server struct {
 	errorMetricsHandler           *handlers.ErrorMetricsHandler
 	systemHandler                 *handlers.SystemHandler
 	systemSummaryHandler          *handlers.SystemSummaryHandler
+	systemStatusHandler           *handlers.SystemStatusHandler
 	uploadLegacyHandler           *handlers.UploadLegacyHandler
 	// Мониторинг
 	healthChecker    *servermonitoring.HealthChecker
@@ -228,6 +236,28 @@ func (s *Server) startSessionTimeoutChecker() {
 	}
 }
 
+// startTempCleanup периодически удаляет старые временные файлы импорта (config.TempDir).
+// Файлы неудачных импортов не удаляются: их чистят вручную через db-manager clean-temp --force.
+func (s *Server) startTempCleanup() {
+	tempFiles := importer.NewTempFileManager(s.config.TempDir)
+	ticker := time.NewTicker(s.config.TempCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			result, err := tempFiles.Clean(s.config.TempFileMaxAge, false)
+			if err != nil {
+				s.logErrorf("Error cleaning temp files: %v", err)
+			} else if len(result.Removed) > 0 {
+				log.Printf("Removed %d temp files from %s (%d bytes)", len(result.Removed), tempFiles.Dir(), result.FreedBytes)
+			}
+		case <-s.shutdownChan:
+			return
+		}
+	}
+}
+
 // getOrCreateKpvedTree получает или создает кэшированное дерево КПВЭД
 // Это позволяет переиспользовать дерево для множественных операций, избегая повторных запросов к БД
 func (s *Server) getOrCreateKpvedTree() *normalization.KpvedTree {
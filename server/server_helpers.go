@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"httpserver/database"
@@ -16,11 +17,7 @@ import (
 )
 
 func (s *Server) log(entry LogEntry) {
-	select {
-	case s.logChan <- entry:
-	default:
-		// Если канал полон, пропускаем запись
-	}
+	s.enqueueLog(entry)
 
 	// Форматируем уровень логирования с эмодзи для лучшей читаемости
 	levelIcon := ""
@@ -40,6 +37,42 @@ func (s *Server) log(entry LogEntry) {
 	log.Printf("%s [%s] %s: %s", levelIcon, entry.Level, entry.Timestamp.Format("15:04:05"), entry.Message)
 }
 
+// enqueueLog кладет запись в ограниченный буфер logChan, никогда не блокируя вызывающего.
+// При переполнении из буфера вытесняется самая старая запись, а счетчик потерянных
+// строк увеличивается: без читателя (запуск без GUI) канал просто хранит последние записи.
+func (s *Server) enqueueLog(entry LogEntry) {
+	if s.logChan == nil {
+		return
+	}
+	if cap(s.logChan) == 0 {
+		select {
+		case s.logChan <- entry:
+		default:
+			atomic.AddInt64(&s.droppedLogEntries, 1)
+		}
+		return
+	}
+
+	for {
+		select {
+		case s.logChan <- entry:
+			return
+		default:
+		}
+		// Буфер полон: вытесняем самую старую запись и пробуем снова
+		select {
+		case <-s.logChan:
+			atomic.AddInt64(&s.droppedLogEntries, 1)
+		default:
+		}
+	}
+}
+
+// DroppedLogEntries возвращает число записей лога, вытесненных из переполненного буфера
+func (s *Server) DroppedLogEntries() int64 {
+	return atomic.LoadInt64(&s.droppedLogEntries)
+}
+
 // logError логирует ошибку с уровнем ERROR
 func (s *Server) logError(message string, endpoint string) {
 	s.log(LogEntry{
@@ -70,6 +70,15 @@ func (s *Server) initHandlers() {
 				s.qualityHandler.SetGetProjectDatabases(func(projectID int, activeOnly bool) ([]*database.ProjectDatabase, error) {
 					return s.serviceDB.GetProjectDatabases(projectID, activeOnly)
 				})
+				// Метрики качества хранятся в основной БД, которая может быть переключена во время работы
+				s.qualityHandler.SetGetQualityMetrics(func(databaseIDs []int, since time.Time) ([]database.DataQualityMetric, error) {
+					s.dbMutex.RLock()
+					defer s.dbMutex.RUnlock()
+					if s.db == nil {
+						return nil, fmt.Errorf("main database is not available")
+					}
+					return s.db.GetQualityMetricsForDatabases(databaseIDs, since)
+				})
 			}
 
 			// Устанавливаем кэш для статистики проектов (TTL: 5 минут)
@@ -606,6 +615,12 @@ func (s *Server) initHandlers() {
 		)
 	}
 
+	// Сводный статус системы
+	s.systemStatusHandler = handlers.NewSystemStatusHandler(handlers.NewBaseHandlerFromMiddleware(), s.serviceDB, s.gostsDB, "data/backups")
+	s.systemStatusHandler.SetDatabasesFunc(s.buildSystemStatusDatabasesFunc())
+	s.systemStatusHandler.SetRunningJobsFunc(s.buildRunningJobsFunc())
+	s.systemStatusHandler.SetConfigFunc(func() *Config { return s.config })
+
 	// Инициализируем дефолтные привязки классификаторов к типам проектов
 	s.initDefaultProjectTypeClassifiers()
 }
@@ -780,6 +795,65 @@ func (s *Server) buildMonitoringMetricsFunc() func() handlers.MonitoringData {
 	}
 }
 
+func (s *Server) buildSystemStatusDatabasesFunc() func() []handlers.SystemStatusDatabase {
+	return func() []handlers.SystemStatusDatabase {
+		s.dbMutex.RLock()
+		defer s.dbMutex.RUnlock()
+
+		databases := []handlers.SystemStatusDatabase{
+			{Name: "main", Path: s.currentDBPath},
+			{Name: "normalized", Path: s.currentNormalizedDBPath},
+			{Name: "service"},
+		}
+		if s.db != nil {
+			databases[0].Conn = s.db.GetDB()
+		}
+		if s.normalizedDB != nil {
+			databases[1].Conn = s.normalizedDB.GetDB()
+		}
+		if s.config != nil {
+			databases[2].Path = s.config.ServiceDatabasePath
+		}
+		if s.serviceDB != nil {
+			databases[2].Conn = s.serviceDB.GetDB()
+		}
+		return databases
+	}
+}
+
+func (s *Server) buildRunningJobsFunc() func() []handlers.SystemStatusRunningJob {
+	return func() []handlers.SystemStatusRunningJob {
+		jobs := []handlers.SystemStatusRunningJob{}
+
+		s.normalizerMutex.RLock()
+		if s.normalizerRunning {
+			startTime := s.normalizerStartTime
+			jobs = append(jobs, handlers.SystemStatusRunningJob{
+				Name:      "normalization",
+				StartedAt: &startTime,
+				Details:   fmt.Sprintf("processed %d", s.normalizerProcessed),
+			})
+		}
+		s.normalizerMutex.RUnlock()
+
+		if s.counterpartyService != nil && s.counterpartyService.IsRunning() {
+			jobs = append(jobs, handlers.SystemStatusRunningJob{Name: "counterparty_normalization"})
+		}
+
+		s.kpvedCurrentTasksMutex.RLock()
+		activeWorkers := len(s.kpvedCurrentTasks)
+		s.kpvedCurrentTasksMutex.RUnlock()
+		if activeWorkers > 0 {
+			jobs = append(jobs, handlers.SystemStatusRunningJob{
+				Name:    "kpved_classification",
+				Details: fmt.Sprintf("%d active workers", activeWorkers),
+			})
+		}
+
+		return jobs
+	}
+}
+
 func (s *Server) queryMainDBCount(query string) int {
 	if s.db == nil || s.db.GetDB() == nil {
 		return 0
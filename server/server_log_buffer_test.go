@@ -0,0 +1,46 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestServerLog_DropsOldestWithoutBlocking(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	const bufferSize = 8
+	const total = 1000
+	s := &Server{logChan: make(chan LogEntry, bufferSize)}
+
+	// Читателя нет, как при запуске без GUI: запись в лог не должна блокироваться
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < total; i++ {
+			s.log(LogEntry{Timestamp: time.Now(), Level: "INFO", Message: fmt.Sprintf("line %d", i)})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Producer blocked on full log channel")
+	}
+
+	if got := s.DroppedLogEntries(); got != total-bufferSize {
+		t.Errorf("Expected %d dropped entries, got %d", total-bufferSize, got)
+	}
+	if len(s.logChan) != bufferSize {
+		t.Fatalf("Expected %d buffered entries, got %d", bufferSize, len(s.logChan))
+	}
+	for i := total - bufferSize; i < total; i++ {
+		entry := <-s.logChan
+		if want := fmt.Sprintf("line %d", i); entry.Message != want {
+			t.Errorf("Expected newest entries to be kept, got %q instead of %q", entry.Message, want)
+		}
+	}
+}
@@ -18,6 +18,7 @@ import (
 	infranormalization "httpserver/internal/infrastructure/normalization"
 	"httpserver/internal/infrastructure/workers"
 	"httpserver/nomenclature"
+	"httpserver/normalization"
 	"httpserver/server/handlers"
 	"httpserver/server/services"
 )
@@ -351,6 +352,7 @@ func NewServerWithConfig(db *database.DB, normalizedDB *database.DB, serviceDB *
 		log.Fatalf("Failed to create client service: %v", err)
 	}
 	clientHandler := handlers.NewClientHandler(clientService, baseHandler)
+	clientHandler.SetDocumentsDir(config.ClientDocumentsDir)
 	// Функции будут установлены после создания Server, так как они требуют доступ к методам Server
 
 	// Создаем normalization handler (будет обновлен в Start() с функцией запуска)
@@ -588,6 +590,9 @@ func NewServerWithConfig(db *database.DB, normalizedDB *database.DB, serviceDB *
 		baseHandler,
 	)
 
+	// Стоп-слова для ключевых слов номенклатуры и ГОСТов
+	normalization.SetStopwords(normalization.DefaultStopwordSet(config.Stopwords...))
+
 	// Создаем GOSTs database, service and handler
 	gostsDB, err := database.NewGostsDB("gosts.db")
 	if err != nil {
@@ -598,7 +603,9 @@ func NewServerWithConfig(db *database.DB, normalizedDB *database.DB, serviceDB *
 	var gostService *services.GostService
 	var gostHandler *handlers.GostHandler
 	if gostsDB != nil {
+		gostsDB.SetEncodingOptions(config.GostEncodingOptions())
 		gostService = services.NewGostService(gostsDB)
+		gostService.SetSourcePriority(config.GostSourcePriority)
 		gostHandler = handlers.NewGostHandler(gostService)
 	}
 
@@ -749,6 +756,7 @@ func NewServerWithConfig(db *database.DB, normalizedDB *database.DB, serviceDB *
 		errorMetricsHandler:           errorMetricsHandler,
 		systemHandler:                 systemHandler,
 		systemSummaryHandler:          systemSummaryHandler,
+		gostsDB:                       gostsDB,
 		uploadLegacyHandler:           uploadLegacyHandler,
 		logsHandler:                   container.LogsHandler,
 		healthChecker:                 healthChecker,
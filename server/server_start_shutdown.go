@@ -50,6 +50,9 @@ func (s *Server) Start() error {
 
 	// Запускаем фоновые задачи
 	go s.startSessionTimeoutChecker()
+	if s.config.TempCleanupInterval > 0 {
+		go s.startTempCleanup()
+	}
 
 	// Проверяем и загружаем КПВЭД при необходимости
 	s.ensureKpvedLoaded()
@@ -185,6 +188,13 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		return fmt.Errorf("ошибка остановки сервера: %w", err)
 	}
 
+	// Останавливаем контейнер новой архитектуры: сохраняет кэш веб-поиска и закрывает свои БД
+	if s.cleanContainer != nil {
+		if err := s.cleanContainer.Shutdown(ctx); err != nil {
+			log.Printf("Error shutting down container: %v", err)
+		}
+	}
+
 	log.Println("Graceful shutdown completed")
 	return nil
 }
@@ -245,6 +255,12 @@ func (s *Server) registerGinHandlers(router *gin.Engine) {
 			databasesAPI.GET("/list", s.databaseHandler.HandleDatabasesListGin)
 			databasesAPI.GET("/find", s.databaseHandler.HandleFindDatabaseGin)
 			databasesAPI.GET("/pending", s.databaseHandler.HandlePendingDatabasesGin)
+			databasesAPI.GET("/orphaned-uploads", s.databaseHandler.HandleOrphanedUploadsGin)
+			databasesAPI.POST("/orphaned-uploads/repair", s.databaseHandler.HandleRepairOrphanedUploadsGin)
+			databasesAPI.POST("/:id/repair", s.databaseHandler.HandleRepairDatabaseGin)
+			databasesAPI.POST("/backup/job", s.databaseHandler.HandleStartBackupJobGin)
+			databasesAPI.GET("/backup/job", s.databaseHandler.HandleBackupJobStatusGin)
+			databasesAPI.POST("/backup/job/cancel", s.databaseHandler.HandleCancelBackupJobGin)
 		}
 
 		databaseAPI := api.Group("/database")
@@ -272,6 +288,9 @@ func (s *Server) registerGinHandlers(router *gin.Engine) {
 				s.qualityHandler.HandleQualityStats(c.Writer, c.Request, currentDB, s.currentNormalizedDBPath)
 			})
 		}
+
+		// Метрики качества проекта
+		api.GET("/projects/:id/quality-metrics", s.qualityHandler.HandleProjectQualityMetricsGin)
 	}
 
 	// Dashboard API
@@ -325,7 +344,7 @@ func (s *Server) registerGinHandlers(router *gin.Engine) {
 		{
 			normalizationAPI.GET("/pipeline/stats", httpHandlerToGin(s.normalizationHandler.HandlePipelineStats))
 			normalizationAPI.GET("/pipeline/stage-details", httpHandlerToGin(s.normalizationHandler.HandleStageDetails))
-			normalizationAPI.POST("/start", httpHandlerToGin(s.normalizationHandler.HandleStartVersionedNormalization))
+			normalizationAPI.POST("/start", s.idempotent(), httpHandlerToGin(s.normalizationHandler.HandleStartVersionedNormalization))
 			normalizationAPI.POST("/stop", httpHandlerToGin(s.normalizationHandler.HandleNormalizationStop))
 			normalizationAPI.POST("/apply-patterns", httpHandlerToGin(s.normalizationHandler.HandleApplyPatterns))
 			normalizationAPI.POST("/apply-ai", httpHandlerToGin(s.normalizationHandler.HandleApplyAI))
@@ -372,6 +391,10 @@ func (s *Server) registerGinHandlers(router *gin.Engine) {
 	log.Printf("[registerGinHandlers] Проверка clientHandler: %v", s.clientHandler != nil)
 	if s.clientHandler != nil {
 		log.Printf("[registerGinHandlers] Регистрация Clients API routes")
+
+		// POST /api/projects/:id/benchmarks/import (NDJSON)
+		api.POST("/projects/:id/benchmarks/import", s.clientHandler.HandleImportProjectBenchmarksGin)
+
 		clientsAPI := api.Group("/clients")
 		{
 			// POST /api/clients
@@ -391,6 +414,8 @@ func (s *Server) registerGinHandlers(router *gin.Engine) {
 
 			// GET /api/clients/:clientId/statistics - статистика клиента
 			clientsAPI.GET("/:clientId/statistics", clientIDWrapper(s.clientHandler.GetClientStatistics))
+			// POST /api/clients/:clientId/statistics/recompute - пересчет кэшированной статистики клиента
+			clientsAPI.POST("/:clientId/statistics/recompute", clientIDWrapper(s.clientHandler.RecomputeClientStats))
 			// GET /api/clients/:clientId/nomenclature - номенклатура клиента
 			clientsAPI.GET("/:clientId/nomenclature", clientIDWrapper(s.clientHandler.GetClientNomenclature))
 			// GET /api/clients/:clientId/databases - базы данных клиента
@@ -466,6 +491,8 @@ func (s *Server) registerGinHandlers(router *gin.Engine) {
 					}))
 					// GET /api/clients/:clientId/projects/:projectId/databases (список баз данных проекта)
 					projectDatabasesAPI.GET("", clientProjectIDWrapper(s.clientHandler.GetProjectDatabases))
+					// GET /api/clients/:clientId/projects/:projectId/databases/search (регистрируется до /:databaseId)
+					projectDatabasesAPI.GET("/search", clientProjectIDWrapper(s.clientHandler.SearchProjectDatabases))
 
 					// GET /api/clients/:clientId/projects/:projectId/databases/:databaseId
 					projectDatabasesAPI.GET("/:databaseId", clientProjectDatabaseIDWrapper(s.clientHandler.GetProjectDatabase))
@@ -513,6 +540,33 @@ func (s *Server) registerGinHandlers(router *gin.Engine) {
 					projectBenchmarksAPI.GET("", clientProjectIDWrapper(s.clientHandler.GetProjectBenchmarks))
 					// POST /api/clients/:clientId/projects/:projectId/benchmarks
 					projectBenchmarksAPI.POST("", clientProjectIDWrapper(s.clientHandler.CreateProjectBenchmark))
+					// GET/POST /api/clients/:clientId/projects/:projectId/benchmarks/:benchmarkId/tags
+					projectBenchmarksAPI.GET("/:benchmarkId/tags", s.clientHandler.HandleGetBenchmarkTagsGin)
+					projectBenchmarksAPI.POST("/:benchmarkId/tags", s.clientHandler.HandleAddBenchmarkTagGin)
+					// DELETE /api/clients/:clientId/projects/:projectId/benchmarks/:benchmarkId/tags/:tag
+					projectBenchmarksAPI.DELETE("/:benchmarkId/tags/:tag", s.clientHandler.HandleRemoveBenchmarkTagGin)
+				}
+
+				// GET /api/clients/:clientId/projects/:projectId/normalization-quality
+				clientProjectsAPI.GET("/:projectId/normalization-quality", clientProjectIDWrapper(s.clientHandler.GetNormalizationQuality))
+				// POST /api/clients/:clientId/projects/:projectId/normalization/preview
+				clientProjectsAPI.POST("/:projectId/normalization/preview", clientProjectIDWrapper(s.clientHandler.PreviewCounterpartyNormalization))
+				// GET /api/clients/:clientId/projects/:projectId/gost-references/verify
+				clientProjectsAPI.GET("/:projectId/gost-references/verify", clientProjectIDWrapper(s.handleVerifyProjectTuGostReferences))
+
+				// Обогащение эталонов контрагентов проекта
+				// GET /api/clients/:clientId/projects/:projectId/enrichment/status
+				// POST /api/clients/:clientId/projects/:projectId/enrichment/start
+				// POST /api/clients/:clientId/projects/:projectId/enrichment/stop
+				for _, action := range []string{"status", "start", "stop"} {
+					handler := clientProjectIDWrapper(func(w http.ResponseWriter, r *http.Request, clientID, projectID int) {
+						s.clientHandler.HandleProjectEnrichment(w, r, clientID, projectID, action)
+					})
+					if action == "status" {
+						clientProjectsAPI.GET("/:projectId/enrichment/"+action, handler)
+					} else {
+						clientProjectsAPI.POST("/:projectId/enrichment/"+action, handler)
+					}
 				}
 
 				// Diagnostics для проекта
@@ -562,7 +616,7 @@ func (s *Server) registerGinHandlers(router *gin.Engine) {
 					projectNormalizationAPI := clientProjectsAPI.Group("/:projectId/normalization")
 					{
 						// POST /api/clients/:clientId/projects/:projectId/normalization/start
-						projectNormalizationAPI.POST("/start", clientProjectIDWrapper(func(w http.ResponseWriter, r *http.Request, clientID, projectID int) {
+						projectNormalizationAPI.POST("/start", s.idempotent(), clientProjectIDWrapper(func(w http.ResponseWriter, r *http.Request, clientID, projectID int) {
 							// Добавляем параметры в контекст для использования в handler
 							ctx := context.WithValue(r.Context(), "clientId", clientID)
 							ctx = context.WithValue(ctx, "projectId", projectID)
@@ -690,10 +744,16 @@ func (s *Server) registerGinHandlers(router *gin.Engine) {
 			gostsAPI.GET("/number/:number", s.gostHandler.HandleGetGostByNumber)
 			// GET /api/gosts/search - поиск ГОСТов
 			gostsAPI.GET("/search", s.gostHandler.HandleSearchGosts)
+			// GET /api/gosts/suggest - подсказки ГОСТов по тексту
+			gostsAPI.GET("/suggest", s.gostHandler.HandleSuggestGosts)
+			// POST /api/gosts/extract - извлечение номеров ГОСТов из текста
+			gostsAPI.POST("/extract", s.gostHandler.HandleExtractGosts)
 			// POST /api/gosts/import - импорт ГОСТов
-			gostsAPI.POST("/import", s.gostHandler.HandleImportGosts)
+			gostsAPI.POST("/import", s.idempotent(), s.gostHandler.HandleImportGosts)
 			// GET /api/gosts/statistics - статистика ГОСТов
 			gostsAPI.GET("/statistics", s.gostHandler.HandleGetStatistics)
+			// GET /api/gosts/quality - отчет о качестве данных ГОСТов
+			gostsAPI.GET("/quality", s.gostHandler.HandleGetQualityReport)
 			// GET /api/gosts/export - экспорт ГОСТов в CSV
 			gostsAPI.GET("/export", s.gostHandler.HandleExportGosts)
 			// POST /api/gosts/:id/document - загрузка документа ГОСТа
@@ -721,6 +781,13 @@ func (s *Server) registerGinHandlers(router *gin.Engine) {
 	}
 
 	// System Summary API
+	if s.systemStatusHandler != nil {
+		// GET /api/system/status - сводный статус системы для проверки во время инцидентов
+		api.GET("/system/status", s.systemStatusHandler.HandleSystemStatusGin)
+		// POST /api/system/reindex - перестроение индексов и кэшей после крупных импортов
+		api.POST("/system/reindex", s.systemStatusHandler.HandleSystemReindexGin)
+	}
+
 	if s.systemSummaryHandler != nil {
 		systemSummaryAPI := api.Group("/system/summary")
 		{
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"httpserver/database"
+	apperrors "httpserver/server/errors"
+)
+
+// Состояния фонового создания резервной копии
+const (
+	BackupJobRunning   = "running"
+	BackupJobCompleted = "completed"
+	BackupJobFailed    = "failed"
+	BackupJobCancelled = "cancelled"
+)
+
+// BackupJobRequest параметры фонового создания резервной копии
+type BackupJobRequest struct {
+	IncludeMain    bool     `json:"include_main"`
+	IncludeUploads bool     `json:"include_uploads"`
+	IncludeService bool     `json:"include_service"`
+	SelectedFiles  []string `json:"selected_files"`
+	Format         string   `json:"format"` // "zip", "copy", "both"
+}
+
+// BackupJobStatus состояние фонового создания резервной копии
+type BackupJobStatus struct {
+	Status     string                  `json:"status"`
+	Progress   database.BackupProgress `json:"progress"`
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt *time.Time              `json:"finished_at,omitempty"`
+	Result     map[string]interface{}  `json:"result,omitempty"`
+	Error      string                  `json:"error,omitempty"`
+}
+
+// StartBackupJob запускает создание резервной копии в фоне.
+// Возвращает ошибку конфликта, если предыдущая копия еще создается.
+func (s *DatabaseService) StartBackupJob(req BackupJobRequest) (*BackupJobStatus, error) {
+	s.backupMu.Lock()
+	defer s.backupMu.Unlock()
+
+	if s.backupJob != nil && s.backupJob.Status == BackupJobRunning {
+		return nil, apperrors.NewConflictError("резервная копия уже создается", nil)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.backupJob = &BackupJobStatus{Status: BackupJobRunning, StartedAt: time.Now()}
+	s.backupCancel = cancel
+	job := s.backupJob
+
+	go func() {
+		defer cancel()
+		result, err := s.CreateBackupWithProgress(ctx, req.IncludeMain, req.IncludeUploads, req.IncludeService,
+			req.SelectedFiles, req.Format, func(progress database.BackupProgress) {
+				s.backupMu.Lock()
+				job.Progress = progress
+				s.backupMu.Unlock()
+			})
+
+		s.backupMu.Lock()
+		defer s.backupMu.Unlock()
+		finishedAt := time.Now()
+		job.FinishedAt = &finishedAt
+		switch {
+		case err == nil:
+			job.Status = BackupJobCompleted
+			job.Result = result
+		case errors.Is(err, context.Canceled):
+			job.Status = BackupJobCancelled
+		default:
+			job.Status = BackupJobFailed
+			job.Error = err.Error()
+			slog.Error("[BackupJob] Backup failed", "error", err)
+		}
+		if s.backupJob == job {
+			s.backupCancel = nil
+		}
+	}()
+
+	status := *job
+	return &status, nil
+}
+
+// GetBackupJobStatus возвращает состояние последнего фонового создания резервной копии
+func (s *DatabaseService) GetBackupJobStatus() (*BackupJobStatus, error) {
+	s.backupMu.Lock()
+	defer s.backupMu.Unlock()
+
+	if s.backupJob == nil {
+		return nil, apperrors.NewNotFoundError("резервная копия не создавалась", nil)
+	}
+	status := *s.backupJob
+	return &status, nil
+}
+
+// CancelBackupJob прерывает создание резервной копии. Недописанные файлы удаляются.
+func (s *DatabaseService) CancelBackupJob() error {
+	s.backupMu.Lock()
+	defer s.backupMu.Unlock()
+
+	if s.backupJob == nil || s.backupJob.Status != BackupJobRunning || s.backupCancel == nil {
+		return apperrors.NewConflictError("резервная копия не создается", nil)
+	}
+	s.backupCancel()
+	return nil
+}
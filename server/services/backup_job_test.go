@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"httpserver/database"
+)
+
+// chdirTemp переходит во временный каталог на время теста (пути бэкапов относительные)
+func chdirTemp(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to chdir: %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(oldWd) })
+	return dir
+}
+
+func TestDatabaseService_CreateBackupWithProgress_CancelledRemovesPartialFiles(t *testing.T) {
+	dir := chdirTemp(t)
+	service, _ := setupTestDatabaseService(t)
+
+	sourcePath := filepath.Join(dir, "main.db")
+	if err := os.WriteFile(sourcePath, make([]byte, 3<<20), 0644); err != nil {
+		t.Fatalf("Failed to write source file: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := service.CreateBackupWithProgress(ctx, false, false, false, []string{sourcePath}, "both",
+		func(progress database.BackupProgress) {
+			if progress.BytesDone > 0 {
+				cancel()
+			}
+		})
+	if err == nil {
+		t.Fatal("Expected error for cancelled backup")
+	}
+
+	matches, _ := filepath.Glob(filepath.Join("data", "backups", "*"))
+	if len(matches) != 0 {
+		t.Errorf("Expected no backup files after cancellation, got %v", matches)
+	}
+}
+
+func TestDatabaseService_BackupJob_ReportsProgress(t *testing.T) {
+	dir := chdirTemp(t)
+	service, _ := setupTestDatabaseService(t)
+
+	sourcePath := filepath.Join(dir, "main.db")
+	if err := os.WriteFile(sourcePath, []byte("backup data"), 0644); err != nil {
+		t.Fatalf("Failed to write source file: %v", err)
+	}
+
+	if _, err := service.GetBackupJobStatus(); err == nil {
+		t.Error("Expected error before any backup job")
+	}
+
+	if _, err := service.StartBackupJob(BackupJobRequest{SelectedFiles: []string{sourcePath}, Format: "zip"}); err != nil {
+		t.Fatalf("StartBackupJob failed: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	var status *BackupJobStatus
+	for time.Now().Before(deadline) {
+		var err error
+		status, err = service.GetBackupJobStatus()
+		if err != nil {
+			t.Fatalf("GetBackupJobStatus failed: %v", err)
+		}
+		if status.Status != BackupJobRunning {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if status.Status != BackupJobCompleted {
+		t.Fatalf("Expected completed backup job, got %+v", status)
+	}
+	if status.Progress.FilesDone != 1 || status.Progress.BytesDone != int64(len("backup data")) {
+		t.Errorf("Unexpected progress %+v", status.Progress)
+	}
+	if backupPath, _ := status.Result["backup_path"].(string); backupPath == "" {
+		t.Errorf("Expected backup_path in result, got %v", status.Result)
+	} else if _, err := os.Stat(backupPath); err != nil {
+		t.Errorf("Expected backup file to exist: %v", err)
+	}
+
+	if err := service.CancelBackupJob(); err == nil {
+		t.Error("Expected error cancelling finished backup job")
+	}
+}
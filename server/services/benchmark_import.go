@@ -0,0 +1,146 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"httpserver/database"
+	apperrors "httpserver/server/errors"
+)
+
+const (
+	// BenchmarkImportMaxLineSize максимальный размер одной строки NDJSON при импорте эталонов
+	BenchmarkImportMaxLineSize = 64 * 1024
+	// benchmarkImportBatchSize количество строк, сохраняемых одной транзакцией
+	benchmarkImportBatchSize = 500
+)
+
+// BenchmarkImportLineError ошибка строки NDJSON (строки нумеруются с 1)
+type BenchmarkImportLineError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// BenchmarkImportSummary итог импорта эталонов из NDJSON
+type BenchmarkImportSummary struct {
+	Lines     int                        `json:"lines"` // Прочитано непустых строк
+	Created   int                        `json:"created"`
+	Updated   int                        `json:"updated"`
+	Errors    []BenchmarkImportLineError `json:"errors"`
+	Truncated bool                       `json:"truncated,omitempty"` // Чтение остановлено до конца потока
+}
+
+// ImportProjectBenchmarks импортирует эталоны проекта из потока NDJSON: по одному JSON объекту
+// (database.BenchmarkInput) на строку. Строки проверяются по мере чтения и сохраняются пачками
+// через BulkCreateBenchmarks; ошибки отдельных строк попадают в сводку и не прерывают импорт.
+// Строка длиннее BenchmarkImportMaxLineSize останавливает чтение (Truncated). При ошибке чтения
+// потока уже проверенные строки сохраняются, и вместе с ошибкой возвращается сводка.
+func (s *ClientService) ImportProjectBenchmarks(ctx context.Context, projectID int, body io.Reader) (*BenchmarkImportSummary, error) {
+	if ctx == nil {
+		return nil, apperrors.NewValidationError("context не может быть nil", nil)
+	}
+
+	if s.serviceDB == nil {
+		return nil, apperrors.NewInternalError("сервисная база данных недоступна", nil)
+	}
+
+	if _, err := s.serviceDB.GetClientProject(projectID); err != nil {
+		return nil, apperrors.NewNotFoundError("проект не найден", err)
+	}
+
+	summary := &BenchmarkImportSummary{Errors: []BenchmarkImportLineError{}}
+	batch := make([]database.BenchmarkInput, 0, benchmarkImportBatchSize)
+	batchLines := make([]int, 0, benchmarkImportBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		result, err := s.serviceDB.BulkCreateBenchmarks(projectID, batch)
+		if err != nil {
+			s.logger.Error("Failed to import benchmarks batch", "project_id", projectID, "first_line", batchLines[0], "error", err)
+			return apperrors.NewInternalError(fmt.Sprintf("не удалось сохранить эталоны (строки %d-%d)", batchLines[0], batchLines[len(batchLines)-1]), err)
+		}
+		summary.Created += result.Created
+		summary.Updated += result.Updated
+		batch = batch[:0]
+		batchLines = batchLines[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 4096), BenchmarkImportMaxLineSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if err := ctx.Err(); err != nil {
+			return summary, apperrors.NewConflictError("импорт эталонов отменен", err)
+		}
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		summary.Lines++
+
+		item, err := parseBenchmarkImportLine(text)
+		if err != nil {
+			summary.Errors = append(summary.Errors, BenchmarkImportLineError{Line: line, Error: err.Error()})
+			continue
+		}
+
+		batch = append(batch, item)
+		batchLines = append(batchLines, line)
+		if len(batch) == benchmarkImportBatchSize {
+			if err := flush(); err != nil {
+				return summary, err
+			}
+		}
+	}
+
+	readErr := scanner.Err()
+	if errors.Is(readErr, bufio.ErrTooLong) {
+		summary.Errors = append(summary.Errors, BenchmarkImportLineError{
+			Line:  line + 1,
+			Error: fmt.Sprintf("строка превышает %d байт", BenchmarkImportMaxLineSize),
+		})
+		summary.Truncated = true
+		readErr = nil
+	}
+
+	if err := flush(); err != nil {
+		return summary, err
+	}
+
+	if readErr != nil {
+		summary.Truncated = true
+		return summary, apperrors.NewValidationError("не удалось прочитать тело запроса", readErr)
+	}
+
+	return summary, nil
+}
+
+// parseBenchmarkImportLine разбирает и проверяет одну строку NDJSON
+func parseBenchmarkImportLine(text string) (database.BenchmarkInput, error) {
+	var item database.BenchmarkInput
+	if err := json.Unmarshal([]byte(text), &item); err != nil {
+		return item, fmt.Errorf("неверный JSON: %v", err)
+	}
+
+	item.OriginalName = strings.TrimSpace(item.OriginalName)
+	item.NormalizedName = strings.TrimSpace(item.NormalizedName)
+	item.Category = strings.TrimSpace(item.Category)
+	if item.OriginalName == "" || item.NormalizedName == "" || item.Category == "" {
+		return item, fmt.Errorf("поля 'original_name', 'normalized_name' и 'category' обязательны")
+	}
+	if item.QualityScore < 0 || item.QualityScore > 1 {
+		return item, fmt.Errorf("quality_score должен быть в диапазоне от 0 до 1")
+	}
+
+	return item, nil
+}
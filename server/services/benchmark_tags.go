@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"httpserver/database"
+	apperrors "httpserver/server/errors"
+)
+
+// GetBenchmarkTags возвращает теги эталона проекта клиента в алфавитном порядке
+func (s *ClientService) GetBenchmarkTags(ctx context.Context, clientID, projectID, benchmarkID int) ([]string, error) {
+	if err := s.checkProjectBenchmark(ctx, clientID, projectID, benchmarkID); err != nil {
+		return nil, err
+	}
+
+	return s.benchmarkTags(benchmarkID)
+}
+
+// AddBenchmarkTag добавляет тег эталону проекта клиента и возвращает обновленный список тегов
+func (s *ClientService) AddBenchmarkTag(ctx context.Context, clientID, projectID, benchmarkID int, tag string) ([]string, error) {
+	if err := s.checkProjectBenchmark(ctx, clientID, projectID, benchmarkID); err != nil {
+		return nil, err
+	}
+
+	if err := s.serviceDB.AddBenchmarkTag(benchmarkID, tag); err != nil {
+		if errors.Is(err, database.ErrInvalidBenchmarkTag) {
+			return nil, apperrors.NewValidationError("тег должен быть непустым и не длиннее 64 символов", err)
+		}
+		s.logger.Error("Failed to add benchmark tag", "benchmark_id", benchmarkID, "tag", tag, "error", err)
+		return nil, apperrors.NewInternalError("не удалось добавить тег эталона", err)
+	}
+
+	return s.benchmarkTags(benchmarkID)
+}
+
+// RemoveBenchmarkTag удаляет тег эталона проекта клиента и возвращает обновленный список тегов
+func (s *ClientService) RemoveBenchmarkTag(ctx context.Context, clientID, projectID, benchmarkID int, tag string) ([]string, error) {
+	if err := s.checkProjectBenchmark(ctx, clientID, projectID, benchmarkID); err != nil {
+		return nil, err
+	}
+
+	if err := s.serviceDB.RemoveBenchmarkTag(benchmarkID, tag); err != nil {
+		if errors.Is(err, database.ErrInvalidBenchmarkTag) {
+			return nil, apperrors.NewValidationError("тег должен быть непустым и не длиннее 64 символов", err)
+		}
+		s.logger.Error("Failed to remove benchmark tag", "benchmark_id", benchmarkID, "tag", tag, "error", err)
+		return nil, apperrors.NewInternalError("не удалось удалить тег эталона", err)
+	}
+
+	return s.benchmarkTags(benchmarkID)
+}
+
+// checkProjectBenchmark проверяет, что проект принадлежит клиенту, а эталон - проекту
+func (s *ClientService) checkProjectBenchmark(ctx context.Context, clientID, projectID, benchmarkID int) error {
+	project, err := s.GetClientProject(ctx, clientID, projectID)
+	if err != nil {
+		return err
+	}
+	if project.ClientID != clientID {
+		return apperrors.NewNotFoundError("проект клиента не найден", nil)
+	}
+
+	if benchmarkID <= 0 {
+		return apperrors.NewValidationError("benchmarkID должен быть положительным числом", nil)
+	}
+
+	benchmark, err := s.serviceDB.GetClientBenchmark(benchmarkID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return apperrors.NewNotFoundError("эталон не найден", err)
+		}
+		return apperrors.NewInternalError("не удалось получить эталон", err)
+	}
+	if benchmark.ClientProjectID != projectID {
+		return apperrors.NewNotFoundError("эталон не найден", nil)
+	}
+
+	return nil
+}
+
+// benchmarkTags читает теги эталона без проверки принадлежности
+func (s *ClientService) benchmarkTags(benchmarkID int) ([]string, error) {
+	tags, err := s.serviceDB.GetBenchmarkTags(benchmarkID)
+	if err != nil {
+		s.logger.Error("Failed to get benchmark tags", "benchmark_id", benchmarkID, "error", err)
+		return nil, apperrors.NewInternalError("не удалось получить теги эталона", err)
+	}
+	return tags, nil
+}
@@ -670,9 +670,13 @@ func (cs *ClassificationService) GetOptimizationStats() map[string]interface{} {
 			"max_category_name_len": 50,
 			"enable_logging":        true,
 			"env_variables": map[string]string{
-				"AI_CLASSIFIER_MAX_CATEGORIES": "Максимальное количество категорий (по умолчанию 15)",
-				"AI_CLASSIFIER_MAX_NAME_LEN":   "Максимальная длина названия категории (по умолчанию 50)",
-				"AI_CLASSIFIER_ENABLE_LOGGING": "Включить логирование (true/false, по умолчанию true)",
+				"AI_CLASSIFIER_MAX_CATEGORIES":   "Максимальное количество категорий (по умолчанию 15)",
+				"AI_CLASSIFIER_MAX_NAME_LEN":     "Максимальная длина названия категории (по умолчанию 50)",
+				"AI_CLASSIFIER_ENABLE_LOGGING":   "Включить логирование (true/false, по умолчанию true)",
+				"AI_CLASSIFIER_MAX_RETRIES":      "Повторы запроса при ответе 5xx или пустом ответе (по умолчанию 2)",
+				"AI_CLASSIFIER_STRIP_MARKDOWN":   "Извлекать JSON из markdown и оборванных ответов (true/false, по умолчанию true)",
+				"AI_CLASSIFIER_MAX_BATCH_SIZE":   "Максимальное число позиций в одном пакетном запросе (по умолчанию 20)",
+				"AI_CLASSIFIER_MAX_ALTERNATIVES": "Максимальное число альтернативных категорий в ответе (по умолчанию 3)",
 			},
 		},
 	}
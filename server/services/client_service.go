@@ -366,6 +366,45 @@ func (s *ClientService) DeleteClient(ctx context.Context, clientID int) error {
 	return nil
 }
 
+// RecomputeClientStats пересчитывает кэшированную статистику клиента (количество проектов и эталонов,
+// последняя активность). Используется для обслуживания, если кэш разошелся с данными.
+func (s *ClientService) RecomputeClientStats(ctx context.Context, clientID int) (*database.ClientStats, error) {
+	if ctx == nil {
+		return nil, apperrors.NewValidationError("context не может быть nil", nil)
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, apperrors.NewServiceUnavailableError("контекст отменен", ctx.Err())
+	default:
+	}
+
+	if s.serviceDB == nil {
+		return nil, apperrors.NewInternalError("сервисная база данных недоступна", nil)
+	}
+
+	if clientID <= 0 {
+		return nil, apperrors.NewValidationError("clientID должен быть положительным числом", nil)
+	}
+
+	s.logger.Info("Recomputing client stats", "client_id", clientID)
+
+	if err := s.serviceDB.RecomputeClientStats(clientID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.NewNotFoundError("клиент не найден", err)
+		}
+		s.logger.Error("Failed to recompute client stats", "client_id", clientID, "error", err)
+		return nil, apperrors.NewInternalError("не удалось пересчитать статистику клиента", err)
+	}
+
+	stats, err := s.serviceDB.GetClientStats(clientID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("не удалось получить статистику клиента", err)
+	}
+
+	return stats, nil
+}
+
 func parseLastActivity(value interface{}) *time.Time {
 	switch v := value.(type) {
 	case nil:
@@ -699,6 +738,80 @@ func (s *ClientService) GetProjectDatabases(ctx context.Context, clientID, proje
 	return databases, nil
 }
 
+// SearchProjectDatabases ищет запись по коду или названию во всех активных базах данных проекта.
+// clientID - ID клиента (используется для валидации).
+// limit - максимальное количество результатов (0 - значение по умолчанию).
+func (s *ClientService) SearchProjectDatabases(ctx context.Context, clientID, projectID int, query string, limit int) ([]database.CrossDBHit, error) {
+	if ctx == nil {
+		return nil, apperrors.NewValidationError("context не может быть nil", nil)
+	}
+
+	if s.serviceDB == nil {
+		return nil, apperrors.NewInternalError("сервисная база данных недоступна", nil)
+	}
+
+	if clientID <= 0 {
+		return nil, apperrors.NewValidationError("clientID должен быть положительным числом", nil)
+	}
+
+	if projectID <= 0 {
+		return nil, apperrors.NewValidationError("projectID должен быть положительным числом", nil)
+	}
+
+	if strings.TrimSpace(query) == "" {
+		return nil, apperrors.NewValidationError("параметр поиска не может быть пустым", nil)
+	}
+
+	project, err := s.GetClientProject(ctx, clientID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.ClientID != clientID {
+		return nil, apperrors.NewNotFoundError("проект клиента не найден", nil)
+	}
+
+	s.logger.Info("Searching project databases", "client_id", clientID, "project_id", projectID, "query", query)
+
+	hits, err := s.serviceDB.SearchAcrossProjectDatabasesContext(ctx, projectID, query, limit)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, apperrors.NewServiceUnavailableError("контекст отменен", err)
+		}
+		s.logger.Error("Failed to search project databases", "client_id", clientID, "project_id", projectID, "error", err)
+		return nil, apperrors.NewInternalError("не удалось выполнить поиск по базам данных проекта", err)
+	}
+
+	return hits, nil
+}
+
+// GetNormalizationQuality возвращает сводку уникальности нормализованных названий эталонов проекта.
+// category - категория эталонов (пусто - все категории).
+func (s *ClientService) GetNormalizationQuality(ctx context.Context, clientID, projectID int, category string) (*database.NormalizationQuality, error) {
+	if ctx == nil {
+		return nil, apperrors.NewValidationError("context не может быть nil", nil)
+	}
+
+	if s.serviceDB == nil {
+		return nil, apperrors.NewInternalError("сервисная база данных недоступна", nil)
+	}
+
+	project, err := s.GetClientProject(ctx, clientID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.ClientID != clientID {
+		return nil, apperrors.NewNotFoundError("проект клиента не найден", nil)
+	}
+
+	quality, err := s.serviceDB.GetNormalizationQuality(projectID, category)
+	if err != nil {
+		s.logger.Error("Failed to get normalization quality", "client_id", clientID, "project_id", projectID, "error", err)
+		return nil, apperrors.NewInternalError("не удалось получить качество нормализации", err)
+	}
+
+	return &quality, nil
+}
+
 // GetServiceDB возвращает указатель на serviceDB для прямого доступа (используется в handlers)
 func (s *ClientService) GetServiceDB() *database.ServiceDB {
 	return s.serviceDB
@@ -786,8 +899,11 @@ func (s *ClientService) CreateProjectDatabase(ctx context.Context, clientID, pro
 
 	s.logger.Info("Creating project database", "client_id", clientID, "project_id", projectID, "name", name)
 
-	database, err := s.serviceDB.CreateProjectDatabase(projectID, name, dbPath, description, fileSize)
+	projectDB, err := s.serviceDB.CreateProjectDatabase(projectID, name, dbPath, description, fileSize)
 	if err != nil {
+		if errors.Is(err, database.ErrDuplicate) {
+			return nil, apperrors.NewConflictError("файл базы данных уже зарегистрирован", err)
+		}
 		s.logger.Error("Failed to create project database", "client_id", clientID, "project_id", projectID, "name", name, "error", err)
 		return nil, apperrors.NewInternalError("не удалось создать базу данных проекта", err)
 	}
@@ -796,15 +912,15 @@ func (s *ClientService) CreateProjectDatabase(ctx context.Context, clientID, pro
 	// Выполняем в фоне, чтобы не блокировать создание базы данных
 	go func() {
 		mapper := normalization.NewCounterpartyMapper(s.serviceDB)
-		if err := mapper.MapCounterpartiesFromDatabase(projectID, database.ID); err != nil {
-			s.logger.Warn("Failed to auto-map counterparties for new database", "database_id", database.ID, "error", err)
+		if err := mapper.MapCounterpartiesFromDatabase(projectID, projectDB.ID); err != nil {
+			s.logger.Warn("Failed to auto-map counterparties for new database", "database_id", projectDB.ID, "error", err)
 		} else {
-			s.logger.Info("Successfully auto-mapped counterparties for new database", "database_id", database.ID)
+			s.logger.Info("Successfully auto-mapped counterparties for new database", "database_id", projectDB.ID)
 		}
 	}()
 
-	s.logger.Info("Successfully created project database", "client_id", clientID, "project_id", projectID, "db_id", database.ID, "name", name)
-	return database, nil
+	s.logger.Info("Successfully created project database", "client_id", clientID, "project_id", projectID, "db_id", projectDB.ID, "name", name)
+	return projectDB, nil
 }
 
 // UpdateProjectDatabase обновляет базу данных проекта.
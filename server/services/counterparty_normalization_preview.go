@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+
+	"httpserver/normalization"
+	apperrors "httpserver/server/errors"
+)
+
+// DefaultNormalizationPreviewSampleSize количество предлагаемых изменений в предпросмотре по умолчанию
+const DefaultNormalizationPreviewSampleSize = 50
+
+// CounterpartyNormalizationPreview результат предпросмотра нормализации названий контрагентов:
+// сводка dry run и выборка предлагаемых изменений. В базу данных ничего не записывается.
+type CounterpartyNormalizationPreview struct {
+	Summary      *normalization.CounterpartyNameNormalizationSummary `json:"summary"`
+	Changes      []normalization.CounterpartyNameChange              `json:"changes"`       // первые sampleSize изменений
+	TotalChanges int                                                 `json:"total_changes"` // всего предлагаемых изменений
+}
+
+// PreviewCounterpartyNormalization выполняет NormalizeNamesForProject в режиме dry run
+// и возвращает сводку с первыми sampleSize предлагаемыми изменениями
+// (то же, что показывает normalize_counterparties -dry-run).
+func (s *ClientService) PreviewCounterpartyNormalization(ctx context.Context, clientID, projectID, sampleSize int) (*CounterpartyNormalizationPreview, error) {
+	if ctx == nil {
+		return nil, apperrors.NewValidationError("context не может быть nil", nil)
+	}
+
+	if s.serviceDB == nil {
+		return nil, apperrors.NewInternalError("сервисная база данных недоступна", nil)
+	}
+
+	project, err := s.GetClientProject(ctx, clientID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project.ClientID != clientID {
+		return nil, apperrors.NewNotFoundError("проект клиента не найден", nil)
+	}
+
+	if sampleSize <= 0 {
+		sampleSize = DefaultNormalizationPreviewSampleSize
+	}
+
+	mapper := normalization.NewCounterpartyMapper(s.serviceDB)
+	mapper.SetMaxNameChanges(sampleSize)
+	summary, err := mapper.NormalizeNamesForProject(projectID, true)
+	if err != nil {
+		s.logger.Error("Failed to preview counterparty normalization", "client_id", clientID, "project_id", projectID, "error", err)
+		return nil, apperrors.NewInternalError("не удалось выполнить предпросмотр нормализации", err)
+	}
+
+	preview := &CounterpartyNormalizationPreview{
+		Summary:      summary,
+		Changes:      summary.Changes,
+		TotalChanges: summary.UpdatedRecords,
+	}
+	if preview.Changes == nil {
+		preview.Changes = []normalization.CounterpartyNameChange{}
+	}
+	// Изменения возвращаются отдельным полем, чтобы не дублировать их в сводке
+	summary.Changes = nil
+
+	return preview, nil
+}
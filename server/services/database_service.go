@@ -1,7 +1,7 @@
 package services
 
 import (
-	"archive/zip"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"errors"
@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"httpserver/database"
@@ -28,6 +29,10 @@ type DatabaseService struct {
 	logger       interface{} // Logger - для логирования (опционально)
 	// Callback для обновления БД в Server (опционально)
 	onDBUpdate   func(newDB *database.DB, newPath string) error
+	// Фоновое создание резервной копии (не более одного одновременно)
+	backupMu     sync.Mutex
+	backupJob    *BackupJobStatus
+	backupCancel context.CancelFunc
 }
 
 // NewDatabaseService создает новый сервис для работы с базами данных
@@ -474,6 +479,83 @@ func (s *DatabaseService) CleanupPendingDatabases(olderThanDays int) (int, error
 	return deleted, nil
 }
 
+// FindOrphanedUploads возвращает выгрузки текущей БД, ссылающиеся на удаленные базы данных, клиентов или проекты
+func (s *DatabaseService) FindOrphanedUploads() ([]database.OrphanInfo, error) {
+	if s.serviceDB == nil || s.db == nil {
+		return nil, apperrors.NewInternalError("база данных недоступна", nil)
+	}
+
+	orphans, err := s.serviceDB.FindOrphanedUploads(s.db)
+	if err != nil {
+		return nil, apperrors.NewInternalError("не удалось найти выгрузки с висячими ссылками", err)
+	}
+
+	return orphans, nil
+}
+
+// RepairOrphanedUploads исправляет висячие ссылки выгрузок текущей БД.
+// При reassign client_id/project_id восстанавливаются по существующей базе данных выгрузки.
+func (s *DatabaseService) RepairOrphanedUploads(reassign bool) (*database.OrphanRepairResult, error) {
+	if s.serviceDB == nil || s.db == nil {
+		return nil, apperrors.NewInternalError("база данных недоступна", nil)
+	}
+
+	result, err := s.serviceDB.RepairOrphanedUploads(s.db, reassign)
+	if err != nil {
+		return nil, apperrors.NewInternalError("не удалось исправить выгрузки с висячими ссылками", err)
+	}
+
+	return result, nil
+}
+
+// RepairProjectDatabase восстанавливает цепочку данных базы проекта: создает или привязывает
+// к клиенту и проекту upload записи в файле базы (database.EnsureUploadRecords).
+// Файл базы должен существовать: новая база не создается, данные не удаляются.
+func (s *DatabaseService) RepairProjectDatabase(databaseID int) (*database.UploadRecordsRepairResult, error) {
+	if s.serviceDB == nil {
+		return nil, apperrors.NewInternalError("сервисная база данных недоступна", nil)
+	}
+
+	if databaseID <= 0 {
+		return nil, apperrors.NewValidationError("ID базы данных должен быть положительным числом", nil)
+	}
+
+	projectDB, err := s.serviceDB.GetProjectDatabase(databaseID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("не удалось получить базу данных проекта", err)
+	}
+	if projectDB == nil {
+		return nil, apperrors.NewNotFoundError("база данных не найдена", nil)
+	}
+
+	project, err := s.serviceDB.GetClientProject(projectDB.ClientProjectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, apperrors.NewNotFoundError("проект базы данных не найден", err)
+		}
+		return nil, apperrors.NewInternalError("не удалось получить проект базы данных", err)
+	}
+
+	// NewDB создает отсутствующий файл, поэтому проверяем его заранее
+	info, err := os.Stat(projectDB.FilePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, apperrors.NewNotFoundError(fmt.Sprintf("файл базы данных не найден: %s", projectDB.FilePath), err)
+		}
+		return nil, apperrors.NewInternalError("не удалось проверить файл базы данных", err)
+	}
+	if info.IsDir() {
+		return nil, apperrors.NewValidationError("путь базы данных указывает на каталог", nil)
+	}
+
+	result, err := database.EnsureUploadRecords(projectDB.FilePath, project.ClientID, project.ID, projectDB.ID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("не удалось восстановить выгрузки базы данных", err)
+	}
+
+	return result, nil
+}
+
 // ScanForDatabaseFiles сканирует файловую систему на наличие .db файлов
 func (s *DatabaseService) ScanForDatabaseFiles(paths []string) ([]map[string]interface{}, error) {
 	if s.serviceDB == nil {
@@ -1041,6 +1123,19 @@ func (s *DatabaseService) BulkDeleteDatabases(paths []string, ids []int) (map[st
 
 // CreateBackup создает резервную копию баз данных
 func (s *DatabaseService) CreateBackup(includeMain, includeUploads, includeService bool, selectedFiles []string, format string) (map[string]interface{}, error) {
+	return s.CreateBackupWithProgress(context.Background(), includeMain, includeUploads, includeService, selectedFiles, format, nil)
+}
+
+// CreateBackupWithProgress создает резервную копию баз данных с возможностью отмены через ctx.
+// progress (может быть nil) получает количество обработанных файлов и байт. При отмене или ошибке
+// недописанный ZIP архив и каталог с копиями файлов удаляются.
+func (s *DatabaseService) CreateBackupWithProgress(
+	ctx context.Context,
+	includeMain, includeUploads, includeService bool,
+	selectedFiles []string,
+	format string,
+	progress func(database.BackupProgress),
+) (map[string]interface{}, error) {
 	// Нормализуем формат
 	if format != "zip" && format != "copy" && format != "both" {
 		format = "both"
@@ -1055,36 +1150,6 @@ func (s *DatabaseService) CreateBackup(includeMain, includeUploads, includeServi
 	// Генерируем timestamp для имени бэкапа
 	timestamp := time.Now().Format("20060102_150405")
 
-	var zipFile *os.File
-	var zipWriter *zip.Writer
-	var backupFileName string
-	var backupPath string
-	var filesCopyDir string
-
-	// Создаем ZIP архив, если нужно
-	if format == "zip" || format == "both" {
-		backupFileName = fmt.Sprintf("backup_%s.zip", timestamp)
-		backupPath = filepath.Join(backupDir, backupFileName)
-
-		var err error
-		zipFile, err = os.Create(backupPath)
-		if err != nil {
-			return nil, apperrors.NewInternalError("не удалось создать файл резервной копии", err)
-		}
-		defer zipFile.Close()
-
-		zipWriter = zip.NewWriter(zipFile)
-		defer zipWriter.Close()
-	}
-
-	// Создаем директорию для копий файлов, если нужно
-	if format == "copy" || format == "both" {
-		filesCopyDir = filepath.Join(backupDir, "files", timestamp)
-		if err := os.MkdirAll(filesCopyDir, 0755); err != nil {
-			return nil, apperrors.NewInternalError("не удалось создать директорию для копий файлов", err)
-		}
-	}
-
 	// Собираем файлы для бэкапа
 	filesToBackup := []string{}
 
@@ -1133,10 +1198,11 @@ func (s *DatabaseService) CreateBackup(includeMain, includeUploads, includeServi
 		}
 	}
 
-	// Добавляем файлы в архив и/или копируем их
-	addedFiles := 0
-	totalSize := int64(0)
+	if len(filesToBackup) == 0 {
+		return nil, apperrors.NewValidationError("не найдено файлов для резервного копирования", nil)
+	}
 
+	entries := make([]database.BackupEntry, 0, len(filesToBackup))
 	for _, filePath := range filesToBackup {
 		// Определяем путь в архиве
 		var archivePath string
@@ -1149,103 +1215,49 @@ func (s *DatabaseService) CreateBackup(includeMain, includeUploads, includeServi
 		} else {
 			archivePath = filepath.Join("main", fileName)
 		}
+		entries = append(entries, database.BackupEntry{SourcePath: filePath, ArchivePath: archivePath})
+	}
 
-		// Открываем файл для чтения
-		sourceFile, err := os.Open(filePath)
-		if err != nil {
-			slog.Warn("[CreateBackup] Failed to open file",
-				"path", filePath,
-				"error", err,
-			)
-			continue
-		}
+	backupInfo := map[string]interface{}{
+		"format": format,
+	}
+	addedFiles := 0
+	totalSize := int64(0)
 
-		fileInfo, err := sourceFile.Stat()
+	// Создаем ZIP архив, если нужно
+	if format == "zip" || format == "both" {
+		backupFileName := fmt.Sprintf("backup_%s.zip", timestamp)
+		backupPath := filepath.Join(backupDir, backupFileName)
+
+		result, err := database.WriteBackupArchive(ctx, backupPath, entries, progress)
 		if err != nil {
-			sourceFile.Close()
-			continue
+			return nil, backupError(err, "не удалось создать архив резервной копии")
 		}
-
-		// Если нужно добавить в ZIP архив
-		if zipWriter != nil {
-			// Создаем запись в архиве
-			archiveFile, err := zipWriter.Create(archivePath)
-			if err != nil {
-				slog.Warn("[CreateBackup] Failed to create archive entry",
-					"path", filePath,
-					"error", err,
-				)
-				sourceFile.Close()
-				continue
-			}
-
-			// Копируем содержимое файла в архив
-			if _, err := io.Copy(archiveFile, sourceFile); err != nil {
-				slog.Warn("[CreateBackup] Failed to copy file to archive",
-					"path", filePath,
-					"error", err,
-				)
-				sourceFile.Close()
-				continue
-			}
+		for _, skipped := range result.Skipped {
+			slog.Warn("[CreateBackup] Failed to open file", "path", skipped)
 		}
 
-		// Если нужно скопировать файлы
-		if filesCopyDir != "" {
-			destPath := filepath.Join(filesCopyDir, archivePath)
-			destDir := filepath.Dir(destPath)
-			if err := os.MkdirAll(destDir, 0755); err != nil {
-				slog.Warn("[CreateBackup] Failed to create directory",
-					"dir", destDir,
-					"error", err,
-				)
-				sourceFile.Close()
-				continue
-			}
-
-			// Сбрасываем позицию файла для копирования
-			if _, err := sourceFile.Seek(0, 0); err != nil {
-				slog.Warn("[CreateBackup] Failed to seek file",
-					"path", filePath,
-					"error", err,
-				)
-				sourceFile.Close()
-				continue
-			}
-
-			destFile, err := os.Create(destPath)
-			if err != nil {
-				slog.Warn("[CreateBackup] Failed to create destination file",
-					"path", destPath,
-					"error", err,
-				)
-				sourceFile.Close()
-				continue
-			}
+		addedFiles, totalSize = result.FilesCount, result.TotalSize
+		backupInfo["backup_file"] = backupFileName
+		backupInfo["backup_path"] = backupPath
+	}
 
-			if _, err := io.Copy(destFile, sourceFile); err != nil {
-				slog.Warn("[CreateBackup] Failed to copy file",
-					"source", filePath,
-					"dest", destPath,
-					"error", err,
-				)
-				sourceFile.Close()
-				destFile.Close()
-				continue
+	// Копируем файлы, если нужно
+	if format == "copy" || format == "both" {
+		filesCopyDir := filepath.Join(backupDir, "files", timestamp)
+		copied, copiedSize, err := copyBackupFiles(ctx, filesCopyDir, entries, progress)
+		if err != nil {
+			os.RemoveAll(filesCopyDir)
+			if zipPath, ok := backupInfo["backup_path"].(string); ok {
+				os.Remove(zipPath)
 			}
-			destFile.Close()
+			return nil, backupError(err, "не удалось скопировать файлы резервной копии")
 		}
 
-		sourceFile.Close()
-		addedFiles++
-		totalSize += fileInfo.Size()
-	}
-
-	// Закрываем архив, если он был создан
-	if zipWriter != nil {
-		if err := zipWriter.Close(); err != nil {
-			return nil, apperrors.NewInternalError("не удалось завершить создание архива", err)
+		if addedFiles == 0 {
+			addedFiles, totalSize = copied, copiedSize
 		}
+		backupInfo["files_copy_dir"] = filesCopyDir
 	}
 
 	// Проверяем, что были добавлены файлы
@@ -1253,23 +1265,9 @@ func (s *DatabaseService) CreateBackup(includeMain, includeUploads, includeServi
 		return nil, apperrors.NewValidationError("не найдено файлов для резервного копирования", nil)
 	}
 
-	backupInfo := map[string]interface{}{
-		"files_count": addedFiles,
-		"total_size":  totalSize,
-		"created_at":  time.Now().Format(time.RFC3339),
-		"format":      format,
-	}
-
-	// Добавляем информацию о ZIP архиве, если он был создан
-	if backupFileName != "" {
-		backupInfo["backup_file"] = backupFileName
-		backupInfo["backup_path"] = backupPath
-	}
-
-	// Добавляем информацию о директории с копиями файлов, если она была создана
-	if filesCopyDir != "" {
-		backupInfo["files_copy_dir"] = filesCopyDir
-	}
+	backupInfo["files_count"] = addedFiles
+	backupInfo["total_size"] = totalSize
+	backupInfo["created_at"] = time.Now().Format(time.RFC3339)
 
 	slog.Info("[CreateBackup] Successfully created backup",
 		"files_count", addedFiles,
@@ -1280,6 +1278,77 @@ func (s *DatabaseService) CreateBackup(includeMain, includeUploads, includeServi
 	return backupInfo, nil
 }
 
+// copyBackupFiles копирует файлы резервной копии в каталог copyDir, сохраняя пути из архива.
+// Файлы, которые не удалось открыть или скопировать, пропускаются; отмена ctx прерывает копирование.
+func copyBackupFiles(ctx context.Context, copyDir string, entries []database.BackupEntry, progress func(database.BackupProgress)) (int, int64, error) {
+	state := database.BackupProgress{FilesTotal: len(entries)}
+	for _, entry := range entries {
+		if info, err := os.Stat(entry.SourcePath); err == nil {
+			state.BytesTotal += info.Size()
+		}
+	}
+
+	copied := 0
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return 0, 0, err
+		}
+		state.CurrentFile = entry.SourcePath
+
+		destPath := filepath.Join(copyDir, entry.ArchivePath)
+		if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+			return 0, 0, fmt.Errorf("failed to create directory %s: %w", filepath.Dir(destPath), err)
+		}
+
+		size, err := copyBackupFile(entry.SourcePath, destPath)
+		if err != nil {
+			slog.Warn("[CreateBackup] Failed to copy file",
+				"source", entry.SourcePath,
+				"dest", destPath,
+				"error", err,
+			)
+		} else {
+			copied++
+			state.BytesDone += size
+		}
+
+		state.FilesDone++
+		if progress != nil {
+			progress(state)
+		}
+	}
+
+	return copied, state.BytesDone, nil
+}
+
+// copyBackupFile копирует один файл и возвращает количество скопированных байт
+func copyBackupFile(sourcePath, destPath string) (int64, error) {
+	sourceFile, err := os.Open(sourcePath)
+	if err != nil {
+		return 0, err
+	}
+	defer sourceFile.Close()
+
+	destFile, err := os.Create(destPath)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := io.Copy(destFile, sourceFile)
+	if closeErr := destFile.Close(); err == nil {
+		err = closeErr
+	}
+	return size, err
+}
+
+// backupError преобразует ошибку создания резервной копии: отмена контекста не считается внутренней ошибкой
+func backupError(err error, message string) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return apperrors.NewConflictError("создание резервной копии отменено", err)
+	}
+	return apperrors.NewInternalError(message, err)
+}
+
 // DownloadBackup возвращает путь к файлу резервной копии для скачивания
 func (s *DatabaseService) DownloadBackup(backupDir, filename string) (string, error) {
 	// Безопасность: проверяем, что имя файла не содержит переходов
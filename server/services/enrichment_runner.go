@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"httpserver/database"
+	"httpserver/enrichment"
+	apperrors "httpserver/server/errors"
+)
+
+// enrichmentRunnerBatchSize количество эталонов, читаемых из БД за один запрос
+const enrichmentRunnerBatchSize = 100
+
+// EnrichmentProvider источник сервисов обогащения (реализуется *enrichment.EnricherFactory)
+type EnrichmentProvider interface {
+	GetEnrichers(inn, bin string) []enrichment.Enricher
+	GetBestResult(results []*enrichment.EnrichmentResult) *enrichment.EnrichmentResult
+}
+
+// EnrichmentRunStatus состояние обогащения проекта
+type EnrichmentRunStatus struct {
+	ProjectID  int                            `json:"project_id"`
+	Running    bool                           `json:"running"`
+	Checkpoint *database.EnrichmentCheckpoint `json:"checkpoint,omitempty"`
+}
+
+// EnrichmentRunner обогащает эталоны контрагентов проекта через внешние сервисы.
+// Запросы к каждому сервису ограничиваются rate.Limiter по EnricherConfig.MaxRequests (в минуту),
+// прогресс сохраняется в enrichment_checkpoints после каждого эталона, поэтому прерванный прогон
+// продолжается со следующего необработанного эталона. Эталоны с quality_score не ниже
+// minQualityScore пропускаются.
+type EnrichmentRunner struct {
+	serviceDB       *database.ServiceDB
+	provider        EnrichmentProvider
+	services        map[string]*enrichment.EnricherConfig
+	minQualityScore float64
+	logger          *slog.Logger
+
+	limitersMu sync.Mutex
+	limiters   map[string]*rate.Limiter
+
+	runningMu sync.Mutex
+	running   map[int]context.CancelFunc
+}
+
+// NewEnrichmentRunner создает runner обогащения. services - конфигурация сервисов (Enrichment.Services),
+// minQualityScore - Enrichment.MinQualityScore.
+func NewEnrichmentRunner(
+	serviceDB *database.ServiceDB,
+	provider EnrichmentProvider,
+	services map[string]*enrichment.EnricherConfig,
+	minQualityScore float64,
+) *EnrichmentRunner {
+	return &EnrichmentRunner{
+		serviceDB:       serviceDB,
+		provider:        provider,
+		services:        services,
+		minQualityScore: minQualityScore,
+		logger:          slog.Default(),
+		limiters:        make(map[string]*rate.Limiter),
+		running:         make(map[int]context.CancelFunc),
+	}
+}
+
+// Start запускает (или продолжает) обогащение проекта в фоне
+func (r *EnrichmentRunner) Start(projectID int) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := r.register(projectID, cancel); err != nil {
+		cancel()
+		return err
+	}
+
+	go func() {
+		defer r.unregister(projectID)
+		defer cancel()
+		if _, err := r.run(ctx, projectID); err != nil && !errors.Is(err, context.Canceled) {
+			r.logger.Error("Enrichment run failed", "project_id", projectID, "error", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop прерывает обогащение проекта. Возвращает false, если обогащение не выполняется.
+func (r *EnrichmentRunner) Stop(projectID int) bool {
+	r.runningMu.Lock()
+	defer r.runningMu.Unlock()
+
+	cancel, ok := r.running[projectID]
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// Status возвращает состояние обогащения проекта
+func (r *EnrichmentRunner) Status(projectID int) (*EnrichmentRunStatus, error) {
+	checkpoint, err := r.serviceDB.GetEnrichmentCheckpoint(projectID)
+	if err != nil {
+		return nil, apperrors.NewInternalError("не удалось получить состояние обогащения", err)
+	}
+
+	r.runningMu.Lock()
+	_, running := r.running[projectID]
+	r.runningMu.Unlock()
+
+	return &EnrichmentRunStatus{ProjectID: projectID, Running: running, Checkpoint: checkpoint}, nil
+}
+
+// Run синхронно обогащает эталоны проекта, продолжая с последней контрольной точки.
+// При отмене ctx контрольная точка сохраняется со статусом interrupted и возвращается ошибка ctx.
+func (r *EnrichmentRunner) Run(ctx context.Context, projectID int) (*database.EnrichmentCheckpoint, error) {
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err := r.register(projectID, cancel); err != nil {
+		return nil, err
+	}
+	defer r.unregister(projectID)
+
+	return r.run(runCtx, projectID)
+}
+
+func (r *EnrichmentRunner) register(projectID int, cancel context.CancelFunc) error {
+	r.runningMu.Lock()
+	defer r.runningMu.Unlock()
+
+	if _, ok := r.running[projectID]; ok {
+		return apperrors.NewConflictError("обогащение проекта уже выполняется", nil)
+	}
+	r.running[projectID] = cancel
+	return nil
+}
+
+func (r *EnrichmentRunner) unregister(projectID int) {
+	r.runningMu.Lock()
+	defer r.runningMu.Unlock()
+	delete(r.running, projectID)
+}
+
+func (r *EnrichmentRunner) run(ctx context.Context, projectID int) (*database.EnrichmentCheckpoint, error) {
+	checkpoint, err := r.serviceDB.GetEnrichmentCheckpoint(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if checkpoint == nil || checkpoint.Status == database.EnrichmentRunCompleted {
+		// Завершенный прогон повторяется с начала
+		checkpoint = &database.EnrichmentCheckpoint{ProjectID: projectID}
+	}
+	checkpoint.Status = database.EnrichmentRunRunning
+	checkpoint.LastError = ""
+	checkpoint.CompletedAt = nil
+	if err := r.serviceDB.SaveEnrichmentCheckpoint(checkpoint); err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("Enrichment run started", "project_id", projectID, "resume_after", checkpoint.LastBenchmarkID)
+
+	for {
+		benchmarks, err := r.serviceDB.GetCounterpartyBenchmarksAfter(projectID, checkpoint.LastBenchmarkID, enrichmentRunnerBatchSize)
+		if err != nil {
+			return checkpoint, r.finish(checkpoint, database.EnrichmentRunFailed, err)
+		}
+		if len(benchmarks) == 0 {
+			break
+		}
+
+		for _, benchmark := range benchmarks {
+			if err := ctx.Err(); err != nil {
+				return checkpoint, r.finish(checkpoint, database.EnrichmentRunInterrupted, err)
+			}
+
+			if err := r.enrichBenchmark(ctx, benchmark, checkpoint); err != nil {
+				// Эталон не обработан до конца из-за отмены - он будет обработан при продолжении
+				return checkpoint, r.finish(checkpoint, database.EnrichmentRunInterrupted, err)
+			}
+
+			checkpoint.LastBenchmarkID = benchmark.ID
+			checkpoint.Processed++
+			if err := r.serviceDB.SaveEnrichmentCheckpoint(checkpoint); err != nil {
+				return checkpoint, err
+			}
+		}
+	}
+
+	now := time.Now()
+	checkpoint.CompletedAt = &now
+	if err := r.finish(checkpoint, database.EnrichmentRunCompleted, nil); err != nil {
+		return checkpoint, err
+	}
+
+	r.logger.Info("Enrichment run completed", "project_id", projectID,
+		"processed", checkpoint.Processed, "enriched", checkpoint.Enriched,
+		"skipped", checkpoint.Skipped, "failed", checkpoint.Failed)
+	return checkpoint, nil
+}
+
+// finish сохраняет итоговый статус прогона и возвращает исходную ошибку cause
+func (r *EnrichmentRunner) finish(checkpoint *database.EnrichmentCheckpoint, status string, cause error) error {
+	checkpoint.Status = status
+	if cause != nil {
+		checkpoint.LastError = cause.Error()
+	}
+	if err := r.serviceDB.SaveEnrichmentCheckpoint(checkpoint); err != nil {
+		if cause != nil {
+			return fmt.Errorf("%w (failed to save checkpoint: %v)", cause, err)
+		}
+		return err
+	}
+	return cause
+}
+
+// enrichBenchmark обогащает один эталон и обновляет счетчики контрольной точки.
+// Ошибка возвращается только при отмене ctx; ошибки сервисов учитываются как failed.
+func (r *EnrichmentRunner) enrichBenchmark(ctx context.Context, benchmark *database.ClientBenchmark, checkpoint *database.EnrichmentCheckpoint) error {
+	// tax_id эталона контрагента может содержать как ИНН, так и БИН
+	taxID := strings.TrimSpace(benchmark.TaxID)
+	if benchmark.QualityScore >= r.minQualityScore || taxID == "" {
+		checkpoint.Skipped++
+		return nil
+	}
+
+	var results []*enrichment.EnrichmentResult
+	var serviceErrors []string
+	for _, enricher := range r.provider.GetEnrichers(taxID, taxID) {
+		if err := r.limiter(enricher.GetName()).Wait(ctx); err != nil {
+			return err
+		}
+
+		result, err := enricher.Enrich(taxID, taxID)
+		if err != nil {
+			serviceErrors = append(serviceErrors, fmt.Sprintf("%s: %v", enricher.GetName(), err))
+			continue
+		}
+		results = append(results, result)
+
+		// Как и EnricherFactory.Enrich, останавливаемся на результате с высокой уверенностью
+		if result.Success && result.Confidence >= 0.8 {
+			break
+		}
+	}
+
+	best := r.provider.GetBestResult(results)
+	if best == nil {
+		checkpoint.Failed++
+		checkpoint.LastError = fmt.Sprintf("benchmark %d: no enrichment results %v", benchmark.ID, serviceErrors)
+		return nil
+	}
+
+	err := r.serviceDB.ApplyBenchmarkEnrichment(benchmark.ID, database.BenchmarkEnrichment{
+		NormalizedName: best.FullName,
+		KPP:            best.KPP,
+		OGRN:           best.OGRN,
+		LegalAddress:   best.LegalAddress,
+		ContactPhone:   best.Phone,
+		ContactEmail:   best.Email,
+		BankName:       best.BankName,
+		BankAccount:    best.BankAccount,
+		BIK:            best.BankBIC,
+		QualityScore:   best.Confidence,
+	})
+	if err != nil {
+		checkpoint.Failed++
+		checkpoint.LastError = fmt.Sprintf("benchmark %d: %v", benchmark.ID, err)
+		return nil
+	}
+
+	checkpoint.Enriched++
+	return nil
+}
+
+// limiter возвращает ограничитель запросов сервиса (EnricherConfig.MaxRequests в минуту, 0 - без ограничения)
+func (r *EnrichmentRunner) limiter(service string) *rate.Limiter {
+	r.limitersMu.Lock()
+	defer r.limitersMu.Unlock()
+
+	if limiter, ok := r.limiters[service]; ok {
+		return limiter
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 0)
+	if cfg, ok := r.services[service]; ok && cfg != nil && cfg.MaxRequests > 0 {
+		limiter = rate.NewLimiter(rate.Every(time.Minute/time.Duration(cfg.MaxRequests)), 1)
+	}
+	r.limiters[service] = limiter
+	return limiter
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"httpserver/database"
+	"httpserver/enrichment"
+)
+
+// fakeEnricher обогатитель, считающий вызовы по ИНН и отменяющий контекст после cancelAfter вызовов
+type fakeEnricher struct {
+	calls       map[string]int
+	cancelAfter int
+	cancel      context.CancelFunc
+}
+
+func (f *fakeEnricher) Enrich(inn, bin string) (*enrichment.EnrichmentResult, error) {
+	f.calls[inn]++
+	total := 0
+	for _, n := range f.calls {
+		total += n
+	}
+	if f.cancel != nil && total == f.cancelAfter {
+		f.cancel()
+	}
+	return &enrichment.EnrichmentResult{
+		Source:     "fake",
+		Success:    true,
+		INN:        inn,
+		FullName:   "ООО Обогащенный " + inn,
+		Confidence: 0.9,
+	}, nil
+}
+
+func (f *fakeEnricher) Supports(inn, bin string) bool { return true }
+func (f *fakeEnricher) GetName() string               { return "fake" }
+func (f *fakeEnricher) GetPriority() int              { return 1 }
+func (f *fakeEnricher) IsAvailable() bool             { return true }
+
+// fakeEnrichmentProvider возвращает единственный fakeEnricher
+type fakeEnrichmentProvider struct {
+	enricher *fakeEnricher
+}
+
+func (p *fakeEnrichmentProvider) GetEnrichers(inn, bin string) []enrichment.Enricher {
+	return []enrichment.Enricher{p.enricher}
+}
+
+func (p *fakeEnrichmentProvider) GetBestResult(results []*enrichment.EnrichmentResult) *enrichment.EnrichmentResult {
+	if len(results) == 0 {
+		return nil
+	}
+	return results[0]
+}
+
+func TestEnrichmentRunner_ResumesAfterInterruption(t *testing.T) {
+	serviceDB, err := database.NewServiceDB(filepath.Join(t.TempDir(), "service.db"))
+	if err != nil {
+		t.Fatalf("Failed to create service DB: %v", err)
+	}
+	defer serviceDB.Close()
+
+	client, err := serviceDB.CreateClient("Клиент", "ООО «Клиент»", "", "", "", "")
+	if err != nil {
+		t.Fatalf("CreateClient failed: %v", err)
+	}
+	project, err := serviceDB.CreateClientProject(client.ID, "Контрагенты", "counterparty", "", "", 0.9)
+	if err != nil {
+		t.Fatalf("CreateClientProject failed: %v", err)
+	}
+
+	// Пять эталонов с низким качеством и один уже качественный
+	taxIDs := []string{"7707083893", "7728168971", "7702070139", "7736050003", "7710140679"}
+	for _, taxID := range taxIDs {
+		if _, err := serviceDB.CreateCounterpartyBenchmark(project.ID, "Контрагент "+taxID, "контрагент "+taxID,
+			taxID, "", "", "", "", "", "", "", "", "", "", "", "", "", "", 0.1); err != nil {
+			t.Fatalf("CreateCounterpartyBenchmark failed: %v", err)
+		}
+	}
+	if _, err := serviceDB.CreateCounterpartyBenchmark(project.ID, "Качественный", "качественный",
+		"7704217370", "", "", "", "", "", "", "", "", "", "", "", "", "", "", 0.95); err != nil {
+		t.Fatalf("CreateCounterpartyBenchmark failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	enricher := &fakeEnricher{calls: make(map[string]int), cancelAfter: 2, cancel: cancel}
+	runner := NewEnrichmentRunner(serviceDB, &fakeEnrichmentProvider{enricher: enricher},
+		map[string]*enrichment.EnricherConfig{"fake": {MaxRequests: 6000}}, 0.5)
+
+	// Первый прогон прерывается после второго обращения к сервису
+	checkpoint, err := runner.Run(ctx, project.ID)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if checkpoint.Status != database.EnrichmentRunInterrupted || checkpoint.Enriched != 2 {
+		t.Fatalf("Unexpected checkpoint after interruption: %+v", checkpoint)
+	}
+
+	saved, err := runner.Status(project.ID)
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if saved.Running || saved.Checkpoint == nil || saved.Checkpoint.LastBenchmarkID != checkpoint.LastBenchmarkID {
+		t.Fatalf("Unexpected saved status: %+v", saved)
+	}
+
+	// Продолжение обрабатывает только оставшиеся эталоны
+	enricher.cancel = nil
+	checkpoint, err = runner.Run(context.Background(), project.ID)
+	if err != nil {
+		t.Fatalf("Resumed run failed: %v", err)
+	}
+	if checkpoint.Status != database.EnrichmentRunCompleted {
+		t.Errorf("Expected completed status, got %q", checkpoint.Status)
+	}
+	if checkpoint.Processed != 6 || checkpoint.Enriched != 5 || checkpoint.Skipped != 1 || checkpoint.Failed != 0 {
+		t.Errorf("Unexpected counters: %+v", checkpoint)
+	}
+
+	for _, taxID := range taxIDs {
+		if enricher.calls[taxID] != 1 {
+			t.Errorf("Benchmark %s enriched %d times, want 1", taxID, enricher.calls[taxID])
+		}
+	}
+	if enricher.calls["7704217370"] != 0 {
+		t.Error("Benchmark above MinQualityScore should be skipped")
+	}
+
+	benchmarks, err := serviceDB.GetCounterpartyBenchmarksAfter(project.ID, 0, 10)
+	if err != nil || len(benchmarks) != 6 {
+		t.Fatalf("GetCounterpartyBenchmarksAfter failed: %v (%d benchmarks)", err, len(benchmarks))
+	}
+	benchmark := benchmarks[4]
+	if benchmark.NormalizedName != "ООО Обогащенный "+taxIDs[4] || benchmark.QualityScore != 0.9 {
+		t.Errorf("Enrichment not applied: name=%q quality=%v", benchmark.NormalizedName, benchmark.QualityScore)
+	}
+}
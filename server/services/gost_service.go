@@ -1,8 +1,8 @@
 package services
 
 import (
-	"fmt"
 	"io"
+	"strings"
 	"time"
 
 	"httpserver/database"
@@ -12,16 +12,27 @@ import (
 
 // GostService сервис для работы с ГОСТами
 type GostService struct {
-	gostsDB *database.GostsDB
+	gostsDB        *database.GostsDB
+	sourcePriority []string
 }
 
 // NewGostService создает новый сервис для работы с ГОСТами
 func NewGostService(gostsDB *database.GostsDB) *GostService {
 	return &GostService{
-		gostsDB: gostsDB,
+		gostsDB:        gostsDB,
+		sourcePriority: database.DefaultGostSourcePriority,
 	}
 }
 
+// SetSourcePriority задает порядок источников, по которому при импорте решается,
+// чье значение поля ГОСТа сохраняется. Пустой список - порядок по умолчанию.
+func (s *GostService) SetSourcePriority(priority []string) {
+	if len(priority) == 0 {
+		priority = database.DefaultGostSourcePriority
+	}
+	s.sourcePriority = priority
+}
+
 // ImportGosts импортирует ГОСТы из CSV файла
 func (s *GostService) ImportGosts(file io.Reader, filename string, sourceType, sourceURL string) (map[string]interface{}, error) {
 	// Валидация параметров
@@ -39,103 +50,43 @@ func (s *GostService) ImportGosts(file io.Reader, filename string, sourceType, s
 		return nil, apperrors.NewValidationError("CSV файл не содержит записей", nil)
 	}
 
-	// Создаем или обновляем источник данных
-	source := &database.GostSource{
-		SourceName:   sourceType,
-		SourceURL:    sourceURL,
-		LastSyncDate: timePtr(time.Now()),
-		RecordsCount: len(records),
-	}
-
-	sourceRecord, err := s.gostsDB.CreateOrUpdateSource(source)
+	imported, err := importer.ImportGostRecords(s.gostsDB, records, sourceType, sourceURL, s.sourcePriority)
 	if err != nil {
 		return nil, apperrors.NewInternalError("не удалось создать источник данных", err)
 	}
 
-	// Импортируем данные
-	successCount := 0
-	errorCount := 0
-	updatedCount := 0
-	errors := []string{}
 	const maxErrorsToReport = 100 // Ограничиваем количество ошибок в ответе
-
-	for i, record := range records {
-		// Валидация обязательных полей
-		if record.GostNumber == "" {
-			errorCount++
-			if len(errors) < maxErrorsToReport {
-				errors = append(errors, fmt.Sprintf("Строка %d: отсутствует номер ГОСТа", i+1))
-			}
-			continue
-		}
-
-		if record.Title == "" {
-			errorCount++
-			if len(errors) < maxErrorsToReport {
-				errors = append(errors, fmt.Sprintf("ГОСТ %s: отсутствует название", record.GostNumber))
-			}
-			continue
-		}
-
-		// Проверяем, существует ли уже ГОСТ с таким номером
-		existingGost, err := s.gostsDB.GetGostByNumber(record.GostNumber)
-		isUpdate := err == nil && existingGost != nil
-
-		gost := &database.Gost{
-			GostNumber:    record.GostNumber,
-			Title:         record.Title,
-			AdoptionDate:  record.AdoptionDate,
-			EffectiveDate: record.EffectiveDate,
-			Status:        record.Status,
-			SourceType:    sourceType,
-			SourceID:      &sourceRecord.ID,
-			SourceURL:     sourceURL,
-			Description:   record.Description,
-			Keywords:      record.Keywords,
-		}
-
-		_, err = s.gostsDB.CreateOrUpdateGost(gost)
-		if err != nil {
-			errorCount++
-			if len(errors) < maxErrorsToReport {
-				errors = append(errors, fmt.Sprintf("ГОСТ %s: %v", record.GostNumber, err))
-			}
-			continue
-		}
-
-		if isUpdate {
-			updatedCount++
-		}
-		successCount++
-	}
-
+	errorCount := len(imported.Errors)
 	result := map[string]interface{}{
-		"success":   successCount,
-		"updated":   updatedCount,
-		"created":   successCount - updatedCount,
-		"total":     len(records),
+		"success":   imported.Success,
+		"updated":   imported.Updated,
+		"created":   imported.Inserted,
+		"unchanged": imported.Unchanged,
+		"total":     imported.Total,
 		"errors":    errorCount,
-		"source_id": sourceRecord.ID,
+		"source_id": imported.SourceID,
 	}
 
 	// Добавляем список ошибок только если их не слишком много
-	if len(errors) > 0 {
-		result["error_list"] = errors
+	if errorCount > 0 {
+		errors := imported.Errors
 		if errorCount > maxErrorsToReport {
+			errors = errors[:maxErrorsToReport]
 			result["error_list_truncated"] = true
 			result["total_errors"] = errorCount
 		}
+		result["error_list"] = errors
 	}
 
 	return result, nil
 }
 
-// GetGosts возвращает список ГОСТов с фильтрацией и пагинацией
+// GetGosts возвращает страницу ГОСТов с фильтрацией и общее количество записей
 func (s *GostService) GetGosts(
 	limit, offset int,
 	status, sourceType, search string,
 	adoptionFrom, adoptionTo, effectiveFrom, effectiveTo string,
-) (map[string]interface{}, error) {
+) ([]map[string]interface{}, int, error) {
 	var gosts []*database.Gost
 	var total int
 	var err error
@@ -166,13 +117,27 @@ func (s *GostService) GetGosts(
 	}
 
 	if err != nil {
-		return nil, apperrors.NewInternalError("не удалось получить список ГОСТов", err)
+		return nil, 0, apperrors.NewInternalError("не удалось получить список ГОСТов", err)
 	}
 
-	// Преобразуем в интерфейсы для JSON
-	gostsInterface := make([]interface{}, 0, len(gosts))
+	return gostListItems(gosts), total, nil
+}
+
+// GetEffectiveGosts возвращает страницу ГОСТов, действовавших на указанную дату, и общее количество записей
+func (s *GostService) GetEffectiveGosts(date time.Time, limit, offset int) ([]map[string]interface{}, int, error) {
+	gosts, total, err := s.gostsDB.ListEffectiveAsOf(date, limit, offset)
+	if err != nil {
+		return nil, 0, apperrors.NewInternalError("не удалось получить список действующих ГОСТов", err)
+	}
+
+	return gostListItems(gosts), total, nil
+}
+
+// gostListItems преобразует ГОСТы в карты для JSON
+func gostListItems(gosts []*database.Gost) []map[string]interface{} {
+	gostsList := make([]map[string]interface{}, 0, len(gosts))
 	for _, gost := range gosts {
-		gostsInterface = append(gostsInterface, map[string]interface{}{
+		gostsList = append(gostsList, map[string]interface{}{
 			"id":             gost.ID,
 			"gost_number":    gost.GostNumber,
 			"title":          gost.Title,
@@ -187,12 +152,36 @@ func (s *GostService) GetGosts(
 			"updated_at":     gost.UpdatedAt.Format(time.RFC3339),
 		})
 	}
+	return gostsList
+}
+
+// SuggestGosts возвращает ГОСТы, похожие на произвольный текстовый запрос, с оценкой релевантности
+func (s *GostService) SuggestGosts(query string, limit int) (map[string]interface{}, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, apperrors.NewValidationError("поисковый запрос обязателен", nil)
+	}
+
+	gosts, err := s.gostsDB.SuggestByText(query, limit)
+	if err != nil {
+		return nil, apperrors.NewInternalError("не удалось подобрать ГОСТы", err)
+	}
+
+	suggestions := make([]interface{}, 0, len(gosts))
+	for _, gost := range gosts {
+		suggestions = append(suggestions, map[string]interface{}{
+			"id":             gost.ID,
+			"gost_number":    gost.GostNumber,
+			"title":          gost.Title,
+			"status":         gost.Status,
+			"effective_date": formatDate(gost.EffectiveDate),
+			"score":          gost.Score,
+		})
+	}
 
 	return map[string]interface{}{
-		"gosts":  gostsInterface,
-		"total":  total,
-		"limit":  limit,
-		"offset": offset,
+		"query":       query,
+		"suggestions": suggestions,
+		"total":       len(suggestions),
 	}, nil
 }
 
@@ -265,20 +254,43 @@ func (s *GostService) GetGostDetail(id int) (map[string]interface{}, error) {
 		})
 	}
 
+	// Другие редакции того же ГОСТа (отличаются годом), от новой к старой
+	editions, err := s.gostsDB.FindByNumberLoose(gost.GostNumber)
+	if err != nil {
+		// Не критично, деталь ГОСТа возвращается без связанных редакций
+		editions = []*database.Gost{}
+	}
+
+	relatedEditions := make([]interface{}, 0, len(editions))
+	for _, edition := range editions {
+		if edition.ID == gost.ID {
+			continue
+		}
+		relatedEditions = append(relatedEditions, map[string]interface{}{
+			"id":             edition.ID,
+			"gost_number":    edition.GostNumber,
+			"title":          edition.Title,
+			"status":         edition.Status,
+			"adoption_date":  formatDate(edition.AdoptionDate),
+			"effective_date": formatDate(edition.EffectiveDate),
+		})
+	}
+
 	return map[string]interface{}{
-		"id":             gost.ID,
-		"gost_number":    gost.GostNumber,
-		"title":          gost.Title,
-		"adoption_date":  formatDate(gost.AdoptionDate),
-		"effective_date": formatDate(gost.EffectiveDate),
-		"status":         gost.Status,
-		"source_type":    gost.SourceType,
-		"source_url":     gost.SourceURL,
-		"description":    gost.Description,
-		"keywords":       gost.Keywords,
-		"documents":      documentsInterface,
-		"created_at":     gost.CreatedAt.Format(time.RFC3339),
-		"updated_at":     gost.UpdatedAt.Format(time.RFC3339),
+		"id":               gost.ID,
+		"gost_number":      gost.GostNumber,
+		"title":            gost.Title,
+		"adoption_date":    formatDate(gost.AdoptionDate),
+		"effective_date":   formatDate(gost.EffectiveDate),
+		"status":           gost.Status,
+		"source_type":      gost.SourceType,
+		"source_url":       gost.SourceURL,
+		"description":      gost.Description,
+		"keywords":         gost.Keywords,
+		"documents":        documentsInterface,
+		"related_editions": relatedEditions,
+		"created_at":       gost.CreatedAt.Format(time.RFC3339),
+		"updated_at":       gost.UpdatedAt.Format(time.RFC3339),
 	}, nil
 }
 
@@ -305,6 +317,38 @@ func (s *GostService) GetGostByNumber(gostNumber string) (map[string]interface{}
 	}, nil
 }
 
+// ExtractGosts извлекает номера ГОСТов из произвольного текста и сопоставляет их с базой ГОСТов
+func (s *GostService) ExtractGosts(text string) (map[string]interface{}, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, apperrors.NewValidationError("текст обязателен", nil)
+	}
+
+	numbers := importer.ExtractGostNumbers(text)
+	found := make([]interface{}, 0, len(numbers))
+	notFound := make([]string, 0)
+	for _, number := range numbers {
+		gost, err := s.gostsDB.GetGostByNumber(number)
+		if err != nil {
+			notFound = append(notFound, number)
+			continue
+		}
+		found = append(found, map[string]interface{}{
+			"id":             gost.ID,
+			"gost_number":    gost.GostNumber,
+			"title":          gost.Title,
+			"status":         gost.Status,
+			"effective_date": formatDate(gost.EffectiveDate),
+		})
+	}
+
+	return map[string]interface{}{
+		"numbers":   numbers,
+		"found":     found,
+		"not_found": notFound,
+		"total":     len(numbers),
+	}, nil
+}
+
 // GetStatistics возвращает статистику по базе ГОСТов
 func (s *GostService) GetStatistics() (map[string]interface{}, error) {
 	stats, err := s.gostsDB.GetStatistics()
@@ -315,6 +359,16 @@ func (s *GostService) GetStatistics() (map[string]interface{}, error) {
 	return stats, nil
 }
 
+// GetQualityReport возвращает сводку по качеству данных ГОСТов
+func (s *GostService) GetQualityReport() (*database.GostQualityReport, error) {
+	report, err := s.gostsDB.QualityReport()
+	if err != nil {
+		return nil, apperrors.NewInternalError("не удалось построить отчет о качестве ГОСТов", err)
+	}
+
+	return report, nil
+}
+
 // UploadDocument загружает документ для ГОСТа
 func (s *GostService) UploadDocument(gostID int, filePath, fileType string, fileSize int64) (map[string]interface{}, error) {
 	// Проверяем существование ГОСТа
@@ -347,10 +401,6 @@ func (s *GostService) UploadDocument(gostID int, filePath, fileType string, file
 
 // Вспомогательные функции
 
-func timePtr(t time.Time) *time.Time {
-	return &t
-}
-
 func formatDate(t *time.Time) string {
 	if t == nil {
 		return ""
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"os"
 	"testing"
+	"time"
 
 	"httpserver/database"
 )
@@ -72,7 +73,7 @@ func TestGostService_GetGosts(t *testing.T) {
 	service := NewGostService(gostsDB)
 
 	// Тестируем получение пустого списка
-	result, err := service.GetGosts(10, 0, "", "", "", "", "", "", "")
+	result, total, err := service.GetGosts(10, 0, "", "", "", "", "", "", "")
 	if err != nil {
 		t.Fatalf("GetGosts() failed: %v", err)
 	}
@@ -81,8 +82,33 @@ func TestGostService_GetGosts(t *testing.T) {
 		t.Error("GetGosts() should not return nil")
 	}
 
-	if total, ok := result["total"].(int); !ok || total != 0 {
-		t.Errorf("Expected total=0, got %v", result["total"])
+	if total != 0 {
+		t.Errorf("Expected total=0, got %v", total)
+	}
+}
+
+// TestGostService_GetEffectiveGosts проверяет получение ГОСТов, действовавших на дату
+func TestGostService_GetEffectiveGosts(t *testing.T) {
+	gostsDB := setupTestGostsDB(t)
+	service := NewGostService(gostsDB)
+
+	effective := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	withdrawn := time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, gost := range []*database.Gost{
+		{GostNumber: "ГОСТ 1-2010", Title: "Действующий", Status: "действующий", EffectiveDate: &effective},
+		{GostNumber: "ГОСТ 2-2010", Title: "Отмененный", Status: "отменен", EffectiveDate: &effective, WithdrawalDate: &withdrawn},
+	} {
+		if _, err := gostsDB.CreateOrUpdateGost(gost); err != nil {
+			t.Fatalf("Failed to seed gost %s: %v", gost.GostNumber, err)
+		}
+	}
+
+	result, total, err := service.GetEffectiveGosts(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), 10, 0)
+	if err != nil {
+		t.Fatalf("GetEffectiveGosts() failed: %v", err)
+	}
+	if total != 1 || len(result) != 1 || result[0]["gost_number"] != "ГОСТ 1-2010" {
+		t.Errorf("Expected only ГОСТ 1-2010, got %v (total %d)", result, total)
 	}
 }
 
@@ -114,6 +140,36 @@ func TestGostService_ImportGosts(t *testing.T) {
 	}
 }
 
+// TestGostService_ImportGosts_SourcePriority проверяет, что при повторном импорте из менее
+// приоритетного источника название ГОСТа не перезаписывается
+func TestGostService_ImportGosts_SourcePriority(t *testing.T) {
+	gostsDB := setupTestGostsDB(t)
+	service := NewGostService(gostsDB)
+	service.SetSourcePriority([]string{"primary", "secondary"})
+
+	imports := []struct {
+		sourceType, title string
+	}{
+		{"secondary", "Название из вторичного источника"},
+		{"primary", "Название из основного источника"},
+		{"secondary", "Новое название из вторичного источника"},
+	}
+	for _, imp := range imports {
+		csvContent := "номер;название;дата принятия;статус\nГОСТ 12345-2020;" + imp.title + ";2020-01-01;действующий"
+		if _, err := service.ImportGosts(bytes.NewReader([]byte(csvContent)), "test.csv", imp.sourceType, ""); err != nil {
+			t.Fatalf("ImportGosts(%s) failed: %v", imp.sourceType, err)
+		}
+	}
+
+	gost, err := gostsDB.GetGostByNumber("ГОСТ 12345-2020")
+	if err != nil {
+		t.Fatalf("GetGostByNumber failed: %v", err)
+	}
+	if gost.Title != "Название из основного источника" || gost.SourceType != "primary" {
+		t.Errorf("Expected title from primary source, got %q (%s)", gost.Title, gost.SourceType)
+	}
+}
+
 // TestGostService_GetGostDetail проверяет получение детальной информации
 func TestGostService_GetGostDetail(t *testing.T) {
 	gostsDB := setupTestGostsDB(t)
@@ -180,6 +236,46 @@ func TestGostService_GetGostByNumber(t *testing.T) {
 	}
 }
 
+// TestGostService_ExtractGosts проверяет извлечение ГОСТов из текста и сопоставление с базой
+func TestGostService_ExtractGosts(t *testing.T) {
+	gostsDB := setupTestGostsDB(t)
+	service := NewGostService(gostsDB)
+
+	gost := &database.Gost{
+		GostNumber: "ГОСТ 8732-1978",
+		Title:      "Трубы стальные бесшовные горячедеформированные",
+		Status:     "действующий",
+		SourceType: "test",
+	}
+	if _, err := gostsDB.CreateOrUpdateGost(gost); err != nil {
+		t.Fatalf("Failed to create test GOST: %v", err)
+	}
+
+	result, err := service.ExtractGosts("Труба 57х3.5 ГОСТ 8732-78, сварка по ГОСТ 5264-80")
+	if err != nil {
+		t.Fatalf("ExtractGosts() failed: %v", err)
+	}
+
+	if total, ok := result["total"].(int); !ok || total != 2 {
+		t.Errorf("Expected total=2, got %v", result["total"])
+	}
+	found, ok := result["found"].([]interface{})
+	if !ok || len(found) != 1 {
+		t.Fatalf("Expected 1 found GOST, got %v", result["found"])
+	}
+	if number := found[0].(map[string]interface{})["gost_number"]; number != "ГОСТ 8732-1978" {
+		t.Errorf("Expected found gost_number='ГОСТ 8732-1978', got %v", number)
+	}
+	notFound, ok := result["not_found"].([]string)
+	if !ok || len(notFound) != 1 || notFound[0] != "ГОСТ 5264-1980" {
+		t.Errorf("Expected not_found=[ГОСТ 5264-1980], got %v", result["not_found"])
+	}
+
+	if _, err := service.ExtractGosts("   "); err == nil {
+		t.Error("ExtractGosts() should fail for empty text")
+	}
+}
+
 // TestGostService_UploadDocument проверяет загрузку документа
 func TestGostService_UploadDocument(t *testing.T) {
 	gostsDB := setupTestGostsDB(t)
@@ -265,7 +361,7 @@ func TestGostService_SearchGosts(t *testing.T) {
 	}
 
 	// Ищем по ключевому слову
-	result, err := service.GetGosts(10, 0, "", "", "поиск", "", "", "", "")
+	result, total, err := service.GetGosts(10, 0, "", "", "поиск", "", "", "", "")
 	if err != nil {
 		t.Fatalf("GetGosts() with search failed: %v", err)
 	}
@@ -275,7 +371,40 @@ func TestGostService_SearchGosts(t *testing.T) {
 	}
 
 	// Проверяем, что найдены результаты
-	if total, ok := result["total"].(int); ok && total > 0 {
+	if total > 0 {
 		t.Logf("Found %d GOSTs matching search", total)
 	}
 }
+
+// TestGostService_GetGostDetail_RelatedEditions проверяет список других редакций ГОСТа
+func TestGostService_GetGostDetail_RelatedEditions(t *testing.T) {
+	gostsDB := setupTestGostsDB(t)
+	service := NewGostService(gostsDB)
+
+	var current *database.Gost
+	for _, number := range []string{"ГОСТ 2590-71", "ГОСТ 2590-2006", "ГОСТ 2590-88"} {
+		gost, err := gostsDB.CreateOrUpdateGost(&database.Gost{GostNumber: number, Title: "Прокат", Status: "действующий", SourceType: "test"})
+		if err != nil {
+			t.Fatalf("Failed to create test GOST: %v", err)
+		}
+		if number == "ГОСТ 2590-88" {
+			current = gost
+		}
+	}
+
+	result, err := service.GetGostDetail(current.ID)
+	if err != nil {
+		t.Fatalf("GetGostDetail() failed: %v", err)
+	}
+
+	editions, ok := result["related_editions"].([]interface{})
+	if !ok || len(editions) != 2 {
+		t.Fatalf("Expected 2 related editions, got %v", result["related_editions"])
+	}
+	want := []string{"ГОСТ 2590-2006", "ГОСТ 2590-71"}
+	for i, edition := range editions {
+		if number := edition.(map[string]interface{})["gost_number"]; number != want[i] {
+			t.Errorf("related_editions[%d] = %v, want %q", i, number, want[i])
+		}
+	}
+}
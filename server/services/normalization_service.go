@@ -128,13 +128,19 @@ func (ns *NormalizationService) GetStatus() NormalizationStatus {
 		startTimeStr = ns.normalizerStartTime.Format(time.RFC3339)
 	}
 
+	var phaseTimings map[string]time.Duration
+	if ns.normalizer != nil {
+		phaseTimings = ns.normalizer.GetPhaseTimings()
+	}
+
 	return NormalizationStatus{
-		IsRunning:   ns.normalizerRunning,
-		Processed:   ns.normalizerProcessed,
-		Success:     ns.normalizerSuccess,
-		Errors:      ns.normalizerErrors,
-		StartTime:   startTimeStr,
-		ElapsedTime: elapsedTime.String(),
+		IsRunning:    ns.normalizerRunning,
+		Processed:    ns.normalizerProcessed,
+		Success:      ns.normalizerSuccess,
+		Errors:       ns.normalizerErrors,
+		StartTime:    startTimeStr,
+		ElapsedTime:  elapsedTime.String(),
+		PhaseTimings: phaseTimings,
 	}
 }
 
@@ -580,3 +586,23 @@ func (ns *NormalizationService) UpdateNormalizationConfig(databasePath, sourceTa
 	}
 	return ns.serviceDB.UpdateNormalizationConfig(databasePath, sourceTable, referenceColumn, codeColumn, nameColumn)
 }
+
+// GetNormalizationConfigForProject получает конфигурацию нормализации проекта
+// с откатом на глобальную конфигурацию, если у проекта нет собственной
+func (ns *NormalizationService) GetNormalizationConfigForProject(projectID int) (*database.NormalizationConfig, error) {
+	if ns.serviceDB == nil {
+		return nil, apperrors.NewServiceUnavailableError("serviceDB недоступна", nil)
+	}
+	return ns.serviceDB.GetNormalizationConfigForProject(projectID)
+}
+
+// UpdateNormalizationConfigForProject сохраняет собственную конфигурацию нормализации проекта
+func (ns *NormalizationService) UpdateNormalizationConfigForProject(projectID int, databasePath, sourceTable, referenceColumn, codeColumn, nameColumn string) error {
+	if ns.serviceDB == nil {
+		return apperrors.NewServiceUnavailableError("serviceDB недоступна", nil)
+	}
+	if _, err := ns.serviceDB.GetClientProject(projectID); err != nil {
+		return apperrors.NewNotFoundError("проект не найден", err)
+	}
+	return ns.serviceDB.UpdateNormalizationConfigForProject(projectID, databasePath, sourceTable, referenceColumn, codeColumn, nameColumn)
+}
@@ -27,10 +27,12 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Проверяем существование файла
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		log.Fatalf("Файл базы данных не найден: %s", dbPath)
+	// Проверяем существование файла (путь из project_databases может быть в разных форматах)
+	resolvedPath, err := database.ResolveProjectDatabasePath(dbPath)
+	if err != nil {
+		log.Fatalf("Файл базы данных не найден: %v", err)
 	}
+	dbPath = resolvedPath
 
 	fmt.Printf("Проверка цепочки данных для БД: %s\n", dbPath)
 	fmt.Println("=" + string(make([]byte, 80)) + "=")
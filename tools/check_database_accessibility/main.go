@@ -4,7 +4,9 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"os"
 	"path/filepath"
+	"strings"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -63,6 +65,8 @@ func main() {
 
 	accessibleCount := 0
 	inaccessibleCount := 0
+	corruptedCount := 0
+	integrityFailures := make(map[string][]string)
 	totalRecords := 0
 
 	for i, db := range databases {
@@ -106,8 +110,24 @@ func main() {
 		}
 		fmt.Printf("✅\n")
 
-		// Проверка 4: Проверка таблиц
-		fmt.Printf("   [3] Таблицы: ")
+		// Проверка 4: Целостность файла (поврежденные страницы, недописанный WAL)
+		fmt.Printf("   [3] Целостность: ")
+		if problems := checkDatabaseIntegrity(conn); len(problems) > 0 {
+			fmt.Printf("❌ %d ошибок\n", len(problems))
+			for _, problem := range problems {
+				fmt.Printf("       - %s\n", problem)
+			}
+			conn.Close()
+			integrityFailures[db.Name] = problems
+			corruptedCount++
+			fmt.Printf("   📊 ИТОГ: ❌ ПОВРЕЖДЕНА\n")
+			fmt.Println()
+			continue
+		}
+		fmt.Printf("✅ ok\n")
+
+		// Проверка 5: Проверка таблиц
+		fmt.Printf("   [4] Таблицы: ")
 
 		var tableNames []string
 		tableRows, err := conn.Query(`
@@ -136,8 +156,8 @@ func main() {
 			fmt.Printf("✅ %d таблиц: %v\n", len(tableNames), tableNames)
 		}
 
-		// Проверка 5: Подсчет записей
-		fmt.Printf("   [4] Записи: ")
+		// Проверка 6: Подсчет записей
+		fmt.Printf("   [5] Записи: ")
 
 		var count int
 		hasData := false
@@ -165,8 +185,8 @@ func main() {
 			fmt.Printf("⚠️  Нет данных\n")
 		}
 
-		// Проверка 6: Чтение образца данных
-		fmt.Printf("   [5] Чтение данных: ")
+		// Проверка 7: Чтение образца данных
+		fmt.Printf("   [6] Чтение данных: ")
 
 		var sampleData []string
 		hasSample := false
@@ -220,6 +240,12 @@ func main() {
 	if inaccessibleCount > 0 {
 		fmt.Printf("❌ Недоступных БД: %d\n", inaccessibleCount)
 	}
+	if corruptedCount > 0 {
+		fmt.Printf("❌ Поврежденных БД: %d\n", corruptedCount)
+		for name, problems := range integrityFailures {
+			fmt.Printf("   %s: %s\n", name, strings.Join(problems, "; "))
+		}
+	}
 	fmt.Printf("📊 Всего записей: %d\n", totalRecords)
 	fmt.Println()
 
@@ -231,6 +257,43 @@ func main() {
 		fmt.Println("╔═══════════════════════════════════════════════════════════════╗")
 		fmt.Println("║     ⚠️  НЕКОТОРЫЕ БД НЕДОСТУПНЫ                             ║")
 		fmt.Println("╚═══════════════════════════════════════════════════════════════╝")
+		fmt.Println()
+		os.Exit(1)
 	}
 	fmt.Println()
 }
+
+// checkDatabaseIntegrity проверяет файл БД через PRAGMA quick_check, а если быстрая проверка
+// прошла - через полный PRAGMA integrity_check. Возвращает сообщения об ошибках; пустой
+// результат означает, что обе проверки вернули "ok".
+func checkDatabaseIntegrity(conn *sql.DB) []string {
+	for _, pragma := range []string{"quick_check", "integrity_check"} {
+		if problems := runIntegrityPragma(conn, pragma); len(problems) > 0 {
+			return problems
+		}
+	}
+	return nil
+}
+
+func runIntegrityPragma(conn *sql.DB, pragma string) []string {
+	rows, err := conn.Query("PRAGMA " + pragma)
+	if err != nil {
+		return []string{fmt.Sprintf("%s: %v", pragma, err)}
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var message string
+		if err := rows.Scan(&message); err != nil {
+			return append(problems, fmt.Sprintf("%s: %v", pragma, err))
+		}
+		if message != "ok" {
+			problems = append(problems, fmt.Sprintf("%s: %s", pragma, message))
+		}
+	}
+	if err := rows.Err(); err != nil {
+		problems = append(problems, fmt.Sprintf("%s: %v", pragma, err))
+	}
+	return problems
+}
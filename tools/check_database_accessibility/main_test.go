@@ -0,0 +1,83 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createIntegrityFixture создает БД с таблицей на несколько страниц
+func createIntegrityFixture(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "fixture.db")
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open fixture: %v", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Exec(`CREATE TABLE nomenclature_items (id INTEGER PRIMARY KEY, name TEXT, code TEXT)`); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := conn.Exec(`CREATE INDEX idx_nomenclature_code ON nomenclature_items(code)`); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	for i := 0; i < 500; i++ {
+		if _, err := conn.Exec(`INSERT INTO nomenclature_items (name, code) VALUES (?, ?)`,
+			strings.Repeat("Болт М10 ", 10), i); err != nil {
+			t.Fatalf("Failed to insert row: %v", err)
+		}
+	}
+	return path
+}
+
+func openForIntegrityCheck(t *testing.T, path string) *sql.DB {
+	t.Helper()
+	conn, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestCheckDatabaseIntegrity_Healthy(t *testing.T) {
+	conn := openForIntegrityCheck(t, createIntegrityFixture(t))
+	if problems := checkDatabaseIntegrity(conn); len(problems) != 0 {
+		t.Errorf("Expected healthy database, got %v", problems)
+	}
+}
+
+func TestCheckDatabaseIntegrity_Corrupted(t *testing.T) {
+	path := createIntegrityFixture(t)
+
+	// Затираем страницы в середине файла, заголовок и первая страница остаются целыми
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	const pageSize = 4096
+	if len(data) < 8*pageSize {
+		t.Fatalf("Fixture is too small: %d bytes", len(data))
+	}
+	for i := 2 * pageSize; i < 5*pageSize; i++ {
+		data[i] = 0xA5
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write corrupted fixture: %v", err)
+	}
+
+	conn := openForIntegrityCheck(t, path)
+	if err := conn.Ping(); err != nil {
+		t.Fatalf("Corrupted fixture should still open: %v", err)
+	}
+	problems := checkDatabaseIntegrity(conn)
+	if len(problems) == 0 {
+		t.Fatal("Expected integrity problems for corrupted database")
+	}
+	if !strings.HasPrefix(problems[0], "quick_check: ") {
+		t.Errorf("Expected quick_check to detect corruption first, got %v", problems)
+	}
+}
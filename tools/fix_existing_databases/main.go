@@ -4,12 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"log"
-	"os"
-	"path/filepath"
 
 	"httpserver/database"
-
-	"github.com/google/uuid"
 )
 
 func main() {
@@ -70,125 +66,6 @@ func main() {
 
 	fmt.Printf("\nНайдено проектов для проверки: %d\n\n", len(projects))
 
-
-	// Функция для исправления upload записей (копия логики из ensureUploadRecordsForDatabase)
-	fixUploadRecords := func(dbPath string, clientID, projectID, databaseID int) error {
-		// Открываем исходную базу данных
-		sourceDB, err := database.NewDB(dbPath)
-		if err != nil {
-			return fmt.Errorf("failed to open source database %s: %w", dbPath, err)
-		}
-		defer sourceDB.Close()
-
-		// Получаем все существующие upload записи
-		uploads, err := sourceDB.GetAllUploads()
-		if err != nil {
-			log.Printf("Note: Could not get uploads from %s (table may not exist): %v", dbPath, err)
-			uploads = []*database.Upload{}
-		}
-
-		// Проверяем, есть ли upload записи с правильными client_id и project_id
-		needsUpdate := false
-		needsCreate := false
-
-		if len(uploads) == 0 {
-			needsCreate = true
-		} else {
-			// Проверяем, есть ли хотя бы одна запись с правильными client_id и project_id
-			hasCorrectUpload := false
-			for _, upload := range uploads {
-				if upload.ClientID != nil && *upload.ClientID == clientID &&
-					upload.ProjectID != nil && *upload.ProjectID == projectID {
-					hasCorrectUpload = true
-					break
-				}
-			}
-
-			if !hasCorrectUpload {
-				needsUpdate = true
-				// Если все upload записи не имеют правильных client_id/project_id, создаем новую
-				allMissingIDs := true
-				for _, upload := range uploads {
-					if upload.ClientID != nil || upload.ProjectID != nil {
-						allMissingIDs = false
-						break
-					}
-				}
-				if allMissingIDs {
-					needsCreate = true
-				}
-			}
-		}
-
-		// Обновляем существующие upload записи
-		if needsUpdate {
-			for _, upload := range uploads {
-				// Обновляем только если client_id или project_id отсутствуют или неверны
-				shouldUpdate := false
-				if upload.ClientID == nil || *upload.ClientID != clientID {
-					shouldUpdate = true
-				}
-				if upload.ProjectID == nil || *upload.ProjectID != projectID {
-					shouldUpdate = true
-				}
-
-				if shouldUpdate {
-					err := sourceDB.UpdateUploadClientProject(upload.ID, clientID, projectID)
-					if err != nil {
-						log.Printf("Warning: Failed to update upload %d in %s: %v", upload.ID, dbPath, err)
-					} else {
-						log.Printf("Updated upload %d in %s with client_id=%d, project_id=%d", upload.ID, dbPath, clientID, projectID)
-					}
-				}
-			}
-		}
-
-		// Создаем новую upload запись, если нужно
-		if needsCreate {
-			uploadUUID := uuid.New().String()
-			dbID := databaseID
-
-			// Пытаемся определить версию 1С и имя конфигурации из метаданных или имени файла
-			version1C := "8.3"
-			configName := "Unknown"
-
-			// Парсим имя файла для получения информации
-			fileName := filepath.Base(dbPath)
-			fileInfo := database.ParseDatabaseFileInfo(fileName)
-			if fileInfo.ConfigName != "" && fileInfo.ConfigName != "Unknown" {
-				configName = fileInfo.ConfigName
-			}
-
-			upload, err := sourceDB.CreateUploadWithDatabase(
-				uploadUUID,
-				version1C,
-				configName,
-				&dbID,
-				"", // computerName
-				"", // userName
-				"", // configVersion
-				1,  // iterationNumber
-				"", // iterationLabel
-				"", // programmerName
-				"", // uploadPurpose
-				nil, // parentUploadID
-			)
-			if err != nil {
-				return fmt.Errorf("failed to create upload in %s: %w", dbPath, err)
-			}
-
-			// Обновляем client_id и project_id
-			err = sourceDB.UpdateUploadClientProject(upload.ID, clientID, projectID)
-			if err != nil {
-				log.Printf("Warning: Failed to update new upload %d with client_id/project_id: %v", upload.ID, err)
-			} else {
-				log.Printf("Created and updated upload %d in %s with client_id=%d, project_id=%d", upload.ID, dbPath, clientID, projectID)
-			}
-		}
-
-		return nil
-	}
-
 	totalDatabases := 0
 	fixedDatabases := 0
 	skippedDatabases := 0
@@ -219,37 +96,12 @@ func main() {
 			fmt.Printf("\n  БД: %s (ID: %d)\n", db.Name, db.ID)
 			fmt.Printf("    Путь: %s\n", db.FilePath)
 
-			// Проверяем существование файла (пробуем разные варианты путей)
-			dbPath := db.FilePath
-			if !filepath.IsAbs(dbPath) {
-				// Пробуем разные варианты путей
-				possiblePaths := []string{
-					dbPath,                                    // Как есть
-					filepath.Join("data", dbPath),            // data/uploads/...
-					filepath.Join(".", dbPath),               // ./uploads/...
-					filepath.Join("data", "uploads", filepath.Base(dbPath)), // data/uploads/имя_файла.db
-				}
-				
-				found := false
-				for _, path := range possiblePaths {
-					if _, err := os.Stat(path); err == nil {
-						dbPath = path
-						found = true
-						break
-					}
-				}
-				
-				if !found {
-					fmt.Printf("    ❌ Файл не существует: %s\n", db.FilePath)
-					skippedDatabases++
-					continue
-				}
-			} else {
-				if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-					fmt.Printf("    ❌ Файл не существует: %s\n", dbPath)
-					skippedDatabases++
-					continue
-				}
+			// Проверяем существование файла (путь мог быть сохранен в разных форматах)
+			dbPath, err := database.ResolveProjectDatabasePath(db.FilePath)
+			if err != nil {
+				fmt.Printf("    ❌ Файл не существует: %s\n", db.FilePath)
+				skippedDatabases++
+				continue
 			}
 
 			// Открываем исходную БД для проверки
@@ -320,12 +172,12 @@ func main() {
 			// Исправляем, если нужно
 			if fix {
 				fmt.Printf("    🔧 Исправление upload записей...\n")
-				err := fixUploadRecords(dbPath, project.ClientID, project.ID, db.ID)
+				stats, err := database.EnsureUploadRecordsForDatabase(sourceDB, project.ClientID, project.ID, db.ID)
 				if err != nil {
 					fmt.Printf("    ❌ Ошибка исправления: %v\n", err)
 					errorDatabases++
 				} else {
-					fmt.Printf("    ✅ Upload записи исправлены\n")
+					fmt.Printf("    ✅ Upload записи исправлены (создано: %d, обновлено: %d)\n", stats.Created, stats.Updated)
 					fixedDatabases++
 				}
 			} else {
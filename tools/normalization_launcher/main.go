@@ -10,6 +10,7 @@ import (
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+	"httpserver/internal/httpclient"
 )
 
 func main() {
@@ -50,7 +51,7 @@ func main() {
 	fmt.Println()
 
 	apiURL := "http://localhost:9999"
-	client := &http.Client{Timeout: 5 * time.Second}
+	client := httpclient.New(httpclient.Options{Timeout: 5 * time.Second})
 
 	req, _ := http.NewRequest("GET", apiURL+"/api/clients", nil)
 	resp, err := client.Do(req)
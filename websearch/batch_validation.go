@@ -0,0 +1,108 @@
+package websearch
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"httpserver/websearch/types"
+)
+
+// DefaultBatchConcurrency число одновременных проверок в ValidateGostsBatch, если BatchOptions.Concurrency не задан
+const DefaultBatchConcurrency = 4
+
+// GostValidator проверяет один ГОСТ по номеру; реализуется *ProductExistenceValidator
+type GostValidator interface {
+	Validate(ctx context.Context, name string) (*types.ValidationResult, error)
+}
+
+var _ GostValidator = (*ProductExistenceValidator)(nil)
+
+// BatchOptions параметры ValidateGostsBatch
+type BatchOptions struct {
+	Concurrency int           // Число одновременных проверок (0 - DefaultBatchConcurrency)
+	ItemTimeout time.Duration // Таймаут проверки одного ГОСТа (0 - ограничивается только ctx)
+}
+
+// ValidateGostsBatch проверяет ГОСТы numbers пулом из opts.Concurrency воркеров и возвращает
+// результаты в порядке numbers. Частоту запросов ограничивает rate.Limiter клиента валидатора,
+// поэтому задержки между вызовами не нужны. Ошибка проверки одного ГОСТа не прерывает пакет:
+// она попадает в его результат со статусом "error". При отмене ctx непроверенные ГОСТы также
+// получают результат "error", а функция возвращает ctx.Err() вместе с частичными результатами.
+func ValidateGostsBatch(ctx context.Context, validator GostValidator, numbers []string, opts BatchOptions) ([]*types.ValidationResult, error) {
+	concurrency := opts.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+	if concurrency > len(numbers) {
+		concurrency = len(numbers)
+	}
+
+	results := make([]*types.ValidationResult, len(numbers))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = validateBatchItem(ctx, validator, numbers[i], opts.ItemTimeout)
+			}
+		}()
+	}
+
+feed:
+	for i := range numbers {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		for i, result := range results {
+			if result == nil {
+				results[i] = batchErrorResult(fmt.Errorf("validation cancelled: %w", err))
+			}
+		}
+		return results, err
+	}
+	return results, nil
+}
+
+// validateBatchItem проверяет один ГОСТ с таймаутом itemTimeout
+func validateBatchItem(ctx context.Context, validator GostValidator, number string, itemTimeout time.Duration) *types.ValidationResult {
+	if err := ctx.Err(); err != nil {
+		return batchErrorResult(fmt.Errorf("validation cancelled: %w", err))
+	}
+	if itemTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, itemTimeout)
+		defer cancel()
+	}
+
+	result, err := validator.Validate(ctx, number)
+	if err != nil {
+		return batchErrorResult(fmt.Errorf("failed to validate %s: %w", number, err))
+	}
+	if result == nil {
+		return batchErrorResult(fmt.Errorf("failed to validate %s: empty result", number))
+	}
+	return result
+}
+
+// batchErrorResult результат проверки ГОСТа, завершившейся ошибкой
+func batchErrorResult(err error) *types.ValidationResult {
+	return &types.ValidationResult{
+		Status:    "error",
+		Message:   fmt.Sprintf("Ошибка проверки: %v", err),
+		Timestamp: time.Now(),
+		Details: map[string]interface{}{
+			"error": err.Error(),
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package websearch
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// batchSearchProvider потокобезопасная заглушка поиска для пакетной проверки
+type batchSearchProvider struct {
+	mu       sync.Mutex
+	inFlight int
+	maxSeen  int
+	search   func(ctx context.Context, query string) (*SearchResult, error)
+}
+
+func (p *batchSearchProvider) Search(ctx context.Context, query string) (*SearchResult, error) {
+	p.mu.Lock()
+	p.inFlight++
+	p.maxSeen = max(p.maxSeen, p.inFlight)
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.inFlight--
+		p.mu.Unlock()
+	}()
+	return p.search(ctx, query)
+}
+
+func TestValidateGostsBatch_OrderAndPerItemErrors(t *testing.T) {
+	provider := &batchSearchProvider{search: func(ctx context.Context, query string) (*SearchResult, error) {
+		time.Sleep(5 * time.Millisecond)
+		switch {
+		case strings.Contains(query, "ошибка"):
+			return nil, errors.New("provider unavailable")
+		case strings.Contains(query, "медленный"):
+			<-ctx.Done()
+			return nil, ctx.Err()
+		case strings.Contains(query, "нет"):
+			return &SearchResult{Found: false}, nil
+		}
+		return &SearchResult{Found: true, Confidence: 0.8, Results: []SearchItem{{Title: query}}}, nil
+	}}
+	numbers := []string{"ГОСТ 1-1", "ГОСТ ошибка", "ГОСТ 2-2", "ГОСТ нет", "ГОСТ медленный", "ГОСТ 3-3", "ГОСТ 4-4"}
+
+	results, err := ValidateGostsBatch(context.Background(), NewProductExistenceValidator(provider), numbers,
+		BatchOptions{Concurrency: 3, ItemTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("ValidateGostsBatch failed: %v", err)
+	}
+	if len(results) != len(numbers) {
+		t.Fatalf("Expected %d results, got %d", len(numbers), len(results))
+	}
+
+	want := []string{"success", "error", "success", "not_found", "error", "success", "success"}
+	for i, result := range results {
+		if result.Status != want[i] {
+			t.Errorf("%s: expected status %s, got %s (%s)", numbers[i], want[i], result.Status, result.Message)
+		}
+		if result.Status == "success" && result.Results[0].Title != numbers[i] {
+			t.Errorf("Result %d belongs to %s, expected %s", i, result.Results[0].Title, numbers[i])
+		}
+	}
+	if provider.maxSeen > 3 {
+		t.Errorf("Expected at most 3 concurrent validations, got %d", provider.maxSeen)
+	}
+}
+
+func TestValidateGostsBatch_Cancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var mu sync.Mutex
+	calls := 0
+	provider := &batchSearchProvider{search: func(searchCtx context.Context, query string) (*SearchResult, error) {
+		mu.Lock()
+		calls++
+		if calls == 2 {
+			cancel()
+		}
+		mu.Unlock()
+		return &SearchResult{Found: true, Confidence: 0.8, Results: []SearchItem{{Title: query}}}, nil
+	}}
+	numbers := []string{"ГОСТ 1-1", "ГОСТ 2-2", "ГОСТ 3-3", "ГОСТ 4-4", "ГОСТ 5-5", "ГОСТ 6-6"}
+
+	results, err := ValidateGostsBatch(ctx, NewProductExistenceValidator(provider), numbers, BatchOptions{Concurrency: 1})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if len(results) != len(numbers) || results[0].Status != "success" {
+		t.Fatalf("Expected partial results with the first validation completed, got %+v", results)
+	}
+	cancelled := 0
+	for _, result := range results {
+		if result == nil {
+			t.Fatal("Expected a result for every GOST")
+		}
+		if result.Status == "error" {
+			cancelled++
+		}
+	}
+	if cancelled < len(numbers)-2 {
+		t.Errorf("Expected unvalidated GOSTs to be reported as cancelled, got %d", cancelled)
+	}
+}
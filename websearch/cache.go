@@ -1,6 +1,7 @@
 package websearch
 
 import (
+	"log"
 	"sync"
 	"time"
 )
@@ -11,6 +12,10 @@ type CacheConfig struct {
 	TTL             time.Duration `json:"ttl"`
 	CleanupInterval time.Duration `json:"cleanup_interval"`
 	MaxSize         int           `json:"max_size"`
+	// PersistPath файл, в котором кэш сохраняется между перезапусками (пусто - только в памяти).
+	// Записи загружаются в NewCache и сохраняются каждые FlushInterval и в Close.
+	PersistPath   string        `json:"persist_path,omitempty"`
+	FlushInterval time.Duration `json:"flush_interval,omitempty"` // По умолчанию defaultCacheFlushInterval
 }
 
 // CacheEntry запись в кэше
@@ -26,6 +31,10 @@ type Cache struct {
 	data   map[string]*CacheEntry
 	mutex  sync.RWMutex
 	stats  *CacheStats
+
+	stop      chan struct{} // Закрывается в Close и останавливает фоновые очистку и сохранение
+	closeOnce sync.Once
+	flushMu   sync.Mutex // Сериализует запись файла PersistPath
 }
 
 // CacheStats статистика кэша
@@ -33,6 +42,8 @@ type CacheStats struct {
 	Hits   int64 `json:"hits"`
 	Misses int64 `json:"misses"`
 	Size   int   `json:"size"`
+	// LoadedFromDisk число записей, загруженных из CacheConfig.PersistPath при создании кэша
+	LoadedFromDisk int `json:"loaded_from_disk"`
 }
 
 // NewCache создает новый кэш
@@ -41,6 +52,16 @@ func NewCache(config *CacheConfig) *Cache {
 		config: config,
 		data:   make(map[string]*CacheEntry),
 		stats:  &CacheStats{},
+		stop:   make(chan struct{}),
+	}
+
+	if config.Enabled && config.PersistPath != "" {
+		loaded, err := cache.load()
+		if err != nil {
+			log.Printf("Warning: failed to load web search cache from %s: %v", config.PersistPath, err)
+		}
+		cache.stats.LoadedFromDisk = loaded
+		go cache.startFlush()
 	}
 
 	// Запускаем очистку устаревших записей
@@ -51,6 +72,19 @@ func NewCache(config *CacheConfig) *Cache {
 	return cache
 }
 
+// Close останавливает фоновые задачи кэша и сохраняет записи в CacheConfig.PersistPath.
+// Повторный вызов ничего не делает.
+func (c *Cache) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.stop)
+		if c.config.Enabled && c.config.PersistPath != "" {
+			err = c.Flush()
+		}
+	})
+	return err
+}
+
 // Get возвращает результат из кэша
 func (c *Cache) Get(key string) (*SearchResult, bool) {
 	if !c.config.Enabled {
@@ -81,8 +115,13 @@ func (c *Cache) Get(key string) (*SearchResult, bool) {
 	return entry.Result, true
 }
 
-// Set сохраняет результат в кэш
+// Set сохраняет результат в кэш на CacheConfig.TTL
 func (c *Cache) Set(key string, result *SearchResult) {
+	c.SetWithTTL(key, result, c.config.TTL)
+}
+
+// SetWithTTL сохраняет результат в кэш на ttl, например отрицательный результат на меньший срок
+func (c *Cache) SetWithTTL(key string, result *SearchResult, ttl time.Duration) {
 	if !c.config.Enabled {
 		return
 	}
@@ -97,7 +136,7 @@ func (c *Cache) Set(key string, result *SearchResult) {
 
 	c.data[key] = &CacheEntry{
 		Result:     result,
-		Expiration: time.Now().Add(c.config.TTL),
+		Expiration: time.Now().Add(ttl),
 		AccessCount: 1,
 	}
 
@@ -160,8 +199,13 @@ func (c *Cache) startCleanup() {
 	ticker := time.NewTicker(c.config.CleanupInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		c.cleanup()
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-c.stop:
+			return
+		}
 	}
 }
 
@@ -0,0 +1,135 @@
+package websearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultCacheFlushInterval период сохранения кэша на диск, если CacheConfig.FlushInterval не задан
+const defaultCacheFlushInterval = 5 * time.Minute
+
+// persistedCacheEntry запись кэша в файле CacheConfig.PersistPath
+type persistedCacheEntry struct {
+	Key        string        `json:"key"`
+	Result     *SearchResult `json:"result"`
+	Expiration time.Time     `json:"expiration"`
+}
+
+// persistedCache содержимое файла CacheConfig.PersistPath
+type persistedCache struct {
+	Entries []persistedCacheEntry `json:"entries"`
+}
+
+// CachePersistPathFor возвращает отдельный файл кэша name на основе общего пути base из конфигурации:
+// "data/websearch_cache.json" и "multi" дают "data/websearch_cache.multi.json".
+// У каждого кэша свой файл (и свой .tmp), иначе кэши одного процесса перезаписывают записи друг друга.
+// Пустой base означает кэш только в памяти.
+func CachePersistPathFor(base, name string) string {
+	if base == "" || name == "" {
+		return base
+	}
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + name + ext
+}
+
+// Flush сохраняет неустаревшие записи кэша в CacheConfig.PersistPath.
+// Файл записывается атомарно: сначала во временный файл, затем переименовывается.
+func (c *Cache) Flush() error {
+	if c.config.PersistPath == "" {
+		return nil
+	}
+
+	now := time.Now()
+	c.mutex.RLock()
+	snapshot := persistedCache{Entries: make([]persistedCacheEntry, 0, len(c.data))}
+	for key, entry := range c.data {
+		if now.After(entry.Expiration) {
+			continue
+		}
+		snapshot.Entries = append(snapshot.Entries, persistedCacheEntry{Key: key, Result: entry.Result, Expiration: entry.Expiration})
+	}
+	c.mutex.RUnlock()
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache: %w", err)
+	}
+
+	c.flushMu.Lock()
+	defer c.flushMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(c.config.PersistPath), 0755); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmpPath := c.config.PersistPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.config.PersistPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+// load загружает записи из CacheConfig.PersistPath, пропуская устаревшие, и возвращает их число.
+// Отсутствующий файл означает пустой кэш.
+func (c *Cache) load() (int, error) {
+	data, err := os.ReadFile(c.config.PersistPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	var snapshot persistedCache
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return 0, fmt.Errorf("failed to parse cache: %w", err)
+	}
+
+	now := time.Now()
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	loaded := 0
+	for _, entry := range snapshot.Entries {
+		if entry.Result == nil || now.After(entry.Expiration) {
+			continue
+		}
+		if c.config.MaxSize > 0 && len(c.data) >= c.config.MaxSize {
+			break
+		}
+		c.data[entry.Key] = &CacheEntry{Result: entry.Result, Expiration: entry.Expiration, AccessCount: 1}
+		loaded++
+	}
+	c.stats.Size = len(c.data)
+	return loaded, nil
+}
+
+// startFlush периодически сохраняет кэш на диск до вызова Close
+func (c *Cache) startFlush() {
+	interval := c.config.FlushInterval
+	if interval <= 0 {
+		interval = defaultCacheFlushInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := c.Flush(); err != nil {
+				log.Printf("Warning: failed to save web search cache: %v", err)
+			}
+		case <-c.stop:
+			return
+		}
+	}
+}
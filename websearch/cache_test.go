@@ -1,6 +1,8 @@
 package websearch
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -167,3 +169,72 @@ func TestCache_Clear(t *testing.T) {
 	}
 }
 
+func TestCache_PersistAcrossRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "websearch_cache.json")
+	config := &CacheConfig{Enabled: true, TTL: time.Hour, PersistPath: path}
+
+	cache := NewCache(config)
+	cache.Set("gost-7798", &SearchResult{Query: "ГОСТ 7798-70", Found: true, Source: "duckduckgo-html",
+		Results: []SearchItem{{Title: "Болт ГОСТ 7798-70", URL: "https://example.com/7798"}}})
+	cache.Set("gost-expired", &SearchResult{Query: "ГОСТ 1-1", Found: true})
+	cache.data["gost-expired"].Expiration = time.Now().Add(-time.Minute)
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened := NewCache(&CacheConfig{Enabled: true, TTL: time.Hour, PersistPath: path})
+	defer reopened.Close()
+
+	if loaded := reopened.GetStats().LoadedFromDisk; loaded != 1 {
+		t.Errorf("Expected 1 entry loaded from disk, got %d", loaded)
+	}
+	cached, found := reopened.Get("gost-7798")
+	if !found {
+		t.Fatal("Expected persisted entry to be a cache hit after restart")
+	}
+	if cached.Query != "ГОСТ 7798-70" || len(cached.Results) != 1 || cached.Results[0].URL != "https://example.com/7798" {
+		t.Errorf("Unexpected persisted result: %+v", cached)
+	}
+	if _, found := reopened.Get("gost-expired"); found {
+		t.Error("Expired entry should not be loaded")
+	}
+	if hits := reopened.GetStats().Hits; hits != 1 {
+		t.Errorf("Expected 1 hit, got %d", hits)
+	}
+}
+
+func TestCache_PersistMissingOrCorruptFile(t *testing.T) {
+	dir := t.TempDir()
+
+	cache := NewCache(&CacheConfig{Enabled: true, TTL: time.Hour, PersistPath: filepath.Join(dir, "missing.json")})
+	if loaded := cache.GetStats().LoadedFromDisk; loaded != 0 {
+		t.Errorf("Expected empty cache without file, got %d entries", loaded)
+	}
+	cache.Close()
+
+	corrupt := filepath.Join(dir, "corrupt.json")
+	if err := os.WriteFile(corrupt, []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache = NewCache(&CacheConfig{Enabled: true, TTL: time.Hour, PersistPath: corrupt})
+	defer cache.Close()
+	cache.Set("key", &SearchResult{Query: "болт"})
+	if _, found := cache.Get("key"); !found {
+		t.Error("Cache with unreadable file should still work in memory")
+	}
+}
+
+func TestCachePersistPathFor(t *testing.T) {
+	tests := []struct {
+		base, name, want string
+	}{
+		{"data/websearch_cache.json", "multi", "data/websearch_cache.multi.json"},
+		{"websearch_cache", "simple", "websearch_cache.simple"},
+		{"", "multi", ""},
+	}
+	for _, tt := range tests {
+		if got := CachePersistPathFor(tt.base, tt.name); got != tt.want {
+			t.Errorf("CachePersistPathFor(%q, %q) = %q, want %q", tt.base, tt.name, got, tt.want)
+		}
+	}
+}
@@ -12,30 +12,99 @@ import (
 	"time"
 
 	"golang.org/x/time/rate"
+	"httpserver/internal/httpclient"
 	"httpserver/websearch/types"
 )
 
+// SearchMode режим поиска DuckDuckGo
+type SearchMode string
+
+const (
+	// SearchModeAuto сначала Instant Answer API, при пустом ответе - HTML-поиск (по умолчанию)
+	SearchModeAuto SearchMode = "auto"
+	// SearchModeAPI только Instant Answer API (api.duckduckgo.com)
+	SearchModeAPI SearchMode = "api"
+	// SearchModeHTML только HTML-поиск (html.duckduckgo.com)
+	SearchModeHTML SearchMode = "html"
+)
+
+// Адреса DuckDuckGo по умолчанию
+const (
+	DefaultAPIBaseURL  = "https://api.duckduckgo.com"
+	DefaultHTMLBaseURL = "https://html.duckduckgo.com"
+)
+
+// DefaultMaxRetries число повторов запроса при временных ошибках, если ClientConfig.MaxRetries не задан
+const DefaultMaxRetries = 2
+
+// DefaultNegativeCacheTTL время хранения в кэше результатов "не найдено", если ClientConfig.NegativeCacheTTL не задан.
+// Короче обычного TTL: товар или ГОСТ может появиться в выдаче позже.
+const DefaultNegativeCacheTTL = 30 * time.Minute
+
+// ParseSearchMode разбирает режим поиска из строки; пустая строка означает SearchModeAuto
+func ParseSearchMode(value string) (SearchMode, error) {
+	switch mode := SearchMode(strings.ToLower(strings.TrimSpace(value))); mode {
+	case "":
+		return SearchModeAuto, nil
+	case SearchModeAuto, SearchModeAPI, SearchModeHTML:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown search mode %q: expected %q, %q or %q", value, SearchModeAuto, SearchModeAPI, SearchModeHTML)
+	}
+}
+
 // Client клиент для веб-поиска через DuckDuckGo
 type Client struct {
-	baseURL    string
-	httpClient *http.Client
-	timeout    time.Duration
-	limiter    *rate.Limiter
-	cache      *Cache
+	baseURL     string
+	htmlBaseURL string
+	mode        SearchMode
+	httpClient  *http.Client
+	timeout     time.Duration
+	limiter     *rate.Limiter
+	cache       *Cache
+	maxResults  int
+
+	negativeCacheTTL time.Duration
+	minConfidence    float64
+
+	// Настройки произвольного HTML-поиска (NewHTMLSearchClient); для DuckDuckGo пусты
+	htmlURLTemplate string
+	htmlSource      string
+	resultClass     string
+	snippetClass    string
 }
 
 // ClientConfig конфигурация клиента
 type ClientConfig struct {
-	BaseURL    string
-	Timeout    time.Duration
-	RateLimit  rate.Limit
-	Cache      *Cache
+	BaseURL     string     // Адрес Instant Answer API (по умолчанию DefaultAPIBaseURL)
+	HTMLBaseURL string     // Адрес HTML-поиска (по умолчанию DefaultHTMLBaseURL), можно подменить заглушкой в тестах
+	SearchMode  SearchMode // Режим поиска (по умолчанию SearchModeAuto)
+	Timeout     time.Duration
+	RateLimit   rate.Limit
+	Cache       *Cache
+	MaxResults  int // Максимум результатов, извлекаемых из HTML-страницы (по умолчанию 30)
+	// MaxRetries число повторов запроса после сетевой ошибки или ответа 429/502/503/504
+	// (0 - DefaultMaxRetries, отрицательное значение - без повторов). Повторы выполняются
+	// с экспоненциальной задержкой и разбросом, учитывают Retry-After и ожидают RateLimit.
+	MaxRetries int
+	// NegativeCacheTTL время хранения в кэше результатов без найденных страниц
+	// (0 - DefaultNegativeCacheTTL, отрицательное значение - не кэшировать)
+	NegativeCacheTTL time.Duration
+	// MinConfidence минимальная уверенность поиска, при которой валидаторы считают товар найденным;
+	// при меньшей уверенности результат валидации - "inconclusive" (0 - порог не применяется)
+	MinConfidence float64
 }
 
 // NewClient создает новый клиент для веб-поиска
 func NewClient(config ClientConfig) *Client {
 	if config.BaseURL == "" {
-		config.BaseURL = "https://api.duckduckgo.com"
+		config.BaseURL = DefaultAPIBaseURL
+	}
+	if config.HTMLBaseURL == "" {
+		config.HTMLBaseURL = DefaultHTMLBaseURL
+	}
+	if config.SearchMode == "" {
+		config.SearchMode = SearchModeAuto
 	}
 	if config.Timeout == 0 {
 		config.Timeout = 5 * time.Second
@@ -43,21 +112,73 @@ func NewClient(config ClientConfig) *Client {
 	if config.RateLimit == 0 {
 		config.RateLimit = rate.Every(time.Second) // 1 запрос в секунду
 	}
+	if config.MaxResults <= 0 {
+		config.MaxResults = defaultHTMLMaxResults
+	}
+	if config.NegativeCacheTTL == 0 {
+		config.NegativeCacheTTL = DefaultNegativeCacheTTL
+	}
 
+	limiter := rate.NewLimiter(config.RateLimit, 1)
 	return &Client{
-		baseURL: config.BaseURL,
-		httpClient: &http.Client{
-			Timeout: config.Timeout,
-		},
-		timeout: config.Timeout,
-		limiter: rate.NewLimiter(config.RateLimit, 1),
-		cache:   config.Cache,
+		baseURL:          strings.TrimRight(config.BaseURL, "/"),
+		htmlBaseURL:      strings.TrimRight(config.HTMLBaseURL, "/"),
+		mode:             config.SearchMode,
+		httpClient:       newSearchHTTPClient(config.Timeout, config.MaxRetries, limiter),
+		timeout:          config.Timeout,
+		limiter:          limiter,
+		cache:            config.Cache,
+		maxResults:       config.MaxResults,
+		negativeCacheTTL: config.NegativeCacheTTL,
+		minConfidence:    config.MinConfidence,
 	}
 }
 
-// Search выполняет поиск по запросу
-// Сначала пытается использовать Instant Answer API, если результатов нет - использует HTML-поиск
+// MinConfidence возвращает порог уверенности для валидаторов (ClientConfig.MinConfidence)
+func (c *Client) MinConfidence() float64 {
+	return c.minConfidence
+}
+
+// cacheResult сохраняет результат поиска в кэш: результаты без найденных страниц хранятся
+// negativeCacheTTL, чтобы повторные проверки отсутствующих товаров не обращались к сети
+func (c *Client) cacheResult(key string, result *types.SearchResult) {
+	if c.cache == nil || result == nil {
+		return
+	}
+	if result.Found {
+		c.cache.Set(key, result)
+		return
+	}
+	if c.negativeCacheTTL > 0 {
+		c.cache.SetWithTTL(key, result, c.negativeCacheTTL)
+	}
+}
+
+// newSearchHTTPClient создает HTTP-клиент поиска с повторами при временных ошибках.
+// Каждый повтор, как и первый запрос, ожидает limiter, поэтому повторы не превышают лимит запросов.
+func newSearchHTTPClient(timeout time.Duration, maxRetries int, limiter *rate.Limiter) *http.Client {
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	return httpclient.New(httpclient.Options{
+		Timeout:     timeout,
+		RetryCount:  maxRetries,
+		RetryJitter: httpclient.DefaultRetryJitter,
+		RetryWait:   limiter.Wait,
+	})
+}
+
+// Search выполняет поиск по запросу в соответствии с режимом клиента:
+// SearchModeHTML - только HTML-поиск, SearchModeAPI - только Instant Answer API,
+// SearchModeAuto - сначала Instant Answer API, если результатов нет - HTML-поиск
 func (c *Client) Search(ctx context.Context, query string) (*types.SearchResult, error) {
+	if c.mode == SearchModeHTML {
+		return c.SearchHTML(ctx, query)
+	}
+
 	// Валидация и санитизация запроса
 	query = sanitizeQuery(query)
 	if query == "" {
@@ -74,16 +195,27 @@ func (c *Client) Search(ctx context.Context, query string) (*types.SearchResult,
 
 	// Сначала пробуем Instant Answer API
 	result, err := c.searchInstantAnswer(ctx, query)
+	if c.mode == SearchModeAPI {
+		if err != nil {
+			return nil, err
+		}
+		c.cacheResult(cacheKey, result)
+		return result, nil
+	}
 	if err == nil && result != nil && result.Found && len(result.Results) > 0 {
 		// Сохранение в кэш
-		if c.cache != nil {
-			c.cache.Set(cacheKey, result)
-		}
+		c.cacheResult(cacheKey, result)
 		return result, nil
 	}
 
-	// Если Instant Answer не дал результатов, используем HTML-поиск
-	return c.SearchHTML(ctx, query)
+	// Если Instant Answer не дал результатов, используем HTML-поиск.
+	// Отрицательный результат кэшируется и под основным ключом, чтобы повторный запрос
+	// не обращался к Instant Answer API
+	result, err = c.SearchHTML(ctx, query)
+	if err == nil && !result.Found {
+		c.cacheResult(cacheKey, result)
+	}
+	return result, err
 }
 
 // searchInstantAnswer выполняет поиск через Instant Answer API
@@ -219,4 +351,3 @@ func generateCacheKey(query string) string {
 	hash := sha256.Sum256([]byte(strings.ToLower(query)))
 	return hex.EncodeToString(hash[:])
 }
-
@@ -2,6 +2,11 @@ package websearch
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -108,6 +113,252 @@ func TestExtractTitle(t *testing.T) {
 	}
 }
 
+// largeHTMLPage строит страницу выдачи DuckDuckGo с count результатами.
+// Результаты с номерами из matching содержат запрос в заголовке
+func largeHTMLPage(count int, query string, matching ...int) string {
+	isMatching := make(map[int]bool, len(matching))
+	for _, i := range matching {
+		isMatching[i] = true
+	}
+
+	var page strings.Builder
+	page.WriteString("<html><body><div id=\"links\">")
+	for i := 0; i < count; i++ {
+		title := fmt.Sprintf("Result %d", i)
+		if isMatching[i] {
+			title += " " + query
+		}
+		fmt.Fprintf(&page, `<div class="result"><a href="https://example.com/%d">%s</a><div class="snippet">Snippet %d</div></div>`, i, title, i)
+	}
+	page.WriteString("</div></body></html>")
+	return page.String()
+}
+
+func TestParseHTMLResults_MaxResults(t *testing.T) {
+	const query = "болт м10"
+
+	tests := []struct {
+		name       string
+		maxResults int
+		want       int
+	}{
+		{name: "default limit", maxResults: 0, want: defaultHTMLMaxResults},
+		{name: "custom limit", maxResults: 5, want: 5},
+		{name: "limit above page size", maxResults: 2000, want: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient(ClientConfig{MaxResults: tt.maxResults})
+			page := largeHTMLPage(1000, query, 3, 900)
+
+			result, err := client.parseHTMLResults(strings.NewReader(page), query)
+			if err != nil {
+				t.Fatalf("parseHTMLResults failed: %v", err)
+			}
+			if len(result.Results) != tt.want {
+				t.Fatalf("Expected %d results, got %d", tt.want, len(result.Results))
+			}
+
+			// Совпадение из ограниченного набора поднимается наверх, порядок остальных сохраняется
+			if result.Results[0].URL != "https://example.com/3" {
+				t.Errorf("Expected matching result first, got %s", result.Results[0].URL)
+			}
+			second := "https://example.com/0"
+			if tt.want > 900 {
+				second = "https://example.com/900"
+			}
+			if result.Results[1].URL != second {
+				t.Errorf("Expected %s second, got %s", second, result.Results[1].URL)
+			}
+
+			// Результаты за пределами лимита не извлекаются
+			if last := result.Results[len(result.Results)-1].URL; last != fmt.Sprintf("https://example.com/%d", tt.want-1) {
+				t.Errorf("Expected last retained result %d, got %s", tt.want-1, last)
+			}
+		})
+	}
+}
+
+// TestClientSearch_Modes проверяет выбор эндпоинта DuckDuckGo в зависимости от режима поиска
+func TestClientSearch_Modes(t *testing.T) {
+	var apiHits, htmlHits int
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apiHits++
+		if r.URL.Query().Get("format") != "json" {
+			t.Errorf("Expected JSON format request to API, got %s", r.URL.RawQuery)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("q") == "пусто" {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		fmt.Fprint(w, `{"Abstract":"Болт","AbstractText":"Болт - крепежное изделие","AbstractURL":"https://example.com/bolt"}`)
+	}))
+	defer apiServer.Close()
+
+	htmlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		htmlHits++
+		if r.URL.Path != "/html/" {
+			t.Errorf("Unexpected HTML search path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `<html><body><div class="result"><a href="/gost/7798">ГОСТ 7798 болт</a><div class="snippet">Болты</div></div></body></html>`)
+	}))
+	defer htmlServer.Close()
+
+	newClient := func(mode SearchMode) *Client {
+		apiHits, htmlHits = 0, 0
+		return NewClient(ClientConfig{
+			BaseURL:     apiServer.URL,
+			HTMLBaseURL: htmlServer.URL + "/",
+			SearchMode:  mode,
+			RateLimit:   rate.Inf,
+		})
+	}
+	ctx := context.Background()
+
+	client := newClient(SearchModeHTML)
+	result, err := client.Search(ctx, "болт")
+	if err != nil {
+		t.Fatalf("HTML mode search failed: %v", err)
+	}
+	if result.Source != "duckduckgo-html" || apiHits != 0 || htmlHits != 1 {
+		t.Errorf("Expected only HTML endpoint in HTML mode, got source %q, api %d, html %d", result.Source, apiHits, htmlHits)
+	}
+	if len(result.Results) != 1 || result.Results[0].URL != htmlServer.URL+"/gost/7798" {
+		t.Errorf("Expected relative link resolved against HTML endpoint, got %+v", result.Results)
+	}
+
+	client = newClient(SearchModeAPI)
+	result, err = client.Search(ctx, "болт")
+	if err != nil {
+		t.Fatalf("API mode search failed: %v", err)
+	}
+	if result.Source != "duckduckgo" || !result.Found || apiHits != 1 {
+		t.Errorf("Expected Instant Answer result in API mode, got %+v (api %d)", result, apiHits)
+	}
+	result, err = client.Search(ctx, "пусто")
+	if err != nil {
+		t.Fatalf("API mode search failed: %v", err)
+	}
+	if result.Found || htmlHits != 0 {
+		t.Errorf("Expected empty result without HTML fallback in API mode, got found=%v, html %d", result.Found, htmlHits)
+	}
+
+	client = newClient(SearchModeAuto)
+	result, err = client.Search(ctx, "пусто")
+	if err != nil {
+		t.Fatalf("Auto mode search failed: %v", err)
+	}
+	if result.Source != "duckduckgo-html" || apiHits != 1 || htmlHits != 1 {
+		t.Errorf("Expected HTML fallback in auto mode, got source %q, api %d, html %d", result.Source, apiHits, htmlHits)
+	}
+
+	if _, err := ParseSearchMode("scrape"); err == nil {
+		t.Error("Expected error for unknown search mode")
+	}
+	if mode, err := ParseSearchMode(" HTML "); err != nil || mode != SearchModeHTML {
+		t.Errorf("Expected html mode, got %q (%v)", mode, err)
+	}
+}
+
+// TestClientSearchHTML_RetriesAfterTooManyRequests проверяет повтор после 429 с Retry-After
+func TestClientSearchHTML_RetriesAfterTooManyRequests(t *testing.T) {
+	var hits, failures int32 = 0, 1
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) <= atomic.LoadInt32(&failures) {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprint(w, `<html><body><div class="result"><a href="https://example.com/bolt">Болт М10</a></div></body></html>`)
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{HTMLBaseURL: server.URL, SearchMode: SearchModeHTML, RateLimit: rate.Inf})
+	result, err := client.Search(context.Background(), "болт")
+	if err != nil {
+		t.Fatalf("Search failed after retry: %v", err)
+	}
+	if !result.Found || atomic.LoadInt32(&hits) != 2 {
+		t.Errorf("Expected result after 2 requests, got found=%v after %d", result.Found, hits)
+	}
+
+	// MaxRetries ограничивает число попыток
+	atomic.StoreInt32(&hits, 0)
+	atomic.StoreInt32(&failures, 100)
+	client = NewClient(ClientConfig{HTMLBaseURL: server.URL, SearchMode: SearchModeHTML, RateLimit: rate.Inf, MaxRetries: 3})
+	if _, err := client.Search(context.Background(), "гайка"); err == nil {
+		t.Error("Expected error when all attempts are rate limited")
+	}
+	if got := atomic.LoadInt32(&hits); got != 4 {
+		t.Errorf("Expected 4 attempts with MaxRetries=3, got %d", got)
+	}
+}
+
+// TestClientSearchHTML_ContextCancelStopsRetries проверяет, что отмена контекста прерывает ожидание повтора
+func TestClientSearchHTML_ContextCancelStopsRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(ClientConfig{HTMLBaseURL: server.URL, SearchMode: SearchModeHTML, RateLimit: rate.Inf, Timeout: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, err := client.Search(ctx, "болт"); err == nil {
+		t.Fatal("Expected error after context cancellation")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected retries to stop promptly, took %v", elapsed)
+	}
+}
+
+// TestClientSearch_CachesNegativeResults проверяет, что "не найдено" кэшируется на NegativeCacheTTL
+func TestClientSearch_CachesNegativeResults(t *testing.T) {
+	var apiHits, htmlHits int32
+	apiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&apiHits, 1)
+		fmt.Fprint(w, `{}`)
+	}))
+	defer apiServer.Close()
+	htmlServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&htmlHits, 1)
+		fmt.Fprint(w, `<html><body>Ничего не найдено</body></html>`)
+	}))
+	defer htmlServer.Close()
+
+	cache := NewCache(&CacheConfig{Enabled: true, TTL: 24 * time.Hour})
+	client := NewClient(ClientConfig{
+		BaseURL:          apiServer.URL,
+		HTMLBaseURL:      htmlServer.URL,
+		RateLimit:        rate.Inf,
+		Cache:            cache,
+		NegativeCacheTTL: time.Minute,
+	})
+
+	for i := 0; i < 3; i++ {
+		result, err := client.Search(context.Background(), "несуществующий товар")
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if result.Found {
+			t.Fatal("Expected not found result")
+		}
+	}
+	if apiHits != 1 || htmlHits != 1 {
+		t.Errorf("Expected negative result to be served from cache, got api %d, html %d", apiHits, htmlHits)
+	}
+
+	entry := cache.data[generateCacheKey("несуществующий товар")]
+	if entry == nil || time.Until(entry.Expiration) > time.Minute {
+		t.Errorf("Expected negative result cached for NegativeCacheTTL, got %+v", entry)
+	}
+}
+
 // Интеграционный тест (требует интернет-соединения)
 func TestClientSearch_Integration(t *testing.T) {
 	if testing.Short() {
@@ -0,0 +1,77 @@
+package websearch
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// htmlQueryPlaceholder место запроса в HTMLSearchConfig.URLTemplate
+const htmlQueryPlaceholder = "{query}"
+
+// HTMLSearchConfig конфигурация клиента для произвольного поискового сервиса с HTML-выдачей
+// (собственный SearXNG, корпоративный поиск и т.п.)
+type HTMLSearchConfig struct {
+	Name         string // Имя провайдера в SearchResult.Source (по умолчанию "html")
+	URLTemplate  string // Адрес страницы результатов, {query} заменяется запросом: https://search.example.com/search?q={query}
+	ResultClass  string // Класс элемента результата; пусто - те же правила, что для DuckDuckGo
+	SnippetClass string // Класс сниппета внутри результата; пусто - те же правила, что для DuckDuckGo
+	Timeout      time.Duration
+	RateLimit    rate.Limit
+	Cache        *Cache
+	MaxResults   int // Максимум результатов, извлекаемых из HTML-страницы (по умолчанию 30)
+	MaxRetries   int // Число повторов при временных ошибках (см. ClientConfig.MaxRetries)
+	// NegativeCacheTTL и MinConfidence - см. ClientConfig
+	NegativeCacheTTL time.Duration
+	MinConfidence    float64
+}
+
+// NewHTMLSearchClient создает клиент HTML-поиска через сервис config.URLTemplate.
+// Клиент работает в режиме SearchModeHTML: Search и SearchHTML разбирают страницу результатов
+// так же, как выдачу DuckDuckGo, но по классам ResultClass и SnippetClass, если они заданы.
+func NewHTMLSearchClient(config HTMLSearchConfig) (*Client, error) {
+	if !strings.Contains(config.URLTemplate, htmlQueryPlaceholder) {
+		return nil, fmt.Errorf("html search url template %q must contain %s", config.URLTemplate, htmlQueryPlaceholder)
+	}
+	endpoint, err := url.Parse(strings.ReplaceAll(config.URLTemplate, htmlQueryPlaceholder, ""))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid html search url template %q", config.URLTemplate)
+	}
+
+	if config.Name == "" {
+		config.Name = "html"
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.RateLimit == 0 {
+		config.RateLimit = rate.Every(time.Second)
+	}
+	if config.MaxResults <= 0 {
+		config.MaxResults = defaultHTMLMaxResults
+	}
+	if config.NegativeCacheTTL == 0 {
+		config.NegativeCacheTTL = DefaultNegativeCacheTTL
+	}
+
+	limiter := rate.NewLimiter(config.RateLimit, 1)
+	return &Client{
+		// Относительные ссылки в выдаче разрешаются относительно адреса сервиса
+		htmlBaseURL:      endpoint.Scheme + "://" + endpoint.Host,
+		mode:             SearchModeHTML,
+		httpClient:       newSearchHTTPClient(config.Timeout, config.MaxRetries, limiter),
+		timeout:          config.Timeout,
+		limiter:          limiter,
+		cache:            config.Cache,
+		maxResults:       config.MaxResults,
+		negativeCacheTTL: config.NegativeCacheTTL,
+		minConfidence:    config.MinConfidence,
+		htmlURLTemplate:  config.URLTemplate,
+		htmlSource:       config.Name,
+		resultClass:      config.ResultClass,
+		snippetClass:     config.SnippetClass,
+	}, nil
+}
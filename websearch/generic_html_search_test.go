@@ -0,0 +1,62 @@
+package websearch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestNewHTMLSearchClient_InvalidTemplate(t *testing.T) {
+	for _, template := range []string{"", "https://search.example.com/search?q=", "search?q={query}"} {
+		if _, err := NewHTMLSearchClient(HTMLSearchConfig{URLTemplate: template}); err == nil {
+			t.Errorf("Expected error for template %q", template)
+		}
+	}
+}
+
+func TestHTMLSearchClient_Search(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query().Get("text")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<html><body>
+			<div class="header">Поиск</div>
+			<li class="serp-item"><a href="/go?to=1">Болт М10 ГОСТ 7798-70</a><div class="serp-text">Болт с шестигранной головкой</div></li>
+			<li class="serp-item"><a href="https://shop.example.com/bolt">Купить болт М10</a><div class="serp-text">Крепеж оптом</div></li>
+		</body></html>`))
+	}))
+	defer server.Close()
+
+	client, err := NewHTMLSearchClient(HTMLSearchConfig{
+		Name:         "intranet",
+		URLTemplate:  server.URL + "/search?text={query}",
+		ResultClass:  "serp-item",
+		SnippetClass: "serp-text",
+		Timeout:      time.Second,
+		RateLimit:    rate.Inf,
+	})
+	if err != nil {
+		t.Fatalf("NewHTMLSearchClient failed: %v", err)
+	}
+
+	result, err := client.Search(context.Background(), "болт М10")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if gotQuery != "болт М10" {
+		t.Errorf("Expected query to be passed in template, got %q", gotQuery)
+	}
+	if result.Source != "intranet" || !result.Found || len(result.Results) != 2 {
+		t.Fatalf("Unexpected result: source=%s found=%v results=%d", result.Source, result.Found, len(result.Results))
+	}
+	if result.Results[0].URL != server.URL+"/go?to=1" {
+		t.Errorf("Expected relative link to be resolved against the service, got %s", result.Results[0].URL)
+	}
+	if result.Results[0].Snippet != "Болт с шестигранной головкой" {
+		t.Errorf("Expected snippet from configured class, got %q", result.Results[0].Snippet)
+	}
+}
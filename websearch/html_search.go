@@ -6,6 +6,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -13,7 +14,10 @@ import (
 	"httpserver/websearch/types"
 )
 
-// SearchHTML выполняет HTML-поиск через DuckDuckGo
+// defaultHTMLMaxResults ограничение числа результатов HTML-поиска, если ClientConfig.MaxResults не задан
+const defaultHTMLMaxResults = 30
+
+// SearchHTML выполняет HTML-поиск через DuckDuckGo (или через сервис, заданный в NewHTMLSearchClient)
 // Этот метод парсит HTML-страницы результатов поиска и извлекает ссылки и сниппеты
 func (c *Client) SearchHTML(ctx context.Context, query string) (*SearchResult, error) {
 	// Валидация и санитизация запроса
@@ -23,7 +27,7 @@ func (c *Client) SearchHTML(ctx context.Context, query string) (*SearchResult, e
 	}
 
 	// Проверка кэша (используем отдельный ключ для HTML-поиска)
-	cacheKey := generateCacheKey("html:" + query)
+	cacheKey := generateCacheKey(c.htmlCachePrefix() + query)
 	if c.cache != nil {
 		if cached, found := c.cache.Get(cacheKey); found {
 			return cached, nil
@@ -36,7 +40,7 @@ func (c *Client) SearchHTML(ctx context.Context, query string) (*SearchResult, e
 	}
 
 	// Формирование URL для HTML-поиска
-	searchURL := fmt.Sprintf("https://html.duckduckgo.com/html/?q=%s", url.QueryEscape(query))
+	searchURL := c.htmlSearchURL(query)
 
 	// Создание запроса с контекстом
 	req, err := http.NewRequestWithContext(ctx, "GET", searchURL, nil)
@@ -67,9 +71,7 @@ func (c *Client) SearchHTML(ctx context.Context, query string) (*SearchResult, e
 	}
 
 	// Сохранение в кэш
-	if c.cache != nil {
-		c.cache.Set(cacheKey, result)
-	}
+	c.cacheResult(cacheKey, result)
 
 	return result, nil
 }
@@ -78,7 +80,7 @@ func (c *Client) SearchHTML(ctx context.Context, query string) (*SearchResult, e
 func (c *Client) parseHTMLResults(body io.Reader, query string) (*types.SearchResult, error) {
 	result := &types.SearchResult{
 		Query:     query,
-		Source:    "duckduckgo-html",
+		Source:    c.htmlSourceName(),
 		Timestamp: time.Now(),
 		Results:   make([]types.SearchItem, 0),
 	}
@@ -90,8 +92,12 @@ func (c *Client) parseHTMLResults(body io.Reader, query string) (*types.SearchRe
 	}
 
 	// Ищем результаты поиска
-	// DuckDuckGo использует класс "result" для результатов поиска
-	c.extractResults(doc, result)
+	// DuckDuckGo использует класс "result" для результатов поиска.
+	// Извлекаются только первые результаты выдачи: вызывающий код использует лишь верхние
+	c.extractResults(doc, result, c.htmlResultLimit())
+
+	// Упорядочиваем ограниченный набор по релевантности запросу
+	rankHTMLResults(result.Results, query)
 
 	// Определяем уверенность на основе количества результатов
 	if len(result.Results) > 0 {
@@ -105,8 +111,53 @@ func (c *Client) parseHTMLResults(body io.Reader, query string) (*types.SearchRe
 	return result, nil
 }
 
-// extractResults извлекает результаты поиска из HTML-дерева
-func (c *Client) extractResults(n *html.Node, result *types.SearchResult) {
+// htmlEndpoint возвращает адрес HTML-поиска DuckDuckGo
+func (c *Client) htmlEndpoint() string {
+	if c.htmlBaseURL == "" {
+		return DefaultHTMLBaseURL
+	}
+	return c.htmlBaseURL
+}
+
+// htmlSearchURL возвращает адрес страницы результатов HTML-поиска для запроса
+func (c *Client) htmlSearchURL(query string) string {
+	if c.htmlURLTemplate != "" {
+		return strings.ReplaceAll(c.htmlURLTemplate, htmlQueryPlaceholder, url.QueryEscape(query))
+	}
+	return fmt.Sprintf("%s/html/?q=%s", c.htmlEndpoint(), url.QueryEscape(query))
+}
+
+// htmlSourceName возвращает имя провайдера для SearchResult.Source
+func (c *Client) htmlSourceName() string {
+	if c.htmlSource != "" {
+		return c.htmlSource
+	}
+	return "duckduckgo-html"
+}
+
+// htmlCachePrefix префикс ключа кэша HTML-поиска: клиенты разных сервисов могут использовать общий кэш
+func (c *Client) htmlCachePrefix() string {
+	if c.htmlSource != "" {
+		return "html:" + c.htmlSource + ":"
+	}
+	return "html:"
+}
+
+// htmlResultLimit возвращает максимальное число извлекаемых результатов HTML-поиска
+func (c *Client) htmlResultLimit() int {
+	if c.maxResults <= 0 {
+		return defaultHTMLMaxResults
+	}
+	return c.maxResults
+}
+
+// extractResults извлекает не более limit результатов поиска из HTML-дерева.
+// После достижения лимита обход дерева прекращается
+func (c *Client) extractResults(n *html.Node, result *types.SearchResult, limit int) {
+	if len(result.Results) >= limit {
+		return
+	}
+
 	if n.Type == html.ElementNode {
 		// Ищем элементы с классом "result" или "web-result"
 		if c.isResultNode(n) {
@@ -118,11 +169,29 @@ func (c *Client) extractResults(n *html.Node, result *types.SearchResult) {
 	}
 
 	// Рекурсивно обходим дочерние узлы
-	for child := n.FirstChild; child != nil; child = child.NextSibling {
-		c.extractResults(child, result)
+	for child := n.FirstChild; child != nil && len(result.Results) < limit; child = child.NextSibling {
+		c.extractResults(child, result, limit)
 	}
 }
 
+// rankHTMLResults повышает релевантность результатов, содержащих запрос, и сортирует их по убыванию.
+// При равной релевантности сохраняется порядок выдачи DuckDuckGo
+func rankHTMLResults(results []types.SearchItem, query string) {
+	queryLower := strings.ToLower(query)
+	for i := range results {
+		if strings.Contains(strings.ToLower(results[i].Title), queryLower) {
+			results[i].Relevance += 0.3
+		}
+		if strings.Contains(strings.ToLower(results[i].Snippet), queryLower) {
+			results[i].Relevance += 0.1
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Relevance > results[j].Relevance
+	})
+}
+
 // isResultNode проверяет, является ли узел результатом поиска
 func (c *Client) isResultNode(n *html.Node) bool {
 	if n.Type != html.ElementNode {
@@ -134,10 +203,7 @@ func (c *Client) isResultNode(n *html.Node) bool {
 		if attr.Key == "class" {
 			classes := strings.Fields(attr.Val)
 			for _, class := range classes {
-				// DuckDuckGo использует различные классы для результатов
-				if strings.Contains(class, "result") ||
-					strings.Contains(class, "web-result") ||
-					strings.Contains(class, "links_main") {
+				if c.isResultClass(class) {
 					return true
 				}
 			}
@@ -147,6 +213,17 @@ func (c *Client) isResultNode(n *html.Node) bool {
 	return false
 }
 
+// isResultClass проверяет, является ли класс элемента классом результата поиска
+func (c *Client) isResultClass(class string) bool {
+	if c.resultClass != "" {
+		return class == c.resultClass
+	}
+	// DuckDuckGo использует различные классы для результатов
+	return strings.Contains(class, "result") ||
+		strings.Contains(class, "web-result") ||
+		strings.Contains(class, "links_main")
+}
+
 // extractResultItem извлекает информацию о результате из HTML-узла
 func (c *Client) extractResultItem(n *html.Node) *types.SearchItem {
 	item := &types.SearchItem{
@@ -222,7 +299,7 @@ func (c *Client) findLink(n *html.Node) *linkInfo {
 					if strings.HasPrefix(href, "//") {
 						href = "https:" + href
 					} else if strings.HasPrefix(href, "/") {
-						href = "https://html.duckduckgo.com" + href
+						href = c.htmlEndpoint() + href
 					}
 				}
 			}
@@ -259,9 +336,7 @@ func (c *Client) findSnippet(n *html.Node) string {
 			if attr.Key == "class" {
 				classes := strings.Fields(attr.Val)
 				for _, class := range classes {
-					if strings.Contains(class, "snippet") ||
-						strings.Contains(class, "result__snippet") ||
-						strings.Contains(class, "result__body") {
+					if c.isSnippetClass(class) {
 						text := c.extractText(n)
 						if text != "" {
 							return strings.TrimSpace(text)
@@ -282,6 +357,16 @@ func (c *Client) findSnippet(n *html.Node) string {
 	return ""
 }
 
+// isSnippetClass проверяет, является ли класс элемента классом сниппета
+func (c *Client) isSnippetClass(class string) bool {
+	if c.snippetClass != "" {
+		return class == c.snippetClass
+	}
+	return strings.Contains(class, "snippet") ||
+		strings.Contains(class, "result__snippet") ||
+		strings.Contains(class, "result__body")
+}
+
 // extractText извлекает текст из узла и его дочерних элементов
 func (c *Client) extractText(n *html.Node) string {
 	var text strings.Builder
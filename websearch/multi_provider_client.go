@@ -3,6 +3,7 @@ package websearch
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -110,15 +111,35 @@ func (mpc *MultiProviderClient) GetStats() map[string]interface{} {
 
 	stats := make(map[string]interface{})
 	for name, provider := range mpc.providers {
-		stats[name] = map[string]interface{}{
+		providerStats := map[string]interface{}{
 			"available": provider.IsAvailable(),
 			"rate_limit": provider.GetRateLimit().String(),
 		}
+		if tracked, ok := provider.(*HealthTrackedProvider); ok {
+			providerStats["health"] = tracked.Health()
+		}
+		stats[name] = providerStats
 	}
 
 	return stats
 }
 
+// GetProviderHealth возвращает состояние здоровья провайдеров, обернутых HealthTrackedProvider
+func (mpc *MultiProviderClient) GetProviderHealth() []ProviderHealth {
+	mpc.mu.RLock()
+	defer mpc.mu.RUnlock()
+
+	health := make([]ProviderHealth, 0, len(mpc.providers))
+	for _, provider := range mpc.providers {
+		if tracked, ok := provider.(*HealthTrackedProvider); ok {
+			health = append(health, tracked.Health())
+		}
+	}
+	sort.Slice(health, func(i, j int) bool { return health[i].Provider < health[j].Provider })
+
+	return health
+}
+
 // GetCacheStats возвращает статистику кэша
 func (mpc *MultiProviderClient) GetCacheStats() map[string]interface{} {
 	if mpc.cache == nil {
@@ -0,0 +1,225 @@
+package websearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"httpserver/websearch/types"
+)
+
+// ErrProviderDisabled провайдер временно отключен из-за низкой доли успешных запросов
+var ErrProviderDisabled = errors.New("search provider is temporarily disabled")
+
+// ProviderHealthConfig настройки отслеживания здоровья провайдера
+type ProviderHealthConfig struct {
+	// WindowSize число последних запросов, по которым считается доля успешных
+	WindowSize int
+	// MinSamples минимальное число запросов в окне, после которого провайдер может быть отключен
+	MinSamples int
+	// MinSuccessRate провайдер отключается, если доля успешных запросов ниже порога
+	MinSuccessRate float64
+	// ProbeInterval интервал повторной проверки отключенного провайдера через HealthCheck
+	ProbeInterval time.Duration
+}
+
+// DefaultProviderHealthConfig возвращает настройки отслеживания здоровья по умолчанию
+func DefaultProviderHealthConfig() ProviderHealthConfig {
+	return ProviderHealthConfig{
+		WindowSize:     20,
+		MinSamples:     10,
+		MinSuccessRate: 0.2,
+		ProbeInterval:  5 * time.Minute,
+	}
+}
+
+// ProviderHealth состояние здоровья провайдера
+type ProviderHealth struct {
+	Provider    string     `json:"provider"`
+	Healthy     bool       `json:"healthy"`
+	SuccessRate float64    `json:"success_rate"`
+	Samples     int        `json:"samples"`
+	DisabledAt  *time.Time `json:"disabled_at,omitempty"`
+	NextProbeAt *time.Time `json:"next_probe_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+}
+
+// HealthTrackedProvider обертка провайдера, отслеживающая долю успешных запросов.
+// Успешным считается запрос без ошибки с непустым результатом: деградировавший DuckDuckGo
+// отвечает без ошибок, но ничего не находит. При доле успешных ниже порога провайдер
+// временно отключается (IsAvailable возвращает false), и роутер переходит к следующему.
+// Раз в ProbeInterval провайдер снова становится доступен, и первый запрос к нему
+// предваряется HealthCheck: при успехе провайдер включается, иначе остается отключенным.
+type HealthTrackedProvider struct {
+	types.SearchProviderInterface
+	config ProviderHealthConfig
+	now    func() time.Time
+
+	mu         sync.Mutex
+	outcomes   []bool
+	next       int
+	samples    int
+	disabledAt time.Time
+	nextProbe  time.Time
+	lastError  string
+}
+
+// NewHealthTrackedProvider оборачивает провайдера отслеживанием здоровья
+func NewHealthTrackedProvider(provider types.SearchProviderInterface, config ProviderHealthConfig) *HealthTrackedProvider {
+	defaults := DefaultProviderHealthConfig()
+	if config.WindowSize <= 0 {
+		config.WindowSize = defaults.WindowSize
+	}
+	if config.MinSamples <= 0 || config.MinSamples > config.WindowSize {
+		config.MinSamples = config.WindowSize
+	}
+	if config.ProbeInterval <= 0 {
+		config.ProbeInterval = defaults.ProbeInterval
+	}
+
+	return &HealthTrackedProvider{
+		SearchProviderInterface: provider,
+		config:                  config,
+		now:                     time.Now,
+		outcomes:                make([]bool, config.WindowSize),
+	}
+}
+
+// WithHealthTracking оборачивает все провайдеры отслеживанием здоровья
+func WithHealthTracking(providers map[string]types.SearchProviderInterface, config ProviderHealthConfig) map[string]types.SearchProviderInterface {
+	wrapped := make(map[string]types.SearchProviderInterface, len(providers))
+	for name, provider := range providers {
+		if _, ok := provider.(*HealthTrackedProvider); ok {
+			wrapped[name] = provider
+			continue
+		}
+		wrapped[name] = NewHealthTrackedProvider(provider, config)
+	}
+	return wrapped
+}
+
+// IsAvailable возвращает false, пока провайдер отключен и не подошло время повторной проверки
+func (p *HealthTrackedProvider) IsAvailable() bool {
+	if !p.SearchProviderInterface.IsAvailable() {
+		return false
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.disabledAt.IsZero() || !p.now().Before(p.nextProbe)
+}
+
+// Search выполняет поиск и учитывает его результат в доле успешных запросов.
+// Для отключенного провайдера сначала выполняется HealthCheck.
+func (p *HealthTrackedProvider) Search(ctx context.Context, query string) (*types.SearchResult, error) {
+	if err := p.probeIfDisabled(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := p.SearchProviderInterface.Search(ctx, query)
+	switch {
+	case err != nil:
+		p.record(false, err.Error())
+	case result == nil || len(result.Results) == 0:
+		p.record(false, "empty result")
+	default:
+		p.record(true, "")
+	}
+	return result, err
+}
+
+// probeIfDisabled проверяет отключенный провайдер, если подошло время повторной проверки.
+// Проверку выполняет один запрос: следующая откладывается до ее начала.
+func (p *HealthTrackedProvider) probeIfDisabled(ctx context.Context) error {
+	p.mu.Lock()
+	if p.disabledAt.IsZero() {
+		p.mu.Unlock()
+		return nil
+	}
+	now := p.now()
+	if now.Before(p.nextProbe) {
+		p.mu.Unlock()
+		return fmt.Errorf("%s: %w", p.GetName(), ErrProviderDisabled)
+	}
+	p.nextProbe = now.Add(p.config.ProbeInterval)
+	p.mu.Unlock()
+
+	if err := p.HealthCheck(ctx); err != nil {
+		p.mu.Lock()
+		p.lastError = err.Error()
+		p.mu.Unlock()
+		return fmt.Errorf("%s: %w: %v", p.GetName(), ErrProviderDisabled, err)
+	}
+
+	p.mu.Lock()
+	p.disabledAt = time.Time{}
+	p.nextProbe = time.Time{}
+	p.samples = 0
+	p.next = 0
+	p.mu.Unlock()
+	log.Printf("[WebSearch] Provider %s passed health check and is enabled again", p.GetName())
+	return nil
+}
+
+// record учитывает результат запроса и отключает провайдера при доле успешных ниже порога
+func (p *HealthTrackedProvider) record(success bool, errMsg string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.outcomes[p.next] = success
+	p.next = (p.next + 1) % len(p.outcomes)
+	if p.samples < len(p.outcomes) {
+		p.samples++
+	}
+	if !success {
+		p.lastError = errMsg
+	}
+
+	if !p.disabledAt.IsZero() || p.samples < p.config.MinSamples {
+		return
+	}
+	if rate := p.successRateLocked(); rate < p.config.MinSuccessRate {
+		now := p.now()
+		p.disabledAt = now
+		p.nextProbe = now.Add(p.config.ProbeInterval)
+		log.Printf("[WebSearch] Provider %s disabled: success rate %.2f over last %d requests (last error: %s)",
+			p.GetName(), rate, p.samples, p.lastError)
+	}
+}
+
+// successRateLocked доля успешных запросов в окне (вызывается под p.mu)
+func (p *HealthTrackedProvider) successRateLocked() float64 {
+	if p.samples == 0 {
+		return 1
+	}
+	successes := 0
+	for i := 0; i < p.samples; i++ {
+		if p.outcomes[i] {
+			successes++
+		}
+	}
+	return float64(successes) / float64(p.samples)
+}
+
+// Health возвращает текущее состояние здоровья провайдера
+func (p *HealthTrackedProvider) Health() ProviderHealth {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	health := ProviderHealth{
+		Provider:    p.GetName(),
+		Healthy:     p.disabledAt.IsZero(),
+		SuccessRate: p.successRateLocked(),
+		Samples:     p.samples,
+		LastError:   p.lastError,
+	}
+	if !p.disabledAt.IsZero() {
+		disabledAt, nextProbe := p.disabledAt, p.nextProbe
+		health.DisabledAt = &disabledAt
+		health.NextProbeAt = &nextProbe
+	}
+	return health
+}
@@ -0,0 +1,119 @@
+package websearch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"httpserver/websearch/types"
+)
+
+// flakyProvider провайдер, который перестает находить результаты при degraded = true
+type flakyProvider struct {
+	mockProvider
+	degraded bool
+}
+
+func (f *flakyProvider) Search(ctx context.Context, query string) (*types.SearchResult, error) {
+	if f.degraded {
+		return &types.SearchResult{Query: query, Source: f.name}, nil
+	}
+	return &types.SearchResult{
+		Query:   query,
+		Found:   true,
+		Results: []types.SearchItem{{Title: "result", URL: "https://example.com"}},
+		Source:  f.name,
+	}, nil
+}
+
+func (f *flakyProvider) HealthCheck(ctx context.Context) error {
+	if f.degraded {
+		return errors.New("no results")
+	}
+	return nil
+}
+
+func TestHealthTrackedProvider_FallbackAndRecovery(t *testing.T) {
+	primary := &flakyProvider{mockProvider: mockProvider{name: "duckduckgo", available: true}, degraded: true}
+	backup := &mockProvider{name: "backup", available: true, result: &types.SearchResult{
+		Found:   true,
+		Results: []types.SearchItem{{Title: "backup result"}},
+		Source:  "backup",
+	}}
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracked := NewHealthTrackedProvider(primary, ProviderHealthConfig{
+		WindowSize:     4,
+		MinSamples:     4,
+		MinSuccessRate: 0.5,
+		ProbeInterval:  time.Minute,
+	})
+	tracked.now = func() time.Time { return now }
+
+	// Пустые ответы без ошибок считаются неуспешными и отключают провайдера
+	for i := 0; i < 4; i++ {
+		if _, err := tracked.Search(context.Background(), "query"); err != nil {
+			t.Fatalf("Search %d returned error: %v", i, err)
+		}
+	}
+	if tracked.IsAvailable() {
+		t.Fatal("Expected provider to be disabled after empty results")
+	}
+	health := tracked.Health()
+	if health.Healthy || health.SuccessRate != 0 || health.NextProbeAt == nil {
+		t.Fatalf("Unexpected health of disabled provider: %+v", health)
+	}
+	if _, err := tracked.Search(context.Background(), "query"); !errors.Is(err, ErrProviderDisabled) {
+		t.Errorf("Expected ErrProviderDisabled, got %v", err)
+	}
+
+	// Роутер переходит к следующему провайдеру
+	router := NewProviderRouter(map[string]types.SearchProviderInterface{
+		"duckduckgo": tracked,
+		"backup":     backup,
+	}, NewStubReliabilityManager(), RouterConfig{Strategy: StrategyRoundRobin})
+	for i := 0; i < 3; i++ {
+		result, err := router.SearchWithFallback(context.Background(), "query", 3)
+		if err != nil {
+			t.Fatalf("SearchWithFallback failed: %v", err)
+		}
+		if result.Source != "backup" {
+			t.Fatalf("Expected fallback to backup provider, got %q", result.Source)
+		}
+	}
+
+	// Повторная проверка до восстановления оставляет провайдера отключенным
+	now = now.Add(time.Minute)
+	if !tracked.IsAvailable() {
+		t.Fatal("Expected provider to be offered for re-probe after probe interval")
+	}
+	if _, err := tracked.Search(context.Background(), "query"); !errors.Is(err, ErrProviderDisabled) {
+		t.Fatalf("Expected failed probe to keep provider disabled, got %v", err)
+	}
+	if tracked.IsAvailable() {
+		t.Fatal("Expected provider to stay disabled until next probe")
+	}
+
+	// После восстановления проверка проходит и провайдер снова используется
+	primary.degraded = false
+	now = now.Add(time.Minute)
+	result, err := tracked.Search(context.Background(), "query")
+	if err != nil {
+		t.Fatalf("Search after recovery failed: %v", err)
+	}
+	if len(result.Results) == 0 {
+		t.Error("Expected results after recovery")
+	}
+	health = tracked.Health()
+	if !health.Healthy || health.Samples != 1 || health.SuccessRate != 1 {
+		t.Errorf("Unexpected health after recovery: %+v", health)
+	}
+
+	client := NewMultiProviderClient(MultiProviderClientConfig{
+		Providers: WithHealthTracking(map[string]types.SearchProviderInterface{"duckduckgo": tracked, "backup": backup}, DefaultProviderHealthConfig()),
+	})
+	if got := client.GetProviderHealth(); len(got) != 2 || got[0].Provider != "backup" || got[1].Provider != "duckduckgo" {
+		t.Errorf("Unexpected provider health snapshot: %+v", got)
+	}
+}
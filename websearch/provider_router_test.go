@@ -32,6 +32,10 @@ func (m *mockProvider) ValidateCredentials(ctx context.Context) error {
 	return nil
 }
 
+func (m *mockProvider) HealthCheck(ctx context.Context) error {
+	return m.ValidateCredentials(ctx)
+}
+
 func (m *mockProvider) Search(ctx context.Context, query string) (*types.SearchResult, error) {
 	if m.shouldErr {
 		return nil, errors.New("search error")
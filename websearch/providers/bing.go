@@ -10,6 +10,7 @@ import (
 
 	"golang.org/x/time/rate"
 
+	"httpserver/internal/httpclient"
 	"httpserver/websearch/types"
 )
 
@@ -28,14 +29,16 @@ func NewBingProvider(apiKey string, timeout time.Duration, rateLimit time.Durati
 	limiter := rate.NewLimiter(rate.Every(rateLimit), 1)
 
 	return &BingProvider{
-		apiKey:  apiKey,
-		baseURL: "https://api.bing.microsoft.com/v7.0/search",
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		limiter:   limiter,
-		rateLimit: rateLimit,
-		available: apiKey != "",
+		apiKey:     apiKey,
+		baseURL:    "https://api.bing.microsoft.com/v7.0/search",
+		httpClient: httpclient.New(httpclient.Options{
+			Timeout:     timeout,
+			RetryCount:  2,
+			RetryJitter: httpclient.DefaultRetryJitter,
+		}),
+		limiter:    limiter,
+		rateLimit:  rateLimit,
+		available:  apiKey != "",
 	}
 }
 
@@ -58,6 +61,11 @@ func (b *BingProvider) ValidateCredentials(ctx context.Context) error {
 	return nil
 }
 
+// HealthCheck проверяет, что провайдер отвечает на пробный запрос непустым результатом
+func (b *BingProvider) HealthCheck(ctx context.Context) error {
+	return probeSearch(ctx, b)
+}
+
 // Search выполняет поиск через Bing Search API
 func (b *BingProvider) Search(ctx context.Context, query string) (*types.SearchResult, error) {
 	if !b.IsAvailable() {
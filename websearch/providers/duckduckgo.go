@@ -10,6 +10,7 @@ import (
 
 	"golang.org/x/time/rate"
 
+	"httpserver/internal/httpclient"
 	"httpserver/websearch/types"
 )
 
@@ -27,13 +28,15 @@ func NewDuckDuckGoProvider(timeout time.Duration, rateLimit time.Duration) *Duck
 	limiter := rate.NewLimiter(rate.Every(rateLimit), 1)
 
 	return &DuckDuckGoProvider{
-		baseURL: "https://api.duckduckgo.com",
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		limiter:   limiter,
-		rateLimit: rateLimit,
-		available: true,
+		baseURL:    "https://api.duckduckgo.com",
+		httpClient: httpclient.New(httpclient.Options{
+			Timeout:     timeout,
+			RetryCount:  2,
+			RetryJitter: httpclient.DefaultRetryJitter,
+		}),
+		limiter:    limiter,
+		rateLimit:  rateLimit,
+		available:  true,
 	}
 }
 
@@ -53,6 +56,11 @@ func (d *DuckDuckGoProvider) ValidateCredentials(ctx context.Context) error {
 	return nil
 }
 
+// HealthCheck проверяет, что провайдер отвечает на пробный запрос непустым результатом
+func (d *DuckDuckGoProvider) HealthCheck(ctx context.Context) error {
+	return probeSearch(ctx, d)
+}
+
 // Search выполняет поиск через DuckDuckGo API
 func (d *DuckDuckGoProvider) Search(ctx context.Context, query string) (*types.SearchResult, error) {
 	if err := d.limiter.Wait(ctx); err != nil {
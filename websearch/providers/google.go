@@ -10,6 +10,7 @@ import (
 
 	"golang.org/x/time/rate"
 
+	"httpserver/internal/httpclient"
 	"httpserver/websearch/types"
 )
 
@@ -29,15 +30,17 @@ func NewGoogleProvider(apiKey, searchID string, timeout time.Duration, rateLimit
 	limiter := rate.NewLimiter(rate.Every(rateLimit), 1)
 
 	return &GoogleProvider{
-		apiKey:    apiKey,
-		searchID:  searchID,
-		baseURL:   "https://www.googleapis.com/customsearch/v1",
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
-		limiter:   limiter,
-		rateLimit: rateLimit,
-		available: apiKey != "" && searchID != "",
+		apiKey:     apiKey,
+		searchID:   searchID,
+		baseURL:    "https://www.googleapis.com/customsearch/v1",
+		httpClient: httpclient.New(httpclient.Options{
+			Timeout:     timeout,
+			RetryCount:  2,
+			RetryJitter: httpclient.DefaultRetryJitter,
+		}),
+		limiter:    limiter,
+		rateLimit:  rateLimit,
+		available:  apiKey != "" && searchID != "",
 	}
 }
 
@@ -62,6 +65,11 @@ func (g *GoogleProvider) ValidateCredentials(ctx context.Context) error {
 	return nil
 }
 
+// HealthCheck проверяет, что провайдер отвечает на пробный запрос непустым результатом
+func (g *GoogleProvider) HealthCheck(ctx context.Context) error {
+	return probeSearch(ctx, g)
+}
+
 // Search выполняет поиск через Google Custom Search API
 func (g *GoogleProvider) Search(ctx context.Context, query string) (*types.SearchResult, error) {
 	if !g.IsAvailable() {
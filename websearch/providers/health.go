@@ -0,0 +1,28 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+
+	"httpserver/websearch/types"
+)
+
+// healthProbeQuery запрос пробной проверки: по нему любой работающий поисковик находит результаты
+const healthProbeQuery = "ГОСТ"
+
+// probeSearch выполняет пробный запрос через провайдера.
+// Пустой результат считается ошибкой: именно так выглядит деградация DuckDuckGo.
+func probeSearch(ctx context.Context, provider types.SearchProviderInterface) error {
+	if !provider.IsAvailable() {
+		return fmt.Errorf("%s provider is not available", provider.GetName())
+	}
+
+	result, err := provider.Search(ctx, healthProbeQuery)
+	if err != nil {
+		return fmt.Errorf("%s health probe failed: %w", provider.GetName(), err)
+	}
+	if result == nil || len(result.Results) == 0 {
+		return fmt.Errorf("%s health probe returned no results", provider.GetName())
+	}
+	return nil
+}
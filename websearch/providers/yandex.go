@@ -50,6 +50,11 @@ func (y *YandexProvider) ValidateCredentials(ctx context.Context) error {
 	return nil
 }
 
+// HealthCheck проверяет, что провайдер отвечает на пробный запрос непустым результатом
+func (y *YandexProvider) HealthCheck(ctx context.Context) error {
+	return probeSearch(ctx, y)
+}
+
 // Search выполняет поиск через Yandex XML Search API
 // Примечание: Yandex XML Search API требует платной подписки и API ключа
 // Для использования необходимо:
@@ -25,7 +25,7 @@ type SearchItem struct {
 
 // ValidationResult результат валидации через веб-поиск
 type ValidationResult struct {
-	Status    string                 `json:"status"` // "success", "error", "not_found"
+	Status    string                 `json:"status"` // "success", "error", "not_found", "low_accuracy", "inconclusive"
 	Message   string                 `json:"message"`
 	Score     float64                `json:"score"` // уверенность от 0.0 до 1.0
 	Details   map[string]interface{} `json:"details,omitempty"`
@@ -50,6 +50,9 @@ type SearchProviderInterface interface {
 	// ValidateCredentials проверяет валидность учетных данных
 	ValidateCredentials(ctx context.Context) error
 
+	// HealthCheck выполняет пробный запрос и возвращает ошибку, если провайдер не находит ничего
+	HealthCheck(ctx context.Context) error
+
 	// GetRateLimit возвращает лимит запросов
 	GetRateLimit() time.Duration
 }
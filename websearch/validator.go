@@ -11,18 +11,43 @@ import (
 
 // SearchClientInterface импортируется из search_client_interface.go
 
+// ValidationStatusInconclusive статус валидации, когда релевантные результаты найдены,
+// но уверенность поиска ниже порога minConfidence
+const ValidationStatusInconclusive = "inconclusive"
+
+// confidenceThresholdSource клиент поиска, задающий порог уверенности валидаторов (см. ClientConfig.MinConfidence)
+type confidenceThresholdSource interface {
+	MinConfidence() float64
+}
+
+// clientMinConfidence возвращает порог уверенности клиента или 0, если клиент его не задает
+func clientMinConfidence(client SearchClientInterface) float64 {
+	if source, ok := client.(confidenceThresholdSource); ok {
+		return source.MinConfidence()
+	}
+	return 0
+}
+
 // ProductExistenceValidator валидатор для проверки существования товара
 type ProductExistenceValidator struct {
-	client SearchClientInterface
+	client        SearchClientInterface
+	minConfidence float64
 }
 
-// NewProductExistenceValidator создает новый валидатор существования товара
+// NewProductExistenceValidator создает новый валидатор существования товара.
+// Порог уверенности берется из клиента (ClientConfig.MinConfidence), если клиент его задает.
 func NewProductExistenceValidator(client SearchClientInterface) *ProductExistenceValidator {
 	return &ProductExistenceValidator{
-		client: client,
+		client:        client,
+		minConfidence: clientMinConfidence(client),
 	}
 }
 
+// SetMinConfidence задает минимальную уверенность поиска, при которой товар считается найденным (0 - без порога)
+func (v *ProductExistenceValidator) SetMinConfidence(minConfidence float64) {
+	v.minConfidence = minConfidence
+}
+
 // Validate проверяет существование товара по названию
 func (v *ProductExistenceValidator) Validate(ctx context.Context, name string) (*types.ValidationResult, error) {
 	if strings.TrimSpace(name) == "" {
@@ -95,6 +120,9 @@ func (v *ProductExistenceValidator) analyzeResults(result *types.SearchResult, n
 		validation.Details["match_count"] = matchCount
 		validation.Details["total_results"] = len(result.Results)
 		validation.Details["confidence"] = result.Confidence
+		if v.minConfidence > 0 && result.Confidence < v.minConfidence {
+			markInconclusive(validation, result.Confidence, v.minConfidence)
+		}
 	} else {
 		validation.Status = "not_found"
 		validation.Found = false
@@ -107,18 +135,34 @@ func (v *ProductExistenceValidator) analyzeResults(result *types.SearchResult, n
 	return validation
 }
 
+// markInconclusive помечает найденный результат как неокончательный из-за низкой уверенности поиска
+func markInconclusive(validation *types.ValidationResult, confidence, minConfidence float64) {
+	validation.Status = ValidationStatusInconclusive
+	validation.Found = false
+	validation.Message = fmt.Sprintf("%s, но уверенность поиска %.2f ниже порога %.2f", validation.Message, confidence, minConfidence)
+	validation.Details["min_confidence"] = minConfidence
+}
+
 // ProductAccuracyValidator валидатор для проверки точности данных товара
 type ProductAccuracyValidator struct {
-	client SearchClientInterface
+	client        SearchClientInterface
+	minConfidence float64
 }
 
-// NewProductAccuracyValidator создает новый валидатор точности данных
+// NewProductAccuracyValidator создает новый валидатор точности данных.
+// Порог уверенности берется из клиента (ClientConfig.MinConfidence), если клиент его задает.
 func NewProductAccuracyValidator(client SearchClientInterface) *ProductAccuracyValidator {
 	return &ProductAccuracyValidator{
-		client: client,
+		client:        client,
+		minConfidence: clientMinConfidence(client),
 	}
 }
 
+// SetMinConfidence задает минимальную уверенность поиска, при которой данные считаются подтвержденными (0 - без порога)
+func (v *ProductAccuracyValidator) SetMinConfidence(minConfidence float64) {
+	v.minConfidence = minConfidence
+}
+
 // Validate проверяет точность данных товара (название + код)
 func (v *ProductAccuracyValidator) Validate(ctx context.Context, name, code string) (*types.ValidationResult, error) {
 	if strings.TrimSpace(name) == "" {
@@ -236,6 +280,8 @@ func (v *ProductAccuracyValidator) analyzeAccuracy(result *types.SearchResult, n
 		validation.Details["confidence"] = result.Confidence
 		if !validation.Found {
 			validation.Status = "low_accuracy"
+		} else if v.minConfidence > 0 && result.Confidence < v.minConfidence {
+			markInconclusive(validation, result.Confidence, v.minConfidence)
 		}
 	} else {
 		validation.Status = "not_found"
@@ -2,6 +2,7 @@ package websearch
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -131,3 +132,105 @@ func TestProductValidator_AnalyzeResults(t *testing.T) {
 	}
 }
 
+
+// fakeSearchProvider заглушка поиска, возвращающая заранее заданный результат
+type fakeSearchProvider struct {
+	result  *SearchResult
+	err     error
+	queries []string
+}
+
+func (f *fakeSearchProvider) Search(ctx context.Context, query string) (*SearchResult, error) {
+	f.queries = append(f.queries, query)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.result, nil
+}
+
+var _ SearchClientInterface = (*fakeSearchProvider)(nil)
+
+func TestProductValidators_WithFakeProvider(t *testing.T) {
+	provider := &fakeSearchProvider{result: &SearchResult{
+		Found:      true,
+		Source:     "fake",
+		Confidence: 0.8,
+		Results: []SearchItem{
+			{Title: "Болт М10 ГОСТ 7798-70", Snippet: "Болт м10 с шестигранной головкой, артикул 12345"},
+			{Title: "Гайки", Snippet: "Крепеж оптом"},
+		},
+	}}
+	ctx := context.Background()
+
+	existence, err := NewProductExistenceValidator(provider).Validate(ctx, "Болт М10")
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !existence.Found || existence.Score != 0.5 || existence.Provider != "fake" {
+		t.Errorf("Unexpected existence result: found=%v score=%v provider=%s", existence.Found, existence.Score, existence.Provider)
+	}
+
+	accuracy, err := NewProductAccuracyValidator(provider).Validate(ctx, "Болт М10", "12345")
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !accuracy.Found || accuracy.Details["matched_items"] != 1 {
+		t.Errorf("Unexpected accuracy result: found=%v details=%v", accuracy.Found, accuracy.Details)
+	}
+	if last := provider.queries[len(provider.queries)-1]; last != "Болт М10 12345" {
+		t.Errorf("Expected name and code in query, got %q", last)
+	}
+
+	provider.err = errors.New("provider unavailable")
+	failed, err := NewProductExistenceValidator(provider).Validate(ctx, "Болт М10")
+	if err != nil {
+		t.Fatalf("Validate should report provider errors in the result: %v", err)
+	}
+	if failed.Status != "error" || failed.Found {
+		t.Errorf("Expected error status, got %s", failed.Status)
+	}
+}
+
+// thresholdSearchProvider заглушка с порогом уверенности, как у Client с ClientConfig.MinConfidence
+type thresholdSearchProvider struct {
+	fakeSearchProvider
+	minConfidence float64
+}
+
+func (p *thresholdSearchProvider) MinConfidence() float64 {
+	return p.minConfidence
+}
+
+func TestProductExistenceValidator_Outcomes(t *testing.T) {
+	items := []SearchItem{{Title: "Болт М10 ГОСТ 7798-70", Snippet: "Болт с шестигранной головкой"}}
+	tests := []struct {
+		name       string
+		result     *SearchResult
+		wantStatus string
+		wantFound  bool
+	}{
+		{"found", &SearchResult{Found: true, Confidence: 0.8, Results: items}, "success", true},
+		{"not found", &SearchResult{Found: false}, "not_found", false},
+		{"low confidence", &SearchResult{Found: true, Confidence: 0.4, Results: items}, ValidationStatusInconclusive, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &thresholdSearchProvider{fakeSearchProvider: fakeSearchProvider{result: tt.result}, minConfidence: 0.6}
+			validation, err := NewProductExistenceValidator(provider).Validate(context.Background(), "болт м10")
+			if err != nil {
+				t.Fatalf("Validate failed: %v", err)
+			}
+			if validation.Status != tt.wantStatus || validation.Found != tt.wantFound {
+				t.Errorf("Expected %s (found=%v), got %s (found=%v): %s", tt.wantStatus, tt.wantFound, validation.Status, validation.Found, validation.Message)
+			}
+		})
+	}
+
+	// Явно заданный порог заменяет порог клиента
+	provider := &fakeSearchProvider{result: &SearchResult{Found: true, Confidence: 0.4, Results: items}}
+	validator := NewProductExistenceValidator(provider)
+	validator.SetMinConfidence(0.3)
+	if validation, _ := validator.Validate(context.Background(), "болт м10"); validation.Status != "success" {
+		t.Errorf("Expected success above explicit threshold, got %s", validation.Status)
+	}
+}